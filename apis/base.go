@@ -40,8 +40,11 @@ func NewRouter(app core.App) (*router.Router[*core.RequestEvent], error) {
 	bindSettingsApi(app, apiGroup)
 	bindCollectionApi(app, apiGroup)
 	bindRecordCrudApi(app, apiGroup)
+	bindRecordUploadApi(app, apiGroup)
+	bindRecordPresignedUploadApi(app, apiGroup)
 	bindRecordAuthApi(app, apiGroup)
 	bindLogsApi(app, apiGroup)
+	bindWebhooksApi(app, apiGroup)
 	bindBackupApi(app, apiGroup)
 	bindCronApi(app, apiGroup)
 	bindFileApi(app, apiGroup)
@@ -49,6 +52,10 @@ func NewRouter(app core.App) (*router.Router[*core.RequestEvent], error) {
 	bindRealtimeApi(app, apiGroup)
 	bindHealthApi(app, apiGroup)
 	bindSQLApi(app, apiGroup)
+	bindOAuth2ServerApi(app, apiGroup)
+
+	// well-known discovery routes
+	bindWellKnownApi(app, pbRouter.RouterGroup)
 
 	// UI routes
 	bindUIExtensions(app)
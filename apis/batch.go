@@ -148,6 +148,8 @@ func batchTransaction(e *core.RequestEvent) error {
 		return e.BadRequestError("Invalid batch request data.", err)
 	}
 
+	prescanBatchFiles(e, form.Requests)
+
 	event := new(core.BatchRequestEvent)
 	event.RequestEvent = e
 	event.Batch = form.Requests
@@ -354,6 +356,7 @@ func processInternalRequest(
 	event := &core.RequestEvent{}
 	event.App = activeApp
 	event.Auth = baseEvent.Auth
+	event.APIKey = baseEvent.APIKey
 	event.SetAll(baseEvent.GetAll())
 
 	// load RequestInfo context
@@ -454,6 +457,56 @@ func multipartDataFromInternalRequest(ir *core.InternalRequest) (*bytes.Buffer,
 	return buf, mw, mw.Close()
 }
 
+var batchRecordsURLRegex = regexp.MustCompile(`^/api/collections/([^\/\?]+)/records(?:/([^\/\?]+))?`)
+
+// prescanBatchFiles scans the batch requests uploaded files before the
+// transaction start to avoid holding the db write lock during the
+// (possibly slow) file scans.
+//
+// The scan results are reused by the related records validation.
+func prescanBatchFiles(e *core.RequestEvent, requests []*core.InternalRequest) {
+	for _, ir := range requests {
+		match := batchRecordsURLRegex.FindStringSubmatch(ir.URL)
+		if len(match) == 0 {
+			continue
+		}
+
+		collection, err := e.App.FindCachedCollectionByNameOrId(match[1])
+		if err != nil || collection.IsView() {
+			continue
+		}
+
+		id := match[2]
+		if id == "" {
+			id = cast.ToString(ir.Body["id"])
+		}
+
+		var record *core.Record
+		if id != "" {
+			record, _ = e.App.FindRecordById(collection, id)
+		}
+		if record == nil {
+			record = core.NewRecord(collection)
+		}
+
+		ctx := core.ContextWithAuth(e.Request.Context(), e.Auth)
+
+		for key, raw := range ir.Body {
+			files, ok := raw.([]*filesystem.File)
+			if !ok {
+				continue
+			}
+
+			// strip the append/prepend modifiers
+			name := strings.TrimSuffix(strings.TrimPrefix(key, "+"), "+")
+
+			if fileField, ok := collection.Fields.GetByName(name).(*core.FileField); ok {
+				fileField.PreScanFiles(ctx, e.App, record, files...)
+			}
+		}
+	}
+}
+
 func extractPrefixedFiles(request *http.Request, prefixes ...string) (map[string][]*filesystem.File, error) {
 	if request.MultipartForm == nil {
 		if err := request.ParseMultipartForm(router.DefaultMaxMemory); err != nil {
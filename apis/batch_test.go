@@ -620,6 +620,7 @@ func TestBatchRequest(t *testing.T) {
 			},
 			ExpectedEvents: map[string]int{
 				"*":              0,
+				"OnFileScan":     1, // all files have the same content and are pre-scanned once
 				"OnBatchRequest": 1,
 				// ---
 				"OnModelCreate":             3,
@@ -47,13 +47,21 @@ func TestCollectionsList(t *testing.T) {
 			ExpectedContent: []string{
 				`"page":1`,
 				`"perPage":30`,
-				`"totalItems":16`,
+				`"totalItems":24`,
 				`"items":[{`,
 				`"name":"` + core.CollectionNameSuperusers + `"`,
 				`"name":"` + core.CollectionNameAuthOrigins + `"`,
 				`"name":"` + core.CollectionNameExternalAuths + `"`,
 				`"name":"` + core.CollectionNameMFAs + `"`,
 				`"name":"` + core.CollectionNameOTPs + `"`,
+				`"name":"` + core.CollectionNameWebAuthnCredentials + `"`,
+				`"name":"` + core.CollectionNameTOTPs + `"`,
+				`"name":"` + core.CollectionNameAPIKeys + `"`,
+				`"name":"` + core.CollectionNameOAuth2Clients + `"`,
+				`"name":"` + core.CollectionNameAuthLockouts + `"`,
+				`"name":"` + core.CollectionNamePasswordHistory + `"`,
+				`"name":"` + core.CollectionNameUploads + `"`,
+				`"name":"` + core.CollectionNameAuthStates + `"`,
 				`"name":"users"`,
 				`"name":"nologin"`,
 				`"name":"clients"`,
@@ -85,9 +93,9 @@ func TestCollectionsList(t *testing.T) {
 			ExpectedContent: []string{
 				`"page":2`,
 				`"perPage":2`,
-				`"totalItems":16`,
+				`"totalItems":24`,
 				`"items":[{`,
-				`"name":"` + core.CollectionNameMFAs + `"`,
+				`"name":"` + core.CollectionNameAuthLockouts + `"`,
 			},
 			ExpectedEvents: map[string]int{
 				"*":                        0,
@@ -573,7 +581,7 @@ func TestCollectionCreate(t *testing.T) {
 				`"name":"new"`,
 				`"type":"auth"`,
 				`"system":false`,
-				`"passwordAuth":{"enabled":true,"identityFields":["email"]}`,
+				`"passwordAuth":{"enabled":true,"identityFields":["email"],"lockout":{"enabled":false,"maxFailures":5,"duration":900,"delay":1,"alert":false}}`,
 				`"authRule":""`,
 				`"manageRule":null`,
 				`"name":"test"`,
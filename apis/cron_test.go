@@ -60,6 +60,10 @@ func TestCronsList(t *testing.T) {
 				`{"id":"__pbDBOptimize__","expression":"0 0 * * *"}`,
 				`{"id":"__pbMFACleanup__","expression":"0 * * * *"}`,
 				`{"id":"__pbOTPCleanup__","expression":"0 * * * *"}`,
+				`{"id":"__pbAuthLockoutCleanup__","expression":"0 * * * *"}`,
+				`{"id":"__pbAuthStateCleanup__","expression":"0 * * * *"}`,
+				`{"id":"__pbUploadsCleanup__","expression":"0 * * * *"}`,
+				`{"id":"__pbSigningKeysRotation__","expression":"15 * * * *"}`,
 			},
 			ExpectedEvents: map[string]int{"*": 0},
 		},
@@ -161,8 +161,54 @@ func (api *fileApi) download(e *core.RequestEvent) error {
 	event.ServedPath = originalPath
 	event.ServedName = filename
 
+	// check for valid image transform param
+	transformParam := e.Request.URL.Query().Get("transform")
+	if transformParam != "" {
+		transform, err := fileField.FindTransform(transformParam)
+		if err != nil {
+			event.ThumbError = err
+		} else {
+			// extract the original file meta attributes and check it existence
+			oAttrs, oAttrsErr := fsys.Attributes(originalPath)
+			if oAttrsErr != nil {
+				return e.NotFoundError("", oAttrsErr)
+			}
+
+			if !list.ExistInSlice(oAttrs.ContentType, imageContentTypes) {
+				event.ThumbError = fmt.Errorf("the original file format %q is not supported", oAttrs.ContentType)
+			} else {
+				event.ServedName = transform.Filename(filename)
+				event.ServedPath = baseFilesPath + "/thumbs_" + filename + "/" + event.ServedName
+
+				// create a new transformed image if it doesn't exist
+				if exists, _ := fsys.Exists(event.ServedPath); !exists {
+					err := api.generateImage(e, event.ServedPath, func() error {
+						return fsys.CreateImageTransform(originalPath, event.ServedPath, transform)
+					})
+					if err != nil {
+						e.App.Logger().Warn(
+							"Fallback to original - failed to create image transform "+event.ServedName,
+							slog.Any("error", err),
+							slog.String("original", originalPath),
+							slog.String("transform", event.ServedPath),
+						)
+
+						// fallback to the original
+						event.ThumbError = err
+						event.ServedName = filename
+						event.ServedPath = originalPath
+					}
+				}
+			}
+		}
+	}
+
 	// check for valid thumb size param
+	// (the transform param takes precedence)
 	thumbSize := e.Request.URL.Query().Get("thumb")
+	if transformParam != "" {
+		thumbSize = ""
+	}
 	if thumbSize != "" && (list.ExistInSlice(thumbSize, defaultThumbSizes) || list.ExistInSlice(thumbSize, fileField.Thumbs)) {
 		// extract the original file meta attributes and check it existence
 		oAttrs, oAttrsErr := fsys.Attributes(originalPath)
@@ -205,6 +251,21 @@ func (api *fileApi) download(e *core.RequestEvent) error {
 	e.Response.Header().Del("X-Frame-Options")
 
 	return e.App.OnFileDownloadRequest().Trigger(event, func(e *core.FileDownloadRequestEvent) error {
+		// redirect to a short-lived presigned storage URL to avoid streaming the protected file through the app
+		if s3 := e.App.Settings().S3; e.FileField.Protected && s3.Enabled && s3.PresignedDownloads {
+			url, err := fsys.SignedServeURL(e.Request, e.ServedPath, e.ServedName, s3.PresignDuration())
+			if err != nil {
+				return e.NotFoundError("", err)
+			}
+
+			// the URL is valid only for a short time and shouldn't be cached
+			e.Response.Header().Set("Cache-Control", "no-store")
+
+			return execAfterSuccessTx(true, e.App, func() error {
+				return e.Redirect(http.StatusTemporaryRedirect, url)
+			})
+		}
+
 		err = execAfterSuccessTx(true, e.App, func() error {
 			return fsys.Serve(e.Response, e.Request, e.ServedPath, e.ServedName)
 		})
@@ -223,7 +284,15 @@ func (api *fileApi) createThumb(
 	thumbPath string,
 	thumbSize string,
 ) error {
-	ch := api.thumbGenPending.DoChan(thumbPath, func() (any, error) {
+	return api.generateImage(e, thumbPath, func() error {
+		return fsys.CreateThumb(originalPath, thumbPath, thumbSize)
+	})
+}
+
+// generateImage runs the generate func for the specified image key
+// limiting the concurrent generations and deduplicating the pending ones.
+func (api *fileApi) generateImage(e *core.RequestEvent, key string, generate func() error) error {
+	ch := api.thumbGenPending.DoChan(key, func() (any, error) {
 		ctx, cancel := context.WithTimeout(e.Request.Context(), api.thumbGenMaxWait)
 		defer cancel()
 
@@ -232,12 +301,12 @@ func (api *fileApi) createThumb(
 		}
 		defer api.thumbGenSem.Release(1)
 
-		return nil, fsys.CreateThumb(originalPath, thumbPath, thumbSize)
+		return nil, generate()
 	})
 
 	res := <-ch
 
-	api.thumbGenPending.Forget(thumbPath)
+	api.thumbGenPending.Forget(key)
 
 	return res.Err
 }
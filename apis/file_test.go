@@ -536,6 +536,141 @@ func TestFileDownload(t *testing.T) {
 	}
 }
 
+func TestFileDownloadTransform(t *testing.T) {
+	t.Parallel()
+
+	_, currentFile, _, _ := runtime.Caller(0)
+	dataDirRelPath := "../tests/data/"
+
+	testFilePath := filepath.Join(path.Dir(currentFile), dataDirRelPath, "storage/_pb_users_auth_/oap640cot4yru2s/test_kfd2wYLxkz.txt")
+	testImgPath := filepath.Join(path.Dir(currentFile), dataDirRelPath, "storage/_pb_users_auth_/4q1xlclmfloku33/300_1SEi6Q6U72.png")
+
+	testFile, fileErr := os.ReadFile(testFilePath)
+	if fileErr != nil {
+		t.Fatal(fileErr)
+	}
+
+	testImg, imgErr := os.ReadFile(testImgPath)
+	if imgErr != nil {
+		t.Fatal(imgErr)
+	}
+
+	setTransforms := func(t testing.TB, app *tests.TestApp, expectThumbError bool) {
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, f := range users.Fields {
+			if fileField, ok := f.(*core.FileField); ok {
+				fileField.Transforms = []string{"20x10,format:webp", "grayscale,format:jpeg,quality:80"}
+			}
+		}
+
+		if err := app.UnsafeWithoutHooks().Save(users); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := app.ReloadCachedCollections(); err != nil {
+			t.Fatal(err)
+		}
+
+		app.OnFileDownloadRequest().BindFunc(func(e *core.FileDownloadRequestEvent) error {
+			if expectThumbError && e.ThumbError == nil {
+				t.Fatal("Expected thumb error, got nil")
+			}
+			if !expectThumbError && e.ThumbError != nil {
+				t.Fatalf("Expected no thumb error, got %v", e.ThumbError)
+			}
+			return e.Next()
+		})
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:   "not allowed transform (should fallback to the original)",
+			Method: http.MethodGet,
+			URL:    "/api/files/_pb_users_auth_/4q1xlclmfloku33/300_1SEi6Q6U72.png?transform=20x10,format:png",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				setTransforms(t, app, true)
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{string(testImg)},
+			ExpectedEvents: map[string]int{
+				"*":                     0,
+				"OnFileDownloadRequest": 1,
+			},
+		},
+		{
+			Name:   "non-image file with allowed transform (should fallback to the original)",
+			Method: http.MethodGet,
+			URL:    "/api/files/_pb_users_auth_/oap640cot4yru2s/test_kfd2wYLxkz.txt?transform=20x10,format:webp",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				setTransforms(t, app, true)
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{string(testFile)},
+			ExpectedEvents: map[string]int{
+				"*":                     0,
+				"OnFileDownloadRequest": 1,
+			},
+		},
+		{
+			Name:   "allowed transform (with different options order and ignored thumb param)",
+			Method: http.MethodGet,
+			URL:    "/api/files/_pb_users_auth_/4q1xlclmfloku33/300_1SEi6Q6U72.png?transform=format:webp,20x10&thumb=70x50",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				setTransforms(t, app, false)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if ct := res.Header.Get("Content-Type"); ct != "image/webp" {
+					t.Fatalf("Expected image/webp Content-Type, got %q", ct)
+				}
+
+				fsys, err := app.NewFilesystem()
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer fsys.Close()
+
+				key := "_pb_users_auth_/4q1xlclmfloku33/thumbs_300_1SEi6Q6U72.png/20x10_format-webp_300_1SEi6Q6U72.webp"
+				if exists, _ := fsys.Exists(key); !exists {
+					t.Fatalf("Missing transformed file %q", key)
+				}
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"RIFF", "WEBPVP8L"},
+			ExpectedEvents: map[string]int{
+				"*":                     0,
+				"OnFileDownloadRequest": 1,
+			},
+		},
+		{
+			Name:   "allowed transform with jpeg output",
+			Method: http.MethodGet,
+			URL:    "/api/files/_pb_users_auth_/4q1xlclmfloku33/300_1SEi6Q6U72.png?transform=quality:80,grayscale,format:jpg",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				setTransforms(t, app, false)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if ct := res.Header.Get("Content-Type"); ct != "image/jpeg" {
+					t.Fatalf("Expected image/jpeg Content-Type, got %q", ct)
+				}
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"\xff\xd8\xff"}, // jpeg SOI marker
+			ExpectedEvents: map[string]int{
+				"*":                     0,
+				"OnFileDownloadRequest": 1,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
 func TestConcurrentThumbsGeneration(t *testing.T) {
 	t.Parallel()
 
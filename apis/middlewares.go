@@ -196,11 +196,17 @@ func loadAuthToken() *hook.Handler[*core.RequestEvent] {
 				return e.Next()
 			}
 
+			if strings.HasPrefix(token, core.APIKeyPrefix) {
+				loadAPIKeyAuth(e, token)
+				return e.Next()
+			}
+
 			record, err := e.App.FindAuthRecordByToken(token, core.TokenTypeAuth)
 			if err != nil {
 				e.App.Logger().Debug("loadAuthToken failure", "error", err)
 			} else if record != nil {
 				e.Auth = record
+				touchAuthSession(e, token)
 			}
 
 			return e.Next()
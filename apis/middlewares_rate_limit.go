@@ -3,6 +3,8 @@ package apis
 import (
 	"errors"
 	"net/netip"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -74,6 +76,63 @@ func collectionPathRateLimit(collectionPathParam string, baseTags ...string) *ho
 	}
 }
 
+// tagsRateLimit defines a rate limit middleware for the internal
+// non-collection handlers.
+//
+// The tags are checked before the default path labels (aka. ["oauth2:token", "POST /api/oauth2/token", "/api/oauth2/token"]).
+func tagsRateLimit(tags ...string) *hook.Handler[*core.RequestEvent] {
+	return &hook.Handler[*core.RequestEvent]{
+		Id:       DefaultRateLimitMiddlewareId,
+		Priority: DefaultRateLimitMiddlewarePriority,
+		Func: func(e *core.RequestEvent) error {
+			if skipRateLimit(e) {
+				return e.Next()
+			}
+
+			labels := append(slices.Clone(tags), defaultRateLimitLabels(e)...)
+
+			rule, ok := e.App.Settings().RateLimits.FindRateLimitRule(labels, defaultRateLimitAudience(e)...)
+			if ok {
+				err := checkRateLimit(e, e.Request.Pattern+strings.Join(tags, "")+rule.Audience, rule)
+				if err != nil {
+					return err
+				}
+			}
+
+			return e.Next()
+		},
+	}
+}
+
+// builtinRateLimit defines a fixed per-IP rate limit middleware.
+//
+// It is intended for the unauthenticated routes that persist short-lived
+// auth states (WebAuthn challenges, SAML requests, etc.) and unlike the
+// settings rate limit rules it is always applied, with the exception of
+// the superuser requests and the excluded IPs.
+func builtinRateLimit(tag string, maxRequests int, durationInSec int64) *hook.Handler[*core.RequestEvent] {
+	rule := core.RateLimitRule{
+		Label:       "@builtin:" + tag,
+		MaxRequests: maxRequests,
+		Duration:    durationInSec,
+	}
+
+	return &hook.Handler[*core.RequestEvent]{
+		Priority: DefaultRateLimitMiddlewarePriority,
+		Func: func(e *core.RequestEvent) error {
+			if e.HasSuperuserAuth() || isIPInList(e.App.Settings().RateLimits.ExcludedIPs, e.RealIP()) {
+				return e.Next()
+			}
+
+			if err := checkRateLimit(e, rule.Label, rule); err != nil {
+				return err
+			}
+
+			return e.Next()
+		},
+	}
+}
+
 // checkCollectionRateLimit checks whether the current request satisfy the
 // rate limit configuration for the specific collection.
 //
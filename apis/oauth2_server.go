@@ -0,0 +1,360 @@
+package apis
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Supported OAuth2 server scopes.
+const (
+	oauth2ServerScopeOpenId  = "openid"
+	oauth2ServerScopeProfile = "profile"
+	oauth2ServerScopeEmail   = "email"
+)
+
+var oauth2ServerScopes = []string{
+	oauth2ServerScopeOpenId,
+	oauth2ServerScopeProfile,
+	oauth2ServerScopeEmail,
+}
+
+// the auth state kinds of the OAuth2 server flow steps
+const (
+	authStateKindOAuth2ServerRequest      = "oauth2ServerRequest"
+	authStateKindOAuth2ServerCode         = "oauth2ServerCode"
+	authStateKindOAuth2ServerRefreshToken = "oauth2ServerRefreshToken"
+)
+
+const (
+	// oauth2ServerRequestDuration is the max time for the users to sign in
+	// and approve a pending authorization request.
+	oauth2ServerRequestDuration = 10 * time.Minute
+
+	// oauth2ServerCodeDuration is the max time for exchanging an authorization code.
+	oauth2ServerCodeDuration = 1 * time.Minute
+)
+
+// bindOAuth2ServerApi registers the OAuth2/OpenID Connect server
+// api endpoints and the corresponding handlers.
+//
+// Note that the discovery endpoints are registered separately with [bindWellKnownApi].
+func bindOAuth2ServerApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/oauth2")
+	sub.GET("/authorize", oauth2ServerAuthorize).Bind(
+		tagsRateLimit("oauth2:authorize"),
+		builtinRateLimit("oauth2Authorize", authStatesMaxRequestsPerMinute, 60),
+	)
+	sub.GET("/requests/{id}", oauth2ServerViewRequest).Bind(
+		tagsRateLimit("oauth2:viewRequest"),
+	)
+	sub.POST("/requests/{id}", oauth2ServerConsent).Bind(
+		tagsRateLimit("oauth2:consent"),
+	)
+	sub.POST("/token", oauth2ServerToken).Bind(
+		tagsRateLimit("oauth2:token"),
+		builtinRateLimit("oauth2Token", authStatesMaxRequestsPerMinute, 60),
+		SkipSuccessActivityLog(), // skip success log as it could contain sensitive information in the request
+	)
+	sub.GET("/userinfo", oauth2ServerUserInfo).Bind(
+		tagsRateLimit("oauth2:userinfo"),
+	)
+	sub.POST("/userinfo", oauth2ServerUserInfo).Bind(
+		tagsRateLimit("oauth2:userinfo"),
+	)
+}
+
+// oauth2ServerRequest is the data of a pending OAuth2 server
+// authorization request (or code) auth state.
+//
+// The client auth collection and the approving auth record are stored
+// as the state collectionRef and recordRef.
+type oauth2ServerRequest struct {
+	ClientId      string   `json:"clientId"`
+	RedirectURI   string   `json:"redirectURI"`
+	Scopes        []string `json:"scopes"`
+	State         string   `json:"state"`
+	Nonce         string   `json:"nonce"`
+	CodeChallenge string   `json:"codeChallenge"`
+
+	// set only after the request approval
+	AuthTime time.Time `json:"authTime"`
+}
+
+// oauth2ServerRefreshTokenData is the data of an issued OAuth2 server
+// refresh token auth state.
+//
+// All refresh tokens rotated from the same authorization code share the
+// same FamilyId so that the entire chain could be revoked on reuse.
+type oauth2ServerRefreshTokenData struct {
+	ClientId string `json:"clientId"`
+	Scope    string `json:"scope"`
+	FamilyId string `json:"familyId"`
+	Used     bool   `json:"used"`
+}
+
+// saveOAuth2ServerState persists a new OAuth2 server auth state
+// with the specified kind, lookup key and data.
+func saveOAuth2ServerState(app core.App, kind string, key string, collectionId string, recordId string, data any, duration time.Duration) error {
+	state := core.NewAuthState(app)
+	state.SetCollectionRef(collectionId)
+	state.SetRecordRef(recordId)
+	state.SetKind(kind)
+	state.SetKey(key)
+	state.SetData(data)
+	state.SetExpires(types.NowDateTime().Add(duration))
+
+	return app.Save(state)
+}
+
+// findOAuth2ServerRequest loads the pending OAuth2 server authorization
+// request (or code) auth state with the specified kind and key.
+//
+// If consume is set, the state is also deleted.
+func findOAuth2ServerRequest(app core.App, kind string, key string, consume bool) (*core.AuthState, *oauth2ServerRequest, bool) {
+	var state *core.AuthState
+	var err error
+
+	if consume {
+		state, err = app.ConsumeAuthState(kind, key)
+	} else {
+		state, err = app.FindAuthState(kind, key)
+	}
+	if err != nil {
+		return nil, nil, false
+	}
+
+	request := &oauth2ServerRequest{}
+	if err := state.UnmarshalData(request); err != nil {
+		return nil, nil, false
+	}
+
+	return state, request, true
+}
+
+// newOAuth2ServerRefreshToken generates a new OAuth2 server refresh token
+// and persists its auth state for the rotation and reuse detection.
+//
+// If familyId is empty a new refresh token family is started.
+func newOAuth2ServerRefreshToken(app core.App, client *core.OAuth2Client, record *core.Record, scope string, familyId string) (string, error) {
+	if familyId == "" {
+		familyId = security.RandomString(30)
+	}
+
+	duration := app.Settings().OAuth2Server.RefreshTokenDurationTime()
+
+	token, err := record.NewOAuth2RefreshToken(client.Id, scope, duration)
+	if err != nil {
+		return "", err
+	}
+
+	data := oauth2ServerRefreshTokenData{
+		ClientId: client.Id,
+		Scope:    scope,
+		FamilyId: familyId,
+	}
+
+	err = saveOAuth2ServerState(app, authStateKindOAuth2ServerRefreshToken, token, record.Collection().Id, record.Id, data, duration)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// revokeOAuth2ServerRefreshTokens deletes all refresh token auth states
+// of the provided auth record that belong to the specified token family.
+func revokeOAuth2ServerRefreshTokens(app core.App, record *core.Record, familyId string) error {
+	states, err := app.FindAllAuthStatesByRecord(record, authStateKindOAuth2ServerRefreshToken)
+	if err != nil {
+		return err
+	}
+
+	for _, state := range states {
+		data := oauth2ServerRefreshTokenData{}
+		if err := state.UnmarshalData(&data); err != nil || data.FamilyId != familyId {
+			continue
+		}
+
+		if err := app.Delete(state); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+// oauth2ServerError writes an OAuth2 error response as described in
+// [RFC 6749] (section 5.2).
+//
+// [RFC 6749]: https://datatracker.ietf.org/doc/html/rfc6749#section-5.2
+func oauth2ServerError(e *core.RequestEvent, status int, code string, description string) error {
+	if status == http.StatusUnauthorized {
+		e.Response.Header().Set("WWW-Authenticate", `Bearer error="`+code+`"`)
+	}
+
+	return e.JSON(status, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+// oauth2ServerRedirectURL returns the client redirect uri
+// extended with the provided query parameters.
+func oauth2ServerRedirectURL(redirectURI string, params map[string]string) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI // already validated on client save so it shouldn't happen
+	}
+
+	query := u.Query()
+	for k, v := range params {
+		if v != "" {
+			query.Set(k, v)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// oauth2ServerIssuer returns the OAuth2 server issuer identifier (aka. the app url).
+func oauth2ServerIssuer(app core.App) string {
+	return strings.TrimRight(app.Settings().Meta.AppURL, "/")
+}
+
+// parseOAuth2ServerScopes parses the space separated scope string and
+// returns the normalized scopes list (fallbacks to "openid" if empty).
+//
+// Returns false if the scope string contains unsupported scope value.
+func parseOAuth2ServerScopes(scope string) ([]string, bool) {
+	fields := strings.Fields(scope)
+	if len(fields) == 0 {
+		return []string{oauth2ServerScopeOpenId}, true
+	}
+
+	result := make([]string, 0, len(fields))
+
+	for _, s := range oauth2ServerScopes {
+		if slices.Contains(fields, s) {
+			result = append(result, s)
+		}
+	}
+
+	for _, f := range fields {
+		if !slices.Contains(oauth2ServerScopes, f) {
+			return nil, false
+		}
+	}
+
+	return result, true
+}
+
+// oauth2ServerUserClaims returns the OpenID Connect standard claims
+// of the provided auth record for the specified scopes.
+//
+// The profile claims are resolved from the auth collection OAuth2 mapped fields.
+func oauth2ServerUserClaims(app core.App, record *core.Record, scopes []string) map[string]any {
+	claims := map[string]any{
+		"sub": record.Id,
+	}
+
+	if slices.Contains(scopes, oauth2ServerScopeProfile) {
+		mapped := record.Collection().OAuth2.MappedFields
+
+		if mapped.Name != "" {
+			claims["name"] = record.GetString(mapped.Name)
+		}
+
+		if mapped.Username != "" {
+			claims["preferred_username"] = record.GetString(mapped.Username)
+		}
+
+		if mapped.AvatarURL != "" {
+			if picture := oauth2ServerPictureURL(app, record, mapped.AvatarURL); picture != "" {
+				claims["picture"] = picture
+			}
+		}
+
+		if updated := record.GetDateTime("updated"); !updated.IsZero() {
+			claims["updated_at"] = updated.Time().Unix()
+		}
+	}
+
+	if slices.Contains(scopes, oauth2ServerScopeEmail) && record.Email() != "" {
+		claims["email"] = record.Email()
+		claims["email_verified"] = record.Verified()
+	}
+
+	return claims
+}
+
+// oauth2ServerPictureURL returns the absolute picture url of the specified record avatar field.
+//
+// For file fields the url points to the first file of the field
+// (note that the files of collections with protected files may not be accessible).
+func oauth2ServerPictureURL(app core.App, record *core.Record, fieldName string) string {
+	value := record.GetString(fieldName)
+	if value == "" {
+		if files := record.GetStringSlice(fieldName); len(files) > 0 {
+			value = files[0]
+		}
+	}
+
+	if value == "" {
+		return ""
+	}
+
+	if _, ok := record.Collection().Fields.GetByName(fieldName).(*core.FileField); !ok {
+		return value
+	}
+
+	return oauth2ServerIssuer(app) + "/api/files/" +
+		url.PathEscape(record.Collection().Id) + "/" +
+		url.PathEscape(record.Id) + "/" +
+		url.PathEscape(value)
+}
+
+// newOAuth2ServerIdToken generates a new OpenID Connect ID token
+// signed with the active app signing key.
+func newOAuth2ServerIdToken(app core.App, client *core.OAuth2Client, record *core.Record, scopes []string, nonce string, authTime time.Time) (string, error) {
+	key, err := app.ActiveSigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	signer, err := key.Signer()
+	if err != nil {
+		return "", errors.Join(core.ErrMissingSigningKey, err)
+	}
+
+	claims := jwt.MapClaims{}
+	for k, v := range oauth2ServerUserClaims(app, record, scopes) {
+		claims[k] = v
+	}
+
+	claims["iss"] = oauth2ServerIssuer(app)
+	claims["aud"] = client.Id
+	claims["iat"] = time.Now().Unix()
+
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	if !authTime.IsZero() {
+		claims["auth_time"] = authTime.Unix()
+	}
+
+	return security.NewSignedJWT(claims, key.Algorithm, key.Id, signer, record.Collection().AuthToken.DurationTime())
+}
@@ -0,0 +1,199 @@
+package apis
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// oauth2ServerAuthorize handles the OAuth2 authorization endpoint request
+// (only the authorization code flow with PKCE is supported).
+//
+// On success the user is redirected to the configured consent page
+// with the pending request id as "request" query parameter.
+func oauth2ServerAuthorize(e *core.RequestEvent) error {
+	settings := e.App.Settings().OAuth2Server
+	if !settings.Enabled {
+		return e.NotFoundError("The OAuth2 server is not enabled.", nil)
+	}
+
+	query := e.Request.URL.Query()
+
+	client, err := e.App.FindOAuth2ClientById(query.Get("client_id"))
+	if err != nil {
+		return e.BadRequestError("Missing or invalid client_id.", err)
+	}
+
+	// the redirect_uri could be omitted only if the client has a single registered one
+	redirectURI := query.Get("redirect_uri")
+	if redirectURI == "" && len(client.RedirectURIs()) == 1 {
+		redirectURI = client.RedirectURIs()[0]
+	}
+	if !client.HasRedirectURI(redirectURI) {
+		return e.BadRequestError("Missing or invalid redirect_uri.", nil)
+	}
+
+	// from here on the errors are redirected back to the client
+	state := query.Get("state")
+	redirectErr := func(code string, description string) error {
+		return e.Redirect(http.StatusFound, oauth2ServerRedirectURL(redirectURI, map[string]string{
+			"error":             code,
+			"error_description": description,
+			"state":             state,
+		}))
+	}
+
+	if query.Get("response_type") != "code" {
+		return redirectErr("unsupported_response_type", "Only the authorization code flow is supported.")
+	}
+
+	scopes, ok := parseOAuth2ServerScopes(query.Get("scope"))
+	if !ok {
+		return redirectErr("invalid_scope", "Unsupported scope value.")
+	}
+
+	codeChallenge := query.Get("code_challenge")
+	if codeChallenge == "" || query.Get("code_challenge_method") != "S256" {
+		return redirectErr("invalid_request", "PKCE with S256 code_challenge_method is required.")
+	}
+
+	requestId := security.RandomString(30)
+
+	err = saveOAuth2ServerState(e.App, authStateKindOAuth2ServerRequest, requestId, client.CollectionRef(), "", &oauth2ServerRequest{
+		ClientId:      client.Id,
+		RedirectURI:   redirectURI,
+		Scopes:        scopes,
+		State:         state,
+		Nonce:         query.Get("nonce"),
+		CodeChallenge: codeChallenge,
+	}, oauth2ServerRequestDuration)
+	if err != nil {
+		return e.InternalServerError("Failed to store the authorization request.", err)
+	}
+
+	consentURL, err := url.Parse(settings.ConsentURL)
+	if err != nil {
+		return e.InternalServerError("Invalid OAuth2 server consent URL.", err)
+	}
+
+	consentQuery := consentURL.Query()
+	consentQuery.Set("request", requestId)
+	consentURL.RawQuery = consentQuery.Encode()
+
+	return e.Redirect(http.StatusFound, consentURL.String())
+}
+
+// oauth2ServerViewRequest returns the public information of a pending
+// authorization request so that it could be displayed on the consent page.
+func oauth2ServerViewRequest(e *core.RequestEvent) error {
+	if !e.App.Settings().OAuth2Server.Enabled {
+		return e.NotFoundError("The OAuth2 server is not enabled.", nil)
+	}
+
+	state, request, ok := findOAuth2ServerRequest(e.App, authStateKindOAuth2ServerRequest, e.Request.PathValue("id"), false)
+	if !ok {
+		return e.NotFoundError("Missing or expired authorization request.", nil)
+	}
+
+	client, err := e.App.FindOAuth2ClientById(request.ClientId)
+	if err != nil {
+		return e.NotFoundError("Missing or invalid OAuth2 client.", err)
+	}
+
+	collection, err := e.App.FindCachedCollectionByNameOrId(state.CollectionRef())
+	if err != nil {
+		return e.NotFoundError("Missing or invalid auth collection.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"client": map[string]any{
+			"id":   client.Id,
+			"name": client.Name(),
+		},
+		"collectionId":   collection.Id,
+		"collectionName": collection.Name,
+		"scopes":         request.Scopes,
+		"skipConsent":    client.SkipConsent(),
+	})
+}
+
+type oauth2ServerConsentForm struct {
+	Approve bool `form:"approve" json:"approve"`
+}
+
+// oauth2ServerConsent approves or denies a pending authorization request.
+//
+// The approval requires the request to be authenticated with a regular
+// auth token of a record from the client auth collection.
+//
+// Returns the client redirect url with the authorization code
+// (or the access_denied error) that the consent page should navigate to.
+func oauth2ServerConsent(e *core.RequestEvent) error {
+	if !e.App.Settings().OAuth2Server.Enabled {
+		return e.NotFoundError("The OAuth2 server is not enabled.", nil)
+	}
+
+	form := new(oauth2ServerConsentForm)
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	requestId := e.Request.PathValue("id")
+
+	state, request, ok := findOAuth2ServerRequest(e.App, authStateKindOAuth2ServerRequest, requestId, false)
+	if !ok {
+		return e.NotFoundError("Missing or expired authorization request.", nil)
+	}
+
+	if !form.Approve {
+		if _, _, ok := findOAuth2ServerRequest(e.App, authStateKindOAuth2ServerRequest, requestId, true); !ok {
+			return e.NotFoundError("Missing or expired authorization request.", nil)
+		}
+
+		return e.JSON(http.StatusOK, map[string]string{
+			"redirectURL": oauth2ServerRedirectURL(request.RedirectURI, map[string]string{
+				"error":             "access_denied",
+				"error_description": "The authorization request was denied.",
+				"state":             request.State,
+			}),
+		})
+	}
+
+	if e.Auth == nil {
+		return e.UnauthorizedError("The request requires valid auth record token.", nil)
+	}
+
+	if e.APIKey != nil {
+		return e.ForbiddenError("Authorization requests cannot be approved with an API key.", nil)
+	}
+
+	if e.Auth.Collection().Id != state.CollectionRef() {
+		return e.ForbiddenError("The authenticated record is not allowed to approve the authorization request.", nil)
+	}
+
+	// consume only after the auth checks so that
+	// the user could retry with the correct account
+	state, request, ok = findOAuth2ServerRequest(e.App, authStateKindOAuth2ServerRequest, requestId, true)
+	if !ok {
+		return e.NotFoundError("Missing or expired authorization request.", nil)
+	}
+
+	code := security.RandomString(40)
+
+	request.AuthTime = time.Now()
+
+	err := saveOAuth2ServerState(e.App, authStateKindOAuth2ServerCode, code, state.CollectionRef(), e.Auth.Id, request, oauth2ServerCodeDuration)
+	if err != nil {
+		return e.InternalServerError("Failed to store the authorization code.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]string{
+		"redirectURL": oauth2ServerRedirectURL(request.RedirectURI, map[string]string{
+			"code":  code,
+			"state": request.State,
+		}),
+	})
+}
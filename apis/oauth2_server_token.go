@@ -0,0 +1,221 @@
+package apis
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// oauth2ServerToken handles the OAuth2 token endpoint request
+// (supports the "authorization_code" and "refresh_token" grants).
+//
+// Note that for compatibility with the existing OAuth2 client libraries
+// the errors are returned in the RFC 6749 format and not as [router.ApiError].
+func oauth2ServerToken(e *core.RequestEvent) error {
+	if !e.App.Settings().OAuth2Server.Enabled {
+		return e.NotFoundError("The OAuth2 server is not enabled.", nil)
+	}
+
+	if err := e.Request.ParseForm(); err != nil {
+		return oauth2ServerError(e, http.StatusBadRequest, "invalid_request", "Failed to parse the request form data.")
+	}
+
+	client, ok := oauth2ServerAuthenticateClient(e)
+	if !ok {
+		return oauth2ServerError(e, http.StatusUnauthorized, "invalid_client", "Missing or invalid client credentials.")
+	}
+
+	switch e.Request.PostForm.Get("grant_type") {
+	case "authorization_code":
+		return oauth2ServerAuthorizationCodeGrant(e, client)
+	case "refresh_token":
+		return oauth2ServerRefreshTokenGrant(e, client)
+	default:
+		return oauth2ServerError(e, http.StatusBadRequest, "unsupported_grant_type", "Only the authorization_code and refresh_token grants are supported.")
+	}
+}
+
+// oauth2ServerAuthenticateClient loads and authenticates the token
+// request client either with HTTP Basic auth or with the client_id
+// and client_secret form fields.
+//
+// Public clients are authenticated only by their client_id.
+func oauth2ServerAuthenticateClient(e *core.RequestEvent) (*core.OAuth2Client, bool) {
+	clientId, clientSecret, hasBasicAuth := e.Request.BasicAuth()
+	if hasBasicAuth {
+		// the credentials are form-urlencoded before encoding with base64 (RFC 6749 section 2.3.1)
+		clientId, _ = url.QueryUnescape(clientId)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientId = e.Request.PostForm.Get("client_id")
+		clientSecret = e.Request.PostForm.Get("client_secret")
+	}
+
+	if clientId == "" {
+		return nil, false
+	}
+
+	client, err := e.App.FindOAuth2ClientById(clientId)
+	if err != nil {
+		return nil, false
+	}
+
+	if !client.IsPublic() && !client.ValidateSecret(clientSecret) {
+		return nil, false
+	}
+
+	return client, true
+}
+
+func oauth2ServerAuthorizationCodeGrant(e *core.RequestEvent, client *core.OAuth2Client) error {
+	form := e.Request.PostForm
+
+	state, request, ok := findOAuth2ServerRequest(e.App, authStateKindOAuth2ServerCode, form.Get("code"), true)
+	if !ok || request.ClientId != client.Id || state.CollectionRef() != client.CollectionRef() {
+		return oauth2ServerError(e, http.StatusBadRequest, "invalid_grant", "Missing, invalid or expired authorization code.")
+	}
+
+	if form.Get("redirect_uri") != request.RedirectURI {
+		return oauth2ServerError(e, http.StatusBadRequest, "invalid_grant", "The redirect_uri doesn't match with the authorization request one.")
+	}
+
+	// RFC 7636 section 4.1
+	verifier := form.Get("code_verifier")
+	if len(verifier) < 43 || len(verifier) > 128 || !security.Equal(security.S256Challenge(verifier), request.CodeChallenge) {
+		return oauth2ServerError(e, http.StatusBadRequest, "invalid_grant", "Missing or invalid code_verifier.")
+	}
+
+	record, err := e.App.FindRecordById(state.CollectionRef(), state.RecordRef())
+	if err != nil {
+		return oauth2ServerError(e, http.StatusBadRequest, "invalid_grant", "The authorization code record is missing.")
+	}
+
+	// start a new refresh token family
+	refreshToken, err := newOAuth2ServerRefreshToken(e.App, client, record, strings.Join(request.Scopes, " "), "")
+	if err != nil {
+		return e.InternalServerError("Failed to create OAuth2 refresh token.", err)
+	}
+
+	return oauth2ServerTokenResponse(e, client, record, request.Scopes, refreshToken, request.Nonce, request.AuthTime)
+}
+
+func oauth2ServerRefreshTokenGrant(e *core.RequestEvent, client *core.OAuth2Client) error {
+	form := e.Request.PostForm
+
+	refreshToken := form.Get("refresh_token")
+
+	record, err := e.App.FindAuthRecordByToken(refreshToken, core.TokenTypeOAuth2Refresh)
+	if err != nil || record.Collection().Id != client.CollectionRef() {
+		return oauth2ServerError(e, http.StatusBadRequest, "invalid_grant", "Missing, invalid or expired refresh token.")
+	}
+
+	// (the token is already verified so its claims could be trusted)
+	claims, _ := security.ParseUnverifiedJWT(refreshToken)
+	originalScope, _ := claims[core.TokenClaimScope].(string)
+	scopes := strings.Fields(originalScope)
+
+	// the requested scope (if any) must not include scopes not originally granted
+	if requestedScope := form.Get("scope"); requestedScope != "" {
+		requestedScopes, ok := parseOAuth2ServerScopes(requestedScope)
+		if !ok {
+			return oauth2ServerError(e, http.StatusBadRequest, "invalid_scope", "Unsupported scope value.")
+		}
+
+		for _, s := range requestedScopes {
+			if !slices.Contains(scopes, s) {
+				return oauth2ServerError(e, http.StatusBadRequest, "invalid_scope", "The requested scope exceeds the originally granted one.")
+			}
+		}
+
+		scopes = requestedScopes
+	}
+
+	// rotate the refresh token
+	//
+	// The used tokens are kept until their expiration so that a reuse
+	// (aka. a leaked token) could be detected and the entire token family revoked.
+	var reused bool
+	var data oauth2ServerRefreshTokenData
+	var newRefreshToken string
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		state, err := txApp.FindAuthState(authStateKindOAuth2ServerRefreshToken, refreshToken)
+		if err != nil {
+			return err
+		}
+
+		if err := state.UnmarshalData(&data); err != nil {
+			return err
+		}
+
+		if state.RecordRef() != record.Id || data.ClientId != client.Id {
+			return errors.New("refresh token state mismatch")
+		}
+
+		if data.Used {
+			reused = true
+			return revokeOAuth2ServerRefreshTokens(txApp, record, data.FamilyId)
+		}
+
+		data.Used = true
+		state.SetData(data)
+		if err := txApp.Save(state); err != nil {
+			return err
+		}
+
+		newRefreshToken, err = newOAuth2ServerRefreshToken(txApp, client, record, data.Scope, data.FamilyId)
+
+		return err
+	})
+	if err != nil || reused {
+		return oauth2ServerError(e, http.StatusBadRequest, "invalid_grant", "Missing, invalid or expired refresh token.")
+	}
+
+	return oauth2ServerTokenResponse(e, client, record, scopes, newRefreshToken, "", time.Time{})
+}
+
+// oauth2ServerTokenResponse issues and writes the OAuth2 token endpoint success response.
+func oauth2ServerTokenResponse(
+	e *core.RequestEvent,
+	client *core.OAuth2Client,
+	record *core.Record,
+	scopes []string,
+	refreshToken string,
+	nonce string,
+	authTime time.Time,
+) error {
+	scope := strings.Join(scopes, " ")
+
+	accessToken, err := record.NewOAuth2AccessToken(client.Id, scope)
+	if err != nil {
+		return e.InternalServerError("Failed to create OAuth2 access token.", err)
+	}
+
+	result := map[string]any{
+		"access_token":  accessToken,
+		"token_type":    "Bearer",
+		"expires_in":    record.Collection().AuthToken.Duration,
+		"refresh_token": refreshToken,
+		"scope":         scope,
+	}
+
+	if slices.Contains(scopes, oauth2ServerScopeOpenId) {
+		idToken, err := newOAuth2ServerIdToken(e.App, client, record, scopes, nonce, authTime)
+		if err != nil {
+			return e.InternalServerError("Failed to create OpenID Connect ID token.", err)
+		}
+
+		result["id_token"] = idToken
+	}
+
+	// RFC 6749 section 5.1
+	e.Response.Header().Set("Cache-Control", "no-store")
+	e.Response.Header().Set("Pragma", "no-cache")
+
+	return e.JSON(http.StatusOK, result)
+}
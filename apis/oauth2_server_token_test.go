@@ -0,0 +1,636 @@
+package apis_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestOAuth2ServerToken(t *testing.T) {
+	t.Parallel()
+
+	formHeaders := func(basicAuth ...string) map[string]string {
+		headers := map[string]string{
+			"content-type": "application/x-www-form-urlencoded",
+		}
+
+		if len(basicAuth) == 2 {
+			headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(basicAuth[0]+":"+basicAuth[1]))
+		}
+
+		return headers
+	}
+
+	// codeScenario returns a new BeforeTestFunc and form body that
+	// exchange a valid authorization code for the specified client.
+	codeScenario := func(clientId string, scope string, bodyParams ...string) (func(t testing.TB, app *tests.TestApp, e *core.ServeEvent), io.Reader) {
+		var body string
+
+		before := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+			enableOAuth2Server(t, app)
+
+			code := newOAuth2ServerCode(
+				t,
+				e,
+				testOAuth2ServerAuthorizeURL(clientId, scope, "nonce", "test_nonce"),
+				testOAuth2ServerUserToken,
+			)
+
+			form := url.Values{}
+			form.Set("grant_type", "authorization_code")
+			form.Set("code", code)
+			form.Set("redirect_uri", testOAuth2ServerRedirectURI)
+			form.Set("code_verifier", testOAuth2ServerVerifier)
+			for i := 0; i+1 < len(bodyParams); i += 2 {
+				form.Set(bodyParams[i], bodyParams[i+1])
+			}
+
+			body = form.Encode()
+		}
+
+		return before, &lazyBody{resolve: func() string { return body }}
+	}
+
+	// refreshScenario returns a new BeforeTestFunc and form body with
+	// refresh token issued to the users test record for the specified client.
+	refreshScenario := func(clientId string, scope string, bodyParams ...string) (func(t testing.TB, app *tests.TestApp, e *core.ServeEvent), io.Reader) {
+		var body string
+
+		before := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+			enableOAuth2Server(t, app)
+
+			refreshToken := newOAuth2ServerRefreshToken(t, e, clientId, scope)
+
+			form := url.Values{}
+			form.Set("grant_type", "refresh_token")
+			form.Set("refresh_token", refreshToken)
+			for i := 0; i+1 < len(bodyParams); i += 2 {
+				form.Set(bodyParams[i], bodyParams[i+1])
+			}
+
+			body = form.Encode()
+		}
+
+		return before, &lazyBody{resolve: func() string { return body }}
+	}
+
+	// statelessRefreshScenario returns a new BeforeTestFunc and form body with
+	// a signed refresh token that was not issued by the token endpoint.
+	statelessRefreshScenario := func(collection string, recordId string, clientId string) (func(t testing.TB, app *tests.TestApp, e *core.ServeEvent), io.Reader) {
+		var body string
+
+		before := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+			enableOAuth2Server(t, app)
+
+			record, err := app.FindRecordById(collection, recordId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			refreshToken, err := record.NewOAuth2RefreshToken(clientId, "openid", time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body = "grant_type=refresh_token&refresh_token=" + refreshToken
+		}
+
+		return before, &lazyBody{resolve: func() string { return body }}
+	}
+
+	// assertTokenResponse checks the token response tokens and returns the decoded response.
+	assertTokenResponse := func(t testing.TB, app *tests.TestApp, res *http.Response, expectedClientId string, expectIdToken bool) map[string]any {
+		if v := res.Header.Get("Cache-Control"); v != "no-store" {
+			t.Fatalf("Expected Cache-Control no-store header, got %q", v)
+		}
+
+		result := map[string]any{}
+		if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+
+		accessToken, _ := result["access_token"].(string)
+		record, err := app.FindAuthRecordByToken(accessToken, core.TokenTypeOAuth2Access)
+		if err != nil || record.Id != "4q1xlclmfloku33" {
+			t.Fatalf("Expected valid access token for user 4q1xlclmfloku33, got %v (%v)", record, err)
+		}
+
+		if _, err := app.FindAuthRecordByToken(accessToken, core.TokenTypeAuth); err == nil {
+			t.Fatal("Expected the access token to not be accepted as regular auth token")
+		}
+
+		refreshToken, _ := result["refresh_token"].(string)
+		if _, err := app.FindAuthRecordByToken(refreshToken, core.TokenTypeOAuth2Refresh); err != nil {
+			t.Fatalf("Expected valid refresh token, got %v", err)
+		}
+
+		refreshClaims, _ := security.ParseUnverifiedJWT(refreshToken)
+		if v := refreshClaims[core.TokenClaimClientId]; v != expectedClientId {
+			t.Fatalf("Expected refresh token client %q, got %v", expectedClientId, v)
+		}
+
+		idToken, hasIdToken := result["id_token"].(string)
+		if hasIdToken != expectIdToken {
+			t.Fatalf("Expected id_token %v, got %v", expectIdToken, hasIdToken)
+		}
+
+		if hasIdToken {
+			key, ok := app.Settings().SigningKeys.ActiveKey()
+			if !ok {
+				t.Fatal("Expected the app signing key to be generated")
+			}
+
+			signer, err := key.Signer()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims, err := security.ParseSignedJWT(idToken, key.Algorithm, signer.Public())
+			if err != nil {
+				t.Fatalf("Failed to verify the id_token: %v", err)
+			}
+
+			expectedClaims := map[string]any{
+				"iss": "http://localhost:8090",
+				"sub": "4q1xlclmfloku33",
+				"aud": expectedClientId,
+			}
+			for k, v := range expectedClaims {
+				if claims[k] != v {
+					t.Errorf("Expected id_token claim %q with value %v, got %v", k, v, claims[k])
+				}
+			}
+			result["id_token_claims"] = map[string]any(claims)
+		}
+
+		return result
+	}
+
+	successBefore, successBody := codeScenario("usersconfidential", "openid email")
+	postSecretBefore, postSecretBody := codeScenario("usersconfidential", "openid", "client_id", "usersconfidential", "client_secret", "secret")
+	publicBefore, publicBody := codeScenario("userspublic", "profile", "client_id", "userspublic")
+	otherClientBefore, otherClientBody := codeScenario("userspublic", "openid", "client_id", "usersconfidential", "client_secret", "secret")
+	redirectMismatchBefore, redirectMismatchBody := codeScenario("usersconfidential", "openid", "redirect_uri", "https://example.com/other")
+	invalidVerifierBefore, invalidVerifierBody := codeScenario("usersconfidential", "openid", "code_verifier", strings.Repeat("a", 43))
+	shortVerifierBefore, shortVerifierBody := codeScenario("usersconfidential", "openid", "code_verifier", "abc")
+
+	var reusedBody string
+	var reusedRefreshBody string
+	var rotatedRefreshToken string
+
+	refreshBefore, refreshBody := refreshScenario("usersconfidential", "openid profile email", "scope", "openid email")
+	refreshOtherClientBefore, refreshOtherClientBody := refreshScenario("userstrusted", "openid")
+	refreshExceedingScopeBefore, refreshExceedingScopeBody := refreshScenario("usersconfidential", "openid", "scope", "openid email")
+	refreshOtherCollectionBefore, refreshOtherCollectionBody := statelessRefreshScenario(core.CollectionNameSuperusers, "sywbhecnh46rhm0", "usersconfidential")
+	refreshStatelessBefore, refreshStatelessBody := statelessRefreshScenario("users", "4q1xlclmfloku33", "usersconfidential")
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "disabled OAuth2 server",
+			Method:          http.MethodPost,
+			URL:             "/api/oauth2/token",
+			Body:            strings.NewReader("grant_type=authorization_code&client_id=userspublic"),
+			Headers:         formHeaders(),
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:    "missing client",
+			Method:  http.MethodPost,
+			URL:     "/api/oauth2/token",
+			Body:    strings.NewReader("grant_type=authorization_code"),
+			Headers: formHeaders(),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableOAuth2Server(t, app)
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"error":"invalid_client"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:    "confidential client with invalid secret",
+			Method:  http.MethodPost,
+			URL:     "/api/oauth2/token",
+			Body:    strings.NewReader("grant_type=authorization_code"),
+			Headers: formHeaders("usersconfidential", "invalid"),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableOAuth2Server(t, app)
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"error":"invalid_client"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:    "confidential client without secret",
+			Method:  http.MethodPost,
+			URL:     "/api/oauth2/token",
+			Body:    strings.NewReader("grant_type=authorization_code&client_id=usersconfidential"),
+			Headers: formHeaders(),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableOAuth2Server(t, app)
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"error":"invalid_client"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:    "unsupported grant_type",
+			Method:  http.MethodPost,
+			URL:     "/api/oauth2/token",
+			Body:    strings.NewReader("grant_type=password&client_id=userspublic"),
+			Headers: formHeaders(),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableOAuth2Server(t, app)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"error":"unsupported_grant_type"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:    "authorization_code grant with invalid code",
+			Method:  http.MethodPost,
+			URL:     "/api/oauth2/token",
+			Body:    strings.NewReader("grant_type=authorization_code&code=invalid&client_id=userspublic&redirect_uri=" + url.QueryEscape(testOAuth2ServerRedirectURI) + "&code_verifier=" + testOAuth2ServerVerifier),
+			Headers: formHeaders(),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableOAuth2Server(t, app)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"error":"invalid_grant"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "authorization_code grant with code issued to another client",
+			Method:          http.MethodPost,
+			URL:             "/api/oauth2/token",
+			Body:            otherClientBody,
+			Headers:         formHeaders(),
+			BeforeTestFunc:  otherClientBefore,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"error":"invalid_grant"`},
+			ExpectedEvents: map[string]int{
+				"*": 0,
+				// consumed authorization code
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+		},
+		{
+			Name:            "authorization_code grant with redirect_uri mismatch",
+			Method:          http.MethodPost,
+			URL:             "/api/oauth2/token",
+			Body:            redirectMismatchBody,
+			Headers:         formHeaders("usersconfidential", "secret"),
+			BeforeTestFunc:  redirectMismatchBefore,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"error":"invalid_grant"`},
+			ExpectedEvents: map[string]int{
+				"*": 0,
+				// consumed authorization code
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+		},
+		{
+			Name:            "authorization_code grant with invalid code_verifier",
+			Method:          http.MethodPost,
+			URL:             "/api/oauth2/token",
+			Body:            invalidVerifierBody,
+			Headers:         formHeaders("usersconfidential", "secret"),
+			BeforeTestFunc:  invalidVerifierBefore,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"error":"invalid_grant"`},
+			ExpectedEvents: map[string]int{
+				"*": 0,
+				// consumed authorization code
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+		},
+		{
+			Name:            "authorization_code grant with too short code_verifier",
+			Method:          http.MethodPost,
+			URL:             "/api/oauth2/token",
+			Body:            shortVerifierBody,
+			Headers:         formHeaders("usersconfidential", "secret"),
+			BeforeTestFunc:  shortVerifierBefore,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"error":"invalid_grant"`},
+			ExpectedEvents: map[string]int{
+				"*": 0,
+				// consumed authorization code
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+		},
+		{
+			Name:    "authorization_code grant with already used code",
+			Method:  http.MethodPost,
+			URL:     "/api/oauth2/token",
+			Body:    &lazyBody{resolve: func() string { return reusedBody }},
+			Headers: formHeaders("usersconfidential", "secret"),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				before, body := codeScenario("usersconfidential", "openid")
+				before(t, app, e)
+
+				raw, err := io.ReadAll(body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				reusedBody = string(raw)
+
+				// exchange the code once
+				req, _ := http.NewRequest(http.MethodPost, "/api/oauth2/token", strings.NewReader(reusedBody))
+				req.SetBasicAuth("usersconfidential", "secret")
+				req.Header.Set("content-type", "application/x-www-form-urlencoded")
+				if rec := serveOAuth2ServerRequest(t, e, req); rec.Code != http.StatusOK {
+					t.Fatalf("Expected the first code exchange to succeed, got %d: %s", rec.Code, rec.Body.String())
+				}
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"error":"invalid_grant"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:           "authorization_code grant (client_secret_basic)",
+			Method:         http.MethodPost,
+			URL:            "/api/oauth2/token",
+			Body:           successBody,
+			Headers:        formHeaders("usersconfidential", "secret"),
+			BeforeTestFunc: successBefore,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				result := assertTokenResponse(t, app, res, "usersconfidential", true)
+
+				if v := result["scope"]; v != "openid email" {
+					t.Fatalf("Expected scope %q, got %v", "openid email", v)
+				}
+
+				if v := result["token_type"]; v != "Bearer" {
+					t.Fatalf("Expected Bearer token_type, got %v", v)
+				}
+
+				claims := result["id_token_claims"].(map[string]any)
+				expectedClaims := map[string]any{
+					"nonce":          "test_nonce",
+					"email":          "test@example.com",
+					"email_verified": false,
+				}
+				for k, v := range expectedClaims {
+					if claims[k] != v {
+						t.Errorf("Expected id_token claim %q with value %v, got %v", k, v, claims[k])
+					}
+				}
+
+				if _, ok := claims["auth_time"]; !ok {
+					t.Error("Expected auth_time id_token claim")
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"access_token":`,
+				`"refresh_token":`,
+				`"id_token":`,
+				`"expires_in":1209600`,
+			},
+			ExpectedEvents: map[string]int{
+				// signing key generation
+				"OnModelUpdate":             1,
+				"OnModelUpdateExecute":      1,
+				"OnModelAfterUpdateSuccess": 1,
+				"OnModelValidate":           2, // + 1 for the refresh token state
+				"OnSettingsReload":          1,
+				// consumed authorization code and new refresh token
+				"OnModelCreate":              1,
+				"OnModelCreateExecute":       1,
+				"OnModelAfterCreateSuccess":  1,
+				"OnRecordValidate":           1,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+		},
+		{
+			Name:           "authorization_code grant (client_secret_post)",
+			Method:         http.MethodPost,
+			URL:            "/api/oauth2/token",
+			Body:           postSecretBody,
+			Headers:        formHeaders(),
+			BeforeTestFunc: postSecretBefore,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				assertTokenResponse(t, app, res, "usersconfidential", true)
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"access_token":`,
+				`"refresh_token":`,
+				`"id_token":`,
+			},
+		},
+		{
+			Name:           "authorization_code grant (public client without openid scope)",
+			Method:         http.MethodPost,
+			URL:            "/api/oauth2/token",
+			Body:           publicBody,
+			Headers:        formHeaders(),
+			BeforeTestFunc: publicBefore,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				assertTokenResponse(t, app, res, "userspublic", false)
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"access_token":`,
+				`"refresh_token":`,
+				`"scope":"profile"`,
+			},
+			NotExpectedContent: []string{
+				`"id_token"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*": 0,
+				// consumed authorization code and new refresh token
+				"OnModelValidate":            1,
+				"OnModelCreate":              1,
+				"OnModelCreateExecute":       1,
+				"OnModelAfterCreateSuccess":  1,
+				"OnRecordValidate":           1,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+		},
+		{
+			Name:            "refresh_token grant with token issued to another client",
+			Method:          http.MethodPost,
+			URL:             "/api/oauth2/token",
+			Body:            refreshOtherClientBody,
+			Headers:         formHeaders("usersconfidential", "secret"),
+			BeforeTestFunc:  refreshOtherClientBefore,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"error":"invalid_grant"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "refresh_token grant with token of a record from another collection",
+			Method:          http.MethodPost,
+			URL:             "/api/oauth2/token",
+			Body:            refreshOtherCollectionBody,
+			Headers:         formHeaders("usersconfidential", "secret"),
+			BeforeTestFunc:  refreshOtherCollectionBefore,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"error":"invalid_grant"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:    "refresh_token grant with regular auth token",
+			Method:  http.MethodPost,
+			URL:     "/api/oauth2/token",
+			Body:    strings.NewReader("grant_type=refresh_token&refresh_token=" + testOAuth2ServerUserToken),
+			Headers: formHeaders("usersconfidential", "secret"),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableOAuth2Server(t, app)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"error":"invalid_grant"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "refresh_token grant with scope exceeding the original one",
+			Method:          http.MethodPost,
+			URL:             "/api/oauth2/token",
+			Body:            refreshExceedingScopeBody,
+			Headers:         formHeaders("usersconfidential", "secret"),
+			BeforeTestFunc:  refreshExceedingScopeBefore,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"error":"invalid_scope"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "refresh_token grant with token not issued by the token endpoint",
+			Method:          http.MethodPost,
+			URL:             "/api/oauth2/token",
+			Body:            refreshStatelessBody,
+			Headers:         formHeaders("usersconfidential", "secret"),
+			BeforeTestFunc:  refreshStatelessBefore,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"error":"invalid_grant"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:    "refresh_token grant with already used token (revokes the rotated tokens)",
+			Method:  http.MethodPost,
+			URL:     "/api/oauth2/token",
+			Body:    &lazyBody{resolve: func() string { return reusedRefreshBody }},
+			Headers: formHeaders("usersconfidential", "secret"),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableOAuth2Server(t, app)
+
+				refreshToken := newOAuth2ServerRefreshToken(t, e, "usersconfidential", "openid")
+
+				// rotate once
+				rec := refreshOAuth2ServerToken(t, e, "usersconfidential", refreshToken)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("Expected the first refresh to succeed, got %d: %s", rec.Code, rec.Body.String())
+				}
+
+				result := struct {
+					RefreshToken string `json:"refresh_token"`
+				}{}
+				if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+					t.Fatal(err)
+				}
+				rotatedRefreshToken = result.RefreshToken
+
+				if rotatedRefreshToken == "" || rotatedRefreshToken == refreshToken {
+					t.Fatalf("Expected the refresh token to be rotated, got %q", rotatedRefreshToken)
+				}
+
+				reusedRefreshBody = "grant_type=refresh_token&refresh_token=" + refreshToken
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if _, err := app.FindAuthState("oauth2ServerRefreshToken", rotatedRefreshToken); err == nil {
+					t.Fatal("Expected the rotated refresh token to be revoked")
+				}
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"error":"invalid_grant"`},
+			ExpectedEvents: map[string]int{
+				"*": 0,
+				// revoked token family
+				"OnModelDelete":              2,
+				"OnModelDeleteExecute":       2,
+				"OnModelAfterDeleteSuccess":  2,
+				"OnRecordDelete":             2,
+				"OnRecordDeleteExecute":      2,
+				"OnRecordAfterDeleteSuccess": 2,
+			},
+		},
+		{
+			Name:           "refresh_token grant with narrowed scope",
+			Method:         http.MethodPost,
+			URL:            "/api/oauth2/token",
+			Body:           refreshBody,
+			Headers:        formHeaders("usersconfidential", "secret"),
+			BeforeTestFunc: refreshBefore,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				result := assertTokenResponse(t, app, res, "usersconfidential", true)
+
+				claims := result["id_token_claims"].(map[string]any)
+				if _, ok := claims["nonce"]; ok {
+					t.Error("Expected no nonce id_token claim")
+				}
+				if _, ok := claims["name"]; ok {
+					t.Error("Expected no profile id_token claims")
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"access_token":`,
+				`"refresh_token":`,
+				`"id_token":`,
+				`"scope":"openid email"`,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,41 @@
+package apis
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// oauth2ServerUserInfo returns the OpenID Connect claims of the auth record
+// associated with the request OAuth2 access token.
+func oauth2ServerUserInfo(e *core.RequestEvent) error {
+	if !e.App.Settings().OAuth2Server.Enabled {
+		return e.NotFoundError("The OAuth2 server is not enabled.", nil)
+	}
+
+	token := getAuthTokenFromRequest(e)
+
+	record, err := e.App.FindAuthRecordByToken(token, core.TokenTypeOAuth2Access)
+	if err != nil {
+		return oauth2ServerError(e, http.StatusUnauthorized, "invalid_token", "Missing, invalid or expired access token.")
+	}
+
+	claims, _ := security.ParseUnverifiedJWT(token)
+
+	// the client could have been deleted after the token was issued
+	clientId, _ := claims[core.TokenClaimClientId].(string)
+	if _, err := e.App.FindOAuth2ClientById(clientId); err != nil {
+		return oauth2ServerError(e, http.StatusUnauthorized, "invalid_token", "The access token client is no longer registered.")
+	}
+
+	scope, _ := claims[core.TokenClaimScope].(string)
+	scopes := strings.Fields(scope)
+	if !slices.Contains(scopes, oauth2ServerScopeOpenId) {
+		return oauth2ServerError(e, http.StatusForbidden, "insufficient_scope", "The access token doesn't have the openid scope.")
+	}
+
+	return e.JSON(http.StatusOK, oauth2ServerUserClaims(e.App, record, scopes))
+}
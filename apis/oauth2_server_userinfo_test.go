@@ -0,0 +1,166 @@
+package apis_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestOAuth2ServerUserInfo(t *testing.T) {
+	t.Parallel()
+
+	// accessTokenScenario returns a new BeforeTestFunc and headers map with
+	// OAuth2 access token issued for the test user and the specified client.
+	accessTokenScenario := func(clientId string, scope string) (func(t testing.TB, app *tests.TestApp, e *core.ServeEvent), map[string]string) {
+		headers := map[string]string{}
+
+		before := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+			enableOAuth2Server(t, app)
+
+			users, err := app.FindCollectionByNameOrId("users")
+			if err != nil {
+				t.Fatal(err)
+			}
+			users.OAuth2.MappedFields.Name = "name"
+			users.OAuth2.MappedFields.AvatarURL = "avatar"
+			if err := app.Save(users); err != nil {
+				t.Fatal(err)
+			}
+
+			user, err := app.FindRecordById(users, "4q1xlclmfloku33")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			token, err := user.NewOAuth2AccessToken(clientId, scope)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			headers["Authorization"] = "Bearer " + token
+		}
+
+		return before, headers
+	}
+
+	successBefore, successHeaders := accessTokenScenario("usersconfidential", "openid profile email")
+	openIdOnlyBefore, openIdOnlyHeaders := accessTokenScenario("usersconfidential", "openid")
+	noOpenIdBefore, noOpenIdHeaders := accessTokenScenario("usersconfidential", "profile email")
+	deletedClientBefore, deletedClientHeaders := accessTokenScenario("usersconfidential", "openid")
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "disabled OAuth2 server",
+			Method:          http.MethodGet,
+			URL:             "/api/oauth2/userinfo",
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "missing access token",
+			Method: http.MethodGet,
+			URL:    "/api/oauth2/userinfo",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableOAuth2Server(t, app)
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"error":"invalid_token"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "regular auth token",
+			Method: http.MethodGet,
+			URL:    "/api/oauth2/userinfo",
+			Headers: map[string]string{
+				"Authorization": testOAuth2ServerUserToken,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableOAuth2Server(t, app)
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"error":"invalid_token"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:    "access token of a deleted client",
+			Method:  http.MethodGet,
+			URL:     "/api/oauth2/userinfo",
+			Headers: deletedClientHeaders,
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				deletedClientBefore(t, app, e)
+
+				client, err := app.FindOAuth2ClientById("usersconfidential")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if err := app.Delete(client); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"error":"invalid_token"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "access token without openid scope",
+			Method:          http.MethodGet,
+			URL:             "/api/oauth2/userinfo",
+			Headers:         noOpenIdHeaders,
+			BeforeTestFunc:  noOpenIdBefore,
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"error":"insufficient_scope"`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:           "access token with openid scope only",
+			Method:         http.MethodGet,
+			URL:            "/api/oauth2/userinfo",
+			Headers:        openIdOnlyHeaders,
+			BeforeTestFunc: openIdOnlyBefore,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`{"sub":"4q1xlclmfloku33"}`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+		{
+			Name:           "access token with openid, profile and email scopes (GET)",
+			Method:         http.MethodGet,
+			URL:            "/api/oauth2/userinfo",
+			Headers:        successHeaders,
+			BeforeTestFunc: successBefore,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"sub":"4q1xlclmfloku33"`,
+				`"name":"test1"`,
+				`"preferred_username":"users75657"`,
+				`"picture":"http://localhost:8090/api/files/_pb_users_auth_/4q1xlclmfloku33/300_1SEi6Q6U72.png"`,
+				`"updated_at":`,
+				`"email":"test@example.com"`,
+				`"email_verified":false`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+		{
+			Name:           "access token with openid, profile and email scopes (POST)",
+			Method:         http.MethodPost,
+			URL:            "/api/oauth2/userinfo",
+			Headers:        successHeaders,
+			BeforeTestFunc: successBefore,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"sub":"4q1xlclmfloku33"`,
+				`"email":"test@example.com"`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -35,9 +35,11 @@ const RealtimeClientIPKey = "pbRealtimeClientIP"
 func bindRealtimeApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
 	sub := rg.Group("/realtime")
 	sub.GET("", realtimeConnect).Bind(SkipSuccessActivityLog())
+	sub.GET("/ws", realtimeConnectWebSocket).Bind(SkipSuccessActivityLog())
 	sub.POST("", realtimeSetSubscriptions)
 
 	bindRealtimeEvents(app)
+	bindRealtimePeers(app)
 }
 
 func realtimeConnect(e *core.RequestEvent) error {
@@ -78,8 +80,11 @@ func realtimeConnect(e *core.RequestEvent) error {
 		ce.App.SubscriptionsBroker().Register(ce.Client)
 		defer func() {
 			e.App.SubscriptionsBroker().Unregister(ce.Client.Id())
+			realtimeMarkClientDisconnect(e.App, ce.Client)
 		}()
 
+		realtimeInitClientReplay(ce.RequestEvent, ce.Client)
+
 		ce.App.Logger().Debug("Realtime connection established", slog.String("clientId", ce.Client.Id()))
 
 		// signalize established connection (aka. fire "connect" message)
@@ -136,7 +141,12 @@ func realtimeConnect(e *core.RequestEvent) error {
 				msgEvent.Client = ce.Client
 				msgEvent.Message = &msg
 				msgErr := ce.App.OnRealtimeMessageSend().Trigger(msgEvent, func(me *core.RealtimeMessageEvent) error {
-					err := me.Message.WriteSSE(me.Response, me.Client.Id())
+					eventId := me.Message.Id
+					if eventId == "" {
+						eventId = me.Client.Id()
+					}
+
+					err := me.Message.WriteSSE(me.Response, eventId)
 					if err != nil {
 						return err
 					}
@@ -236,6 +246,9 @@ func realtimeSetSubscriptions(e *core.RequestEvent) error {
 		// subscribe to the new subscriptions
 		e.Client.Subscribe(e.Subscriptions...)
 
+		// send the missed events (if the client has reconnected)
+		realtimeReplayClientEvents(e.App, e.Client)
+
 		e.App.Logger().Debug(
 			"Realtime subscriptions updated",
 			slog.String("clientId", e.Client.Id()),
@@ -420,7 +433,9 @@ func bindRealtimeEvents(app core.App) {
 		Func: func(e *core.ModelEvent) error {
 			record := realtimeResolveRecord(e.App, e.Model, "")
 			if record != nil {
-				err := realtimeBroadcastRecord(e.App, "create", record, false)
+				eventId := realtimeLogRecordEvent(e.App, "create", record)
+
+				err := realtimeBroadcastRecord(e.App, eventId, "create", record, false)
 				if err != nil {
 					app.Logger().Debug(
 						"Failed to broadcast record create",
@@ -429,6 +444,16 @@ func bindRealtimeEvents(app core.App) {
 						slog.String("error", err.Error()),
 					)
 				}
+
+				err = realtimePublishRecord(e.App, "create", record)
+				if err != nil {
+					app.Logger().Debug(
+						"Failed to publish record create to the realtime peers",
+						slog.String("id", record.Id),
+						slog.String("collectionName", record.Collection().Name),
+						slog.String("error", err.Error()),
+					)
+				}
 			}
 
 			return e.Next()
@@ -440,7 +465,9 @@ func bindRealtimeEvents(app core.App) {
 		Func: func(e *core.ModelEvent) error {
 			record := realtimeResolveRecord(e.App, e.Model, "")
 			if record != nil {
-				err := realtimeBroadcastRecord(e.App, "update", record, false)
+				eventId := realtimeLogRecordEvent(e.App, "update", record)
+
+				err := realtimeBroadcastRecord(e.App, eventId, "update", record, false)
 				if err != nil {
 					app.Logger().Debug(
 						"Failed to broadcast record update",
@@ -449,6 +476,16 @@ func bindRealtimeEvents(app core.App) {
 						slog.String("error", err.Error()),
 					)
 				}
+
+				err = realtimePublishRecord(e.App, "update", record)
+				if err != nil {
+					app.Logger().Debug(
+						"Failed to publish record update to the realtime peers",
+						slog.String("id", record.Id),
+						slog.String("collectionName", record.Collection().Name),
+						slog.String("error", err.Error()),
+					)
+				}
 			}
 
 			return e.Next()
@@ -464,7 +501,7 @@ func bindRealtimeEvents(app core.App) {
 				// note: use the outside scoped app instance for the access checks so that the API rules
 				// are performed out of the delete transaction ensuring that they would still work even if
 				// a cascade-deleted record's API rule relies on an already deleted parent record
-				err := realtimeBroadcastRecord(e.App, "delete", record, true, app)
+				err := realtimeBroadcastRecord(e.App, "", "delete", record, true, app)
 				if err != nil {
 					app.Logger().Debug(
 						"Failed to dry cache record delete",
@@ -488,7 +525,15 @@ func bindRealtimeEvents(app core.App) {
 			// custom model it'll fail to resolve since the record is already deleted
 			collection := realtimeResolveRecordCollection(e.App, e.Model)
 			if collection != nil {
-				err := realtimeBroadcastDryCacheKey(e.App, getDryCacheKey("delete", e.Model))
+				// note: custom models are not logged and published because their record state is already deleted
+				record := realtimeResolveDeletedRecord(e.Model)
+
+				var eventId string
+				if record != nil {
+					eventId = realtimeLogRecordEvent(e.App, "delete", record)
+				}
+
+				err := realtimeBroadcastDryCacheKey(e.App, getDryCacheKey("delete", e.Model), eventId)
 				if err != nil {
 					app.Logger().Debug(
 						"Failed to broadcast record delete",
@@ -497,6 +542,18 @@ func bindRealtimeEvents(app core.App) {
 						slog.String("error", err.Error()),
 					)
 				}
+
+				if record != nil {
+					err = realtimePublishRecord(e.App, "delete", record)
+					if err != nil {
+						app.Logger().Debug(
+							"Failed to publish record delete to the realtime peers",
+							slog.String("id", record.Id),
+							slog.String("collectionName", collection.Name),
+							slog.String("error", err.Error()),
+						)
+					}
+				}
 			}
 
 			return e.Next()
@@ -591,7 +648,9 @@ type recordData struct {
 // to be performed against different db app context (e.g. out of a transaction).
 // If set, it is expected that optAccessCheckApp instance is used for read-only operations to avoid deadlocks.
 // If not set, it fallbacks to app.
-func realtimeBroadcastRecord(app core.App, action string, record *core.Record, dryCache bool, optAccessCheckApp ...core.App) error {
+//
+// eventId is the optional event log entry id assigned to the broadcasted messages.
+func realtimeBroadcastRecord(app core.App, eventId string, action string, record *core.Record, dryCache bool, optAccessCheckApp ...core.App) error {
 	collection := record.Collection()
 	if collection == nil {
 		return errors.New("[broadcastRecord] Record collection not set")
@@ -602,17 +661,6 @@ func realtimeBroadcastRecord(app core.App, action string, record *core.Record, d
 		return nil // no subscribers
 	}
 
-	subscriptionRuleMap := map[string]*string{
-		(collection.Name + "/" + record.Id + "?"): collection.ViewRule,
-		(collection.Id + "/" + record.Id + "?"):   collection.ViewRule,
-		(collection.Name + "/*?"):                 collection.ListRule,
-		(collection.Id + "/*?"):                   collection.ListRule,
-
-		// @deprecated: the same as the wildcard topic but kept for backward compatibility
-		(collection.Name + "?"): collection.ListRule,
-		(collection.Id + "?"):   collection.ListRule,
-	}
-
 	dryCacheKey := getDryCacheKey(action, record)
 
 	group := new(errgroup.Group)
@@ -624,156 +672,191 @@ func realtimeBroadcastRecord(app core.App, action string, record *core.Record, d
 
 	for _, chunk := range chunks {
 		group.Go(routine.SafeWrap(func() error {
-			var clientAuth *core.Record
-
 			for _, client := range chunk {
 				// note: not executed concurrently to avoid races and to ensure
 				// that the access checks are applied for the current record db state
-				for prefix, rule := range subscriptionRuleMap {
-					subs := client.Subscriptions(prefix)
-					if len(subs) == 0 {
-						continue
+				messages := realtimeRecordClientMessages(app, accessCheckApp, client, eventId, action, record)
+
+				for _, msg := range messages {
+					if dryCache {
+						cached, ok := client.Get(dryCacheKey).([]subscriptions.Message)
+						if !ok {
+							cached = []subscriptions.Message{msg}
+						} else {
+							cached = append(cached, msg)
+						}
+						client.Set(dryCacheKey, cached)
+					} else {
+						routine.FireAndForget(func() {
+							client.Send(msg)
+						})
 					}
+				}
+			}
 
-					clientAuth, _ = client.Get(RealtimeClientAuthKey).(*core.Record)
+			return nil
+		}))
+	}
 
-					for sub, options := range subs {
-						// mock request data
-						requestInfo := &core.RequestInfo{
-							Context: core.RequestInfoContextRealtime,
-							Method:  "GET",
-							Query:   options.Query,
-							Headers: options.Headers,
-							Auth:    clientAuth,
-						}
+	return group.Wait()
+}
 
-						if !realtimeCanAccessRecord(accessCheckApp, record, requestInfo, rule) {
-							continue
-						}
+// realtimeRecordClientMessages returns the record event messages
+// for the client subscriptions that have access to the record.
+func realtimeRecordClientMessages(
+	app core.App,
+	accessCheckApp core.App,
+	client subscriptions.Client,
+	eventId string,
+	action string,
+	record *core.Record,
+) []subscriptions.Message {
+	collection := record.Collection()
 
-						// create a clean record copy without expand and unknown fields because we don't know yet
-						// which exact fields the client subscription requested or has permissions to access
-						cleanRecord := record.Fresh()
-
-						// -------------------------------------------
-						// @todo consider with the refactoring whether
-						// the default enriching used by the regular APIs
-						// can be reused here too to avoid eventual future
-						// discrepencies in the record event data
-						//
-						// https://github.com/pocketbase/pocketbase/issues/7721
-						// -------------------------------------------
-
-						// enable hidden fields for superuser subscribers
-						if requestInfo.HasSuperuserAuth() {
-							cleanRecord.Unhide(collection.Fields.FieldNames()...)
-						}
+	subscriptionRuleMap := map[string]*string{
+		(collection.Name + "/" + record.Id + "?"): collection.ViewRule,
+		(collection.Id + "/" + record.Id + "?"):   collection.ViewRule,
+		(collection.Name + "/*?"):                 collection.ListRule,
+		(collection.Id + "/*?"):                   collection.ListRule,
 
-						// trigger the enrich hooks
-						enrichErr := triggerRecordEnrichHooks(app, requestInfo, []*core.Record{cleanRecord}, func() error {
-							// apply expand
-							rawExpand := options.Query[expandQueryParam]
-							if rawExpand != "" {
-								expandErrs := app.ExpandRecord(cleanRecord, strings.Split(rawExpand, ","), expandFetch(app, requestInfo))
-								if len(expandErrs) > 0 {
-									app.Logger().Debug(
-										"[broadcastRecord] expand errors",
-										slog.String("id", cleanRecord.Id),
-										slog.String("collectionName", cleanRecord.Collection().Name),
-										slog.String("sub", sub),
-										slog.String("expand", rawExpand),
-										slog.Any("errors", expandErrs),
-									)
-								}
-							}
-
-							// ignore the auth record email visibility checks
-							// for auth owner, superuser or manager
-							if collection.IsAuth() {
-								if isSameAuth(clientAuth, cleanRecord) ||
-									realtimeCanAccessRecord(accessCheckApp, cleanRecord, requestInfo, collection.ManageRule) {
-									cleanRecord.IgnoreEmailVisibility(true)
-								}
-							}
-
-							return nil
-						})
-						if enrichErr != nil {
-							app.Logger().Debug(
-								"[broadcastRecord] record enrich error",
-								slog.String("id", cleanRecord.Id),
-								slog.String("collectionName", cleanRecord.Collection().Name),
-								slog.String("sub", sub),
-								slog.Any("error", enrichErr),
-							)
-							continue
-						}
+		// @deprecated: the same as the wildcard topic but kept for backward compatibility
+		(collection.Name + "?"): collection.ListRule,
+		(collection.Id + "?"):   collection.ListRule,
+	}
 
-						data := &recordData{
-							Action: action,
-							Record: cleanRecord,
-						}
+	var messages []subscriptions.Message
 
-						// check fields
-						rawFields := options.Query[fieldsQueryParam]
-						if rawFields != "" {
-							decoded, err := picker.Pick(cleanRecord, rawFields)
-							if err == nil {
-								data.Record = decoded
-							} else {
-								app.Logger().Debug(
-									"[broadcastRecord] pick fields error",
-									slog.String("id", cleanRecord.Id),
-									slog.String("collectionName", cleanRecord.Collection().Name),
-									slog.String("sub", sub),
-									slog.String("fields", rawFields),
-									slog.String("error", err.Error()),
-								)
-							}
-						}
+	var clientAuth *core.Record
 
-						dataBytes, err := json.Marshal(data)
-						if err != nil {
-							app.Logger().Debug(
-								"[broadcastRecord] data marshal error",
-								slog.String("id", cleanRecord.Id),
-								slog.String("collectionName", cleanRecord.Collection().Name),
-								slog.String("error", err.Error()),
-							)
-							continue
-						}
+	for prefix, rule := range subscriptionRuleMap {
+		subs := client.Subscriptions(prefix)
+		if len(subs) == 0 {
+			continue
+		}
 
-						msg := subscriptions.Message{
-							Name: sub,
-							Data: dataBytes,
-						}
+		clientAuth, _ = client.Get(RealtimeClientAuthKey).(*core.Record)
 
-						if dryCache {
-							messages, ok := client.Get(dryCacheKey).([]subscriptions.Message)
-							if !ok {
-								messages = []subscriptions.Message{msg}
-							} else {
-								messages = append(messages, msg)
-							}
-							client.Set(dryCacheKey, messages)
-						} else {
-							routine.FireAndForget(func() {
-								client.Send(msg)
-							})
-						}
+		for sub, options := range subs {
+			// mock request data
+			requestInfo := &core.RequestInfo{
+				Context: core.RequestInfoContextRealtime,
+				Method:  "GET",
+				Query:   options.Query,
+				Headers: options.Headers,
+				Auth:    clientAuth,
+			}
+
+			if !realtimeCanAccessRecord(accessCheckApp, record, requestInfo, rule) {
+				continue
+			}
+
+			// create a clean record copy without expand and unknown fields because we don't know yet
+			// which exact fields the client subscription requested or has permissions to access
+			cleanRecord := record.Fresh()
+
+			// -------------------------------------------
+			// @todo consider with the refactoring whether
+			// the default enriching used by the regular APIs
+			// can be reused here too to avoid eventual future
+			// discrepencies in the record event data
+			//
+			// https://github.com/pocketbase/pocketbase/issues/7721
+			// -------------------------------------------
+
+			// enable hidden fields for superuser subscribers
+			if requestInfo.HasSuperuserAuth() {
+				cleanRecord.Unhide(collection.Fields.FieldNames()...)
+			}
+
+			// trigger the enrich hooks
+			enrichErr := triggerRecordEnrichHooks(app, requestInfo, []*core.Record{cleanRecord}, func() error {
+				// apply expand
+				rawExpand := options.Query[expandQueryParam]
+				if rawExpand != "" {
+					expandErrs := app.ExpandRecord(cleanRecord, strings.Split(rawExpand, ","), expandFetch(app, requestInfo))
+					if len(expandErrs) > 0 {
+						app.Logger().Debug(
+							"[broadcastRecord] expand errors",
+							slog.String("id", cleanRecord.Id),
+							slog.String("collectionName", cleanRecord.Collection().Name),
+							slog.String("sub", sub),
+							slog.String("expand", rawExpand),
+							slog.Any("errors", expandErrs),
+						)
+					}
+				}
+
+				// ignore the auth record email visibility checks
+				// for auth owner, superuser or manager
+				if collection.IsAuth() {
+					if isSameAuth(clientAuth, cleanRecord) ||
+						realtimeCanAccessRecord(accessCheckApp, cleanRecord, requestInfo, collection.ManageRule) {
+						cleanRecord.IgnoreEmailVisibility(true)
 					}
 				}
+
+				return nil
+			})
+			if enrichErr != nil {
+				app.Logger().Debug(
+					"[broadcastRecord] record enrich error",
+					slog.String("id", cleanRecord.Id),
+					slog.String("collectionName", cleanRecord.Collection().Name),
+					slog.String("sub", sub),
+					slog.Any("error", enrichErr),
+				)
+				continue
 			}
 
-			return nil
-		}))
+			data := &recordData{
+				Action: action,
+				Record: cleanRecord,
+			}
+
+			// check fields
+			rawFields := options.Query[fieldsQueryParam]
+			if rawFields != "" {
+				decoded, err := picker.Pick(cleanRecord, rawFields)
+				if err == nil {
+					data.Record = decoded
+				} else {
+					app.Logger().Debug(
+						"[broadcastRecord] pick fields error",
+						slog.String("id", cleanRecord.Id),
+						slog.String("collectionName", cleanRecord.Collection().Name),
+						slog.String("sub", sub),
+						slog.String("fields", rawFields),
+						slog.String("error", err.Error()),
+					)
+				}
+			}
+
+			dataBytes, err := json.Marshal(data)
+			if err != nil {
+				app.Logger().Debug(
+					"[broadcastRecord] data marshal error",
+					slog.String("id", cleanRecord.Id),
+					slog.String("collectionName", cleanRecord.Collection().Name),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+
+			messages = append(messages, subscriptions.Message{
+				Id:   eventId,
+				Name: sub,
+				Data: dataBytes,
+			})
+		}
 	}
 
-	return group.Wait()
+	return messages
 }
 
 // realtimeBroadcastDryCacheKey broadcasts the dry cached key related messages.
-func realtimeBroadcastDryCacheKey(app core.App, key string) error {
+//
+// eventId is the optional event log entry id assigned to the broadcasted messages.
+func realtimeBroadcastDryCacheKey(app core.App, key string, eventId string) error {
 	chunks := app.SubscriptionsBroker().ChunkedClients(clientsChunkSize)
 	if len(chunks) == 0 {
 		return nil // no subscribers
@@ -795,6 +878,9 @@ func realtimeBroadcastDryCacheKey(app core.App, key string) error {
 
 				routine.FireAndForget(func() {
 					for _, msg := range messages {
+						if eventId != "" {
+							msg.Id = eventId
+						}
 						client.Send(msg)
 					}
 				})
@@ -853,6 +939,9 @@ func isSameAuth(authA, authB *core.Record) bool {
 }
 
 // realtimeCanAccessRecord checks if the subscription client has access to the specified record model.
+//
+// If app is a [realtimeSnapshotApp] of the record, the checks are performed
+// against the record snapshot state instead of the current db row.
 func realtimeCanAccessRecord(
 	app core.App,
 	record *core.Record,
@@ -861,7 +950,17 @@ func realtimeCanAccessRecord(
 ) bool {
 	// check the access rule
 	// ---
-	if ok, _ := app.CanAccessRecord(record, requestInfo, accessRule); !ok {
+	if snapshotApp, ok := app.(*realtimeSnapshotApp); ok && snapshotApp.isSnapshotOf(record) {
+		if !requestInfo.HasSuperuserAuth() {
+			if accessRule == nil {
+				return false
+			}
+
+			if *accessRule != "" && !realtimeRecordMatchFilter(app, record, requestInfo, *accessRule, true) {
+				return false
+			}
+		}
+	} else if ok, _ := app.CanAccessRecord(record, requestInfo, accessRule); !ok {
 		return false
 	}
 
@@ -877,13 +976,24 @@ func realtimeCanAccessRecord(
 		return false
 	}
 
+	return realtimeRecordMatchFilter(app, record, requestInfo, filter, false)
+}
+
+// realtimeRecordMatchFilter checks whether the record db state matches the specified filter.
+func realtimeRecordMatchFilter(
+	app core.App,
+	record *core.Record,
+	requestInfo *core.RequestInfo,
+	filter string,
+	allowHiddenFields bool,
+) bool {
 	var exists int
 
 	q := app.ConcurrentDB().Select("(1)").
 		From(record.Collection().Name).
 		AndWhere(dbx.HashExp{record.Collection().Name + ".id": record.Id})
 
-	resolver := core.NewRecordFieldResolver(app, record.Collection(), requestInfo, false)
+	resolver := core.NewRecordFieldResolver(app, record.Collection(), requestInfo, allowHiddenFields)
 	expr, err := search.FilterData(filter).BuildExpr(resolver)
 	if err != nil {
 		return false
@@ -896,7 +1006,20 @@ func realtimeCanAccessRecord(
 		return false
 	}
 
-	err = q.Limit(1).Row(&exists)
+	q.Limit(1)
+
+	if snapshotApp, ok := app.(*realtimeSnapshotApp); ok && snapshotApp.isSnapshotOf(record) {
+		snapshotQuery, err := snapshotApp.snapshotQuery(q)
+		if err != nil {
+			return false
+		}
+
+		err = snapshotQuery.Row(&exists)
+
+		return err == nil && exists > 0
+	}
+
+	err = q.Row(&exists)
 
 	return err == nil && exists > 0
 }
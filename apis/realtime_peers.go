@@ -0,0 +1,230 @@
+package apis
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// realtimePeerMessage represents a record change notification
+// propagated to the other app instances through the subscriptions broker transport.
+type realtimePeerMessage struct {
+	// Record is the db export of the changed record (without the hidden fields).
+	//
+	// It is used as the record state for the peer broadcast and access checks
+	// because the peer database could be lagging behind (e.g. read replica)
+	// or the record may no longer exist (e.g. because it was deleted).
+	Record       map[string]any `json:"record"`
+	Action       string         `json:"action"`
+	CollectionId string         `json:"collectionId"`
+	RecordId     string         `json:"recordId"`
+}
+
+// bindRealtimePeers registers the handler for the record changes
+// received from the subscriptions broker peers (if any).
+func bindRealtimePeers(app core.App) {
+	app.SubscriptionsBroker().SetPeerHandler(func(payload []byte) {
+		if err := realtimeHandlePeerMessage(app, payload); err != nil {
+			app.Logger().Debug(
+				"Failed to handle realtime peer message",
+				slog.String("error", err.Error()),
+			)
+		}
+	})
+}
+
+// realtimePublishRecord propagates the record change to the subscriptions broker peers.
+//
+// It does nothing if the broker doesn't have a transport.
+func realtimePublishRecord(app core.App, action string, record *core.Record) error {
+	broker := app.SubscriptionsBroker()
+	if broker.Transport() == nil {
+		return nil // no peers
+	}
+
+	exported, err := record.DBExport(app)
+	if err != nil {
+		return err
+	}
+
+	// never send the hidden fields (tokenKey, password, etc.) to the peers
+	for _, f := range record.Collection().Fields {
+		if f.GetHidden() {
+			delete(exported, f.GetName())
+		}
+	}
+
+	payload, err := json.Marshal(realtimePeerMessage{
+		Action:       action,
+		CollectionId: record.Collection().Id,
+		RecordId:     record.Id,
+		Record:       exported,
+	})
+	if err != nil {
+		return err
+	}
+
+	return broker.Publish(payload)
+}
+
+// realtimeHandlePeerMessage broadcasts the received peer record change to the local subscribers.
+//
+// The peer messages are never republished to avoid infinite loops.
+func realtimeHandlePeerMessage(app core.App, payload []byte) error {
+	msg := realtimePeerMessage{}
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return err
+	}
+
+	collection, err := app.FindCachedCollectionByNameOrId(msg.CollectionId)
+	if err != nil {
+		return err
+	}
+
+	record, err := realtimePeerSnapshotRecord(app, collection, msg)
+	if err != nil {
+		return err
+	}
+
+	eventId := realtimeLogRecordEvent(app, msg.Action, record)
+
+	if app.SubscriptionsBroker().TotalClients() > 0 {
+		accessCheckApp := &realtimeSnapshotApp{App: app, record: record}
+
+		err = realtimeBroadcastRecord(app, eventId, msg.Action, record, false, accessCheckApp)
+		if err != nil {
+			return err
+		}
+	}
+
+	// sync the clients auth state
+	if collection.IsAuth() {
+		switch msg.Action {
+		case "update":
+			return realtimeUpdateClientsAuth(app, record)
+		case "delete":
+			return realtimeUnsetClientsAuthByRecordModelOrProxy(app, record)
+		}
+	}
+
+	return nil
+}
+
+// realtimePeerSnapshotRecord loads the peer message record snapshot into a new Record model.
+//
+// Because the hidden fields are not sent with the peer message, their values
+// are loaded from the local db record (if it still exists).
+func realtimePeerSnapshotRecord(app core.App, collection *core.Collection, msg realtimePeerMessage) (*core.Record, error) {
+	if msg.Record == nil {
+		return nil, errors.New("missing peer record snapshot")
+	}
+
+	record := core.NewRecord(collection)
+
+	if local, err := app.FindRecordById(collection, msg.RecordId); err == nil {
+		for _, field := range collection.Fields {
+			if field.GetHidden() {
+				record.SetRaw(field.GetName(), local.GetRaw(field.GetName()))
+			}
+		}
+	}
+
+	for _, field := range collection.Fields {
+		raw, ok := msg.Record[field.GetName()]
+		if !ok || field.GetHidden() {
+			continue
+		}
+
+		value, err := field.PrepareValue(record, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		record.SetRaw(field.GetName(), value)
+	}
+
+	record.Id = msg.RecordId
+
+	return record, nil
+}
+
+// realtimeSnapshotApp is a read-only access check app wrapper that
+// evaluates the realtime API rules and filters of its record against
+// the record snapshot state instead of the current db row.
+//
+// See [realtimeCanAccessRecord].
+type realtimeSnapshotApp struct {
+	core.App
+
+	record *core.Record
+}
+
+// isSnapshotOf reports whether record refers to the snapshot record.
+func (s *realtimeSnapshotApp) isSnapshotOf(record *core.Record) bool {
+	return record.Id == s.record.Id && record.Collection().Id == s.record.Collection().Id
+}
+
+// snapshotQuery converts the provided select query into a query where the
+// snapshot record collection table is shadowed by a common table expression
+// with the same name that combines the snapshot record state with the other db rows.
+//
+// This way the snapshot is "visible" for the query (including its subqueries)
+// without writing anything to the db.
+func (s *realtimeSnapshotApp) snapshotQuery(q *dbx.SelectQuery) (*dbx.Query, error) {
+	collection := s.record.Collection()
+
+	exported, err := s.record.DBExport(s.App)
+	if err != nil {
+		return nil, err
+	}
+
+	built := q.Build()
+
+	params := dbx.Params{}
+	maps.Copy(params, built.Params())
+	params["__snapshotId"] = s.record.Id
+
+	snapshotCols := make([]string, 0, len(exported))
+	tableCols := make([]string, 0, len(exported))
+	for i, field := range collection.Fields {
+		name := field.GetName()
+
+		value, ok := exported[name]
+		if !ok {
+			continue
+		}
+
+		placeholder := "__snapshot" + strconv.Itoa(i)
+		params[placeholder] = value
+
+		snapshotCols = append(snapshotCols, "{:"+placeholder+"} AS [["+name+"]]")
+		tableCols = append(tableCols, "[["+name+"]]")
+	}
+
+	sql := "WITH {{" + collection.Name + "}} AS (" +
+		"SELECT " + strings.Join(snapshotCols, ", ") +
+		" UNION ALL " +
+		"SELECT " + strings.Join(tableCols, ", ") + " FROM {{main." + collection.Name + "}} WHERE [[id]] != {:__snapshotId}" +
+		") " + built.SQL()
+
+	return s.ConcurrentDB().NewQuery(sql).Bind(params), nil
+}
+
+// realtimeResolveDeletedRecord converts *if possible* the provided deleted model to a Record
+// without additional db lookups.
+func realtimeResolveDeletedRecord(model core.Model) *core.Record {
+	switch m := model.(type) {
+	case *core.Record:
+		return m
+	case core.RecordProxy:
+		return m.ProxyRecord()
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package apis_test
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestRealtimePeers(t *testing.T) {
+	t.Parallel()
+
+	const testCollectionName = "realtime_peers_test"
+
+	hub := subscriptions.NewLocalHub()
+
+	// init the app instances (each with its own db copy)
+	instances := make([]*tests.TestApp, 3)
+	for i := range instances {
+		app, err := tests.NewTestApp()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer app.Cleanup()
+
+		// init realtime handlers
+		apis.NewRouter(app)
+
+		app.SubscriptionsBroker().SetTransport(hub.NewTransport())
+
+		collection := core.NewBaseCollection(testCollectionName, "pbc_realtime_peers")
+		collection.Fields.Add(&core.TextField{Name: "title"})
+		collection.Fields.Add(&core.TextField{Name: "secret", Hidden: true})
+		collection.ListRule = types.Pointer("title != 'hidden'")
+		if err := app.Save(collection); err != nil {
+			t.Fatal(err)
+		}
+
+		instances[i] = app
+	}
+
+	// simulate a lagging peer db with an outdated record state
+	// that doesn't satisfy the list rule (the sent snapshot should be used instead)
+	{
+		collection, err := instances[1].FindCollectionByNameOrId(testCollectionName)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lagging := core.NewRecord(collection)
+		lagging.Id = "peersrecord0001"
+		lagging.Set("title", "hidden")
+		if err := instances[1].SaveNoValidate(lagging); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// capture the raw peer payloads
+	var payloadsMu sync.Mutex
+	payloads := [][]byte{}
+	spy := hub.NewTransport()
+	defer spy.Close()
+	spy.Subscribe(func(payload []byte) {
+		payloadsMu.Lock()
+		payloads = append(payloads, payload)
+		payloadsMu.Unlock()
+	})
+
+	clients := make([]subscriptions.Client, len(instances))
+	for i, app := range instances {
+		clients[i] = subscriptions.NewDefaultClient()
+		clients[i].Subscribe(testCollectionName + "/*")
+		app.SubscriptionsBroker().Register(clients[i])
+	}
+
+	var mu sync.Mutex
+	notifications := map[int][]string{}
+
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			timeout := time.After(500 * time.Millisecond)
+
+			for {
+				select {
+				case e, ok := <-client.Channel():
+					if !ok {
+						return
+					}
+
+					data := struct {
+						Action string
+						Record struct{ Title string }
+					}{}
+					_ = json.Unmarshal(e.Data, &data)
+
+					mu.Lock()
+					notifications[i] = append(notifications[i], data.Action+"_"+data.Record.Title)
+					mu.Unlock()
+				case <-timeout:
+					return
+				}
+			}
+		}()
+	}
+
+	// perform the changes only on the first instance
+	app := instances[0]
+
+	collection, err := app.FindCollectionByNameOrId(testCollectionName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hidden := core.NewRecord(collection)
+	hidden.Set("title", "hidden")
+	if err := app.Save(hidden); err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Id = "peersrecord0001"
+	record.Set("title", "a")
+	record.Set("secret", "test_secret")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// wait the create events to be delivered before the update
+	// since the peers messages are processed asynchronously
+	time.Sleep(50 * time.Millisecond)
+
+	record.Set("title", "b")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if err := app.Delete(record); err != nil {
+		t.Fatal(err)
+	}
+
+	wg.Wait()
+
+	expected := []string{"create_a", "update_b", "delete_b"}
+
+	for i := range instances {
+		events := notifications[i]
+		if !slices.Equal(events, expected) {
+			t.Fatalf("[instance %d] Expected events %v, got %v", i, expected, events)
+		}
+	}
+
+	// the peers db should remain unchanged
+	for i, peer := range instances[1:] {
+		expectedTotal := int64(0)
+		if i == 0 {
+			expectedTotal = 1 // the lagging record
+		}
+
+		total, err := peer.CountRecords(testCollectionName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != expectedTotal {
+			t.Fatalf("[peer %d] Expected %d records in the peer db, got %d", i, expectedTotal, total)
+		}
+	}
+
+	// the hidden fields should not be sent to the peers
+	payloadsMu.Lock()
+	defer payloadsMu.Unlock()
+	if len(payloads) == 0 {
+		t.Fatal("Expected at least one peer payload")
+	}
+	for _, payload := range payloads {
+		if strings.Contains(string(payload), "secret") {
+			t.Fatalf("Expected the hidden field to be excluded from the peer payload, got %s", payload)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package apis
+
+import (
+	"log/slog"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+)
+
+// realtimeClientLastEventIdKey is the name of the realtime client store key
+// that holds the last received event id of the reconnected client.
+const realtimeClientLastEventIdKey = "pbRealtimeLastEventId"
+
+// realtimeLogEntry represents the data of a single realtime event log entry.
+type realtimeLogEntry struct {
+	Record *core.Record
+	Action string
+}
+
+// realtimeLogRecordEvent stores the record change in the broker event log
+// and returns the created event log entry id.
+//
+// It returns an empty string if the broker doesn't have an event log.
+func realtimeLogRecordEvent(app core.App, action string, record *core.Record) string {
+	eventLog := app.SubscriptionsBroker().EventLog()
+	if eventLog == nil {
+		return ""
+	}
+
+	entry := eventLog.Add(record.Collection().Id, &realtimeLogEntry{
+		Action: action,
+		Record: record.Fresh(),
+	})
+
+	return entry.Id
+}
+
+// realtimeInitClientReplay marks the client event log position and
+// stores its last received event id (if any) for replaying the missed
+// events with the next subscriptions change.
+//
+// The last event id is resolved from the SSE "Last-Event-ID" header
+// or from the "lastEventId" query parameter (e.g. for WebSocket clients).
+func realtimeInitClientReplay(e *core.RequestEvent, client subscriptions.Client) {
+	eventLog := e.App.SubscriptionsBroker().EventLog()
+	if eventLog == nil {
+		return
+	}
+
+	// allow replaying with the client id in case the client
+	// disconnects before receiving any other message
+	eventLog.Mark(client.Id())
+
+	lastEventId := e.Request.Header.Get("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = e.Request.URL.Query().Get("lastEventId")
+	}
+
+	if lastEventId != "" {
+		client.Set(realtimeClientLastEventIdKey, lastEventId)
+	}
+}
+
+// realtimeMarkClientDisconnect updates the client event log position on disconnect.
+func realtimeMarkClientDisconnect(app core.App, client subscriptions.Client) {
+	eventLog := app.SubscriptionsBroker().EventLog()
+	if eventLog != nil {
+		eventLog.Mark(client.Id())
+	}
+}
+
+// realtimeReplayClientEvents sends to the client the logged record events
+// that it has missed since its last received event id (if any).
+//
+// The events are filtered by the current client subscriptions and
+// access checks the same way as the regular broadcasted ones.
+//
+// Note that it is possible for a client to receive the same event twice
+// if it happened during the replay (the message id could be used to deduplicate it).
+func realtimeReplayClientEvents(app core.App, client subscriptions.Client) {
+	lastEventId, _ := client.Get(realtimeClientLastEventIdKey).(string)
+	if lastEventId == "" {
+		return
+	}
+
+	client.Unset(realtimeClientLastEventIdKey)
+
+	eventLog := app.SubscriptionsBroker().EventLog()
+	if eventLog == nil {
+		return
+	}
+
+	entries, ok := eventLog.Since(lastEventId)
+	if !ok {
+		app.Logger().Debug(
+			"Unable to replay the missed realtime events",
+			slog.String("clientId", client.Id()),
+			slog.String("lastEventId", lastEventId),
+		)
+		return
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	messages := []subscriptions.Message{}
+
+	for _, entry := range entries {
+		data, ok := entry.Data.(*realtimeLogEntry)
+		if !ok {
+			continue
+		}
+
+		// evaluate the access checks against the logged record state
+		// (the record may no longer exist or it could have been changed)
+		accessCheckApp := &realtimeSnapshotApp{App: app, record: data.Record}
+
+		messages = append(messages, realtimeRecordClientMessages(app, accessCheckApp, client, entry.Id, data.Action, data.Record)...)
+	}
+
+	if len(messages) == 0 {
+		return
+	}
+
+	routine.FireAndForget(func() {
+		for _, msg := range messages {
+			client.Send(msg)
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package apis_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestRealtimeReplay(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("realtime_replay_test")
+	collection.Fields.Add(&core.TextField{Name: "title"})
+	collection.ListRule = types.Pointer("title != 'hidden'")
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	router, err := apis.NewRouter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux, err := router.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// simulate a previous connection
+	app.SubscriptionsBroker().EventLog().Mark("old_client_id")
+
+	// changes while the client was disconnected
+	// ---
+	visible := core.NewRecord(collection)
+	visible.Set("title", "a")
+	if err := app.Save(visible); err != nil {
+		t.Fatal(err)
+	}
+
+	hidden := core.NewRecord(collection)
+	hidden.Set("title", "hidden")
+	if err := app.Save(hidden); err != nil {
+		t.Fatal(err)
+	}
+
+	visible.Set("title", "b")
+	if err := app.Save(visible); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(visible); err != nil {
+		t.Fatal(err)
+	}
+
+	// unrelated collection change
+	demo, err := app.FindRecordById("demo2", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	demo.Set("title", "demo_new")
+	if err := app.Save(demo); err != nil {
+		t.Fatal(err)
+	}
+	// ---
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/realtime", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Last-Event-ID", "old_client_id")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	type sseEvent struct {
+		Id   string
+		Name string
+		Data string
+	}
+
+	events := make(chan sseEvent, 10)
+	go func() {
+		defer close(events)
+
+		scanner := bufio.NewScanner(res.Body)
+		event := sseEvent{}
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id:"):
+				event.Id = strings.TrimPrefix(line, "id:")
+			case strings.HasPrefix(line, "event:"):
+				event.Name = strings.TrimPrefix(line, "event:")
+			case strings.HasPrefix(line, "data:"):
+				event.Data = strings.TrimPrefix(line, "data:")
+			case line == "":
+				events <- event
+				event = sseEvent{}
+			}
+		}
+	}()
+
+	receive := func() sseEvent {
+		t.Helper()
+
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatal("Unexpected closed connection")
+			}
+			return e
+		case <-time.After(3 * time.Second):
+			t.Fatal("Timeout waiting for event")
+		}
+
+		return sseEvent{}
+	}
+
+	connectEvent := receive()
+	if connectEvent.Name != "PB_CONNECT" {
+		t.Fatalf("Expected PB_CONNECT event, got %q", connectEvent.Name)
+	}
+
+	subscribeRes, err := http.Post(
+		server.URL+"/api/realtime",
+		"application/json",
+		strings.NewReader(`{"clientId":"`+connectEvent.Id+`","subscriptions":["realtime_replay_test/*"]}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscribeRes.Body.Close()
+
+	if subscribeRes.StatusCode != 204 {
+		t.Fatalf("Expected 204 subscribe response, got %d", subscribeRes.StatusCode)
+	}
+
+	expected := []string{"create_a", "update_b", "delete_b"}
+
+	var lastId string
+	for i, expectedAction := range expected {
+		e := receive()
+
+		if e.Name != "realtime_replay_test/*" {
+			t.Fatalf("[%d] Expected realtime_replay_test/* event, got %q", i, e.Name)
+		}
+
+		if e.Id == "" || e.Id == connectEvent.Id || e.Id == lastId {
+			t.Fatalf("[%d] Expected unique event id, got %q", i, e.Id)
+		}
+		lastId = e.Id
+
+		data := struct {
+			Action string
+			Record struct{ Title string }
+		}{}
+		if err := json.Unmarshal([]byte(e.Data), &data); err != nil {
+			t.Fatal(err)
+		}
+
+		if v := data.Action + "_" + data.Record.Title; v != expectedAction {
+			t.Fatalf("[%d] Expected %q, got %q", i, expectedAction, v)
+		}
+	}
+
+	// the replayed ids could be used as Last-Event-ID
+	entries, ok := app.SubscriptionsBroker().EventLog().Since(lastId)
+	if !ok {
+		t.Fatalf("Expected the last event id %q to be known", lastId)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected only the unrelated demo2 event after the last replayed one, got %d", len(entries))
+	}
+}
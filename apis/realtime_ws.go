@@ -0,0 +1,412 @@
+package apis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"golang.org/x/net/websocket"
+)
+
+// note: the max allowed size of a single client WebSocket message
+// (should be enough for the max 1000 subscriptions with 2500 chars each)
+const realtimeWSMaxPayloadBytes = 3 << 20
+
+// WebSocket client message types.
+const (
+	realtimeWSMessageAuth        = "auth"
+	realtimeWSMessageSubscribe   = "subscribe"
+	realtimeWSMessageUnsubscribe = "unsubscribe"
+)
+
+// realtimeSubscribeRateLimitLabels are the default rate limit labels of the SSE
+// subscribe request that are applied also to the WebSocket subscribe messages.
+var realtimeSubscribeRateLimitLabels = []string{http.MethodPost + " /api/realtime", "/api/realtime"}
+
+// realtimeWSClientMessage represents a single message sent by the WebSocket client.
+type realtimeWSClientMessage struct {
+	Type          string   `json:"type"`
+	Token         string   `json:"token"`
+	Subscriptions []string `json:"subscriptions"`
+}
+
+func (m *realtimeWSClientMessage) validate() error {
+	return validation.ValidateStruct(m,
+		validation.Field(&m.Type, validation.Required, validation.In(
+			realtimeWSMessageAuth,
+			realtimeWSMessageSubscribe,
+			realtimeWSMessageUnsubscribe,
+		)),
+		validation.Field(&m.Token, validation.When(m.Type == realtimeWSMessageAuth, validation.Required)),
+		validation.Field(&m.Subscriptions,
+			validation.Length(0, 1000),
+			validation.Each(validation.Length(0, 2500)),
+		),
+	)
+}
+
+// realtimeWSServerMessage represents a single message sent to the WebSocket client.
+type realtimeWSServerMessage struct {
+	Id   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Data json.RawMessage `json:"data"`
+}
+
+// realtimeConnectWebSocket handles the realtime WebSocket connection.
+//
+// It works similarly to the SSE [realtimeConnect] handler with the difference
+// that the auth state and subscriptions are managed with messages sent over the same socket:
+//
+//	{"type": "auth", "token": "..."}
+//	{"type": "subscribe", "subscriptions": ["collection/*", ...]}   // replaces all existing subscriptions
+//	{"type": "unsubscribe", "subscriptions": ["collection/*", ...]} // removes the specified (or all if empty) subscriptions
+//
+// Each client message is acknowledged with PB_AUTH, PB_SUBSCRIBE or PB_UNSUBSCRIBE
+// message on success and with PB_ERROR message on failure.
+//
+// Both the subscribe and unsubscribe messages trigger the OnRealtimeSubscribeRequest hook
+// (for the unsubscribe message the event subscriptions are the remaining ones).
+//
+// The subscribe messages are rate limited the same way as the SSE subscribe requests.
+func realtimeConnectWebSocket(e *core.RequestEvent) error {
+	if !strings.EqualFold(e.Request.Header.Get("Upgrade"), "websocket") {
+		return e.BadRequestError("Missing or invalid WebSocket upgrade request.", nil)
+	}
+
+	// disable global write deadline for the WebSocket connection
+	rc := http.NewResponseController(e.Response)
+	writeDeadlineErr := rc.SetWriteDeadline(time.Time{})
+	if writeDeadlineErr != nil && !errors.Is(writeDeadlineErr, http.ErrNotSupported) {
+		return e.InternalServerError("Failed to initialize WebSocket connection.", writeDeadlineErr)
+	}
+
+	// create cancellable request
+	cancelCtx, cancelRequest := context.WithCancel(e.Request.Context())
+	defer cancelRequest()
+	e.Request = e.Request.Clone(cancelCtx)
+
+	connectEvent := new(core.RealtimeConnectRequestEvent)
+	connectEvent.RequestEvent = e
+	connectEvent.IdleTimeout = 5 * time.Minute
+	connectEvent.MaxTimeout = 30 * time.Minute
+	connectEvent.Client = subscriptions.NewDefaultClient()
+
+	// could be used as an optional cross-reference check in other API endpoints
+	connectEvent.Client.Set(RealtimeClientIPKey, e.RealIP())
+
+	// allow authenticating directly with the upgrade request (e.g. for non-browser clients)
+	if e.Auth != nil {
+		connectEvent.Client.Set(RealtimeClientAuthKey, e.Auth)
+	}
+
+	return e.App.OnRealtimeConnectRequest().Trigger(connectEvent, func(ce *core.RealtimeConnectRequestEvent) error {
+		server := websocket.Server{
+			// the realtime clients are authenticated with explicit auth
+			// messages (aka. there is no ambient authority) so allow any origin
+			Handshake: func(config *websocket.Config, r *http.Request) error {
+				return nil
+			},
+			Handler: func(conn *websocket.Conn) {
+				conn.MaxPayloadBytes = realtimeWSMaxPayloadBytes
+				conn.SetDeadline(time.Time{})
+
+				realtimeServeWebSocket(ce, conn, cancelRequest)
+			},
+		}
+
+		server.ServeHTTP(ce.Response, ce.Request)
+
+		return nil
+	})
+}
+
+func realtimeServeWebSocket(ce *core.RealtimeConnectRequestEvent, conn *websocket.Conn, cancelRequest context.CancelFunc) {
+	// register new subscription client
+	ce.App.SubscriptionsBroker().Register(ce.Client)
+	defer func() {
+		ce.App.SubscriptionsBroker().Unregister(ce.Client.Id())
+		realtimeMarkClientDisconnect(ce.App, ce.Client)
+	}()
+
+	realtimeInitClientReplay(ce.RequestEvent, ce.Client)
+
+	ce.App.Logger().Debug("Realtime WebSocket connection established", slog.String("clientId", ce.Client.Id()))
+
+	send := func(msg *subscriptions.Message) error {
+		msgEvent := new(core.RealtimeMessageEvent)
+		msgEvent.RequestEvent = realtimeWSRequestEvent(ce)
+		msgEvent.Client = ce.Client
+		msgEvent.Message = msg
+
+		return ce.App.OnRealtimeMessageSend().Trigger(msgEvent, func(me *core.RealtimeMessageEvent) error {
+			return websocket.JSON.Send(conn, realtimeWSServerMessage{
+				Id:   me.Message.Id,
+				Name: me.Message.Name,
+				Data: me.Message.Data,
+			})
+		})
+	}
+
+	// signalize established connection (aka. fire "connect" message)
+	connectMsgErr := send(&subscriptions.Message{
+		Name: "PB_CONNECT",
+		Data: []byte(`{"clientId":"` + ce.Client.Id() + `"}`),
+	})
+	if connectMsgErr != nil {
+		ce.App.Logger().Debug(
+			"Realtime WebSocket connection closed (failed to deliver PB_CONNECT)",
+			slog.String("clientId", ce.Client.Id()),
+			slog.String("error", connectMsgErr.Error()),
+		)
+		return
+	}
+
+	// read the client messages in a separate goroutine
+	// (the replies are sent through the client channel so that there is a single writer)
+	received := make(chan struct{})
+	go func() {
+		defer cancelRequest()
+
+		for {
+			msg := realtimeWSClientMessage{}
+
+			err := websocket.JSON.Receive(conn, &msg)
+			if err != nil {
+				if !errors.Is(err, io.EOF) && !errors.Is(err, context.Canceled) {
+					var syntaxErr *json.SyntaxError
+					var typeErr *json.UnmarshalTypeError
+					if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+						ce.Client.Send(*realtimeWSErrorMessage("", err))
+						continue
+					}
+				}
+				return
+			}
+
+			select {
+			case received <- struct{}{}:
+			default:
+			}
+
+			if reply := realtimeHandleWSClientMessage(ce, &msg); reply != nil {
+				ce.Client.Send(*reply)
+			}
+		}
+	}()
+
+	// start a max lifetime timer to prevent accumulating too much
+	// connection resources and to allow the GC to run more regularly
+	maxTimer := time.NewTimer(ce.MaxTimeout)
+	defer maxTimer.Stop()
+
+	// start an idle timer to keep track of inactive/forgotten connections
+	idleTimer := time.NewTimer(ce.IdleTimeout)
+	defer idleTimer.Stop()
+
+	for {
+		select {
+		case <-maxTimer.C:
+			cancelRequest()
+		case <-idleTimer.C:
+			cancelRequest()
+		case <-received:
+			idleTimer.Stop()
+			idleTimer.Reset(ce.IdleTimeout)
+		case msg, ok := <-ce.Client.Channel():
+			if !ok {
+				// channel is closed
+				ce.App.Logger().Debug(
+					"Realtime WebSocket connection closed (closed channel)",
+					slog.String("clientId", ce.Client.Id()),
+				)
+				return
+			}
+
+			if err := send(&msg); err != nil {
+				ce.App.Logger().Debug(
+					"Realtime WebSocket connection closed (failed to deliver message)",
+					slog.String("clientId", ce.Client.Id()),
+					slog.String("error", err.Error()),
+				)
+				return
+			}
+
+			idleTimer.Stop()
+			idleTimer.Reset(ce.IdleTimeout)
+		case <-ce.Request.Context().Done():
+			// connection is closed
+			ce.App.Logger().Debug(
+				"Realtime WebSocket connection closed (cancelled request)",
+				slog.String("clientId", ce.Client.Id()),
+			)
+			return
+		}
+	}
+}
+
+// realtimeHandleWSClientMessage processes a single WebSocket client message
+// and returns the reply message that should be sent back to the client.
+func realtimeHandleWSClientMessage(ce *core.RealtimeConnectRequestEvent, msg *realtimeWSClientMessage) *subscriptions.Message {
+	if err := msg.validate(); err != nil {
+		return realtimeWSErrorMessage(msg.Type, err)
+	}
+
+	// note: a new request event is created for each message because the client
+	// auth state could be changed meanwhile by the auth record hooks
+	e := realtimeWSRequestEvent(ce)
+
+	switch msg.Type {
+	case realtimeWSMessageAuth:
+		auth, err := e.App.FindAuthRecordByToken(msg.Token, core.TokenTypeAuth)
+		if err != nil {
+			return realtimeWSErrorMessage(msg.Type, errors.New("invalid or expired auth token"))
+		}
+
+		// for now allow only guest->auth upgrades and any other auth change is forbidden
+		if e.Auth != nil && !isSameAuth(e.Auth, auth) {
+			return realtimeWSErrorMessage(msg.Type, errors.New("the current and the new authorization don't match"))
+		}
+
+		ce.Client.Set(RealtimeClientAuthKey, auth)
+
+		return realtimeWSReplyMessage("PB_AUTH", map[string]any{"collectionId": auth.Collection().Id, "id": auth.Id})
+	case realtimeWSMessageSubscribe:
+		if err := checkRealtimeWSSubscribeRateLimit(e); err != nil {
+			return realtimeWSErrorMessage(msg.Type, err)
+		}
+
+		subs, ok, err := realtimeWSSetSubscriptions(e, ce.Client, msg.Subscriptions)
+		if err != nil {
+			return realtimeWSErrorMessage(msg.Type, err)
+		}
+		if !ok {
+			return nil
+		}
+
+		return realtimeWSReplyMessage("PB_SUBSCRIBE", map[string]any{"subscriptions": subs})
+	case realtimeWSMessageUnsubscribe:
+		// the remaining subscriptions (or none if no subscriptions are specified)
+		remaining := []string{}
+		if len(msg.Subscriptions) > 0 {
+			for sub := range ce.Client.Subscriptions() {
+				if !slices.Contains(msg.Subscriptions, sub) {
+					remaining = append(remaining, sub)
+				}
+			}
+			slices.Sort(remaining)
+		}
+
+		_, ok, err := realtimeWSSetSubscriptions(e, ce.Client, remaining)
+		if err != nil {
+			return realtimeWSErrorMessage(msg.Type, err)
+		}
+		if !ok {
+			return nil
+		}
+
+		return realtimeWSReplyMessage("PB_UNSUBSCRIBE", map[string]any{"subscriptions": msg.Subscriptions})
+	}
+
+	return nil
+}
+
+// realtimeWSRequestEvent returns a new request event for a single WebSocket
+// client message with the current client auth state.
+//
+// The connect request event is shared between the connection goroutines
+// and must not be modified.
+func realtimeWSRequestEvent(ce *core.RealtimeConnectRequestEvent) *core.RequestEvent {
+	e := new(core.RequestEvent)
+	e.App = ce.App
+	e.APIKey = ce.APIKey
+	e.Request = ce.Request
+	e.Response = ce.Response
+	e.Auth, _ = ce.Client.Get(RealtimeClientAuthKey).(*core.Record)
+
+	return e
+}
+
+// realtimeWSSetSubscriptions replaces the client subscriptions
+// through the OnRealtimeSubscribeRequest hook (similar to the SSE [realtimeSetSubscriptions]).
+//
+// Returns the applied subscriptions and whether the default hook handler was reached.
+func realtimeWSSetSubscriptions(e *core.RequestEvent, client subscriptions.Client, subs []string) ([]string, bool, error) {
+	event := new(core.RealtimeSubscribeRequestEvent)
+	event.RequestEvent = e
+	event.Client = client
+	event.Subscriptions = subs
+
+	var applied bool
+
+	err := e.App.OnRealtimeSubscribeRequest().Trigger(event, func(e *core.RealtimeSubscribeRequestEvent) error {
+		applied = true
+
+		// update auth state
+		e.Client.Set(RealtimeClientAuthKey, e.Auth)
+
+		// unsubscribe from any previous existing subscriptions
+		e.Client.Unsubscribe()
+
+		// subscribe to the new subscriptions
+		e.Client.Subscribe(e.Subscriptions...)
+
+		// send the missed events (if the client has reconnected)
+		realtimeReplayClientEvents(e.App, e.Client)
+
+		e.App.Logger().Debug(
+			"Realtime WebSocket subscriptions updated",
+			slog.String("clientId", e.Client.Id()),
+			slog.Any("subscriptions", e.Subscriptions),
+		)
+
+		return nil
+	})
+
+	return event.Subscriptions, applied, err
+}
+
+// checkRealtimeWSSubscribeRateLimit checks a single WebSocket subscribe message
+// against the same rate limit rule (and counter) as the SSE subscribe request.
+//
+// The WebSocket messages are sent over the already established connection
+// and are not handled by the global rate limit middleware.
+func checkRealtimeWSSubscribeRateLimit(e *core.RequestEvent) error {
+	if skipRateLimit(e) {
+		return nil
+	}
+
+	rule, ok := e.App.Settings().RateLimits.FindRateLimitRule(
+		realtimeSubscribeRateLimitLabels,
+		defaultRateLimitAudience(e)...,
+	)
+	if !ok {
+		return nil
+	}
+
+	return checkRateLimit(e, rule.Label+rule.Audience, rule)
+}
+
+func realtimeWSReplyMessage(name string, data any) *subscriptions.Message {
+	raw, _ := json.Marshal(data)
+
+	return &subscriptions.Message{
+		Name: name,
+		Data: raw,
+	}
+}
+
+func realtimeWSErrorMessage(msgType string, err error) *subscriptions.Message {
+	return realtimeWSReplyMessage("PB_ERROR", map[string]any{
+		"type":    msgType,
+		"message": err.Error(),
+	})
+}
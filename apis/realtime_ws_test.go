@@ -0,0 +1,286 @@
+package apis_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"golang.org/x/net/websocket"
+)
+
+func TestRealtimeWebSocketInvalidUpgrade(t *testing.T) {
+	t.Parallel()
+
+	scenario := tests.ApiScenario{
+		Name:            "non-upgrade request",
+		Method:          http.MethodGet,
+		URL:             "/api/realtime/ws",
+		ExpectedStatus:  400,
+		ExpectedContent: []string{`"data":{}`},
+		ExpectedEvents:  map[string]int{"*": 0},
+	}
+
+	scenario.Test(t)
+}
+
+func TestRealtimeWebSocket(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("realtime_ws_test")
+	collection.Fields.Add(&core.TextField{Name: "title"})
+	collection.ListRule = types.Pointer("@request.auth.id != ''")
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := user.NewAuthToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type subscribeRequest struct {
+		auth          *core.Record
+		subscriptions []string
+	}
+	var subscribeRequestsMu sync.Mutex
+	var subscribeRequests []subscribeRequest
+	app.OnRealtimeSubscribeRequest().BindFunc(func(e *core.RealtimeSubscribeRequestEvent) error {
+		subscribeRequestsMu.Lock()
+		subscribeRequests = append(subscribeRequests, subscribeRequest{e.Auth, e.Subscriptions})
+		subscribeRequestsMu.Unlock()
+		return e.Next()
+	})
+
+	router, err := apis.NewRouter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux, err := router.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	conn, err := websocket.Dial(strings.Replace(server.URL, "http", "ws", 1)+"/api/realtime/ws", "", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	type message struct {
+		Name string
+		Data map[string]any
+	}
+
+	receive := func(expectedName string) message {
+		t.Helper()
+
+		conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+
+		msg := message{}
+		if err := websocket.JSON.Receive(conn, &msg); err != nil {
+			t.Fatalf("Failed to receive %q message: %v", expectedName, err)
+		}
+
+		if msg.Name != expectedName {
+			t.Fatalf("Expected %q message, got %q (%v)", expectedName, msg.Name, msg.Data)
+		}
+
+		return msg
+	}
+
+	send := func(raw string) {
+		t.Helper()
+
+		if err := websocket.Message.Send(conn, raw); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	createRecord := func(title string) {
+		t.Helper()
+
+		record := core.NewRecord(collection)
+		record.Set("title", title)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	connectMsg := receive("PB_CONNECT")
+	if v, _ := connectMsg.Data["clientId"].(string); v == "" {
+		t.Fatal("Expected non-empty clientId")
+	}
+
+	// invalid messages
+	send(`invalid`)
+	receive("PB_ERROR")
+	send(`{"type":"missing"}`)
+	receive("PB_ERROR")
+	send(`{"type":"auth","token":"invalid"}`)
+	receive("PB_ERROR")
+
+	// guest subscription
+	send(`{"type":"subscribe","subscriptions":["realtime_ws_test/*"]}`)
+	receive("PB_SUBSCRIBE")
+	createRecord("guest")
+
+	// auth and resubscribe
+	send(`{"type":"auth","token":"` + token + `"}`)
+	authMsg := receive("PB_AUTH")
+	if v, _ := authMsg.Data["id"].(string); v != user.Id {
+		t.Fatalf("Expected auth id %q, got %q", user.Id, v)
+	}
+
+	send(`{"type":"subscribe","subscriptions":["realtime_ws_test/*"]}`)
+	receive("PB_SUBSCRIBE")
+
+	createRecord("auth")
+
+	// the guest create event shouldn't have been delivered
+	recordMsg := receive("realtime_ws_test/*")
+	if v, _ := recordMsg.Data["action"].(string); v != "create" {
+		t.Fatalf("Expected create action, got %q", v)
+	}
+	record, _ := recordMsg.Data["record"].(map[string]any)
+	if v, _ := record["title"].(string); v != "auth" {
+		t.Fatalf("Expected record with title %q, got %q", "auth", v)
+	}
+
+	// unsubscribe
+	send(`{"type":"unsubscribe","subscriptions":["realtime_ws_test/*"]}`)
+	receive("PB_UNSUBSCRIBE")
+
+	createRecord("unsubscribed")
+
+	// the next message should be the error reply and not the create event
+	send(`{"type":"missing"}`)
+	errMsg := receive("PB_ERROR")
+	raw, _ := json.Marshal(errMsg.Data)
+	if !strings.Contains(string(raw), `"type":"missing"`) {
+		t.Fatalf("Expected the error message to contain the message type, got %s", raw)
+	}
+
+	subscribeRequestsMu.Lock()
+	defer subscribeRequestsMu.Unlock()
+
+	// guest subscribe, auth subscribe and unsubscribe
+	if len(subscribeRequests) != 3 {
+		t.Fatalf("Expected 3 OnRealtimeSubscribeRequest calls, got %d", len(subscribeRequests))
+	}
+
+	if subscribeRequests[0].auth != nil {
+		t.Fatalf("Expected the 1st subscribe request to be guest, got %v", subscribeRequests[0].auth)
+	}
+
+	for i, r := range subscribeRequests[1:] {
+		if r.auth == nil || r.auth.Id != user.Id {
+			t.Fatalf("[%d] Expected the subscribe request auth to be %q, got %v", i+1, user.Id, r.auth)
+		}
+	}
+
+	if len(subscribeRequests[2].subscriptions) != 0 {
+		t.Fatalf("Expected no remaining subscriptions after unsubscribe, got %v", subscribeRequests[2].subscriptions)
+	}
+}
+
+func TestRealtimeWebSocketSubscribeRateLimit(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().RateLimits.Enabled = true
+	app.Settings().RateLimits.Rules = []core.RateLimitRule{
+		{MaxRequests: 2, Label: "POST /api/realtime", Duration: 60},
+		{MaxRequests: 100, Label: "/api/", Duration: 60},
+	}
+
+	router, err := apis.NewRouter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux, err := router.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	conn, err := websocket.Dial(strings.Replace(server.URL, "http", "ws", 1)+"/api/realtime/ws", "", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	type message struct {
+		Name string
+		Data map[string]any
+	}
+
+	receive := func(expectedName string) message {
+		t.Helper()
+
+		conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+
+		msg := message{}
+		if err := websocket.JSON.Receive(conn, &msg); err != nil {
+			t.Fatalf("Failed to receive %q message: %v", expectedName, err)
+		}
+
+		if msg.Name != expectedName {
+			t.Fatalf("Expected %q message, got %q (%v)", expectedName, msg.Name, msg.Data)
+		}
+
+		return msg
+	}
+
+	receive("PB_CONNECT")
+
+	for i := 0; i < 2; i++ {
+		if err := websocket.Message.Send(conn, `{"type":"subscribe","subscriptions":["demo1"]}`); err != nil {
+			t.Fatal(err)
+		}
+		receive("PB_SUBSCRIBE")
+	}
+
+	if err := websocket.Message.Send(conn, `{"type":"subscribe","subscriptions":["demo1"]}`); err != nil {
+		t.Fatal(err)
+	}
+	errMsg := receive("PB_ERROR")
+	if v, _ := errMsg.Data["type"].(string); v != "subscribe" {
+		t.Fatalf("Expected subscribe error message, got %v", errMsg.Data)
+	}
+
+	// the SSE subscribe request shares the same rate limit
+	res, err := http.Post(server.URL+"/api/realtime", "application/json", strings.NewReader(`{"clientId":"missing"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected the SSE subscribe request to be rate limited, got status %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,120 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// recordsAggregate handles the records aggregate API request
+// (e.g. "?groupBy=status&aggregate=count(),sum(total)&filter=...").
+//
+// The collection list rule is applied the same way as in the records list API.
+func recordsAggregate(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	err = checkAPIKeyScope(e, collection, core.APIKeyScopeActionList)
+	if err != nil {
+		return err
+	}
+
+	err = checkCollectionRateLimit(e, collection, "list")
+	if err != nil {
+		return err
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	if collection.ListRule == nil && !requestInfo.HasSuperuserAuth() {
+		return e.ForbiddenError("Only superusers can perform this action.", nil)
+	}
+
+	// forbid users and guests to query special filter/sort/groupBy/aggregate fields
+	err = checkForSuperuserOnlyRuleFields(requestInfo)
+	if err != nil {
+		return err
+	}
+
+	query := e.App.RecordQuery(collection)
+
+	fieldsResolver := core.NewRecordFieldResolver(e.App, collection, requestInfo, true)
+
+	if !requestInfo.HasSuperuserAuth() && collection.ListRule != nil && *collection.ListRule != "" {
+		expr, err := search.FilterData(*collection.ListRule).BuildExpr(fieldsResolver)
+		if err != nil {
+			return err
+		}
+		query.AndWhere(expr)
+
+		// will be applied by the search provider right before executing the query
+		// fieldsResolver.UpdateQuery(query)
+	}
+
+	// hidden fields are searchable only by superusers
+	fieldsResolver.SetAllowHiddenFields(requestInfo.HasSuperuserAuth())
+
+	searchProvider := search.NewProvider(fieldsResolver).Query(query)
+
+	// use rowid when available to minimize the need of a covering index with the "id" field
+	if !collection.IsView() {
+		searchProvider.CountCol("_rowid_")
+	}
+
+	result, err := searchProvider.ParseAndExecAggregate(e.Request.URL.Query().Encode())
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	event := new(core.RecordsAggregateRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Result = result
+
+	return e.App.OnRecordsAggregateRequest().Trigger(event, func(e *core.RecordsAggregateRequestEvent) error {
+		// Add a randomized throttle in case of too many empty search filter attempts
+		// (see the related recordsList comment for more details).
+		if !e.HasSuperuserAuth() &&
+			(collection.ListRule != nil && *collection.ListRule != "") &&
+			(requestInfo.Query["filter"] != "") &&
+			isEmptyAggregateResult(e.Result) &&
+			checkRateLimit(e.RequestEvent, "@pb_list_timing_check_"+collection.Id, listTimingRateLimitRule) != nil {
+			e.App.Logger().Debug("Randomized throttle because of too many failed aggregate searches", "collectionId", collection.Id)
+			randomizedThrottle(500)
+		}
+
+		return execAfterSuccessTx(true, e.App, func() error {
+			return e.JSON(http.StatusOK, e.Result)
+		})
+	})
+}
+
+// isEmptyAggregateResult reports whether the aggregate result
+// doesn't have any matching rows.
+//
+// Because the aggregate query without group by expressions always returns
+// a single row, it is considered empty if all of its values are
+// either NULL or zero (aka. the empty set COUNT, SUM, AVG, etc. values).
+func isEmptyAggregateResult(result *search.Result) bool {
+	items, _ := result.Items.([]map[string]any)
+
+	switch len(items) {
+	case 0:
+		return true
+	case 1:
+		for _, v := range items[0] {
+			if v != nil && v != int64(0) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,169 @@
+package apis
+
+import (
+	"errors"
+	"net/http"
+
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// scopedAPIKeyStoreKey is the request event store key of the
+// loaded but not yet authorized scoped API key auth state.
+const scopedAPIKeyStoreKey = "@pbScopedAPIKey"
+
+type scopedAPIKeyAuth struct {
+	record *core.Record
+	apiKey *core.APIKey
+}
+
+// loadAPIKeyAuth attempts to load the auth context from the provided plain API key.
+//
+// The auth state of API keys without scopes is loaded directly.
+//
+// The auth state of API keys with scopes is deferred until an explicit
+// checkAPIKeyScope call from a collection records handler so that the key
+// is treated as guest for all routes that are not covered by its scopes.
+func loadAPIKeyAuth(e *core.RequestEvent, key string) {
+	record, apiKey, err := e.App.FindAuthRecordByAPIKey(key)
+	if err != nil {
+		e.App.Logger().Debug("loadAPIKeyAuth failure", "error", err)
+		return
+	}
+
+	if allowedIPs := apiKey.AllowedIPs(); len(allowedIPs) > 0 && !isIPInList(allowedIPs, e.RealIP()) {
+		e.App.Logger().Debug("loadAPIKeyAuth failure", "error", errors.New("the API key is not allowed for the current IP"), "apiKeyId", apiKey.Id)
+		return
+	}
+
+	if len(apiKey.Scopes()) > 0 {
+		e.Set(scopedAPIKeyStoreKey, &scopedAPIKeyAuth{record: record, apiKey: apiKey})
+		return
+	}
+
+	e.Auth = record
+	e.APIKey = apiKey
+}
+
+// checkAPIKeyScope checks whether the scoped API key of the current request (if any)
+// allows the specified collection action and loads its auth state.
+func checkAPIKeyScope(e *core.RequestEvent, collection *core.Collection, action string) error {
+	if e.Auth != nil {
+		return nil
+	}
+
+	scoped, _ := e.Get(scopedAPIKeyStoreKey).(*scopedAPIKeyAuth)
+	if scoped == nil {
+		return nil
+	}
+
+	if !scoped.apiKey.HasScope(collection.Name, action) {
+		return e.ForbiddenError("The API key is not allowed to perform this action.", nil)
+	}
+
+	e.Auth = scoped.record
+	e.APIKey = scoped.apiKey
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type apiKeyForm struct {
+	Name       string         `form:"name" json:"name"`
+	Expires    types.DateTime `form:"expires" json:"expires"`
+	AllowedIPs []string       `form:"allowedIPs" json:"allowedIPs"`
+	Scopes     []string       `form:"scopes" json:"scopes"`
+}
+
+func (form *apiKeyForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&form.Expires, validation.By(func(value any) error {
+			v, _ := value.(types.DateTime)
+			if !v.IsZero() && !v.Time().After(types.NowDateTime().Time()) {
+				return validation.NewError("validation_expires_in_the_past", "The expiration date must be in the future.")
+			}
+			return nil
+		})),
+	)
+}
+
+func recordListAPIKeys(e *core.RequestEvent) error {
+	record := e.Auth
+	if record == nil {
+		return e.UnauthorizedError("The request requires valid record authorization token.", nil)
+	}
+
+	apiKeys, err := e.App.FindAllAPIKeysByRecord(record)
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to load the API keys.", err))
+	}
+
+	return e.JSON(http.StatusOK, apiKeys)
+}
+
+func recordCreateAPIKey(e *core.RequestEvent) error {
+	record := e.Auth
+	if record == nil {
+		return e.UnauthorizedError("The request requires valid record authorization token.", nil)
+	}
+
+	// prevent API keys to be used for generating other (possibly less restricted) keys
+	if e.APIKey != nil {
+		return e.ForbiddenError("API keys can't be used to manage other API keys.", nil)
+	}
+
+	form := &apiKeyForm{}
+	if err := e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err := form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	apiKey := core.NewAPIKey(e.App)
+	apiKey.SetCollectionRef(record.Collection().Id)
+	apiKey.SetRecordRef(record.Id)
+	apiKey.SetName(form.Name)
+	apiKey.SetExpires(form.Expires)
+	apiKey.SetAllowedIPs(form.AllowedIPs)
+	apiKey.SetScopes(form.Scopes)
+
+	plainKey, err := apiKey.GenerateKey()
+	if err != nil {
+		return e.InternalServerError("Failed to generate API key.", err)
+	}
+
+	if err = e.App.Save(apiKey); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to create API key.", err))
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"apiKey": apiKey,
+		"key":    plainKey,
+	})
+}
+
+func recordDeleteAPIKey(e *core.RequestEvent) error {
+	record := e.Auth
+	if record == nil {
+		return e.UnauthorizedError("The request requires valid record authorization token.", nil)
+	}
+
+	if e.APIKey != nil {
+		return e.ForbiddenError("API keys can't be used to manage other API keys.", nil)
+	}
+
+	apiKey, err := e.App.FindAPIKeyById(e.Request.PathValue("id"))
+	if err != nil || apiKey.CollectionRef() != record.Collection().Id || apiKey.RecordRef() != record.Id {
+		return e.NotFoundError("Missing or invalid API key.", err)
+	}
+
+	if err = e.App.Delete(apiKey); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to delete API key.", err))
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
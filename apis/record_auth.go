@@ -5,6 +5,11 @@ import (
 	"github.com/pocketbase/pocketbase/tools/router"
 )
 
+// authStatesMaxRequestsPerMinute is the max allowed number of requests
+// per minute from a single IP for the unauthenticated routes that
+// persist short-lived auth states (see builtinRateLimit).
+const authStatesMaxRequestsPerMinute = 60
+
 // bindRecordAuthApi registers the auth record api endpoints and
 // the corresponding handlers.
 func bindRecordAuthApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
@@ -43,6 +48,77 @@ func bindRecordAuthApi(app core.App, rg *router.RouterGroup[*core.RequestEvent])
 		collectionPathRateLimit("", "authWithOTP", "auth"),
 	)
 
+	sub.POST("/request-webauthn-registration", recordRequestWebAuthnRegistration).Bind(
+		collectionPathRateLimit("", "requestWebAuthnRegistration"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.POST("/confirm-webauthn-registration", recordConfirmWebAuthnRegistration).Bind(
+		collectionPathRateLimit("", "confirmWebAuthnRegistration"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.POST("/request-webauthn-auth", recordRequestWebAuthnAuth).Bind(
+		collectionPathRateLimit("", "requestWebAuthnAuth"),
+		builtinRateLimit("requestWebAuthnAuth", authStatesMaxRequestsPerMinute, 60),
+	)
+	sub.POST("/auth-with-webauthn", recordAuthWithWebAuthn).Bind(
+		collectionPathRateLimit("", "authWithWebAuthn", "auth"),
+	)
+
+	sub.POST("/request-totp-setup", recordRequestTOTPSetup).Bind(
+		collectionPathRateLimit("", "requestTOTPSetup"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.POST("/confirm-totp-setup", recordConfirmTOTPSetup).Bind(
+		collectionPathRateLimit("", "confirmTOTPSetup"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.POST("/disable-totp", recordDisableTOTP).Bind(
+		collectionPathRateLimit("", "disableTOTP"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.POST("/auth-with-totp", recordAuthWithTOTP).Bind(
+		collectionPathRateLimit("", "authWithTOTP", "auth"),
+	)
+
+	sub.POST("/auth-with-ldap", recordAuthWithLDAP).Bind(
+		collectionPathRateLimit("", "authWithLDAP", "auth"),
+	)
+
+	sub.GET("/saml/metadata", recordSAMLMetadata)
+	sub.GET("/saml/login", recordSAMLLogin).Bind(
+		collectionPathRateLimit("", "samlLogin"),
+		builtinRateLimit("samlLogin", authStatesMaxRequestsPerMinute, 60),
+	)
+	sub.POST("/saml/acs", recordSAMLACS).Bind(
+		collectionPathRateLimit("", "samlACS"),
+		builtinRateLimit("samlACS", authStatesMaxRequestsPerMinute, 60),
+	)
+	sub.POST("/auth-with-saml", recordAuthWithSAML).Bind(
+		collectionPathRateLimit("", "authWithSAML", "auth"),
+	)
+
+	sub.GET("/api-keys", recordListAPIKeys).Bind(
+		collectionPathRateLimit("", "listAPIKeys"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.POST("/api-keys", recordCreateAPIKey).Bind(
+		collectionPathRateLimit("", "createAPIKey"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.DELETE("/api-keys/{id}", recordDeleteAPIKey).Bind(
+		collectionPathRateLimit("", "deleteAPIKey"),
+		RequireSameCollectionContextAuth(""),
+	)
+
+	sub.GET("/auth-sessions", recordListAuthSessions).Bind(
+		collectionPathRateLimit("", "listAuthSessions"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.DELETE("/auth-sessions/{id}", recordRevokeAuthSession).Bind(
+		collectionPathRateLimit("", "revokeAuthSession"),
+		RequireSameCollectionContextAuth(""),
+	)
+
 	sub.POST("/request-password-reset", recordRequestPasswordReset).Bind(
 		collectionPathRateLimit("", "requestPasswordReset"),
 	)
@@ -66,6 +142,7 @@ func bindRecordAuthApi(app core.App, rg *router.RouterGroup[*core.RequestEvent])
 	)
 
 	sub.POST("/impersonate/{id}", recordAuthImpersonate).Bind(RequireSuperuserAuth())
+	sub.POST("/unlock/{id}", recordAuthUnlock).Bind(RequireSuperuserAuth())
 }
 
 func findAuthCollection(e *core.RequestEvent) (*core.Collection, error) {
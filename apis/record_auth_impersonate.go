@@ -32,12 +32,12 @@ func recordAuthImpersonate(e *core.RequestEvent) error {
 		return e.BadRequestError("An error occurred while validating the submitted data.", err)
 	}
 
-	token, err := record.NewStaticAuthToken(time.Duration(form.Duration) * time.Second)
+	token, err := newAuthToken(e.App, record, time.Duration(form.Duration)*time.Second, false, "")
 	if err != nil {
 		return e.InternalServerError("Failed to generate static auth token", err)
 	}
 
-	return recordAuthResponse(e, record, token, "", nil)
+	return recordAuthResponse(e, record, token, "", nil, nil)
 }
 
 // -------------------------------------------------------------------
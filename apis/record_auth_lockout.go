@@ -0,0 +1,287 @@
+package apis
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// recordAuthUnlock handles the superuser request to clear the
+// failed password attempts and lockout of a single auth record.
+func recordAuthUnlock(e *core.RequestEvent) error {
+	if !e.HasSuperuserAuth() {
+		return e.ForbiddenError("", nil)
+	}
+
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	record, err := e.App.FindRecordById(collection, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	if err := e.App.DeleteAllAuthLockoutsByRecord(record); err != nil {
+		return e.InternalServerError("Failed to unlock the auth record.", err)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// -------------------------------------------------------------------
+
+// reservePasswordAuthAttempt acquires the lock of the auth record
+// (or of the submitted identity if authRecord is nil) and loads its lockout.
+//
+// The lock is expected to be held until the attempt is either cleared on success
+// with [clearPasswordAuthAttempts] or finalized with [failPasswordAuthAttempt]
+// to prevent bypassing the failures counter with parallel requests.
+// The returned release function is safe to be called multiple times.
+//
+// Note that nothing is persisted before the password check so that
+// the existing and the unknown identities take the same time.
+//
+// Returns an error if the identity is currently locked or
+// the progressive delay since the last failure hasn't elapsed yet.
+func reservePasswordAuthAttempt(e *core.RequestEvent, collection *core.Collection, authRecord *core.Record, identity string) (*core.AuthLockout, func(), error) {
+	var recordId string
+	if authRecord != nil {
+		recordId = authRecord.Id
+	} else {
+		identity = strings.ToLower(identity)
+	}
+
+	// serialize the concurrent attempts for the same identity
+	release := sync.OnceFunc(lockAuthLockoutKey(authLockoutKey(collection.Id, recordId, identity)))
+
+	var lockout *core.AuthLockout
+
+	if authRecord != nil {
+		var err error
+		lockout, err = e.App.FindAuthLockoutByRecord(authRecord)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				release()
+				return nil, nil, e.InternalServerError("", err)
+			}
+
+			lockout = core.NewAuthLockout(e.App)
+			lockout.SetCollectionRef(collection.Id)
+			lockout.SetRecordRef(authRecord.Id)
+		}
+	} else {
+		lockout = unknownIdentityLockoutsStore(e.App).getOrCreate(e.App, collection, identity, authLockoutKey(collection.Id, "", identity))
+	}
+
+	// note: the error is the same for existing and unknown identities
+	// to avoid revealing which accounts exist
+	if !lockout.RetryAfter(collection.PasswordAuth.Lockout).IsZero() {
+		release()
+		return nil, nil, e.TooManyRequestsError("Too many failed authentication attempts. Please try again later.", nil)
+	}
+
+	return lockout, release, nil
+}
+
+// clearPasswordAuthAttempts deletes the lockout after a successful password authentication.
+//
+// It is expected to be called while holding the [reservePasswordAuthAttempt] lock.
+func clearPasswordAuthAttempts(e *core.RequestEvent, lockout *core.AuthLockout) {
+	if lockout.RecordRef() == "" {
+		unknownIdentityLockoutsStore(e.App).remove(authLockoutKey(lockout.CollectionRef(), "", lockout.Identity()))
+		return
+	}
+
+	if err := e.App.Delete(lockout); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		// treat as non-critical error, just log for now
+		e.App.Logger().Warn("Failed to delete auth lockout", "error", err, "authLockoutId", lockout.Id)
+	}
+}
+
+// failPasswordAuthAttempt registers a new failed attempt for the reserved lockout.
+//
+// If the lockout has reached the collection max allowed failures it is also
+// locked and the OnRecordAuthLockoutRequest hook is triggered.
+//
+// It is expected to be called while holding the [reservePasswordAuthAttempt] lock.
+func failPasswordAuthAttempt(e *core.RequestEvent, collection *core.Collection, authRecord *core.Record, identity string, lockout *core.AuthLockout) error {
+	config := collection.PasswordAuth.Lockout
+
+	// start over after an elapsed lock
+	if lockout.HasExpiredLock() {
+		lockout.SetFailures(0)
+		lockout.SetLockedUntil(types.DateTime{})
+	}
+
+	lockout.SetFailures(lockout.Failures() + 1)
+	lockout.SetLastFailure(types.NowDateTime())
+
+	// the unknown identities lockouts are kept only in memory
+	if lockout.RecordRef() != "" {
+		if err := e.App.Save(lockout); err != nil {
+			return err
+		}
+	}
+
+	if lockout.Failures() < config.MaxFailures {
+		return nil
+	}
+
+	lockout.SetLockedUntil(types.NowDateTime().Add(config.DurationTime()))
+
+	event := new(core.RecordAuthLockoutRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Record = authRecord
+	event.Identity = identity
+	event.Lockout = lockout
+
+	return e.App.OnRecordAuthLockoutRequest().Trigger(event, func(e *core.RecordAuthLockoutRequestEvent) error {
+		// the unknown identities lockouts are kept only in memory
+		if e.Lockout.RecordRef() != "" {
+			if err := e.App.Save(e.Lockout); err != nil {
+				return err
+			}
+		}
+
+		if e.Record != nil && e.Collection.PasswordAuth.Lockout.Alert {
+			ip, userAgent := authSessionClientInfo(e.RequestEvent)
+			alertInfo := fmt.Sprintf(
+				"%s - %s %s (the account was temporarily locked after %d failed password attempts)",
+				types.NowDateTime().String(), ip, userAgent, e.Lockout.Failures(),
+			)
+
+			if err := sendAuthAlert(e.RequestEvent, e.Record, alertInfo); err != nil {
+				e.App.Logger().Warn("[failPasswordAuthAttempt] Failed to send lockout alert", "error", err)
+			}
+		}
+
+		return e.Next()
+	})
+}
+
+// -------------------------------------------------------------------
+
+func authLockoutKey(collectionId, recordId, identity string) string {
+	if recordId != "" {
+		return collectionId + "/record/" + recordId
+	}
+
+	return collectionId + "/identity/" + identity
+}
+
+var (
+	authLockoutLocksMu sync.Mutex
+	authLockoutLocks   = map[string]*authLockoutLock{}
+)
+
+type authLockoutLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lockAuthLockoutKey acquires the lock of the specified lockout key
+// and returns its release function.
+func lockAuthLockoutKey(key string) func() {
+	authLockoutLocksMu.Lock()
+	l, ok := authLockoutLocks[key]
+	if !ok {
+		l = &authLockoutLock{}
+		authLockoutLocks[key] = l
+	}
+	l.refs++
+	authLockoutLocksMu.Unlock()
+
+	l.mu.Lock()
+
+	return func() {
+		l.mu.Unlock()
+
+		authLockoutLocksMu.Lock()
+		l.refs--
+		if l.refs <= 0 {
+			delete(authLockoutLocks, key)
+		}
+		authLockoutLocksMu.Unlock()
+	}
+}
+
+// -------------------------------------------------------------------
+
+const unknownIdentityLockoutsStoreKey = "@pbUnknownIdentityLockouts"
+
+// maxUnknownIdentityLockouts limits the number of the tracked
+// unknown identities to prevent the app memory to be exhausted.
+const maxUnknownIdentityLockouts = 10000
+
+// unknownIdentityLockouts is a simple in-memory store with the lockouts
+// of the submitted identities that don't match any auth record.
+//
+// They are not persisted in the database to prevent creating
+// a new db entry for each random submitted identity.
+type unknownIdentityLockouts struct {
+	items map[string]*core.AuthLockout
+	mu    sync.Mutex
+}
+
+func unknownIdentityLockoutsStore(app core.App) *unknownIdentityLockouts {
+	return app.Store().GetOrSet(unknownIdentityLockoutsStoreKey, func() any {
+		return &unknownIdentityLockouts{items: map[string]*core.AuthLockout{}}
+	}).(*unknownIdentityLockouts)
+}
+
+// getOrCreate returns the existing non-expired lockout of the specified
+// key or registers a new one for the provided collection identity.
+func (s *unknownIdentityLockouts) getOrCreate(app core.App, collection *core.Collection, identity string, key string) *core.AuthLockout {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	duration := collection.PasswordAuth.Lockout.DurationTime()
+
+	if lockout, ok := s.items[key]; ok && !isUnknownIdentityLockoutExpired(lockout, duration) {
+		return lockout
+	}
+
+	if len(s.items) >= maxUnknownIdentityLockouts {
+		for k, item := range s.items {
+			if isUnknownIdentityLockoutExpired(item, duration) {
+				delete(s.items, k)
+			}
+		}
+
+		// still full -> start over
+		if len(s.items) >= maxUnknownIdentityLockouts {
+			clear(s.items)
+		}
+	}
+
+	lockout := core.NewAuthLockout(app)
+	lockout.SetCollectionRef(collection.Id)
+	lockout.SetIdentity(identity)
+
+	s.items[key] = lockout
+
+	return lockout
+}
+
+func (s *unknownIdentityLockouts) remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, key)
+}
+
+// isUnknownIdentityLockoutExpired reports whether the lockout is
+// no longer relevant (similar to [core.App.DeleteExpiredAuthLockouts]).
+func isUnknownIdentityLockoutExpired(lockout *core.AuthLockout, duration time.Duration) bool {
+	return !lockout.IsLocked() && time.Since(lockout.LastFailure().Time()) > duration
+}
@@ -16,6 +16,23 @@ type otpResponse struct {
 	Duration int64 `json:"duration"` // in seconds
 }
 
+type webauthnResponse struct {
+	Enabled  bool  `json:"enabled"`
+	Duration int64 `json:"duration"` // in seconds
+}
+
+type totpResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+type ldapResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+type samlResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
 type mfaResponse struct {
 	Enabled  bool  `json:"enabled"`
 	Duration int64 `json:"duration"` // in seconds
@@ -55,6 +72,10 @@ type authMethodsResponse struct {
 	OAuth2   oauth2Response   `json:"oauth2"`
 	MFA      mfaResponse      `json:"mfa"`
 	OTP      otpResponse      `json:"otp"`
+	WebAuthn webauthnResponse `json:"webauthn"`
+	TOTP     totpResponse     `json:"totp"`
+	LDAP     ldapResponse     `json:"ldap"`
+	SAML     samlResponse     `json:"saml"`
 
 	// legacy fields
 	// @todo remove after dropping v0.22 support
@@ -96,6 +117,19 @@ func recordAuthMethods(e *core.RequestEvent) error {
 		OTP: otpResponse{
 			Enabled: collection.OTP.Enabled,
 		},
+		WebAuthn: webauthnResponse{
+			Enabled: collection.WebAuthn.Enabled,
+		},
+		TOTP: totpResponse{
+			// TOTP can be used only as a second factor
+			Enabled: collection.TOTP.Enabled && collection.MFA.Enabled,
+		},
+		LDAP: ldapResponse{
+			Enabled: collection.LDAP.Enabled,
+		},
+		SAML: samlResponse{
+			Enabled: collection.SAML.Enabled,
+		},
 		MFA: mfaResponse{
 			Enabled: collection.MFA.Enabled,
 		},
@@ -110,6 +144,10 @@ func recordAuthMethods(e *core.RequestEvent) error {
 		result.OTP.Duration = collection.OTP.Duration
 	}
 
+	if collection.WebAuthn.Enabled {
+		result.WebAuthn.Duration = collection.WebAuthn.Duration
+	}
+
 	if collection.MFA.Enabled {
 		result.MFA.Duration = collection.MFA.Duration
 	}
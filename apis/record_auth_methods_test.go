@@ -38,18 +38,37 @@ func TestRecordAuthMethodsList(t *testing.T) {
 				`"oauth2":{"providers":[],"enabled":false}`,
 				`"mfa":{"enabled":false,"duration":0}`,
 				`"otp":{"enabled":false,"duration":0}`,
+				`"webauthn":{"enabled":false,"duration":0}`,
+				`"totp":{"enabled":false}`,
+				`"ldap":{"enabled":false}`,
+				`"saml":{"enabled":false}`,
 			},
 			ExpectedEvents: map[string]int{"*": 0},
 		},
 		{
-			Name:           "auth collection with all auth methods allowed",
-			Method:         http.MethodGet,
-			URL:            "/api/collections/users/auth-methods",
+			Name:   "auth collection with all auth methods allowed",
+			Method: http.MethodGet,
+			URL:    "/api/collections/users/auth-methods",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				usersCol, err := app.FindCollectionByNameOrId("users")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				usersCol.WebAuthn.Enabled = true
+				usersCol.TOTP.Enabled = true
+
+				if err := app.Save(usersCol); err != nil {
+					t.Fatal(err)
+				}
+			},
 			ExpectedStatus: 200,
 			ExpectedContent: []string{
 				`"password":{"identityFields":["email","username"],"enabled":true}`,
 				`"mfa":{"enabled":true,"duration":1800}`,
 				`"otp":{"enabled":true,"duration":300}`,
+				`"webauthn":{"enabled":true,"duration":300}`,
+				`"totp":{"enabled":true}`,
 				`"oauth2":{`,
 				`"providers":[{`,
 				`"name":"google"`,
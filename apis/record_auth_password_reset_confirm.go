@@ -26,9 +26,11 @@ func recordConfirmPasswordReset(e *core.RequestEvent) error {
 		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
 	}
 
-	authRecord, err := e.App.FindAuthRecordByToken(form.Token, core.TokenTypePasswordReset)
-	if err != nil {
-		return firstApiError(err, e.BadRequestError("Invalid or expired password reset token.", err))
+	// note: reuse the already validated form record to skip the
+	// duplicated password policy history check on save
+	authRecord := form.record
+	if authRecord == nil {
+		return e.BadRequestError("Invalid or expired password reset token.", nil)
 	}
 
 	event := new(core.RecordConfirmPasswordResetRequestEvent)
@@ -54,6 +56,11 @@ func recordConfirmPasswordReset(e *core.RequestEvent) error {
 
 		e.App.Store().Remove(getPasswordResetResendKey(authRecord))
 
+		// the new password also lifts any previous failed attempts lockout
+		if err := e.App.DeleteAllAuthLockoutsByRecord(authRecord); err != nil {
+			e.App.Logger().Warn("Failed to delete the auth record lockouts", "error", err, "recordId", authRecord.Id)
+		}
+
 		return execAfterSuccessTx(true, e.App, func() error {
 			return e.NoContent(http.StatusNoContent)
 		})
@@ -65,6 +72,7 @@ func recordConfirmPasswordReset(e *core.RequestEvent) error {
 type recordConfirmPasswordResetForm struct {
 	app        core.App
 	collection *core.Collection
+	record     *core.Record // the token auth record (set on successful token validation)
 
 	Token           string `form:"token" json:"token"`
 	Password        string `form:"password" json:"password"`
@@ -80,7 +88,7 @@ func (form *recordConfirmPasswordResetForm) validate() error {
 
 	return validation.ValidateStruct(form,
 		validation.Field(&form.Token, validation.Required, validation.By(form.checkToken)),
-		validation.Field(&form.Password, validation.Required, validation.Length(min, 255)), // the FieldPassword validator will check further the specicic length constraints
+		validation.Field(&form.Password, validation.Required, validation.Length(min, 255), validation.By(form.checkPasswordPolicy)), // the FieldPassword validator will check further the specicic length constraints
 		validation.Field(&form.PasswordConfirm, validation.Required, validation.By(validators.Equal(form.Password))),
 	)
 }
@@ -100,5 +108,25 @@ func (form *recordConfirmPasswordResetForm) checkToken(value any) error {
 		return validation.NewError("validation_token_collection_mismatch", "The provided token is for different auth collection.")
 	}
 
+	form.record = record
+
 	return nil
 }
+
+func (form *recordConfirmPasswordResetForm) checkPasswordPolicy(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil
+	}
+
+	passField, ok := form.collection.Fields.GetByName(core.FieldNamePassword).(*core.PasswordField)
+	if !ok || passField == nil {
+		return nil
+	}
+
+	if form.record == nil {
+		return nil // the token errors are reported separately
+	}
+
+	return passField.ValidatePolicy(form.app, form.record, v)
+}
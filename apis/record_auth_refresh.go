@@ -12,6 +12,11 @@ func recordAuthRefresh(e *core.RequestEvent) error {
 		return e.NotFoundError("Missing auth record context.", nil)
 	}
 
+	// API keys are long-lived credentials and can't be exchanged for auth tokens
+	if e.APIKey != nil {
+		return e.ForbiddenError("API keys can't be refreshed.", nil)
+	}
+
 	event := new(core.RecordAuthRefreshRequestEvent)
 	event.RequestEvent = e
 	event.Collection = record.Collection()
@@ -23,13 +28,16 @@ func recordAuthRefresh(e *core.RequestEvent) error {
 		// skip token renewal if the token's payload doesn't explicitly allow it (e.g. impersonate tokens)
 		claims, _ := security.ParseUnverifiedJWT(token) //
 		if v, ok := claims[core.TokenClaimRefreshable]; ok && cast.ToBool(v) {
+			// preserve the auth session of the refreshed token (if any)
+			sessionId, _ := claims[core.TokenClaimSessionId].(string)
+
 			var tokenErr error
-			token, tokenErr = e.Record.NewAuthToken()
+			token, tokenErr = newAuthToken(e.App, e.Record, 0, true, sessionId)
 			if tokenErr != nil {
 				return e.InternalServerError("Failed to refresh auth token.", tokenErr)
 			}
 		}
 
-		return recordAuthResponse(e.RequestEvent, e.Record, token, "", nil)
+		return recordAuthResponse(e.RequestEvent, e.Record, token, "", nil, nil)
 	})
 }
@@ -0,0 +1,168 @@
+package apis
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+type authSessionResponse struct {
+	Id        string         `json:"id"`
+	IP        string         `json:"ip"`
+	UserAgent string         `json:"userAgent"`
+	Created   types.DateTime `json:"created"`
+	LastSeen  types.DateTime `json:"lastSeen"`
+	Current   bool           `json:"current"`
+}
+
+func recordListAuthSessions(e *core.RequestEvent) error {
+	record := e.Auth
+	if record == nil {
+		return e.UnauthorizedError("The request requires valid record authorization token.", nil)
+	}
+
+	origins, err := e.App.FindAllAuthOriginsByRecord(record)
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to load the auth sessions.", err))
+	}
+
+	currentSessionId := requestAuthSessionId(e)
+
+	result := make([]authSessionResponse, len(origins))
+	for i, origin := range origins {
+		lastSeen := origin.LastSeen()
+		if lastSeen.IsZero() {
+			lastSeen = origin.Updated()
+		}
+
+		result[i] = authSessionResponse{
+			Id:        origin.Id,
+			IP:        origin.IP(),
+			UserAgent: origin.UserAgent(),
+			Created:   origin.Created(),
+			LastSeen:  lastSeen,
+			Current:   currentSessionId != "" && origin.Id == currentSessionId,
+		}
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+func recordRevokeAuthSession(e *core.RequestEvent) error {
+	record := e.Auth
+	if record == nil {
+		return e.UnauthorizedError("The request requires valid record authorization token.", nil)
+	}
+
+	origin, err := e.App.FindAuthOriginById(e.Request.PathValue("id"))
+	if err != nil || origin.CollectionRef() != record.Collection().Id || origin.RecordRef() != record.Id {
+		return e.NotFoundError("Missing or invalid auth session.", err)
+	}
+
+	if err = e.App.Delete(origin); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to revoke the auth session.", err))
+	}
+
+	authSessionsLastSeenStore(e.App).remove(origin.Id)
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// requestAuthSessionId returns the auth session id of the current request auth token (if any).
+func requestAuthSessionId(e *core.RequestEvent) string {
+	claims, _ := security.ParseUnverifiedJWT(getAuthTokenFromRequest(e))
+
+	sessionId, _ := claims[core.TokenClaimSessionId].(string)
+
+	return sessionId
+}
+
+// -------------------------------------------------------------------
+
+const authSessionsLastSeenStoreKey = "@pbAuthSessionsLastSeen"
+
+// authSessionLastSeenInterval is the minimum interval between
+// two consecutive "lastSeen" db updates of the same auth session.
+const authSessionLastSeenInterval = 5 * time.Minute
+
+// maxAuthSessionsLastSeen limits the number of the tracked
+// auth sessions to prevent the app memory to be exhausted.
+const maxAuthSessionsLastSeen = 10000
+
+// authSessionsLastSeen is a simple in-memory store with the
+// last time each auth session "lastSeen" field was persisted.
+type authSessionsLastSeen struct {
+	items map[string]time.Time
+	mu    sync.Mutex
+}
+
+func authSessionsLastSeenStore(app core.App) *authSessionsLastSeen {
+	return app.Store().GetOrSet(authSessionsLastSeenStoreKey, func() any {
+		return &authSessionsLastSeen{items: map[string]time.Time{}}
+	}).(*authSessionsLastSeen)
+}
+
+// shouldTouch reports whether the specified session "lastSeen" field
+// should be updated and if so registers the current time for it.
+func (s *authSessionsLastSeen) shouldTouch(sessionId string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if last, ok := s.items[sessionId]; ok && now.Sub(last) < authSessionLastSeenInterval {
+		return false
+	}
+
+	if len(s.items) >= maxAuthSessionsLastSeen {
+		for id, last := range s.items {
+			if now.Sub(last) >= authSessionLastSeenInterval {
+				delete(s.items, id)
+			}
+		}
+
+		// still full -> start over
+		if len(s.items) >= maxAuthSessionsLastSeen {
+			clear(s.items)
+		}
+	}
+
+	s.items[sessionId] = now
+
+	return true
+}
+
+func (s *authSessionsLastSeen) remove(sessionId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, sessionId)
+}
+
+// touchAuthSession updates the "lastSeen" field of the auth session
+// associated with the already verified request auth token.
+//
+// To minimize the per-request overhead the update is performed
+// at most once per authSessionLastSeenInterval for each session.
+func touchAuthSession(e *core.RequestEvent, token string) {
+	claims, _ := security.ParseUnverifiedJWT(token)
+
+	sessionId, _ := claims[core.TokenClaimSessionId].(string)
+	if sessionId == "" || !authSessionsLastSeenStore(e.App).shouldTouch(sessionId) {
+		return
+	}
+
+	_, err := e.App.NonconcurrentDB().Update(
+		core.CollectionNameAuthOrigins,
+		dbx.Params{"lastSeen": types.NowDateTime().String()},
+		dbx.HashExp{"id": sessionId},
+	).Execute()
+	if err != nil {
+		e.App.Logger().Debug("Failed to update auth session lastSeen", "error", err, "sessionId", sessionId)
+	}
+}
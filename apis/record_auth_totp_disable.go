@@ -0,0 +1,52 @@
+package apis
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func recordDisableTOTP(e *core.RequestEvent) error {
+	_, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	record := e.Auth
+	if record == nil {
+		return e.UnauthorizedError("The request requires valid record authorization token.", nil)
+	}
+
+	totp, err := e.App.FindTOTPByRecord(record)
+	if err != nil {
+		return e.NotFoundError("The current record doesn't have TOTP setup.", err)
+	}
+
+	// require a valid authenticator app or recovery code for the confirmed TOTPs
+	// to prevent disabling the second factor with just a stolen auth token
+	if totp.Confirmed() {
+		form := &totpCodeForm{}
+		if err = e.BindBody(form); err != nil {
+			return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+		}
+		if err = form.validate(); err != nil {
+			return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+		}
+
+		err = checkRateLimit(e, "@pb_totp_"+record.Id, core.RateLimitRule{MaxRequests: 5, Duration: 180})
+		if err != nil {
+			return e.TooManyRequestsError("Too many attempts, please try again later.", nil)
+		}
+
+		if !validateTOTPOrRecoveryCode(totp, form.Code) {
+			return e.BadRequestError("Invalid TOTP or recovery code.", errors.New("incorrect code"))
+		}
+	}
+
+	if err := e.App.Delete(totp); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to disable TOTP.", err))
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,101 @@
+package apis
+
+import (
+	"errors"
+	"net/http"
+
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const (
+	totpRecoveryCodesCount     = 10
+	totpRecoveryCodeAlphabet   = "abcdefghijklmnopqrstuvwxyz0123456789"
+	totpRecoveryCodePartLength = 5
+)
+
+func recordConfirmTOTPSetup(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.TOTP.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow TOTP authentication.", nil)
+	}
+
+	record := e.Auth
+	if record == nil {
+		return e.UnauthorizedError("The request requires valid record authorization token.", nil)
+	}
+
+	form := &totpCodeForm{}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	totp, err := e.App.FindTOTPByRecord(record)
+	if err != nil || totp.Confirmed() {
+		return e.BadRequestError("Missing or already confirmed TOTP setup.", err)
+	}
+
+	err = checkRateLimit(e, "@pb_totp_"+record.Id, core.RateLimitRule{MaxRequests: 5, Duration: 180})
+	if err != nil {
+		return e.TooManyRequestsError("Too many attempts, please try again later.", nil)
+	}
+
+	if !totp.ValidateCode(form.Code) {
+		return e.BadRequestError("Invalid TOTP code.", errors.New("incorrect code"))
+	}
+
+	recoveryCodes := make([]string, totpRecoveryCodesCount)
+	for i := range recoveryCodes {
+		recoveryCodes[i] = newTOTPRecoveryCode()
+	}
+
+	totp.SetConfirmed(true)
+
+	event := new(core.RecordConfirmTOTPSetupRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Record = record
+	event.TOTP = totp
+	event.RecoveryCodes = recoveryCodes
+
+	return e.App.OnRecordConfirmTOTPSetupRequest().Trigger(event, func(e *core.RecordConfirmTOTPSetupRequestEvent) error {
+		e.TOTP.SetRecoveryCodes(e.RecoveryCodes)
+
+		if err := e.App.Save(e.TOTP); err != nil {
+			return firstApiError(err, e.BadRequestError("Failed to confirm the TOTP setup.", err))
+		}
+
+		return execAfterSuccessTx(true, e.App, func() error {
+			return e.JSON(http.StatusOK, map[string]any{
+				"recoveryCodes": e.RecoveryCodes,
+			})
+		})
+	})
+}
+
+// newTOTPRecoveryCode generates a new random recovery code in the format "xxxxx-xxxxx".
+func newTOTPRecoveryCode() string {
+	return security.RandomStringWithAlphabet(totpRecoveryCodePartLength, totpRecoveryCodeAlphabet) +
+		"-" +
+		security.RandomStringWithAlphabet(totpRecoveryCodePartLength, totpRecoveryCodeAlphabet)
+}
+
+// -------------------------------------------------------------------
+
+type totpCodeForm struct {
+	Code string `form:"code" json:"code"`
+}
+
+func (form *totpCodeForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Code, validation.Required, validation.Length(1, 100)),
+	)
+}
@@ -0,0 +1,61 @@
+package apis
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func recordRequestTOTPSetup(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.TOTP.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow TOTP authentication.", nil)
+	}
+
+	record := e.Auth
+	if record == nil {
+		return e.UnauthorizedError("The request requires valid record authorization token.", nil)
+	}
+
+	totp, err := e.App.FindTOTPByRecord(record)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return e.InternalServerError("Failed to fetch the record TOTP.", err)
+		}
+
+		totp = core.NewTOTP(e.App)
+		totp.SetCollectionRef(collection.Id)
+		totp.SetRecordRef(record.Id)
+	} else if totp.Confirmed() {
+		return e.BadRequestError("TOTP is already enabled for the current record.", nil)
+	}
+
+	// always generate a new secret so that an unconfirmed setup could be restarted
+	totp.SetSecret(security.RandomTOTPSecret())
+
+	if err := e.App.Save(totp); err != nil {
+		return firstApiError(err, e.InternalServerError("Failed to save the TOTP setup.", err))
+	}
+
+	issuer := collection.TOTP.Issuer
+	if issuer == "" {
+		issuer = e.App.Settings().Meta.AppName
+	}
+
+	accountName := record.Email()
+	if accountName == "" {
+		accountName = record.Id
+	}
+
+	return e.JSON(http.StatusOK, map[string]string{
+		"secret": totp.Secret(),
+		"uri":    security.TOTPProvisioningURI(totp.Secret(), issuer, accountName),
+	})
+}
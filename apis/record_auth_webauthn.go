@@ -0,0 +1,104 @@
+package apis
+
+import (
+	"net/url"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/pocketbase/pocketbase/tools/webauthn"
+)
+
+// the auth state kinds of the pending WebAuthn ceremony challenges
+const (
+	authStateKindWebAuthnRegistration = "webauthnRegistration"
+	authStateKindWebAuthnAuth         = "webauthnAuth"
+)
+
+type webauthnChallengeData struct {
+	Challenge string `json:"challenge"`
+}
+
+// webauthnSaveChallenge persists a new WebAuthn ceremony challenge
+// and returns its id and value.
+//
+// recordId is required only for the registration ceremony.
+func webauthnSaveChallenge(app core.App, collection *core.Collection, kind string, recordId string) (string, string, error) {
+	challengeId := security.RandomString(30)
+	challenge := webauthn.NewChallenge()
+
+	state := core.NewAuthState(app)
+	state.SetCollectionRef(collection.Id)
+	state.SetRecordRef(recordId)
+	state.SetKind(kind)
+	state.SetKey(challengeId)
+	state.SetData(webauthnChallengeData{Challenge: challenge})
+	state.SetExpires(types.NowDateTime().Add(collection.WebAuthn.DurationTime()))
+
+	if err := app.Save(state); err != nil {
+		return "", "", err
+	}
+
+	return challengeId, challenge, nil
+}
+
+// webauthnConsumeChallenge removes and returns the non-expired
+// WebAuthn ceremony challenge state with the specified id.
+//
+// Returns false if the challenge is missing, expired or
+// it was issued for a different collection.
+func webauthnConsumeChallenge(app core.App, collection *core.Collection, kind string, challengeId string) (*core.AuthState, string, bool) {
+	state, err := app.ConsumeAuthState(kind, challengeId)
+	if err != nil || state.CollectionRef() != collection.Id {
+		return nil, "", false
+	}
+
+	data := webauthnChallengeData{}
+	if err := state.UnmarshalData(&data); err != nil || data.Challenge == "" {
+		return nil, "", false
+	}
+
+	return state, data.Challenge, true
+}
+
+// -------------------------------------------------------------------
+
+// webauthnRelyingParty returns the WebAuthn relying party id and name
+// of the specified collection (fallbacks to the app settings).
+func webauthnRelyingParty(app core.App, collection *core.Collection) webauthn.RelyingParty {
+	rp := webauthn.RelyingParty{
+		Id:   collection.WebAuthn.RPId,
+		Name: collection.WebAuthn.RPName,
+	}
+
+	if rp.Id == "" {
+		if u, err := url.Parse(app.Settings().Meta.AppURL); err == nil {
+			rp.Id = u.Hostname()
+		}
+	}
+
+	if rp.Name == "" {
+		rp.Name = app.Settings().Meta.AppName
+	}
+
+	return rp
+}
+
+// webauthnCeremonyOptions returns the WebAuthn response verification options
+// for the specified collection and challenge.
+func webauthnCeremonyOptions(app core.App, collection *core.Collection, challenge string) webauthn.CeremonyOptions {
+	origins := collection.WebAuthn.Origins
+
+	if len(origins) == 0 {
+		if u, err := url.Parse(app.Settings().Meta.AppURL); err == nil {
+			origins = []string{u.Scheme + "://" + u.Host}
+		}
+	}
+
+	return webauthn.CeremonyOptions{
+		Challenge:               challenge,
+		RPId:                    webauthnRelyingParty(app, collection).Id,
+		Origins:                 origins,
+		RequireUserVerification: collection.WebAuthn.UserVerification == core.WebAuthnUserVerificationRequired,
+	}
+}
@@ -0,0 +1,106 @@
+package apis
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/webauthn"
+)
+
+func recordConfirmWebAuthnRegistration(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.WebAuthn.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow WebAuthn authentication.", nil)
+	}
+
+	record := e.Auth
+	if record == nil {
+		return e.UnauthorizedError("The request requires valid record authorization token.", nil)
+	}
+
+	form := &confirmWebAuthnRegistrationForm{}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	state, challenge, ok := webauthnConsumeChallenge(e.App, collection, authStateKindWebAuthnRegistration, form.ChallengeId)
+	if !ok || state.RecordRef() != record.Id {
+		return e.BadRequestError("Invalid or expired WebAuthn challenge.", errors.New("missing or mismatched registration challenge"))
+	}
+
+	verified, err := webauthn.VerifyRegistration(
+		webauthnCeremonyOptions(e.App, collection, challenge),
+		form.Credential.Response.ClientDataJSON,
+		form.Credential.Response.AttestationObject,
+	)
+	if err != nil {
+		return e.BadRequestError("Failed to verify the WebAuthn credential.", err)
+	}
+
+	credentialId := webauthn.Base64URL(verified.Id).String()
+
+	existing, err := e.App.FindWebAuthnCredentialByCredentialId(collection.Id, credentialId)
+	if err == nil && existing != nil {
+		return e.BadRequestError("The WebAuthn credential is already registered.", nil)
+	}
+
+	credential := core.NewWebAuthnCredential(e.App)
+	credential.SetCollectionRef(collection.Id)
+	credential.SetRecordRef(record.Id)
+	credential.SetName(form.Name)
+	credential.SetCredentialId(credentialId)
+	credential.SetPublicKey(webauthn.Base64URL(verified.PublicKey).String())
+	credential.SetAlgorithm(verified.Algorithm)
+	credential.SetSignCount(int(verified.SignCount))
+	credential.SetTransports(form.Credential.Response.Transports)
+	credential.SetAAGUID(hex.EncodeToString(verified.AAGUID))
+	credential.SetBackupEligible(verified.BackupEligible)
+
+	event := new(core.RecordRegisterWebAuthnRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Record = record
+	event.Credential = credential
+
+	return e.App.OnRecordRegisterWebAuthnRequest().Trigger(event, func(e *core.RecordRegisterWebAuthnRequestEvent) error {
+		if err := e.App.Save(e.Credential); err != nil {
+			return firstApiError(err, e.BadRequestError("Failed to save the WebAuthn credential.", err))
+		}
+
+		return execAfterSuccessTx(true, e.App, func() error {
+			return e.JSON(http.StatusOK, e.Credential)
+		})
+	})
+}
+
+// -------------------------------------------------------------------
+
+type confirmWebAuthnRegistrationForm struct {
+	ChallengeId string                        `form:"challengeId" json:"challengeId"`
+	Name        string                        `form:"name" json:"name"`
+	Credential  webauthn.RegistrationResponse `form:"credential" json:"credential"`
+}
+
+func (form *confirmWebAuthnRegistrationForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.ChallengeId, validation.Required, validation.Length(1, 255)),
+		validation.Field(&form.Name, validation.Length(0, 255)),
+		validation.Field(&form.Credential, validation.By(func(value any) error {
+			c, _ := value.(webauthn.RegistrationResponse)
+			if len(c.Response.ClientDataJSON) == 0 || len(c.Response.AttestationObject) == 0 {
+				return validation.ErrRequired
+			}
+			return nil
+		})),
+	)
+}
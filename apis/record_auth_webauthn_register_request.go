@@ -0,0 +1,75 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/webauthn"
+)
+
+func recordRequestWebAuthnRegistration(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.WebAuthn.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow WebAuthn authentication.", nil)
+	}
+
+	record := e.Auth
+	if record == nil {
+		return e.UnauthorizedError("The request requires valid record authorization token.", nil)
+	}
+
+	existing, err := e.App.FindAllWebAuthnCredentialsByRecord(record)
+	if err != nil {
+		return e.InternalServerError("Failed to fetch the existing record WebAuthn credentials.", err)
+	}
+
+	exclude := make([]webauthn.CredentialDescriptor, 0, len(existing))
+	for _, c := range existing {
+		id, err := webauthn.DecodeBase64URL(c.CredentialId())
+		if err != nil {
+			continue // shouldn't happen
+		}
+		exclude = append(exclude, webauthn.CredentialDescriptor{
+			Type:       "public-key",
+			Id:         id,
+			Transports: c.Transports(),
+		})
+	}
+
+	name := record.Email()
+	if name == "" {
+		name = record.Id
+	}
+
+	challengeId, challenge, err := webauthnSaveChallenge(e.App, collection, authStateKindWebAuthnRegistration, record.Id)
+	if err != nil {
+		return e.InternalServerError("Failed to create WebAuthn challenge.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"challengeId": challengeId,
+		"publicKey": webauthn.CreationOptions{
+			RP: webauthnRelyingParty(e.App, collection),
+			User: webauthn.User{
+				Id:          []byte(record.Id),
+				Name:        name,
+				DisplayName: name,
+			},
+			Challenge:          challenge,
+			PubKeyCredParams:   webauthn.PublicKeyCredentialParameters(),
+			Timeout:            collection.WebAuthn.Duration * 1000,
+			ExcludeCredentials: exclude,
+			AuthenticatorSelection: webauthn.AuthenticatorSelection{
+				// the authentication ceremony relies on discoverable credentials
+				ResidentKey:        "required",
+				RequireResidentKey: true,
+				UserVerification:   collection.WebAuthn.UserVerification,
+			},
+			Attestation: "none",
+		},
+	})
+}
@@ -0,0 +1,37 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/webauthn"
+)
+
+func recordRequestWebAuthnAuth(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.WebAuthn.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow WebAuthn authentication.", nil)
+	}
+
+	challengeId, challenge, err := webauthnSaveChallenge(e.App, collection, authStateKindWebAuthnAuth, "")
+	if err != nil {
+		return e.InternalServerError("Failed to create WebAuthn challenge.", err)
+	}
+
+	// note: allowCredentials is left empty since the credentials are discoverable
+	// (this also prevents exposing the existence of a specific user)
+	return e.JSON(http.StatusOK, map[string]any{
+		"challengeId": challengeId,
+		"publicKey": webauthn.RequestOptions{
+			Challenge:        challenge,
+			Timeout:          collection.WebAuthn.Duration * 1000,
+			RPId:             webauthnRelyingParty(e.App, collection).Id,
+			AllowCredentials: []webauthn.CredentialDescriptor{},
+			UserVerification: collection.WebAuthn.UserVerification,
+		},
+	})
+}
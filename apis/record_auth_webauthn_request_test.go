@@ -0,0 +1,139 @@
+package apis_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRecordRequestWebAuthnAuth(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "not an auth collection",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/demo1/request-webauthn-auth",
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "auth collection with disabled webauthn",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/users/request-webauthn-auth",
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "auth collection with enabled webauthn",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/request-webauthn-auth",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableWebAuthn(t, app, "users", false)
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"challengeId":"`,
+				`"publicKey":{`,
+				`"challenge":"`,
+				`"timeout":300000`,
+				`"rpId":"localhost"`,
+				`"allowCredentials":[]`,
+				`"userVerification":"preferred"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*": 0,
+				// challenge auth state
+				"OnModelValidate":            1,
+				"OnModelCreate":              1,
+				"OnModelCreateExecute":       1,
+				"OnModelAfterCreateSuccess":  1,
+				"OnRecordValidate":           1,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+			},
+		},
+		{
+			Name:   "custom relying party id",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/request-webauthn-auth",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				collection, err := app.FindCollectionByNameOrId("users")
+				if err != nil {
+					t.Fatal(err)
+				}
+				collection.WebAuthn.Enabled = true
+				collection.WebAuthn.RPId = "example.com"
+				collection.WebAuthn.Duration = 100
+				collection.WebAuthn.UserVerification = core.WebAuthnUserVerificationRequired
+				if err := app.Save(collection); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"challengeId":"`,
+				`"timeout":100000`,
+				`"rpId":"example.com"`,
+				`"userVerification":"required"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*": 0,
+				// challenge auth state
+				"OnModelValidate":            1,
+				"OnModelCreate":              1,
+				"OnModelCreateExecute":       1,
+				"OnModelAfterCreateSuccess":  1,
+				"OnRecordValidate":           1,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+			},
+		},
+
+		// rate limit checks
+		// -----------------------------------------------------------
+		{
+			Name:   "RateLimit rule - users:requestWebAuthnAuth",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/request-webauthn-auth",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().RateLimits.Enabled = true
+				app.Settings().RateLimits.Rules = []core.RateLimitRule{
+					{MaxRequests: 100, Label: "abc"},
+					{MaxRequests: 100, Label: "*:requestWebAuthnAuth"},
+					{MaxRequests: 0, Label: "users:requestWebAuthnAuth"},
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "builtin per-IP rate limit (ignores the disabled settings rate limits)",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/request-webauthn-auth",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableWebAuthn(t, app, "users", false)
+
+				app.Settings().RateLimits.Enabled = false
+
+				for i := 0; i < 60; i++ {
+					requestWebAuthnChallenge(t, e, "/api/collections/users/request-webauthn-auth", "")
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,420 @@
+package apis
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/ldap"
+)
+
+// errLDAPInvalidCredentials is returned when the submitted
+// credentials doesn't match with exactly one directory entry.
+var errLDAPInvalidCredentials = errors.New("invalid LDAP credentials")
+
+func recordAuthWithLDAP(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.LDAP.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow LDAP authentication.", nil)
+	}
+
+	var fallbackAuthRecord *core.Record
+	if e.Auth != nil && e.Auth.Collection().Id == collection.Id {
+		fallbackAuthRecord = e.Auth
+	}
+
+	e.Set(core.RequestEventKeyInfoContext, core.RequestInfoContextLDAP)
+
+	form := new(recordLDAPLoginForm)
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+
+	// bind with the directory credentials and locate existing ExternalAuth rel
+	// ---------------------------------------------------------------
+
+	ctx, cancel := context.WithTimeout(e.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	entry, err := ldapAuthenticate(ctx, collection.LDAP, form.Username, form.Password)
+	if err != nil {
+		if errors.Is(err, errLDAPInvalidCredentials) {
+			return e.BadRequestError("Failed to authenticate.", err)
+		}
+		return e.InternalServerError("Failed to authenticate with the LDAP server.", err)
+	}
+
+	var authRecord *core.Record
+
+	// check for existing relation with the auth collection
+	externalAuthRel, err := e.App.FindFirstExternalAuthByExpr(dbx.HashExp{
+		"collectionRef": collection.Id,
+		"provider":      core.ExternalAuthProviderLDAP,
+		"providerId":    ldapProviderId(entry),
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return e.InternalServerError("Failed LDAP relation check.", err)
+	}
+
+	entryEmail := ldapEntryEmail(collection, entry)
+
+	switch {
+	case err == nil && externalAuthRel != nil:
+		authRecord, err = e.App.FindRecordById(collection, externalAuthRel.RecordRef())
+		if err != nil {
+			return err
+		}
+	case fallbackAuthRecord != nil:
+		// fallback to the logged auth record (if any)
+		authRecord = fallbackAuthRecord
+	case entryEmail != "":
+		// look for an existing auth record by the directory entry email
+		authRecord, err = e.App.FindAuthRecordByEmail(collection.Id, entryEmail)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return e.InternalServerError("Failed LDAP auth record check.", err)
+		}
+	}
+
+	// ---------------------------------------------------------------
+
+	event := new(core.RecordAuthWithLDAPRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Username = form.Username
+	event.Entry = entry
+	event.CreateData = form.CreateData
+	event.Record = authRecord
+	event.IsNewRecord = authRecord == nil
+
+	return e.App.OnRecordAuthWithLDAPRequest().Trigger(event, func(e *core.RecordAuthWithLDAPRequestEvent) error {
+		if err := ldapSubmit(e, externalAuthRel); err != nil {
+			return firstApiError(err, e.BadRequestError("Failed to authenticate.", err))
+		}
+
+		meta := map[string]any{
+			"dn":    e.Entry.DN,
+			"isNew": e.IsNewRecord,
+		}
+
+		return RecordAuthResponse(e.RequestEvent, e.Record, core.MFAMethodLDAP, meta)
+	})
+}
+
+// -------------------------------------------------------------------
+
+type recordLDAPLoginForm struct {
+	// Additional data that will be used for creating a new auth record
+	// if an existing LDAP linked account doesn't exist.
+	CreateData map[string]any `form:"createData" json:"createData"`
+
+	// The directory username (it replaces the {username} placeholder of the user search filter).
+	Username string `form:"username" json:"username"`
+
+	// The directory password.
+	Password string `form:"password" json:"password"`
+}
+
+func (form *recordLDAPLoginForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Username, validation.Required, validation.Length(1, 255)),
+		validation.Field(&form.Password, validation.Required, validation.Length(1, 1024)),
+	)
+}
+
+// ldapAuthenticate searches for the directory entry matching the
+// configured user filter and verifies its password with a simple bind.
+//
+// It returns errLDAPInvalidCredentials if the search doesn't
+// match exactly one entry or the entry bind fails.
+func ldapAuthenticate(ctx context.Context, config core.LDAPConfig, username string, password string) (*ldap.Entry, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.TLSSkipVerify}
+
+	client, err := ldap.Dial(ctx, config.URL, tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the LDAP server: %w", err)
+	}
+	defer client.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		client.SetTimeout(time.Until(deadline))
+	}
+
+	if config.StartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	// search with the service account (if any)
+	if config.BindDN != "" {
+		if err := client.Bind(config.BindDN, config.BindPassword); err != nil {
+			return nil, fmt.Errorf("failed to bind with the service account: %w", err)
+		}
+	}
+
+	entries, err := client.Search(ldap.SearchRequest{
+		BaseDN:     config.BaseDN,
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     strings.ReplaceAll(config.UserFilter, core.LDAPUsernamePlaceholder, ldap.EscapeFilter(username)),
+		Attributes: config.Attributes(),
+		SizeLimit:  2,
+	})
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.ResultSizeLimitExceeded) {
+			return nil, fmt.Errorf("%w: more than one matching entry", errLDAPInvalidCredentials)
+		}
+		return nil, fmt.Errorf("failed to search for the user entry: %w", err)
+	}
+
+	if len(entries) != 1 {
+		return nil, fmt.Errorf("%w: expected exactly one matching entry, got %d", errLDAPInvalidCredentials, len(entries))
+	}
+
+	if err := client.Bind(entries[0].DN, password); err != nil {
+		if errors.Is(err, ldap.ErrEmptyPassword) || ldap.IsErrorWithCode(err, ldap.ResultInvalidCredentials) {
+			return nil, fmt.Errorf("%w: %w", errLDAPInvalidCredentials, err)
+		}
+		return nil, fmt.Errorf("failed to bind with the user entry: %w", err)
+	}
+
+	return entries[0], nil
+}
+
+// ldapProviderId returns the external auth providerId of the directory entry.
+//
+// DNs are case-insensitive so the id is normalized to lowercase.
+func ldapProviderId(entry *ldap.Entry) string {
+	return strings.ToLower(entry.DN)
+}
+
+// ldapEntryEmail returns the directory entry email based on the collection email field mapping.
+func ldapEntryEmail(collection *core.Collection, entry *ldap.Entry) string {
+	attr := collection.LDAP.MappedFields[core.FieldNameEmail]
+	if attr == "" {
+		return ""
+	}
+
+	return entry.Value(attr)
+}
+
+// ldapMappedData returns the record field values resolved from
+// the directory entry attributes and group memberships.
+//
+// For multiple values fields all attribute values are returned,
+// otherwise - only the first one.
+func ldapMappedData(collection *core.Collection, entry *ldap.Entry) map[string]any {
+	config := collection.LDAP
+
+	isMultiple := func(name string) bool {
+		mv, ok := collection.Fields.GetByName(name).(core.MultiValuer)
+		return ok && mv.IsMultiple()
+	}
+
+	data := make(map[string]any, len(config.MappedFields)+len(config.GroupMappings))
+
+	for name, attr := range config.MappedFields {
+		if isMultiple(name) {
+			data[name] = entry.Values(attr)
+		} else {
+			data[name] = entry.Value(attr)
+		}
+	}
+
+	if len(config.GroupMappings) == 0 {
+		return data
+	}
+
+	groups := entry.Values(config.GroupsAttribute)
+
+	isMember := func(group string) bool {
+		group = strings.TrimSpace(group)
+		for _, g := range groups {
+			if strings.EqualFold(strings.TrimSpace(g), group) {
+				return true
+			}
+		}
+		return false
+	}
+
+	groupValues := map[string][]string{}
+	for _, mapping := range config.GroupMappings {
+		if _, ok := groupValues[mapping.Field]; !ok {
+			groupValues[mapping.Field] = []string{} // clear if not a member of any of the field groups
+		}
+
+		if isMember(mapping.Group) {
+			groupValues[mapping.Field] = append(groupValues[mapping.Field], mapping.Value)
+		}
+	}
+
+	for name, values := range groupValues {
+		switch {
+		case isMultiple(name):
+			data[name] = values
+		case len(values) > 0:
+			data[name] = values[0]
+		default:
+			data[name] = nil
+		}
+	}
+
+	return data
+}
+
+func ldapSubmit(e *core.RecordAuthWithLDAPRequestEvent, optExternalAuth *core.ExternalAuth) error {
+	return e.App.RunInTransaction(func(txApp core.App) error {
+		entryEmail := ldapEntryEmail(e.Collection, e.Entry)
+		mappedData := ldapMappedData(e.Collection, e.Entry)
+
+		if e.Record == nil {
+			// extra check to prevent creating a superuser record via
+			// LDAP in case the method is used by another action
+			if e.Collection.Name == core.CollectionNameSuperusers {
+				return errors.New("superusers are not allowed to sign-up with LDAP")
+			}
+
+			payload := maps.Clone(e.CreateData)
+			if payload == nil {
+				payload = map[string]any{}
+			}
+
+			// the directory attributes have precedence over the submitted data
+			for name := range e.Collection.LDAP.MappedFields {
+				payload[name] = mappedData[name]
+				delete(mappedData, name) // already assigned
+			}
+
+			// the group fields are assigned after the record creation to avoid
+			// conflicts with the collection create rule (e.g. for a "role" field)
+			for _, mapping := range e.Collection.LDAP.GroupMappings {
+				delete(payload, mapping.Field)
+			}
+
+			createdRecord, err := sendLDAPRecordCreateRequest(txApp, e, payload)
+			if err != nil {
+				return err
+			}
+
+			e.Record = createdRecord
+
+			for name, value := range mappedData {
+				e.Record.Set(name, value)
+			}
+
+			// mark as verified as long as it matches the directory data (even if the email is empty)
+			if e.Record.Email() == entryEmail {
+				e.Record.SetVerified(true)
+			}
+
+			if err := txApp.Save(e.Record); err != nil {
+				return err
+			}
+		} else {
+			var needUpdate bool
+
+			isLoggedAuthRecord := e.Auth != nil &&
+				e.Auth.Id == e.Record.Id &&
+				e.Auth.Collection().Id == e.Record.Collection().Id
+
+			// prevent pre-hijacking with password auth
+			//
+			// reset the unverified user password in case the record was precreated by a malicious actor
+			if !isLoggedAuthRecord && !e.Record.Verified() {
+				needUpdate = true
+				e.Record.SetRandomPassword()
+			}
+
+			// prevent pre-hijacking with OAuth2 or another directory entry
+			if !e.Record.Verified() {
+				err := txApp.DeleteAllExternalAuthsByRecord(e.Record)
+				if err != nil {
+					return err
+				}
+				optExternalAuth = nil // clear to allow recreate below
+			}
+
+			// sync the mapped fields
+			// (the email is updated only if empty to avoid unintended email changes)
+			for name, value := range mappedData {
+				if name == core.FieldNameEmail && e.Record.Email() != "" {
+					continue
+				}
+
+				oldValue := e.Record.Get(name)
+				e.Record.Set(name, value)
+				if !reflect.DeepEqual(oldValue, e.Record.Get(name)) {
+					needUpdate = true
+				}
+			}
+
+			// update the existing auth record verified state
+			// (only if the auth record doesn't have an email or the auth record email match with the directory one)
+			if !e.Record.Verified() && (e.Record.Email() == "" || e.Record.Email() == entryEmail) {
+				needUpdate = true
+				e.Record.SetVerified(true)
+			}
+
+			if needUpdate {
+				if err := txApp.Save(e.Record); err != nil {
+					return err
+				}
+			}
+		}
+
+		// create ExternalAuth relation if missing
+		if optExternalAuth == nil {
+			optExternalAuth = core.NewExternalAuth(txApp)
+			optExternalAuth.SetCollectionRef(e.Record.Collection().Id)
+			optExternalAuth.SetRecordRef(e.Record.Id)
+			optExternalAuth.SetProvider(core.ExternalAuthProviderLDAP)
+			optExternalAuth.SetProviderId(ldapProviderId(e.Entry))
+
+			if err := txApp.Save(optExternalAuth); err != nil {
+				return fmt.Errorf("failed to save linked rel: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func sendLDAPRecordCreateRequest(txApp core.App, e *core.RecordAuthWithLDAPRequestEvent, payload map[string]any) (*core.Record, error) {
+	ir := &core.InternalRequest{
+		Method: http.MethodPost,
+		URL:    "/api/collections/" + e.Collection.Name + "/records",
+		Body:   payload,
+	}
+
+	var createdRecord *core.Record
+	response, err := processInternalRequest(txApp, e.RequestEvent, ir, core.RequestInfoContextLDAP, func(data any) error {
+		createdRecord, _ = data.(*core.Record)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Status != http.StatusOK || createdRecord == nil {
+		return nil, errors.New("failed to create LDAP auth record")
+	}
+
+	return createdRecord, nil
+}
@@ -75,6 +75,20 @@ func recordAuthWithPassword(e *core.RequestEvent) error {
 		return e.InternalServerError("", foundErr)
 	}
 
+	var lockout *core.AuthLockout
+	releaseLockout := func() {}
+	if collection.PasswordAuth.Lockout.Enabled {
+		var release func()
+		lockout, release, err = reservePasswordAuthAttempt(e, collection, foundRecord, form.Identity)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		// release the lock as soon as the attempt is finalized
+		releaseLockout = release
+	}
+
 	event := new(core.RecordAuthWithPasswordRequestEvent)
 	event.RequestEvent = e
 	event.Collection = collection
@@ -90,9 +104,29 @@ func recordAuthWithPassword(e *core.RequestEvent) error {
 				dummyPasswordCheck(e.App, e.Collection)
 			}
 
+			if lockout != nil {
+				err := failPasswordAuthAttempt(e.RequestEvent, e.Collection, foundRecord, form.Identity, lockout)
+				if err != nil {
+					e.App.Logger().Warn("Failed to lock the password auth identity", "error", err, "identity", form.Identity)
+				}
+				releaseLockout()
+			}
+
 			return e.BadRequestError("Failed to authenticate.", errors.New("invalid login credentials"))
 		}
 
+		if lockout != nil {
+			clearPasswordAuthAttempts(e.RequestEvent, lockout)
+			releaseLockout()
+		}
+
+		passField, ok := e.Collection.Fields.GetByName(core.FieldNamePassword).(*core.PasswordField)
+		if ok && passField.HasExpired(e.App, e.Record) {
+			return e.BadRequestError("The password has expired and must be reset.", validation.Errors{
+				core.FieldNamePassword: validation.NewError("validation_password_expired", "The password has expired and must be reset."),
+			})
+		}
+
 		return RecordAuthResponse(e.RequestEvent, e.Record, core.MFAMethodPassword, nil)
 	})
 }
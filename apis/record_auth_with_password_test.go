@@ -173,6 +173,13 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnRecordValidate":            1,
 				"OnMailerSend":                1,
 				"OnMailerRecordAuthAlertSend": 1,
+				// stale auth session
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
 			},
 		},
 		{
@@ -210,6 +217,13 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnRecordValidate":            1,
 				"OnMailerSend":                1,
 				"OnMailerRecordAuthAlertSend": 1,
+				// stale auth session
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
 			},
 		},
 		{
@@ -261,6 +275,13 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnRecordValidate":            1,
 				"OnMailerSend":                1,
 				"OnMailerRecordAuthAlertSend": 1,
+				// stale auth session
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
 			},
 		},
 		{
@@ -331,6 +352,13 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnRecordValidate":            1,
 				"OnMailerSend":                1,
 				"OnMailerRecordAuthAlertSend": 1,
+				// stale auth session
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
 			},
 		},
 		{
@@ -386,6 +414,13 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnRecordValidate":            1,
 				"OnMailerSend":                1,
 				"OnMailerRecordAuthAlertSend": 1,
+				// stale auth session
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
 			},
 		},
 		{
@@ -611,6 +646,13 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnRecordValidate":            1,
 				"OnMailerSend":                1,
 				"OnMailerRecordAuthAlertSend": 1,
+				// stale auth session
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
 			},
 		},
 		{
@@ -649,6 +691,13 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnRecordValidate":            1,
 				"OnMailerSend":                1,
 				"OnMailerRecordAuthAlertSend": 1,
+				// stale auth session
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
 			},
 		},
 		{
@@ -687,6 +736,45 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnRecordValidate":            1,
 				"OnMailerSend":                1,
 				"OnMailerRecordAuthAlertSend": 1,
+				// stale auth session
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+		},
+		{
+			Name:   "valid credentials with expired password",
+			Method: http.MethodPost,
+			URL:    "/api/collections/clients/auth-with-password",
+			Body: strings.NewReader(`{
+				"identity":"test@example.com",
+				"password":"1234567890"
+			}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				clients, err := app.FindCollectionByNameOrId("clients")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// the test records are created long before the max age
+				clients.Fields.GetByName("password").(*core.PasswordField).MaxAge = 3600
+				if err := app.Save(clients); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"data":{"password":{"code":"validation_password_expired"`,
+			},
+			NotExpectedContent: []string{
+				`"token":`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                               0,
+				"OnRecordAuthWithPasswordRequest": 1,
 			},
 		},
 
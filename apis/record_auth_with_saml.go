@@ -0,0 +1,572 @@
+package apis
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/saml"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// the auth state kinds of the SAML flow steps
+const (
+	authStateKindSAMLRequest   = "samlRequest"
+	authStateKindSAMLCode      = "samlCode"
+	authStateKindSAMLAssertion = "samlAssertion"
+)
+
+const (
+	// samlRequestDuration is the max time for the users to
+	// sign in with the IdP after an SP-initiated login.
+	samlRequestDuration = 10 * time.Minute
+
+	// samlCodeDuration is the max time for exchanging
+	// the code issued after a successful SAML response.
+	samlCodeDuration = 2 * time.Minute
+)
+
+// samlRequestData is the data of a pending SP-initiated SAML login auth state.
+type samlRequestData struct {
+	RedirectURL   string `json:"redirectURL"`
+	RequestId     string `json:"requestId"`
+	CodeChallenge string `json:"codeChallenge"`
+}
+
+// samlCodeData is the data of an issued SAML code auth state.
+type samlCodeData struct {
+	Assertion *saml.Assertion `json:"assertion"`
+
+	// the S256 challenge of the client code verifier
+	// (empty for the IdP-initiated responses)
+	CodeChallenge string `json:"codeChallenge"`
+}
+
+// saveSAMLState persists a new SAML auth state with the specified kind, lookup key and data.
+func saveSAMLState(app core.App, collection *core.Collection, kind string, key string, data any, expires time.Time) error {
+	state := core.NewAuthState(app)
+	state.SetCollectionRef(collection.Id)
+	state.SetKind(kind)
+	state.SetKey(key)
+	state.SetData(data)
+
+	expiresDate, err := types.ParseDateTime(expires)
+	if err != nil {
+		return err
+	}
+	state.SetExpires(expiresDate)
+
+	return app.Save(state)
+}
+
+// consumeSAMLState removes and unmarshals into result the non-expired
+// SAML auth state with the specified kind and lookup key.
+//
+// Returns false if the state is missing, expired or
+// it was created for a different collection.
+func consumeSAMLState(app core.App, collection *core.Collection, kind string, key string, result any) bool {
+	if key == "" {
+		return false
+	}
+
+	state, err := app.ConsumeAuthState(kind, key)
+	if err != nil || state.CollectionRef() != collection.Id {
+		return false
+	}
+
+	return state.UnmarshalData(result) == nil
+}
+
+// -------------------------------------------------------------------
+
+// samlServiceProvider returns the collection SAML service provider configuration.
+func samlServiceProvider(app core.App, collection *core.Collection) (*saml.ServiceProvider, error) {
+	certs, err := saml.ParseCertificates(collection.SAML.IdPCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := strings.TrimRight(app.Settings().Meta.AppURL, "/") +
+		"/api/collections/" + url.PathEscape(collection.Id) + "/saml"
+
+	entityId := collection.SAML.SPEntityId
+	if entityId == "" {
+		entityId = baseURL + "/metadata"
+	}
+
+	return &saml.ServiceProvider{
+		EntityId:        entityId,
+		ACSURL:          baseURL + "/acs",
+		NameIdFormat:    collection.SAML.NameIdFormat,
+		IdPEntityId:     collection.SAML.IdPEntityId,
+		IdPSSOURL:       collection.SAML.IdPSSOURL,
+		IdPCertificates: certs,
+	}, nil
+}
+
+// samlRedirectURL returns the client redirect url extended with the provided query parameter.
+func samlRedirectURL(redirectURL string, key string, value string) string {
+	u, err := url.Parse(redirectURL)
+	if err != nil {
+		return redirectURL // already validated on collection save so it shouldn't happen
+	}
+
+	query := u.Query()
+	query.Set(key, value)
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+func findSAMLCollection(e *core.RequestEvent) (*core.Collection, *saml.ServiceProvider, error) {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !collection.SAML.Enabled {
+		return nil, nil, e.ForbiddenError("The collection is not configured to allow SAML authentication.", nil)
+	}
+
+	sp, err := samlServiceProvider(e.App, collection)
+	if err != nil {
+		return nil, nil, e.InternalServerError("Invalid SAML configuration.", err)
+	}
+
+	return collection, sp, nil
+}
+
+// -------------------------------------------------------------------
+
+// recordSAMLMetadata returns the collection SAML service provider metadata.
+func recordSAMLMetadata(e *core.RequestEvent) error {
+	_, sp, err := findSAMLCollection(e)
+	if err != nil {
+		return err
+	}
+
+	return e.Blob(http.StatusOK, "application/samlmetadata+xml", sp.Metadata())
+}
+
+// recordSAMLLogin starts an SP-initiated SAML login by redirecting
+// the user to the IdP single sign-on url.
+func recordSAMLLogin(e *core.RequestEvent) error {
+	collection, sp, err := findSAMLCollection(e)
+	if err != nil {
+		return err
+	}
+
+	redirectURL := e.Request.URL.Query().Get("redirectURL")
+	if redirectURL == "" {
+		redirectURL = collection.SAML.RedirectURLs[0]
+	} else if !slices.Contains(collection.SAML.RedirectURLs, redirectURL) {
+		return e.BadRequestError("The redirectURL is not one of the allowed SAML redirect urls.", nil)
+	}
+
+	// the S256 challenge of the client held code verifier
+	// that is required for exchanging the issued code
+	codeChallenge := e.Request.URL.Query().Get("codeChallenge")
+	if len(codeChallenge) != 43 {
+		return e.BadRequestError("Missing or invalid S256 codeChallenge.", nil)
+	}
+
+	relayState := security.RandomString(30)
+
+	request := samlRequestData{
+		RedirectURL:   redirectURL,
+		RequestId:     saml.NewRequestId(),
+		CodeChallenge: codeChallenge,
+	}
+
+	err = saveSAMLState(e.App, collection, authStateKindSAMLRequest, relayState, request, time.Now().Add(samlRequestDuration))
+	if err != nil {
+		return e.InternalServerError("Failed to store the SAML authentication request.", err)
+	}
+
+	authURL, err := sp.AuthnRequestURL(request.RequestId, relayState)
+	if err != nil {
+		return e.InternalServerError("Failed to build the SAML authentication request.", err)
+	}
+
+	return e.Redirect(http.StatusFound, authURL)
+}
+
+type samlACSData struct {
+	SAMLResponse string `form:"SAMLResponse" json:"SAMLResponse"`
+	RelayState   string `form:"RelayState" json:"RelayState"`
+}
+
+// recordSAMLACS handles the SAML responses posted by the IdP
+// (aka. the Assertion Consumer Service).
+//
+// On success the user is redirected to the client redirect url with
+// a short-lived one-time "code" query parameter that could be exchanged
+// for an auth token with the auth-with-saml endpoint.
+// On failure the user is redirected with an "error" query parameter instead.
+func recordSAMLACS(e *core.RequestEvent) error {
+	collection, sp, err := findSAMLCollection(e)
+	if err != nil {
+		return err
+	}
+
+	data := samlACSData{}
+	if err := e.BindBody(&data); err != nil || data.SAMLResponse == "" {
+		return e.BadRequestError("Missing or invalid SAML response.", err)
+	}
+
+	var redirectURL string
+	var inResponseTo string
+	var codeChallenge string
+
+	pending := samlRequestData{}
+	switch {
+	case consumeSAMLState(e.App, collection, authStateKindSAMLRequest, data.RelayState, &pending):
+		// SP-initiated
+		redirectURL = pending.RedirectURL
+		inResponseTo = pending.RequestId
+		codeChallenge = pending.CodeChallenge
+	case collection.SAML.AllowIdPInitiated:
+		// IdP-initiated (the RelayState could be optionally one of the allowed redirect urls)
+		if data.RelayState != "" && slices.Contains(collection.SAML.RedirectURLs, data.RelayState) {
+			redirectURL = data.RelayState
+		} else {
+			redirectURL = collection.SAML.RedirectURLs[0]
+		}
+	default:
+		return e.BadRequestError("Missing or expired SAML authentication request.", nil)
+	}
+
+	assertion, err := sp.ParseResponse(data.SAMLResponse, inResponseTo)
+	if err != nil {
+		e.App.Logger().Debug("Invalid SAML response", "error", err, "collectionId", collection.Id)
+		return e.Redirect(http.StatusSeeOther, samlRedirectURL(redirectURL, "error", "invalid_saml_response"))
+	}
+
+	// prevent assertions replay
+	// (the processed assertion ids are tracked until the assertion expiration
+	// and the unique state key makes a concurrent replay fail on save)
+	_, err = e.App.FindAuthState(authStateKindSAMLAssertion, collection.Id+assertion.Id)
+	if err == nil {
+		e.App.Logger().Debug("Already processed SAML assertion", "assertionId", assertion.Id, "collectionId", collection.Id)
+		return e.Redirect(http.StatusSeeOther, samlRedirectURL(redirectURL, "error", "invalid_saml_response"))
+	}
+	err = saveSAMLState(e.App, collection, authStateKindSAMLAssertion, collection.Id+assertion.Id, nil, assertion.ExpiresAt.Add(saml.DefaultClockSkew))
+	if err != nil {
+		e.App.Logger().Debug("Already processed or untracked SAML assertion", "assertionId", assertion.Id, "collectionId", collection.Id, "error", err)
+		return e.Redirect(http.StatusSeeOther, samlRedirectURL(redirectURL, "error", "invalid_saml_response"))
+	}
+
+	code := security.RandomString(40)
+
+	codeData := samlCodeData{
+		Assertion:     assertion,
+		CodeChallenge: codeChallenge,
+	}
+
+	err = saveSAMLState(e.App, collection, authStateKindSAMLCode, code, codeData, time.Now().Add(samlCodeDuration))
+	if err != nil {
+		return e.InternalServerError("Failed to store the SAML code.", err)
+	}
+
+	return e.Redirect(http.StatusSeeOther, samlRedirectURL(redirectURL, "code", code))
+}
+
+// -------------------------------------------------------------------
+
+func recordAuthWithSAML(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.SAML.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow SAML authentication.", nil)
+	}
+
+	e.Set(core.RequestEventKeyInfoContext, core.RequestInfoContextSAML)
+
+	form := new(recordSAMLLoginForm)
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+
+	// exchange the code and locate existing ExternalAuth rel
+	// ---------------------------------------------------------------
+
+	codeData := samlCodeData{}
+	if !consumeSAMLState(e.App, collection, authStateKindSAMLCode, form.Code, &codeData) || codeData.Assertion == nil {
+		return e.BadRequestError("Invalid or expired SAML code.", nil)
+	}
+	assertion := codeData.Assertion
+
+	// the codes issued for SP-initiated logins are bound to the
+	// client that started the login with the code verifier challenge
+	isVerifiedClient := codeData.CodeChallenge != ""
+	if isVerifiedClient && !security.Equal(security.S256Challenge(form.CodeVerifier), codeData.CodeChallenge) {
+		return e.BadRequestError("Invalid or expired SAML code.", errors.New("invalid SAML code verifier"))
+	}
+
+	// link to the logged auth record (if any) only if the code
+	// was issued for the current client (aka. not IdP-initiated)
+	var fallbackAuthRecord *core.Record
+	if isVerifiedClient && e.Auth != nil && e.Auth.Collection().Id == collection.Id {
+		fallbackAuthRecord = e.Auth
+	}
+
+	var authRecord *core.Record
+
+	// check for existing relation with the auth collection
+	externalAuthRel, err := e.App.FindFirstExternalAuthByExpr(dbx.HashExp{
+		"collectionRef": collection.Id,
+		"provider":      core.ExternalAuthProviderSAML,
+		"providerId":    assertion.NameId,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return e.InternalServerError("Failed SAML relation check.", err)
+	}
+
+	assertionEmail := samlAssertionEmail(collection, assertion)
+
+	switch {
+	case err == nil && externalAuthRel != nil:
+		authRecord, err = e.App.FindRecordById(collection, externalAuthRel.RecordRef())
+		if err != nil {
+			return err
+		}
+	case fallbackAuthRecord != nil:
+		// fallback to the logged auth record (if any)
+		authRecord = fallbackAuthRecord
+	case assertionEmail != "":
+		// look for an existing auth record by the assertion email
+		authRecord, err = e.App.FindAuthRecordByEmail(collection.Id, assertionEmail)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return e.InternalServerError("Failed SAML auth record check.", err)
+		}
+	}
+
+	// ---------------------------------------------------------------
+
+	event := new(core.RecordAuthWithSAMLRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Assertion = assertion
+	event.CreateData = form.CreateData
+	event.Record = authRecord
+	event.IsNewRecord = authRecord == nil
+
+	return e.App.OnRecordAuthWithSAMLRequest().Trigger(event, func(e *core.RecordAuthWithSAMLRequestEvent) error {
+		if err := samlSubmit(e, externalAuthRel); err != nil {
+			return firstApiError(err, e.BadRequestError("Failed to authenticate.", err))
+		}
+
+		meta := map[string]any{
+			"nameId":       e.Assertion.NameId,
+			"sessionIndex": e.Assertion.SessionIndex,
+			"isNew":        e.IsNewRecord,
+		}
+
+		return RecordAuthResponse(e.RequestEvent, e.Record, core.MFAMethodSAML, meta)
+	})
+}
+
+// -------------------------------------------------------------------
+
+type recordSAMLLoginForm struct {
+	// Additional data that will be used for creating a new auth record
+	// if an existing SAML linked account doesn't exist.
+	CreateData map[string]any `form:"createData" json:"createData"`
+
+	// The one-time code returned to the client redirect url after the SAML response processing.
+	Code string `form:"code" json:"code"`
+
+	// The code verifier whose S256 challenge was sent with the SAML login request
+	// (required for the codes issued for SP-initiated logins).
+	CodeVerifier string `form:"codeVerifier" json:"codeVerifier"`
+}
+
+func (form *recordSAMLLoginForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Code, validation.Required, validation.Length(1, 255)),
+		validation.Field(&form.CodeVerifier, validation.Length(43, 128)),
+	)
+}
+
+// samlAssertionEmail returns the assertion email based on the collection
+// email field mapping (fallbacks to the email address NameID).
+func samlAssertionEmail(collection *core.Collection, assertion *saml.Assertion) string {
+	if attr := collection.SAML.MappedFields[core.FieldNameEmail]; attr != "" {
+		return assertion.Value(attr)
+	}
+
+	if assertion.NameIdFormat == saml.NameIdFormatEmailAddress {
+		return assertion.NameId
+	}
+
+	return ""
+}
+
+// samlMappedData returns the record field values resolved from the assertion attributes.
+//
+// For multiple values fields all attribute values are returned,
+// otherwise - only the first one.
+func samlMappedData(collection *core.Collection, assertion *saml.Assertion) map[string]any {
+	data := make(map[string]any, len(collection.SAML.MappedFields)+1)
+
+	for name, attr := range collection.SAML.MappedFields {
+		mv, ok := collection.Fields.GetByName(name).(core.MultiValuer)
+		if ok && mv.IsMultiple() {
+			data[name] = assertion.Values(attr)
+		} else {
+			data[name] = assertion.Value(attr)
+		}
+	}
+
+	if _, ok := data[core.FieldNameEmail]; !ok {
+		if email := samlAssertionEmail(collection, assertion); email != "" {
+			data[core.FieldNameEmail] = email
+		}
+	}
+
+	return data
+}
+
+func samlSubmit(e *core.RecordAuthWithSAMLRequestEvent, optExternalAuth *core.ExternalAuth) error {
+	return e.App.RunInTransaction(func(txApp core.App) error {
+		assertionEmail := samlAssertionEmail(e.Collection, e.Assertion)
+		mappedData := samlMappedData(e.Collection, e.Assertion)
+
+		if e.Record == nil {
+			// extra check to prevent creating a superuser record via
+			// SAML in case the method is used by another action
+			if e.Collection.Name == core.CollectionNameSuperusers {
+				return errors.New("superusers are not allowed to sign-up with SAML")
+			}
+
+			payload := maps.Clone(e.CreateData)
+			if payload == nil {
+				payload = map[string]any{}
+			}
+
+			// the assertion attributes have precedence over the submitted data
+			maps.Copy(payload, mappedData)
+
+			createdRecord, err := sendSAMLRecordCreateRequest(txApp, e, payload)
+			if err != nil {
+				return err
+			}
+
+			e.Record = createdRecord
+
+			// mark as verified as long as it matches the assertion data (even if the email is empty)
+			if e.Record.Email() == assertionEmail && !e.Record.Verified() {
+				e.Record.SetVerified(true)
+				if err := txApp.Save(e.Record); err != nil {
+					return err
+				}
+			}
+		} else {
+			var needUpdate bool
+
+			isLoggedAuthRecord := e.Auth != nil &&
+				e.Auth.Id == e.Record.Id &&
+				e.Auth.Collection().Id == e.Record.Collection().Id
+
+			// prevent pre-hijacking with password auth
+			//
+			// reset the unverified user password in case the record was precreated by a malicious actor
+			if !isLoggedAuthRecord && !e.Record.Verified() {
+				needUpdate = true
+				e.Record.SetRandomPassword()
+			}
+
+			// prevent pre-hijacking with OAuth2 or another external identity
+			if !e.Record.Verified() {
+				err := txApp.DeleteAllExternalAuthsByRecord(e.Record)
+				if err != nil {
+					return err
+				}
+				optExternalAuth = nil // clear to allow recreate below
+			}
+
+			// sync the mapped fields
+			// (the email is updated only if empty to avoid unintended email changes)
+			for name, value := range mappedData {
+				if name == core.FieldNameEmail && e.Record.Email() != "" {
+					continue
+				}
+
+				oldValue := e.Record.Get(name)
+				e.Record.Set(name, value)
+				if !reflect.DeepEqual(oldValue, e.Record.Get(name)) {
+					needUpdate = true
+				}
+			}
+
+			// update the existing auth record verified state
+			// (only if the auth record doesn't have an email or the auth record email match with the assertion one)
+			if !e.Record.Verified() && (e.Record.Email() == "" || e.Record.Email() == assertionEmail) {
+				needUpdate = true
+				e.Record.SetVerified(true)
+			}
+
+			if needUpdate {
+				if err := txApp.Save(e.Record); err != nil {
+					return err
+				}
+			}
+		}
+
+		// create ExternalAuth relation if missing
+		if optExternalAuth == nil {
+			optExternalAuth = core.NewExternalAuth(txApp)
+			optExternalAuth.SetCollectionRef(e.Record.Collection().Id)
+			optExternalAuth.SetRecordRef(e.Record.Id)
+			optExternalAuth.SetProvider(core.ExternalAuthProviderSAML)
+			optExternalAuth.SetProviderId(e.Assertion.NameId)
+
+			if err := txApp.Save(optExternalAuth); err != nil {
+				return fmt.Errorf("failed to save linked rel: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func sendSAMLRecordCreateRequest(txApp core.App, e *core.RecordAuthWithSAMLRequestEvent, payload map[string]any) (*core.Record, error) {
+	ir := &core.InternalRequest{
+		Method: http.MethodPost,
+		URL:    "/api/collections/" + e.Collection.Name + "/records",
+		Body:   payload,
+	}
+
+	var createdRecord *core.Record
+	response, err := processInternalRequest(txApp, e.RequestEvent, ir, core.RequestInfoContextSAML, func(data any) error {
+		createdRecord, _ = data.(*core.Record)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Status != http.StatusOK || createdRecord == nil {
+		return nil, errors.New("failed to create SAML auth record")
+	}
+
+	return createdRecord, nil
+}
@@ -0,0 +1,129 @@
+package apis
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func recordAuthWithTOTP(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	// TOTP can be used only as a second factor
+	if !collection.TOTP.Enabled || !collection.MFA.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow TOTP authentication.", nil)
+	}
+
+	form := &authWithTOTPForm{}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	event := new(core.RecordAuthWithTOTPRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+
+	// extra validations
+	// (note: returns a generic 400 as a very basic MFA sessions enumeration protection)
+	// ---
+	mfa, err := e.App.FindMFAById(form.MFAId)
+	if err != nil {
+		return e.BadRequestError("Invalid or expired MFA session.", err)
+	}
+
+	if mfa.CollectionRef() != collection.Id {
+		return e.BadRequestError("Invalid or expired MFA session.", errors.New("the MFA is for a different collection"))
+	}
+
+	if mfa.HasExpired(collection.MFA.DurationTime()) {
+		return e.BadRequestError("Invalid or expired MFA session.", errors.New("the MFA is expired"))
+	}
+
+	event.Record, err = e.App.FindRecordById(collection, mfa.RecordRef())
+	if err != nil {
+		return e.BadRequestError("Invalid or expired MFA session.", fmt.Errorf("missing auth record: %w", err))
+	}
+
+	event.TOTP, err = e.App.FindTOTPByRecord(event.Record)
+	if err != nil || !event.TOTP.Confirmed() {
+		return e.BadRequestError("Invalid TOTP code.", errors.New("the record doesn't have confirmed TOTP"))
+	}
+
+	// since the codes are simple digit numbers, enforce an extra rate limit rule as basic enumeration protection
+	err = checkRateLimit(e, "@pb_totp_"+event.Record.Id, core.RateLimitRule{MaxRequests: 5, Duration: 180})
+	if err != nil {
+		return e.TooManyRequestsError("Too many attempts, please try again later.", nil)
+	}
+
+	lastStep := event.TOTP.LastStep()
+
+	if !validateTOTPOrRecoveryCode(event.TOTP, form.Code) {
+		return e.BadRequestError("Invalid TOTP code.", errors.New("incorrect code"))
+	}
+
+	// persist the last used time step with a conditional update
+	// so that concurrent requests with the same code can't be replayed
+	isRecoveryCode := event.TOTP.LastStep() == lastStep
+	if !isRecoveryCode {
+		err = e.App.UpdateTOTPLastStep(event.TOTP)
+		if errors.Is(err, core.ErrTOTPStepUsed) {
+			return e.BadRequestError("Invalid TOTP code.", err)
+		}
+		if err != nil {
+			return e.InternalServerError("Failed to update the record TOTP.", err)
+		}
+	}
+	// ---
+
+	return e.App.OnRecordAuthWithTOTPRequest().Trigger(event, func(e *core.RecordAuthWithTOTPRequestEvent) error {
+		// persist the remaining recovery codes
+		//
+		// note: don't wait for success auth response because the code
+		// was already validated and must not be replayed
+		if isRecoveryCode {
+			if err := e.App.Save(e.TOTP); err != nil {
+				return firstApiError(err, e.InternalServerError("Failed to update the record TOTP.", err))
+			}
+		}
+
+		return RecordAuthResponse(e.RequestEvent, e.Record, core.MFAMethodTOTP, nil)
+	})
+}
+
+// validateTOTPOrRecoveryCode checks whether code is either a valid authenticator
+// app code or one of the unused recovery codes of the provided TOTP model.
+//
+// On success the TOTP model is updated and must be persisted.
+func validateTOTPOrRecoveryCode(totp *core.TOTP, code string) bool {
+	code = strings.TrimSpace(code)
+
+	if len(code) == security.TOTPDigits {
+		return totp.ValidateCode(code)
+	}
+
+	return totp.UseRecoveryCode(strings.ToLower(code))
+}
+
+// -------------------------------------------------------------------
+
+type authWithTOTPForm struct {
+	MFAId string `form:"mfaId" json:"mfaId"`
+	Code  string `form:"code" json:"code"`
+}
+
+func (form *authWithTOTPForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.MFAId, validation.Required, validation.Length(1, 255)),
+		validation.Field(&form.Code, validation.Required, validation.Length(1, 100)),
+	)
+}
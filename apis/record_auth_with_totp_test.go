@@ -0,0 +1,484 @@
+package apis_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// the secret of the tests.StubTOTPRecords records
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// enableTOTP enables the TOTP second factor for the specified collection.
+func enableTOTP(t testing.TB, app core.App, collectionName string, mfa bool) {
+	collection, err := app.FindCollectionByNameOrId(collectionName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection.TOTP.Enabled = true
+	collection.MFA.Enabled = mfa
+
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// totpCodeBody returns a lazy request body with the "{CODE}" placeholder
+// replaced with the authenticator app code for the specified time offset.
+func totpCodeBody(t testing.TB, body string, offset time.Duration) io.Reader {
+	return &lazyBody{resolve: func() string {
+		code, err := security.TOTP(testTOTPSecret, time.Now().Add(offset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.ReplaceAll(body, "{CODE}", code)
+	}}
+}
+
+// createTestMFA creates a new first factor MFA record for the specified auth record.
+func createTestMFA(t testing.TB, app core.App, collectionName string, recordId string, mfaId string, created types.DateTime) {
+	record, err := app.FindRecordById(collectionName, recordId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mfa := core.NewMFA(app)
+	mfa.Id = mfaId
+	mfa.SetCollectionRef(record.Collection().Id)
+	mfa.SetRecordRef(record.Id)
+	mfa.SetMethod(core.MFAMethodPassword)
+	mfa.SetRaw("created", created)
+	mfa.SetRaw("updated", created)
+	if err := app.SaveNoValidate(mfa); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordAuthWithTOTP(t *testing.T) {
+	t.Parallel()
+
+	mfaId := strings.Repeat("a", 15)
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "not an auth collection",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/demo1/auth-with-totp",
+			Body:            strings.NewReader(`{"mfaId":"` + mfaId + `","code":"123456"}`),
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "auth collection with disabled totp",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/users/auth-with-totp",
+			Body:            strings.NewReader(`{"mfaId":"` + mfaId + `","code":"123456"}`),
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "auth collection with enabled totp but disabled mfa",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   totpCodeBody(t, `{"mfaId":"`+mfaId+`","code":"{CODE}"}`, 0),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTOTP(t, app, "users", false)
+
+				if err := tests.StubTOTPRecords(app); err != nil {
+					t.Fatal(err)
+				}
+
+				createTestMFA(t, app, "users", "4q1xlclmfloku33", mfaId, types.NowDateTime())
+			},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "invalid body",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   strings.NewReader(`{"mfaId`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTOTP(t, app, "users", true)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "empty body",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTOTP(t, app, "users", true)
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"data":{`,
+				`"mfaId":{"code":"validation_required"`,
+				`"code":{"code":"validation_required"`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+		{
+			Name:   "missing mfa",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   totpCodeBody(t, `{"mfaId":"`+mfaId+`","code":"{CODE}"}`, 0),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTOTP(t, app, "users", true)
+
+				if err := tests.StubTOTPRecords(app); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "mfa from a different collection",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   totpCodeBody(t, `{"mfaId":"`+mfaId+`","code":"{CODE}"}`, 0),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTOTP(t, app, "users", true)
+
+				if err := tests.StubTOTPRecords(app); err != nil {
+					t.Fatal(err)
+				}
+
+				createTestMFA(t, app, core.CollectionNameSuperusers, "sbmbsdb40jyxf7h", mfaId, types.NowDateTime())
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "expired mfa",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   totpCodeBody(t, `{"mfaId":"`+mfaId+`","code":"{CODE}"}`, 0),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTOTP(t, app, "users", true)
+
+				if err := tests.StubTOTPRecords(app); err != nil {
+					t.Fatal(err)
+				}
+
+				createTestMFA(t, app, "users", "4q1xlclmfloku33", mfaId, types.NowDateTime().AddDate(-1, 0, 0))
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "auth record without totp",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   totpCodeBody(t, `{"mfaId":"`+mfaId+`","code":"{CODE}"}`, 0),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTOTP(t, app, "users", true)
+
+				createTestMFA(t, app, "users", "4q1xlclmfloku33", mfaId, types.NowDateTime())
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "auth record with unconfirmed totp",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   totpCodeBody(t, `{"mfaId":"`+mfaId+`","code":"{CODE}"}`, 0),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTOTP(t, app, "users", true)
+
+				if err := tests.StubTOTPRecords(app); err != nil {
+					t.Fatal(err)
+				}
+
+				totp, err := app.FindTOTPById("user1_totp")
+				if err != nil {
+					t.Fatal(err)
+				}
+				totp.SetConfirmed(false)
+				if err := app.Save(totp); err != nil {
+					t.Fatal(err)
+				}
+
+				createTestMFA(t, app, "users", "4q1xlclmfloku33", mfaId, types.NowDateTime())
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "invalid code",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   totpCodeBody(t, `{"mfaId":"`+mfaId+`","code":"{CODE}"}`, -10*security.TOTPPeriod*time.Second),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTOTP(t, app, "users", true)
+
+				if err := tests.StubTOTPRecords(app); err != nil {
+					t.Fatal(err)
+				}
+
+				createTestMFA(t, app, "users", "4q1xlclmfloku33", mfaId, types.NowDateTime())
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "already used code",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   totpCodeBody(t, `{"mfaId":"`+mfaId+`","code":"{CODE}"}`, 0),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTOTP(t, app, "users", true)
+
+				if err := tests.StubTOTPRecords(app); err != nil {
+					t.Fatal(err)
+				}
+
+				totp, err := app.FindTOTPById("user1_totp")
+				if err != nil {
+					t.Fatal(err)
+				}
+				totp.SetLastStep(security.TOTPStep(time.Now()))
+				if err := app.Save(totp); err != nil {
+					t.Fatal(err)
+				}
+
+				createTestMFA(t, app, "users", "4q1xlclmfloku33", mfaId, types.NowDateTime())
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "valid code",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   totpCodeBody(t, `{"mfaId":"`+mfaId+`","code":"{CODE}"}`, 0),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTOTP(t, app, "users", true)
+
+				if err := tests.StubTOTPRecords(app); err != nil {
+					t.Fatal(err)
+				}
+
+				createTestMFA(t, app, "users", "4q1xlclmfloku33", mfaId, types.NowDateTime())
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":"`,
+				`"record":{`,
+				`"email":"test@example.com"`,
+			},
+			NotExpectedContent: []string{
+				`"mfaId":"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                           0,
+				"OnRecordAuthWithTOTPRequest": 1,
+				"OnRecordAuthRequest":         1,
+				"OnRecordEnrich":              1,
+				// ---
+				// (the totp lastStep is persisted with a direct conditional update)
+				"OnModelValidate":           1, // authOrigin create
+				"OnModelCreate":             1,
+				"OnModelCreateExecute":      1,
+				"OnModelAfterCreateSuccess": 1,
+				"OnModelDelete":             1, // mfa delete
+				"OnModelDeleteExecute":      1,
+				"OnModelAfterDeleteSuccess": 1,
+				// ---
+				"OnRecordValidate":           1,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				totp, err := app.FindTOTPById("user1_totp")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if step := security.TOTPStep(time.Now()); totp.LastStep() < step-1 {
+					t.Fatalf("Expected the lastStep to be updated, got %d (current step %d)", totp.LastStep(), step)
+				}
+
+				if _, err := app.FindMFAById(mfaId); err == nil {
+					t.Fatal("Expected the MFA record to be deleted")
+				}
+			},
+		},
+		{
+			Name:   "valid recovery code",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   strings.NewReader(`{"mfaId":"` + mfaId + `","code":" BBBBB-11111 "}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTOTP(t, app, "users", true)
+
+				if err := tests.StubTOTPRecords(app); err != nil {
+					t.Fatal(err)
+				}
+
+				createTestMFA(t, app, "users", "4q1xlclmfloku33", mfaId, types.NowDateTime())
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":"`,
+				`"record":{`,
+				`"email":"test@example.com"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                           0,
+				"OnRecordAuthWithTOTPRequest": 1,
+				"OnRecordAuthRequest":         1,
+				"OnRecordEnrich":              1,
+				// ---
+				"OnModelValidate":           2, // totp update + authOrigin create
+				"OnModelUpdate":             1,
+				"OnModelUpdateExecute":      1,
+				"OnModelAfterUpdateSuccess": 1,
+				"OnModelCreate":             1,
+				"OnModelCreateExecute":      1,
+				"OnModelAfterCreateSuccess": 1,
+				"OnModelDelete":             1, // mfa delete
+				"OnModelDeleteExecute":      1,
+				"OnModelAfterDeleteSuccess": 1,
+				// ---
+				"OnRecordValidate":           2,
+				"OnRecordUpdate":             1,
+				"OnRecordUpdateExecute":      1,
+				"OnRecordAfterUpdateSuccess": 1,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				totp, err := app.FindTOTPById("user1_totp")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if totp.RecoveryCodesCount() != 1 {
+					t.Fatalf("Expected 1 remaining recovery code, got %d", totp.RecoveryCodesCount())
+				}
+
+				if totp.UseRecoveryCode("bbbbb-11111") {
+					t.Fatal("Expected the used recovery code to be removed")
+				}
+			},
+		},
+
+		// rate limit checks
+		// -----------------------------------------------------------
+		{
+			Name:   "RateLimit rule - users:authWithTOTP",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().RateLimits.Enabled = true
+				app.Settings().RateLimits.Rules = []core.RateLimitRule{
+					{MaxRequests: 100, Label: "abc"},
+					{MaxRequests: 100, Label: "*:authWithTOTP"},
+					{MaxRequests: 0, Label: "users:authWithTOTP"},
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "RateLimit rule - *:auth",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().RateLimits.Enabled = true
+				app.Settings().RateLimits.Rules = []core.RateLimitRule{
+					{MaxRequests: 100, Label: "abc"},
+					{MaxRequests: 0, Label: "*:auth"},
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRecordAuthWithTOTPManualRateLimiterCheck(t *testing.T) {
+	t.Parallel()
+
+	var storeCache map[string]any
+
+	mfaId := strings.Repeat("a", 15)
+
+	scenarios := []struct {
+		code           string
+		offset         time.Duration
+		expectedStatus int
+	}{
+		{"{CODE}", -10 * security.TOTPPeriod * time.Second, 400},
+		{"{CODE}", -10 * security.TOTPPeriod * time.Second, 400},
+		{"invalid", 0, 400},
+		{"invalid", 0, 400},
+		{"invalid", 0, 400},
+		{"invalid", 0, 429},
+		{"{CODE}", 0, 429}, // reject even if it is correct
+		{"aaaaa-00000", 0, 429},
+	}
+
+	for _, s := range scenarios {
+		(&tests.ApiScenario{
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   totpCodeBody(t, `{"mfaId":"`+mfaId+`","code":"`+s.code+`"}`, s.offset),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				for k, v := range storeCache {
+					app.Store().Set(k, v)
+				}
+
+				enableTOTP(t, app, "users", true)
+
+				if err := tests.StubTOTPRecords(app); err != nil {
+					t.Fatal(err)
+				}
+
+				createTestMFA(t, app, "users", "4q1xlclmfloku33", mfaId, types.NowDateTime())
+			},
+			ExpectedStatus:  s.expectedStatus,
+			ExpectedContent: []string{`"`}, // it doesn't matter anything non-empty
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				storeCache = app.Store().GetAll()
+			},
+		}).Test(t)
+	}
+}
@@ -0,0 +1,113 @@
+package apis
+
+import (
+	"errors"
+	"fmt"
+
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/webauthn"
+)
+
+func recordAuthWithWebAuthn(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.WebAuthn.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow WebAuthn authentication.", nil)
+	}
+
+	form := &authWithWebAuthnForm{}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	event := new(core.RecordAuthWithWebAuthnRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+
+	// extra validations
+	// (note: returns a generic 400 as a very basic credentials enumeration protection)
+	// ---
+	_, challenge, ok := webauthnConsumeChallenge(e.App, collection, authStateKindWebAuthnAuth, form.ChallengeId)
+	if !ok {
+		return e.BadRequestError("Invalid or expired WebAuthn credential.", errors.New("missing or mismatched authentication challenge"))
+	}
+
+	event.Credential, err = e.App.FindWebAuthnCredentialByCredentialId(collection.Id, form.Credential.RawId.String())
+	if err != nil {
+		return e.BadRequestError("Invalid or expired WebAuthn credential.", err)
+	}
+
+	// the user handle is optional for non-discoverable credentials
+	userHandle := string(form.Credential.Response.UserHandle)
+	if userHandle != "" && userHandle != event.Credential.RecordRef() {
+		return e.BadRequestError("Invalid or expired WebAuthn credential.", errors.New("user handle mismatch"))
+	}
+
+	event.Record, err = e.App.FindRecordById(collection, event.Credential.RecordRef())
+	if err != nil {
+		return e.BadRequestError("Invalid or expired WebAuthn credential.", fmt.Errorf("missing auth record: %w", err))
+	}
+
+	publicKey, err := webauthn.DecodeBase64URL(event.Credential.PublicKey())
+	if err != nil {
+		return e.BadRequestError("Invalid or expired WebAuthn credential.", fmt.Errorf("invalid stored public key: %w", err))
+	}
+
+	signCount, err := webauthn.VerifyAssertion(
+		webauthnCeremonyOptions(e.App, collection, challenge),
+		publicKey,
+		uint32(event.Credential.SignCount()),
+		form.Credential.Response.ClientDataJSON,
+		form.Credential.Response.AuthenticatorData,
+		form.Credential.Response.Signature,
+	)
+	if err != nil {
+		return e.BadRequestError("Invalid or expired WebAuthn credential.", err)
+	}
+	// ---
+
+	return e.App.OnRecordAuthWithWebAuthnRequest().Trigger(event, func(e *core.RecordAuthWithWebAuthnRequestEvent) error {
+		// update the signature counter
+		//
+		// note: don't wait for success auth response (it could fail because of MFA)
+		// because the assertion was already verified and must not be replayed
+		if signCount > 0 {
+			e.Credential.SetSignCount(int(signCount))
+			if err := e.App.Save(e.Credential); err != nil {
+				return firstApiError(err, e.InternalServerError("Failed to update the WebAuthn credential signature counter.", err))
+			}
+		}
+
+		return RecordAuthResponse(e.RequestEvent, e.Record, core.MFAMethodWebAuthn, nil)
+	})
+}
+
+// -------------------------------------------------------------------
+
+type authWithWebAuthnForm struct {
+	ChallengeId string                     `form:"challengeId" json:"challengeId"`
+	Credential  webauthn.AssertionResponse `form:"credential" json:"credential"`
+}
+
+func (form *authWithWebAuthnForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.ChallengeId, validation.Required, validation.Length(1, 255)),
+		validation.Field(&form.Credential, validation.By(func(value any) error {
+			c, _ := value.(webauthn.AssertionResponse)
+			if len(c.RawId) == 0 ||
+				len(c.Response.ClientDataJSON) == 0 ||
+				len(c.Response.AuthenticatorData) == 0 ||
+				len(c.Response.Signature) == 0 {
+				return validation.ErrRequired
+			}
+			return nil
+		})),
+	)
+}
@@ -0,0 +1,647 @@
+package apis_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/webauthn"
+)
+
+const (
+	testWebAuthnRPId   = "localhost" // from the default app URL
+	testWebAuthnOrigin = "http://localhost:8090"
+)
+
+// testPasskey is a minimal ES256 virtual authenticator.
+type testPasskey struct {
+	key       *ecdsa.PrivateKey
+	id        []byte
+	signCount uint32
+}
+
+func newTestPasskey(t testing.TB) *testPasskey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testPasskey{key: key, id: []byte("test_passkey_id")}
+}
+
+func (p *testPasskey) coseKey(t testing.TB) []byte {
+	raw, err := webauthn.EncodeCBOR(map[any]any{
+		1:  2,
+		3:  webauthn.AlgES256,
+		-1: 1,
+		-2: p.key.X.FillBytes(make([]byte, 32)),
+		-3: p.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return raw
+}
+
+func (p *testPasskey) authData(t testing.TB, attested bool) []byte {
+	rpIdHash := sha256.Sum256([]byte(testWebAuthnRPId))
+
+	flags := webauthn.FlagUserPresent | webauthn.FlagUserVerified
+	if attested {
+		flags |= webauthn.FlagAttestedCredentialData
+	}
+
+	data := append([]byte{}, rpIdHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, p.signCount)
+
+	if attested {
+		data = append(data, make([]byte, 16)...) // aaguid
+		data = binary.BigEndian.AppendUint16(data, uint16(len(p.id)))
+		data = append(data, p.id...)
+		data = append(data, p.coseKey(t)...)
+	}
+
+	return data
+}
+
+func testWebAuthnClientData(t testing.TB, typ string, challenge string) []byte {
+	raw, err := json.Marshal(webauthn.ClientData{Type: typ, Challenge: challenge, Origin: testWebAuthnOrigin})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return raw
+}
+
+// registrationJSON returns a serialized navigator.credentials.create() response.
+func (p *testPasskey) registrationJSON(t testing.TB, challenge string) string {
+	attestation, err := webauthn.EncodeCBOR(map[any]any{
+		"fmt":      "none",
+		"attStmt":  map[any]any{},
+		"authData": p.authData(t, true),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credential := webauthn.RegistrationResponse{
+		Id:    webauthn.Base64URL(p.id).String(),
+		RawId: p.id,
+		Type:  "public-key",
+	}
+	credential.Response.ClientDataJSON = testWebAuthnClientData(t, webauthn.ClientDataTypeCreate, challenge)
+	credential.Response.AttestationObject = attestation
+	credential.Response.Transports = []string{"internal"}
+
+	raw, err := json.Marshal(credential)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(raw)
+}
+
+// assertionJSON returns a serialized navigator.credentials.get() response.
+func (p *testPasskey) assertionJSON(t testing.TB, challenge string, userHandle string) string {
+	authData := p.authData(t, false)
+	clientData := testWebAuthnClientData(t, webauthn.ClientDataTypeGet, challenge)
+
+	clientDataHash := sha256.Sum256(clientData)
+	signed := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	sig, err := ecdsa.SignASN1(rand.Reader, p.key, signed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credential := webauthn.AssertionResponse{
+		Id:    webauthn.Base64URL(p.id).String(),
+		RawId: p.id,
+		Type:  "public-key",
+	}
+	credential.Response.ClientDataJSON = clientData
+	credential.Response.AuthenticatorData = authData
+	credential.Response.Signature = sig
+	credential.Response.UserHandle = []byte(userHandle)
+
+	raw, err := json.Marshal(credential)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(raw)
+}
+
+// save stores the passkey as WebAuthn credential of the provided auth record.
+func (p *testPasskey) save(t testing.TB, app core.App, authRecord *core.Record, signCount int) *core.WebAuthnCredential {
+	credential := core.NewWebAuthnCredential(app)
+	credential.SetCollectionRef(authRecord.Collection().Id)
+	credential.SetRecordRef(authRecord.Id)
+	credential.SetName("test")
+	credential.SetCredentialId(webauthn.Base64URL(p.id).String())
+	credential.SetPublicKey(webauthn.Base64URL(p.coseKey(t)).String())
+	credential.SetAlgorithm(webauthn.AlgES256)
+	credential.SetSignCount(signCount)
+	if err := app.Save(credential); err != nil {
+		t.Fatal(err)
+	}
+
+	return credential
+}
+
+// lazyBody is a request body reader that is resolved on its first read
+// (aka. after the scenario BeforeTestFunc execution).
+type lazyBody struct {
+	resolve func() string
+	reader  io.Reader
+}
+
+func (b *lazyBody) Read(p []byte) (int, error) {
+	if b.reader == nil {
+		b.reader = strings.NewReader(b.resolve())
+	}
+
+	return b.reader.Read(p)
+}
+
+// enableWebAuthn enables the WebAuthn auth for the specified collection.
+func enableWebAuthn(t testing.TB, app core.App, collectionName string, mfa bool) {
+	collection, err := app.FindCollectionByNameOrId(collectionName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection.WebAuthn.Enabled = true
+	collection.MFA.Enabled = mfa
+
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// requestWebAuthnChallenge sends a WebAuthn challenge request to the
+// specified url and returns the challenge id and value.
+func requestWebAuthnChallenge(t testing.TB, e *core.ServeEvent, url string, token string) (string, string) {
+	mux, err := e.Router.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, url, nil)
+	req.Header.Set("Authorization", token)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Failed to request WebAuthn challenge (%d): %s", rec.Code, rec.Body.String())
+	}
+
+	result := struct {
+		ChallengeId string `json:"challengeId"`
+		PublicKey   struct {
+			Challenge string `json:"challenge"`
+		} `json:"publicKey"`
+	}{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+
+	return result.ChallengeId, result.PublicKey.Challenge
+}
+
+// -------------------------------------------------------------------
+
+func TestRecordAuthWithWebAuthn(t *testing.T) {
+	t.Parallel()
+
+	const authRequestURL = "/api/collections/users/request-webauthn-auth"
+	const userId = "4q1xlclmfloku33"
+
+	// validAuthScenario returns a new BeforeTestFunc and body that
+	// perform a valid assertion with the provided passkey.
+	validAuthScenario := func(mfa bool, userHandle string, storedSignCount int) (func(t testing.TB, app *tests.TestApp, e *core.ServeEvent), io.Reader) {
+		var body string
+
+		before := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+			enableWebAuthn(t, app, "users", mfa)
+
+			user, err := app.FindRecordById("users", userId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			passkey := newTestPasskey(t)
+			passkey.signCount = 5
+			passkey.save(t, app, user, storedSignCount)
+
+			challengeId, challenge := requestWebAuthnChallenge(t, e, authRequestURL, "")
+
+			body = `{"challengeId":"` + challengeId + `","credential":` + passkey.assertionJSON(t, challenge, userHandle) + `}`
+		}
+
+		return before, &lazyBody{resolve: func() string { return body }}
+	}
+
+	successBefore, successBody := validAuthScenario(false, userId, 2)
+	mfaBefore, mfaBody := validAuthScenario(true, userId, 0)
+	userHandleBefore, userHandleBody := validAuthScenario(false, "abc", 0)
+	clonedBefore, clonedBody := validAuthScenario(false, userId, 5)
+
+	var reusedBody string
+	var invalidSignatureBody string
+	var differentCollectionBody string
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "not an auth collection",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/demo1/auth-with-webauthn",
+			Body:            strings.NewReader(`{"challengeId":"test"}`),
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "auth collection with disabled webauthn",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/users/auth-with-webauthn",
+			Body:            strings.NewReader(`{"challengeId":"test"}`),
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "invalid body",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			Body:   strings.NewReader(`{"challengeId`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableWebAuthn(t, app, "users", false)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "empty body",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			Body:   strings.NewReader(``),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableWebAuthn(t, app, "users", false)
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"data":{`,
+				`"challengeId":{"code":"validation_required"`,
+				`"credential":{"code":"validation_required"`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+		{
+			Name:   "missing challenge",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			Body: &lazyBody{resolve: func() string {
+				return `{"challengeId":"missing","credential":` + newTestPasskey(t).assertionJSON(t, "abc", userId) + `}`
+			}},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableWebAuthn(t, app, "users", false)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "challenge for a different collection",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			Body:   &lazyBody{resolve: func() string { return differentCollectionBody }},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableWebAuthn(t, app, "users", false)
+				enableWebAuthn(t, app, "clients", false)
+
+				user, err := app.FindRecordById("users", userId)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				passkey := newTestPasskey(t)
+				passkey.save(t, app, user, 0)
+
+				challengeId, challenge := requestWebAuthnChallenge(t, e, "/api/collections/clients/request-webauthn-auth", "")
+
+				differentCollectionBody = `{"challengeId":"` + challengeId + `","credential":` + passkey.assertionJSON(t, challenge, userId) + `}`
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents: map[string]int{
+				"*": 0,
+				// consumed challenge
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+		},
+		{
+			Name:   "unknown credential",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			Body:   &lazyBody{resolve: func() string { return reusedBody }},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableWebAuthn(t, app, "users", false)
+
+				challengeId, challenge := requestWebAuthnChallenge(t, e, authRequestURL, "")
+
+				// not saved
+				passkey := newTestPasskey(t)
+
+				reusedBody = `{"challengeId":"` + challengeId + `","credential":` + passkey.assertionJSON(t, challenge, userId) + `}`
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents: map[string]int{
+				"*": 0,
+				// consumed challenge
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+		},
+		{
+			Name:            "mismatched user handle",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/users/auth-with-webauthn",
+			Body:            userHandleBody,
+			BeforeTestFunc:  userHandleBefore,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents: map[string]int{
+				"*": 0,
+				// consumed challenge
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+		},
+		{
+			Name:   "invalid signature",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			Body:   &lazyBody{resolve: func() string { return invalidSignatureBody }},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableWebAuthn(t, app, "users", false)
+
+				user, err := app.FindRecordById("users", userId)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				passkey := newTestPasskey(t)
+				passkey.save(t, app, user, 0)
+
+				// sign with a different key
+				passkey.key = newTestPasskey(t).key
+
+				challengeId, challenge := requestWebAuthnChallenge(t, e, authRequestURL, "")
+
+				invalidSignatureBody = `{"challengeId":"` + challengeId + `","credential":` + passkey.assertionJSON(t, challenge, userId) + `}`
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents: map[string]int{
+				"*": 0,
+				// consumed challenge
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+		},
+		{
+			Name:            "not increased signature counter",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/users/auth-with-webauthn",
+			Body:            clonedBody,
+			BeforeTestFunc:  clonedBefore,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents: map[string]int{
+				"*": 0,
+				// consumed challenge
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+		},
+		{
+			Name:            "valid assertion (enabled MFA)",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/users/auth-with-webauthn",
+			Body:            mfaBody,
+			BeforeTestFunc:  mfaBefore,
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"mfaId":"`},
+			ExpectedEvents: map[string]int{
+				"*":                               0,
+				"OnRecordAuthWithWebAuthnRequest": 1,
+				"OnRecordAuthRequest":             1,
+				// ---
+				"OnModelValidate":           2,
+				"OnModelCreate":             1, // mfa record
+				"OnModelCreateExecute":      1,
+				"OnModelAfterCreateSuccess": 1,
+				"OnModelUpdate":             1, // sign counter
+				"OnModelUpdateExecute":      1,
+				"OnModelAfterUpdateSuccess": 1,
+				// ---
+				"OnRecordValidate":           2,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+				"OnRecordUpdate":             1,
+				"OnRecordUpdateExecute":      1,
+				"OnRecordAfterUpdateSuccess": 1,
+				// consumed challenge
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+		},
+		{
+			Name:           "valid assertion (disabled MFA)",
+			Method:         http.MethodPost,
+			URL:            "/api/collections/users/auth-with-webauthn",
+			Body:           successBody,
+			BeforeTestFunc: successBefore,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":"`,
+				`"record":{`,
+				`"email":"test@example.com"`,
+			},
+			NotExpectedContent: []string{
+				`"meta":`,
+				// hidden fields
+				`"tokenKey"`,
+				`"password"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                               0,
+				"OnRecordAuthWithWebAuthnRequest": 1,
+				"OnRecordAuthRequest":             1,
+				"OnRecordEnrich":                  1,
+				// ---
+				"OnModelValidate":           2,
+				"OnModelCreate":             1, // authOrigin
+				"OnModelCreateExecute":      1,
+				"OnModelAfterCreateSuccess": 1,
+				"OnModelUpdate":             1, // sign counter
+				"OnModelUpdateExecute":      1,
+				"OnModelAfterUpdateSuccess": 1,
+				// ---
+				"OnRecordValidate":           2,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+				"OnRecordUpdate":             1,
+				"OnRecordUpdateExecute":      1,
+				"OnRecordAfterUpdateSuccess": 1,
+				// consumed challenge
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				credential, err := app.FindWebAuthnCredentialByCredentialId(
+					"_pb_users_auth_",
+					webauthn.Base64URL("test_passkey_id").String(),
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if v := credential.SignCount(); v != 5 {
+					t.Fatalf("Expected the sign counter to be updated to 5, got %d", v)
+				}
+			},
+		},
+
+		// rate limit checks
+		// -----------------------------------------------------------
+		{
+			Name:   "RateLimit rule - users:authWithWebAuthn",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().RateLimits.Enabled = true
+				app.Settings().RateLimits.Rules = []core.RateLimitRule{
+					{MaxRequests: 100, Label: "abc"},
+					{MaxRequests: 100, Label: "*:authWithWebAuthn"},
+					{MaxRequests: 100, Label: "users:auth"},
+					{MaxRequests: 0, Label: "users:authWithWebAuthn"},
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "RateLimit rule - *:auth",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().RateLimits.Enabled = true
+				app.Settings().RateLimits.Rules = []core.RateLimitRule{
+					{MaxRequests: 100, Label: "abc"},
+					{MaxRequests: 0, Label: "*:auth"},
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRecordAuthWithWebAuthnChallengeReuse(t *testing.T) {
+	t.Parallel()
+
+	var body string
+
+	scenario := tests.ApiScenario{
+		Method: http.MethodPost,
+		URL:    "/api/collections/users/auth-with-webauthn",
+		Body:   &lazyBody{resolve: func() string { return body }},
+		BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+			enableWebAuthn(t, app, "users", false)
+
+			user, err := app.FindRecordById("users", "4q1xlclmfloku33")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			passkey := newTestPasskey(t)
+			passkey.save(t, app, user, 0)
+
+			challengeId, challenge := requestWebAuthnChallenge(t, e, "/api/collections/users/request-webauthn-auth", "")
+
+			body = `{"challengeId":"` + challengeId + `","credential":` + passkey.assertionJSON(t, challenge, user.Id) + `}`
+
+			// consume the challenge with a first successful auth
+			mux, err := e.Router.BuildMux()
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/collections/users/auth-with-webauthn", strings.NewReader(body))
+			req.Header.Set("content-type", "application/json")
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected the first auth to succeed, got %d: %s", rec.Code, rec.Body.String())
+			}
+		},
+		ExpectedStatus:  400,
+		ExpectedContent: []string{`"data":{}`},
+		ExpectedEvents:  map[string]int{"*": 0},
+	}
+
+	scenario.Test(t)
+}
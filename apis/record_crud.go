@@ -1,6 +1,7 @@
 package apis
 
 import (
+	"context"
 	cryptoRand "crypto/rand"
 	"errors"
 	"fmt"
@@ -27,6 +28,12 @@ import (
 func bindRecordCrudApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
 	subGroup := rg.Group("/collections/{collection}/records").Unbind(DefaultRateLimitMiddlewareId)
 	subGroup.GET("", recordsList)
+	subGroup.GET("/aggregate", recordsAggregate)
+	subGroup.GET("/trash", recordsTrashList).Bind(RequireSuperuserAuth())
+	subGroup.DELETE("/trash/{id}", recordTrashDelete).Bind(RequireSuperuserAuth())
+	subGroup.POST("/{id}/restore", recordRestore).Bind(RequireSuperuserAuth())
+	subGroup.GET("/{id}/revisions", recordRevisionsList).Bind(RequireSuperuserAuth())
+	subGroup.POST("/{id}/revisions/{revisionId}/revert", recordRevert).Bind(RequireSuperuserAuth())
 	subGroup.GET("/{id}", recordView)
 	subGroup.POST("", recordCreate(true, nil)).Bind(dynamicCollectionBodyLimit(""))
 	subGroup.PATCH("/{id}", recordUpdate(true, nil)).Bind(dynamicCollectionBodyLimit(""))
@@ -39,6 +46,11 @@ func recordsList(e *core.RequestEvent) error {
 		return e.NotFoundError("Missing collection context.", err)
 	}
 
+	err = checkAPIKeyScope(e, collection, core.APIKeyScopeActionList)
+	if err != nil {
+		return err
+	}
+
 	err = checkCollectionRateLimit(e, collection, "list")
 	if err != nil {
 		return err
@@ -147,6 +159,11 @@ func recordView(e *core.RequestEvent) error {
 		return e.NotFoundError("Missing collection context.", err)
 	}
 
+	err = checkAPIKeyScope(e, collection, core.APIKeyScopeActionView)
+	if err != nil {
+		return err
+	}
+
 	err = checkCollectionRateLimit(e, collection, "view")
 	if err != nil {
 		return err
@@ -196,6 +213,9 @@ func recordView(e *core.RequestEvent) error {
 	event.Record = record
 
 	return e.App.OnRecordViewRequest().Trigger(event, func(e *core.RecordRequestEvent) error {
+		// note: resolved before the enrich to reflect only the stored record state
+		setRecordETagHeader(e.RequestEvent, e.Record)
+
 		if err := EnrichRecord(e.RequestEvent, e.Record); err != nil {
 			return firstApiError(err, e.InternalServerError("Failed to enrich record", err))
 		}
@@ -217,6 +237,11 @@ func recordCreate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			return e.BadRequestError("Unsupported collection type.", nil)
 		}
 
+		err = checkAPIKeyScope(e, collection, core.APIKeyScopeActionCreate)
+		if err != nil {
+			return err
+		}
+
 		err = checkCollectionRateLimit(e, collection, "create")
 		if err != nil {
 			return err
@@ -239,9 +264,11 @@ func recordCreate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			return firstApiError(err, e.BadRequestError("Failed to read the submitted data.", err))
 		}
 
-		// set a random password for the OAuth2 ignoring its plain password validators
+		// set a random password for the OAuth2, LDAP and SAML auth records ignoring its plain password validators
 		var skipPlainPasswordRecordValidators bool
-		if requestInfo.Context == core.RequestInfoContextOAuth2 {
+		if requestInfo.Context == core.RequestInfoContextOAuth2 ||
+			requestInfo.Context == core.RequestInfoContextLDAP ||
+			requestInfo.Context == core.RequestInfoContextSAML {
 			if _, ok := data[core.FieldNamePassword]; !ok {
 				data[core.FieldNamePassword] = security.RandomString(30)
 				data[core.FieldNamePassword+"Confirm"] = data[core.FieldNamePassword]
@@ -336,6 +363,8 @@ func recordCreate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			}
 		}
 
+		prescanRecordFiles(e, record)
+
 		var isOptFinalizerCalled bool
 
 		event := new(core.RecordRequestEvent)
@@ -346,12 +375,15 @@ func recordCreate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 		hookErr := e.App.OnRecordCreateRequest().Trigger(event, func(e *core.RecordRequestEvent) error {
 			form.SetApp(e.App)
 			form.SetRecord(e.Record)
+			form.SetContext(recordRequestContext(e.RequestEvent))
 
 			err := form.Submit()
 			if err != nil {
 				return firstApiError(err, e.BadRequestError("Failed to create record", err))
 			}
 
+			setRecordETagHeader(e.RequestEvent, e.Record)
+
 			err = EnrichRecord(e.RequestEvent, e.Record)
 			if err != nil {
 				return firstApiError(err, e.InternalServerError("Failed to enrich record", err))
@@ -400,6 +432,11 @@ func recordUpdate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			return e.BadRequestError("Unsupported collection type.", nil)
 		}
 
+		err = checkAPIKeyScope(e, collection, core.APIKeyScopeActionUpdate)
+		if err != nil {
+			return err
+		}
+
 		err = checkCollectionRateLimit(e, collection, "update")
 		if err != nil {
 			return err
@@ -475,6 +512,12 @@ func recordUpdate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			form.GrantManagerAccess()
 		}
 
+		if etags := ifMatchETags(e); len(etags) > 0 {
+			record.IfMatch(etags...)
+		}
+
+		prescanRecordFiles(e, record)
+
 		var isOptFinalizerCalled bool
 
 		event := new(core.RecordRequestEvent)
@@ -485,12 +528,18 @@ func recordUpdate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 		hookErr := e.App.OnRecordUpdateRequest().Trigger(event, func(e *core.RecordRequestEvent) error {
 			form.SetApp(e.App)
 			form.SetRecord(e.Record)
+			form.SetContext(recordRequestContext(e.RequestEvent))
 
 			err := form.Submit()
 			if err != nil {
+				if apiErr := recordETagApiError(e.RequestEvent, err); apiErr != nil {
+					return apiErr
+				}
 				return firstApiError(err, e.BadRequestError("Failed to update record.", err))
 			}
 
+			setRecordETagHeader(e.RequestEvent, e.Record)
+
 			err = EnrichRecord(e.RequestEvent, e.Record)
 			if err != nil {
 				return firstApiError(err, e.InternalServerError("Failed to enrich record", err))
@@ -539,6 +588,11 @@ func recordDelete(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			return e.BadRequestError("Unsupported collection type.", nil)
 		}
 
+		err = checkAPIKeyScope(e, collection, core.APIKeyScopeActionDelete)
+		if err != nil {
+			return err
+		}
+
 		err = checkCollectionRateLimit(e, collection, "delete")
 		if err != nil {
 			return err
@@ -582,6 +636,10 @@ func recordDelete(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			return e.NotFoundError("", err)
 		}
 
+		if etags := ifMatchETags(e); len(etags) > 0 {
+			record.IfMatch(etags...)
+		}
+
 		var isOptFinalizerCalled bool
 
 		event := new(core.RecordRequestEvent)
@@ -590,7 +648,10 @@ func recordDelete(responseWriteAfterTx bool, optFinalizer func(data any) error)
 		event.Record = record
 
 		hookErr := e.App.OnRecordDeleteRequest().Trigger(event, func(e *core.RecordRequestEvent) error {
-			if err := e.App.Delete(e.Record); err != nil {
+			if err := e.App.DeleteWithContext(recordRequestContext(e.RequestEvent), e.Record); err != nil {
+				if apiErr := recordETagApiError(e.RequestEvent, err); apiErr != nil {
+					return apiErr
+				}
 				return firstApiError(err, e.BadRequestError("Failed to delete record. Make sure that the record is not part of a required relation reference.", err))
 			}
 
@@ -642,6 +703,20 @@ func recordDataFromRequest(e *core.RequestEvent, record *core.Record) (map[strin
 	if err != nil {
 		return nil, err
 	}
+
+	// resolve the attached completed resumable uploads
+	attachedUploads, err := extractAttachedUploads(e, record.Collection(), info.Body)
+	if err != nil {
+		return nil, err
+	}
+	for k, files := range attachedUploads {
+		if uploadedFiles == nil {
+			uploadedFiles = map[string][]*filesystem.File{}
+		}
+		uploadedFiles[k] = append(uploadedFiles[k], files...)
+
+		delete(result, strings.TrimSuffix(k, "+")+uploadFieldModifier)
+	}
 	if len(uploadedFiles) > 0 {
 		for k, files := range uploadedFiles {
 			uploaded := make([]any, 0, len(files))
@@ -764,3 +839,71 @@ func hasAuthManageAccess(app core.App, requestInfo *core.RequestInfo, collection
 
 	return err == nil && exists > 0
 }
+
+// recordRequestContext returns the request context extended
+// with the request auth (if any).
+//
+// It is used for example to identify the acting auth record in the records revisions history.
+func recordRequestContext(e *core.RequestEvent) context.Context {
+	return core.ContextWithAuth(e.Request.Context(), e.Auth)
+}
+
+// prescanRecordFiles scans the unsaved record files before the save so that
+// the (possibly slow) file scans don't hold the db write lock in case the
+// save is wrapped in a transaction (e.g. from a request hook).
+//
+// The batch requests files are pre-scanned before the batch transaction start.
+func prescanRecordFiles(e *core.RequestEvent, record *core.Record) {
+	if e.App.IsTransactional() {
+		return
+	}
+
+	for _, field := range record.Collection().Fields {
+		fileField, ok := field.(*core.FileField)
+		if !ok {
+			continue
+		}
+
+		files := record.GetUnsavedFiles(fileField.Name)
+		if len(files) > 0 {
+			fileField.PreScanFiles(recordRequestContext(e), e.App, record, files...)
+		}
+	}
+}
+
+// setRecordETagHeader sets the response ETag header with the stored record state entity tag
+// (if the record state could be identified, see [core.Record.ETag]).
+func setRecordETagHeader(e *core.RequestEvent, record *core.Record) {
+	if etag := record.ETag(); etag != "" {
+		e.Response.Header().Set("ETag", etag)
+	}
+}
+
+// recordETagApiError returns the 412 API error of the record
+// If-Match condition failure (or nil if err is not such failure).
+func recordETagApiError(e *core.RequestEvent, err error) *router.ApiError {
+	switch {
+	case errors.Is(err, core.ErrRecordETagMismatch):
+		return e.Error(http.StatusPreconditionFailed, "The record was modified since it was last loaded.", err)
+	case errors.Is(err, core.ErrRecordETagUnavailable):
+		return e.Error(http.StatusPreconditionFailed, "The record state cannot be identified for a conditional request (only If-Match: * is supported for this collection).", err)
+	}
+
+	return nil
+}
+
+// ifMatchETags returns the entity tags from the request If-Match header(s) (if any).
+func ifMatchETags(e *core.RequestEvent) []string {
+	var result []string
+
+	for _, v := range e.Request.Header.Values("If-Match") {
+		for _, etag := range strings.Split(v, ",") {
+			etag = strings.TrimSpace(etag)
+			if etag != "" {
+				result = append(result, etag)
+			}
+		}
+	}
+
+	return result
+}
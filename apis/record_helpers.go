@@ -33,16 +33,54 @@ var ErrMFA = errors.New("mfa required")
 //
 // Set authMethod to empty string if you want to ignore the MFA checks and the login alerts
 // (can be also adjusted additionally via the OnRecordAuthRequest hook).
+//
+// The generated auth token is bound to a new or existing (same IP and user agent)
+// auth session that is persisted on successful authentication.
 func RecordAuthResponse(e *core.RequestEvent, authRecord *core.Record, authMethod string, meta any) error {
-	token, tokenErr := authRecord.NewAuthToken()
+	session, err := newAuthSession(e, authRecord)
+	if err != nil {
+		return e.InternalServerError("Failed to load auth session.", err)
+	}
+
+	token, tokenErr := newAuthToken(e.App, authRecord, 0, true, session.current.Id)
 	if tokenErr != nil {
 		return e.InternalServerError("Failed to create auth token.", tokenErr)
 	}
 
-	return recordAuthResponse(e, authRecord, token, authMethod, meta)
+	return recordAuthResponse(e, authRecord, token, authMethod, meta, session)
 }
 
-func recordAuthResponse(e *core.RequestEvent, authRecord *core.Record, token string, authMethod string, meta any) error {
+// newAuthToken generates a new auth token for the provided record
+// (optionally bound to the specified auth session).
+//
+// If enabled in the app settings, the token is signed with the active
+// asymmetric app signing key instead of the record HMAC secret.
+//
+// Zero or negative duration will fallback to the duration from the auth collection settings.
+func newAuthToken(app core.App, record *core.Record, duration time.Duration, refreshable bool, sessionId string) (string, error) {
+	if app.Settings().SigningKeys.AuthTokens {
+		key, err := app.ActiveSigningKey()
+		if err != nil {
+			return "", err
+		}
+
+		return record.NewSignedAuthToken(key, duration, refreshable, sessionId)
+	}
+
+	switch {
+	case !refreshable:
+		return record.NewStaticAuthToken(duration)
+	case sessionId != "":
+		return record.NewSessionAuthToken(sessionId)
+	default:
+		return record.NewAuthToken()
+	}
+}
+
+// recordAuthResponse writes the auth response for the provided token.
+//
+// session is optional and if set it will be persisted after a successful authentication.
+func recordAuthResponse(e *core.RequestEvent, authRecord *core.Record, token string, authMethod string, meta any, session *authSession) error {
 	if authRecord.IsSuperuser() {
 		allowedIPs := e.App.Settings().SuperuserIPs
 		if len(allowedIPs) > 0 && !isIPInList(allowedIPs, e.RealIP()) {
@@ -118,9 +156,16 @@ func recordAuthResponse(e *core.RequestEvent, authRecord *core.Record, token str
 			return err
 		}
 
-		if e.AuthMethod != "" && authRecord.Collection().AuthAlert.Enabled {
-			if err = authAlert(e.RequestEvent, e.Record); err != nil {
-				e.App.Logger().Warn("[recordAuthResponse] Failed to send login alert", "error", err)
+		if session != nil {
+			if err = session.save(e.RequestEvent); err != nil {
+				return e.InternalServerError("Failed to save auth session.", err)
+			}
+
+			// send email alert for the new origin auth (skip first login)
+			if session.isNewOrigin && !session.isFirst() && e.AuthMethod != "" && authRecord.Collection().AuthAlert.Enabled {
+				if err = authAlert(e.RequestEvent, e.Record); err != nil {
+					e.App.Logger().Warn("[recordAuthResponse] Failed to send login alert", "error", err)
+				}
 			}
 		}
 
@@ -507,7 +552,12 @@ func autoResolveRecordsFlags(app core.App, records []*core.Record, requestInfo *
 	return nil
 }
 
-var ruleQueryParams = []string{search.FilterQueryParam, search.SortQueryParam}
+var ruleQueryParams = []string{
+	search.FilterQueryParam,
+	search.SortQueryParam,
+	search.GroupByQueryParam,
+	search.AggregateQueryParam,
+}
 var superuserOnlyRuleFields = []string{"@collection.", "@request."}
 
 // checkForSuperuserOnlyRuleFields loosely checks and returns an error if
@@ -584,81 +634,132 @@ func execAfterSuccessTx(checkTx bool, app core.App, fn func() error) error {
 
 // -------------------------------------------------------------------
 
-const maxAuthOrigins = 5
-
-func authAlert(e *core.RequestEvent, authRecord *core.Record) error {
-	// generate fingerprint
-	// ---
-	ip := e.RealIP()
+// authSession holds the auth origin (aka. auth session) of the current request.
+type authSession struct {
+	current  *core.AuthOrigin
+	existing []*core.AuthOrigin // all previously persisted auth origins of the auth record (in DESC order)
 
-	userAgent := e.Request.UserAgent()
-	if len(userAgent) > 200 {
-		userAgent = userAgent[:200] + "..."
-	}
+	// isNewOrigin reports whether there is no other auth session
+	// with the current request fingerprint (aka. new login location)
+	isNewOrigin bool
+}
 
+// newAuthSession initializes a new auth origin (with pregenerated id)
+// for the current request.
+//
+// Each login creates its own auth session so that the sessions of the
+// different devices with the same fingerprint (IP + user agent) can be
+// revoked individually.
+func newAuthSession(e *core.RequestEvent, authRecord *core.Record) (*authSession, error) {
+	ip, userAgent := authSessionClientInfo(e)
 	fingerprint := security.MD5(ip + userAgent)
-	alertInfo := fmt.Sprintf("%s - %s %s", types.NowDateTime().String(), ip, userAgent)
-	// ---
 
 	origins, err := e.App.FindAllAuthOriginsByRecord(authRecord)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	isFirstLogin := len(origins) == 0
+	session := &authSession{existing: origins, isNewOrigin: true}
 
-	var currentOrigin *core.AuthOrigin
 	for _, origin := range origins {
 		if origin.Fingerprint() == fingerprint {
-			currentOrigin = origin
+			session.isNewOrigin = false
 			break
 		}
 	}
-	if currentOrigin == nil {
-		currentOrigin = core.NewAuthOrigin(e.App)
-		currentOrigin.SetCollectionRef(authRecord.Collection().Id)
-		currentOrigin.SetRecordRef(authRecord.Id)
-		currentOrigin.SetFingerprint(fingerprint)
-	}
 
-	// send email alert for the new origin auth (skip first login)
+	session.current = core.NewAuthOrigin(e.App)
+	session.current.Id = core.GenerateDefaultRandomId()
+	session.current.SetCollectionRef(authRecord.Collection().Id)
+	session.current.SetRecordRef(authRecord.Id)
+	session.current.SetFingerprint(fingerprint)
+
+	return session, nil
+}
+
+// isFirst reports whether this is the first persisted auth session of the auth record.
+func (s *authSession) isFirst() bool {
+	return len(s.existing) == 0
+}
+
+// save creates the current auth origin and removes the stale
+// auth record sessions that can no longer have a valid auth token.
+func (s *authSession) save(e *core.RequestEvent) error {
+	// delete the sessions that were not used for longer than the auth token duration
+	// (the lastSeen field is updated on each authenticated request, including the
+	// token refresh, at most once per authSessionLastSeenInterval)
 	//
-	// Note: The "fake" timeout is a temp solution to avoid blocking
-	//       for too long when the SMTP server is not accessible, due
-	//       to the lack of context cancellation support in the underlying
-	//       mailer and net/smtp package.
-	//       The goroutine technically "leaks" but we assume that the OS will
-	//       terminate the connection after some time (usually after 3-4 mins).
-	if !isFirstLogin && currentOrigin.IsNew() && authRecord.Email() != "" {
-		mailSent := make(chan error, 1)
-
-		timer := time.AfterFunc(15*time.Second, func() {
-			mailSent <- errors.New("auth alert mail send wait timeout reached")
-		})
+	// note: it is not executed in a transaction to avoid unnecessary locks
+	staleDate := time.Now().Add(-s.current.Collection().AuthToken.DurationTime() - authSessionLastSeenInterval)
+	for _, origin := range s.existing {
+		lastSeen := origin.LastSeen()
+		if lastSeen.IsZero() {
+			lastSeen = origin.Updated()
+		}
 
-		routine.FireAndForget(func() {
-			err := mails.SendRecordAuthAlert(e.App, authRecord, alertInfo)
-			timer.Stop()
-			mailSent <- err
-		})
+		if !lastSeen.Time().Before(staleDate) {
+			continue
+		}
 
-		err = <-mailSent
-		if err != nil {
-			return err
+		if err := e.App.Delete(origin); err != nil {
+			// treat as non-critical error, just log for now
+			e.App.Logger().Warn("Failed to delete stale AuthOrigin record", "error", err, "authOriginId", origin.Id)
 		}
 	}
 
-	// try to keep only up to maxAuthOrigins
-	// (pop the last used ones; it is not executed in a transaction to avoid unnecessary locks)
-	if currentOrigin.IsNew() && len(origins) >= maxAuthOrigins {
-		for i := len(origins) - 1; i >= maxAuthOrigins-1; i-- {
-			if err := e.App.Delete(origins[i]); err != nil {
-				// treat as non-critical error, just log for now
-				e.App.Logger().Warn("Failed to delete old AuthOrigin record", "error", err, "authOriginId", origins[i].Id)
-			}
-		}
+	ip, userAgent := authSessionClientInfo(e)
+	s.current.SetIP(ip)
+	s.current.SetUserAgent(userAgent)
+	s.current.SetLastSeen(types.NowDateTime())
+
+	return e.App.Save(s.current)
+}
+
+// authSessionClientInfo returns the normalized client IP and user agent of the current request.
+func authSessionClientInfo(e *core.RequestEvent) (ip string, userAgent string) {
+	ip = e.RealIP()
+
+	userAgent = e.Request.UserAgent()
+	if len(userAgent) > 200 {
+		userAgent = userAgent[:200] + "..."
 	}
 
-	// create/update the origin fingerprint
-	return e.App.Save(currentOrigin)
+	return ip, userAgent
+}
+
+// authAlert sends a new login location email alert to the auth record.
+//
+// Note: The "fake" timeout is a temp solution to avoid blocking
+// for too long when the SMTP server is not accessible, due
+// to the lack of context cancellation support in the underlying
+// mailer and net/smtp package.
+// The goroutine technically "leaks" but we assume that the OS will
+// terminate the connection after some time (usually after 3-4 mins).
+func authAlert(e *core.RequestEvent, authRecord *core.Record) error {
+	ip, userAgent := authSessionClientInfo(e)
+	alertInfo := fmt.Sprintf("%s - %s %s", types.NowDateTime().String(), ip, userAgent)
+
+	return sendAuthAlert(e, authRecord, alertInfo)
+}
+
+// sendAuthAlert sends an auth alert email with the specified info to the auth record
+// (see [authAlert] for the send timeout details).
+func sendAuthAlert(e *core.RequestEvent, authRecord *core.Record, alertInfo string) error {
+	if authRecord.Email() == "" {
+		return nil
+	}
+
+	mailSent := make(chan error, 1)
+
+	timer := time.AfterFunc(15*time.Second, func() {
+		mailSent <- errors.New("auth alert mail send wait timeout reached")
+	})
+
+	routine.FireAndForget(func() {
+		err := mails.SendRecordAuthAlert(e.App, authRecord, alertInfo)
+		timer.Stop()
+		mailSent <- err
+	})
+
+	return <-mailSent
 }
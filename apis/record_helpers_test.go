@@ -13,6 +13,7 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/pocketbase/pocketbase/tools/types"
 )
 
@@ -343,6 +344,7 @@ func TestRecordAuthResponseAuthAlertCheck(t *testing.T) {
 	scenarios := []struct {
 		name          string
 		devices       []string // mock existing device fingerprints
+		staleDevices  []string // mock existing device fingerprints that were not used for longer than the token duration
 		expectDevices []string
 		enabled       bool
 		expectEmail   bool
@@ -355,9 +357,10 @@ func TestRecordAuthResponseAuthAlertCheck(t *testing.T) {
 			expectEmail:   false,
 		},
 		{
+			// each login creates a new auth session
 			name:          "existing device",
 			devices:       []string{"1", testFingerprint},
-			expectDevices: []string{"1", testFingerprint},
+			expectDevices: []string{"1", testFingerprint, testFingerprint},
 			enabled:       true,
 			expectEmail:   false,
 		},
@@ -369,16 +372,26 @@ func TestRecordAuthResponseAuthAlertCheck(t *testing.T) {
 			expectEmail:   true,
 		},
 		{
+			// the existing sessions are not evicted
 			name:          "new device (>= 5)",
 			devices:       []string{"1", "2", "3", "4", "5"},
-			expectDevices: []string{"2", "3", "4", "5", testFingerprint},
+			expectDevices: []string{"1", "2", "3", "4", "5", testFingerprint},
 			enabled:       true,
 			expectEmail:   true,
 		},
 		{
+			name:          "stale devices",
+			devices:       []string{"1"},
+			staleDevices:  []string{"2", testFingerprint},
+			expectDevices: []string{"1", testFingerprint},
+			enabled:       true,
+			expectEmail:   false,
+		},
+		{
+			// the auth origins are always stored as auth sessions
 			name:          "with disabled auth alert collection flag",
 			devices:       []string{"1", "2"},
-			expectDevices: []string{"1", "2"},
+			expectDevices: []string{"1", "2", testFingerprint},
 			enabled:       false,
 			expectEmail:   false,
 		},
@@ -424,6 +437,18 @@ func TestRecordAuthResponseAuthAlertCheck(t *testing.T) {
 				}
 			}
 
+			staleDate := types.NowDateTime().Add(-user.Collection().AuthToken.DurationTime() - time.Hour)
+			for _, fingerprint := range s.staleDevices {
+				d := core.NewAuthOrigin(app)
+				d.SetCollectionRef(user.Collection().Id)
+				d.SetRecordRef(user.Id)
+				d.SetFingerprint(fingerprint)
+				d.SetLastSeen(staleDate)
+				if err = app.Save(d); err != nil {
+					t.Fatal(err)
+				}
+			}
+
 			err = apis.RecordAuthResponse(event, user, "example", nil)
 			if err != nil {
 				t.Fatalf("Failed to resolve auth response: %v", err)
@@ -460,6 +485,22 @@ func TestRecordAuthResponseAuthAlertCheck(t *testing.T) {
 					t.Fatalf("Missing device with fingerprint %q:\n%v", fingerprint, fingerprints)
 				}
 			}
+
+			// the auth token should be bound to the current device auth session
+			current, err := app.FindAuthOriginByRecordAndFingerprint(user, testFingerprint)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := struct {
+				Token string `json:"token"`
+			}{}
+			if err := json.Unmarshal(event.Response.(*httptest.ResponseRecorder).Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			claims, _ := security.ParseUnverifiedJWT(body.Token)
+			if v := claims[core.TokenClaimSessionId]; v != current.Id {
+				t.Fatalf("Expected token session id %q, got %v", current.Id, v)
+			}
 		})
 	}
 }
@@ -0,0 +1,92 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+var revisionFilterFields = []string{
+	"id", "created", "action", "authCollectionRef", "authRef", "changes",
+	`^changes\.[\w\.\:]*\w+$`,
+}
+
+// recordRevisionsList handles the superuser only list API request
+// for the revisions history of a single record.
+//
+// Note that the record itself is not required to exist
+// so that the revisions of deleted records could be also listed.
+//
+// The records history is supported only for base collections.
+func recordRevisionsList(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	if !collection.IsBase() {
+		return e.BadRequestError("The records history is supported only for base collections.", nil)
+	}
+
+	err = checkCollectionRateLimit(e, collection, "list")
+	if err != nil {
+		return err
+	}
+
+	query := e.App.RevisionQuery().AndWhere(dbx.HashExp{
+		"collectionRef": collection.Id,
+		"recordRef":     e.Request.PathValue("id"),
+	})
+
+	fieldResolver := search.NewSimpleFieldResolver(revisionFilterFields...)
+
+	result, err := search.NewProvider(fieldResolver).
+		Query(query).
+		ParseAndExec(e.Request.URL.Query().Encode(), &[]*core.Revision{})
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// recordRevert handles the superuser only API request for reverting
+// a record to its state right after the specified revision.
+func recordRevert(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	if !collection.IsBase() {
+		return e.BadRequestError("The records history is supported only for base collections.", nil)
+	}
+
+	err = checkCollectionRateLimit(e, collection, "update")
+	if err != nil {
+		return err
+	}
+
+	record, err := e.App.FindRecordById(collection, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	revision, err := e.App.FindRevisionById(e.Request.PathValue("revisionId"))
+	if err != nil || revision.CollectionRef != collection.Id || revision.RecordRef != record.Id {
+		return e.NotFoundError("", err)
+	}
+
+	err = e.App.RevertRecordWithContext(recordRequestContext(e), record, revision)
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to revert record.", err))
+	}
+
+	if err := EnrichRecord(e, record); err != nil {
+		return firstApiError(err, e.InternalServerError("Failed to enrich record", err))
+	}
+
+	return e.JSON(http.StatusOK, record)
+}
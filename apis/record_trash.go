@@ -0,0 +1,161 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// recordsTrashList handles the superuser only list API request
+// for the soft deleted (aka. trashed) records of a collection.
+func recordsTrashList(e *core.RequestEvent) error {
+	collection, err := findSoftDeletableCollection(e)
+	if err != nil {
+		return err
+	}
+
+	err = checkCollectionRateLimit(e, collection, "list")
+	if err != nil {
+		return err
+	}
+
+	query := e.App.RecordQueryWithTrashed(collection).
+		AndWhere(dbx.Not(dbx.HashExp{collection.Name + "." + core.FieldNameDeleted: ""}))
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	fieldsResolver := core.NewRecordFieldResolver(e.App, collection, requestInfo, true)
+	fieldsResolver.SetAllowHiddenFields(true)
+
+	searchProvider := search.NewProvider(fieldsResolver).
+		Query(query).
+		CountCol("_rowid_")
+
+	records := []*core.Record{}
+	result, err := searchProvider.ParseAndExec(e.Request.URL.Query().Encode(), &records)
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	event := new(core.RecordsListRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Records = records
+	event.Result = result
+
+	return e.App.OnRecordsTrashListRequest().Trigger(event, func(e *core.RecordsListRequestEvent) error {
+		if err := EnrichRecords(e.RequestEvent, e.Records); err != nil {
+			return firstApiError(err, e.InternalServerError("Failed to enrich records", err))
+		}
+
+		return execAfterSuccessTx(true, e.App, func() error {
+			return e.JSON(http.StatusOK, e.Result)
+		})
+	})
+}
+
+// recordRestore handles the superuser only soft deleted record restore API request.
+func recordRestore(e *core.RequestEvent) error {
+	collection, err := findSoftDeletableCollection(e)
+	if err != nil {
+		return err
+	}
+
+	err = checkCollectionRateLimit(e, collection, "update")
+	if err != nil {
+		return err
+	}
+
+	record, err := findTrashedRecord(e, collection)
+	if err != nil {
+		return err
+	}
+
+	event := new(core.RecordRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Record = record
+
+	return e.App.OnRecordRestoreRequest().Trigger(event, func(e *core.RecordRequestEvent) error {
+		if err := e.App.RestoreRecord(e.Record); err != nil {
+			return firstApiError(err, e.BadRequestError("Failed to restore record. Make sure that it doesn't conflict with an existing unique value.", err))
+		}
+
+		if err := EnrichRecord(e.RequestEvent, e.Record); err != nil {
+			return firstApiError(err, e.InternalServerError("Failed to enrich record", err))
+		}
+
+		return execAfterSuccessTx(true, e.App, func() error {
+			return e.JSON(http.StatusOK, e.Record)
+		})
+	})
+}
+
+// recordTrashDelete handles the superuser only API request for
+// permanently deleting a soft deleted record.
+func recordTrashDelete(e *core.RequestEvent) error {
+	collection, err := findSoftDeletableCollection(e)
+	if err != nil {
+		return err
+	}
+
+	err = checkCollectionRateLimit(e, collection, "delete")
+	if err != nil {
+		return err
+	}
+
+	record, err := findTrashedRecord(e, collection)
+	if err != nil {
+		return err
+	}
+
+	event := new(core.RecordRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Record = record
+
+	return e.App.OnRecordTrashDeleteRequest().Trigger(event, func(e *core.RecordRequestEvent) error {
+		// already trashed records are always permanently deleted
+		if err := e.App.Delete(e.Record); err != nil {
+			return firstApiError(err, e.BadRequestError("Failed to delete record. Make sure that the record is not part of a required relation reference.", err))
+		}
+
+		return execAfterSuccessTx(true, e.App, func() error {
+			return e.NoContent(http.StatusNoContent)
+		})
+	})
+}
+
+// findTrashedRecord loads the soft deleted record matching the "id" path param.
+func findTrashedRecord(e *core.RequestEvent, collection *core.Collection) (*core.Record, error) {
+	record := &core.Record{}
+
+	err := e.App.RecordQueryWithTrashed(collection).
+		AndWhere(dbx.HashExp{collection.Name + "." + core.FieldNameId: e.Request.PathValue("id")}).
+		AndWhere(dbx.Not(dbx.HashExp{collection.Name + "." + core.FieldNameDeleted: ""})).
+		Limit(1).
+		One(record)
+	if err != nil {
+		return nil, e.NotFoundError("", err)
+	}
+
+	return record, nil
+}
+
+func findSoftDeletableCollection(e *core.RequestEvent) (*core.Collection, error) {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return nil, e.NotFoundError("Missing collection context.", err)
+	}
+
+	if !collection.IsBase() || !collection.SoftDelete.Enabled {
+		return nil, e.BadRequestError("The collection doesn't have soft delete enabled.", nil)
+	}
+
+	return collection, nil
+}
@@ -0,0 +1,416 @@
+package apis
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+// tus protocol constants (see https://tus.io/protocols/resumable-upload).
+const (
+	tusVersion     = "1.0.0"
+	tusExtensions  = "creation,creation-with-upload,termination,expiration"
+	tusContentType = "application/offset+octet-stream"
+)
+
+// uploadFieldModifier is the record body key suffix that could be used
+// to attach completed resumable uploads to a file field
+// (e.g. {"documents:upload": ["UPLOAD_ID1", "UPLOAD_ID2"]}).
+const uploadFieldModifier = ":upload"
+
+// bindRecordUploadApi registers the resumable record file uploads api endpoints
+// (implementing the core tus protocol and its creation, termination and expiration extensions).
+//
+// note: the rate limiter is "inlined" similar to the records crud api
+func bindRecordUploadApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	subGroup := rg.Group("/collections/{collection}/uploads/{field}").
+		Unbind(DefaultRateLimitMiddlewareId).
+		BindFunc(tusHeaders)
+	subGroup.OPTIONS("", recordUploadOptions)
+	subGroup.POST("", recordUploadCreate).Bind(RequireAuth())
+	subGroup.HEAD("/{id}", recordUploadHead).Bind(RequireAuth())
+	subGroup.PATCH("/{id}", recordUploadPatch).Bind(RequireAuth())
+	subGroup.DELETE("/{id}", recordUploadDelete).Bind(RequireAuth())
+}
+
+// tusHeaders sets the common tus response headers and checks
+// the client protocol version (except for the OPTIONS discovery requests).
+func tusHeaders(e *core.RequestEvent) error {
+	e.Response.Header().Set("Tus-Resumable", tusVersion)
+
+	if e.Request.Method != http.MethodOptions && e.Request.Header.Get("Tus-Resumable") != tusVersion {
+		e.Response.Header().Set("Tus-Version", tusVersion)
+		return e.Error(http.StatusPreconditionFailed, "Unsupported or missing Tus-Resumable version.", nil)
+	}
+
+	return e.Next()
+}
+
+func recordUploadOptions(e *core.RequestEvent) error {
+	_, field, err := uploadFieldFromRequest(e)
+	if err != nil {
+		return err
+	}
+
+	e.Response.Header().Set("Tus-Version", tusVersion)
+	e.Response.Header().Set("Tus-Extension", tusExtensions)
+	e.Response.Header().Set("Tus-Max-Size", strconv.FormatInt(uploadMaxSize(field), 10))
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+func recordUploadCreate(e *core.RequestEvent) error {
+	collection, field, err := uploadFieldFromRequest(e)
+	if err != nil {
+		return err
+	}
+
+	err = checkCollectionRateLimit(e, collection, "upload")
+	if err != nil {
+		return err
+	}
+
+	if err = checkUploadAccess(e, collection); err != nil {
+		return err
+	}
+
+	size, err := strconv.ParseInt(e.Request.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || size <= 0 {
+		return e.BadRequestError("Missing or invalid Upload-Length header.", err)
+	}
+
+	if size > uploadMaxSize(field) {
+		return e.Error(http.StatusRequestEntityTooLarge, "The upload exceeds the maximum allowed file size.", nil)
+	}
+
+	metadata := parseUploadMetadata(e.Request.Header.Get("Upload-Metadata"))
+
+	filename := metadata["filename"]
+	if filename == "" {
+		filename = metadata["name"]
+	}
+	if filename == "" {
+		filename = field.Name
+	}
+	if len(filename) > 255 {
+		filename = filename[len(filename)-255:]
+	}
+
+	upload := core.NewUpload(e.App)
+	upload.SetCollectionRef(e.Auth.Collection().Id)
+	upload.SetRecordRef(e.Auth.Id)
+	upload.SetTargetCollectionRef(collection.Id)
+	upload.SetTargetField(field.Name)
+	upload.SetFilename(filename)
+	upload.SetSize(size)
+
+	if err = e.App.Save(upload); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to create upload.", err))
+	}
+
+	e.Response.Header().Set("Location", uploadLocation(collection, field, upload))
+
+	// creation-with-upload
+	if e.Request.Header.Get("Content-Type") == tusContentType {
+		if err = appendUploadChunk(e, upload, 0); err != nil {
+			return err
+		}
+	}
+
+	setUploadHeaders(e, upload)
+
+	return e.JSON(http.StatusCreated, upload)
+}
+
+func recordUploadHead(e *core.RequestEvent) error {
+	upload, err := uploadFromRequest(e)
+	if err != nil {
+		return err
+	}
+
+	setUploadHeaders(e, upload)
+	e.Response.Header().Set("Upload-Length", strconv.FormatInt(upload.Size(), 10))
+	e.Response.Header().Set("Cache-Control", "no-store")
+
+	return e.NoContent(http.StatusOK)
+}
+
+func recordUploadPatch(e *core.RequestEvent) error {
+	upload, err := uploadFromRequest(e)
+	if err != nil {
+		return err
+	}
+
+	collection, err := e.App.FindCachedCollectionByNameOrId(upload.TargetCollectionRef())
+	if err != nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	err = checkCollectionRateLimit(e, collection, "upload")
+	if err != nil {
+		return err
+	}
+
+	if e.Request.Header.Get("Content-Type") != tusContentType {
+		return e.Error(http.StatusUnsupportedMediaType, "The chunk Content-Type must be "+tusContentType+".", nil)
+	}
+
+	offset, err := strconv.ParseInt(e.Request.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return e.BadRequestError("Missing or invalid Upload-Offset header.", err)
+	}
+
+	if err := appendUploadChunk(e, upload, offset); err != nil {
+		return err
+	}
+
+	setUploadHeaders(e, upload)
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+func recordUploadDelete(e *core.RequestEvent) error {
+	upload, err := uploadFromRequest(e)
+	if err != nil {
+		return err
+	}
+
+	if !lockUpload(upload.Id) {
+		return e.Error(http.StatusLocked, "The upload is currently being modified.", nil)
+	}
+	defer unlockUpload(upload.Id)
+
+	if err := e.App.Delete(upload); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to delete upload.", err))
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// -------------------------------------------------------------------
+
+var (
+	uploadLocksMu sync.Mutex
+	uploadLocks   = map[string]struct{}{}
+)
+
+// lockUpload marks the specified upload as busy and reports
+// whether it wasn't already locked by another request.
+func lockUpload(id string) bool {
+	uploadLocksMu.Lock()
+	defer uploadLocksMu.Unlock()
+
+	if _, ok := uploadLocks[id]; ok {
+		return false
+	}
+
+	uploadLocks[id] = struct{}{}
+
+	return true
+}
+
+func unlockUpload(id string) {
+	uploadLocksMu.Lock()
+	defer uploadLocksMu.Unlock()
+
+	delete(uploadLocks, id)
+}
+
+// appendUploadChunk stores the request body as a new upload chunk
+// starting at the specified offset and updates the upload offset.
+//
+// In case of an interrupted request body read, the partially
+// received content is still stored so that the client could resume from it.
+func appendUploadChunk(e *core.RequestEvent, upload *core.Upload, offset int64) error {
+	if !lockUpload(upload.Id) {
+		return e.Error(http.StatusLocked, "The upload is currently being modified.", nil)
+	}
+	defer unlockUpload(upload.Id)
+
+	// reload to ensure that the offset is the latest one
+	fresh, err := e.App.FindUploadById(upload.Id)
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+	upload.SetProxyRecord(fresh.Record)
+
+	if offset != upload.Offset() {
+		return e.Error(http.StatusConflict, "The Upload-Offset doesn't match with the current upload offset.", nil)
+	}
+
+	remaining := upload.Size() - offset
+
+	content, readErr := io.ReadAll(io.LimitReader(e.Request.Body, remaining+1))
+	if int64(len(content)) > remaining {
+		return e.Error(http.StatusRequestEntityTooLarge, "The chunk exceeds the declared Upload-Length.", nil)
+	}
+
+	if len(content) == 0 {
+		if readErr != nil {
+			return firstApiError(readErr, e.BadRequestError("Failed to read the upload chunk.", readErr))
+		}
+		return nil
+	}
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return e.InternalServerError("Failed to initialize the upload filesystem.", err)
+	}
+	defer fsys.Close()
+	fsys.SetContext(e.Request.Context())
+
+	if err := fsys.Upload(content, upload.ChunkKey(offset)); err != nil {
+		return e.InternalServerError("Failed to store the upload chunk.", err)
+	}
+
+	upload.SetOffset(offset + int64(len(content)))
+
+	if err := e.App.Save(upload); err != nil {
+		return firstApiError(err, e.InternalServerError("Failed to update the upload offset.", err))
+	}
+
+	if readErr != nil {
+		return firstApiError(readErr, e.BadRequestError("Failed to read the upload chunk.", readErr))
+	}
+
+	return nil
+}
+
+// checkUploadAccess loosely checks whether the current request auth
+// could eventually attach an upload to a record of the specified collection
+// (the actual create/update rules are checked on record save).
+func checkUploadAccess(e *core.RequestEvent, collection *core.Collection) error {
+	if !e.HasSuperuserAuth() && collection.CreateRule == nil && collection.UpdateRule == nil {
+		return e.ForbiddenError("Only superusers can perform this action.", nil)
+	}
+
+	return nil
+}
+
+func setUploadHeaders(e *core.RequestEvent, upload *core.Upload) {
+	e.Response.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset(), 10))
+	e.Response.Header().Set("Upload-Expires", upload.Updated().Time().Add(core.UploadMaxAge).UTC().Format(http.TimeFormat))
+}
+
+func uploadLocation(collection *core.Collection, field *core.FileField, upload *core.Upload) string {
+	return "/api/collections/" + collection.Name + "/uploads/" + field.Name + "/" + upload.Id
+}
+
+func uploadMaxSize(field *core.FileField) int64 {
+	if field.MaxSize <= 0 {
+		return core.DefaultFileFieldMaxSize
+	}
+
+	return field.MaxSize
+}
+
+// uploadFieldFromRequest returns the collection and file field from the request path params.
+func uploadFieldFromRequest(e *core.RequestEvent) (*core.Collection, *core.FileField, error) {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil || collection.IsView() {
+		return nil, nil, e.NotFoundError("Missing collection context.", err)
+	}
+
+	field, _ := collection.Fields.GetByName(e.Request.PathValue("field")).(*core.FileField)
+	if field == nil {
+		return nil, nil, e.NotFoundError("Missing file field.", nil)
+	}
+
+	return collection, field, nil
+}
+
+// uploadFromRequest returns the upload from the request path params
+// that is owned by the current request auth record.
+func uploadFromRequest(e *core.RequestEvent) (*core.Upload, error) {
+	collection, field, err := uploadFieldFromRequest(e)
+	if err != nil {
+		return nil, err
+	}
+
+	upload, err := e.App.FindUploadById(e.Request.PathValue("id"))
+	if err != nil ||
+		!upload.IsOwner(e.Auth) ||
+		upload.TargetCollectionRef() != collection.Id ||
+		upload.TargetField() != field.Name {
+		return nil, e.NotFoundError("", err)
+	}
+
+	return upload, nil
+}
+
+// parseUploadMetadata parses the tus Upload-Metadata header value
+// (comma separated key and base64 encoded value pairs).
+func parseUploadMetadata(raw string) map[string]string {
+	result := map[string]string{}
+
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			continue
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		result[key] = string(decoded)
+	}
+
+	return result
+}
+
+// extractAttachedUploads resolves the "fieldName:upload" request body values
+// into files from the completed uploads of the current request auth record.
+//
+// The returned map keys are with the append modifier (e.g. "documents+")
+// so that the uploaded files are added to the existing record ones.
+func extractAttachedUploads(e *core.RequestEvent, collection *core.Collection, body map[string]any) (map[string][]*filesystem.File, error) {
+	result := map[string][]*filesystem.File{}
+
+	for _, field := range collection.Fields {
+		if field.Type() != core.FieldTypeFile {
+			continue
+		}
+
+		raw, ok := body[field.GetName()+uploadFieldModifier]
+		if !ok {
+			continue
+		}
+
+		for _, id := range list.ToUniqueStringSlice(raw) {
+			invalidErr := validation.Errors{field.GetName(): validation.NewError(
+				"validation_invalid_upload",
+				"Missing or incomplete upload {{.uploadId}}.",
+			).SetParams(map[string]any{"uploadId": id})}
+
+			upload, err := e.App.FindUploadById(id)
+			if err != nil ||
+				!upload.IsOwner(e.Auth) ||
+				upload.TargetCollectionRef() != collection.Id ||
+				upload.TargetField() != field.GetName() {
+				return nil, invalidErr
+			}
+
+			file, err := upload.NewFile(e.App)
+			if err != nil {
+				e.App.Logger().Debug("Failed to load the upload file", "error", err, "uploadId", id)
+				return nil, invalidErr
+			}
+
+			key := field.GetName() + "+"
+			result[key] = append(result[key], file)
+		}
+	}
+
+	return result, nil
+}
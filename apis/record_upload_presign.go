@@ -0,0 +1,235 @@
+package apis
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/filesystem/blob"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// bindRecordPresignedUploadApi registers the direct to S3 record file uploads api endpoints.
+//
+// The presigned uploads are regular staged uploads (see [bindRecordUploadApi])
+// whose content is sent by the client directly to the S3 storage
+// and could be attached to a record file field in the same way.
+//
+// note: the rate limiter is "inlined" similar to the records crud api
+func bindRecordPresignedUploadApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	subGroup := rg.Group("/collections/{collection}/uploads/{field}").Unbind(DefaultRateLimitMiddlewareId)
+	subGroup.POST("/presign", recordUploadPresign).Bind(RequireAuth())
+	subGroup.POST("/{id}/finalize", recordUploadFinalize).Bind(RequireAuth())
+}
+
+type presignedUploadResponse struct {
+	Upload  *core.Upload      `json:"upload"`
+	URL     string            `json:"url"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers"`
+	Expires types.DateTime    `json:"expires"`
+}
+
+type presignUploadFile struct {
+	Filename    string `form:"filename" json:"filename"`
+	Size        int64  `form:"size" json:"size"`
+	ContentType string `form:"contentType" json:"contentType"`
+}
+
+type presignUploadForm struct {
+	field *core.FileField
+
+	Files []presignUploadFile `form:"files" json:"files"`
+}
+
+func (form *presignUploadForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Files,
+			validation.Required,
+			validation.Length(1, uploadMaxSelect(form.field)),
+			validation.Each(validation.By(form.checkFile)),
+		),
+	)
+}
+
+func (form *presignUploadForm) checkFile(value any) error {
+	v, _ := value.(presignUploadFile)
+
+	return validation.ValidateStruct(&v,
+		validation.Field(&v.Filename, validation.Required, validation.Length(1, 255)),
+		validation.Field(&v.Size, validation.Required, validation.Min(int64(1)), validation.Max(uploadMaxSize(form.field))),
+		validation.Field(&v.ContentType, validation.Required, validation.By(func(value any) error {
+			contentType, _ := value.(string)
+			if len(form.field.MimeTypes) > 0 && !list.ExistInSlice(contentType, form.field.MimeTypes) {
+				return validation.NewError("validation_invalid_mime_type", "The content type must be one of {{.mimeTypes}}.").
+					SetParams(map[string]any{"mimeTypes": form.field.MimeTypes})
+			}
+			return nil
+		})),
+	)
+}
+
+func recordUploadPresign(e *core.RequestEvent) error {
+	collection, field, err := uploadFieldFromRequest(e)
+	if err != nil {
+		return err
+	}
+
+	if err = checkPresignedUploadsEnabled(e); err != nil {
+		return err
+	}
+
+	err = checkCollectionRateLimit(e, collection, "upload")
+	if err != nil {
+		return err
+	}
+
+	if err = checkUploadAccess(e, collection); err != nil {
+		return err
+	}
+
+	form := &presignUploadForm{field: field}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return e.InternalServerError("Failed to initialize the upload filesystem.", err)
+	}
+	defer fsys.Close()
+	fsys.SetContext(e.Request.Context())
+
+	expiry := e.App.Settings().S3.PresignDuration()
+
+	result := make([]*presignedUploadResponse, 0, len(form.Files))
+
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		for _, file := range form.Files {
+			upload := core.NewUpload(txApp)
+			upload.SetCollectionRef(e.Auth.Collection().Id)
+			upload.SetRecordRef(e.Auth.Id)
+			upload.SetTargetCollectionRef(collection.Id)
+			upload.SetTargetField(field.Name)
+			upload.SetFilename(file.Filename)
+			upload.SetSize(file.Size)
+
+			if err := txApp.Save(upload); err != nil {
+				return err
+			}
+
+			url, err := fsys.SignedURL(upload.ChunkKey(0), &blob.SignedURLOptions{
+				Method:        http.MethodPut,
+				Expiry:        expiry,
+				ContentType:   file.ContentType,
+				ContentLength: file.Size,
+			})
+			if err != nil {
+				return err
+			}
+
+			expires, _ := types.ParseDateTime(time.Now().Add(expiry))
+
+			result = append(result, &presignedUploadResponse{
+				Upload: upload,
+				URL:    url,
+				Method: http.MethodPut,
+				Headers: map[string]string{
+					"Content-Type":   file.ContentType,
+					"Content-Length": strconv.FormatInt(file.Size, 10),
+				},
+				Expires: expires,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to create the presigned uploads.", err))
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+func recordUploadFinalize(e *core.RequestEvent) error {
+	upload, err := uploadFromRequest(e)
+	if err != nil {
+		return err
+	}
+
+	if err = checkPresignedUploadsEnabled(e); err != nil {
+		return err
+	}
+
+	if !lockUpload(upload.Id) {
+		return e.Error(http.StatusLocked, "The upload is currently being modified.", nil)
+	}
+	defer unlockUpload(upload.Id)
+
+	if upload.IsComplete() {
+		return e.JSON(http.StatusOK, upload)
+	}
+
+	fsys, err := e.App.NewFilesystem()
+	if err != nil {
+		return e.InternalServerError("Failed to initialize the upload filesystem.", err)
+	}
+	defer fsys.Close()
+	fsys.SetContext(e.Request.Context())
+
+	attrs, err := fsys.Attributes(upload.ChunkKey(0))
+	if err != nil {
+		if errors.Is(err, filesystem.ErrNotFound) {
+			return e.BadRequestError("The upload content is missing.", err)
+		}
+		return e.InternalServerError("Failed to load the upload content attributes.", err)
+	}
+
+	// the declared size is signed as Content-Length in the presigned PUT URL
+	// but not all S3 compatible storages enforce it so the upload is
+	// discarded (together with its content) if it doesn't match the declared one
+	if attrs.Size != upload.Size() {
+		if err := e.App.Delete(upload); err != nil {
+			e.App.Logger().Warn("Failed to delete invalid presigned upload", "error", err, "uploadId", upload.Id)
+		}
+
+		return e.BadRequestError("The uploaded content size doesn't match the declared one.", nil)
+	}
+
+	upload.SetOffset(attrs.Size)
+
+	if err := e.App.Save(upload); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to finalize the upload.", err))
+	}
+
+	return e.JSON(http.StatusOK, upload)
+}
+
+// -------------------------------------------------------------------
+
+func checkPresignedUploadsEnabled(e *core.RequestEvent) error {
+	s3 := e.App.Settings().S3
+	if !s3.Enabled || !s3.PresignedUploads {
+		return e.BadRequestError("The presigned uploads are not enabled.", nil)
+	}
+
+	return nil
+}
+
+func uploadMaxSelect(field *core.FileField) int {
+	if field.MaxSelect <= 1 {
+		return 1
+	}
+
+	return field.MaxSelect
+}
@@ -77,6 +77,19 @@ func Serve(app core.App, config ServeConfig) error {
 	pbRouter.Bind(CORS(CORSConfig{
 		AllowOrigins: config.AllowedOrigins,
 		AllowMethods: []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
+		// expose the record ETag for the If-Match conditional requests
+		// and the tus resumable upload headers
+		ExposeHeaders: []string{
+			"ETag",
+			"Location",
+			"Tus-Resumable",
+			"Tus-Version",
+			"Tus-Extension",
+			"Tus-Max-Size",
+			"Upload-Offset",
+			"Upload-Length",
+			"Upload-Expires",
+		},
 	}))
 
 	// @todo consider moving in base
@@ -7,6 +7,7 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/security"
 )
 
 // bindSettingsApi registers the settings api endpoints.
@@ -16,6 +17,7 @@ func bindSettingsApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
 	subGroup.PATCH("", settingsSet)
 	subGroup.POST("/test/s3", settingsTestS3)
 	subGroup.POST("/test/email", settingsTestEmail)
+	subGroup.POST("/signing-keys/rotate", settingsRotateSigningKeys)
 
 	// @todo move to collections
 	subGroup.POST("/apple/generate-client-secret", settingsGenerateAppleClientSecret)
@@ -143,3 +145,35 @@ func settingsGenerateAppleClientSecret(e *core.RequestEvent) error {
 		"secret": secret,
 	})
 }
+
+func settingsRotateSigningKeys(e *core.RequestEvent) error {
+	form := new(rotateSigningKeysForm)
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("An error occurred while loading the submitted data.", err)
+	}
+	if err := form.validate(); err != nil {
+		return e.BadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	key, err := e.App.RotateSigningKeys(form.Algorithm)
+	if err != nil {
+		return e.BadRequestError("Failed to rotate the app signing keys.", err)
+	}
+
+	// never expose the private key
+	key.PrivateKey = ""
+
+	return e.JSON(http.StatusOK, key)
+}
+
+type rotateSigningKeysForm struct {
+	// Algorithm is the optional signing algorithm of the new key
+	// (fallbacks to the current active key algorithm).
+	Algorithm string `form:"algorithm" json:"algorithm"`
+}
+
+func (form *rotateSigningKeysForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Algorithm, validation.In(security.SigningAlgRS256, security.SigningAlgEdDSA)),
+	)
+}
@@ -0,0 +1,73 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// bindWebhooksApi registers the webhook deliveries api endpoints.
+func bindWebhooksApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/webhooks").Bind(RequireSuperuserAuth(), SkipSuccessActivityLog())
+	sub.GET("/deliveries", webhookDeliveriesList)
+	sub.GET("/deliveries/{id}", webhookDeliveryView)
+	sub.POST("/deliveries/{id}/resend", webhookDeliveryResend)
+}
+
+var webhookDeliveryFilterFields = []string{
+	"id", "created", "updated", "webhookRef", "url", "collectionRef", "recordRef",
+	"action", "status", "error", "attempts", "responseStatus", "nextAttempt", "payload",
+	`^payload\.[\w\.\:]*\w+$`,
+}
+
+func webhookDeliveriesList(e *core.RequestEvent) error {
+	fieldResolver := search.NewSimpleFieldResolver(webhookDeliveryFilterFields...)
+
+	result, err := search.NewProvider(fieldResolver).
+		Query(e.App.WebhookDeliveryQuery()).
+		ParseAndExec(e.Request.URL.Query().Encode(), &[]*core.WebhookDelivery{})
+
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+func webhookDeliveryView(e *core.RequestEvent) error {
+	id := e.Request.PathValue("id")
+	if id == "" {
+		return e.NotFoundError("", nil)
+	}
+
+	delivery, err := e.App.FindWebhookDeliveryById(id)
+	if err != nil || delivery == nil {
+		return e.NotFoundError("", err)
+	}
+
+	return e.JSON(http.StatusOK, delivery)
+}
+
+// webhookDeliveryResend makes a new attempt to send the webhook delivery
+// (regardless of its current status) and returns its updated state.
+//
+// The request failure is not considered an API error and
+// its details are available in the returned delivery.
+func webhookDeliveryResend(e *core.RequestEvent) error {
+	id := e.Request.PathValue("id")
+	if id == "" {
+		return e.NotFoundError("", nil)
+	}
+
+	delivery, err := e.App.FindWebhookDeliveryById(id)
+	if err != nil || delivery == nil {
+		return e.NotFoundError("", err)
+	}
+
+	// the request error is available in the delivery entry
+	_ = e.App.SendWebhookDelivery(delivery)
+
+	return e.JSON(http.StatusOK, delivery)
+}
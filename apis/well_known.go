@@ -0,0 +1,66 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// bindWellKnownApi registers the "/.well-known/*" discovery endpoints
+// and the corresponding handlers.
+func bindWellKnownApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/.well-known")
+	sub.GET("/openid-configuration", wellKnownOpenIdConfiguration)
+	sub.GET("/jwks.json", wellKnownJWKS)
+}
+
+// wellKnownOpenIdConfiguration returns the OpenID Connect provider metadata
+// as described in https://openid.net/specs/openid-connect-discovery-1_0.html.
+func wellKnownOpenIdConfiguration(e *core.RequestEvent) error {
+	if !e.App.Settings().OAuth2Server.Enabled {
+		return e.NotFoundError("The OAuth2 server is not enabled.", nil)
+	}
+
+	issuer := oauth2ServerIssuer(e.App)
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/api/oauth2/authorize",
+		"token_endpoint":                        issuer + "/api/oauth2/token",
+		"userinfo_endpoint":                     issuer + "/api/oauth2/userinfo",
+		"jwks_uri":                              issuer + "/.well-known/jwks.json",
+		"response_types_supported":              []string{"code"},
+		"response_modes_supported":              []string{"query"},
+		"grant_types_supported":                 []string{"authorization_code", "refresh_token"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{security.SigningAlgRS256, security.SigningAlgEdDSA},
+		"scopes_supported":                      oauth2ServerScopes,
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"claims_supported": []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce",
+			"name", "preferred_username", "picture", "updated_at",
+			"email", "email_verified",
+		},
+	})
+}
+
+// wellKnownJWKS returns the public JSON Web Key Set of the app signing keys.
+func wellKnownJWKS(e *core.RequestEvent) error {
+	// ensure that there is at least one signing key so that clients
+	// could fetch it before the first token is issued
+	if e.App.Settings().OAuth2Server.Enabled || e.App.Settings().SigningKeys.AuthTokens {
+		if _, err := e.App.ActiveSigningKey(); err != nil {
+			return e.InternalServerError("Failed to initialize the app signing key.", err)
+		}
+	}
+
+	keys, err := e.App.Settings().SigningKeys.PublicJWKs()
+	if err != nil {
+		return e.InternalServerError("Failed to load the app signing keys.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{"keys": keys})
+}
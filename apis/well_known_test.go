@@ -0,0 +1,193 @@
+package apis_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestWellKnownOpenIdConfiguration(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "disabled OAuth2 server",
+			Method:          http.MethodGet,
+			URL:             "/.well-known/openid-configuration",
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "enabled OAuth2 server",
+			Method: http.MethodGet,
+			URL:    "/.well-known/openid-configuration",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableOAuth2Server(t, app)
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"issuer":"http://localhost:8090"`,
+				`"authorization_endpoint":"http://localhost:8090/api/oauth2/authorize"`,
+				`"token_endpoint":"http://localhost:8090/api/oauth2/token"`,
+				`"userinfo_endpoint":"http://localhost:8090/api/oauth2/userinfo"`,
+				`"jwks_uri":"http://localhost:8090/.well-known/jwks.json"`,
+				`"response_types_supported":["code"]`,
+				`"grant_types_supported":["authorization_code","refresh_token"]`,
+				`"id_token_signing_alg_values_supported":["RS256","EdDSA"]`,
+				`"scopes_supported":["openid","profile","email"]`,
+				`"code_challenge_methods_supported":["S256"]`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestWellKnownJWKS(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "disabled OAuth2 server without signing keys",
+			Method:          http.MethodGet,
+			URL:             "/.well-known/jwks.json",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`{"keys":[]}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "enabled OAuth2 server without signing keys",
+			Method: http.MethodGet,
+			URL:    "/.well-known/jwks.json",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableOAuth2Server(t, app)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if total := len(app.Settings().SigningKeys.Keys); total != 1 {
+					t.Fatalf("Expected 1 generated signing key, got %d", total)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"kty":"RSA"`,
+				`"use":"sig"`,
+				`"alg":"RS256"`,
+			},
+			ExpectedEvents: map[string]int{
+				"OnModelUpdate":             1,
+				"OnModelUpdateExecute":      1,
+				"OnModelAfterUpdateSuccess": 1,
+				"OnModelValidate":           1,
+				"OnSettingsReload":          1,
+			},
+		},
+		{
+			Name:   "enabled signed auth tokens without signing keys",
+			Method: http.MethodGet,
+			URL:    "/.well-known/jwks.json",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().SigningKeys.AuthTokens = true
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if total := len(app.Settings().SigningKeys.Keys); total != 1 {
+					t.Fatalf("Expected 1 generated signing key, got %d", total)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"kty":"RSA"`,
+				`"alg":"RS256"`,
+			},
+			ExpectedEvents: map[string]int{
+				"OnModelUpdate":             1,
+				"OnModelUpdateExecute":      1,
+				"OnModelAfterUpdateSuccess": 1,
+				"OnModelValidate":           1,
+				"OnSettingsReload":          1,
+			},
+		},
+		{
+			Name:   "existing Ed25519 and expired signing keys",
+			Method: http.MethodGet,
+			URL:    "/.well-known/jwks.json",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				privateKey, err := security.GenerateSigningKey(security.SigningAlgEdDSA)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				expired := core.SigningKeyConfig{Id: "expired", Algorithm: security.SigningAlgEdDSA, PrivateKey: privateKey}
+				expired.Expires = types.NowDateTime().Add(-time.Minute)
+
+				app.Settings().SigningKeys.Keys = []core.SigningKeyConfig{
+					{Id: "key1", Algorithm: security.SigningAlgEdDSA, PrivateKey: privateKey},
+					expired,
+				}
+
+				if err := app.Save(app.Settings()); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"kid":"key1"`,
+				`"kty":"OKP"`,
+				`"crv":"Ed25519"`,
+				`"alg":"EdDSA"`,
+				`"x":`,
+			},
+			NotExpectedContent: []string{
+				`"kid":"expired"`,
+				"privateKey",
+				`"d":`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+		{
+			Name:   "existing signing keys",
+			Method: http.MethodGet,
+			URL:    "/.well-known/jwks.json",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				privateKey, err := security.GenerateSigningKey(security.SigningAlgRS256)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				app.Settings().SigningKeys.Keys = []core.SigningKeyConfig{
+					{Id: "key1", Algorithm: security.SigningAlgRS256, PrivateKey: privateKey},
+					{Id: "key2", Algorithm: security.SigningAlgRS256, PrivateKey: privateKey},
+				}
+
+				if err := app.Save(app.Settings()); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"kid":"key1"`,
+				`"kid":"key2"`,
+				`"n":`,
+				`"e":"AQAB"`,
+			},
+			NotExpectedContent: []string{
+				"privateKey",
+				"PRIVATE KEY",
+				`"d":`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
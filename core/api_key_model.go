@@ -0,0 +1,285 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameAPIKeys = "_apiKeys"
+
+// APIKeyPrefix is the prefix of all plain API keys
+// (aka. "pbk_" + apiKeyId + "_" + secret).
+const APIKeyPrefix = "pbk_"
+
+const apiKeySecretLength = 40
+
+// Supported API key scope actions.
+const (
+	APIKeyScopeActionList   = "list"
+	APIKeyScopeActionView   = "view"
+	APIKeyScopeActionCreate = "create"
+	APIKeyScopeActionUpdate = "update"
+	APIKeyScopeActionDelete = "delete"
+)
+
+// apiKeyScopeRegex validates a single "collectionName:action" scope entry
+// (both parts could be "*" to match any collection or action).
+var apiKeyScopeRegex = regexp.MustCompile(`^(\*|\w+):(\*|list|view|create|update|delete)$`)
+
+var (
+	_ Model        = (*APIKey)(nil)
+	_ PreValidator = (*APIKey)(nil)
+	_ RecordProxy  = (*APIKey)(nil)
+)
+
+// APIKey defines a Record proxy for working with the apiKeys collection
+// (aka. long-lived auth record API keys).
+type APIKey struct {
+	*Record
+}
+
+// NewAPIKey instantiates and returns a new blank *APIKey model.
+//
+// Example usage:
+//
+//	apiKey := core.NewAPIKey(app)
+//	apiKey.SetRecordRef(user.Id)
+//	apiKey.SetCollectionRef(user.Collection().Id)
+//	apiKey.SetName("CI integration")
+//	apiKey.SetScopes([]string{"posts:list", "posts:view"})
+//	plainKey, _ := apiKey.GenerateKey()
+//	app.Save(apiKey)
+func NewAPIKey(app App) *APIKey {
+	m := &APIKey{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameAPIKeys)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on APIKey.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *APIKey) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameAPIKeys {
+		return errors.New("missing or invalid APIKey ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *APIKey) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *APIKey) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *APIKey) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *APIKey) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *APIKey) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *APIKey) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Name returns the "name" record field value.
+func (m *APIKey) Name() string {
+	return m.GetString("name")
+}
+
+// SetName updates the "name" record field value.
+func (m *APIKey) SetName(name string) {
+	m.Set("name", name)
+}
+
+// Expires returns the "expires" record field value
+// (zero value means that the API key never expires).
+func (m *APIKey) Expires() types.DateTime {
+	return m.GetDateTime("expires")
+}
+
+// SetExpires updates the "expires" record field value.
+func (m *APIKey) SetExpires(expires types.DateTime) {
+	m.Set("expires", expires)
+}
+
+// HasExpired checks if the API key has an expiration date
+// and whether it is before or equal to maxTime.
+//
+// Example:
+//
+//	apiKey.HasExpired(types.NowDateTime())
+func (m *APIKey) HasExpired(maxTime types.DateTime) bool {
+	expires := m.Expires()
+
+	return !expires.IsZero() && !expires.Time().After(maxTime.Time())
+}
+
+// AllowedIPs returns the "allowedIPs" record field value
+// (a list of individual IPs or subnets; empty list means any IP).
+func (m *APIKey) AllowedIPs() []string {
+	return m.GetStringSlice("allowedIPs")
+}
+
+// SetAllowedIPs updates the "allowedIPs" record field value.
+func (m *APIKey) SetAllowedIPs(ips []string) {
+	m.Set("allowedIPs", ips)
+}
+
+// Scopes returns the "scopes" record field value
+// (a list of "collectionName:action" entries; empty list means unrestricted).
+func (m *APIKey) Scopes() []string {
+	return m.GetStringSlice("scopes")
+}
+
+// SetScopes updates the "scopes" record field value.
+func (m *APIKey) SetScopes(scopes []string) {
+	m.Set("scopes", scopes)
+}
+
+// HasScope checks whether the API key is allowed to perform the
+// specified action on the specified collection.
+//
+// API keys without any scopes are unrestricted.
+func (m *APIKey) HasScope(collectionName string, action string) bool {
+	scopes := m.Scopes()
+	if len(scopes) == 0 {
+		return true
+	}
+
+	for _, scope := range scopes {
+		scopeCollection, scopeAction, ok := strings.Cut(scope, ":")
+		if !ok {
+			continue
+		}
+
+		if (scopeCollection == "*" || scopeCollection == collectionName) &&
+			(scopeAction == "*" || scopeAction == action) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Created returns the "created" record field value.
+func (m *APIKey) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *APIKey) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+// GenerateKey generates a new random API key secret, stores its hash
+// in the "keyHash" record field and returns the plain API key.
+//
+// The plain API key is not stored and must be shown only once to the key owner.
+//
+// If the model doesn't have an id yet, a new one will be generated.
+func (m *APIKey) GenerateKey() (string, error) {
+	if m.Id == "" {
+		m.Id = GenerateDefaultRandomId()
+	}
+
+	if strings.Contains(m.Id, "_") {
+		return "", errors.New("the API key id must not contain underscores")
+	}
+
+	secret := security.RandomString(apiKeySecretLength)
+
+	m.Set("keyHash", security.SHA256(secret))
+
+	return APIKeyPrefix + m.Id + "_" + secret, nil
+}
+
+// ValidateSecret checks whether secret matches with the stored API key hash.
+func (m *APIKey) ValidateSecret(secret string) bool {
+	hash := m.GetString("keyHash")
+	if hash == "" || secret == "" {
+		return false
+	}
+
+	return security.Equal(hash, security.SHA256(secret))
+}
+
+// ParseAPIKey splits the provided plain API key into its id and secret parts.
+func ParseAPIKey(key string) (id string, secret string, ok bool) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return "", "", false
+	}
+
+	id, secret, ok = strings.Cut(key[len(APIKeyPrefix):], "_")
+	if !ok || id == "" || secret == "" {
+		return "", "", false
+	}
+
+	return id, secret, true
+}
+
+func (app *BaseApp) registerAPIKeyHooks() {
+	recordRefHooks[*APIKey](app, CollectionNameAPIKeys, CollectionTypeAuth)
+
+	app.OnRecordValidate(CollectionNameAPIKeys).Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			err := validation.Validate(e.Record.GetStringSlice("scopes"), validation.Each(validation.Match(apiKeyScopeRegex).
+				Error("Invalid scope format - expects collectionName:action where action is one of list, view, create, update, delete (both parts could be *).")))
+			if err != nil {
+				return validation.Errors{"scopes": err}
+			}
+
+			err = validation.Validate(e.Record.GetStringSlice("allowedIPs"), validation.Each(validation.By(validateIPOrSubnet)))
+			if err != nil {
+				return validation.Errors{"allowedIPs": err}
+			}
+
+			return e.Next()
+		},
+		Priority: 99,
+	})
+}
+
+func validateIPOrSubnet(value any) error {
+	v, _ := value.(string)
+
+	if _, err := netip.ParsePrefix(v); err == nil {
+		return nil
+	}
+
+	if _, err := netip.ParseAddr(v); err == nil {
+		return nil
+	}
+
+	return validation.NewError("validation_invalid_ip", "Must be a valid IP address or subnet.")
+}
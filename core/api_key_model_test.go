@@ -0,0 +1,425 @@
+package core_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	apiKey := core.NewAPIKey(app)
+
+	if apiKey.Collection().Name != core.CollectionNameAPIKeys {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameAPIKeys, apiKey.Collection().Name)
+	}
+}
+
+func TestAPIKeyProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	apiKey := core.APIKey{}
+	apiKey.SetProxyRecord(record)
+
+	if apiKey.ProxyRecord() == nil || apiKey.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, apiKey.ProxyRecord())
+	}
+}
+
+func TestAPIKeyStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	apiKey := core.NewAPIKey(app)
+
+	fields := []struct {
+		name   string
+		getter func() string
+		setter func(string)
+	}{
+		{"collectionRef", apiKey.CollectionRef, apiKey.SetCollectionRef},
+		{"recordRef", apiKey.RecordRef, apiKey.SetRecordRef},
+		{"name", apiKey.Name, apiKey.SetName},
+	}
+
+	testValues := []string{"test_1", "test2", ""}
+
+	for _, f := range fields {
+		for i, testValue := range testValues {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := apiKey.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestAPIKeySliceFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	apiKey := core.NewAPIKey(app)
+
+	fields := []struct {
+		name   string
+		getter func() []string
+		setter func([]string)
+	}{
+		{"allowedIPs", apiKey.AllowedIPs, apiKey.SetAllowedIPs},
+		{"scopes", apiKey.Scopes, apiKey.SetScopes},
+	}
+
+	testValues := [][]string{{"a", "b"}, {"c"}, {}}
+
+	for _, f := range fields {
+		for i, testValue := range testValues {
+			t.Run(fmt.Sprintf("%s_%d", f.name, i), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); strings.Join(v, ",") != strings.Join(testValue, ",") {
+					t.Fatalf("Expected getter %v, got %v", testValue, v)
+				}
+
+				if v := apiKey.GetStringSlice(f.name); strings.Join(v, ",") != strings.Join(testValue, ",") {
+					t.Fatalf("Expected field value %v, got %v", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestAPIKeyExpires(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	apiKey := core.NewAPIKey(app)
+
+	if v := apiKey.Expires(); !v.IsZero() {
+		t.Fatalf("Expected zero expires, got %v", v)
+	}
+
+	now := types.NowDateTime()
+
+	if apiKey.HasExpired(now) {
+		t.Fatal("Expected API key without expiration date to never expire")
+	}
+
+	apiKey.SetExpires(now)
+
+	if v := apiKey.Expires(); v.String() != now.String() {
+		t.Fatalf("Expected expires %q, got %q", now.String(), v.String())
+	}
+
+	scenarios := []struct {
+		maxTime  types.DateTime
+		expected bool
+	}{
+		{now.Add(-1 * time.Second), false},
+		{now, true},
+		{now.Add(1 * time.Second), true},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.maxTime.String()), func(t *testing.T) {
+			if v := apiKey.HasExpired(s.maxTime); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestAPIKeyHasScope(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		scopes     []string
+		collection string
+		action     string
+		expected   bool
+	}{
+		{nil, "demo1", core.APIKeyScopeActionDelete, true},
+		{[]string{"demo1:list"}, "demo1", core.APIKeyScopeActionList, true},
+		{[]string{"demo1:list"}, "demo1", core.APIKeyScopeActionView, false},
+		{[]string{"demo1:list"}, "demo2", core.APIKeyScopeActionList, false},
+		{[]string{"demo1:list", "demo2:*"}, "demo2", core.APIKeyScopeActionUpdate, true},
+		{[]string{"*:view"}, "demo3", core.APIKeyScopeActionView, true},
+		{[]string{"*:view"}, "demo3", core.APIKeyScopeActionCreate, false},
+		{[]string{"*:*"}, "demo3", core.APIKeyScopeActionCreate, true},
+		{[]string{"invalid"}, "demo3", core.APIKeyScopeActionCreate, false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%v_%s_%s", i, s.scopes, s.collection, s.action), func(t *testing.T) {
+			apiKey := core.NewAPIKey(app)
+			apiKey.SetScopes(s.scopes)
+
+			if v := apiKey.HasScope(s.collection, s.action); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestAPIKeyGenerateKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	t.Run("with underscore id", func(t *testing.T) {
+		apiKey := core.NewAPIKey(app)
+		apiKey.Id = "invalid_id"
+
+		if _, err := apiKey.GenerateKey(); err == nil {
+			t.Fatal("Expected error for id with underscore")
+		}
+	})
+
+	t.Run("without id", func(t *testing.T) {
+		apiKey := core.NewAPIKey(app)
+
+		key, err := apiKey.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if apiKey.Id == "" {
+			t.Fatal("Expected the API key id to be generated")
+		}
+
+		id, secret, ok := core.ParseAPIKey(key)
+		if !ok || id != apiKey.Id {
+			t.Fatalf("Expected key with id %q, got %q (%v)", apiKey.Id, key, ok)
+		}
+
+		if strings.Contains(apiKey.GetString("keyHash"), secret) {
+			t.Fatal("Expected the plain secret to not be stored")
+		}
+
+		if !apiKey.ValidateSecret(secret) {
+			t.Fatal("Expected the generated secret to be valid")
+		}
+
+		if apiKey.ValidateSecret(secret + "a") {
+			t.Fatal("Expected the modified secret to be invalid")
+		}
+
+		if apiKey.ValidateSecret("") {
+			t.Fatal("Expected the empty secret to be invalid")
+		}
+
+		// regenerate
+		newKey, err := apiKey.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if newKey == key {
+			t.Fatal("Expected a different key")
+		}
+		if apiKey.ValidateSecret(secret) {
+			t.Fatal("Expected the old secret to be invalid after regeneration")
+		}
+	})
+}
+
+func TestParseAPIKey(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		key            string
+		expectedId     string
+		expectedSecret string
+		expectedOk     bool
+	}{
+		{"", "", "", false},
+		{"abc_def", "", "", false},
+		{"pbk_", "", "", false},
+		{"pbk_abc", "", "", false},
+		{"pbk_abc_", "", "", false},
+		{"pbk__def", "", "", false},
+		{"pbk_abc_def", "abc", "def", true},
+		{"pbk_abc_def_ghi", "abc", "def_ghi", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.key, func(t *testing.T) {
+			id, secret, ok := core.ParseAPIKey(s.key)
+
+			if ok != s.expectedOk {
+				t.Fatalf("Expected ok %v, got %v", s.expectedOk, ok)
+			}
+
+			if id != s.expectedId {
+				t.Fatalf("Expected id %q, got %q", s.expectedId, id)
+			}
+
+			if secret != s.expectedSecret {
+				t.Fatalf("Expected secret %q, got %q", s.expectedSecret, secret)
+			}
+		})
+	}
+}
+
+func TestAPIKeyPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	apiKeysCol, err := app.FindCollectionByNameOrId(core.CollectionNameAPIKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fill := func(apiKey *core.APIKey) {
+		apiKey.SetRecordRef(user.Id)
+		apiKey.SetCollectionRef(user.Collection().Id)
+		apiKey.SetName("test")
+		apiKey.Set("keyHash", "test")
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		apiKey := &core.APIKey{}
+
+		if err := app.Validate(apiKey); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-APIKey collection", func(t *testing.T) {
+		apiKey := &core.APIKey{}
+		apiKey.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		fill(apiKey)
+
+		if err := app.Validate(apiKey); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("APIKey collection", func(t *testing.T) {
+		apiKey := &core.APIKey{}
+		apiKey.SetProxyRecord(core.NewRecord(apiKeysCol))
+		fill(apiKey)
+
+		if err := app.Validate(apiKey); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestAPIKeyValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newAPIKey := func(record *core.Record) *core.APIKey {
+		apiKey := core.NewAPIKey(app)
+		apiKey.SetCollectionRef(record.Collection().Id)
+		apiKey.SetRecordRef(record.Id)
+		apiKey.SetName("test")
+		apiKey.GenerateKey()
+		return apiKey
+	}
+
+	scenarios := []struct {
+		name           string
+		apiKey         func() *core.APIKey
+		expectedErrors []string
+	}{
+		{
+			"empty",
+			func() *core.APIKey {
+				return core.NewAPIKey(app)
+			},
+			[]string{"collectionRef", "recordRef", "name", "keyHash"},
+		},
+		{
+			"non-auth collection",
+			func() *core.APIKey {
+				return newAPIKey(demo1)
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"invalid scopes and IPs",
+			func() *core.APIKey {
+				apiKey := newAPIKey(user)
+				apiKey.SetScopes([]string{"demo1:list", "demo1", "demo1:invalid", "*:*"})
+				apiKey.SetAllowedIPs([]string{"127.0.0.1", "10.0.0.0/8", "invalid"})
+				return apiKey
+			},
+			[]string{"scopes"},
+		},
+		{
+			"invalid IPs",
+			func() *core.APIKey {
+				apiKey := newAPIKey(user)
+				apiKey.SetScopes([]string{"demo1:list", "*:*"})
+				apiKey.SetAllowedIPs([]string{"127.0.0.1", "10.0.0.0/8", "invalid"})
+				return apiKey
+			},
+			[]string{"allowedIPs"},
+		},
+		{
+			"valid",
+			func() *core.APIKey {
+				apiKey := newAPIKey(user)
+				apiKey.SetScopes([]string{"demo1:list", "*:view", "demo2:*"})
+				apiKey.SetAllowedIPs([]string{"127.0.0.1", "::1", "10.0.0.0/8"})
+				return apiKey
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.apiKey())
+			tests.TestValidationErrors(t, errs, s.expectedErrors)
+		})
+	}
+}
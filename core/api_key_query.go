@@ -0,0 +1,105 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Common API key related errors
+var (
+	ErrInvalidAPIKey = errors.New("invalid or missing API key")
+	ErrExpiredAPIKey = errors.New("the API key has expired")
+)
+
+// FindAllAPIKeysByRecord returns all APIKey models linked to the provided auth record (in DESC order).
+func (app *BaseApp) FindAllAPIKeysByRecord(authRecord *Record) ([]*APIKey, error) {
+	result := []*APIKey{}
+
+	err := app.RecordQuery(CollectionNameAPIKeys).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+		}).
+		OrderBy("created DESC").
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindAPIKeyById returns a single APIKey model by its id.
+func (app *BaseApp) FindAPIKeyById(id string) (*APIKey, error) {
+	result := &APIKey{}
+
+	err := app.RecordQuery(CollectionNameAPIKeys).
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindAuthRecordByAPIKey finds the auth record and the APIKey model
+// associated with the provided plain API key.
+//
+// Returns [ErrInvalidAPIKey] if the key is malformed or doesn't match
+// with any of the stored API keys and [ErrExpiredAPIKey] if it has expired.
+//
+// Note that the API key allowed IPs and scopes are not checked.
+func (app *BaseApp) FindAuthRecordByAPIKey(key string) (*Record, *APIKey, error) {
+	id, secret, ok := ParseAPIKey(key)
+	if !ok {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := app.FindAPIKeyById(id)
+	if err != nil || !apiKey.ValidateSecret(secret) {
+		return nil, nil, errors.Join(ErrInvalidAPIKey, err)
+	}
+
+	if apiKey.HasExpired(types.NowDateTime()) {
+		return nil, nil, ErrExpiredAPIKey
+	}
+
+	record, err := app.FindRecordById(apiKey.CollectionRef(), apiKey.RecordRef())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !record.Collection().IsAuth() {
+		return nil, nil, ErrNotAuthRecord
+	}
+
+	return record, apiKey, nil
+}
+
+// DeleteAllAPIKeysByRecord deletes all APIKey models associated with the provided record.
+//
+// Returns a combined error with the failed deletes.
+func (app *BaseApp) DeleteAllAPIKeysByRecord(authRecord *Record) error {
+	models, err := app.FindAllAPIKeysByRecord(authRecord)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, m := range models {
+		if err := app.Delete(m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestFindAllAPIKeysByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := tests.StubAPIKeyRecords(app); err != nil {
+		t.Fatal(err)
+	}
+
+	superuser2, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	superuser4, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test4@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		record   *core.Record
+		expected []string
+	}{
+		{superuser2, []string{"superuser2key", "superuser2scoped", "superuser2expired", "superuser2ip"}},
+		{superuser4, nil},
+		{user1, []string{"user1key"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.record.Collection().Name+"_"+s.record.Id, func(t *testing.T) {
+			result, err := app.FindAllAPIKeysByRecord(s.record)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != len(s.expected) {
+				t.Fatalf("Expected total API keys %d, got %d", len(s.expected), len(result))
+			}
+
+			for _, id := range s.expected {
+				var exists bool
+				for _, apiKey := range result {
+					if apiKey.Id == id {
+						exists = true
+						break
+					}
+				}
+				if !exists {
+					t.Errorf("Missing API key %q", id)
+				}
+			}
+		})
+	}
+}
+
+func TestFindAPIKeyById(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := tests.StubAPIKeyRecords(app); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		id          string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{"user1key", false},
+		{"superuser2scoped", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.id, func(t *testing.T) {
+			result, err := app.FindAPIKeyById(s.id)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.Id != s.id {
+				t.Fatalf("Expected record with id %q, got %q", s.id, result.Id)
+			}
+		})
+	}
+}
+
+func TestFindAuthRecordByAPIKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := tests.StubAPIKeyRecords(app); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		key              string
+		expectedErr      error
+		expectedRecordId string
+	}{
+		{"", core.ErrInvalidAPIKey, ""},
+		{"invalid", core.ErrInvalidAPIKey, ""},
+		{"pbk_missing_secret", core.ErrInvalidAPIKey, ""},
+		{"pbk_user1key_invalid", core.ErrInvalidAPIKey, ""},
+		{"pbk_superuser2expired_secret", core.ErrExpiredAPIKey, ""},
+		// IPs and scopes are not checked
+		{"pbk_superuser2ip_secret", nil, "sbmbsdb40jyxf7h"},
+		{"pbk_superuser2scoped_secret", nil, "sbmbsdb40jyxf7h"},
+		{"pbk_user1key_secret", nil, "4q1xlclmfloku33"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.key, func(t *testing.T) {
+			record, apiKey, err := app.FindAuthRecordByAPIKey(s.key)
+
+			if s.expectedErr != nil {
+				if !errors.Is(err, s.expectedErr) {
+					t.Fatalf("Expected error %v, got %v", s.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected nil error, got %v", err)
+			}
+
+			if record.Id != s.expectedRecordId {
+				t.Fatalf("Expected record %q, got %q", s.expectedRecordId, record.Id)
+			}
+
+			if apiKey.RecordRef() != record.Id || apiKey.CollectionRef() != record.Collection().Id {
+				t.Fatalf("Expected API key linked to record %q, got %q", record.Id, apiKey.RecordRef())
+			}
+		})
+	}
+}
+
+func TestDeleteAllAPIKeysByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := tests.StubAPIKeyRecords(app); err != nil {
+		t.Fatal(err)
+	}
+
+	superuser2, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.DeleteAllAPIKeysByRecord(superuser2); err != nil {
+		t.Fatal(err)
+	}
+
+	apiKeys, err := app.FindAllAPIKeysByRecord(superuser2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(apiKeys) != 0 {
+		t.Fatalf("Expected all superuser2 API keys to be deleted, got %d", len(apiKeys))
+	}
+
+	// other records API keys should remain
+	if _, err := app.FindAPIKeyById("user1key"); err != nil {
+		t.Fatalf("Expected user1key to remain, got %v", err)
+	}
+}
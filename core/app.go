@@ -10,6 +10,7 @@ import (
 
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/tools/cron"
+	"github.com/pocketbase/pocketbase/tools/filescan"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/mailer"
@@ -89,6 +90,12 @@ type App interface {
 	// based on the current app settings.
 	NewMailClient() mailer.Mailer
 
+	// NewFileScanner creates and returns a new clamd file scanner client
+	// based on the current app settings.
+	//
+	// Returns nil if the built-in file scanning is disabled.
+	NewFileScanner() filescan.Scanner
+
 	// NewFilesystem creates a new local or S3 filesystem instance
 	// for managing regular app files (ex. record uploads)
 	// based on the current app settings.
@@ -107,6 +114,16 @@ type App interface {
 	// ReloadSettings reinitializes and reloads the stored application settings.
 	ReloadSettings() error
 
+	// ActiveSigningKey returns the app signing key used for signing
+	// new asymmetric tokens (e.g. the OAuth2 server ID tokens).
+	//
+	// If the app doesn't have any signing key, a new RS256 one is generated and persisted.
+	ActiveSigningKey() (SigningKeyConfig, error)
+
+	// RotateSigningKeys generates and persists a new active app signing key
+	// for the specified algorithm (see [SigningKeysConfig.Rotate]).
+	RotateSigningKeys(algorithm string) (SigningKeyConfig, error)
+
 	// CreateBackup creates a new backup of the current app pb_data directory.
 	//
 	// Backups can be stored on S3 if it is configured in app.Settings().Backups.
@@ -383,6 +400,54 @@ type App interface {
 
 	// ---------------------------------------------------------------
 
+	// RevisionQuery returns a new Revision select query.
+	RevisionQuery() *dbx.SelectQuery
+
+	// FindRevisionById finds a single Revision entry by its id.
+	FindRevisionById(id string) (*Revision, error)
+
+	// FindAllRevisionsByRecord returns all revisions of the provided record
+	// ordered by their creation (oldest first).
+	FindAllRevisionsByRecord(record *Record) ([]*Revision, error)
+
+	// DeleteAllRevisionsByCollection deletes all revisions associated with the provided collection.
+	DeleteAllRevisionsByCollection(collection *Collection) error
+
+	// RevertRecord reverts the record fields to their state right after
+	// the specified revision, aka. it undoes all newer revisions changes,
+	// and saves the record.
+	//
+	// File and password fields are not reverted.
+	RevertRecord(record *Record, revision *Revision) error
+
+	// RevertRecordWithContext is the same as [App.RevertRecord] but allows
+	// specifying a context to limit the db execution.
+	RevertRecordWithContext(ctx context.Context, record *Record, revision *Revision) error
+
+	// ---------------------------------------------------------------
+
+	// WebhookDeliveryQuery returns a new WebhookDelivery select query.
+	WebhookDeliveryQuery() *dbx.SelectQuery
+
+	// FindWebhookDeliveryById finds a single WebhookDelivery entry by its id.
+	FindWebhookDeliveryById(id string) (*WebhookDelivery, error)
+
+	// SendWebhookDelivery makes a single attempt to send the webhook delivery
+	// request and persists its updated state.
+	//
+	// A failed delivery is scheduled for a retry with exponential backoff
+	// until the Webhooks.MaxRetries setting is reached.
+	SendWebhookDelivery(delivery *WebhookDelivery) error
+
+	// RetryWebhookDeliveries resends all pending webhook deliveries
+	// whose next attempt time has been reached.
+	RetryWebhookDeliveries() error
+
+	// DeleteOldWebhookDeliveries delete all webhook deliveries that are created before createdBefore.
+	DeleteOldWebhookDeliveries(createdBefore time.Time) error
+
+	// ---------------------------------------------------------------
+
 	// CollectionQuery returns a new Collection select query.
 	CollectionQuery() *dbx.SelectQuery
 
@@ -465,6 +530,8 @@ type App interface {
 	//
 	// Note that this method will also trigger the records related
 	// cascade and file delete actions.
+	//
+	// The collection soft delete option (if enabled) is ignored, aka. all records are permanently deleted.
 	TruncateCollection(collection *Collection) error
 
 	// ImportCollections imports the provided collections data in a single transaction.
@@ -547,6 +614,136 @@ type App interface {
 
 	// ---------------------------------------------------------------
 
+	// FindAllWebAuthnCredentialsByRecord returns all WebAuthnCredential models
+	// linked to the provided auth record (in DESC order).
+	FindAllWebAuthnCredentialsByRecord(authRecord *Record) ([]*WebAuthnCredential, error)
+
+	// FindWebAuthnCredentialById returns a single WebAuthnCredential model by its id.
+	FindWebAuthnCredentialById(id string) (*WebAuthnCredential, error)
+
+	// FindWebAuthnCredentialByCredentialId returns a single WebAuthnCredential model
+	// by its collection and base64url encoded authenticator credential id.
+	FindWebAuthnCredentialByCredentialId(collectionId string, credentialId string) (*WebAuthnCredential, error)
+
+	// DeleteAllWebAuthnCredentialsByRecord deletes all WebAuthnCredential models associated with the provided record.
+	//
+	// Returns a combined error with the failed deletes.
+	DeleteAllWebAuthnCredentialsByRecord(authRecord *Record) error
+
+	// ---------------------------------------------------------------
+
+	// FindTOTPByRecord returns the TOTP model linked to the provided auth record.
+	FindTOTPByRecord(authRecord *Record) (*TOTP, error)
+
+	// FindTOTPById returns a single TOTP model by its id.
+	FindTOTPById(id string) (*TOTP, error)
+
+	// UpdateTOTPLastStep persists the "lastStep" field of the provided TOTP model
+	// with a conditional update that succeeds only if the stored step is older.
+	//
+	// Returns [ErrTOTPStepUsed] if no row was updated, aka. the same code
+	// was already used (e.g. by a concurrent request).
+	UpdateTOTPLastStep(totp *TOTP) error
+
+	// ---------------------------------------------------------------
+
+	// FindAllAPIKeysByRecord returns all APIKey models linked to the provided auth record (in DESC order).
+	FindAllAPIKeysByRecord(authRecord *Record) ([]*APIKey, error)
+
+	// FindAPIKeyById returns a single APIKey model by its id.
+	FindAPIKeyById(id string) (*APIKey, error)
+
+	// FindAuthRecordByAPIKey finds the auth record and the APIKey model
+	// associated with the provided plain API key.
+	//
+	// Returns [ErrInvalidAPIKey] if the key is malformed or doesn't match
+	// with any of the stored API keys and [ErrExpiredAPIKey] if it has expired.
+	//
+	// Note that the API key allowed IPs and scopes are not checked.
+	FindAuthRecordByAPIKey(key string) (*Record, *APIKey, error)
+
+	// DeleteAllAPIKeysByRecord deletes all APIKey models associated with the provided record.
+	//
+	// Returns a combined error with the failed deletes.
+	DeleteAllAPIKeysByRecord(authRecord *Record) error
+
+	// ---------------------------------------------------------------
+
+	// FindAuthLockoutByRecord returns the AuthLockout model linked to the provided auth record.
+	FindAuthLockoutByRecord(authRecord *Record) (*AuthLockout, error)
+
+	// FindAuthLockoutByIdentity returns the AuthLockout model linked to
+	// the provided collection and unknown identity (compared as it is).
+	FindAuthLockoutByIdentity(collection *Collection, identity string) (*AuthLockout, error)
+
+	// DeleteAllAuthLockoutsByRecord deletes all AuthLockout models associated with the provided record.
+	//
+	// Returns a combined error with the failed deletes.
+	DeleteAllAuthLockoutsByRecord(authRecord *Record) error
+
+	// DeleteExpiredAuthLockouts deletes the no longer relevant AuthLockout models
+	// for all auth collections, aka. the ones without any failed attempt
+	// within the last collection PasswordAuth.Lockout.Duration.
+	DeleteExpiredAuthLockouts() error
+
+	// ---------------------------------------------------------------
+
+	// FindAuthState returns the non-expired AuthState model matching
+	// the provided kind and lookup value.
+	//
+	// Note that the lookup values are expected to be random secrets so the
+	// state is not scoped to a collection and it is up to the caller to check
+	// whether the state collectionRef (and recordRef) match with the current context.
+	//
+	// Returns [sql.ErrNoRows] if there is no such non-expired auth state.
+	FindAuthState(kind string, lookupValue string) (*AuthState, error)
+
+	// ConsumeAuthState finds and deletes the non-expired AuthState model
+	// matching the provided kind and lookup value.
+	//
+	// The find and delete operations are performed in a single transaction
+	// so that the same auth state could be consumed only once.
+	//
+	// Returns [sql.ErrNoRows] if there is no such non-expired auth state.
+	ConsumeAuthState(kind string, lookupValue string) (*AuthState, error)
+
+	// FindAllAuthStatesByRecord returns all non-expired AuthState models
+	// of the specified kind linked to the provided auth record.
+	FindAllAuthStatesByRecord(authRecord *Record, kind string) ([]*AuthState, error)
+
+	// DeleteExpiredAuthStates deletes all expired AuthState models.
+	DeleteExpiredAuthStates() error
+
+	// ---------------------------------------------------------------
+
+	// FindPasswordHistoryByRecord returns the latest (in DESC order) PasswordHistory
+	// models linked to the provided record and password field name.
+	//
+	// Set limit to <= 0 to return all matching models.
+	FindPasswordHistoryByRecord(record *Record, fieldName string, limit int) ([]*PasswordHistory, error)
+
+	// DeleteOldPasswordHistory deletes the PasswordHistory models linked to the
+	// provided record and password field name, keeping only the latest "keep" ones.
+	//
+	// Returns a combined error with the failed deletes.
+	DeleteOldPasswordHistory(record *Record, fieldName string, keep int) error
+
+	// ---------------------------------------------------------------
+
+	// FindUploadById returns a single Upload model by its id.
+	FindUploadById(id string) (*Upload, error)
+
+	// DeleteExpiredUploads deletes the stale Upload models (and their staged chunks),
+	// aka. the ones without any received chunk within the last [UploadMaxAge].
+	DeleteExpiredUploads() error
+
+	// ---------------------------------------------------------------
+
+	// FindOAuth2ClientById returns a single OAuth2Client model by its id (aka. the client id).
+	FindOAuth2ClientById(id string) (*OAuth2Client, error)
+
+	// ---------------------------------------------------------------
+
 	// FindAllAuthOriginsByRecord returns all AuthOrigin models linked to the provided auth record (in DESC order).
 	FindAllAuthOriginsByRecord(authRecord *Record) ([]*AuthOrigin, error)
 
@@ -556,7 +753,7 @@ type App interface {
 	// FindAuthOriginById returns a single AuthOrigin model by its id.
 	FindAuthOriginById(id string) (*AuthOrigin, error)
 
-	// FindAuthOriginByRecordAndFingerprint returns a single AuthOrigin model
+	// FindAuthOriginByRecordAndFingerprint returns the latest AuthOrigin model
 	// by its authRecord relation and fingerprint.
 	FindAuthOriginByRecordAndFingerprint(authRecord *Record, fingerprint string) (*AuthOrigin, error)
 
@@ -572,8 +769,27 @@ type App interface {
 	// In case a collection id or name is provided and that collection doesn't
 	// actually exists, the generated query will be created with a cancelled context
 	// and will fail once an executor (Row(), One(), All(), etc.) is called.
+	//
+	// If the collection has soft delete enabled, the trashed records are excluded
+	// from the query results with an extra WHERE condition
+	// (note that calling query.Where() replaces it; use query.AndWhere() instead).
 	RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuery
 
+	// RecordQueryWithTrashed is similar to RecordQuery but
+	// the returned query includes also the soft deleted (aka. trashed) records.
+	RecordQueryWithTrashed(collectionModelOrIdentifier any) *dbx.SelectQuery
+
+	// RestoreRecord restores a soft deleted (aka. trashed) record
+	// together with the records that were cascade soft deleted with it.
+	//
+	// The restore is performed as a regular record update, aka.
+	// it triggers the related record update hooks.
+	RestoreRecord(record *Record) error
+
+	// DeleteExpiredTrashedRecords permanently deletes the trashed
+	// records older than their collection SoftDelete.MaxDays option.
+	DeleteExpiredTrashedRecords() error
+
 	// FindRecordById finds the Record model by its id.
 	FindRecordById(collectionModelOrIdentifier any, recordId string, optFilters ...func(q *dbx.SelectQuery) error) (*Record, error)
 
@@ -1317,6 +1533,18 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnFileTokenRequest(tags ...string) *hook.TaggedHook[*FileTokenRequestEvent]
 
+	// OnFileScan hook is triggered for each newly uploaded record file
+	// during the record validation.
+	//
+	// By default the file is scanned with the [App.NewFileScanner()] client
+	// (if enabled) but e.Scanner could be replaced with a custom one.
+	// Infected files are rejected with a field validation error.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnFileScan(tags ...string) *hook.TaggedHook[*FileScanEvent]
+
 	// ---------------------------------------------------------------
 	// Record Auth API event hooks
 	// ---------------------------------------------------------------
@@ -1343,6 +1571,21 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordAuthWithPasswordRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithPasswordRequestEvent]
 
+	// OnRecordAuthLockoutRequest hook is triggered when a password auth
+	// identity reaches the collection PasswordAuth.Lockout.MaxFailures
+	// consecutive failed attempts, right before persisting the lock.
+	//
+	// [RecordAuthLockoutRequestEvent.Record] is nil if the failed attempts
+	// were for an identity that doesn't match any auth record.
+	//
+	// Could be used for example to notify an external monitoring system
+	// or to adjust the lockout period (by modifying [RecordAuthLockoutRequestEvent.Lockout]).
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAuthLockoutRequest(tags ...string) *hook.TaggedHook[*RecordAuthLockoutRequestEvent]
+
 	// OnRecordAuthWithOAuth2Request hook is triggered on each Record
 	// OAuth2 sign-in/sign-up API request (after token exchange and before external provider linking).
 	//
@@ -1453,6 +1696,69 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordAuthWithOTPRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithOTPRequestEvent]
 
+	// OnRecordRegisterWebAuthnRequest hook is triggered on each Record
+	// confirm WebAuthn (passkey) registration API request, after the
+	// ceremony response verification and right before saving the new credential.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordRegisterWebAuthnRequest(tags ...string) *hook.TaggedHook[*RecordRegisterWebAuthnRequestEvent]
+
+	// OnRecordAuthWithWebAuthnRequest hook is triggered on each Record
+	// auth with WebAuthn (passkey) API request.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAuthWithWebAuthnRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithWebAuthnRequestEvent]
+
+	// OnRecordConfirmTOTPSetupRequest hook is triggered on each Record
+	// confirm TOTP (authenticator app) setup API request, after the
+	// code verification and right before saving the confirmed TOTP.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordConfirmTOTPSetupRequest(tags ...string) *hook.TaggedHook[*RecordConfirmTOTPSetupRequestEvent]
+
+	// OnRecordAuthWithTOTPRequest hook is triggered on each Record
+	// auth with TOTP (authenticator app) API request.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAuthWithTOTPRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithTOTPRequestEvent]
+
+	// OnRecordAuthWithLDAPRequest hook is triggered on each Record
+	// LDAP sign-in/sign-up API request (after the directory bind and before external auth linking).
+	//
+	// If [RecordAuthWithLDAPRequestEvent.Record] is not set, then the LDAP
+	// request will try to create a new auth Record.
+	//
+	// To assign or link a different existing record model you can
+	// change the [RecordAuthWithLDAPRequestEvent.Record] field.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAuthWithLDAPRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithLDAPRequestEvent]
+
+	// OnRecordAuthWithSAMLRequest hook is triggered on each Record
+	// SAML sign-in/sign-up API request (after the SAML assertion
+	// code exchange and before external auth linking).
+	//
+	// If [RecordAuthWithSAMLRequestEvent.Record] is not set, then the SAML
+	// request will try to create a new auth Record.
+	//
+	// To assign or link a different existing record model you can
+	// change the [RecordAuthWithSAMLRequestEvent.Record] field.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAuthWithSAMLRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithSAMLRequestEvent]
+
 	// ---------------------------------------------------------------
 	// Record CRUD API event hooks
 	// ---------------------------------------------------------------
@@ -1466,6 +1772,47 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordsListRequest(tags ...string) *hook.TaggedHook[*RecordsListRequestEvent]
 
+	// OnRecordsAggregateRequest hook is triggered on each API Records aggregate request.
+	//
+	// Could be used to validate or modify the response before returning it to the client.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordsAggregateRequest(tags ...string) *hook.TaggedHook[*RecordsAggregateRequestEvent]
+
+	// OnRecordsTrashListRequest hook is triggered on each API soft deleted
+	// (aka. trashed) Records list request.
+	//
+	// Could be used to validate or modify the response before returning it to the client.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordsTrashListRequest(tags ...string) *hook.TaggedHook[*RecordsListRequestEvent]
+
+	// OnRecordRestoreRequest hook is triggered on each API soft deleted
+	// (aka. trashed) Record restore request.
+	//
+	// Could be used to additionally validate the request or implement
+	// completely different restore behavior.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordRestoreRequest(tags ...string) *hook.TaggedHook[*RecordRequestEvent]
+
+	// OnRecordTrashDeleteRequest hook is triggered on each API soft deleted
+	// (aka. trashed) Record permanent delete request.
+	//
+	// Could be used to additionally validate the request or implement
+	// completely different delete behavior.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordTrashDeleteRequest(tags ...string) *hook.TaggedHook[*RecordRequestEvent]
+
 	// OnRecordViewRequest hook is triggered on each API Record view request.
 	//
 	// Could be used to validate or modify the response before returning it to the client.
@@ -0,0 +1,216 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameAuthLockouts = "_authLockouts"
+
+var (
+	_ Model        = (*AuthLockout)(nil)
+	_ PreValidator = (*AuthLockout)(nil)
+	_ RecordProxy  = (*AuthLockout)(nil)
+)
+
+// AuthLockout defines a Record proxy for working with the authLockouts collection
+// (aka. the tracked failed password authentication attempts).
+//
+// A lockout is linked either to an existing auth record (recordRef) or,
+// when no record matches, to the normalized submitted identity.
+type AuthLockout struct {
+	*Record
+}
+
+// NewAuthLockout instantiates and returns a new blank *AuthLockout model.
+//
+// Example usage:
+//
+//	lockout := core.NewAuthLockout(app)
+//	lockout.SetCollectionRef(user.Collection().Id)
+//	lockout.SetRecordRef(user.Id)
+//	lockout.SetFailures(1)
+//	app.Save(lockout)
+func NewAuthLockout(app App) *AuthLockout {
+	m := &AuthLockout{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameAuthLockouts)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on AuthLockout.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *AuthLockout) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameAuthLockouts {
+		return errors.New("missing or invalid AuthLockout ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *AuthLockout) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *AuthLockout) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *AuthLockout) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *AuthLockout) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value
+// (empty if the lockout is for an unknown identity).
+func (m *AuthLockout) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *AuthLockout) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Identity returns the "identity" record field value
+// (the normalized submitted identity of an unknown auth record).
+func (m *AuthLockout) Identity() string {
+	return m.GetString("identity")
+}
+
+// SetIdentity updates the "identity" record field value.
+func (m *AuthLockout) SetIdentity(identity string) {
+	m.Set("identity", identity)
+}
+
+// Failures returns the "failures" record field value
+// (the number of consecutive failed attempts).
+func (m *AuthLockout) Failures() int {
+	return m.GetInt("failures")
+}
+
+// SetFailures updates the "failures" record field value.
+func (m *AuthLockout) SetFailures(failures int) {
+	m.Set("failures", failures)
+}
+
+// LastFailure returns the "lastFailure" record field value.
+func (m *AuthLockout) LastFailure() types.DateTime {
+	return m.GetDateTime("lastFailure")
+}
+
+// SetLastFailure updates the "lastFailure" record field value.
+func (m *AuthLockout) SetLastFailure(date types.DateTime) {
+	m.Set("lastFailure", date)
+}
+
+// LockedUntil returns the "lockedUntil" record field value.
+func (m *AuthLockout) LockedUntil() types.DateTime {
+	return m.GetDateTime("lockedUntil")
+}
+
+// SetLockedUntil updates the "lockedUntil" record field value.
+func (m *AuthLockout) SetLockedUntil(date types.DateTime) {
+	m.Set("lockedUntil", date)
+}
+
+// Created returns the "created" record field value.
+func (m *AuthLockout) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *AuthLockout) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+// IsLocked reports whether the lockout is currently active.
+func (m *AuthLockout) IsLocked() bool {
+	lockedUntil := m.LockedUntil()
+
+	return !lockedUntil.IsZero() && lockedUntil.Time().After(time.Now())
+}
+
+// HasExpiredLock reports whether the lockout was locked in the past
+// and its lock period has already elapsed.
+func (m *AuthLockout) HasExpiredLock() bool {
+	lockedUntil := m.LockedUntil()
+
+	return !lockedUntil.IsZero() && !lockedUntil.Time().After(time.Now())
+}
+
+// RetryAfter returns the earliest time when a new attempt is allowed,
+// taking into account both the lock period and the config progressive delay.
+//
+// Returns zero time if a new attempt is allowed right away.
+func (m *AuthLockout) RetryAfter(config PasswordLockoutConfig) time.Time {
+	if m.IsLocked() {
+		return m.LockedUntil().Time()
+	}
+
+	if m.HasExpiredLock() || m.LastFailure().IsZero() {
+		return time.Time{}
+	}
+
+	retryAfter := m.LastFailure().Time().Add(config.DelayTime(m.Failures()))
+	if !retryAfter.After(time.Now()) {
+		return time.Time{}
+	}
+
+	return retryAfter
+}
+
+func (app *BaseApp) registerAuthLockoutHooks() {
+	app.OnRecordValidate(CollectionNameAuthLockouts).Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			collectionId := e.Record.GetString("collectionRef")
+			err := validation.Validate(collectionId, validation.Required, validation.By(validateCollectionId(e.App, CollectionTypeAuth)))
+			if err != nil {
+				return validation.Errors{"collectionRef": err}
+			}
+
+			// the record ref is optional since unknown identities are also tracked
+			// to prevent revealing which accounts exist
+			recordId := e.Record.GetString("recordRef")
+			if recordId == "" && e.Record.GetString("identity") == "" {
+				return validation.Errors{"identity": validation.ErrRequired}
+			}
+			err = validation.Validate(recordId, validation.By(validateRecordId(e.App, collectionId)))
+			if err != nil {
+				return validation.Errors{"recordRef": err}
+			}
+
+			return e.Next()
+		},
+		Priority: 99,
+	})
+
+	recordRefCascadeHooks(app, CollectionNameAuthLockouts, CollectionTypeAuth)
+
+	// run on every hour to cleanup the no longer relevant lockouts
+	app.Cron().Add("__pbAuthLockoutCleanup__", "0 * * * *", func() {
+		if err := app.DeleteExpiredAuthLockouts(); err != nil {
+			app.Logger().Warn("Failed to delete expired auth lockouts", "error", err)
+		}
+	})
+}
@@ -0,0 +1,436 @@
+package core_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestNewAuthLockout(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	lockout := core.NewAuthLockout(app)
+
+	if lockout.Collection().Name != core.CollectionNameAuthLockouts {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameAuthLockouts, lockout.Collection().Name)
+	}
+}
+
+func TestAuthLockoutProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	lockout := core.AuthLockout{}
+	lockout.SetProxyRecord(record)
+
+	if lockout.ProxyRecord() == nil || lockout.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, lockout.ProxyRecord())
+	}
+}
+
+func TestAuthLockoutStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	lockout := core.NewAuthLockout(app)
+
+	fields := []struct {
+		name   string
+		getter func() string
+		setter func(string)
+	}{
+		{"collectionRef", lockout.CollectionRef, lockout.SetCollectionRef},
+		{"recordRef", lockout.RecordRef, lockout.SetRecordRef},
+		{"identity", lockout.Identity, lockout.SetIdentity},
+	}
+
+	testValues := []string{"test_1", "test2", ""}
+
+	for _, f := range fields {
+		for i, testValue := range testValues {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := lockout.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestAuthLockoutFailures(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	lockout := core.NewAuthLockout(app)
+
+	testValues := []int{-1, 0, 1, 10}
+
+	for i, testValue := range testValues {
+		t.Run(fmt.Sprintf("%d_%d", i, testValue), func(t *testing.T) {
+			lockout.SetFailures(testValue)
+
+			if v := lockout.Failures(); v != testValue {
+				t.Fatalf("Expected getter %d, got %d", testValue, v)
+			}
+
+			if v := lockout.GetInt("failures"); v != testValue {
+				t.Fatalf("Expected field value %d, got %d", testValue, v)
+			}
+		})
+	}
+}
+
+func TestAuthLockoutDateFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	lockout := core.NewAuthLockout(app)
+
+	fields := []struct {
+		name   string
+		getter func() types.DateTime
+		setter func(types.DateTime)
+	}{
+		{"lastFailure", lockout.LastFailure, lockout.SetLastFailure},
+		{"lockedUntil", lockout.LockedUntil, lockout.SetLockedUntil},
+	}
+
+	testValues := []types.DateTime{types.NowDateTime(), {}}
+
+	for _, f := range fields {
+		for i, testValue := range testValues {
+			t.Run(fmt.Sprintf("%s_%d", f.name, i), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); !v.Equal(testValue) {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := lockout.GetDateTime(f.name); !v.Equal(testValue) {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestAuthLockoutCreated(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	lockout := core.NewAuthLockout(app)
+
+	if v := lockout.Created().String(); v != "" {
+		t.Fatalf("Expected empty created, got %q", v)
+	}
+
+	now := types.NowDateTime()
+	lockout.SetRaw("created", now)
+
+	if v := lockout.Created().String(); v != now.String() {
+		t.Fatalf("Expected %q created, got %q", now.String(), v)
+	}
+}
+
+func TestAuthLockoutUpdated(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	lockout := core.NewAuthLockout(app)
+
+	if v := lockout.Updated().String(); v != "" {
+		t.Fatalf("Expected empty updated, got %q", v)
+	}
+
+	now := types.NowDateTime()
+	lockout.SetRaw("updated", now)
+
+	if v := lockout.Updated().String(); v != now.String() {
+		t.Fatalf("Expected %q updated, got %q", now.String(), v)
+	}
+}
+
+func TestAuthLockoutLockState(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	now := types.NowDateTime()
+
+	scenarios := []struct {
+		name                   string
+		lockedUntil            types.DateTime
+		expectedIsLocked       bool
+		expectedHasExpiredLock bool
+	}{
+		{"zero lockedUntil", types.DateTime{}, false, false},
+		{"past lockedUntil", now.Add(-1 * time.Minute), false, true},
+		{"future lockedUntil", now.Add(1 * time.Minute), true, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			lockout := core.NewAuthLockout(app)
+			lockout.SetLockedUntil(s.lockedUntil)
+
+			if v := lockout.IsLocked(); v != s.expectedIsLocked {
+				t.Fatalf("Expected IsLocked %v, got %v", s.expectedIsLocked, v)
+			}
+
+			if v := lockout.HasExpiredLock(); v != s.expectedHasExpiredLock {
+				t.Fatalf("Expected HasExpiredLock %v, got %v", s.expectedHasExpiredLock, v)
+			}
+		})
+	}
+}
+
+func TestAuthLockoutRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	now := types.NowDateTime()
+
+	config := core.PasswordLockoutConfig{
+		Enabled:     true,
+		MaxFailures: 5,
+		Duration:    600,
+		Delay:       10,
+	}
+
+	scenarios := []struct {
+		name        string
+		failures    int
+		lastFailure types.DateTime
+		lockedUntil types.DateTime
+		expected    time.Time
+	}{
+		{"no failures", 0, types.DateTime{}, types.DateTime{}, time.Time{}},
+		{"elapsed delay", 2, now.Add(-21 * time.Second), types.DateTime{}, time.Time{}},
+		{"active delay", 2, now.Add(-19 * time.Second), types.DateTime{}, now.Add(1 * time.Second).Time()},
+		{"active lock", 5, now, now.Add(1 * time.Minute), now.Add(1 * time.Minute).Time()},
+		{"expired lock", 5, now.Add(-2 * time.Second), now.Add(-1 * time.Second), time.Time{}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			lockout := core.NewAuthLockout(app)
+			lockout.SetFailures(s.failures)
+			lockout.SetLastFailure(s.lastFailure)
+			lockout.SetLockedUntil(s.lockedUntil)
+
+			result := lockout.RetryAfter(config)
+
+			if !result.Equal(s.expected) {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestAuthLockoutPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	lockoutsCol, err := app.FindCollectionByNameOrId(core.CollectionNameAuthLockouts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fill := func(lockout *core.AuthLockout) {
+		lockout.SetCollectionRef(user.Collection().Id)
+		lockout.SetRecordRef(user.Id)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		lockout := &core.AuthLockout{}
+
+		if err := app.Validate(lockout); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-AuthLockout collection", func(t *testing.T) {
+		lockout := &core.AuthLockout{}
+		lockout.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		fill(lockout)
+
+		if err := app.Validate(lockout); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("AuthLockout collection", func(t *testing.T) {
+		lockout := &core.AuthLockout{}
+		lockout.SetProxyRecord(core.NewRecord(lockoutsCol))
+		fill(lockout)
+
+		if err := app.Validate(lockout); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestAuthLockoutValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name           string
+		lockout        func() *core.AuthLockout
+		expectedErrors []string
+	}{
+		{
+			"empty",
+			func() *core.AuthLockout {
+				return core.NewAuthLockout(app)
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing both recordRef and identity",
+			func() *core.AuthLockout {
+				lockout := core.NewAuthLockout(app)
+				lockout.SetCollectionRef(user.Collection().Id)
+				return lockout
+			},
+			[]string{"identity"},
+		},
+		{
+			"non-auth collection",
+			func() *core.AuthLockout {
+				lockout := core.NewAuthLockout(app)
+				lockout.SetCollectionRef(demo1.Collection().Id)
+				lockout.SetRecordRef(demo1.Id)
+				return lockout
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing record id",
+			func() *core.AuthLockout {
+				lockout := core.NewAuthLockout(app)
+				lockout.SetCollectionRef(user.Collection().Id)
+				lockout.SetRecordRef("missing")
+				return lockout
+			},
+			[]string{"recordRef"},
+		},
+		{
+			"valid record ref",
+			func() *core.AuthLockout {
+				lockout := core.NewAuthLockout(app)
+				lockout.SetCollectionRef(user.Collection().Id)
+				lockout.SetRecordRef(user.Id)
+				return lockout
+			},
+			[]string{},
+		},
+		{
+			"valid identity",
+			func() *core.AuthLockout {
+				lockout := core.NewAuthLockout(app)
+				lockout.SetCollectionRef(user.Collection().Id)
+				lockout.SetIdentity("missing@example.com")
+				return lockout
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.lockout())
+			tests.TestValidationErrors(t, errs, s.expectedErrors)
+		})
+	}
+}
+
+func TestAuthLockoutDeleteOnRecordDelete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userLockout := core.NewAuthLockout(app)
+	userLockout.SetCollectionRef(user.Collection().Id)
+	userLockout.SetRecordRef(user.Id)
+	userLockout.SetFailures(1)
+	if err := app.Save(userLockout); err != nil {
+		t.Fatal(err)
+	}
+
+	identityLockout := core.NewAuthLockout(app)
+	identityLockout.SetCollectionRef(user.Collection().Id)
+	identityLockout.SetIdentity("missing@example.com")
+	identityLockout.SetFailures(1)
+	if err := app.Save(identityLockout); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.FindAuthLockoutByRecord(user); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Expected the user lockout to be deleted, got %v", err)
+	}
+
+	// unknown identities lockouts should remain
+	if _, err := app.FindAuthLockoutByIdentity(user.Collection(), "missing@example.com"); err != nil {
+		t.Fatalf("Expected the identity lockout to remain, got %v", err)
+	}
+}
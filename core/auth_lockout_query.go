@@ -0,0 +1,115 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// FindAuthLockoutByRecord returns the AuthLockout model linked to the provided auth record.
+func (app *BaseApp) FindAuthLockoutByRecord(authRecord *Record) (*AuthLockout, error) {
+	result := &AuthLockout{}
+
+	err := app.RecordQuery(CollectionNameAuthLockouts).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+		}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindAuthLockoutByIdentity returns the AuthLockout model linked to
+// the provided collection and unknown identity (compared as it is).
+func (app *BaseApp) FindAuthLockoutByIdentity(collection *Collection, identity string) (*AuthLockout, error) {
+	result := &AuthLockout{}
+
+	err := app.RecordQuery(CollectionNameAuthLockouts).
+		AndWhere(dbx.HashExp{
+			"collectionRef": collection.Id,
+			"recordRef":     "",
+			"identity":      identity,
+		}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteAllAuthLockoutsByRecord deletes all AuthLockout models associated with the provided record.
+//
+// Returns a combined error with the failed deletes.
+func (app *BaseApp) DeleteAllAuthLockoutsByRecord(authRecord *Record) error {
+	models := []*AuthLockout{}
+
+	err := app.RecordQuery(CollectionNameAuthLockouts).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+		}).
+		All(&models)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, m := range models {
+		if err := app.Delete(m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// DeleteExpiredAuthLockouts deletes the no longer relevant AuthLockout models
+// for all auth collections, aka. the ones without any failed attempt
+// within the last collection PasswordAuth.Lockout.Duration.
+func (app *BaseApp) DeleteExpiredAuthLockouts() error {
+	authCollections, err := app.FindAllCollections(CollectionTypeAuth)
+	if err != nil {
+		return err
+	}
+
+	// note: perform even if the lockout is disabled to ensure that there are no dangling old records
+	for _, collection := range authCollections {
+		minValidDate, err := types.ParseDateTime(time.Now().Add(-1 * collection.PasswordAuth.Lockout.DurationTime()))
+		if err != nil {
+			return err
+		}
+
+		items := []*Record{}
+
+		err = app.RecordQuery(CollectionNameAuthLockouts).
+			AndWhere(dbx.HashExp{"collectionRef": collection.Id}).
+			AndWhere(dbx.NewExp("[[updated]] < {:date}", dbx.Params{"date": minValidDate})).
+			All(&items)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			err = app.Delete(item)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,261 @@
+package core_test
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// stubAuthLockouts creates the following test lockouts:
+//   - "user1_lockout" (test@example.com, updated now)
+//   - "user2_lockout" (test2@example.com, updated 1 hour ago)
+//   - "identity1_lockout" (users "missing@example.com", updated 1 hour ago)
+//   - "superuser_lockout" (superusers "missing@example.com", updated now)
+func stubAuthLockouts(t *testing.T, app core.App) {
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	superusers, err := app.FindCollectionByNameOrId(core.CollectionNameSuperusers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stubs := []struct {
+		id           string
+		collectionId string
+		recordId     string
+		identity     string
+		updated      types.DateTime
+	}{
+		{"user1_lockout", user1.Collection().Id, user1.Id, "", types.NowDateTime()},
+		{"user2_lockout", user2.Collection().Id, user2.Id, "", types.NowDateTime().Add(-1 * time.Hour)},
+		{"identity1_lockout", user1.Collection().Id, "", "missing@example.com", types.NowDateTime().Add(-1 * time.Hour)},
+		{"superuser_lockout", superusers.Id, "", "missing@example.com", types.NowDateTime()},
+	}
+
+	for _, s := range stubs {
+		lockout := core.NewAuthLockout(app)
+		lockout.Id = s.id
+		lockout.SetCollectionRef(s.collectionId)
+		lockout.SetRecordRef(s.recordId)
+		lockout.SetIdentity(s.identity)
+		lockout.SetFailures(1)
+		lockout.SetLastFailure(s.updated)
+		if err := app.SaveNoValidate(lockout); err != nil {
+			t.Fatal(err)
+		}
+
+		// manually update the autodate field
+		_, err := app.DB().Update(core.CollectionNameAuthLockouts, dbx.Params{"updated": s.updated}, dbx.HashExp{"id": s.id}).Execute()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindAuthLockoutByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthLockouts(t, app)
+
+	scenarios := []struct {
+		email      string
+		expectedId string
+	}{
+		{"test@example.com", "user1_lockout"},
+		{"test2@example.com", "user2_lockout"},
+		{"test3@example.com", ""},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.email, func(t *testing.T) {
+			record, err := app.FindAuthRecordByEmail("users", s.email)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			lockout, err := app.FindAuthLockoutByRecord(record)
+
+			if s.expectedId == "" {
+				if !errors.Is(err, sql.ErrNoRows) {
+					t.Fatalf("Expected sql.ErrNoRows, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if lockout.Id != s.expectedId {
+				t.Fatalf("Expected lockout %q, got %q", s.expectedId, lockout.Id)
+			}
+		})
+	}
+}
+
+func TestFindAuthLockoutByIdentity(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthLockouts(t, app)
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	superusers, err := app.FindCollectionByNameOrId(core.CollectionNameSuperusers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		collection *core.Collection
+		identity   string
+		expectedId string
+	}{
+		{users, "", ""},
+		{users, "other@example.com", ""},
+		{users, "MISSING@example.com", ""},
+		{users, "missing@example.com", "identity1_lockout"},
+		{superusers, "missing@example.com", "superuser_lockout"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.collection.Name+"_"+s.identity, func(t *testing.T) {
+			lockout, err := app.FindAuthLockoutByIdentity(s.collection, s.identity)
+
+			if s.expectedId == "" {
+				if !errors.Is(err, sql.ErrNoRows) {
+					t.Fatalf("Expected sql.ErrNoRows, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if lockout.Id != s.expectedId {
+				t.Fatalf("Expected lockout %q, got %q", s.expectedId, lockout.Id)
+			}
+		})
+	}
+}
+
+func TestDeleteAllAuthLockoutsByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthLockouts(t, app)
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deletedIds := []string{}
+	app.OnRecordAfterDeleteSuccess().BindFunc(func(e *core.RecordEvent) error {
+		deletedIds = append(deletedIds, e.Record.Id)
+		return e.Next()
+	})
+
+	if err := app.DeleteAllAuthLockoutsByRecord(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deletedIds) != 1 || deletedIds[0] != "user1_lockout" {
+		t.Fatalf("Expected only user1_lockout to be deleted, got %v", deletedIds)
+	}
+}
+
+func TestDeleteExpiredAuthLockouts(t *testing.T) {
+	t.Parallel()
+
+	checkDeletedIds := func(app core.App, t *testing.T, expectedDeletedIds []string) {
+		stubAuthLockouts(t, app)
+
+		deletedIds := []string{}
+		app.OnRecordAfterDeleteSuccess().BindFunc(func(e *core.RecordEvent) error {
+			deletedIds = append(deletedIds, e.Record.Id)
+			return e.Next()
+		})
+
+		if err := app.DeleteExpiredAuthLockouts(); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(deletedIds) != len(expectedDeletedIds) {
+			t.Fatalf("Expected deleted ids\n%v\ngot\n%v", expectedDeletedIds, deletedIds)
+		}
+
+		for _, id := range expectedDeletedIds {
+			if !slices.Contains(deletedIds, id) {
+				t.Errorf("Expected to find deleted id %q in %v", id, deletedIds)
+			}
+		}
+	}
+
+	t.Run("default test collections", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		// the test db collections have zero lockout duration
+		checkDeletedIds(app, t, []string{
+			"user1_lockout",
+			"user2_lockout",
+			"identity1_lockout",
+			"superuser_lockout",
+		})
+	})
+
+	t.Run("lockout collection duration mock", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			t.Fatal(err)
+		}
+		users.PasswordAuth.Lockout.Duration = 1800
+		if err := app.Save(users); err != nil {
+			t.Fatalf("Failed to mock users lockout duration: %v", err)
+		}
+
+		superusers, err := app.FindCollectionByNameOrId(core.CollectionNameSuperusers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		superusers.PasswordAuth.Lockout.Duration = 1800
+		if err := app.Save(superusers); err != nil {
+			t.Fatalf("Failed to mock superusers lockout duration: %v", err)
+		}
+
+		checkDeletedIds(app, t, []string{
+			"user2_lockout",
+			"identity1_lockout",
+		})
+	})
+}
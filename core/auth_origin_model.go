@@ -98,6 +98,39 @@ func (m *AuthOrigin) SetFingerprint(fingerprint string) {
 	m.Set("fingerprint", fingerprint)
 }
 
+// IP returns the "ip" record field value
+// (the last known IP address of the auth session).
+func (m *AuthOrigin) IP() string {
+	return m.GetString("ip")
+}
+
+// SetIP updates the "ip" record field value.
+func (m *AuthOrigin) SetIP(ip string) {
+	m.Set("ip", ip)
+}
+
+// UserAgent returns the "userAgent" record field value
+// (the last known user agent of the auth session).
+func (m *AuthOrigin) UserAgent() string {
+	return m.GetString("userAgent")
+}
+
+// SetUserAgent updates the "userAgent" record field value.
+func (m *AuthOrigin) SetUserAgent(userAgent string) {
+	m.Set("userAgent", userAgent)
+}
+
+// LastSeen returns the "lastSeen" record field value
+// (the last time the auth session was used).
+func (m *AuthOrigin) LastSeen() types.DateTime {
+	return m.GetDateTime("lastSeen")
+}
+
+// SetLastSeen updates the "lastSeen" record field value.
+func (m *AuthOrigin) SetLastSeen(lastSeen types.DateTime) {
+	m.Set("lastSeen", lastSeen)
+}
+
 // Created returns the "created" record field value.
 func (m *AuthOrigin) Created() types.DateTime {
 	return m.GetDateTime("created")
@@ -163,6 +196,12 @@ func recordRefHooks[T RecordProxy](app App, collectionName string, optCollection
 		Priority: 99,
 	})
 
+	recordRefCascadeHooks(app, collectionName, optCollectionTypes...)
+}
+
+// recordRefCascadeHooks registers hooks that delete the record proxies of the
+// specified collection on their "collectionRef" or "recordRef" relation delete.
+func recordRefCascadeHooks(app App, collectionName string, optCollectionTypes ...string) {
 	// delete on collection ref delete
 	app.OnCollectionDeleteExecute().Bind(&hook.Handler[*CollectionEvent]{
 		Func: func(e *CollectionEvent) error {
@@ -109,6 +109,74 @@ func TestAuthOriginFingerprint(t *testing.T) {
 	}
 }
 
+func TestAuthOriginIP(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	origin := core.NewAuthOrigin(app)
+
+	testValues := []string{"127.0.0.1", "::1", ""}
+	for i, testValue := range testValues {
+		t.Run(fmt.Sprintf("%d_%q", i, testValue), func(t *testing.T) {
+			origin.SetIP(testValue)
+
+			if v := origin.IP(); v != testValue {
+				t.Fatalf("Expected getter %q, got %q", testValue, v)
+			}
+
+			if v := origin.GetString("ip"); v != testValue {
+				t.Fatalf("Expected field value %q, got %q", testValue, v)
+			}
+		})
+	}
+}
+
+func TestAuthOriginUserAgent(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	origin := core.NewAuthOrigin(app)
+
+	testValues := []string{"test_1", "Mozilla/5.0", ""}
+	for i, testValue := range testValues {
+		t.Run(fmt.Sprintf("%d_%q", i, testValue), func(t *testing.T) {
+			origin.SetUserAgent(testValue)
+
+			if v := origin.UserAgent(); v != testValue {
+				t.Fatalf("Expected getter %q, got %q", testValue, v)
+			}
+
+			if v := origin.GetString("userAgent"); v != testValue {
+				t.Fatalf("Expected field value %q, got %q", testValue, v)
+			}
+		})
+	}
+}
+
+func TestAuthOriginLastSeen(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	origin := core.NewAuthOrigin(app)
+
+	if v := origin.LastSeen().String(); v != "" {
+		t.Fatalf("Expected empty lastSeen, got %q", v)
+	}
+
+	now := types.NowDateTime()
+	origin.SetLastSeen(now)
+
+	if v := origin.LastSeen().String(); v != now.String() {
+		t.Fatalf("Expected %q lastSeen, got %q", now.String(), v)
+	}
+}
+
 func TestAuthOriginCreated(t *testing.T) {
 	t.Parallel()
 
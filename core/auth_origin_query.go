@@ -1,6 +1,7 @@
 package core
 
 import (
+	"database/sql"
 	"errors"
 
 	"github.com/pocketbase/dbx"
@@ -57,7 +58,7 @@ func (app *BaseApp) FindAuthOriginById(id string) (*AuthOrigin, error) {
 	return result, nil
 }
 
-// FindAuthOriginByRecordAndFingerprint returns a single AuthOrigin model
+// FindAuthOriginByRecordAndFingerprint returns the latest AuthOrigin model
 // by its authRecord relation and fingerprint.
 func (app *BaseApp) FindAuthOriginByRecordAndFingerprint(authRecord *Record, fingerprint string) (*AuthOrigin, error) {
 	result := &AuthOrigin{}
@@ -68,6 +69,7 @@ func (app *BaseApp) FindAuthOriginByRecordAndFingerprint(authRecord *Record, fin
 			"recordRef":     authRecord.Id,
 			"fingerprint":   fingerprint,
 		}).
+		OrderBy("created DESC").
 		Limit(1).
 		One(result)
 
@@ -99,3 +101,29 @@ func (app *BaseApp) DeleteAllAuthOriginsByRecord(authRecord *Record) error {
 
 	return nil
 }
+
+// authSessionExists checks whether an AuthOrigin model with the specified id
+// exists and is linked to the provided auth record.
+//
+// It is used as a lightweight auth session check on each token verification.
+func (app *BaseApp) authSessionExists(authRecord *Record, sessionId string) (bool, error) {
+	var exists int
+
+	err := app.ConcurrentDB().Select("(1)").
+		From(CollectionNameAuthOrigins).
+		AndWhere(dbx.HashExp{
+			"id":            sessionId,
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+		}).
+		Limit(1).
+		Row(&exists)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return exists > 0, nil
+}
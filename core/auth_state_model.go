@@ -0,0 +1,188 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameAuthStates = "_authStates"
+
+var (
+	_ Model        = (*AuthState)(nil)
+	_ PreValidator = (*AuthState)(nil)
+	_ RecordProxy  = (*AuthState)(nil)
+)
+
+// AuthState defines a Record proxy for working with the authStates collection
+// (aka. the short-lived state of the multi-step auth flows like the
+// pending WebAuthn challenges, SAML requests or OAuth2 authorization codes).
+//
+// Each state is identified by its kind and hashed lookup key
+// and it is usually consumed (deleted) on its first use.
+type AuthState struct {
+	*Record
+}
+
+// NewAuthState instantiates and returns a new blank *AuthState model.
+//
+// Example usage:
+//
+//	state := core.NewAuthState(app)
+//	state.SetCollectionRef(collection.Id)
+//	state.SetKind("myFlow")
+//	state.SetKey(code)
+//	state.SetExpires(types.NowDateTime().Add(5 * time.Minute))
+//	app.Save(state)
+func NewAuthState(app App) *AuthState {
+	m := &AuthState{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameAuthStates)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on AuthState.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *AuthState) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameAuthStates {
+		return errors.New("missing or invalid AuthState ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *AuthState) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *AuthState) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *AuthState) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *AuthState) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value
+// (could be empty if the state is not linked to a specific auth record).
+func (m *AuthState) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *AuthState) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Kind returns the "kind" record field value
+// (the name of the auth flow step the state belongs to).
+func (m *AuthState) Kind() string {
+	return m.GetString("kind")
+}
+
+// SetKind updates the "kind" record field value.
+func (m *AuthState) SetKind(kind string) {
+	m.Set("kind", kind)
+}
+
+// Key returns the "key" record field value
+// (aka. the hash of the state lookup value).
+func (m *AuthState) Key() string {
+	return m.GetString("key")
+}
+
+// SetKey updates the "key" record field value with the hash of
+// the provided lookup value (e.g. authorization code, challenge id, etc.).
+func (m *AuthState) SetKey(lookupValue string) {
+	m.Set("key", authStateKeyHash(lookupValue))
+}
+
+// UnmarshalData unmarshals the "data" record field value into result.
+func (m *AuthState) UnmarshalData(result any) error {
+	return m.UnmarshalJSONField("data", result)
+}
+
+// SetData updates the "data" record field value
+// (the value is expected to be JSON serializable).
+func (m *AuthState) SetData(data any) {
+	m.Set("data", data)
+}
+
+// Expires returns the "expires" record field value.
+func (m *AuthState) Expires() types.DateTime {
+	return m.GetDateTime("expires")
+}
+
+// SetExpires updates the "expires" record field value.
+func (m *AuthState) SetExpires(date types.DateTime) {
+	m.Set("expires", date)
+}
+
+// Created returns the "created" record field value.
+func (m *AuthState) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// HasExpired reports whether the state expiration date has passed.
+func (m *AuthState) HasExpired() bool {
+	return !m.Expires().Time().After(time.Now())
+}
+
+// authStateKeyHash returns the stored hash of the provided state lookup value
+// (the lookup values are usually bearer secrets so they are never stored as they are).
+func authStateKeyHash(lookupValue string) string {
+	return security.SHA256(lookupValue)
+}
+
+func (app *BaseApp) registerAuthStateHooks() {
+	app.OnRecordValidate(CollectionNameAuthStates).Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			collectionId := e.Record.GetString("collectionRef")
+			err := validation.Validate(collectionId, validation.Required, validation.By(validateCollectionId(e.App, CollectionTypeAuth)))
+			if err != nil {
+				return validation.Errors{"collectionRef": err}
+			}
+
+			// the record ref is optional since most of the states
+			// are created before the auth record is known
+			recordId := e.Record.GetString("recordRef")
+			err = validation.Validate(recordId, validation.By(validateRecordId(e.App, collectionId)))
+			if err != nil {
+				return validation.Errors{"recordRef": err}
+			}
+
+			return e.Next()
+		},
+		Priority: 99,
+	})
+
+	recordRefCascadeHooks(app, CollectionNameAuthStates, CollectionTypeAuth)
+
+	// run on every hour to cleanup the expired auth states
+	app.Cron().Add("__pbAuthStateCleanup__", "0 * * * *", func() {
+		if err := app.DeleteExpiredAuthStates(); err != nil {
+			app.Logger().Warn("Failed to delete expired auth states", "error", err)
+		}
+	})
+}
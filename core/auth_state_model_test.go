@@ -0,0 +1,304 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestNewAuthState(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	state := core.NewAuthState(app)
+
+	if state.Collection().Name != core.CollectionNameAuthStates {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameAuthStates, state.Collection().Name)
+	}
+}
+
+func TestAuthStateProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	state := core.AuthState{}
+	state.SetProxyRecord(record)
+
+	if state.ProxyRecord() == nil || state.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, state.ProxyRecord())
+	}
+}
+
+func TestAuthStateStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	state := core.NewAuthState(app)
+
+	fields := []struct {
+		name   string
+		getter func() string
+		setter func(string)
+	}{
+		{"collectionRef", state.CollectionRef, state.SetCollectionRef},
+		{"recordRef", state.RecordRef, state.SetRecordRef},
+		{"kind", state.Kind, state.SetKind},
+	}
+
+	testValues := []string{"test_1", "test2", ""}
+
+	for _, f := range fields {
+		for i, testValue := range testValues {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := state.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestAuthStateKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	state := core.NewAuthState(app)
+	state.SetKey("test")
+
+	expected := security.SHA256("test")
+
+	if v := state.Key(); v != expected {
+		t.Fatalf("Expected the hashed key %q, got %q", expected, v)
+	}
+}
+
+func TestAuthStateData(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	state := core.NewAuthState(app)
+	state.SetData(map[string]any{"a": 123, "b": "test"})
+
+	result := struct {
+		A int    `json:"a"`
+		B string `json:"b"`
+	}{}
+	if err := state.UnmarshalData(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result.A != 123 || result.B != "test" {
+		t.Fatalf("Expected the data to be unmarshaled, got %#v", result)
+	}
+}
+
+func TestAuthStateExpires(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		expires  types.DateTime
+		expected bool
+	}{
+		{types.DateTime{}, true},
+		{types.NowDateTime().Add(-1 * time.Minute), true},
+		{types.NowDateTime().Add(1 * time.Minute), false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.expires), func(t *testing.T) {
+			state := core.NewAuthState(app)
+			state.SetExpires(s.expires)
+
+			if v := state.Expires(); !v.Equal(s.expires) {
+				t.Fatalf("Expected expires %q, got %q", s.expires, v)
+			}
+
+			if v := state.HasExpired(); v != s.expected {
+				t.Fatalf("Expected HasExpired %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestAuthStatePreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	t.Run("no proxy record", func(t *testing.T) {
+		state := &core.AuthState{}
+
+		if err := app.Validate(state); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-AuthState collection", func(t *testing.T) {
+		state := &core.AuthState{}
+		state.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+
+		if err := app.Validate(state); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("AuthState collection", func(t *testing.T) {
+		user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		state := core.NewAuthState(app)
+		state.SetCollectionRef(user.Collection().Id)
+		state.SetKind("test")
+		state.SetKey("test")
+		state.SetExpires(types.NowDateTime().Add(1 * time.Minute))
+
+		if err := app.Validate(state); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestAuthStateValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		state        func() *core.AuthState
+		expectErrors []string
+	}{
+		{
+			"empty",
+			func() *core.AuthState {
+				return core.NewAuthState(app)
+			},
+			[]string{"collectionRef", "kind", "key", "expires"},
+		},
+		{
+			"non-auth collection",
+			func() *core.AuthState {
+				state := core.NewAuthState(app)
+				state.SetCollectionRef(demo1.Collection().Id)
+				state.SetKind("test")
+				state.SetKey("test")
+				state.SetExpires(types.NowDateTime())
+				return state
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing record",
+			func() *core.AuthState {
+				state := core.NewAuthState(app)
+				state.SetCollectionRef(user.Collection().Id)
+				state.SetRecordRef("missing")
+				state.SetKind("test")
+				state.SetKey("test")
+				state.SetExpires(types.NowDateTime())
+				return state
+			},
+			[]string{"recordRef"},
+		},
+		{
+			"valid without record",
+			func() *core.AuthState {
+				state := core.NewAuthState(app)
+				state.SetCollectionRef(user.Collection().Id)
+				state.SetKind("test")
+				state.SetKey("test")
+				state.SetExpires(types.NowDateTime())
+				return state
+			},
+			[]string{},
+		},
+		{
+			"valid with record",
+			func() *core.AuthState {
+				state := core.NewAuthState(app)
+				state.SetCollectionRef(user.Collection().Id)
+				state.SetRecordRef(user.Id)
+				state.SetKind("test")
+				state.SetKey("test")
+				state.SetExpires(types.NowDateTime())
+				return state
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.state())
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestAuthStateCascadeDelete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := core.NewAuthState(app)
+	state.SetCollectionRef(user.Collection().Id)
+	state.SetRecordRef(user.Id)
+	state.SetKind("test")
+	state.SetKey("test")
+	state.SetExpires(types.NowDateTime().Add(1 * time.Minute))
+	if err := app.Save(state); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.FindRecordById(core.CollectionNameAuthStates, state.Id); err == nil {
+		t.Fatal("Expected the auth state to be deleted together with its record")
+	}
+}
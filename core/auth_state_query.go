@@ -0,0 +1,112 @@
+package core
+
+import (
+	"database/sql"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// FindAuthState returns the non-expired AuthState model matching
+// the provided kind and lookup value.
+//
+// Note that the lookup values are expected to be random secrets so the
+// state is not scoped to a collection and it is up to the caller to check
+// whether the state collectionRef (and recordRef) match with the current context.
+//
+// Returns [sql.ErrNoRows] if there is no such non-expired auth state.
+func (app *BaseApp) FindAuthState(kind string, lookupValue string) (*AuthState, error) {
+	result := &AuthState{}
+
+	err := app.RecordQuery(CollectionNameAuthStates).
+		AndWhere(dbx.HashExp{
+			"kind": kind,
+			"key":  authStateKeyHash(lookupValue),
+		}).
+		AndWhere(dbx.NewExp("[[expires]] > {:now}", dbx.Params{"now": types.NowDateTime()})).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ConsumeAuthState finds and deletes the non-expired AuthState model
+// matching the provided kind and lookup value.
+//
+// The find and delete operations are performed in a single transaction
+// so that the same auth state could be consumed only once.
+//
+// Returns [sql.ErrNoRows] if there is no such non-expired auth state.
+func (app *BaseApp) ConsumeAuthState(kind string, lookupValue string) (*AuthState, error) {
+	var result *AuthState
+
+	err := app.RunInTransaction(func(txApp App) error {
+		state, err := txApp.FindAuthState(kind, lookupValue)
+		if err != nil {
+			return err
+		}
+
+		if err := txApp.Delete(state); err != nil {
+			return err
+		}
+
+		result = state
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	return result, nil
+}
+
+// FindAllAuthStatesByRecord returns all non-expired AuthState models
+// of the specified kind linked to the provided auth record.
+func (app *BaseApp) FindAllAuthStatesByRecord(authRecord *Record, kind string) ([]*AuthState, error) {
+	result := []*AuthState{}
+
+	err := app.RecordQuery(CollectionNameAuthStates).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+			"kind":          kind,
+		}).
+		AndWhere(dbx.NewExp("[[expires]] > {:now}", dbx.Params{"now": types.NowDateTime()})).
+		OrderBy("created DESC").
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteExpiredAuthStates deletes all expired AuthState models.
+func (app *BaseApp) DeleteExpiredAuthStates() error {
+	states := []*AuthState{}
+
+	err := app.RecordQuery(CollectionNameAuthStates).
+		AndWhere(dbx.NewExp("[[expires]] <= {:now}", dbx.Params{"now": types.NowDateTime()})).
+		All(&states)
+	if err != nil {
+		return err
+	}
+
+	for _, state := range states {
+		if err := app.Delete(state); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package core_test
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// stubAuthStates creates the following test auth states:
+//   - "state1" (users, kind "test", key "k1", not expired)
+//   - "state2" (users, kind "test", key "k2", expired)
+//   - "state3" (users, kind "other", key "k1", linked to test@example.com, not expired)
+//   - "state4" (superusers, kind "test", key "k3", not expired)
+func stubAuthStates(t *testing.T, app core.App) {
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	superusers, err := app.FindCollectionByNameOrId(core.CollectionNameSuperusers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stubs := []struct {
+		id           string
+		collectionId string
+		recordId     string
+		kind         string
+		key          string
+		expires      types.DateTime
+	}{
+		{"state1", user.Collection().Id, "", "test", "k1", types.NowDateTime().Add(1 * time.Hour)},
+		{"state2", user.Collection().Id, "", "test", "k2", types.NowDateTime().Add(-1 * time.Hour)},
+		{"state3", user.Collection().Id, user.Id, "other", "k1", types.NowDateTime().Add(1 * time.Hour)},
+		{"state4", superusers.Id, "", "test", "k3", types.NowDateTime().Add(1 * time.Hour)},
+	}
+
+	for _, s := range stubs {
+		state := core.NewAuthState(app)
+		state.Id = s.id
+		state.SetCollectionRef(s.collectionId)
+		state.SetRecordRef(s.recordId)
+		state.SetKind(s.kind)
+		state.SetKey(s.key)
+		state.SetExpires(s.expires)
+		if err := app.SaveNoValidate(state); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindAuthState(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthStates(t, app)
+
+	scenarios := []struct {
+		kind       string
+		key        string
+		expectedId string
+	}{
+		{"test", "missing", ""},
+		{"test", "k1", "state1"},
+		{"test", "k2", ""}, // expired
+		{"other", "k1", "state3"},
+		{"other", "k3", ""},
+		{"test", "k3", "state4"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.kind+"_"+s.key, func(t *testing.T) {
+			state, err := app.FindAuthState(s.kind, s.key)
+
+			if s.expectedId == "" {
+				if !errors.Is(err, sql.ErrNoRows) {
+					t.Fatalf("Expected sql.ErrNoRows, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if state.Id != s.expectedId {
+				t.Fatalf("Expected auth state %q, got %q", s.expectedId, state.Id)
+			}
+		})
+	}
+}
+
+func TestConsumeAuthState(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthStates(t, app)
+
+	if _, err := app.ConsumeAuthState("test", "k2"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Expected sql.ErrNoRows for the expired state, got %v", err)
+	}
+
+	// concurrent consume
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	consumed := 0
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			state, err := app.ConsumeAuthState("test", "k1")
+			if err == nil && state.Id == "state1" {
+				mu.Lock()
+				consumed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if consumed != 1 {
+		t.Fatalf("Expected the auth state to be consumed exactly once, got %d", consumed)
+	}
+
+	if _, err := app.FindRecordById(core.CollectionNameAuthStates, "state1"); err == nil {
+		t.Fatal("Expected the consumed auth state to be deleted")
+	}
+}
+
+func TestFindAllAuthStatesByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthStates(t, app)
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		kind        string
+		expectedIds []string
+	}{
+		{"missing", []string{}},
+		{"test", []string{}},
+		{"other", []string{"state3"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.kind, func(t *testing.T) {
+			states, err := app.FindAllAuthStatesByRecord(user, s.kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(states) != len(s.expectedIds) {
+				t.Fatalf("Expected %d auth states, got %d", len(s.expectedIds), len(states))
+			}
+
+			for i, id := range s.expectedIds {
+				if states[i].Id != id {
+					t.Fatalf("Expected auth state %q at %d, got %q", id, i, states[i].Id)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteExpiredAuthStates(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthStates(t, app)
+
+	if err := app.DeleteExpiredAuthStates(); err != nil {
+		t.Fatal(err)
+	}
+
+	states, err := app.FindAllRecords(core.CollectionNameAuthStates)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make([]string, len(states))
+	for i, state := range states {
+		ids[i] = state.Id
+	}
+
+	if len(ids) != 3 {
+		t.Fatalf("Expected 3 remaining auth states, got %v", ids)
+	}
+
+	for _, id := range ids {
+		if id == "state2" {
+			t.Fatalf("Expected the expired auth state to be deleted, got %v", ids)
+		}
+	}
+}
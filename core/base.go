@@ -17,6 +17,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/tools/cron"
+	"github.com/pocketbase/pocketbase/tools/filescan"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/logger"
@@ -159,10 +160,12 @@ type BaseApp struct {
 	// file api event hooks
 	onFileDownloadRequest *hook.Hook[*FileDownloadRequestEvent]
 	onFileTokenRequest    *hook.Hook[*FileTokenRequestEvent]
+	onFileScan            *hook.Hook[*FileScanEvent]
 
 	// record auth API event hooks
 	onRecordAuthRequest                 *hook.Hook[*RecordAuthRequestEvent]
 	onRecordAuthWithPasswordRequest     *hook.Hook[*RecordAuthWithPasswordRequestEvent]
+	onRecordAuthLockoutRequest          *hook.Hook[*RecordAuthLockoutRequestEvent]
 	onRecordAuthWithOAuth2Request       *hook.Hook[*RecordAuthWithOAuth2RequestEvent]
 	onRecordAuthRefreshRequest          *hook.Hook[*RecordAuthRefreshRequestEvent]
 	onRecordRequestPasswordResetRequest *hook.Hook[*RecordRequestPasswordResetRequestEvent]
@@ -173,13 +176,23 @@ type BaseApp struct {
 	onRecordConfirmEmailChangeRequest   *hook.Hook[*RecordConfirmEmailChangeRequestEvent]
 	onRecordRequestOTPRequest           *hook.Hook[*RecordCreateOTPRequestEvent]
 	onRecordAuthWithOTPRequest          *hook.Hook[*RecordAuthWithOTPRequestEvent]
+	onRecordRegisterWebAuthnRequest     *hook.Hook[*RecordRegisterWebAuthnRequestEvent]
+	onRecordAuthWithWebAuthnRequest     *hook.Hook[*RecordAuthWithWebAuthnRequestEvent]
+	onRecordConfirmTOTPSetupRequest     *hook.Hook[*RecordConfirmTOTPSetupRequestEvent]
+	onRecordAuthWithTOTPRequest         *hook.Hook[*RecordAuthWithTOTPRequestEvent]
+	onRecordAuthWithLDAPRequest         *hook.Hook[*RecordAuthWithLDAPRequestEvent]
+	onRecordAuthWithSAMLRequest         *hook.Hook[*RecordAuthWithSAMLRequestEvent]
 
 	// record crud API event hooks
-	onRecordsListRequest  *hook.Hook[*RecordsListRequestEvent]
-	onRecordViewRequest   *hook.Hook[*RecordRequestEvent]
-	onRecordCreateRequest *hook.Hook[*RecordRequestEvent]
-	onRecordUpdateRequest *hook.Hook[*RecordRequestEvent]
-	onRecordDeleteRequest *hook.Hook[*RecordRequestEvent]
+	onRecordsListRequest       *hook.Hook[*RecordsListRequestEvent]
+	onRecordsAggregateRequest  *hook.Hook[*RecordsAggregateRequestEvent]
+	onRecordsTrashListRequest  *hook.Hook[*RecordsListRequestEvent]
+	onRecordRestoreRequest     *hook.Hook[*RecordRequestEvent]
+	onRecordTrashDeleteRequest *hook.Hook[*RecordRequestEvent]
+	onRecordViewRequest        *hook.Hook[*RecordRequestEvent]
+	onRecordCreateRequest      *hook.Hook[*RecordRequestEvent]
+	onRecordUpdateRequest      *hook.Hook[*RecordRequestEvent]
+	onRecordDeleteRequest      *hook.Hook[*RecordRequestEvent]
 
 	// collection API event hooks
 	onCollectionsListRequest   *hook.Hook[*CollectionsListRequestEvent]
@@ -307,10 +320,12 @@ func (app *BaseApp) initHooks() {
 	// file API event hooks
 	app.onFileDownloadRequest = &hook.Hook[*FileDownloadRequestEvent]{}
 	app.onFileTokenRequest = &hook.Hook[*FileTokenRequestEvent]{}
+	app.onFileScan = &hook.Hook[*FileScanEvent]{}
 
 	// record auth API event hooks
 	app.onRecordAuthRequest = &hook.Hook[*RecordAuthRequestEvent]{}
 	app.onRecordAuthWithPasswordRequest = &hook.Hook[*RecordAuthWithPasswordRequestEvent]{}
+	app.onRecordAuthLockoutRequest = &hook.Hook[*RecordAuthLockoutRequestEvent]{}
 	app.onRecordAuthWithOAuth2Request = &hook.Hook[*RecordAuthWithOAuth2RequestEvent]{}
 	app.onRecordAuthRefreshRequest = &hook.Hook[*RecordAuthRefreshRequestEvent]{}
 	app.onRecordRequestPasswordResetRequest = &hook.Hook[*RecordRequestPasswordResetRequestEvent]{}
@@ -321,9 +336,19 @@ func (app *BaseApp) initHooks() {
 	app.onRecordConfirmEmailChangeRequest = &hook.Hook[*RecordConfirmEmailChangeRequestEvent]{}
 	app.onRecordRequestOTPRequest = &hook.Hook[*RecordCreateOTPRequestEvent]{}
 	app.onRecordAuthWithOTPRequest = &hook.Hook[*RecordAuthWithOTPRequestEvent]{}
+	app.onRecordRegisterWebAuthnRequest = &hook.Hook[*RecordRegisterWebAuthnRequestEvent]{}
+	app.onRecordAuthWithWebAuthnRequest = &hook.Hook[*RecordAuthWithWebAuthnRequestEvent]{}
+	app.onRecordConfirmTOTPSetupRequest = &hook.Hook[*RecordConfirmTOTPSetupRequestEvent]{}
+	app.onRecordAuthWithTOTPRequest = &hook.Hook[*RecordAuthWithTOTPRequestEvent]{}
+	app.onRecordAuthWithLDAPRequest = &hook.Hook[*RecordAuthWithLDAPRequestEvent]{}
+	app.onRecordAuthWithSAMLRequest = &hook.Hook[*RecordAuthWithSAMLRequestEvent]{}
 
 	// record crud API event hooks
 	app.onRecordsListRequest = &hook.Hook[*RecordsListRequestEvent]{}
+	app.onRecordsAggregateRequest = &hook.Hook[*RecordsAggregateRequestEvent]{}
+	app.onRecordsTrashListRequest = &hook.Hook[*RecordsListRequestEvent]{}
+	app.onRecordRestoreRequest = &hook.Hook[*RecordRequestEvent]{}
+	app.onRecordTrashDeleteRequest = &hook.Hook[*RecordRequestEvent]{}
 	app.onRecordViewRequest = &hook.Hook[*RecordRequestEvent]{}
 	app.onRecordCreateRequest = &hook.Hook[*RecordRequestEvent]{}
 	app.onRecordUpdateRequest = &hook.Hook[*RecordRequestEvent]{}
@@ -615,6 +640,24 @@ func (app *BaseApp) SubscriptionsBroker() *subscriptions.Broker {
 	return app.subscriptionsBroker
 }
 
+// NewFileScanner creates and returns a new clamd file scanner client
+// based on the current app settings.
+//
+// Returns nil if the built-in file scanning is disabled.
+func (app *BaseApp) NewFileScanner() filescan.Scanner {
+	settings := app.Settings().FileScan
+
+	if !settings.Enabled {
+		return nil
+	}
+
+	return &filescan.Clamd{
+		Network: settings.Network,
+		Address: settings.Address,
+		Timeout: time.Duration(settings.Timeout) * time.Second,
+	}
+}
+
 // NewMailClient creates and returns a new SMTP or Sendmail client
 // based on the current app settings.
 func (app *BaseApp) NewMailClient() mailer.Mailer {
@@ -1060,6 +1103,10 @@ func (app *BaseApp) OnFileTokenRequest(tags ...string) *hook.TaggedHook[*FileTok
 	return hook.NewTaggedHook(app.onFileTokenRequest, tags...)
 }
 
+func (app *BaseApp) OnFileScan(tags ...string) *hook.TaggedHook[*FileScanEvent] {
+	return hook.NewTaggedHook(app.onFileScan, tags...)
+}
+
 // -------------------------------------------------------------------
 // Record auth API event hooks
 // -------------------------------------------------------------------
@@ -1072,6 +1119,10 @@ func (app *BaseApp) OnRecordAuthWithPasswordRequest(tags ...string) *hook.Tagged
 	return hook.NewTaggedHook(app.onRecordAuthWithPasswordRequest, tags...)
 }
 
+func (app *BaseApp) OnRecordAuthLockoutRequest(tags ...string) *hook.TaggedHook[*RecordAuthLockoutRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordAuthLockoutRequest, tags...)
+}
+
 func (app *BaseApp) OnRecordAuthWithOAuth2Request(tags ...string) *hook.TaggedHook[*RecordAuthWithOAuth2RequestEvent] {
 	return hook.NewTaggedHook(app.onRecordAuthWithOAuth2Request, tags...)
 }
@@ -1112,6 +1163,30 @@ func (app *BaseApp) OnRecordAuthWithOTPRequest(tags ...string) *hook.TaggedHook[
 	return hook.NewTaggedHook(app.onRecordAuthWithOTPRequest, tags...)
 }
 
+func (app *BaseApp) OnRecordRegisterWebAuthnRequest(tags ...string) *hook.TaggedHook[*RecordRegisterWebAuthnRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordRegisterWebAuthnRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordAuthWithWebAuthnRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithWebAuthnRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordAuthWithWebAuthnRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordConfirmTOTPSetupRequest(tags ...string) *hook.TaggedHook[*RecordConfirmTOTPSetupRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordConfirmTOTPSetupRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordAuthWithTOTPRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithTOTPRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordAuthWithTOTPRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordAuthWithLDAPRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithLDAPRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordAuthWithLDAPRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordAuthWithSAMLRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithSAMLRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordAuthWithSAMLRequest, tags...)
+}
+
 // -------------------------------------------------------------------
 // Record CRUD API event hooks
 // -------------------------------------------------------------------
@@ -1120,6 +1195,22 @@ func (app *BaseApp) OnRecordsListRequest(tags ...string) *hook.TaggedHook[*Recor
 	return hook.NewTaggedHook(app.onRecordsListRequest, tags...)
 }
 
+func (app *BaseApp) OnRecordsAggregateRequest(tags ...string) *hook.TaggedHook[*RecordsAggregateRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordsAggregateRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordsTrashListRequest(tags ...string) *hook.TaggedHook[*RecordsListRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordsTrashListRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordRestoreRequest(tags ...string) *hook.TaggedHook[*RecordRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordRestoreRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordTrashDeleteRequest(tags ...string) *hook.TaggedHook[*RecordRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordTrashDeleteRequest, tags...)
+}
+
 func (app *BaseApp) OnRecordViewRequest(tags ...string) *hook.TaggedHook[*RecordRequestEvent] {
 	return hook.NewTaggedHook(app.onRecordViewRequest, tags...)
 }
@@ -1313,7 +1404,8 @@ func (app *BaseApp) registerBaseHooks() {
 	app.OnModelAfterDeleteSuccess().Bind(&hook.Handler[*ModelEvent]{
 		Id: "__pbFilesManagerDelete__",
 		Func: func(e *ModelEvent) error {
-			if m, ok := e.Model.(FilesManager); ok && m.BaseFilesPath() != "" && supportFiles(e.Model) {
+			// note: the soft deleted records files are kept until the record is permanently deleted
+			if m, ok := e.Model.(FilesManager); ok && m.BaseFilesPath() != "" && supportFiles(e.Model) && !isSoftDeletedModel(e.Model) {
 				// ensure that there is a trailing slash so that the list iterator could start walking from the prefix dir
 				// (https://github.com/pocketbase/pocketbase/discussions/5246#discussioncomment-10128955)
 				prefix := strings.TrimRight(m.BaseFilesPath(), "/") + "/"
@@ -1380,8 +1472,20 @@ func (app *BaseApp) registerBaseHooks() {
 	app.registerRecordHooks()
 	app.registerSuperuserHooks()
 	app.registerExternalAuthHooks()
+	app.registerSoftDeleteHooks()
+	app.registerRevisionHooks()
+	app.registerWebhookHooks()
+	app.registerSigningKeysHooks()
 	app.registerMFAHooks()
 	app.registerOTPHooks()
+	app.registerWebAuthnCredentialHooks()
+	app.registerTOTPHooks()
+	app.registerAPIKeyHooks()
+	app.registerAuthLockoutHooks()
+	app.registerAuthStateHooks()
+	app.registerPasswordHistoryHooks()
+	app.registerUploadHooks()
+	app.registerOAuth2ClientHooks()
 	app.registerAuthOriginHooks()
 	app.registerNotifyWatcherHooks()
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/filescan"
 	"github.com/pocketbase/pocketbase/tools/logger"
 	"github.com/pocketbase/pocketbase/tools/mailer"
 )
@@ -207,6 +208,34 @@ func TestBaseAppNewMailClient(t *testing.T) {
 	}
 }
 
+func TestBaseAppNewFileScanner(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	app := core.NewBaseApp(core.BaseAppConfig{
+		DataDir: testDataDir,
+	})
+	defer app.ResetBootstrapState()
+
+	if scanner := app.NewFileScanner(); scanner != nil {
+		t.Fatalf("Expected nil scanner when disabled, got %v", scanner)
+	}
+
+	app.Settings().FileScan.Enabled = true
+	app.Settings().FileScan.Network = "unix"
+	app.Settings().FileScan.Address = "/tmp/clamd.sock"
+	app.Settings().FileScan.Timeout = 10
+
+	scanner, ok := app.NewFileScanner().(*filescan.Clamd)
+	if !ok {
+		t.Fatalf("Expected filescan.Clamd instance, got %v", scanner)
+	}
+
+	if scanner.Network != "unix" || scanner.Address != "/tmp/clamd.sock" || scanner.Timeout != 10*time.Second {
+		t.Fatalf("Expected the scanner to be initialized from the settings, got %#v", scanner)
+	}
+}
+
 func TestBaseAppNewFilesystem(t *testing.T) {
 	const testDataDir = "./pb_base_app_test_data_dir/"
 	defer os.RemoveAll(testDataDir)
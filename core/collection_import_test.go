@@ -168,7 +168,7 @@ func TestImportCollections(t *testing.T) {
 			expectCollectionsCount: totalCollections + 1,
 			afterTestFunc: func(testApp *tests.TestApp, resultCollections []*core.Collection) {
 				expectedCollectionFields := map[string]int{
-					core.CollectionNameAuthOrigins: 6,
+					core.CollectionNameAuthOrigins: 9,
 					"nologin":                      10,
 					"demo1":                        19,
 					"demo2":                        5,
@@ -3,6 +3,7 @@ package core
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -381,6 +382,7 @@ type baseCollection struct {
 // Collection defines the table, fields and various options related to a set of records.
 type Collection struct {
 	baseCollection
+	collectionBaseOptions
 	collectionAuthOptions
 	collectionViewOptions
 }
@@ -512,6 +514,8 @@ func (m *Collection) unmarshalRawOptions() error {
 		return json.Unmarshal(raw, &m.collectionViewOptions)
 	case CollectionTypeAuth:
 		return json.Unmarshal(raw, &m.collectionAuthOptions)
+	case CollectionTypeBase:
+		return json.Unmarshal(raw, &m.collectionBaseOptions)
 	}
 
 	return nil
@@ -568,6 +572,7 @@ func (m Collection) MarshalJSON() ([]byte, error) {
 		alias.PasswordResetToken.Secret = ""
 		alias.EmailChangeToken.Secret = ""
 		alias.VerificationToken.Secret = ""
+		alias.LDAP.BindPassword = ""
 
 		if alias.OAuth2.Providers == nil {
 			// ensure that it is always returned as array
@@ -584,7 +589,10 @@ func (m Collection) MarshalJSON() ([]byte, error) {
 
 		return json.Marshal(alias)
 	default:
-		return json.Marshal(m.baseCollection)
+		return json.Marshal(struct {
+			baseCollection
+			collectionBaseOptions
+		}{m.baseCollection, m.collectionBaseOptions})
 	}
 }
 
@@ -626,6 +634,12 @@ func (m *Collection) DBExport(app App) (map[string]any, error) {
 		} else {
 			return nil, err
 		}
+	case CollectionTypeBase:
+		if raw, err := types.ParseJSONRaw(m.collectionBaseOptions); err == nil {
+			result["options"] = raw
+		} else {
+			return nil, err
+		}
 	}
 
 	return result, nil
@@ -724,6 +738,10 @@ func onCollectionDeleteExecute(e *CollectionEvent) error {
 			if err := txApp.DeleteTable(e.Collection.Name); err != nil {
 				return err
 			}
+
+			if err := dropSearchFieldsTables(txApp, e.Collection); err != nil {
+				return err
+			}
 		}
 
 		if !e.Collection.disableIntegrityChecks {
@@ -840,9 +858,72 @@ func onCollectionSave(e *CollectionEvent) error {
 		e.Collection.Indexes[i] = normalized
 	}
 
+	if e.Collection.isSoftDeletable() {
+		e.Collection.excludeTrashedFromUniqueIndexes()
+	} else if !e.Collection.IsNew() {
+		// restore the original unique indexes after disabling the soft delete
+		// (the conflicting trashed records are reported by the validator)
+		oldCollection := &Collection{}
+		err := e.App.ModelQuery(oldCollection).Model(e.Collection.LastSavedPK(), oldCollection)
+		if err == nil && oldCollection.isSoftDeletable() {
+			e.Collection.restoreTrashedUniqueIndexes()
+		}
+	}
+
 	return e.Next()
 }
 
+var deletedColumnRegex = regexp.MustCompile("(?i)(^|[^\\w])" + FieldNameDeleted + "($|[^\\w])")
+
+// excludeTrashedFromUniqueIndexes converts the collection UNIQUE indexes
+// into partial ones so that the trashed records don't occupy their values.
+//
+// Indexes that already reference the "deleted" column are left as they are.
+func (c *Collection) excludeTrashedFromUniqueIndexes() {
+	for i, raw := range c.Indexes {
+		parsed := dbutils.ParseIndex(raw)
+		if !parsed.Unique || !parsed.IsValid() {
+			continue
+		}
+
+		if deletedColumnRegex.MatchString(parsed.Where) {
+			continue // already partial
+		}
+
+		exclude := "`" + FieldNameDeleted + "` = ''"
+		if parsed.Where == "" {
+			parsed.Where = exclude
+		} else {
+			parsed.Where = "(" + parsed.Where + ") AND " + exclude
+		}
+
+		c.Indexes[i] = parsed.Build()
+	}
+}
+
+// restoreTrashedUniqueIndexes reverts the partial
+// UNIQUE indexes created by [Collection.excludeTrashedFromUniqueIndexes].
+func (c *Collection) restoreTrashedUniqueIndexes() {
+	exclude := "`" + FieldNameDeleted + "` = ''"
+
+	for i, raw := range c.Indexes {
+		parsed := dbutils.ParseIndex(raw)
+		if !parsed.Unique || !parsed.IsValid() {
+			continue
+		}
+
+		if parsed.Where == exclude {
+			parsed.Where = ""
+		} else if strings.HasPrefix(parsed.Where, "(") && strings.HasSuffix(parsed.Where, ") AND "+exclude) {
+			parsed.Where = strings.TrimSuffix(strings.TrimPrefix(parsed.Where, "("), ") AND "+exclude)
+		} else {
+			continue
+		}
+
+		c.Indexes[i] = parsed.Build()
+	}
+}
+
 func onCollectionSaveExecute(e *CollectionEvent) error {
 	defer func() {
 		if err := e.App.ReloadCachedCollections(); err != nil {
@@ -941,6 +1022,7 @@ func (c *Collection) initDefaultFields() {
 	switch c.Type {
 	case CollectionTypeBase:
 		c.initIdField()
+		c.initDeletedField()
 	case CollectionTypeAuth:
 		c.initIdField()
 		c.initPasswordField()
@@ -982,6 +1064,29 @@ func (c *Collection) initIdField() {
 	}
 }
 
+func (c *Collection) initDeletedField() {
+	field := c.Fields.GetByName(FieldNameDeleted)
+	if field == nil {
+		if c.SoftDelete.Enabled {
+			// load default field
+			c.Fields.Add(&DateField{
+				Name:   FieldNameDeleted,
+				System: true,
+				Hidden: true,
+			})
+		}
+		return
+	}
+
+	// enforce system defaults
+	//
+	// note: the system flag is never toggled to avoid taking over a
+	// user defined field with the same name (leave to the validator to report it)
+	if dateField, ok := field.(*DateField); ok && dateField.System && c.SoftDelete.Enabled {
+		dateField.Hidden = true
+	}
+}
+
 func (c *Collection) initPasswordField() {
 	field, _ := c.Fields.GetByName(FieldNamePassword).(*PasswordField)
 	if field == nil {
@@ -1,6 +1,8 @@
 package core
 
 import (
+	"encoding/json"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -8,7 +10,9 @@ import (
 	validation "github.com/pocketbase/ozzo-validation/v4"
 	"github.com/pocketbase/ozzo-validation/v4/is"
 	"github.com/pocketbase/pocketbase/tools/auth"
+	"github.com/pocketbase/pocketbase/tools/ldap"
 	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/saml"
 	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/spf13/cast"
@@ -57,6 +61,12 @@ func (m *Collection) setDefaultAuthOptions() {
 		PasswordAuth: PasswordAuthConfig{
 			Enabled:        true,
 			IdentityFields: []string{FieldNameEmail},
+			Lockout: PasswordLockoutConfig{
+				Enabled:     false,
+				MaxFailures: 5,
+				Duration:    900, // 15min
+				Delay:       1,
+			},
 		},
 		MFA: MFAConfig{
 			Enabled:  false,
@@ -68,6 +78,22 @@ func (m *Collection) setDefaultAuthOptions() {
 			Length:        8,
 			EmailTemplate: defaultOTPTemplate,
 		},
+		WebAuthn: WebAuthnConfig{
+			Enabled:          false,
+			Duration:         300, // 5min
+			UserVerification: WebAuthnUserVerificationPreferred,
+		},
+		TOTP: TOTPConfig{
+			Enabled: false,
+		},
+		LDAP: LDAPConfig{
+			Enabled:         false,
+			UserFilter:      "(uid={username})",
+			GroupsAttribute: "memberOf",
+		},
+		SAML: SAMLConfig{
+			Enabled: false,
+		},
 		AuthToken: TokenConfig{
 			Secret:   security.RandomString(50),
 			Duration: 432000, // 5days
@@ -131,6 +157,18 @@ type collectionAuthOptions struct {
 	// OTP defines options related to the One-time password authentication (OTP).
 	OTP OTPConfig `form:"otp" json:"otp"`
 
+	// WebAuthn defines options related to the WebAuthn (passkeys) authentication.
+	WebAuthn WebAuthnConfig `form:"webauthn" json:"webauthn"`
+
+	// TOTP defines options related to the authenticator app (TOTP) second factor.
+	TOTP TOTPConfig `form:"totp" json:"totp"`
+
+	// LDAP defines options related to the LDAP (Active Directory) authentication.
+	LDAP LDAPConfig `form:"ldap" json:"ldap"`
+
+	// SAML defines options related to the SAML 2.0 single sign-on authentication.
+	SAML SAMLConfig `form:"saml" json:"saml"`
+
 	// Various token configurations
 	// ---
 	AuthToken          TokenConfig `form:"authToken" json:"authToken"`
@@ -163,6 +201,10 @@ func (o *collectionAuthOptions) validate(cv *collectionValidator) error {
 		validation.Field(&o.PasswordAuth),
 		validation.Field(&o.OAuth2),
 		validation.Field(&o.OTP),
+		validation.Field(&o.WebAuthn),
+		validation.Field(&o.TOTP),
+		validation.Field(&o.LDAP),
+		validation.Field(&o.SAML),
 		validation.Field(&o.MFA),
 		validation.Field(&o.AuthToken),
 		validation.Field(&o.PasswordResetToken),
@@ -192,6 +234,18 @@ func (o *collectionAuthOptions) validate(cv *collectionValidator) error {
 		if o.OTP.Enabled {
 			authsEnabled++
 		}
+		if o.WebAuthn.Enabled {
+			authsEnabled++
+		}
+		if o.TOTP.Enabled {
+			authsEnabled++
+		}
+		if o.LDAP.Enabled {
+			authsEnabled++
+		}
+		if o.SAML.Enabled {
+			authsEnabled++
+		}
 		if authsEnabled < 2 {
 			return validation.Errors{
 				"mfa": validation.Errors{
@@ -219,6 +273,22 @@ func (o *collectionAuthOptions) validate(cv *collectionValidator) error {
 		}
 	}
 
+	// extra check to ensure that the LDAP mapped fields exist
+	if o.LDAP.Enabled {
+		err = cv.checkLDAPFields(o.LDAP)
+		if err != nil {
+			return validation.Errors{"ldap": err}
+		}
+	}
+
+	// extra check to ensure that the SAML mapped fields exist
+	if o.SAML.Enabled {
+		err = cv.checkSAMLFields(o.SAML)
+		if err != nil {
+			return validation.Errors{"saml": err}
+		}
+	}
+
 	// extra check to ensure that only unique identity fields are used
 	if o.PasswordAuth.Enabled {
 		err = validation.Validate(o.PasswordAuth.IdentityFields, validation.By(cv.checkFieldsForUniqueIndex))
@@ -342,6 +412,351 @@ func (c OTPConfig) DurationTime() time.Duration {
 
 // -------------------------------------------------------------------
 
+// The allowed WebAuthnConfig.UserVerification values.
+const (
+	WebAuthnUserVerificationRequired    = "required"
+	WebAuthnUserVerificationPreferred   = "preferred"
+	WebAuthnUserVerificationDiscouraged = "discouraged"
+)
+
+type WebAuthnConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// Duration specifies how long the registration and
+	// authentication ceremonies challenge to be valid (in seconds).
+	Duration int64 `form:"duration" json:"duration"`
+
+	// RPId is the WebAuthn relying party id, aka. the domain
+	// the passkeys are bound to (e.g. "example.com").
+	//
+	// Leave it empty to use the hostname of the application URL setting.
+	RPId string `form:"rpId" json:"rpId"`
+
+	// RPName is the relying party name shown by the authenticators.
+	//
+	// Leave it empty to use the application name setting.
+	RPName string `form:"rpName" json:"rpName"`
+
+	// Origins is an optional list of the allowed client origins
+	// (e.g. "https://example.com" or "android:apk-key-hash:...").
+	//
+	// Leave it empty to allow only the origin of the application URL setting.
+	Origins []string `form:"origins" json:"origins"`
+
+	// UserVerification specifies the authenticator user verification
+	// requirement ("required", "preferred" or "discouraged").
+	//
+	// Only "required" is enforced on the server side.
+	UserVerification string `form:"userVerification" json:"userVerification"`
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (c WebAuthnConfig) MarshalJSON() ([]byte, error) {
+	type alias WebAuthnConfig
+
+	// serialize as empty array
+	if c.Origins == nil {
+		c.Origins = []string{}
+	}
+
+	return json.Marshal(alias(c))
+}
+
+// Validate makes WebAuthnConfig validatable by implementing [validation.Validatable] interface.
+func (c WebAuthnConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Duration, validation.When(c.Enabled, validation.Required, validation.Min(10), validation.Max(86400))),
+		validation.Field(&c.RPId, validation.Length(0, 255), is.Host),
+		validation.Field(&c.RPName, validation.Length(0, 255)),
+		validation.Field(&c.Origins, validation.Each(validation.Required, validation.Length(1, 2000))),
+		validation.Field(&c.UserVerification, validation.When(
+			c.Enabled,
+			validation.Required,
+			validation.In(
+				WebAuthnUserVerificationRequired,
+				WebAuthnUserVerificationPreferred,
+				WebAuthnUserVerificationDiscouraged,
+			),
+		)),
+	)
+}
+
+// DurationTime returns the current Duration as [time.Duration].
+func (c WebAuthnConfig) DurationTime() time.Duration {
+	return time.Duration(c.Duration) * time.Second
+}
+
+// -------------------------------------------------------------------
+
+type TOTPConfig struct {
+	// Enabled specifies whether the auth records are allowed to enroll
+	// an authenticator app and to use it as MFA factor.
+	//
+	// Note that TOTP can be used only as a second factor, aka. it
+	// requires MFA to be also enabled.
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// Issuer is the optional issuer label shown by the authenticator apps.
+	//
+	// Leave it empty to use the application name setting.
+	Issuer string `form:"issuer" json:"issuer"`
+}
+
+// Validate makes TOTPConfig validatable by implementing [validation.Validatable] interface.
+func (c TOTPConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Issuer, validation.Length(0, 255)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// LDAPUsernamePlaceholder is the [LDAPConfig.UserFilter] placeholder
+// that is replaced with the escaped submitted username.
+const LDAPUsernamePlaceholder = "{username}"
+
+type LDAPConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// URL is the LDAP server url
+	// (e.g. "ldap://ldap.example.com:389" or "ldaps://ldap.example.com:636").
+	URL string `form:"url" json:"url"`
+
+	// StartTLS specifies whether to upgrade the plain "ldap://"
+	// connection to TLS with the StartTLS operation.
+	StartTLS bool `form:"startTLS" json:"startTLS"`
+
+	// TLSSkipVerify disables the LDAP server certificate verification.
+	//
+	// It is intended to be used only with self-signed certificates in trusted networks.
+	TLSSkipVerify bool `form:"tlsSkipVerify" json:"tlsSkipVerify"`
+
+	// BindDN and BindPassword are the optional credentials of the
+	// service account used for searching the user entries.
+	//
+	// Leave them empty to search anonymously.
+	BindDN       string `form:"bindDN" json:"bindDN"`
+	BindPassword string `form:"bindPassword" json:"bindPassword,omitempty"`
+
+	// BaseDN is the DN relative to which the user entries are searched
+	// (e.g. "ou=people,dc=example,dc=com").
+	BaseDN string `form:"baseDN" json:"baseDN"`
+
+	// UserFilter is the search filter used to find the user entry
+	// where the {username} placeholder is replaced with the submitted username
+	// (e.g. "(uid={username})" or "(&(objectClass=user)(sAMAccountName={username}))").
+	UserFilter string `form:"userFilter" json:"userFilter"`
+
+	// MappedFields maps the auth record field names to the
+	// user entry attributes (e.g. {"email": "mail", "name": "cn"}).
+	//
+	// The mapped fields are assigned on the auth record creation
+	// and synced on each login (except the email that is updated only if empty).
+	MappedFields map[string]string `form:"mappedFields" json:"mappedFields"`
+
+	// GroupsAttribute is the user entry attribute with the DNs of the user groups.
+	GroupsAttribute string `form:"groupsAttribute" json:"groupsAttribute"`
+
+	// GroupMappings assigns auth record field values based on the user groups.
+	//
+	// The mapped fields are synced on each login and for multiple values fields
+	// (e.g. multi-select) all matching group values are assigned, otherwise - the first one.
+	// If the user is not a member of any of the field groups, the field is cleared.
+	GroupMappings []LDAPGroupMapping `form:"groupMappings" json:"groupMappings"`
+}
+
+type LDAPGroupMapping struct {
+	// Group is the group DN (case-insensitive).
+	Group string `form:"group" json:"group"`
+
+	// Field is the name of the auth record field to assign.
+	Field string `form:"field" json:"field"`
+
+	// Value is the field value to assign (e.g. "true" for bool fields).
+	Value string `form:"value" json:"value"`
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (c LDAPConfig) MarshalJSON() ([]byte, error) {
+	type alias LDAPConfig
+
+	// serialize as empty object and array
+	if c.MappedFields == nil {
+		c.MappedFields = map[string]string{}
+	}
+	if c.GroupMappings == nil {
+		c.GroupMappings = []LDAPGroupMapping{}
+	}
+
+	return json.Marshal(alias(c))
+}
+
+// Validate makes LDAPConfig validatable by implementing [validation.Validatable] interface.
+func (c LDAPConfig) Validate() error {
+	if !c.Enabled {
+		return nil // no need to validate
+	}
+
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.URL, validation.Required, validation.Length(0, 2000), validation.By(checkLDAPURL)),
+		validation.Field(&c.StartTLS, validation.When(c.StartTLS && !strings.HasPrefix(c.URL, "ldap://"), validation.Empty.Error("StartTLS is supported only with ldap:// urls."))),
+		validation.Field(&c.BindDN, validation.Length(0, 2000)),
+		validation.Field(&c.BindPassword, validation.When(c.BindDN != "", validation.Required)),
+		validation.Field(&c.BaseDN, validation.Required, validation.Length(0, 2000)),
+		validation.Field(&c.UserFilter, validation.Required, validation.Length(0, 2000), validation.By(checkLDAPUserFilter)),
+		validation.Field(&c.MappedFields, validation.Each(validation.Required, validation.Length(1, 255))),
+		validation.Field(&c.GroupsAttribute, validation.When(len(c.GroupMappings) > 0, validation.Required), validation.Length(0, 255)),
+		validation.Field(&c.GroupMappings),
+	)
+}
+
+// Attributes returns the unique list of the user entry attributes
+// that are required for the fields mapping.
+func (c LDAPConfig) Attributes() []string {
+	attributes := make([]string, 0, len(c.MappedFields)+1)
+
+	for _, attr := range c.MappedFields {
+		attributes = append(attributes, attr)
+	}
+
+	if len(c.GroupMappings) > 0 {
+		attributes = append(attributes, c.GroupsAttribute)
+	}
+
+	return list.ToUniqueStringSlice(attributes)
+}
+
+// Validate makes LDAPGroupMapping validatable by implementing [validation.Validatable] interface.
+func (m LDAPGroupMapping) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Group, validation.Required, validation.Length(1, 2000)),
+		validation.Field(&m.Field, validation.Required, validation.Length(1, 255)),
+		validation.Field(&m.Value, validation.Length(0, 255)),
+	)
+}
+
+func checkLDAPURL(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	u, err := url.Parse(v)
+	if err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Hostname() == "" {
+		return validation.NewError("validation_invalid_ldap_url", "Must be a valid ldap:// or ldaps:// url.")
+	}
+
+	return nil
+}
+
+func checkLDAPUserFilter(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if !strings.Contains(v, LDAPUsernamePlaceholder) {
+		return validation.NewError("validation_missing_ldap_username_placeholder", "The filter must contain the {username} placeholder.")
+	}
+
+	if _, err := ldap.CompileFilter(strings.ReplaceAll(v, LDAPUsernamePlaceholder, "test")); err != nil {
+		return validation.NewError("validation_invalid_ldap_filter", "Invalid LDAP search filter.")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type SAMLConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// IdPEntityId is the identity provider entity id
+	// (the expected issuer of the SAML responses and assertions).
+	IdPEntityId string `form:"idpEntityId" json:"idpEntityId"`
+
+	// IdPSSOURL is the identity provider single sign-on url (HTTP-Redirect binding).
+	IdPSSOURL string `form:"idpSSOURL" json:"idpSSOURL"`
+
+	// IdPCertificate is the PEM encoded identity provider signing certificate.
+	//
+	// Multiple certificates could be specified to allow keys rotation.
+	IdPCertificate string `form:"idpCertificate" json:"idpCertificate"`
+
+	// SPEntityId is the optional service provider entity id
+	// (default to the collection SAML metadata url).
+	SPEntityId string `form:"spEntityId" json:"spEntityId"`
+
+	// NameIdFormat is the optional requested subject NameID format
+	// (e.g. "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress").
+	NameIdFormat string `form:"nameIdFormat" json:"nameIdFormat"`
+
+	// AllowIdPInitiated specifies whether to accept unsolicited
+	// (IdP-initiated) SAML responses.
+	//
+	// The user is redirected to the first of the RedirectURLs after
+	// an IdP-initiated login.
+	AllowIdPInitiated bool `form:"allowIdPInitiated" json:"allowIdPInitiated"`
+
+	// RedirectURLs is the list of the allowed client urls where the user
+	// is redirected after the SAML response processing.
+	RedirectURLs []string `form:"redirectURLs" json:"redirectURLs"`
+
+	// MappedFields maps the auth record field names to the
+	// SAML assertion attribute names (e.g. {"name": "displayName"}).
+	//
+	// The mapped fields are assigned on the auth record creation
+	// and synced on each login (except the email that is updated only if empty).
+	MappedFields map[string]string `form:"mappedFields" json:"mappedFields"`
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (c SAMLConfig) MarshalJSON() ([]byte, error) {
+	type alias SAMLConfig
+
+	// serialize as empty object and array
+	if c.RedirectURLs == nil {
+		c.RedirectURLs = []string{}
+	}
+	if c.MappedFields == nil {
+		c.MappedFields = map[string]string{}
+	}
+
+	return json.Marshal(alias(c))
+}
+
+// Validate makes SAMLConfig validatable by implementing [validation.Validatable] interface.
+func (c SAMLConfig) Validate() error {
+	if !c.Enabled {
+		return nil // no need to validate
+	}
+
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.IdPEntityId, validation.Required, validation.Length(0, 2000)),
+		validation.Field(&c.IdPSSOURL, validation.Required, validation.Length(0, 2000), is.URL),
+		validation.Field(&c.IdPCertificate, validation.Required, validation.By(checkSAMLCertificate)),
+		validation.Field(&c.SPEntityId, validation.Length(0, 2000)),
+		validation.Field(&c.NameIdFormat, validation.Length(0, 255)),
+		validation.Field(&c.RedirectURLs, validation.Required, validation.Each(validation.By(validateOAuth2RedirectURI))),
+		validation.Field(&c.MappedFields, validation.Each(validation.Required, validation.Length(1, 255))),
+	)
+}
+
+func checkSAMLCertificate(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if _, err := saml.ParseCertificates(v); err != nil {
+		return validation.NewError("validation_invalid_saml_certificate", "Must be a valid PEM encoded X.509 certificate.")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
 type MFAConfig struct {
 	Enabled bool `form:"enabled" json:"enabled"`
 
@@ -376,6 +791,10 @@ type PasswordAuthConfig struct {
 	//
 	// Usually only fields that has single column UNIQUE index are accepted as values.
 	IdentityFields []string `form:"identityFields" json:"identityFields"`
+
+	// Lockout defines the brute-force protection options
+	// for the failed password authentication attempts.
+	Lockout PasswordLockoutConfig `form:"lockout" json:"lockout"`
 }
 
 // Validate makes PasswordAuthConfig validatable by implementing [validation.Validatable] interface.
@@ -389,9 +808,73 @@ func (c PasswordAuthConfig) Validate() error {
 
 	return validation.ValidateStruct(&c,
 		validation.Field(&c.IdentityFields, validation.Required),
+		validation.Field(&c.Lockout),
+	)
+}
+
+// PasswordLockoutConfig defines the per-identity failed password attempts tracking options.
+//
+// Unlike the IP based rate limits, the failed attempts are tracked per
+// auth record (or per submitted identity if no record was found) and
+// therefore they also cover distributed credential stuffing attacks.
+type PasswordLockoutConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// MaxFailures specifies the number of consecutive failed attempts
+	// after which the identity is locked.
+	MaxFailures int `form:"maxFailures" json:"maxFailures"`
+
+	// Duration specifies how long the identity remains locked (in seconds).
+	Duration int64 `form:"duration" json:"duration"`
+
+	// Delay specifies the base progressive delay (in seconds) that must
+	// elapse after a failed attempt before the identity could be tried again.
+	//
+	// The delay is doubled on each consecutive failure (capped to Duration).
+	//
+	// Set it to 0 to disable the progressive delay.
+	Delay int64 `form:"delay" json:"delay"`
+
+	// Alert specifies whether to notify the auth record by email
+	// (using the AuthAlert template) when its account gets locked.
+	Alert bool `form:"alert" json:"alert"`
+}
+
+// Validate makes PasswordLockoutConfig validatable by implementing [validation.Validatable] interface.
+func (c PasswordLockoutConfig) Validate() error {
+	if !c.Enabled {
+		return nil // no need to validate
+	}
+
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxFailures, validation.Required, validation.Min(1), validation.Max(1000)),
+		validation.Field(&c.Duration, validation.Required, validation.Min(1), validation.Max(604800)),
+		validation.Field(&c.Delay, validation.Min(0), validation.Max(3600)),
 	)
 }
 
+// DurationTime returns the current Duration as [time.Duration].
+func (c PasswordLockoutConfig) DurationTime() time.Duration {
+	return time.Duration(c.Duration) * time.Second
+}
+
+// DelayTime returns the progressive delay that must elapse after
+// the specified number of consecutive failures.
+func (c PasswordLockoutConfig) DelayTime(failures int) time.Duration {
+	if c.Delay <= 0 || failures <= 0 {
+		return 0
+	}
+
+	maxDelay := c.DurationTime()
+
+	delay := time.Duration(c.Delay) * time.Second
+	for i := 1; i < failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxDelay)
+}
+
 // -------------------------------------------------------------------
 
 type OAuth2KnownFields struct {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -17,6 +18,11 @@ import (
 func TestCollectionAuthOptionsValidate(t *testing.T) {
 	t.Parallel()
 
+	idp, err := tests.NewTestSAMLIdP("https://idp.example.com", "https://idp.example.com/sso")
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	scenarios := []struct {
 		name           string
 		collection     func(app core.App) (*core.Collection, error)
@@ -168,6 +174,154 @@ func TestCollectionAuthOptionsValidate(t *testing.T) {
 			expectedErrors: []string{"otp"},
 		},
 
+		// webauthn
+		{
+			name: "trigger webauthn validations",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.WebAuthn = core.WebAuthnConfig{
+					Enabled:  true,
+					Duration: -10,
+				}
+				return c, nil
+			},
+			expectedErrors: []string{"webauthn"},
+		},
+
+		// totp
+		{
+			name: "trigger totp validations",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.TOTP = core.TOTPConfig{
+					Enabled: true,
+					Issuer:  strings.Repeat("a", 256),
+				}
+				return c, nil
+			},
+			expectedErrors: []string{"totp"},
+		},
+
+		// ldap
+		{
+			name: "trigger ldap validations",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.LDAP = core.LDAPConfig{
+					Enabled: true,
+				}
+				return c, nil
+			},
+			expectedErrors: []string{"ldap"},
+		},
+		{
+			name: "ldap with missing mapped field",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.LDAP.Enabled = true
+				c.LDAP.URL = "ldap://example.com"
+				c.LDAP.BaseDN = "dc=example,dc=com"
+				c.LDAP.MappedFields = map[string]string{"email": "mail", "missing": "cn"}
+				return c, nil
+			},
+			expectedErrors: []string{"ldap"},
+		},
+		{
+			name: "ldap with disallowed mapped field",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.LDAP.Enabled = true
+				c.LDAP.URL = "ldap://example.com"
+				c.LDAP.BaseDN = "dc=example,dc=com"
+				c.LDAP.MappedFields = map[string]string{"password": "userPassword"}
+				return c, nil
+			},
+			expectedErrors: []string{"ldap"},
+		},
+		{
+			name: "ldap with missing group mapping field",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.LDAP.Enabled = true
+				c.LDAP.URL = "ldap://example.com"
+				c.LDAP.BaseDN = "dc=example,dc=com"
+				c.LDAP.GroupMappings = []core.LDAPGroupMapping{{Group: "cn=admins", Field: "missing", Value: "true"}}
+				return c, nil
+			},
+			expectedErrors: []string{"ldap"},
+		},
+		{
+			name: "ldap with existing mapped and group fields",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.Fields.Add(&core.TextField{Name: "name"})
+				c.Fields.Add(&core.BoolField{Name: "admin"})
+				c.LDAP.Enabled = true
+				c.LDAP.URL = "ldap://example.com"
+				c.LDAP.BaseDN = "dc=example,dc=com"
+				c.LDAP.MappedFields = map[string]string{"email": "mail", "name": "cn"}
+				c.LDAP.GroupMappings = []core.LDAPGroupMapping{{Group: "cn=admins", Field: "admin", Value: "true"}}
+				return c, nil
+			},
+			expectedErrors: []string{},
+		},
+
+		// saml
+		{
+			name: "trigger saml validations",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.SAML = core.SAMLConfig{
+					Enabled: true,
+				}
+				return c, nil
+			},
+			expectedErrors: []string{"saml"},
+		},
+		{
+			name: "saml with missing mapped field",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.SAML.Enabled = true
+				c.SAML.IdPEntityId = idp.EntityId
+				c.SAML.IdPSSOURL = idp.SSOURL
+				c.SAML.IdPCertificate = idp.CertificatePEM
+				c.SAML.RedirectURLs = []string{"https://example.com/callback"}
+				c.SAML.MappedFields = map[string]string{"email": "mail", "missing": "displayName"}
+				return c, nil
+			},
+			expectedErrors: []string{"saml"},
+		},
+		{
+			name: "saml with disallowed mapped field",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.SAML.Enabled = true
+				c.SAML.IdPEntityId = idp.EntityId
+				c.SAML.IdPSSOURL = idp.SSOURL
+				c.SAML.IdPCertificate = idp.CertificatePEM
+				c.SAML.RedirectURLs = []string{"https://example.com/callback"}
+				c.SAML.MappedFields = map[string]string{"tokenKey": "uid"}
+				return c, nil
+			},
+			expectedErrors: []string{"saml"},
+		},
+		{
+			name: "saml with existing mapped fields",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.Fields.Add(&core.TextField{Name: "name"})
+				c.SAML.Enabled = true
+				c.SAML.IdPEntityId = idp.EntityId
+				c.SAML.IdPSSOURL = idp.SSOURL
+				c.SAML.IdPCertificate = idp.CertificatePEM
+				c.SAML.RedirectURLs = []string{"https://example.com/callback"}
+				c.SAML.MappedFields = map[string]string{"email": "mail", "name": "displayName"}
+				return c, nil
+			},
+			expectedErrors: []string{},
+		},
+
 		// mfa
 		{
 			name: "trigger mfa validations",
@@ -205,6 +359,47 @@ func TestCollectionAuthOptionsValidate(t *testing.T) {
 			},
 			expectedErrors: []string{},
 		},
+		{
+			name: "mfa enabled with password and webauthn auth methods",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.MFA.Enabled = true
+				c.PasswordAuth.Enabled = true
+				c.OTP.Enabled = false
+				c.OAuth2.Enabled = false
+				c.WebAuthn.Enabled = true
+				return c, nil
+			},
+			expectedErrors: []string{},
+		},
+		{
+			name: "mfa enabled with password and totp auth methods",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.MFA.Enabled = true
+				c.PasswordAuth.Enabled = true
+				c.OTP.Enabled = false
+				c.OAuth2.Enabled = false
+				c.TOTP.Enabled = true
+				return c, nil
+			},
+			expectedErrors: []string{},
+		},
+		{
+			name: "mfa enabled with password and ldap auth methods",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewAuthCollection("new_auth")
+				c.MFA.Enabled = true
+				c.PasswordAuth.Enabled = true
+				c.OTP.Enabled = false
+				c.OAuth2.Enabled = false
+				c.LDAP.Enabled = true
+				c.LDAP.URL = "ldap://example.com"
+				c.LDAP.BaseDN = "dc=example,dc=com"
+				return c, nil
+			},
+			expectedErrors: []string{},
+		},
 		{
 			name: "mfa disabled with invalid rule",
 			collection: func(app core.App) (*core.Collection, error) {
@@ -613,6 +808,326 @@ func TestOTPConfigDurationTime(t *testing.T) {
 	}
 }
 
+func TestWebAuthnConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.WebAuthnConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.WebAuthnConfig{},
+			[]string{},
+		},
+		{
+			"zero value (enabled)",
+			core.WebAuthnConfig{Enabled: true},
+			[]string{"duration", "userVerification"},
+		},
+		{
+			"invalid duration (< 10)",
+			core.WebAuthnConfig{Enabled: true, Duration: 9, UserVerification: "preferred"},
+			[]string{"duration"},
+		},
+		{
+			"invalid duration (> 86400)",
+			core.WebAuthnConfig{Enabled: true, Duration: 86401, UserVerification: "preferred"},
+			[]string{"duration"},
+		},
+		{
+			"invalid userVerification",
+			core.WebAuthnConfig{Enabled: true, Duration: 100, UserVerification: "invalid"},
+			[]string{"userVerification"},
+		},
+		{
+			"invalid rpId and origins",
+			core.WebAuthnConfig{
+				Enabled:          true,
+				Duration:         100,
+				UserVerification: "required",
+				RPId:             "https://example.com",
+				Origins:          []string{""},
+			},
+			[]string{"rpId", "origins"},
+		},
+		{
+			"invalid rpName",
+			core.WebAuthnConfig{RPName: strings.Repeat("a", 256)},
+			[]string{"rpName"},
+		},
+		{
+			"valid data",
+			core.WebAuthnConfig{
+				Enabled:          true,
+				Duration:         86400,
+				UserVerification: "discouraged",
+				RPId:             "example.com",
+				RPName:           "Example",
+				Origins:          []string{"https://example.com", "android:apk-key-hash:abc"},
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestWebAuthnConfigDurationTime(t *testing.T) {
+	scenarios := []struct {
+		config   core.WebAuthnConfig
+		expected time.Duration
+	}{
+		{core.WebAuthnConfig{}, 0 * time.Second},
+		{core.WebAuthnConfig{Duration: 1234}, 1234 * time.Second},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%d", i, s.config.Duration), func(t *testing.T) {
+			result := s.config.DurationTime()
+
+			if result != s.expected {
+				t.Fatalf("Expected duration %d, got %d", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestTOTPConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.TOTPConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.TOTPConfig{},
+			[]string{},
+		},
+		{
+			"zero value (enabled)",
+			core.TOTPConfig{Enabled: true},
+			[]string{},
+		},
+		{
+			"invalid issuer",
+			core.TOTPConfig{Enabled: true, Issuer: strings.Repeat("a", 256)},
+			[]string{"issuer"},
+		},
+		{
+			"valid data",
+			core.TOTPConfig{Enabled: true, Issuer: strings.Repeat("a", 255)},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestLDAPConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.LDAPConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.LDAPConfig{},
+			[]string{},
+		},
+		{
+			"zero value (enabled)",
+			core.LDAPConfig{Enabled: true},
+			[]string{"url", "baseDN", "userFilter"},
+		},
+		{
+			"invalid url scheme",
+			core.LDAPConfig{
+				Enabled:    true,
+				URL:        "http://example.com",
+				BaseDN:     "dc=example,dc=com",
+				UserFilter: "(uid={username})",
+			},
+			[]string{"url"},
+		},
+		{
+			"startTLS with ldaps url",
+			core.LDAPConfig{
+				Enabled:    true,
+				URL:        "ldaps://example.com",
+				StartTLS:   true,
+				BaseDN:     "dc=example,dc=com",
+				UserFilter: "(uid={username})",
+			},
+			[]string{"startTLS"},
+		},
+		{
+			"bindDN without bindPassword",
+			core.LDAPConfig{
+				Enabled:    true,
+				URL:        "ldap://example.com",
+				BindDN:     "cn=admin,dc=example,dc=com",
+				BaseDN:     "dc=example,dc=com",
+				UserFilter: "(uid={username})",
+			},
+			[]string{"bindPassword"},
+		},
+		{
+			"userFilter without placeholder",
+			core.LDAPConfig{
+				Enabled:    true,
+				URL:        "ldap://example.com",
+				BaseDN:     "dc=example,dc=com",
+				UserFilter: "(uid=john)",
+			},
+			[]string{"userFilter"},
+		},
+		{
+			"invalid userFilter",
+			core.LDAPConfig{
+				Enabled:    true,
+				URL:        "ldap://example.com",
+				BaseDN:     "dc=example,dc=com",
+				UserFilter: "(&(uid={username})",
+			},
+			[]string{"userFilter"},
+		},
+		{
+			"group mappings with empty groupsAttribute and invalid mapping",
+			core.LDAPConfig{
+				Enabled:       true,
+				URL:           "ldap://example.com",
+				BaseDN:        "dc=example,dc=com",
+				UserFilter:    "(uid={username})",
+				GroupMappings: []core.LDAPGroupMapping{{Field: "admin"}},
+			},
+			[]string{"groupsAttribute", "groupMappings"},
+		},
+		{
+			"valid data",
+			core.LDAPConfig{
+				Enabled:         true,
+				URL:             "ldap://example.com:389",
+				StartTLS:        true,
+				BindDN:          "cn=admin,dc=example,dc=com",
+				BindPassword:    "123456",
+				BaseDN:          "dc=example,dc=com",
+				UserFilter:      "(&(objectClass=person)(uid={username}))",
+				MappedFields:    map[string]string{"email": "mail"},
+				GroupsAttribute: "memberOf",
+				GroupMappings:   []core.LDAPGroupMapping{{Group: "cn=admins,dc=example,dc=com", Field: "admin", Value: "true"}},
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestLDAPConfigAttributes(t *testing.T) {
+	config := core.LDAPConfig{
+		MappedFields:    map[string]string{"email": "mail", "name": "cn", "username": "cn"},
+		GroupsAttribute: "memberOf",
+	}
+
+	result := config.Attributes()
+	slices.Sort(result)
+
+	expected := []string{"cn", "mail"}
+	if !slices.Equal(result, expected) {
+		t.Fatalf("Expected attributes %v, got %v", expected, result)
+	}
+
+	// with group mappings
+	config.GroupMappings = []core.LDAPGroupMapping{{Group: "cn=admins", Field: "admin"}}
+
+	result = config.Attributes()
+	slices.Sort(result)
+
+	expected = []string{"cn", "mail", "memberOf"}
+	if !slices.Equal(result, expected) {
+		t.Fatalf("Expected attributes %v, got %v", expected, result)
+	}
+}
+
+func TestSAMLConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	idp, err := tests.NewTestSAMLIdP("https://idp.example.com", "https://idp.example.com/sso")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name           string
+		config         core.SAMLConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.SAMLConfig{},
+			[]string{},
+		},
+		{
+			"zero value (enabled)",
+			core.SAMLConfig{Enabled: true},
+			[]string{"idpEntityId", "idpSSOURL", "idpCertificate", "redirectURLs"},
+		},
+		{
+			"invalid data",
+			core.SAMLConfig{
+				Enabled:        true,
+				IdPEntityId:    idp.EntityId,
+				IdPSSOURL:      "invalid",
+				IdPCertificate: "invalid",
+				RedirectURLs:   []string{"/relative"},
+				MappedFields:   map[string]string{"name": ""},
+			},
+			[]string{"idpSSOURL", "idpCertificate", "redirectURLs", "mappedFields"},
+		},
+		{
+			"valid data",
+			core.SAMLConfig{
+				Enabled:           true,
+				IdPEntityId:       idp.EntityId,
+				IdPSSOURL:         idp.SSOURL,
+				IdPCertificate:    idp.CertificatePEM,
+				SPEntityId:        "https://example.com",
+				NameIdFormat:      "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress",
+				AllowIdPInitiated: true,
+				RedirectURLs:      []string{"https://example.com/callback", "myapp://callback"},
+				MappedFields:      map[string]string{"name": "displayName"},
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
 func TestMFAConfigValidate(t *testing.T) {
 	scenarios := []struct {
 		name           string
@@ -696,6 +1211,11 @@ func TestPasswordAuthConfigValidate(t *testing.T) {
 			core.PasswordAuthConfig{Enabled: true, IdentityFields: []string{"", ""}},
 			[]string{"identityFields"},
 		},
+		{
+			"invalid lockout",
+			core.PasswordAuthConfig{Enabled: true, IdentityFields: []string{"abc"}, Lockout: core.PasswordLockoutConfig{Enabled: true}},
+			[]string{"lockout"},
+		},
 		{
 			"valid data",
 			core.PasswordAuthConfig{Enabled: true, IdentityFields: []string{"abc"}},
@@ -712,6 +1232,96 @@ func TestPasswordAuthConfigValidate(t *testing.T) {
 	}
 }
 
+func TestPasswordLockoutConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.PasswordLockoutConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.PasswordLockoutConfig{},
+			[]string{},
+		},
+		{
+			"zero value (enabled)",
+			core.PasswordLockoutConfig{Enabled: true},
+			[]string{"maxFailures", "duration"},
+		},
+		{
+			"invalid values (< min)",
+			core.PasswordLockoutConfig{Enabled: true, MaxFailures: -1, Duration: -1, Delay: -1},
+			[]string{"maxFailures", "duration", "delay"},
+		},
+		{
+			"invalid values (> max)",
+			core.PasswordLockoutConfig{Enabled: true, MaxFailures: 1001, Duration: 604801, Delay: 3601},
+			[]string{"maxFailures", "duration", "delay"},
+		},
+		{
+			"valid data",
+			core.PasswordLockoutConfig{Enabled: true, MaxFailures: 1000, Duration: 604800, Delay: 3600},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestPasswordLockoutConfigDurationTime(t *testing.T) {
+	scenarios := []struct {
+		config   core.PasswordLockoutConfig
+		expected time.Duration
+	}{
+		{core.PasswordLockoutConfig{}, 0 * time.Second},
+		{core.PasswordLockoutConfig{Duration: 1234}, 1234 * time.Second},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%d", i, s.config.Duration), func(t *testing.T) {
+			result := s.config.DurationTime()
+
+			if result != s.expected {
+				t.Fatalf("Expected duration %d, got %d", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestPasswordLockoutConfigDelayTime(t *testing.T) {
+	scenarios := []struct {
+		config   core.PasswordLockoutConfig
+		failures int
+		expected time.Duration
+	}{
+		{core.PasswordLockoutConfig{Duration: 100}, 3, 0},
+		{core.PasswordLockoutConfig{Duration: 100, Delay: 2}, 0, 0},
+		{core.PasswordLockoutConfig{Duration: 100, Delay: 2}, 1, 2 * time.Second},
+		{core.PasswordLockoutConfig{Duration: 100, Delay: 2}, 2, 4 * time.Second},
+		{core.PasswordLockoutConfig{Duration: 100, Delay: 2}, 5, 32 * time.Second},
+		{core.PasswordLockoutConfig{Duration: 100, Delay: 2}, 6, 64 * time.Second},
+		{core.PasswordLockoutConfig{Duration: 100, Delay: 2}, 7, 100 * time.Second}, // capped
+		{core.PasswordLockoutConfig{Duration: 100, Delay: 2}, 1000, 100 * time.Second},
+		{core.PasswordLockoutConfig{Duration: 1, Delay: 2}, 1, 1 * time.Second},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%d_%d", i, s.config.Delay, s.failures), func(t *testing.T) {
+			result := s.config.DelayTime(s.failures)
+
+			if result != s.expected {
+				t.Fatalf("Expected delay %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
 func TestOAuth2ConfigGetProviderConfig(t *testing.T) {
 	scenarios := []struct {
 		name           string
@@ -1,11 +1,145 @@
 package core
 
+import (
+	"strings"
+	"time"
+
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+)
+
 var _ optionsValidator = (*collectionBaseOptions)(nil)
 
 // collectionBaseOptions defines the options for the "base" type collection.
 type collectionBaseOptions struct {
+	// SoftDelete specifies the collection records soft delete (aka. trash) behavior.
+	SoftDelete SoftDeleteConfig `form:"softDelete" json:"softDelete"`
+
+	// History specifies whether to keep a revisions history
+	// (aka. audit trail) of the collection records changes.
+	History HistoryConfig `form:"history" json:"history"`
 }
 
 func (o *collectionBaseOptions) validate(cv *collectionValidator) error {
+	return validation.ValidateStruct(o,
+		validation.Field(&o.SoftDelete, validation.By(cv.checkSoftDeleteField)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// SoftDeleteConfig defines the collection records soft delete options.
+//
+// When enabled, deleting a record only marks it as deleted by setting
+// its system "deleted" field and hides it from the [App.RecordQuery] results.
+// The trashed records could be restored with [App.RestoreRecord] and
+// they are permanently deleted when deleted again or after MaxDays.
+//
+// Disabling the option makes the trashed records visible again and restores
+// the original UNIQUE indexes (the collection validation fails if they conflict).
+type SoftDeleteConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// MaxDays specifies how many days to keep the trashed records
+	// before permanently deleting them.
+	//
+	// Set to zero to disable the automatic purge.
+	MaxDays int `form:"maxDays" json:"maxDays"`
+}
+
+// Validate makes SoftDeleteConfig validatable by implementing [validation.Validatable] interface.
+func (c SoftDeleteConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxDays, validation.Min(0)),
+	)
+}
+
+// MaxDaysTime returns the current MaxDays as [time.Duration].
+func (c SoftDeleteConfig) MaxDaysTime() time.Duration {
+	return time.Duration(c.MaxDays) * 24 * time.Hour
+}
+
+// -------------------------------------------------------------------
+
+// HistoryConfig defines the collection records revisions history options.
+//
+// When enabled, every record create, update and delete stores a new
+// [Revision] with the changed field values and the acting auth record
+// in the auxiliary database. The revision is written as part of the record
+// db write, aka. if it fails to be stored the record operation fails too.
+//
+// The history is available only for the "base" collections
+// (the auth records changes are not tracked).
+//
+// Note that the changes are based on the record fields against [Record.Original]
+// (aka. if you have performed save on the same Record instance multiple times
+// you may have to refetch it, so that the revision could reflect only the last change).
+type HistoryConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+}
+
+func (cv *collectionValidator) checkSoftDeleteField(value any) error {
+	v, _ := value.(SoftDeleteConfig)
+	if !v.Enabled {
+		if !cv.original.IsNew() && cv.original.isSoftDeletable() {
+			return cv.checkTrashedUniqueConflicts()
+		}
+		return nil
+	}
+
+	if field, ok := cv.new.Fields.GetByName(FieldNameDeleted).(*DateField); !ok || !field.System {
+		return validation.NewError(
+			"validation_soft_delete_reserved_field",
+			`Soft delete requires the "{{.fieldName}}" field name to be reserved for the system date field. Rename or remove your existing "{{.fieldName}}" field.`,
+		).SetParams(map[string]any{"fieldName": FieldNameDeleted})
+	}
+
+	return nil
+}
+
+// checkTrashedUniqueConflicts ensures that the restored UNIQUE indexes
+// after disabling the soft delete don't conflict with the existing
+// (including the trashed) records.
+func (cv *collectionValidator) checkTrashedUniqueConflicts() error {
+	for _, raw := range cv.new.Indexes {
+		parsed := dbutils.ParseIndex(raw)
+		if !parsed.Unique || !parsed.IsValid() || deletedColumnRegex.MatchString(parsed.Where) {
+			continue
+		}
+
+		columns := make([]string, len(parsed.Columns))
+		for i, col := range parsed.Columns {
+			if fieldNameRegex.MatchString(col.Name) {
+				columns[i] = "[[" + col.Name + "]]"
+			} else {
+				columns[i] = col.Name // expression
+			}
+
+			if col.Collate != "" {
+				columns[i] += " COLLATE " + col.Collate
+			}
+		}
+
+		where := ""
+		if parsed.Where != "" {
+			where = " WHERE " + parsed.Where
+		}
+
+		// note: errors are ignored (e.g. new or renamed columns) and left to the db index creation
+		var exists int
+		_ = cv.app.ConcurrentDB().NewQuery(
+			"SELECT (1) FROM {{" + cv.original.Name + "}}" + where +
+				" GROUP BY " + strings.Join(columns, ", ") +
+				" HAVING COUNT(*) > 1 LIMIT 1",
+		).Row(&exists)
+
+		if exists > 0 {
+			return validation.NewError(
+				"validation_soft_delete_unique_conflict",
+				"Soft delete can't be disabled because the trashed records conflict with the {{.indexName}} unique index. Restore or permanently delete them first.",
+			).SetParams(map[string]any{"indexName": parsed.IndexName})
+		}
+	}
+
 	return nil
 }
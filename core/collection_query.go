@@ -218,6 +218,8 @@ func (app *BaseApp) IsCollectionNameUnique(name string, excludeIds ...string) bo
 //
 // Note that this method will also trigger the records related
 // cascade and file delete actions.
+//
+// The collection soft delete option (if enabled) is ignored, aka. all records are permanently deleted.
 func (app *BaseApp) TruncateCollection(collection *Collection) error {
 	if collection.IsView() {
 		return errors.New("view collections cannot be truncated since they don't store their own records")
@@ -227,7 +229,7 @@ func (app *BaseApp) TruncateCollection(collection *Collection) error {
 		records := make([]*Record, 0, 500)
 
 		for {
-			err := txApp.RecordQuery(collection).Limit(500).All(&records)
+			err := txApp.RecordQueryWithTrashed(collection).Limit(500).All(&records)
 			if err != nil {
 				return err
 			}
@@ -237,7 +239,7 @@ func (app *BaseApp) TruncateCollection(collection *Collection) error {
 			}
 
 			for _, record := range records {
-				err = txApp.Delete(record)
+				err = txApp.Delete(record.IgnoreSoftDelete(true))
 				if err != nil && !errors.Is(err, sql.ErrNoRows) {
 					return err
 				}
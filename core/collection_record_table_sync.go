@@ -35,6 +35,9 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 
 			// add fields definition
 			for _, field := range fields {
+				if !hasRecordTableColumn(field) {
+					continue
+				}
 				cols[field.GetName()] = field.ColumnType(app)
 			}
 
@@ -43,7 +46,11 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 				return err
 			}
 
-			return createCollectionIndexes(txApp, newCollection)
+			if err := createCollectionIndexes(txApp, newCollection); err != nil {
+				return err
+			}
+
+			return syncSearchFieldsTables(txApp, newCollection, nil)
 		}
 
 		// update
@@ -79,8 +86,8 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 
 		// check for deleted columns
 		for _, oldField := range oldFields {
-			if f := newFields.GetById(oldField.GetId()); f != nil {
-				continue // exist
+			if f := newFields.GetById(oldField.GetId()); f != nil || !hasRecordTableColumn(oldField) {
+				continue // exist or columnless
 			}
 
 			_, err := txApp.DB().DropColumn(newTableName, oldField.GetName()).Execute()
@@ -92,6 +99,10 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 		// check for new or renamed columns
 		toRename := map[string]string{}
 		for _, field := range newFields {
+			if !hasRecordTableColumn(field) {
+				continue
+			}
+
 			oldField := oldFields.GetById(field.GetId())
 			// Note:
 			// We are using a temporary column name when adding or renaming columns
@@ -133,10 +144,12 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 		}
 
 		if needIndexesUpdate {
-			return createCollectionIndexes(txApp, newCollection)
+			if err := createCollectionIndexes(txApp, newCollection); err != nil {
+				return err
+			}
 		}
 
-		return nil
+		return syncSearchFieldsTables(txApp, newCollection, oldCollection)
 	})
 	if txErr != nil {
 		return txErr
@@ -364,3 +377,105 @@ func createCollectionIndexes(app App, collection *Collection) error {
 		return nil
 	})
 }
+
+// hasRecordTableColumn reports whether the provided field has a
+// dedicated column in its collection records table.
+//
+// The "search" fields are columnless because their data is stored
+// only in the field FTS5 virtual table.
+func hasRecordTableColumn(field Field) bool {
+	_, isSearch := field.(*SearchField)
+	return !isSearch
+}
+
+// syncSearchFieldsTables creates, rebuilds or drops the FTS5 virtual
+// tables of the new and old collection "search" fields.
+//
+// A virtual table is rebuilt (and repopulated from the existing records)
+// only when its definition has changed, e.g. because of a new indexed field.
+func syncSearchFieldsTables(app App, newCollection *Collection, oldCollection *Collection) error {
+	if oldCollection != nil {
+		for _, oldField := range oldCollection.Fields {
+			searchField, ok := oldField.(*SearchField)
+			if !ok {
+				continue
+			}
+
+			if _, ok := newCollection.Fields.GetById(oldField.GetId()).(*SearchField); ok {
+				continue // still exist
+			}
+
+			if err := dropSearchIndexTable(app, searchField.IndexTableName(oldCollection)); err != nil {
+				return fmt.Errorf("failed to drop %q search index table: %w", searchField.Name, err)
+			}
+		}
+	}
+
+	for _, field := range newCollection.Fields {
+		searchField, ok := field.(*SearchField)
+		if !ok {
+			continue
+		}
+
+		tableName := searchField.IndexTableName(newCollection)
+		tableSQL := searchField.indexTableSQL(newCollection)
+
+		if oldCollection != nil && app.HasTable(tableName) {
+			oldSearchField, ok := oldCollection.Fields.GetById(field.GetId()).(*SearchField)
+			if ok && oldSearchField.indexTableSQL(oldCollection) == tableSQL {
+				continue // no changes
+			}
+		}
+
+		if err := dropSearchIndexTable(app, tableName); err != nil {
+			return fmt.Errorf("failed to drop %q search index table: %w", searchField.Name, err)
+		}
+
+		if _, err := app.DB().NewQuery(tableSQL).Execute(); err != nil {
+			return fmt.Errorf("failed to create %q search index table: %w", searchField.Name, err)
+		}
+
+		// populate with the existing records
+		_, err := app.DB().NewQuery(fmt.Sprintf(
+			"INSERT INTO {{%s}} ([[%s]], %s) SELECT [[id]], %s FROM {{%s}}",
+			tableName,
+			searchIndexIdColumn,
+			searchField.indexColumns(),
+			searchField.indexColumns(),
+			newCollection.Name,
+		)).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to populate %q search index table: %w", searchField.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// dropSearchFieldsTables drops the FTS5 virtual tables of all collection "search" fields.
+func dropSearchFieldsTables(app App, collection *Collection) error {
+	for _, field := range collection.Fields {
+		searchField, ok := field.(*SearchField)
+		if !ok {
+			continue
+		}
+
+		if err := dropSearchIndexTable(app, searchField.IndexTableName(collection)); err != nil {
+			return fmt.Errorf("failed to drop %q search index table: %w", searchField.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// dropSearchIndexTable drops the specified FTS5 virtual table.
+//
+// The "_content" shadow table of the contentless_unindexed FTS5 tables
+// is not removed by SQLite together with the virtual table so it is dropped explicitly.
+func dropSearchIndexTable(app App, tableName string) error {
+	if err := app.DeleteTable(tableName); err != nil {
+		return err
+	}
+
+	return app.DeleteTable(tableName + "_content")
+}
@@ -473,6 +473,60 @@ func (cv *collectionValidator) checkFieldsForUniqueIndex(value any) error {
 	return nil
 }
 
+// checkLDAPFields checks whether the LDAP mapped and group fields
+// exist and are not one of the system managed auth fields.
+func (cv *collectionValidator) checkLDAPFields(config LDAPConfig) error {
+	for name := range config.MappedFields {
+		if err := cv.checkAuthMappedField(name); err != nil {
+			return validation.Errors{
+				"mappedFields": validation.Errors{name: err},
+			}
+		}
+	}
+
+	for i, mapping := range config.GroupMappings {
+		if err := cv.checkAuthMappedField(mapping.Field); err != nil {
+			return validation.Errors{
+				"groupMappings": validation.Errors{
+					strconv.Itoa(i): validation.Errors{"field": err},
+				},
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkSAMLFields checks whether the SAML mapped fields
+// exist and are not one of the system managed auth fields.
+func (cv *collectionValidator) checkSAMLFields(config SAMLConfig) error {
+	for name := range config.MappedFields {
+		if err := cv.checkAuthMappedField(name); err != nil {
+			return validation.Errors{
+				"mappedFields": validation.Errors{name: err},
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkAuthMappedField checks whether the field with the specified name exists
+// and could be assigned by an external identity provider.
+func (cv *collectionValidator) checkAuthMappedField(name string) error {
+	if name == FieldNameId || name == FieldNamePassword || name == FieldNameTokenKey {
+		return validation.NewError("validation_disallowed_mapped_field", "The field {{.fieldName}} cannot be mapped.").
+			SetParams(map[string]any{"fieldName": name})
+	}
+
+	if cv.new.Fields.GetByName(name) == nil {
+		return validation.NewError("validation_missing_field", "Invalid or missing field {{.fieldName}}").
+			SetParams(map[string]any{"fieldName": name})
+	}
+
+	return nil
+}
+
 // note: value could be either *string or string
 func (validator *collectionValidator) checkRule(value any) error {
 	var vStr string
@@ -692,6 +746,8 @@ func (validator *collectionValidator) validateOptions() error {
 		return validator.new.collectionAuthOptions.validate(validator)
 	case CollectionTypeView:
 		return validator.new.collectionViewOptions.validate(validator)
+	case CollectionTypeBase:
+		return validator.new.collectionBaseOptions.validate(validator)
 	}
 
 	return nil
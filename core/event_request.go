@@ -23,6 +23,9 @@ type RequestEvent struct {
 
 	Auth *Record
 
+	// APIKey is the API key used to authenticate the current request (if any).
+	APIKey *APIKey
+
 	router.Event
 
 	mu sync.Mutex
@@ -89,6 +92,7 @@ func (e *RequestEvent) RequestInfo() (*RequestInfo, error) {
 
 	if e.cachedRequestInfo != nil {
 		e.cachedRequestInfo.Auth = e.Auth
+		e.cachedRequestInfo.APIKey = e.APIKey
 
 		infoCtx, _ := e.Get(RequestEventKeyInfoContext).(string)
 		if infoCtx != "" {
@@ -141,6 +145,7 @@ func (e *RequestEvent) initRequestInfo() error {
 	}
 
 	info.Auth = e.Auth
+	info.APIKey = e.APIKey
 
 	e.cachedRequestInfo = info
 
@@ -156,6 +161,8 @@ const (
 	RequestInfoContextProtectedFile = "protectedFile"
 	RequestInfoContextBatch         = "batch"
 	RequestInfoContextOAuth2        = "oauth2"
+	RequestInfoContextLDAP          = "ldap"
+	RequestInfoContextSAML          = "saml"
 	RequestInfoContextOTP           = "otp"
 	RequestInfoContextPasswordAuth  = "password"
 )
@@ -169,6 +176,7 @@ type RequestInfo struct {
 	Headers map[string]string `json:"headers"`
 	Body    map[string]any    `json:"body"`
 	Auth    *Record           `json:"auth"`
+	APIKey  *APIKey           `json:"apiKey,omitempty"`
 	Method  string            `json:"method"`
 	Context string            `json:"context"`
 }
@@ -193,5 +201,9 @@ func (info *RequestInfo) Clone() *RequestInfo {
 		clone.Auth = info.Auth.Fresh()
 	}
 
+	if info.APIKey != nil {
+		clone.APIKey = &APIKey{Record: info.APIKey.Fresh()}
+	}
+
 	return clone
 }
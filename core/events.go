@@ -8,9 +8,13 @@ import (
 	"time"
 
 	"github.com/pocketbase/pocketbase/tools/auth"
+	"github.com/pocketbase/pocketbase/tools/filescan"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/ldap"
 	"github.com/pocketbase/pocketbase/tools/mailer"
 	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/saml"
 	"github.com/pocketbase/pocketbase/tools/search"
 	"github.com/pocketbase/pocketbase/tools/subscriptions"
 	"golang.org/x/crypto/acme/autocert"
@@ -404,7 +408,7 @@ type FileDownloadRequestEvent struct {
 	ServedPath string
 	ServedName string
 
-	// ThumbError indicates the a thumb wasn't able to be generated
+	// ThumbError indicates the a thumb or image transform wasn't able to be generated
 	// (e.g. because it didn't satisfy the support image formats or it timed out).
 	//
 	// Note that PocketBase fallbacks to the original file in case of a thumb error,
@@ -412,6 +416,25 @@ type FileDownloadRequestEvent struct {
 	ThumbError error
 }
 
+type FileScanEvent struct {
+	hook.Event
+	App App
+	baseRecordEventData
+
+	Context   context.Context
+	FileField *FileField
+	File      *filesystem.File
+
+	// Scanner is the scanner that will be used to scan the file content
+	// (it is nil if the built-in file scanning is disabled).
+	//
+	// It could be replaced with a custom [filescan.Scanner] implementation.
+	Scanner filescan.Scanner
+
+	// Result is the file scan result (available after e.Next()).
+	Result *filescan.Result
+}
+
 // -------------------------------------------------------------------
 // Collection API events data
 // -------------------------------------------------------------------
@@ -498,6 +521,15 @@ type RecordsListRequestEvent struct {
 	Result  *search.Result
 }
 
+type RecordsAggregateRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	// Result.Items is []map[string]any with the group by and aggregate values.
+	Result *search.Result
+}
+
 type RecordRequestEvent struct {
 	hook.Event
 	*RequestEvent
@@ -536,6 +568,69 @@ type RecordAuthWithOTPRequestEvent struct {
 	OTP    *OTP
 }
 
+type RecordRegisterWebAuthnRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	Record     *Record
+	Credential *WebAuthnCredential
+}
+
+type RecordAuthWithWebAuthnRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	Record     *Record
+	Credential *WebAuthnCredential
+}
+
+type RecordConfirmTOTPSetupRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	Record *Record
+	TOTP   *TOTP
+
+	// RecoveryCodes is the list with the new plain recovery codes
+	// that will be returned to the client (only their hashes are stored).
+	RecoveryCodes []string
+}
+
+type RecordAuthWithTOTPRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	Record *Record
+	TOTP   *TOTP
+}
+
+type RecordAuthWithLDAPRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	Username    string
+	Record      *Record
+	Entry       *ldap.Entry
+	CreateData  map[string]any
+	IsNewRecord bool
+}
+
+type RecordAuthWithSAMLRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	Record      *Record
+	Assertion   *saml.Assertion
+	CreateData  map[string]any
+	IsNewRecord bool
+}
+
 type RecordAuthRequestEvent struct {
 	hook.Event
 	*RequestEvent
@@ -558,6 +653,17 @@ type RecordAuthWithPasswordRequestEvent struct {
 	Password      string
 }
 
+type RecordAuthLockoutRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	// Record is the locked auth record (nil for unknown identities).
+	Record   *Record
+	Identity string
+	Lockout  *AuthLockout
+}
+
 type RecordAuthWithOAuth2RequestEvent struct {
 	hook.Event
 	*RequestEvent
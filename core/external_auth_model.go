@@ -18,6 +18,14 @@ var (
 
 const CollectionNameExternalAuths = "_externalAuths"
 
+// ExternalAuthProviderLDAP is the provider name of the
+// external auths linked with the LDAP auth method.
+const ExternalAuthProviderLDAP = "ldap"
+
+// ExternalAuthProviderSAML is the provider name of the
+// external auths linked with the SAML auth method.
+const ExternalAuthProviderSAML = "saml"
+
 // ExternalAuth defines a Record proxy for working with the externalAuths collection.
 type ExternalAuth struct {
 	*Record
@@ -123,10 +131,11 @@ func (app *BaseApp) registerExternalAuthHooks() {
 
 	app.OnRecordValidate(CollectionNameExternalAuths).Bind(&hook.Handler[*RecordEvent]{
 		Func: func(e *RecordEvent) error {
-			providerNames := make([]any, 0, len(auth.Providers))
+			providerNames := make([]any, 0, len(auth.Providers)+2)
 			for name := range auth.Providers {
 				providerNames = append(providerNames, name)
 			}
+			providerNames = append(providerNames, ExternalAuthProviderLDAP, ExternalAuthProviderSAML)
 
 			provider := e.Record.GetString("provider")
 			if err := validation.Validate(provider, validation.Required, validation.In(providerNames...)); err != nil {
@@ -299,6 +299,42 @@ func TestExternalAuthValidateHook(t *testing.T) {
 			},
 			[]string{},
 		},
+		{
+			"invalid provider",
+			func() *core.ExternalAuth {
+				ea := core.NewExternalAuth(app)
+				ea.SetCollectionRef(user.Collection().Id)
+				ea.SetRecordRef(user.Id)
+				ea.SetProvider("missing")
+				ea.SetProviderId("test123")
+				return ea
+			},
+			[]string{"provider"},
+		},
+		{
+			"ldap provider",
+			func() *core.ExternalAuth {
+				ea := core.NewExternalAuth(app)
+				ea.SetCollectionRef(user.Collection().Id)
+				ea.SetRecordRef(user.Id)
+				ea.SetProvider(core.ExternalAuthProviderLDAP)
+				ea.SetProviderId("uid=test,dc=example,dc=com")
+				return ea
+			},
+			[]string{},
+		},
+		{
+			"saml provider",
+			func() *core.ExternalAuth {
+				ea := core.NewExternalAuth(app)
+				ea.SetCollectionRef(user.Collection().Id)
+				ea.SetRecordRef(user.Id)
+				ea.SetProvider(core.ExternalAuthProviderSAML)
+				ea.SetProviderId("test@example.com")
+				return ea
+			},
+			[]string{},
+		},
 	}
 
 	for _, s := range scenarios {
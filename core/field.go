@@ -26,6 +26,7 @@ const (
 	FieldNameVerified        = "verified"
 	FieldNameTokenKey        = "tokenKey"
 	FieldNamePassword        = "password"
+	FieldNameDeleted         = "deleted"
 )
 
 // SystemFields returns special internal field names that are usually readonly.
@@ -2,15 +2,22 @@ package core
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql/driver"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"image"
+	"io"
 	"log"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	validation "github.com/pocketbase/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/filescan"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/types"
@@ -125,6 +132,42 @@ type FileField struct {
 	//   - Wx0  (eg. 100x0)    - resize to W width preserving the aspect ratio
 	Thumbs []string `form:"thumbs" json:"thumbs"`
 
+	// Transforms specifies an optional list of the allowed image transformations
+	// that could be requested with the "transform" file query parameter.
+	//
+	// Each entry must be a comma separated list of the following options
+	// (see also [filesystem.ParseImageTransform]):
+	//
+	//   - WxH, WxHt, WxHb, WxHf, 0xH, Wx0 (eg. 100x300f) - resize in the same format as the thumbs
+	//   - format:webp, format:png, format:jpeg - convert to the specified format
+	//   - quality:N (eg. quality:80) - JPEG encoding quality in the range 1-100
+	//   - blur:N    (eg. blur:2.5)   - gaussian blur sigma in the range (0, 50]
+	//   - grayscale  - convert to grayscale
+	//   - autorotate - rotate/flip the image according to its EXIF orientation
+	//
+	// For example: "100x100f,format:webp,grayscale".
+	Transforms []string `form:"transforms" json:"transforms"`
+
+	// SanitizeImages enables the sanitization of the uploaded JPEG, PNG and WebP
+	// images before storing them (strips their EXIF/GPS and other metadata
+	// and bakes in the EXIF orientation).
+	//
+	// Note that the images are re-encoded only when they need to be
+	// rotated or downscaled, otherwise their image data is kept as it is.
+	SanitizeImages bool `form:"sanitizeImages" json:"sanitizeImages"`
+
+	// MaxImageWidth specifies an optional max width (in pixels) to
+	// downscale to (preserving the aspect ratio) the sanitized images.
+	//
+	// It is applied only when SanitizeImages is enabled. Leave it zero to disable.
+	MaxImageWidth int `form:"maxImageWidth" json:"maxImageWidth"`
+
+	// MaxImageHeight specifies an optional max height (in pixels) to
+	// downscale to (preserving the aspect ratio) the sanitized images.
+	//
+	// It is applied only when SanitizeImages is enabled. Leave it zero to disable.
+	MaxImageHeight int `form:"maxImageHeight" json:"maxImageHeight"`
+
 	// Protected will require the users to provide a special file token to access the file.
 	//
 	// Note that by default all files are publicly accessible.
@@ -234,9 +277,34 @@ func (f *FileField) ValidateSettings(ctx context.Context, app App, collection *C
 			validation.NotIn("0x0", "0x0t", "0x0b", "0x0f"),
 			validation.Match(filesystem.ThumbSizeRegex),
 		)),
+		validation.Field(&f.Transforms, validation.Each(validation.By(checkImageTransform))),
+		validation.Field(&f.MaxImageWidth, validation.Min(0), validation.Max(maxSafeJSONInt)),
+		validation.Field(&f.MaxImageHeight, validation.Min(0), validation.Max(maxSafeJSONInt)),
 	)
 }
 
+// FindTransform returns the allowed image transform matching the provided
+// transform string (the options order doesn't matter).
+//
+// Returns an error if the transform is invalid or it is not in the field [FileField.Transforms] list.
+func (f *FileField) FindTransform(str string) (*filesystem.ImageTransform, error) {
+	transform, err := filesystem.ParseImageTransform(str)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := transform.String()
+
+	for _, allowed := range f.Transforms {
+		t, err := filesystem.ParseImageTransform(allowed)
+		if err == nil && t.String() == normalized {
+			return transform, nil
+		}
+	}
+
+	return nil, fmt.Errorf("the image transform %q is not allowed", str)
+}
+
 // ValidateValue implements [Field.ValidateValue] interface method.
 func (f *FileField) ValidateValue(ctx context.Context, app App, record *Record) error {
 	files := f.toSliceValue(record.GetRaw(f.Name))
@@ -301,11 +369,241 @@ func (f *FileField) ValidateValue(ctx context.Context, app App, record *Record)
 				return err
 			}
 		}
+
+		// check the image dimensions before the sanitization
+		if f.SanitizeImages {
+			err = checkImagePixels(upload)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// scan the uploaded files content
+	// (after all other checks since it could be slow)
+	for _, upload := range uploads {
+		if err := f.scanFile(ctx, app, record, upload); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// PreScanFiles scans the provided record uploaded files in advance and
+// caches the results so that the following record validation could reuse
+// them instead of rescanning the same file content.
+//
+// Since the scan could be slow, it is intended to be called before starting
+// a transaction to avoid holding the db write lock while scanning
+// (the record create/update and batch APIs call it before the save).
+//
+// The cached results are reused by the validations of the same
+// collection field and file content and expire after 1 minute
+// (or earlier if the file scan settings are changed).
+func (f *FileField) PreScanFiles(ctx context.Context, app App, record *Record, files ...*filesystem.File) {
+	verdicts := fileScanVerdictsStore(app)
+
+	for _, file := range files {
+		// no need to scan a file that will be rejected anyway
+		if file.Size > f.maxSize() {
+			continue
+		}
+
+		digest, err := fileDigest(file)
+		if err != nil {
+			continue // will be scanned during the validation
+		}
+
+		// already scanned
+		if _, ok := verdicts.get(app, f.scanVerdictKey(record, digest)); ok {
+			continue
+		}
+
+		verdicts.set(app, f.scanVerdictKey(record, digest), f.runFileScan(ctx, app, record, file))
+	}
+}
+
+// scanFile returns the cached [FileField.PreScanFiles] result for the
+// provided uploaded file (if any), otherwise scans it with runFileScan.
+func (f *FileField) scanFile(ctx context.Context, app App, record *Record, file *filesystem.File) error {
+	verdicts := fileScanVerdictsStore(app)
+
+	if !verdicts.isEmpty() {
+		if digest, err := fileDigest(file); err == nil {
+			if verdict, ok := verdicts.get(app, f.scanVerdictKey(record, digest)); ok {
+				return verdict.err
+			}
+		}
+	}
+
+	return f.runFileScan(ctx, app, record, file)
+}
+
+func (f *FileField) scanVerdictKey(record *Record, digest string) string {
+	return record.Collection().Id + "/" + f.Name + "/" + digest
+}
+
+// runFileScan triggers the [App.OnFileScan] hook for the provided
+// uploaded file and returns a validation error if the file is infected,
+// if it exceeds the scanner size limit or if it couldn't be scanned
+// (unless the fail-open setting is enabled).
+func (f *FileField) runFileScan(ctx context.Context, app App, record *Record, file *filesystem.File) error {
+	event := new(FileScanEvent)
+	event.App = app
+	event.Record = record
+	event.Context = ctx
+	event.FileField = f
+	event.File = file
+	event.Scanner = app.NewFileScanner()
+
+	err := app.OnFileScan().Trigger(event, func(e *FileScanEvent) error {
+		if e.Scanner == nil {
+			return nil // no scanner
+		}
+
+		r, err := e.File.Reader.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		e.Result, err = e.Scanner.Scan(e.Context, r)
+
+		return err
+	})
+	if err != nil {
+		// custom hook validation error
+		var validationErr validation.Error
+		if errors.As(err, &validationErr) {
+			return validationErr
+		}
+
+		// the file could be never scanned so always reject it regardless of the fail-open setting
+		if errors.Is(err, filescan.ErrSizeLimitExceeded) {
+			return validation.NewError("validation_file_scan_too_large", "File {{.name}} is too large to be scanned.").
+				SetParams(map[string]any{"name": file.OriginalName})
+		}
+
+		failOpen := app.Settings().FileScan.FailOpen
+
+		app.Logger().Warn(
+			"Failed to scan uploaded file",
+			"error", err,
+			"file", file.Name,
+			"field", f.Name,
+			"failOpen", failOpen,
+		)
+
+		if failOpen {
+			return nil
+		}
+
+		return validation.NewError("validation_file_scan_failed", "Failed to scan file {{.name}}.").
+			SetParams(map[string]any{"name": file.OriginalName})
+	}
+
+	if event.Result != nil && event.Result.Infected {
+		return validation.NewError("validation_file_infected", "File {{.name}} is infected ({{.signature}}).").
+			SetParams(map[string]any{"name": file.OriginalName, "signature": event.Result.Signature})
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+const fileScanVerdictsStoreKey = "@pbFileScanVerdicts"
+
+// fileScanVerdictTTL is the max duration a pre-scan result is kept.
+//
+// It is intentionally short because the results are meant to be
+// reused only by the validation of the same request.
+const fileScanVerdictTTL = 1 * time.Minute
+
+// maxFileScanVerdicts limits the number of the cached pre-scan
+// results to prevent the app memory to be exhausted.
+const maxFileScanVerdicts = 1000
+
+type fileScanVerdict struct {
+	expires time.Time
+	err     error
+	config  FileScanConfig
+}
+
+// fileScanVerdicts is a simple in-memory store with the
+// [FileField.PreScanFiles] results.
+type fileScanVerdicts struct {
+	items map[string]fileScanVerdict
+	mu    sync.Mutex
+}
+
+func fileScanVerdictsStore(app App) *fileScanVerdicts {
+	return app.Store().GetOrSet(fileScanVerdictsStoreKey, func() any {
+		return &fileScanVerdicts{items: map[string]fileScanVerdict{}}
+	}).(*fileScanVerdicts)
+}
+
+func (s *fileScanVerdicts) isEmpty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.items) == 0
+}
+
+func (s *fileScanVerdicts) set(app App, key string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if len(s.items) >= maxFileScanVerdicts {
+		for k, v := range s.items {
+			if now.After(v.expires) {
+				delete(s.items, k)
+			}
+		}
+
+		// still full -> start over
+		if len(s.items) >= maxFileScanVerdicts {
+			clear(s.items)
+		}
+	}
+
+	s.items[key] = fileScanVerdict{
+		expires: now.Add(fileScanVerdictTTL),
+		err:     err,
+		config:  app.Settings().FileScan,
+	}
+}
+
+// get returns the non-expired verdict associated with the key
+// (the verdicts cached with different file scan settings are ignored).
+func (s *fileScanVerdicts) get(app App, key string) (fileScanVerdict, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.items[key]
+
+	return v, ok && time.Now().Before(v.expires) && v.config == app.Settings().FileScan
+}
+
+// fileDigest returns the hex encoded sha256 checksum of the file content.
+func fileDigest(file *filesystem.File) (string, error) {
+	r, err := file.Reader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func (f *FileField) maxSize() int64 {
 	if f.MaxSize <= 0 {
 		return DefaultFileFieldMaxSize
@@ -390,6 +688,8 @@ func (f *FileField) Intercept(
 
 		return actionFunc()
 	case InterceptorActionAfterCreate, InterceptorActionAfterUpdate:
+		f.deleteAttachedUploads(app, record)
+
 		record.SetRaw(uploadedFilesPrefix+f.Name, nil)
 
 		err := f.processFilesToDelete(ctx, app, record)
@@ -530,6 +830,15 @@ func (f *FileField) processFilesToUpload(ctx context.Context, app App, record *R
 	var succeeded []string // list of uploaded file names
 
 	for _, upload := range uploads {
+		if f.SanitizeImages {
+			sanitized, err := f.sanitizeImage(upload)
+			if err != nil {
+				failed = append(failed, fmt.Errorf("%q: %w", upload.Name, err))
+				break
+			}
+			upload = sanitized
+		}
+
 		path := record.BaseFilesPath() + "/" + upload.Name
 		if err := fsys.UploadFile(upload, path); err == nil {
 			succeeded = append(succeeded, upload.Name)
@@ -551,6 +860,82 @@ func (f *FileField) processFilesToUpload(ctx context.Context, app App, record *R
 	return nil
 }
 
+// checkImagePixels returns a validation error if the provided file is an image
+// with more than [filesystem.MaxSanitizeImagePixels] pixels.
+//
+// Only the image header is read, aka. the image is not decoded.
+func checkImagePixels(file *filesystem.File) error {
+	r, err := file.Reader.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil // not an image or unsupported format
+	}
+
+	if int64(config.Width)*int64(config.Height) > filesystem.MaxSanitizeImagePixels {
+		return validation.NewError(
+			"validation_image_too_large",
+			"The image dimensions must not exceed {{.maxPixels}} pixels in total.",
+		).SetParams(map[string]any{"maxPixels": filesystem.MaxSanitizeImagePixels})
+	}
+
+	return nil
+}
+
+// sanitizeImage returns a copy of the provided file with sanitized image content
+// (see [filesystem.SanitizeImage]).
+//
+// The file is returned as it is if it is not a JPEG, PNG or WebP image.
+func (f *FileField) sanitizeImage(file *filesystem.File) (*filesystem.File, error) {
+	r, err := file.Reader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	sanitized, err := filesystem.SanitizeImage(r, f.MaxImageWidth, f.MaxImageHeight)
+	if err != nil {
+		if errors.Is(err, filesystem.ErrUnsupportedImage) {
+			return file, nil
+		}
+		return nil, fmt.Errorf("failed to sanitize image: %w", err)
+	}
+
+	// note: the original file is not modified because its reader
+	// could be still needed (e.g. for deleting attached uploads)
+	clone := *file
+	clone.Reader = &filesystem.BytesReader{Bytes: sanitized}
+	clone.Size = int64(len(sanitized))
+
+	return &clone, nil
+}
+
+// deleteAttachedUploads deletes the staged resumable uploads
+// whose content was stored as part of the new record files.
+func (f *FileField) deleteAttachedUploads(app App, record *Record) {
+	uploaded, _ := record.GetRaw(uploadedFilesPrefix + f.Name).([]*filesystem.File)
+
+	for _, file := range uploaded {
+		r, ok := file.Reader.(*uploadFileReader)
+		if !ok {
+			continue
+		}
+
+		upload, err := app.FindUploadById(r.uploadId)
+		if err != nil {
+			continue // already deleted
+		}
+
+		if err := app.Delete(upload); err != nil {
+			app.Logger().Warn("Failed to delete attached upload", "error", err, "uploadId", r.uploadId)
+		}
+	}
+}
+
 func (f *FileField) deleteNewlyUploadedFiles(ctx context.Context, app App, record *Record) ([]string, error) {
 	uploaded, _ := record.GetRaw(uploadedFilesPrefix + f.Name).([]*filesystem.File)
 	if len(uploaded) == 0 {
@@ -827,3 +1212,14 @@ func (f *FileField) getFileName(file any) string {
 		return ""
 	}
 }
+
+func checkImageTransform(value any) error {
+	v, _ := value.(string)
+
+	if _, err := filesystem.ParseImageTransform(v); err != nil {
+		return validation.NewError("validation_invalid_image_transform", "Invalid image transform - {{.error}}.").
+			SetParams(map[string]any{"error": err.Error()})
+	}
+
+	return nil
+}
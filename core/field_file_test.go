@@ -3,15 +3,23 @@ package core_test
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
 	"slices"
 	"strings"
 	"testing"
 
+	validation "github.com/pocketbase/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/filescan"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/types"
@@ -444,6 +452,179 @@ func TestFileFieldValidateValue(t *testing.T) {
 	}
 }
 
+func TestFileFieldValidateValueScan(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		scanner       filescan.Scanner
+		failOpen      bool
+		hookError     error
+		expectedCode  string
+		expectedCalls int
+	}{
+		{"no scanner", nil, false, nil, "", 0},
+		{"clean file", &mockScanner{result: &filescan.Result{}}, false, nil, "", 1},
+		{
+			"infected file",
+			&mockScanner{result: &filescan.Result{Infected: true, Signature: "Eicar-Test-Signature"}},
+			false, nil, "validation_file_infected", 1,
+		},
+		{"scan failure (fail-closed)", &mockScanner{err: errors.New("test")}, false, nil, "validation_file_scan_failed", 1},
+		{"scan failure (fail-open)", &mockScanner{err: errors.New("test")}, true, nil, "", 1},
+		{"size limit exceeded (fail-open)", &mockScanner{err: filescan.ErrSizeLimitExceeded}, true, nil, "validation_file_scan_too_large", 1},
+		{
+			"custom hook validation error",
+			&mockScanner{result: &filescan.Result{}},
+			true, validation.NewError("test_code", "test"), "test_code", 0,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			app.Settings().FileScan.FailOpen = s.failOpen
+
+			collection, err := app.FindCollectionByNameOrId("demo1")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := filesystem.NewFileFromBytes([]byte("test"), "test.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			record := core.NewRecord(collection)
+			record.Set("file_one", file)
+
+			var hookCalls int
+			var scannedFile *filesystem.File
+
+			app.OnFileScan("demo1").BindFunc(func(e *core.FileScanEvent) error {
+				hookCalls++
+				scannedFile = e.File
+
+				if e.Record != record || e.FileField.Name != "file_one" {
+					t.Fatalf("Unexpected event record or field: %v, %v", e.Record, e.FileField)
+				}
+
+				if s.hookError != nil {
+					return s.hookError
+				}
+
+				e.Scanner = s.scanner
+
+				return e.Next()
+			})
+
+			field := collection.Fields.GetByName("file_one").(*core.FileField)
+
+			err = field.ValidateValue(context.Background(), app, record)
+
+			if hookCalls != 1 || scannedFile != file {
+				t.Fatalf("Expected the hook to be called once with the uploaded file, got %d calls", hookCalls)
+			}
+
+			if mock, ok := s.scanner.(*mockScanner); ok && mock.calls != s.expectedCalls {
+				t.Fatalf("Expected %d scan calls, got %d", s.expectedCalls, mock.calls)
+			}
+
+			if s.expectedCode == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var validationErr validation.Error
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+
+			if validationErr.Code() != s.expectedCode {
+				t.Fatalf("Expected error code %q, got %q", s.expectedCode, validationErr.Code())
+			}
+		})
+	}
+}
+
+func TestFileFieldPreScanFiles(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		scanner        *mockScanner
+		changeSettings bool
+		expectedCode   string
+		expectedCalls  int
+	}{
+		{"clean file", &mockScanner{result: &filescan.Result{}}, false, "", 1},
+		{"infected file", &mockScanner{result: &filescan.Result{Infected: true, Signature: "test"}}, false, "validation_file_infected", 1},
+		{"scan failure", &mockScanner{err: errors.New("test")}, false, "validation_file_scan_failed", 1},
+		{"changed settings after the pre-scan", &mockScanner{result: &filescan.Result{}}, true, "", 2},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			collection, err := app.FindCollectionByNameOrId("demo1")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			app.OnFileScan().BindFunc(func(e *core.FileScanEvent) error {
+				e.Scanner = s.scanner
+				return e.Next()
+			})
+
+			field := collection.Fields.GetByName("file_one").(*core.FileField)
+
+			file1, err := filesystem.NewFileFromBytes([]byte("test"), "test1.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			record := core.NewRecord(collection)
+
+			field.PreScanFiles(context.Background(), app, record, file1)
+
+			if s.changeSettings {
+				app.Settings().FileScan.Timeout++
+			}
+
+			// different file instance with the same content
+			file2, err := filesystem.NewFileFromBytes([]byte("test"), "test2.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			record.Set("file_one", file2)
+
+			err = field.ValidateValue(context.Background(), app, record)
+
+			if s.scanner.calls != s.expectedCalls {
+				t.Fatalf("Expected %d scan calls, got %d", s.expectedCalls, s.scanner.calls)
+			}
+
+			if s.expectedCode == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var validationErr validation.Error
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+
+			if validationErr.Code() != s.expectedCode {
+				t.Fatalf("Expected error code %q, got %q", s.expectedCode, validationErr.Code())
+			}
+		})
+	}
+}
+
 func TestFileFieldValidateSettings(t *testing.T) {
 	testDefaultFieldIdValidation(t, core.FieldTypeFile)
 	testDefaultFieldNameValidation(t, core.FieldTypeFile)
@@ -544,6 +725,54 @@ func TestFileFieldValidateSettings(t *testing.T) {
 			},
 			[]string{},
 		},
+		{
+			"invalid transforms",
+			func() *core.FileField {
+				return &core.FileField{
+					Id:         "test",
+					Name:       "test",
+					Transforms: []string{"100x100,format:webp", "format:gif"},
+				}
+			},
+			[]string{"transforms"},
+		},
+		{
+			"valid transforms",
+			func() *core.FileField {
+				return &core.FileField{
+					Id:         "test",
+					Name:       "test",
+					Transforms: []string{"100x100,format:webp", "grayscale,blur:2", "format:jpeg,quality:80,autorotate"},
+				}
+			},
+			[]string{},
+		},
+		{
+			"MaxImageWidth and MaxImageHeight < 0",
+			func() *core.FileField {
+				return &core.FileField{
+					Id:             "test",
+					Name:           "test",
+					SanitizeImages: true,
+					MaxImageWidth:  -1,
+					MaxImageHeight: -1,
+				}
+			},
+			[]string{"maxImageWidth", "maxImageHeight"},
+		},
+		{
+			"valid MaxImageWidth and MaxImageHeight",
+			func() *core.FileField {
+				return &core.FileField{
+					Id:             "test",
+					Name:           "test",
+					SanitizeImages: true,
+					MaxImageWidth:  100,
+					MaxImageHeight: 0,
+				}
+			},
+			[]string{},
+		},
 		{
 			"MaxSize > safe json int",
 			func() *core.FileField {
@@ -630,6 +859,46 @@ func TestFileFieldCalculateMaxBodySize(t *testing.T) {
 	}
 }
 
+func TestFileFieldFindTransform(t *testing.T) {
+	field := &core.FileField{
+		Transforms: []string{"100x100,format:webp", "grayscale, blur:2"},
+	}
+
+	scenarios := []struct {
+		transform string
+		expected  string
+	}{
+		{"", ""},
+		{"invalid", ""},
+		{"100x100", ""},
+		{"format:webp", ""},
+		{"100x100,format:png", ""},
+		{"100x100,format:webp", "100x100,format:webp"},
+		{"format:webp,100x100", "100x100,format:webp"},
+		{"blur:2,grayscale", "blur:2,grayscale"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.transform, func(t *testing.T) {
+			transform, err := field.FindTransform(s.transform)
+
+			hasErr := err != nil
+			expectErr := s.expected == ""
+			if hasErr != expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", expectErr, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if str := transform.String(); str != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, str)
+			}
+		})
+	}
+}
+
 func TestFileFieldFindGetter(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -966,6 +1235,157 @@ func TestFileFieldIntercept(t *testing.T) {
 	})
 }
 
+func TestFileFieldInterceptSanitizeImages(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	demo1, err := testApp.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	field := demo1.Fields.GetByName("file_many").(*core.FileField)
+	field.MimeTypes = nil
+	field.SanitizeImages = true
+	field.MaxImageHeight = 2
+
+	// 4x8 jpeg with EXIF orientation 6 (rotate 90 CW)
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, image.NewNRGBA(image.Rect(0, 0, 4, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	exif := []byte("Exif\x00\x00II*\x00\x08\x00\x00\x00\x01\x00\x12\x01\x03\x00\x01\x00\x00\x00\x06\x00\x00\x00\x00\x00\x00\x00GPS")
+	jpegData := append([]byte{0xff, 0xd8, 0xff, 0xe1, 0, byte(len(exif) + 2)}, exif...)
+	jpegData = append(jpegData, encoded.Bytes()[2:]...)
+
+	img, err := filesystem.NewFileFromBytes(jpegData, "photo.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txt, err := filesystem.NewFileFromBytes([]byte("Exif GPS"), "test.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(demo1)
+	record.Set("text", "abc")
+	record.Set("file_many", []any{img, txt})
+
+	if err := testApp.Save(record); err != nil {
+		t.Fatalf("Expected save to succeed, got %v", err)
+	}
+
+	checkRecordFiles(t, testApp, record, []string{img.Name, txt.Name})
+
+	fsys, err := testApp.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	// sanitized image
+	r, err := fsys.GetReader(record.BaseFilesPath() + "/" + img.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	stored, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(stored, []byte("Exif")) || bytes.Contains(stored, []byte("GPS")) {
+		t.Fatal("Expected the image metadata to be stripped")
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(stored))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Width != 4 || cfg.Height != 2 {
+		t.Fatalf("Expected the image to be rotated and downscaled to 4x2, got %dx%d", cfg.Width, cfg.Height)
+	}
+
+	// non-image file
+	r2, err := fsys.GetReader(record.BaseFilesPath() + "/" + txt.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r2.Close()
+
+	storedTxt, err := io.ReadAll(r2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(storedTxt) != "Exif GPS" {
+		t.Fatalf("Expected the non-image file to be stored as it is, got %q", storedTxt)
+	}
+}
+
+func TestFileFieldValidateValueSanitizeImagePixels(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// 1x1 png whose header declares 10000x10000 dimensions
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, image.NewNRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	bomb := encoded.Bytes()
+	binary.BigEndian.PutUint32(bomb[16:20], 10000)
+	binary.BigEndian.PutUint32(bomb[20:24], 10000)
+	binary.BigEndian.PutUint32(bomb[29:33], crc32.ChecksumIEEE(bomb[12:29]))
+
+	var regular bytes.Buffer
+	if err := png.Encode(&regular, image.NewNRGBA(image.Rect(0, 0, 10, 10))); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name           string
+		sanitizeImages bool
+		data           []byte
+		expectedCode   string
+	}{
+		{"pixel bomb without sanitization", false, bomb, ""},
+		{"pixel bomb with sanitization", true, bomb, "validation_image_too_large"},
+		{"regular image with sanitization", true, regular.Bytes(), ""},
+		{"non-image with sanitization", true, []byte("test"), ""},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			collection := core.NewBaseCollection("test_collection")
+
+			field := &core.FileField{Name: "test", MaxSelect: 1, SanitizeImages: s.sanitizeImages}
+
+			file, err := filesystem.NewFileFromBytes(s.data, "test.png")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			record := core.NewRecord(collection)
+			record.SetRaw("test", file)
+
+			err = field.ValidateValue(context.Background(), app, record)
+
+			var code string
+			if verr, ok := err.(validation.Error); ok {
+				code = verr.Code()
+			} else if err != nil {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+
+			if code != s.expectedCode {
+				t.Fatalf("Expected error code %q, got %q", s.expectedCode, code)
+			}
+		})
+	}
+}
+
 func TestFileFieldInterceptTx(t *testing.T) {
 	testApp, _ := tests.NewTestApp()
 	defer testApp.Cleanup()
@@ -1155,3 +1575,21 @@ func checkRecordFiles(t *testing.T, testApp core.App, record *core.Record, expec
 		}
 	}
 }
+
+// -------------------------------------------------------------------
+
+type mockScanner struct {
+	result *filescan.Result
+	err    error
+	calls  int
+}
+
+func (m *mockScanner) Scan(ctx context.Context, r io.Reader) (*filescan.Result, error) {
+	m.calls++
+
+	if _, err := io.ReadAll(r); err != nil {
+		return nil, err
+	}
+
+	return m.result, m.err
+}
@@ -2,13 +2,21 @@ package core
 
 import (
 	"context"
+	"crypto/sha256"
+	"database/sql"
 	"database/sql/driver"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	validation "github.com/pocketbase/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/spf13/cast"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -21,6 +29,11 @@ func init() {
 
 const FieldTypePassword = "password"
 
+// MaxPasswordHistoryDepth is the max allowed [PasswordField.HistoryDepth].
+const MaxPasswordHistoryDepth = 24
+
+const passwordHistoryCheckedPrefix = internalCustomFieldKeyPrefix + "_passwordHistoryChecked_"
+
 var (
 	_ Field             = (*PasswordField)(nil)
 	_ GetterFinder      = (*PasswordField)(nil)
@@ -93,6 +106,34 @@ type PasswordField struct {
 	// If explicitly set, must be between [bcrypt.MinCost] and [bcrypt.MaxCost].
 	Cost int `form:"cost" json:"cost"`
 
+	// HistoryDepth specifies the number of the most recent passwords
+	// (including the current one) that are not allowed to be reused.
+	//
+	// Because each entry is checked with a bcrypt comparison,
+	// it must be no more than [MaxPasswordHistoryDepth].
+	//
+	// Leave it zero to skip the password history check.
+	HistoryDepth int `form:"historyDepth" json:"historyDepth"`
+
+	// ForbiddenFields specifies an optional list of other collection field names
+	// whose record values are not allowed to be part of the password, for example:
+	//
+	//	[]string{"email", "username", "name"}
+	//
+	// The check is case-insensitive and only values with at least 3 characters are considered
+	// (for emails the local part before the "@" is also checked separately).
+	ForbiddenFields []string `form:"forbiddenFields" json:"forbiddenFields"`
+
+	// ForbidCommon rejects the passwords that are part of the bundled
+	// list with the most common and frequently breached passwords.
+	ForbidCommon bool `form:"forbidCommon" json:"forbidCommon"`
+
+	// MaxAge specifies an optional max password age (in seconds) after which
+	// the password authentication is rejected until the password is reset.
+	//
+	// Leave it zero to disable the password expiration.
+	MaxAge int64 `form:"maxAge" json:"maxAge"`
+
 	// Required will require the field value to be non-empty string.
 	Required bool `form:"required" json:"required"`
 }
@@ -207,9 +248,174 @@ func (f *PasswordField) ValidateValue(ctx context.Context, app App, record *Reco
 		}
 	}
 
+	// skip the (bcrypt expensive) history check if it was
+	// already performed for the same password with ValidatePolicy
+	var historyChecked bool
+	if f.HistoryDepth > 0 {
+		checkedKey := passwordHistoryCheckedPrefix + f.Name
+		historyChecked = record.GetRaw(checkedKey) == passwordDigest(fp.Plain)
+		record.SetRaw(checkedKey, nil)
+	}
+
+	return f.validatePolicy(app, record, fp.Plain, historyChecked)
+}
+
+// ValidatePolicy checks whether the plain password satisfies the field
+// password policy (common passwords, forbidden field values and history).
+//
+// Note that the length and pattern constraints are checked separately in [PasswordField.ValidateValue].
+//
+// On success the history check is not repeated by the next record
+// validation of the same password (e.g. on the follow-up save).
+func (f *PasswordField) ValidatePolicy(app App, record *Record, password string) error {
+	if err := f.validatePolicy(app, record, password, false); err != nil {
+		return err
+	}
+
+	if f.HistoryDepth > 0 && !record.IsNew() {
+		record.SetRaw(passwordHistoryCheckedPrefix+f.Name, passwordDigest(password))
+	}
+
 	return nil
 }
 
+func (f *PasswordField) validatePolicy(app App, record *Record, password string, skipHistory bool) error {
+	if f.ForbidCommon && security.IsCommonPassword(password) {
+		return validation.NewError("validation_password_common", "The password is too common. Please choose a different one.")
+	}
+
+	if len(f.ForbiddenFields) > 0 {
+		lowerPassword := strings.ToLower(password)
+		for _, name := range f.ForbiddenFields {
+			for _, v := range forbiddenPasswordValues(record, name) {
+				if strings.Contains(lowerPassword, v) {
+					return validation.NewError(
+						"validation_password_contains_field",
+						"The password must not contain the {{.field}} value.",
+					).SetParams(map[string]any{"field": name})
+				}
+			}
+		}
+	}
+
+	if f.HistoryDepth > 0 && !record.IsNew() && !skipHistory {
+		reused, err := f.isRecentPassword(app, record, password)
+		if err != nil {
+			return err
+		}
+		if reused {
+			return validation.NewError("validation_password_reused", "The password was used recently. Please choose a different one.")
+		}
+	}
+
+	return nil
+}
+
+// HasExpired reports whether the current record password is older than the field MaxAge.
+//
+// The password age is determined from the latest password history entry
+// with fallback to the record "created" field value (if any).
+func (f *PasswordField) HasExpired(app App, record *Record) bool {
+	if f.MaxAge <= 0 {
+		return false
+	}
+
+	var setAt types.DateTime
+
+	history, err := app.FindPasswordHistoryByRecord(record, f.Name, 1)
+	if err == nil && len(history) > 0 {
+		setAt = history[0].Created()
+	} else {
+		setAt = record.GetDateTime("created")
+	}
+
+	if setAt.IsZero() {
+		return false
+	}
+
+	return time.Since(setAt.Time()) > time.Duration(f.MaxAge)*time.Second
+}
+
+// isRecentPassword checks whether the password matches the currently
+// persisted record password or one of the latest history entries.
+func (f *PasswordField) isRecentPassword(app App, record *Record, password string) (bool, error) {
+	// load the persisted record state since the original data of
+	// the current record instance could be stale after previous saves
+	persisted, err := app.FindRecordById(record.Collection(), cast.ToString(record.LastSavedPK()))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+	if persisted != nil && f.getPasswordValue(persisted).Validate(password) {
+		return true, nil
+	}
+
+	history, err := app.FindPasswordHistoryByRecord(record, f.Name, f.HistoryDepth)
+	if err != nil {
+		return false, err
+	}
+
+	for _, h := range history {
+		if h.ValidatePassword(password) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// tracksHistory reports whether the field policy requires storing the password history.
+func (f *PasswordField) tracksHistory() bool {
+	return f.HistoryDepth > 0 || f.MaxAge > 0
+}
+
+// saveHistory stores the specified password hash as the latest
+// history entry and deletes the ones beyond the HistoryDepth.
+func (f *PasswordField) saveHistory(app App, record *Record, hash string) error {
+	entry := NewPasswordHistory(app)
+	entry.SetCollectionRef(record.Collection().Id)
+	entry.SetRecordRef(record.Id)
+	entry.SetField(f.Name)
+	entry.SetHash(hash)
+
+	if err := app.Save(entry); err != nil {
+		return err
+	}
+
+	return app.DeleteOldPasswordHistory(record, f.Name, max(f.HistoryDepth, 1))
+}
+
+// passwordDigest returns the hex encoded sha256 checksum of the password
+// (used to avoid keeping a second copy of the plain password in the record).
+func passwordDigest(password string) string {
+	h := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(h[:])
+}
+
+// forbiddenPasswordValues returns the normalized record field values
+// that are not allowed to be part of the password.
+func forbiddenPasswordValues(record *Record, fieldName string) []string {
+	raw := list.ToUniqueStringSlice(record.Get(fieldName))
+
+	result := make([]string, 0, len(raw))
+
+	add := func(v string) {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if len([]rune(v)) >= 3 {
+			result = append(result, v)
+		}
+	}
+
+	for _, v := range raw {
+		add(v)
+
+		if at := strings.LastIndex(v, "@"); at > 0 {
+			add(v[:at])
+		}
+	}
+
+	return result
+}
+
 // ValidateSettings implements [Field.ValidateSettings] interface method.
 func (f *PasswordField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
 	return validation.ValidateStruct(f,
@@ -220,9 +426,29 @@ func (f *PasswordField) ValidateSettings(ctx context.Context, app App, collectio
 		validation.Field(&f.Max, validation.Min(f.Min), validation.Max(71)),
 		validation.Field(&f.Cost, validation.Min(bcrypt.MinCost), validation.Max(bcrypt.MaxCost)),
 		validation.Field(&f.Pattern, validation.By(validators.IsRegex)),
+		validation.Field(&f.HistoryDepth, validation.Min(0), validation.Max(MaxPasswordHistoryDepth)),
+		validation.Field(&f.MaxAge, validation.Min(0)),
+		validation.Field(&f.ForbiddenFields, validation.Each(validation.By(f.checkForbiddenField(collection)))),
 	)
 }
 
+func (f *PasswordField) checkForbiddenField(collection *Collection) validation.RuleFunc {
+	return func(value any) error {
+		name, _ := value.(string)
+
+		field := collection.Fields.GetByName(name)
+		if field == nil {
+			return validation.NewError("validation_forbidden_field_missing", "Missing collection field "+name+".")
+		}
+
+		if field.GetName() == f.Name || field.Type() == FieldTypePassword {
+			return validation.NewError("validation_forbidden_field_password", "Password fields cannot be used as forbidden fields.")
+		}
+
+		return nil
+	}
+}
+
 func (f *PasswordField) getPasswordValue(record *Record) *PasswordFieldValue {
 	raw := record.GetRaw(f.Name)
 
@@ -249,8 +475,16 @@ func (f *PasswordField) Intercept(
 ) error {
 	switch actionName {
 	case InterceptorActionAfterCreate, InterceptorActionAfterUpdate:
-		// unset the plain field value after successful create/update
 		fp := f.getPasswordValue(record)
+
+		if fp.Plain != "" && fp.Hash != "" && f.tracksHistory() {
+			if err := f.saveHistory(app, record, fp.Hash); err != nil {
+				// treat as non-critical error, just log for now
+				app.Logger().Warn("Failed to save password history", "error", err, "recordId", record.Id, "field", f.Name)
+			}
+		}
+
+		// unset the plain field value after successful create/update
 		fp.Plain = ""
 	}
 
@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -123,6 +126,8 @@ func TestPasswordFieldValidateValue(t *testing.T) {
 	defer app.Cleanup()
 
 	collection := core.NewBaseCollection("test_collection")
+	collection.Fields.Add(&core.TextField{Name: "name"})
+	collection.Fields.Add(&core.EmailField{Name: "email"})
 
 	scenarios := []struct {
 		name        string
@@ -270,6 +275,81 @@ func TestPasswordFieldValidateValue(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"common password (ForbidCommon disabled)",
+			&core.PasswordField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "Password123"})
+				return record
+			},
+			false,
+		},
+		{
+			"common password (ForbidCommon enabled)",
+			&core.PasswordField{Name: "test", ForbidCommon: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "Password123"})
+				return record
+			},
+			true,
+		},
+		{
+			"uncommon password (ForbidCommon enabled)",
+			&core.PasswordField{Name: "test", ForbidCommon: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "uncommon_8d1f3a"})
+				return record
+			},
+			false,
+		},
+		{
+			"containing a forbidden field value",
+			&core.PasswordField{Name: "test", ForbiddenFields: []string{"name"}},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("name", "John")
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "123_jOHn_456"})
+				return record
+			},
+			true,
+		},
+		{
+			"containing a too short forbidden field value",
+			&core.PasswordField{Name: "test", ForbiddenFields: []string{"name"}},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("name", "Jo")
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "123_jo_456"})
+				return record
+			},
+			false,
+		},
+		{
+			"containing the local part of a forbidden email field value",
+			&core.PasswordField{Name: "test", ForbiddenFields: []string{"email"}},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("email", "john.doe@example.com")
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "John.Doe1234"})
+				return record
+			},
+			true,
+		},
+		{
+			"not containing any forbidden field value",
+			&core.PasswordField{Name: "test", ForbiddenFields: []string{"name", "email"}},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("name", "John")
+				record.Set("email", "john.doe@example.com")
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "1234567890"})
+				return record
+			},
+			false,
+		},
 	}
 
 	for _, s := range scenarios {
@@ -430,6 +510,65 @@ func TestPasswordFieldValidateSettings(t *testing.T) {
 			},
 			[]string{},
 		},
+		{
+			"historyDepth and maxAge < 0",
+			func(col *core.Collection) *core.PasswordField {
+				return &core.PasswordField{
+					Id:           "test",
+					Name:         "test",
+					HistoryDepth: -1,
+					MaxAge:       -1,
+				}
+			},
+			[]string{"historyDepth", "maxAge"},
+		},
+		{
+			"historyDepth > MaxPasswordHistoryDepth",
+			func(col *core.Collection) *core.PasswordField {
+				return &core.PasswordField{
+					Id:           "test",
+					Name:         "test",
+					HistoryDepth: core.MaxPasswordHistoryDepth + 1,
+				}
+			},
+			[]string{"historyDepth"},
+		},
+		{
+			"valid historyDepth and maxAge",
+			func(col *core.Collection) *core.PasswordField {
+				return &core.PasswordField{
+					Id:           "test",
+					Name:         "test",
+					HistoryDepth: 5,
+					MaxAge:       86400,
+				}
+			},
+			[]string{},
+		},
+		{
+			"forbiddenFields with missing and password fields",
+			func(col *core.Collection) *core.PasswordField {
+				col.Fields.Add(&core.PasswordField{Name: "other_password"})
+				return &core.PasswordField{
+					Id:              "test",
+					Name:            "test",
+					ForbiddenFields: []string{"missing", "test", "other_password"},
+				}
+			},
+			[]string{"forbiddenFields"},
+		},
+		{
+			"valid forbiddenFields",
+			func(col *core.Collection) *core.PasswordField {
+				col.Fields.Add(&core.TextField{Name: "name"})
+				return &core.PasswordField{
+					Id:              "test",
+					Name:            "test",
+					ForbiddenFields: []string{"name"},
+				}
+			},
+			[]string{},
+		},
 	}
 
 	for _, s := range scenarios {
@@ -567,3 +706,175 @@ func TestPasswordFieldFindGetter(t *testing.T) {
 		})
 	}
 }
+
+func TestPasswordFieldPasswordHistory(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passField := users.Fields.GetByName("password").(*core.PasswordField)
+	passField.HistoryDepth = 2
+	if err := app.Save(users); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail(users, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the current password (set before enabling the policy) shouldn't be reusable
+	user.SetPassword("1234567890")
+	if err := app.Save(user); err == nil {
+		t.Fatal("Expected the current password to be rejected")
+	}
+
+	for _, pass := range []string{"new_pass_1", "new_pass_2", "new_pass_3"} {
+		user.SetPassword(pass)
+		if err := app.Save(user); err != nil {
+			t.Fatalf("Failed to save password %q: %v", pass, err)
+		}
+	}
+
+	history, err := app.FindPasswordHistoryByRecord(user, "password", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected %d history entries, got %d", 2, len(history))
+	}
+
+	if user.GetString("password") != "" {
+		t.Fatal("Expected the plain password to be cleared after save")
+	}
+
+	scenarios := []struct {
+		password    string
+		expectError bool
+	}{
+		{"new_pass_3", true},  // current
+		{"new_pass_2", true},  // in history
+		{"new_pass_1", false}, // beyond the history depth
+		{"1234567890", false}, // beyond the history depth
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.password, func(t *testing.T) {
+			err := passField.ValidatePolicy(app, user, s.password)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestPasswordFieldValidatePolicyHistoryCheckOnce(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passField := users.Fields.GetByName("password").(*core.PasswordField)
+	passField.HistoryDepth = 2
+	if err := app.Save(users); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail(users, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := passField.ValidatePolicy(app, user, "new_pass"); err != nil {
+		t.Fatalf("Expected the new password to be valid, got %v", err)
+	}
+
+	// simulate a history entry that was created after the explicit policy check
+	hash, err := bcrypt.GenerateFromPassword([]byte("new_pass"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := core.NewPasswordHistory(app)
+	entry.SetCollectionRef(users.Id)
+	entry.SetRecordRef(user.Id)
+	entry.SetField("password")
+	entry.SetHash(string(hash))
+	if err := app.Save(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	user.SetPassword("new_pass")
+
+	// the history check was already performed for the same password
+	if err := passField.ValidateValue(context.Background(), app, user); err != nil {
+		t.Fatalf("Expected the history check to be skipped, got %v", err)
+	}
+
+	// the skip applies only once
+	if err := passField.ValidateValue(context.Background(), app, user); err == nil {
+		t.Fatal("Expected the history check to be performed again")
+	}
+}
+
+func TestPasswordFieldHasExpired(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passField := users.Fields.GetByName("password").(*core.PasswordField)
+
+	user, err := app.FindAuthRecordByEmail(users, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if passField.HasExpired(app, user) {
+		t.Fatal("Expected non-expired password with zero MaxAge")
+	}
+
+	passField.MaxAge = 3600
+	if err := app.Save(users); err != nil {
+		t.Fatal(err)
+	}
+
+	// fallback to the old record created date
+	if !passField.HasExpired(app, user) {
+		t.Fatal("Expected expired password based on the record created date")
+	}
+
+	user.SetPassword("new_pass_1")
+	if err := app.Save(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if passField.HasExpired(app, user) {
+		t.Fatal("Expected non-expired password after change")
+	}
+
+	// mock an old password change
+	_, err = app.DB().Update(
+		core.CollectionNamePasswordHistory,
+		dbx.Params{"created": types.NowDateTime().Add(-2 * time.Hour)},
+		dbx.HashExp{"recordRef": user.Id},
+	).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !passField.HasExpired(app, user) {
+		t.Fatal("Expected expired password based on the history date")
+	}
+}
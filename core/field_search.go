@@ -0,0 +1,339 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core/validators"
+)
+
+func init() {
+	Fields[FieldTypeSearch] = func() Field {
+		return &SearchField{}
+	}
+}
+
+const FieldTypeSearch = "search"
+
+// supported SearchField tokenizers
+const (
+	SearchTokenizerUnicode61 = "unicode61"
+	SearchTokenizerPorter    = "porter"
+	SearchTokenizerTrigram   = "trigram"
+	SearchTokenizerAscii     = "ascii"
+)
+
+// searchTokenizerOptions maps the SearchField tokenizers to their FTS5 "tokenize" option value.
+var searchTokenizerOptions = map[string]string{
+	SearchTokenizerUnicode61: "unicode61 remove_diacritics 2",
+	SearchTokenizerPorter:    "porter unicode61 remove_diacritics 2",
+	SearchTokenizerTrigram:   "trigram",
+	SearchTokenizerAscii:     "ascii",
+}
+
+// searchIndexIdColumn is the name of the UNINDEXED FTS5 column
+// that stores the id of the indexed record.
+const searchIndexIdColumn = "_recordId"
+
+// searchFieldSourceTypes lists the field types that could be indexed by a SearchField.
+var searchFieldSourceTypes = []string{
+	FieldTypeText,
+	FieldTypeEditor,
+	FieldTypeEmail,
+	FieldTypeURL,
+	FieldTypeSelect,
+	FieldTypeJSON,
+}
+
+var (
+	_ Field             = (*SearchField)(nil)
+	_ RecordInterceptor = (*SearchField)(nil)
+)
+
+// SearchField defines "search" type field for full-text searching
+// one or more of the other collection fields.
+//
+// The indexed fields values are stored in a dedicated contentless
+// SQLite FTS5 virtual table that is kept in sync on record create, update and delete.
+//
+// Because the match and rank results could reveal the indexed values,
+// hidden fields and the auth collection "email" field could be indexed
+// only if the search field itself is also marked as hidden.
+//
+// The field itself doesn't have a records table column and its record value is always an empty string.
+// It could be used in the filter and sort expressions only as argument of the
+// "match" and "rank" functions, for example:
+//
+//	filter: match(content, 'hello world') = true
+//	sort:   rank(content, 'hello world')
+type SearchField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// ---
+
+	// Help is an extra text explaining what the field is about.
+	// It is usually shown in Dashboard UI under the field input.
+	Help string `form:"help" json:"help"`
+
+	// Fields (required) is a list with the names of the collection fields to index.
+	//
+	// Only text, editor, email, url, select and json fields are supported.
+	Fields []string `form:"fields" json:"fields"`
+
+	// Tokenizer specifies the FTS5 tokenizer to use.
+	//
+	// Supported values: "unicode61" (default), "porter", "trigram", "ascii".
+	Tokenizer string `form:"tokenizer" json:"tokenizer"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *SearchField) Type() string {
+	return FieldTypeSearch
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *SearchField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *SearchField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *SearchField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *SearchField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *SearchField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *SearchField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *SearchField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *SearchField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+//
+// It always returns an empty string because the field doesn't have
+// a records table column (see [SearchField] for more details).
+func (f *SearchField) ColumnType(app App) string {
+	return ""
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+//
+// The field doesn't store any data and always resolves to an empty string.
+func (f *SearchField) PrepareValue(record *Record, raw any) (any, error) {
+	return "", nil
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *SearchField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	if _, ok := record.GetRaw(f.Name).(string); !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *SearchField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+		validation.Field(&f.Help, validation.By(DefaultFieldHelpValidationRule)),
+		validation.Field(&f.Tokenizer, validation.In(
+			SearchTokenizerUnicode61,
+			SearchTokenizerPorter,
+			SearchTokenizerTrigram,
+			SearchTokenizerAscii,
+		)),
+		validation.Field(
+			&f.Fields,
+			validation.Required,
+			validation.By(f.checkCollectionType(collection)),
+			validation.By(f.checkSourceFields(collection)),
+		),
+	)
+}
+
+func (f *SearchField) checkCollectionType(collection *Collection) validation.RuleFunc {
+	return func(value any) error {
+		if collection.IsView() {
+			return validation.NewError("validation_search_field_view", "Search fields are not supported in view collections.")
+		}
+
+		return nil
+	}
+}
+
+func (f *SearchField) checkSourceFields(collection *Collection) validation.RuleFunc {
+	return func(value any) error {
+		names, _ := value.([]string)
+
+		for i, name := range names {
+			if slices.Contains(names[:i], name) {
+				return validation.NewError("validation_search_field_duplicated", "Duplicated field "+name+".")
+			}
+
+			field := collection.Fields.GetByName(name)
+			if field == nil {
+				return validation.NewError("validation_search_field_missing", "Missing collection field "+name+".")
+			}
+
+			// the FTS5 virtual tables have reserved "rank" and "rowid" columns
+			if strings.EqualFold(name, "rank") || strings.EqualFold(name, "rowid") || strings.EqualFold(name, searchIndexIdColumn) {
+				return validation.NewError("validation_search_field_reserved", "Field "+name+" cannot be indexed because its name is reserved.")
+			}
+
+			if !slices.Contains(searchFieldSourceTypes, field.Type()) {
+				return validation.NewError(
+					"validation_search_field_unsupported_type",
+					"Field "+name+" is not supported (allowed types: "+strings.Join(searchFieldSourceTypes, ", ")+").",
+				)
+			}
+
+			// prevent exposing the hidden values through the match and rank results
+			isPrivateEmail := collection.IsAuth() && name == FieldNameEmail
+			if (field.GetHidden() || isPrivateEmail) && !f.Hidden {
+				return validation.NewError(
+					"validation_search_field_hidden_source",
+					"Field "+name+" could be indexed only by a hidden search field.",
+				)
+			}
+		}
+
+		return nil
+	}
+}
+
+// IndexTableName returns the name of the FTS5 virtual table of the
+// field in the context of the specified collection.
+func (f *SearchField) IndexTableName(collection *Collection) string {
+	return "_fts_" + collection.Id + "_" + f.Id
+}
+
+// indexTableSQL returns the CREATE statement of the field FTS5 virtual table.
+func (f *SearchField) indexTableSQL(collection *Collection) string {
+	tokenize := searchTokenizerOptions[f.Tokenizer]
+	if tokenize == "" {
+		tokenize = searchTokenizerOptions[SearchTokenizerUnicode61]
+	}
+
+	cols := make([]string, 0, len(f.Fields)+5)
+	cols = append(cols, "[["+searchIndexIdColumn+"]] UNINDEXED")
+	for _, name := range f.Fields {
+		cols = append(cols, "[["+name+"]]")
+	}
+	cols = append(cols, "content=''", "contentless_delete=1", "contentless_unindexed=1", "tokenize='"+tokenize+"'")
+
+	return "CREATE VIRTUAL TABLE {{" + f.IndexTableName(collection) + "}} USING fts5(" + strings.Join(cols, ", ") + ")"
+}
+
+// indexColumns returns the quoted source field columns.
+func (f *SearchField) indexColumns() string {
+	cols := make([]string, len(f.Fields))
+	for i, name := range f.Fields {
+		cols[i] = "[[" + name + "]]"
+	}
+
+	return strings.Join(cols, ", ")
+}
+
+// Interceptors
+// -------------------------------------------------------------------
+
+// Intercept implements the [RecordInterceptor] interface.
+func (f *SearchField) Intercept(
+	ctx context.Context,
+	app App,
+	record *Record,
+	actionName string,
+	actionFunc func() error,
+) error {
+	switch actionName {
+	case InterceptorActionCreateExecute, InterceptorActionUpdateExecute:
+		if err := actionFunc(); err != nil {
+			return err
+		}
+
+		return f.syncRecordIndex(app, record)
+	case InterceptorActionDeleteExecute:
+		if err := actionFunc(); err != nil {
+			return err
+		}
+
+		_, err := app.NonconcurrentDB().NewQuery(
+			"DELETE FROM {{" + f.IndexTableName(record.Collection()) + "}} WHERE [[" + searchIndexIdColumn + "]] = {:id}",
+		).Bind(dbx.Params{"id": record.Id}).Execute()
+
+		return err
+	default:
+		return actionFunc()
+	}
+}
+
+// syncRecordIndex replaces the FTS5 index entry of the specified record.
+func (f *SearchField) syncRecordIndex(app App, record *Record) error {
+	collection := record.Collection()
+	indexTable := f.IndexTableName(collection)
+	params := dbx.Params{"id": record.Id}
+
+	_, err := app.NonconcurrentDB().NewQuery(fmt.Sprintf(
+		"DELETE FROM {{%s}} WHERE [[%s]] = {:id}",
+		indexTable,
+		searchIndexIdColumn,
+	)).Bind(params).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to delete old %q search index entry: %w", f.Name, err)
+	}
+
+	_, err = app.NonconcurrentDB().NewQuery(fmt.Sprintf(
+		"INSERT INTO {{%s}} ([[%s]], %s) SELECT [[id]], %s FROM {{%s}} WHERE [[id]] = {:id}",
+		indexTable,
+		searchIndexIdColumn,
+		f.indexColumns(),
+		f.indexColumns(),
+		collection.Name,
+	)).Bind(params).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to insert %q search index entry: %w", f.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,360 @@
+package core_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+func TestSearchFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeSearch)
+}
+
+func TestSearchFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.SearchField{}
+
+	expected := ""
+
+	if v := f.ColumnType(app); v != expected {
+		t.Fatalf("Expected\n%q\ngot\n%q", expected, v)
+	}
+}
+
+func TestSearchFieldPrepareValue(t *testing.T) {
+	f := &core.SearchField{}
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	for _, raw := range []any{nil, "", "test", 123} {
+		v, err := f.PrepareValue(record, raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v != "" {
+			t.Fatalf("Expected empty string for %#v, got %#v", raw, v)
+		}
+	}
+}
+
+func TestSearchFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeSearch)
+	testDefaultFieldNameValidation(t, core.FieldTypeSearch)
+	testDefaultFieldHelpValidation[core.SearchField](t)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name         string
+		isView       bool
+		field        func() *core.SearchField
+		expectErrors []string
+	}{
+		{
+			"zero minimal",
+			false,
+			func() *core.SearchField {
+				return &core.SearchField{Id: "test", Name: "test"}
+			},
+			[]string{"fields"},
+		},
+		{
+			"view collection",
+			true,
+			func() *core.SearchField {
+				return &core.SearchField{Id: "test", Name: "test", Fields: []string{"title"}}
+			},
+			[]string{"fields"},
+		},
+		{
+			"missing field",
+			false,
+			func() *core.SearchField {
+				return &core.SearchField{Id: "test", Name: "test", Fields: []string{"title", "missing"}}
+			},
+			[]string{"fields"},
+		},
+		{
+			"duplicated field",
+			false,
+			func() *core.SearchField {
+				return &core.SearchField{Id: "test", Name: "test", Fields: []string{"title", "title"}}
+			},
+			[]string{"fields"},
+		},
+		{
+			"unsupported field type",
+			false,
+			func() *core.SearchField {
+				return &core.SearchField{Id: "test", Name: "test", Fields: []string{"title", "total"}}
+			},
+			[]string{"fields"},
+		},
+		{
+			"reserved field name",
+			false,
+			func() *core.SearchField {
+				return &core.SearchField{Id: "test", Name: "test", Fields: []string{"rank"}}
+			},
+			[]string{"fields"},
+		},
+		{
+			"hidden source field",
+			false,
+			func() *core.SearchField {
+				return &core.SearchField{Id: "test", Name: "test", Fields: []string{"title", "secret"}}
+			},
+			[]string{"fields"},
+		},
+		{
+			"hidden source field with hidden search field",
+			false,
+			func() *core.SearchField {
+				return &core.SearchField{Id: "test", Name: "test", Fields: []string{"title", "secret"}, Hidden: true}
+			},
+			[]string{},
+		},
+		{
+			"invalid tokenizer",
+			false,
+			func() *core.SearchField {
+				return &core.SearchField{Id: "test", Name: "test", Fields: []string{"title"}, Tokenizer: "invalid"}
+			},
+			[]string{"tokenizer"},
+		},
+		{
+			"valid settings",
+			false,
+			func() *core.SearchField {
+				return &core.SearchField{Id: "test", Name: "test", Fields: []string{"title", "body"}, Tokenizer: core.SearchTokenizerTrigram}
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			field := s.field()
+
+			var collection *core.Collection
+			if s.isView {
+				collection = core.NewViewCollection("test_collection")
+			} else {
+				collection = core.NewBaseCollection("test_collection")
+			}
+			collection.Fields.Add(
+				&core.TextField{Name: "title"},
+				&core.EditorField{Name: "body"},
+				&core.NumberField{Name: "total"},
+				&core.TextField{Name: "rank"},
+				&core.TextField{Name: "secret", Hidden: true},
+				field,
+			)
+
+			errs := field.ValidateSettings(context.Background(), app, collection)
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestSearchFieldValidateSettingsAuthEmail(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewAuthCollection("test_collection")
+
+	field := &core.SearchField{Id: "test", Name: "test", Fields: []string{"email"}}
+	collection.Fields.Add(field)
+
+	errs := field.ValidateSettings(context.Background(), app, collection)
+	tests.TestValidationErrors(t, errs, []string{"fields"})
+
+	field.Hidden = true
+
+	errs = field.ValidateSettings(context.Background(), app, collection)
+	tests.TestValidationErrors(t, errs, []string{})
+}
+
+func TestSearchFieldIndexSync(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_search")
+	collection.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.EditorField{Name: "body"},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	// existing records should be indexed on field create
+	existing := core.NewRecord(collection)
+	existing.Set("title", "hello world")
+	if err := app.Save(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	collection.Fields.Add(&core.SearchField{Name: "content", Fields: []string{"title", "body"}})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	field := collection.Fields.GetByName("content").(*core.SearchField)
+	indexTable := field.IndexTableName(collection)
+
+	if !app.HasTable(indexTable) {
+		t.Fatalf("Expected index table %q to be created", indexTable)
+	}
+
+	cols, err := app.TableColumns(collection.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(cols, "content") {
+		t.Fatalf("Expected no records table column for the search field, got %v", cols)
+	}
+
+	find := func(query string) []string {
+		ids := []string{}
+
+		records, err := app.FindAllRecords(collection, dbx.NewExp(
+			"[[id]] IN (SELECT [[_recordId]] FROM {{"+indexTable+"}} WHERE {{"+indexTable+"}} MATCH {:q})",
+			dbx.Params{"q": query},
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, r := range records {
+			ids = append(ids, r.Id)
+		}
+
+		return ids
+	}
+
+	if ids := find("hello"); len(ids) != 1 || ids[0] != existing.Id {
+		t.Fatalf("Expected the existing record to be indexed, got %v", ids)
+	}
+
+	// create
+	record := core.NewRecord(collection)
+	record.Set("title", "lorem")
+	record.Set("body", "<p>ipsum dolor</p>")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	if ids := find("ipsum"); len(ids) != 1 || ids[0] != record.Id {
+		t.Fatalf("Expected the new record to be indexed, got %v", ids)
+	}
+
+	// update
+	record.Set("body", "sit amet")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	if ids := find("ipsum"); len(ids) != 0 {
+		t.Fatalf("Expected the old record value to be removed from the index, got %v", ids)
+	}
+	if ids := find("amet"); len(ids) != 1 || ids[0] != record.Id {
+		t.Fatalf("Expected the updated record to be indexed, got %v", ids)
+	}
+
+	// delete
+	if err := app.Delete(record); err != nil {
+		t.Fatal(err)
+	}
+	if ids := find("lorem OR amet"); len(ids) != 0 {
+		t.Fatalf("Expected the deleted record to be removed from the index, got %v", ids)
+	}
+
+	// field remove
+	collection.Fields.RemoveByName("content")
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	if app.HasTable(indexTable) {
+		t.Fatalf("Expected index table %q to be deleted", indexTable)
+	}
+
+	// collection delete
+	collection.Fields.Add(field)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	if !app.HasTable(indexTable) {
+		t.Fatalf("Expected index table %q to be recreated", indexTable)
+	}
+	if err := app.Delete(collection); err != nil {
+		t.Fatal(err)
+	}
+	if app.HasTable(indexTable) {
+		t.Fatalf("Expected index table %q to be deleted with the collection", indexTable)
+	}
+}
+
+func TestSearchFieldResolver(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_search")
+	collection.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.SearchField{Name: "content", Fields: []string{"title"}},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, title := range []string{"apple banana cherry", "apple apple", "banana"} {
+		record := core.NewRecord(collection)
+		record.Set("title", title)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resolver := core.NewRecordFieldResolver(app, collection, nil, false)
+
+	records := []*core.Record{}
+	query := app.RecordQuery(collection)
+
+	_, err := search.NewProvider(resolver).
+		Query(query).
+		Filter([]search.FilterData{"match(content, 'apple') = true"}).
+		Sort([]search.SortField{{Name: "rank(content, 'apple')", Direction: search.SortAsc}}).
+		Exec(&records)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+
+	if v := records[0].GetString("title"); v != "apple apple" {
+		t.Fatalf("Expected the best match to be first, got %q", v)
+	}
+
+	// non search field
+	_, err = search.NewProvider(resolver).
+		Query(app.RecordQuery(collection)).
+		Filter([]search.FilterData{"match(title, 'apple') = true"}).
+		Exec(&records)
+	if err == nil {
+		t.Fatal("Expected error for non search field")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/pocketbase/dbx"
@@ -47,6 +48,10 @@ var caseInsensitiveReservedPKs = []string{
 
 const largestReservedPKLength = 4
 
+// reservedRoutePKs are the record primary keys that are reserved
+// because they match static record API routes (e.g. "/records/trash").
+var reservedRoutePKs = []string{"trash", "aggregate"}
+
 // TextField defines "text" type field for storing any string value.
 //
 // The respective zero record field value is empty string.
@@ -256,6 +261,12 @@ func (f *TextField) ValidatePlainValue(value string) error {
 		}
 	}
 
+	// the records with these ids wouldn't be reachable through the API
+	if f.PrimaryKey && slices.Contains(reservedRoutePKs, value) {
+		return validation.NewError("validation_reserved_pk", "The primary key '{{.reserved}}' is reserved and cannot be used.").
+			SetParams(map[string]any{"reserved": value})
+	}
+
 	// additional primary key checks to minimize eventual filesystem compatibility issues
 	// because the primary key is often used as a file/directory name
 	if f.PrimaryKey && f.Pattern != defaultLowercaseRecordIdPattern {
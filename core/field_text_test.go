@@ -254,6 +254,26 @@ func TestTextFieldValidateValue(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"reserved route pk literal (non-primaryKey)",
+			&core.TextField{Name: "test", PrimaryKey: false},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "trash")
+				return record
+			},
+			false,
+		},
+		{
+			"reserved route pk literal (primaryKey)",
+			&core.TextField{Name: "test", PrimaryKey: true, Pattern: "^[a-z0-9]+$"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", "aggregate")
+				return record
+			},
+			true,
+		},
 		{
 			"zero field value (primaryKey)",
 			&core.TextField{Name: "test", PrimaryKey: true},
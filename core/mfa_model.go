@@ -14,6 +14,10 @@ const (
 	MFAMethodPassword = "password"
 	MFAMethodOAuth2   = "oauth2"
 	MFAMethodOTP      = "otp"
+	MFAMethodWebAuthn = "webauthn"
+	MFAMethodTOTP     = "totp"
+	MFAMethodLDAP     = "ldap"
+	MFAMethodSAML     = "saml"
 )
 
 const CollectionNameMFAs = "_mfas"
@@ -0,0 +1,236 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+
+	"github.com/pocketbase/dbx"
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameOAuth2Clients = "_oauth2Clients"
+
+var (
+	_ Model        = (*OAuth2Client)(nil)
+	_ PreValidator = (*OAuth2Client)(nil)
+	_ RecordProxy  = (*OAuth2Client)(nil)
+)
+
+// OAuth2Client defines a Record proxy for working with the oauth2Clients collection
+// (aka. the applications registered to use the app as OAuth2/OpenID Connect provider).
+//
+// The OAuth2 client id is the same as the record id.
+type OAuth2Client struct {
+	*Record
+}
+
+// NewOAuth2Client instantiates and returns a new blank *OAuth2Client model.
+//
+// Example usage:
+//
+//	client := core.NewOAuth2Client(app)
+//	client.SetName("Internal dashboard")
+//	client.SetCollectionRef(usersCollection.Id)
+//	client.SetRedirectURIs([]string{"https://dashboard.example.com/callback"})
+//	client.SetSecret("...") // leave empty for public clients
+//	app.Save(client)
+func NewOAuth2Client(app App) *OAuth2Client {
+	m := &OAuth2Client{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameOAuth2Clients)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on OAuth2Client.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *OAuth2Client) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameOAuth2Clients {
+		return errors.New("missing or invalid OAuth2Client ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *OAuth2Client) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *OAuth2Client) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value
+// (aka. the id of the auth collection whose records could authorize the client).
+func (m *OAuth2Client) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *OAuth2Client) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// Name returns the "name" record field value.
+func (m *OAuth2Client) Name() string {
+	return m.GetString("name")
+}
+
+// SetName updates the "name" record field value.
+func (m *OAuth2Client) SetName(name string) {
+	m.Set("name", name)
+}
+
+// SetSecret sets the plain client secret that will be hashed on save.
+//
+// Clients without secret are considered public and could authenticate
+// only with their client id (and PKCE).
+func (m *OAuth2Client) SetSecret(secret string) {
+	m.Set("secret", secret)
+}
+
+// IsPublic reports whether the client doesn't have a secret.
+func (m *OAuth2Client) IsPublic() bool {
+	pv, ok := m.GetRaw("secret").(*PasswordFieldValue)
+
+	return !ok || (pv.Hash == "" && pv.Plain == "")
+}
+
+// ValidateSecret checks whether the provided plain secret matches with the client one.
+//
+// Always returns false for public clients.
+func (m *OAuth2Client) ValidateSecret(secret string) bool {
+	pv, ok := m.GetRaw("secret").(*PasswordFieldValue)
+	if !ok || secret == "" {
+		return false
+	}
+
+	return pv.Validate(secret)
+}
+
+// RedirectURIs returns the "redirectURIs" record field value.
+func (m *OAuth2Client) RedirectURIs() []string {
+	return m.GetStringSlice("redirectURIs")
+}
+
+// SetRedirectURIs updates the "redirectURIs" record field value.
+func (m *OAuth2Client) SetRedirectURIs(uris []string) {
+	m.Set("redirectURIs", uris)
+}
+
+// HasRedirectURI reports whether the provided uri exactly matches
+// one of the client registered redirect URIs.
+func (m *OAuth2Client) HasRedirectURI(uri string) bool {
+	return uri != "" && slices.Contains(m.RedirectURIs(), uri)
+}
+
+// SkipConsent returns the "skipConsent" record field value, aka. whether
+// the client is trusted and the users don't need to explicitly approve it.
+func (m *OAuth2Client) SkipConsent() bool {
+	return m.GetBool("skipConsent")
+}
+
+// SetSkipConsent updates the "skipConsent" record field value.
+func (m *OAuth2Client) SetSkipConsent(skip bool) {
+	m.Set("skipConsent", skip)
+}
+
+// Created returns the "created" record field value.
+func (m *OAuth2Client) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *OAuth2Client) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+func (app *BaseApp) registerOAuth2ClientHooks() {
+	app.OnRecordValidate(CollectionNameOAuth2Clients).Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			err := validation.Validate(
+				e.Record.GetString("collectionRef"),
+				validation.Required,
+				validation.By(validateCollectionId(e.App, CollectionTypeAuth)),
+			)
+			if err != nil {
+				return validation.Errors{"collectionRef": err}
+			}
+
+			err = validation.Validate(
+				e.Record.GetStringSlice("redirectURIs"),
+				validation.Required,
+				validation.Each(validation.By(validateOAuth2RedirectURI)),
+			)
+			if err != nil {
+				return validation.Errors{"redirectURIs": err}
+			}
+
+			return e.Next()
+		},
+		Priority: 99,
+	})
+
+	// delete the clients on auth collection delete
+	app.OnCollectionDeleteExecute().Bind(&hook.Handler[*CollectionEvent]{
+		Func: func(e *CollectionEvent) error {
+			if !e.Collection.IsAuth() {
+				return e.Next()
+			}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp App) error {
+				e.App = txApp
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				clients, err := txApp.FindAllRecords(CollectionNameOAuth2Clients, dbx.HashExp{"collectionRef": e.Collection.Id})
+				if err != nil {
+					return err
+				}
+
+				for _, client := range clients {
+					if err := txApp.Delete(client); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+}
+
+// validateOAuth2RedirectURI checks whether the value is an absolute
+// URI without fragment as required by RFC 6749 (section 3.1.2).
+//
+// Custom schemes are allowed to support native apps.
+func validateOAuth2RedirectURI(value any) error {
+	v, _ := value.(string)
+
+	u, err := url.Parse(v)
+	if err != nil || !u.IsAbs() || u.Fragment != "" || len(v) > 2000 {
+		return validation.NewError("validation_invalid_redirect_uri", "Must be an absolute URI without fragment.")
+	}
+
+	return nil
+}
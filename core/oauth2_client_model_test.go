@@ -0,0 +1,354 @@
+package core_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewOAuth2Client(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	client := core.NewOAuth2Client(app)
+
+	if client.Collection().Name != core.CollectionNameOAuth2Clients {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameOAuth2Clients, client.Collection().Name)
+	}
+}
+
+func TestOAuth2ClientProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	client := core.OAuth2Client{}
+	client.SetProxyRecord(record)
+
+	if client.ProxyRecord() == nil || client.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, client.ProxyRecord())
+	}
+}
+
+func TestOAuth2ClientStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	client := core.NewOAuth2Client(app)
+
+	fields := []struct {
+		name   string
+		getter func() string
+		setter func(string)
+	}{
+		{"collectionRef", client.CollectionRef, client.SetCollectionRef},
+		{"name", client.Name, client.SetName},
+	}
+
+	testValues := []string{"test_1", "test2", ""}
+
+	for _, f := range fields {
+		for i, testValue := range testValues {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := client.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestOAuth2ClientSkipConsent(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	client := core.NewOAuth2Client(app)
+
+	testValues := []bool{false, true}
+	for i, testValue := range testValues {
+		t.Run(fmt.Sprintf("%d_%v", i, testValue), func(t *testing.T) {
+			client.SetSkipConsent(testValue)
+
+			if v := client.SkipConsent(); v != testValue {
+				t.Fatalf("Expected getter %v, got %v", testValue, v)
+			}
+
+			if v := client.GetBool("skipConsent"); v != testValue {
+				t.Fatalf("Expected field value %v, got %v", testValue, v)
+			}
+		})
+	}
+}
+
+func TestOAuth2ClientRedirectURIs(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	client := core.NewOAuth2Client(app)
+
+	uris := []string{"https://example.com/callback", "myapp://callback"}
+	client.SetRedirectURIs(uris)
+
+	if v := client.RedirectURIs(); strings.Join(v, ",") != strings.Join(uris, ",") {
+		t.Fatalf("Expected %v, got %v", uris, v)
+	}
+
+	scenarios := []struct {
+		uri      string
+		expected bool
+	}{
+		{"", false},
+		{"https://example.com", false},
+		{"https://example.com/callback/", false},
+		{"https://example.com/callback?a=1", false},
+		{"https://example.com/callback", true},
+		{"myapp://callback", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.uri, func(t *testing.T) {
+			if v := client.HasRedirectURI(s.uri); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestOAuth2ClientSecret(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := tests.StubOAuth2ClientRecords(app); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("new client", func(t *testing.T) {
+		client := core.NewOAuth2Client(app)
+
+		if !client.IsPublic() {
+			t.Fatal("Expected client without secret to be public")
+		}
+
+		client.SetSecret("test")
+
+		if client.IsPublic() {
+			t.Fatal("Expected client with secret to be confidential")
+		}
+	})
+
+	scenarios := []struct {
+		clientId       string
+		secret         string
+		expectedPublic bool
+		expectedValid  bool
+	}{
+		{"userspublic", "", true, false},
+		{"userspublic", "secret", true, false},
+		{"usersconfidential", "", false, false},
+		{"usersconfidential", "invalid", false, false},
+		{"usersconfidential", "secret", false, true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.clientId+"_"+s.secret, func(t *testing.T) {
+			client, err := app.FindOAuth2ClientById(s.clientId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v := client.IsPublic(); v != s.expectedPublic {
+				t.Fatalf("Expected IsPublic %v, got %v", s.expectedPublic, v)
+			}
+
+			if v := client.ValidateSecret(s.secret); v != s.expectedValid {
+				t.Fatalf("Expected ValidateSecret %v, got %v", s.expectedValid, v)
+			}
+		})
+	}
+}
+
+func TestOAuth2ClientPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	clientsCol, err := app.FindCollectionByNameOrId(core.CollectionNameOAuth2Clients)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fill := func(client *core.OAuth2Client) {
+		client.SetCollectionRef(users.Id)
+		client.SetName("test")
+		client.SetRedirectURIs([]string{"https://example.com/callback"})
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		client := &core.OAuth2Client{}
+
+		if err := app.Validate(client); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-OAuth2Client collection", func(t *testing.T) {
+		client := &core.OAuth2Client{}
+		client.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		fill(client)
+
+		if err := app.Validate(client); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("OAuth2Client collection", func(t *testing.T) {
+		client := &core.OAuth2Client{}
+		client.SetProxyRecord(core.NewRecord(clientsCol))
+		fill(client)
+
+		if err := app.Validate(client); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestOAuth2ClientValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newClient := func(collection *core.Collection, redirectURIs ...string) *core.OAuth2Client {
+		client := core.NewOAuth2Client(app)
+		client.SetCollectionRef(collection.Id)
+		client.SetName("test")
+		client.SetRedirectURIs(redirectURIs)
+		return client
+	}
+
+	scenarios := []struct {
+		name           string
+		client         func() *core.OAuth2Client
+		expectedErrors []string
+	}{
+		{
+			"empty",
+			func() *core.OAuth2Client {
+				return core.NewOAuth2Client(app)
+			},
+			[]string{"collectionRef", "name", "redirectURIs"},
+		},
+		{
+			"non-auth collection",
+			func() *core.OAuth2Client {
+				return newClient(demo1, "https://example.com/callback")
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing redirect URIs",
+			func() *core.OAuth2Client {
+				return newClient(users)
+			},
+			[]string{"redirectURIs"},
+		},
+		{
+			"invalid redirect URIs",
+			func() *core.OAuth2Client {
+				return newClient(users, "https://example.com/callback", "/relative", "https://example.com/callback#fragment")
+			},
+			[]string{"redirectURIs"},
+		},
+		{
+			"valid",
+			func() *core.OAuth2Client {
+				client := newClient(users, "https://example.com/callback", "myapp://callback", "http://localhost:3000/callback?a=1")
+				client.SetSecret("test")
+				return client
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.client())
+			tests.TestValidationErrors(t, errs, s.expectedErrors)
+		})
+	}
+}
+
+func TestOAuth2ClientDeleteOnCollectionDelete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := tests.StubOAuth2ClientRecords(app); err != nil {
+		t.Fatal(err)
+	}
+
+	collection := core.NewAuthCollection("test_oauth2_clients")
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	client := core.NewOAuth2Client(app)
+	client.SetCollectionRef(collection.Id)
+	client.SetName("test")
+	client.SetRedirectURIs([]string{"https://example.com/callback"})
+	if err := app.Save(client); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.FindOAuth2ClientById(client.Id); err == nil {
+		t.Fatalf("Expected client %q to be deleted", client.Id)
+	}
+
+	// clients of the other collections should remain
+	for _, id := range []string{"usersconfidential", "superusersclient"} {
+		if _, err := app.FindOAuth2ClientById(id); err != nil {
+			t.Fatalf("Expected client %q to remain, got %v", id, err)
+		}
+	}
+}
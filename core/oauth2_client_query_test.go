@@ -0,0 +1,47 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestFindOAuth2ClientById(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := tests.StubOAuth2ClientRecords(app); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		id          string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{"usersconfidential", false},
+		{"superusersclient", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.id, func(t *testing.T) {
+			result, err := app.FindOAuth2ClientById(s.id)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.Id != s.id {
+				t.Fatalf("Expected result with id %q, got %q", s.id, result.Id)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNamePasswordHistory = "_passwordHistory"
+
+var (
+	_ Model        = (*PasswordHistory)(nil)
+	_ PreValidator = (*PasswordHistory)(nil)
+	_ RecordProxy  = (*PasswordHistory)(nil)
+)
+
+// PasswordHistory defines a Record proxy for working with the passwordHistory collection
+// (aka. the previously set record password hashes used by the password policy checks).
+type PasswordHistory struct {
+	*Record
+}
+
+// NewPasswordHistory instantiates and returns a new blank *PasswordHistory model.
+//
+// Example usage:
+//
+//	entry := core.NewPasswordHistory(app)
+//	entry.SetRecordRef(user.Id)
+//	entry.SetCollectionRef(user.Collection().Id)
+//	entry.SetField(core.FieldNamePassword)
+//	entry.SetHash(user.GetString("password:hash"))
+//	app.Save(entry)
+func NewPasswordHistory(app App) *PasswordHistory {
+	m := &PasswordHistory{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNamePasswordHistory)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on PasswordHistory.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *PasswordHistory) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNamePasswordHistory {
+		return errors.New("missing or invalid PasswordHistory ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *PasswordHistory) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *PasswordHistory) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *PasswordHistory) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *PasswordHistory) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *PasswordHistory) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *PasswordHistory) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Field returns the "field" record field value
+// (the name of the password field the hash belongs to).
+func (m *PasswordHistory) Field() string {
+	return m.GetString("field")
+}
+
+// SetField updates the "field" record field value.
+func (m *PasswordHistory) SetField(name string) {
+	m.Set("field", name)
+}
+
+// Hash returns the "hash" record field value
+// (the bcrypt hash of the previously set password).
+func (m *PasswordHistory) Hash() string {
+	return m.GetString("hash")
+}
+
+// SetHash updates the "hash" record field value.
+func (m *PasswordHistory) SetHash(hash string) {
+	m.Set("hash", hash)
+}
+
+// Created returns the "created" record field value
+// (aka. when the password was set).
+func (m *PasswordHistory) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *PasswordHistory) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+// ValidatePassword checks whether the provided plain password matches with the stored hash.
+func (m *PasswordHistory) ValidatePassword(password string) bool {
+	return PasswordFieldValue{Hash: m.Hash()}.Validate(password)
+}
+
+func (app *BaseApp) registerPasswordHistoryHooks() {
+	recordRefHooks[*PasswordHistory](app, CollectionNamePasswordHistory)
+}
@@ -0,0 +1,238 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestNewPasswordHistory(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	history := core.NewPasswordHistory(app)
+
+	if history.Collection().Name != core.CollectionNamePasswordHistory {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNamePasswordHistory, history.Collection().Name)
+	}
+}
+
+func TestPasswordHistoryProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	history := core.PasswordHistory{}
+	history.SetProxyRecord(record)
+
+	if history.ProxyRecord() == nil || history.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, history.ProxyRecord())
+	}
+}
+
+func TestPasswordHistoryStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	history := core.NewPasswordHistory(app)
+
+	fields := []struct {
+		name   string
+		getter func() string
+		setter func(string)
+	}{
+		{"collectionRef", history.CollectionRef, history.SetCollectionRef},
+		{"recordRef", history.RecordRef, history.SetRecordRef},
+		{"field", history.Field, history.SetField},
+		{"hash", history.Hash, history.SetHash},
+	}
+
+	testValues := []string{"test_1", "test2", ""}
+
+	for _, f := range fields {
+		for i, testValue := range testValues {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := history.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestPasswordHistoryCreated(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	history := core.NewPasswordHistory(app)
+
+	if v := history.Created().String(); v != "" {
+		t.Fatalf("Expected empty created, got %q", v)
+	}
+
+	now := types.NowDateTime()
+	history.SetRaw("created", now)
+
+	if v := history.Created().String(); v != now.String() {
+		t.Fatalf("Expected %q created, got %q", now.String(), v)
+	}
+}
+
+func TestPasswordHistoryUpdated(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	history := core.NewPasswordHistory(app)
+
+	if v := history.Updated().String(); v != "" {
+		t.Fatalf("Expected empty updated, got %q", v)
+	}
+
+	now := types.NowDateTime()
+	history.SetRaw("updated", now)
+
+	if v := history.Updated().String(); v != now.String() {
+		t.Fatalf("Expected %q updated, got %q", now.String(), v)
+	}
+}
+
+func TestPasswordHistoryValidatePassword(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("1234567890"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history := core.NewPasswordHistory(app)
+
+	if history.ValidatePassword("") {
+		t.Fatal("Expected empty hash to fail the validation")
+	}
+
+	history.SetHash(string(hash))
+
+	scenarios := []struct {
+		password string
+		expected bool
+	}{
+		{"", false},
+		{"123456789", false},
+		{"1234567890", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.password, func(t *testing.T) {
+			if v := history.ValidatePassword(s.password); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestPasswordHistoryPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	historyCollection, err := app.FindCollectionByNameOrId(core.CollectionNamePasswordHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		history := &core.PasswordHistory{}
+
+		if err := app.Validate(history); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-PasswordHistory collection", func(t *testing.T) {
+		history := &core.PasswordHistory{}
+		history.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		history.SetCollectionRef(user.Collection().Id)
+		history.SetRecordRef(user.Id)
+		history.SetField("password")
+		history.SetHash("test")
+
+		if err := app.Validate(history); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("PasswordHistory collection", func(t *testing.T) {
+		history := &core.PasswordHistory{}
+		history.SetProxyRecord(core.NewRecord(historyCollection))
+		history.SetCollectionRef(user.Collection().Id)
+		history.SetRecordRef(user.Id)
+		history.SetField("password")
+		history.SetHash("test")
+
+		if err := app.Validate(history); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestPasswordHistoryDeleteOnRecordDelete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history := core.NewPasswordHistory(app)
+	history.SetCollectionRef(user.Collection().Id)
+	history.SetRecordRef(user.Id)
+	history.SetField("password")
+	history.SetHash("test")
+	if err := app.Save(history); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(user); err != nil {
+		t.Fatal(err)
+	}
+
+	models, err := app.FindPasswordHistoryByRecord(user, "password", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(models) != 0 {
+		t.Fatalf("Expected the user password history to be deleted, got %d entries", len(models))
+	}
+}
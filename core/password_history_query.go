@@ -0,0 +1,65 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/pocketbase/dbx"
+)
+
+// FindPasswordHistoryByRecord returns the latest (in DESC order) PasswordHistory
+// models linked to the provided record and password field name.
+//
+// Set limit to <= 0 to return all matching models.
+func (app *BaseApp) FindPasswordHistoryByRecord(record *Record, fieldName string, limit int) ([]*PasswordHistory, error) {
+	result := []*PasswordHistory{}
+
+	query := app.RecordQuery(CollectionNamePasswordHistory).
+		AndWhere(dbx.HashExp{
+			"collectionRef": record.Collection().Id,
+			"recordRef":     record.Id,
+			"field":         fieldName,
+		}).
+		OrderBy("created DESC", "rowid DESC")
+
+	if limit > 0 {
+		query.Limit(int64(limit))
+	}
+
+	err := query.All(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteOldPasswordHistory deletes the PasswordHistory models linked to the
+// provided record and password field name, keeping only the latest "keep" ones.
+//
+// Returns a combined error with the failed deletes.
+func (app *BaseApp) DeleteOldPasswordHistory(record *Record, fieldName string, keep int) error {
+	models, err := app.FindPasswordHistoryByRecord(record, fieldName, 0)
+	if err != nil {
+		return err
+	}
+
+	if keep < 0 {
+		keep = 0
+	}
+
+	if len(models) <= keep {
+		return nil
+	}
+
+	var errs []error
+	for _, m := range models[keep:] {
+		if err := app.Delete(m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
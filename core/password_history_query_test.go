@@ -0,0 +1,159 @@
+package core_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// stubPasswordHistory creates the following test password history entries
+// (ordered from the oldest to the newest):
+//   - "user1_history1" (test@example.com, password, created 3 hours ago)
+//   - "user1_history2" (test@example.com, password, created 2 hours ago)
+//   - "user1_history3" (test@example.com, password, created 1 hour ago)
+//   - "user1_other" (test@example.com, other, created now)
+//   - "user2_history1" (test2@example.com, password, created now)
+func stubPasswordHistory(t *testing.T, app core.App) {
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := types.NowDateTime()
+
+	stubs := []struct {
+		id      string
+		record  *core.Record
+		field   string
+		created types.DateTime
+	}{
+		{"user1_history1", user1, "password", now.Add(-3 * time.Hour)},
+		{"user1_history2", user1, "password", now.Add(-2 * time.Hour)},
+		{"user1_history3", user1, "password", now.Add(-1 * time.Hour)},
+		{"user1_other", user1, "other", now},
+		{"user2_history1", user2, "password", now},
+	}
+
+	for _, s := range stubs {
+		history := core.NewPasswordHistory(app)
+		history.Id = s.id
+		history.SetCollectionRef(s.record.Collection().Id)
+		history.SetRecordRef(s.record.Id)
+		history.SetField(s.field)
+		history.SetHash("test")
+		if err := app.SaveNoValidate(history); err != nil {
+			t.Fatal(err)
+		}
+
+		// manually update the autodate field
+		_, err := app.DB().Update(core.CollectionNamePasswordHistory, dbx.Params{"created": s.created}, dbx.HashExp{"id": s.id}).Execute()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindPasswordHistoryByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubPasswordHistory(t, app)
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user3, err := app.FindAuthRecordByEmail("users", "test3@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		record      *core.Record
+		field       string
+		limit       int
+		expectedIds []string
+	}{
+		{"no history", user3, "password", 0, []string{}},
+		{"missing field", user1, "missing", 0, []string{}},
+		{"all entries", user1, "password", 0, []string{"user1_history3", "user1_history2", "user1_history1"}},
+		{"limit", user1, "password", 2, []string{"user1_history3", "user1_history2"}},
+		{"other field", user1, "other", -1, []string{"user1_other"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			models, err := app.FindPasswordHistoryByRecord(s.record, s.field, s.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ids := make([]string, len(models))
+			for i, m := range models {
+				ids[i] = m.Id
+			}
+
+			if !slices.Equal(ids, s.expectedIds) {
+				t.Fatalf("Expected ids\n%v\ngot\n%v", s.expectedIds, ids)
+			}
+		})
+	}
+}
+
+func TestDeleteOldPasswordHistory(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name               string
+		keep               int
+		expectedDeletedIds []string
+	}{
+		{"keep more than existing", 5, []string{}},
+		{"keep 2", 2, []string{"user1_history1"}},
+		{"keep 0", 0, []string{"user1_history1", "user1_history2", "user1_history3"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			stubPasswordHistory(t, app)
+
+			user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			deletedIds := []string{}
+			app.OnRecordAfterDeleteSuccess().BindFunc(func(e *core.RecordEvent) error {
+				deletedIds = append(deletedIds, e.Record.Id)
+				return e.Next()
+			})
+
+			if err := app.DeleteOldPasswordHistory(user, "password", s.keep); err != nil {
+				t.Fatal(err)
+			}
+
+			slices.Sort(deletedIds)
+
+			if !slices.Equal(deletedIds, s.expectedDeletedIds) {
+				t.Fatalf("Expected deleted ids\n%v\ngot\n%v", s.expectedDeletedIds, deletedIds)
+			}
+		})
+	}
+}
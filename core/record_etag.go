@@ -0,0 +1,168 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+)
+
+// ErrRecordETagMismatch is returned on record update or delete when
+// the stored record state doesn't match any of the [Record.IfMatch] entity tags.
+var ErrRecordETagMismatch = errors.New("the record was modified since it was last loaded")
+
+// ErrRecordETagUnavailable is returned on record update or delete with [Record.IfMatch]
+// entity tags (other than "*") when the stored record state cannot be identified
+// (see [Record.ETag]).
+var ErrRecordETagUnavailable = errors.New("the record state cannot be identified because the collection has hidden fields and no onUpdate autodate field")
+
+// ETag returns a strong entity tag (double quoted) derived from the
+// record collection, the fields data that are exported by default with
+// [Record.PublicExport] (aka. without the hidden fields and with the auth
+// email only if it is public) and the "onUpdate" autodate fields values
+// (even if hidden).
+//
+// The hidden values are not part of the ETag to prevent guessing them
+// but their changes are still reflected via the "onUpdate" autodate fields.
+// Because of this, if the record has hidden data and its collection doesn't
+// have an "onUpdate" autodate field, ETag returns an empty string
+// (aka. the record state cannot be reliably identified and the
+// [Record.IfMatch] conditions other than "*" fail with [ErrRecordETagUnavailable]).
+//
+// The record visibility options (Hide, Unhide, IgnoreEmailVisibility, etc.)
+// are not taken into account so that the ETag of the stored record state
+// is the same regardless of how the record was loaded.
+//
+// For a record loaded from the database (or after successful save)
+// the ETag identifies the stored record state.
+//
+// Returns an empty string also if the record data cannot be serialized.
+func (m *Record) ETag() string {
+	data, err := m.dbExport()
+	if err != nil {
+		return ""
+	}
+
+	isAuth := m.Collection().IsAuth()
+
+	var hasHidden, hasOnUpdate bool
+
+	for _, f := range m.Collection().Fields {
+		name := f.GetName()
+
+		if autodate, ok := f.(*AutodateField); ok && autodate.OnUpdate {
+			hasOnUpdate = true
+			continue
+		}
+
+		if f.GetHidden() ||
+			(isAuth && (name == FieldNamePassword ||
+				name == FieldNameTokenKey ||
+				(name == FieldNameEmail && !m.GetBool(FieldNameEmailVisibility)))) {
+			hasHidden = true
+			delete(data, name)
+		}
+	}
+
+	if hasHidden && !hasOnUpdate {
+		return ""
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return ""
+	}
+
+	h := sha256.New()
+	h.Write([]byte(m.Collection().Id))
+	h.Write([]byte{0})
+	h.Write(raw)
+
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// IfMatch sets the entity tag(s) that the stored record state must match
+// in order an update or delete of the record to succeed (aka. optimistic concurrency control).
+//
+// The special "*" entity tag matches any existing record.
+// Call it without arguments to reset the condition.
+//
+// Weak entity tags (e.g. W/"abc") are compared as their strong version
+// because some proxies weaken the ETag header of compressed responses.
+//
+// On mismatch [App.Save] and [App.Delete] return [ErrRecordETagMismatch]
+// (or [ErrRecordETagUnavailable] if the stored record state doesn't have an ETag).
+//
+// Note that the condition is checked on every Save/Delete call, so when
+// saving the same Record instance multiple times you may have to
+// update it with the latest ETag, e.g.:
+//
+//	record, _ := app.FindRecordById("articles", "RECORD_ID")
+//	record.IfMatch(record.ETag())
+//	record.Set("title", "example")
+//	err := app.Save(record) // ErrRecordETagMismatch if the record was changed meanwhile
+func (m *Record) IfMatch(etags ...string) *Record {
+	m.ifMatch = etags
+	return m
+}
+
+// onRecordIfMatchExecute wraps the record update/delete execute fn
+// with a transactional check of the stored record entity tag.
+func onRecordIfMatchExecute(e *RecordEvent, fn func(e *RecordEvent) error) error {
+	etags := e.Record.ifMatch
+
+	// reset to prevent recursion and restore after the execution
+	e.Record.ifMatch = nil
+	defer func() {
+		e.Record.ifMatch = etags
+	}()
+
+	originalApp := e.App
+	txErr := e.App.RunInTransaction(func(txApp App) error {
+		e.App = txApp
+
+		lastSaved := &Record{}
+		err := txApp.RecordQueryWithTrashed(e.Record.Collection()).
+			AndWhere(dbx.HashExp{e.Record.Collection().Name + "." + FieldNameId: e.Record.LastSavedPK()}).
+			Limit(1).
+			One(lastSaved)
+		if err != nil {
+			return err
+		}
+
+		current := lastSaved.ETag()
+
+		// explicitly reject the conditions that cannot be evaluated
+		if current == "" && !slices.Contains(etags, "*") {
+			return ErrRecordETagUnavailable
+		}
+
+		if !etagsMatch(etags, current) {
+			return ErrRecordETagMismatch
+		}
+
+		return fn(e)
+	})
+	e.App = originalApp
+
+	return txErr
+}
+
+// etagsMatch reports whether any of the provided entity tags matches the current one.
+func etagsMatch(etags []string, current string) bool {
+	for _, etag := range etags {
+		if etag == "*" {
+			return true
+		}
+
+		if current != "" && strings.TrimPrefix(etag, "W/") == current {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,261 @@
+package core_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestRecordETag(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.FindRecordById("demo2", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	etag := record.ETag()
+	if len(etag) != 34 || !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) {
+		t.Fatalf("Expected quoted 32 chars etag, got %q", etag)
+	}
+
+	if v := record.ETag(); v != etag {
+		t.Fatalf("Expected the same etag %q, got %q", etag, v)
+	}
+
+	record.Set("title", "new")
+	changedETag := record.ETag()
+	if changedETag == etag {
+		t.Fatal("Expected the etag to change")
+	}
+
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh, err := app.FindRecordById("demo2", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := fresh.ETag(); v != record.ETag() {
+		t.Fatalf("Expected the saved record etag %q to match the fresh one %q", record.ETag(), v)
+	}
+}
+
+func TestRecordETagHiddenFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.SetEmailVisibility(false)
+
+	etag := record.ETag()
+
+	record.SetPassword("1234567890")
+	record.RefreshTokenKey()
+	record.SetEmail("changed@example.com")
+	record.Unhide(record.Collection().Fields.FieldNames()...)
+	record.IgnoreEmailVisibility(true)
+
+	if v := record.ETag(); v != etag {
+		t.Fatalf("Expected the hidden fields changes to not affect the etag %q, got %q", etag, v)
+	}
+
+	record.SetRaw("updated", types.NowDateTime())
+	if v := record.ETag(); v == etag {
+		t.Fatal("Expected the updated field change to affect the etag")
+	}
+}
+
+func TestRecordETagWithoutOnUpdateAutodate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("etag_test")
+	collection.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("title", "test")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if record.ETag() == "" {
+		t.Fatal("Expected non-empty etag for a record without hidden fields")
+	}
+
+	// hide the field
+	collection.Fields.GetByName("title").SetHidden(true)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := record.ETag(); v != "" {
+		t.Fatalf("Expected empty etag for a record with hidden fields and no onUpdate autodate field, got %q", v)
+	}
+
+	// the hidden values cannot be compared so only the wildcard should match
+	record.Set("title", "new")
+	if err := app.Save(record.IfMatch(record.ETag())); !errors.Is(err, core.ErrRecordETagUnavailable) {
+		t.Fatalf("Expected ErrRecordETagUnavailable, got %v", err)
+	}
+	if err := app.Save(record.IfMatch(`"abc"`)); !errors.Is(err, core.ErrRecordETagUnavailable) {
+		t.Fatalf("Expected ErrRecordETagUnavailable, got %v", err)
+	}
+
+	if err := app.Save(record.IfMatch("*")); err != nil {
+		t.Fatalf("Expected the wildcard save to succeed, got %v", err)
+	}
+}
+
+func TestRecordIfMatch(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name        string
+		etags       func(record *core.Record) []string
+		delete      bool
+		expectError bool
+	}{
+		{
+			"update with matching etag",
+			func(record *core.Record) []string { return []string{record.ETag()} },
+			false,
+			false,
+		},
+		{
+			"update with one of multiple matching etags",
+			func(record *core.Record) []string { return []string{`"invalid"`, record.ETag()} },
+			false,
+			false,
+		},
+		{
+			"update with weak matching etag",
+			func(record *core.Record) []string { return []string{"W/" + record.ETag()} },
+			false,
+			false,
+		},
+		{
+			"update with wildcard etag",
+			func(record *core.Record) []string { return []string{"*"} },
+			false,
+			false,
+		},
+		{
+			"update with mismatched etag",
+			func(record *core.Record) []string { return []string{`"invalid"`} },
+			false,
+			true,
+		},
+		{
+			"delete with matching etag",
+			func(record *core.Record) []string { return []string{record.ETag()} },
+			true,
+			false,
+		},
+		{
+			"delete with mismatched etag",
+			func(record *core.Record) []string { return []string{`"invalid"`} },
+			true,
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			record, err := app.FindRecordById("demo2", "llvuca81nly1qls")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			record.IfMatch(s.etags(record)...)
+
+			if s.delete {
+				err = app.Delete(record)
+			} else {
+				record.Set("title", "new")
+				err = app.Save(record)
+			}
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr && !errors.Is(err, core.ErrRecordETagMismatch) {
+				t.Fatalf("Expected ErrRecordETagMismatch, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRecordIfMatchConcurrentChange(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	editorA, err := app.FindRecordById("demo2", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	editorA.IfMatch(editorA.ETag())
+
+	editorB, err := app.FindRecordById("demo2", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	editorB.IfMatch(editorB.ETag())
+
+	editorA.Set("title", "aa")
+	if err := app.Save(editorA); err != nil {
+		t.Fatalf("Expected the first save to succeed, got %v", err)
+	}
+
+	editorB.Set("title", "bb")
+	if err := app.Save(editorB); !errors.Is(err, core.ErrRecordETagMismatch) {
+		t.Fatalf("Expected ErrRecordETagMismatch, got %v", err)
+	}
+
+	// refresh the condition
+	editorA.IfMatch(editorA.ETag())
+	editorA.Set("title", "aa2")
+	if err := app.Save(editorA); err != nil {
+		t.Fatalf("Expected the refreshed condition save to succeed, got %v", err)
+	}
+
+	// reset the condition
+	editorB.IfMatch()
+	if err := app.Save(editorB); err != nil {
+		t.Fatalf("Expected the unconditional save to succeed, got %v", err)
+	}
+}
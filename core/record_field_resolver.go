@@ -99,6 +99,7 @@ func NewRecordFieldResolver(
 			`^\@request\.context$`,
 			`^\@request\.method$`,
 			`^\@request\.auth\.[\w\.\:]*\w+$`,
+			`^\@request\.apiKey\.[\w\.\:]*\w+$`,
 			`^\@request\.body\.[\w\.\:]*\w+$`,
 			`^\@request\.query\.[\w\.\:]*\w+$`,
 			`^\@request\.headers\.[\w\.\:]*\w+$`,
@@ -121,6 +122,10 @@ func NewRecordFieldResolver(
 				IgnoreEmailVisibility(true).
 				PublicExport()
 		}
+		r.staticRequestInfo["apiKey"] = nil
+		if r.requestInfo.APIKey != nil {
+			r.staticRequestInfo["apiKey"] = r.requestInfo.APIKey.PublicExport()
+		}
 	}
 
 	return r
@@ -294,6 +299,7 @@ func preferGroupBy(info *dbx.QueryInfo, fullUnquotedGroupByCol string) bool {
 //	@request.query.filter
 //	@request.headers.x_token
 //	@request.auth.someRelation.name
+//	@request.apiKey.name
 //	@request.body.someRelation.name
 //	@request.body.someField
 //	@request.body.someSelect:each
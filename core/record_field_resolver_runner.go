@@ -819,6 +819,21 @@ func (r *runner) finalizeActivePropsProcessing(collection *Collection, prop stri
 		result.MultiMatchSubQuery = r.multiMatch
 	}
 
+	// attach the full-text index of the "search" fields
+	// (the field doesn't have a table column and can be used only with the match/rank functions)
+	if searchField, ok := field.(*SearchField); ok {
+		result.Identifier = "''"
+		if r.withMultiMatch {
+			r.multiMatch.ValueIdentifier = "''"
+		}
+		result.NullFallback = search.NullFallbackDisabled
+		result.FullTextIndex = &search.FullTextIndex{
+			TableName:     searchField.IndexTableName(collection),
+			KeyColumn:     searchIndexIdColumn,
+			KeyIdentifier: "[[" + r.activeTableAlias + ".id]]",
+		}
+	}
+
 	// allow querying only auth records with emails marked as public
 	if field.GetName() == FieldNameEmail && !r.resolver.allowHiddenFields && collection.IsAuth() {
 		result.AfterBuild = func(expr dbx.Expression) dbx.Expression {
@@ -28,8 +28,8 @@ func TestRecordFieldResolverAllowedFields(t *testing.T) {
 	r := core.NewRecordFieldResolver(app, collection, nil, false)
 
 	fields := r.AllowedFields()
-	if len(fields) != 8 {
-		t.Fatalf("Expected %d original allowed fields, got %d", 8, len(fields))
+	if len(fields) != 9 {
+		t.Fatalf("Expected %d original allowed fields, got %d", 9, len(fields))
 	}
 
 	// change the allowed fields
@@ -835,6 +835,12 @@ func TestRecordFieldResolverResolveStaticRequestInfoFields(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	apiKey := core.NewAPIKey(app)
+	apiKey.Id = "test_api_key"
+	apiKey.SetName("test_name")
+	apiKey.SetScopes([]string{"demo1:list"})
+	apiKey.Set("keyHash", "test_hash")
+
 	requestInfo := &core.RequestInfo{
 		Context: "ctx",
 		Method:  "get",
@@ -854,7 +860,8 @@ func TestRecordFieldResolverResolveStaticRequestInfoFields(t *testing.T) {
 		Headers: map[string]string{
 			"d": "789",
 		},
-		Auth: authRecord,
+		Auth:   authRecord,
+		APIKey: apiKey,
 	}
 
 	r := core.NewRecordFieldResolver(app, collection, requestInfo, true)
@@ -891,6 +898,11 @@ func TestRecordFieldResolverResolveStaticRequestInfoFields(t *testing.T) {
 		{"@request.auth.emailVisibility", false, `false`},
 		{"@request.auth.email", false, `"test@example.com"`}, // should always be returned no matter of the emailVisibility state
 		{"@request.auth.missing", false, `NULL`},
+		{"@request.apiKey", true, ""},
+		{"@request.apiKey.id", false, `"test_api_key"`},
+		{"@request.apiKey.name", false, `"test_name"`},
+		{"@request.apiKey.scopes", false, `"[\"demo1:list\"]"`},
+		{"@request.apiKey.keyHash", false, `NULL`}, // hidden
 		{"@request.body.raw_json_simple", false, `"123"`},
 		{"@request.body.raw_json_simple.a", false, `NULL`},
 		{"@request.body.raw_json_obj.a", false, `123`},
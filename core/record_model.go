@@ -15,10 +15,8 @@ import (
 	"github.com/pocketbase/dbx"
 	validation "github.com/pocketbase/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/core/validators"
-	"github.com/pocketbase/pocketbase/tools/dbutils"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/hook"
-	"github.com/pocketbase/pocketbase/tools/inflector"
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/store"
 	"github.com/pocketbase/pocketbase/tools/types"
@@ -48,6 +46,10 @@ type Record struct {
 	exportCustomData      bool
 	ignoreEmailVisibility bool
 	ignoreUnchangedFields bool
+	ignoreSoftDelete      bool
+	softDeleted           bool // whether the last delete was performed as soft delete
+
+	ifMatch []string
 }
 
 const systemHookIdRecord = "__pbRecordSystemHook__"
@@ -656,6 +658,8 @@ func (m *Record) Clone() *Record {
 	newRecord.exportCustomData = m.exportCustomData
 	newRecord.ignoreEmailVisibility = m.ignoreEmailVisibility
 	newRecord.ignoreUnchangedFields = m.ignoreUnchangedFields
+	newRecord.ignoreSoftDelete = m.ignoreSoftDelete
+	newRecord.ifMatch = slices.Clone(m.ifMatch)
 	newRecord.customVisibility.Reset(m.customVisibility.GetAll())
 
 	data := m.data.GetAll()
@@ -1130,6 +1134,10 @@ func (m *Record) dbExport() (map[string]any, error) {
 
 	var fieldName string
 	for _, field := range fields {
+		if !hasRecordTableColumn(field) {
+			continue
+		}
+
 		fieldName = field.GetName()
 
 		if f, ok := field.(DriverValuer); ok {
@@ -1427,6 +1435,10 @@ func onRecordValidate(e *RecordEvent) error {
 }
 
 func onRecordSaveExecute(e *RecordEvent) error {
+	if len(e.Record.ifMatch) > 0 && !e.Record.IsNew() {
+		return onRecordIfMatchExecute(e, onRecordSaveExecute)
+	}
+
 	if e.Record.Collection().IsAuth() {
 		if !e.Record.IsNew() {
 			lastSavedRecord, err := e.App.FindRecordById(e.Record.Collection(), e.Record.Id)
@@ -1474,6 +1486,10 @@ func onRecordSaveExecute(e *RecordEvent) error {
 }
 
 func onRecordDeleteExecute(e *RecordEvent) error {
+	if len(e.Record.ifMatch) > 0 {
+		return onRecordIfMatchExecute(e, onRecordDeleteExecute)
+	}
+
 	// fetch rel references (if any)
 	//
 	// note: the select is outside of the transaction to minimize
@@ -1483,6 +1499,12 @@ func onRecordDeleteExecute(e *RecordEvent) error {
 		return err
 	}
 
+	if e.Record.shouldSoftDelete() {
+		return onRecordSoftDeleteExecute(e, refs)
+	}
+
+	e.Record.softDeleted = false
+
 	originalApp := e.App
 	txErr := e.App.RunInTransaction(func(txApp App) error {
 		e.App = txApp
@@ -1504,44 +1526,14 @@ func onRecordDeleteExecute(e *RecordEvent) error {
 //
 // NB! This method is expected to be called from inside of a transaction.
 func cascadeRecordDelete(app App, mainRecord *Record, refs map[*Collection][]Field) error {
-	// Sort the refs keys to ensure that the cascade events firing order is always the same.
-	// This is not necessary for the operation to function correctly but it helps having deterministic output during testing.
-	sortedRefKeys := make([]*Collection, 0, len(refs))
-	for k := range refs {
-		sortedRefKeys = append(sortedRefKeys, k)
-	}
-	sort.Slice(sortedRefKeys, func(i, j int) bool {
-		return sortedRefKeys[i].Name < sortedRefKeys[j].Name
-	})
-
-	for _, refCollection := range sortedRefKeys {
-		fields, ok := refs[refCollection]
-
-		if !ok || refCollection.IsView() {
-			continue // skip missing or view collections
+	for _, refCollection := range sortedRefCollections(refs) {
+		if refCollection.IsView() {
+			continue // skip view collections
 		}
 
-		recordTableName := inflector.Columnify(refCollection.Name)
-
-		for _, field := range fields {
-			prefixedFieldName := recordTableName + "." + inflector.Columnify(field.GetName())
-
-			query := app.RecordQuery(refCollection)
-
-			if opt, ok := field.(MultiValuer); !ok || !opt.IsMultiple() {
-				query.AndWhere(dbx.HashExp{prefixedFieldName: mainRecord.Id})
-			} else {
-				query.AndWhere(dbx.Exists(dbx.NewExp(fmt.Sprintf(
-					`SELECT 1 FROM %s {{__je__}} WHERE [[__je__.value]]={:jevalue}`,
-					dbutils.JSONEach(prefixedFieldName),
-				), dbx.Params{
-					"jevalue": mainRecord.Id,
-				})))
-			}
-
-			if refCollection.Id == mainRecord.Collection().Id {
-				query.AndWhere(dbx.Not(dbx.HashExp{recordTableName + ".id": mainRecord.Id}))
-			}
+		for _, field := range refs[refCollection] {
+			// include the trashed references since the main record is permanently deleted
+			query := refRecordsQuery(app.RecordQueryWithTrashed(refCollection), refCollection, field, mainRecord)
 
 			// trigger cascade for each batchSize rel items until there is none
 			batchSize := 4000
@@ -1597,8 +1589,11 @@ func deleteRefRecords(app App, mainRecord *Record, refRecords []*Record, field F
 
 		// cascade delete the reference
 		// (only if there are no other active references in case of multiple select)
+		//
+		// note: the reference is permanently deleted even if its collection has soft delete enabled
+		// because it wouldn't be possible to restore it without the main record
 		if relField.CascadeDelete && len(ids) == 0 {
-			if err := app.Delete(refRecord); err != nil {
+			if err := app.Delete(refRecord.IgnoreSoftDelete(true)); err != nil {
 				return err
 			}
 			// no further actions are needed (the reference is deleted)
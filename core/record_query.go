@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/tools/dbutils"
 	"github.com/pocketbase/pocketbase/tools/inflector"
@@ -23,7 +24,21 @@ var recordProxyType = reflect.TypeOf((*RecordProxy)(nil)).Elem()
 // In case a collection id or name is provided and that collection doesn't
 // actually exists, the generated query will be created with a cancelled context
 // and will fail once an executor (Row(), One(), All(), etc.) is called.
+//
+// If the collection has soft delete enabled, the trashed records are excluded
+// from the query results with an extra WHERE condition
+// (note that calling query.Where() replaces it; use query.AndWhere() instead).
 func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuery {
+	return app.recordQuery(collectionModelOrIdentifier, false)
+}
+
+// RecordQueryWithTrashed is similar to [BaseApp.RecordQuery] but
+// the returned query includes also the soft deleted (aka. trashed) records.
+func (app *BaseApp) RecordQueryWithTrashed(collectionModelOrIdentifier any) *dbx.SelectQuery {
+	return app.recordQuery(collectionModelOrIdentifier, true)
+}
+
+func (app *BaseApp) recordQuery(collectionModelOrIdentifier any, withTrashed bool) *dbx.SelectQuery {
 	var tableName string
 
 	collection, collectionErr := getCollectionByModelOrIdentifier(app, collectionModelOrIdentifier)
@@ -37,6 +52,10 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 
 	query := app.ConcurrentDB().Select(app.ConcurrentDB().QuoteSimpleColumnName(tableName) + ".*").From(tableName)
 
+	if !withTrashed && collection != nil && collection.IsBase() && collection.SoftDelete.Enabled {
+		query.AndWhere(dbx.HashExp{tableName + "." + FieldNameDeleted: ""})
+	}
+
 	// in case of an error attach a new context and cancel it immediately with the error
 	if collectionErr != nil {
 		ctx, cancelFunc := context.WithCancelCause(context.Background())
@@ -477,6 +496,9 @@ func (app *BaseApp) CountRecords(collectionModelOrIdentifier any, exprs ...dbx.E
 // FindAuthRecordByToken finds the auth record associated with the provided JWT
 // (auth, file, verifyEmail, changeEmail, passwordReset types).
 //
+// Auth tokens bound to an auth session (see [Record.NewSessionAuthToken])
+// are rejected with [ErrRevokedSession] if the session no longer exists.
+//
 // Optionally specify a list of validTypes to check tokens only from those types.
 //
 // Returns an error if the JWT is invalid, expired or not associated to an auth collection record.
@@ -514,7 +536,7 @@ func (app *BaseApp) FindAuthRecordByToken(token string, validTypes ...string) (*
 
 	var baseTokenKey string
 	switch tokenType {
-	case TokenTypeAuth:
+	case TokenTypeAuth, TokenTypeOAuth2Access, TokenTypeOAuth2Refresh:
 		baseTokenKey = record.Collection().AuthToken.Secret
 	case TokenTypeFile:
 		baseTokenKey = record.Collection().FileToken.Secret
@@ -528,17 +550,67 @@ func (app *BaseApp) FindAuthRecordByToken(token string, validTypes ...string) (*
 		return nil, errors.New("unknown token type " + tokenType)
 	}
 
-	secret := record.TokenKey() + baseTokenKey
-
 	// verify token signature
-	_, err = security.ParseJWT(token, secret)
+	header, err := security.ParseUnverifiedJWTHeader(token)
 	if err != nil {
 		return nil, err
 	}
+	switch alg, _ := header["alg"].(string); alg {
+	case security.SigningAlgRS256, security.SigningAlgEdDSA:
+		kid, _ := header["kid"].(string)
+		err = app.verifySignedAuthToken(record, token, tokenType, kid, unverifiedClaims)
+	default:
+		_, err = security.ParseJWT(token, record.TokenKey()+baseTokenKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// check whether the auth session is still active
+	if sessionId, _ := unverifiedClaims[TokenClaimSessionId].(string); sessionId != "" && tokenType == TokenTypeAuth {
+		exists, err := app.authSessionExists(record, sessionId)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrRevokedSession
+		}
+	}
 
 	return record, nil
 }
 
+// verifySignedAuthToken verifies the signature of an asymmetric signed
+// auth token with the matching app signing key and checks whether it is
+// still bound to the current record auth token secrets.
+func (app *BaseApp) verifySignedAuthToken(record *Record, token string, tokenType string, kid string, claims jwt.MapClaims) error {
+	if tokenType != TokenTypeAuth {
+		return errors.New("asymmetric signatures are supported only for auth tokens")
+	}
+
+	key, ok := app.Settings().SigningKeys.FindKey(kid)
+	if !ok {
+		return errors.New("unknown or expired signing key")
+	}
+
+	publicKey, err := app.signingPublicKey(key)
+	if err != nil {
+		return errors.Join(ErrMissingSigningKey, err)
+	}
+
+	_, err = security.ParseSignedJWT(token, key.Algorithm, publicKey)
+	if err != nil {
+		return err
+	}
+
+	keyHash, _ := claims[TokenClaimKeyHash].(string)
+	if keyHash == "" || !security.Equal(keyHash, record.authTokenKeyHash()) {
+		return errors.New("the auth token was invalidated")
+	}
+
+	return nil
+}
+
 // FindAuthRecordByEmail finds the auth record associated with the provided email.
 //
 // The email check would be case-insensitive if the related collection
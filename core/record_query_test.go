@@ -7,11 +7,14 @@ import (
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/pocketbase/pocketbase/tools/types"
 )
 
@@ -971,6 +974,240 @@ func TestFindAuthRecordByToken(t *testing.T) {
 	}
 }
 
+func TestFindAuthRecordBySignedToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, err := app.ActiveSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edKey, err := app.RotateSigningKeys(security.SigningAlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknownKey, err := core.NewSigningKeyConfig(security.SigningAlgRS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newToken := func(key core.SigningKeyConfig) string {
+		token, err := user.NewSignedAuthToken(key, 0, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	rsaToken := newToken(rsaKey)
+	edToken := newToken(edKey)
+	unknownKeyToken := newToken(unknownKey)
+
+	// non-auth token signed with a valid key
+	rsaSigner, err := rsaKey.Signer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileToken, err := security.NewSignedJWT(jwt.MapClaims{
+		core.TokenClaimType:         core.TokenTypeFile,
+		core.TokenClaimId:           user.Id,
+		core.TokenClaimCollectionId: user.Collection().Id,
+	}, rsaKey.Algorithm, rsaKey.Id, rsaSigner, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// auth token without keyHash claim signed with a valid key
+	noKeyHashToken, err := security.NewSignedJWT(jwt.MapClaims{
+		core.TokenClaimType:         core.TokenTypeAuth,
+		core.TokenClaimId:           user.Id,
+		core.TokenClaimCollectionId: user.Collection().Id,
+	}, rsaKey.Algorithm, rsaKey.Id, rsaSigner, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		token       string
+		expectError bool
+	}{
+		{"unknown signing key", unknownKeyToken, true},
+		{"non-auth token type", fileToken, true},
+		{"missing keyHash claim", noKeyHashToken, true},
+		{"rotated (still valid) RS256 signing key", rsaToken, false},
+		{"active EdDSA signing key", edToken, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			record, err := app.FindAuthRecordByToken(s.token)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr to be %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && record.Id != user.Id {
+				t.Fatalf("Expected record with id %q, got %q", user.Id, record.Id)
+			}
+		})
+	}
+
+	t.Run("expired signing key", func(t *testing.T) {
+		settings, err := app.Settings().Clone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		settings.SigningKeys.Keys[1].Expires = types.NowDateTime().Add(-1 * time.Second)
+		if err := app.Save(settings); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := app.FindAuthRecordByToken(rsaToken); err == nil {
+			t.Fatal("Expected the token signed with the expired key to be rejected")
+		}
+
+		if _, err := app.FindAuthRecordByToken(edToken); err != nil {
+			t.Fatalf("Expected the token signed with the active key to be valid, got %v", err)
+		}
+	})
+
+	t.Run("replaced private key with the same id", func(t *testing.T) {
+		// ensure that the public key is cached
+		if _, err := app.FindAuthRecordByToken(edToken); err != nil {
+			t.Fatal(err)
+		}
+
+		replacement, err := core.NewSigningKeyConfig(security.SigningAlgEdDSA)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		settings, err := app.Settings().Clone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		settings.SigningKeys.Keys[0].PrivateKey = replacement.PrivateKey
+		if err := app.Save(settings); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := app.FindAuthRecordByToken(edToken); err == nil {
+			t.Fatal("Expected the token to be rejected after the signing key replacement")
+		}
+
+		replacement.Id = edKey.Id
+		if _, err := app.FindAuthRecordByToken(newToken(replacement)); err != nil {
+			t.Fatalf("Expected the token signed with the replaced key to be valid, got %v", err)
+		}
+	})
+
+	t.Run("changed tokenKey", func(t *testing.T) {
+		user.RefreshTokenKey()
+		if err := app.Save(user); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := app.FindAuthRecordByToken(edToken); err == nil {
+			t.Fatal("Expected the token to be invalidated after the tokenKey change")
+		}
+	})
+}
+
+func TestFindAuthRecordByTokenWithSession(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	superuser, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		sessionId   string
+		expectError bool
+	}{
+		{"missing session", "missing", true},
+		{"session of another record", "9r2j0m74260ur8i", true},
+		{"existing session", "5798yh833k6w6w0", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			token, err := superuser.NewSessionAuthToken(s.sessionId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			record, err := app.FindAuthRecordByToken(token)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr to be %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				if !errors.Is(err, core.ErrRevokedSession) {
+					t.Fatalf("Expected ErrRevokedSession, got %v", err)
+				}
+				return
+			}
+
+			if record.Id != superuser.Id {
+				t.Fatalf("Expected record with id %q, got %q", superuser.Id, record.Id)
+			}
+		})
+	}
+
+	t.Run("revoked session", func(t *testing.T) {
+		token, err := superuser.NewSessionAuthToken("ic55o70g4f8pcl4")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = app.FindAuthRecordByToken(token); err != nil {
+			t.Fatalf("Expected the token to be valid before the session deletion, got %v", err)
+		}
+
+		origin, err := app.FindAuthOriginById("ic55o70g4f8pcl4")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = app.Delete(origin); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = app.FindAuthRecordByToken(token); !errors.Is(err, core.ErrRevokedSession) {
+			t.Fatalf("Expected ErrRevokedSession after the session deletion, got %v", err)
+		}
+	})
+
+	t.Run("non-auth token types ignore the session claim", func(t *testing.T) {
+		token, err := superuser.NewFileToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = app.FindAuthRecordByToken(token, core.TokenTypeFile); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
 func TestFindAuthRecordByEmail(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,288 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func (app *BaseApp) registerSoftDeleteHooks() {
+	// run on every hour to purge the expired trashed records
+	app.Cron().Add("__pbTrashCleanup__", "30 * * * *", func() {
+		if err := app.DeleteExpiredTrashedRecords(); err != nil {
+			app.Logger().Warn("Failed to delete expired trashed records", "error", err)
+		}
+	})
+}
+
+// IgnoreSoftDelete toggles the flag to ignore the collection soft delete option,
+// aka. app.Delete(record) will permanently delete the record.
+func (m *Record) IgnoreSoftDelete(state bool) *Record {
+	m.ignoreSoftDelete = state
+	return m
+}
+
+// IsTrashed reports whether the record is soft deleted
+// (aka. it has a non-empty "deleted" field value).
+func (m *Record) IsTrashed() bool {
+	return m.collection.isSoftDeletable() && !m.GetDateTime(FieldNameDeleted).IsZero()
+}
+
+// shouldSoftDelete reports whether the record delete should be
+// performed as soft delete.
+//
+// Already trashed records (based on their last loaded state) are permanently deleted.
+func (m *Record) shouldSoftDelete() bool {
+	return !m.ignoreSoftDelete &&
+		m.collection.isSoftDeletable() &&
+		m.Original().GetDateTime(FieldNameDeleted).IsZero()
+}
+
+func (m *Collection) isSoftDeletable() bool {
+	return m != nil && m.IsBase() && m.SoftDelete.Enabled
+}
+
+// isSoftDeletedModel reports whether the provided model is a record
+// whose last delete was performed as soft delete (aka. moved to the trash).
+func isSoftDeletedModel(m Model) bool {
+	switch v := m.(type) {
+	case *Record:
+		return v.softDeleted
+	case RecordProxy:
+		return v.ProxyRecord() != nil && v.ProxyRecord().softDeleted
+	}
+
+	return false
+}
+
+// onRecordSoftDeleteExecute marks the event record as deleted and
+// cascade soft deletes its references.
+func onRecordSoftDeleteExecute(e *RecordEvent, refs map[*Collection][]Field) error {
+	// preserve the cascade timestamp (if any)
+	deleted := e.Record.GetDateTime(FieldNameDeleted)
+	if deleted.IsZero() {
+		deleted = types.NowDateTime()
+	}
+
+	originalApp := e.App
+	txErr := e.App.RunInTransaction(func(txApp App) error {
+		e.App = txApp
+
+		_, err := txApp.NonconcurrentDB().Update(
+			e.Record.Collection().Name,
+			dbx.Params{FieldNameDeleted: deleted.String()},
+			dbx.HashExp{FieldNameId: e.Record.Id},
+		).WithContext(e.Context).Execute()
+		if err != nil {
+			return err
+		}
+
+		e.Record.Set(FieldNameDeleted, deleted)
+		e.Record.originalData[FieldNameDeleted] = deleted
+		e.Record.softDeleted = true
+
+		return cascadeRecordSoftDelete(txApp, e.Record, refs)
+	})
+	e.App = originalApp
+
+	return txErr
+}
+
+// cascadeRecordSoftDelete soft deletes the CascadeDelete references
+// of the provided trashed main record.
+//
+// References from collections without soft delete (or without CascadeDelete)
+// are left untouched so that the main record could be restored.
+// They are processed as usual when the main record is permanently deleted.
+//
+// NB! This method is expected to be called from inside of a transaction.
+func cascadeRecordSoftDelete(app App, mainRecord *Record, refs map[*Collection][]Field) error {
+	for _, refCollection := range sortedRefCollections(refs) {
+		if !refCollection.isSoftDeletable() {
+			continue
+		}
+
+		for _, field := range refs[refCollection] {
+			relField, _ := field.(*RelationField)
+			if relField == nil || !relField.CascadeDelete {
+				continue
+			}
+
+			rows := []*Record{}
+			err := refRecordsQuery(app.RecordQuery(refCollection), refCollection, relField, mainRecord).All(&rows)
+			if err != nil {
+				return err
+			}
+
+			for _, refRecord := range rows {
+				// soft delete only if there are no other active references in case of multiple select
+				ids := refRecord.GetStringSlice(relField.Name)
+				if len(ids) > 1 {
+					continue
+				}
+
+				refRecord.Set(FieldNameDeleted, mainRecord.GetDateTime(FieldNameDeleted))
+				if err := app.Delete(refRecord); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// RestoreRecord restores a soft deleted (aka. trashed) record
+// together with the records that were cascade soft deleted with it.
+//
+// The restore is performed as a regular record update, aka.
+// it triggers the related record update hooks.
+func (app *BaseApp) RestoreRecord(record *Record) error {
+	deleted := record.Original().GetString(FieldNameDeleted)
+	if !record.Collection().isSoftDeletable() || deleted == "" {
+		return errors.New("the record is not soft deleted")
+	}
+
+	refs, err := app.FindCachedCollectionReferences(record.Collection())
+	if err != nil {
+		return err
+	}
+
+	txErr := app.RunInTransaction(func(txApp App) error {
+		record.Set(FieldNameDeleted, "")
+		if err := txApp.Save(record); err != nil {
+			return err
+		}
+
+		// restore the references trashed together with the record
+		for _, refCollection := range sortedRefCollections(refs) {
+			if !refCollection.isSoftDeletable() {
+				continue
+			}
+
+			for _, field := range refs[refCollection] {
+				relField, _ := field.(*RelationField)
+				if relField == nil || !relField.CascadeDelete {
+					continue
+				}
+
+				rows := []*Record{}
+				err := refRecordsQuery(txApp.RecordQueryWithTrashed(refCollection), refCollection, relField, record).
+					AndWhere(dbx.HashExp{inflector.Columnify(refCollection.Name) + "." + FieldNameDeleted: deleted}).
+					All(&rows)
+				if err != nil {
+					return err
+				}
+
+				for _, refRecord := range rows {
+					if err := txApp.RestoreRecord(refRecord); err != nil {
+						return fmt.Errorf("failed to restore %s reference %q: %w", refCollection.Name, refRecord.Id, err)
+					}
+				}
+			}
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return txErr
+	}
+
+	record.originalData[FieldNameDeleted] = ""
+
+	return nil
+}
+
+// DeleteExpiredTrashedRecords permanently deletes the trashed
+// records older than their collection SoftDelete.MaxDays option.
+func (app *BaseApp) DeleteExpiredTrashedRecords() error {
+	collections, err := app.FindAllCollections(CollectionTypeBase)
+	if err != nil {
+		return err
+	}
+
+	for _, collection := range collections {
+		if !collection.isSoftDeletable() || collection.SoftDelete.MaxDays <= 0 {
+			continue
+		}
+
+		maxDate, err := types.ParseDateTime(time.Now().Add(-1 * collection.SoftDelete.MaxDaysTime()))
+		if err != nil {
+			return err
+		}
+
+		items := []*Record{}
+
+		err = app.RecordQueryWithTrashed(collection).
+			AndWhere(dbx.NewExp("[[" + FieldNameDeleted + "]] != ''")).
+			AndWhere(dbx.NewExp("[["+FieldNameDeleted+"]] < {:date}", dbx.Params{"date": maxDate})).
+			All(&items)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			// the record could have been already deleted as part of a previous item cascade
+			var exists int
+			_ = app.RecordQueryWithTrashed(collection).
+				Select("(1)").
+				AndWhere(dbx.HashExp{FieldNameId: item.Id}).
+				Limit(1).
+				Row(&exists)
+			if exists == 0 {
+				continue
+			}
+
+			err = app.Delete(item)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// refRecordsQuery filters the provided query to the records that reference
+// mainRecord through the specified relation field.
+func refRecordsQuery(query *dbx.SelectQuery, refCollection *Collection, field Field, mainRecord *Record) *dbx.SelectQuery {
+	recordTableName := inflector.Columnify(refCollection.Name)
+	prefixedFieldName := recordTableName + "." + inflector.Columnify(field.GetName())
+
+	if opt, ok := field.(MultiValuer); !ok || !opt.IsMultiple() {
+		query.AndWhere(dbx.HashExp{prefixedFieldName: mainRecord.Id})
+	} else {
+		query.AndWhere(dbx.Exists(dbx.NewExp(fmt.Sprintf(
+			`SELECT 1 FROM %s {{__je__}} WHERE [[__je__.value]]={:jevalue}`,
+			dbutils.JSONEach(prefixedFieldName),
+		), dbx.Params{
+			"jevalue": mainRecord.Id,
+		})))
+	}
+
+	if refCollection.Id == mainRecord.Collection().Id {
+		query.AndWhere(dbx.Not(dbx.HashExp{recordTableName + ".id": mainRecord.Id}))
+	}
+
+	return query
+}
+
+// sortedRefCollections returns the refs keys sorted by their name
+// to ensure that the cascade events firing order is always the same.
+func sortedRefCollections(refs map[*Collection][]Field) []*Collection {
+	sorted := make([]*Collection, 0, len(refs))
+	for k := range refs {
+		sorted = append(sorted, k)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return sorted
+}
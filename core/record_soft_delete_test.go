@@ -0,0 +1,404 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func createSoftDeleteCollections(t *testing.T, app core.App) (*core.Collection, *core.Collection) {
+	posts := core.NewBaseCollection("sd_posts")
+	posts.SoftDelete.Enabled = true
+	posts.Fields.Add(&core.TextField{Name: "title"})
+	if err := app.Save(posts); err != nil {
+		t.Fatal(err)
+	}
+
+	comments := core.NewBaseCollection("sd_comments")
+	comments.SoftDelete.Enabled = true
+	comments.Fields.Add(&core.RelationField{Name: "post", CollectionId: posts.Id, CascadeDelete: true, MaxSelect: 1})
+	if err := app.Save(comments); err != nil {
+		t.Fatal(err)
+	}
+
+	return posts, comments
+}
+
+func countSoftDeleteRecords(t *testing.T, app core.App, collection *core.Collection, withTrashed bool) int {
+	var total int
+
+	query := app.RecordQuery(collection)
+	if withTrashed {
+		query = app.RecordQueryWithTrashed(collection)
+	}
+
+	if err := query.Select("count(*)").Row(&total); err != nil {
+		t.Fatal(err)
+	}
+
+	return total
+}
+
+func TestSoftDeleteCollectionField(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("sd_test")
+	collection.SoftDelete.Enabled = true
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	field, ok := collection.Fields.GetByName(core.FieldNameDeleted).(*core.DateField)
+	if !ok || !field.System || !field.Hidden {
+		t.Fatalf("Expected hidden system %q date field, got %#v", core.FieldNameDeleted, field)
+	}
+
+	// disable
+	collection.SoftDelete.Enabled = false
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	if !field.System {
+		t.Fatal("Expected the deleted field to remain a system field")
+	}
+
+	// non-date "deleted" field
+	invalid := core.NewBaseCollection("sd_invalid")
+	invalid.Fields.Add(&core.BoolField{Name: core.FieldNameDeleted})
+	invalid.SoftDelete.Enabled = true
+	tests.TestValidationErrors(t, app.Validate(invalid), []string{"softDelete"})
+
+	// user defined "deleted" date field
+	userField := &core.DateField{Name: core.FieldNameDeleted}
+	clash := core.NewBaseCollection("sd_clash")
+	clash.Fields.Add(userField)
+	clash.SoftDelete.Enabled = true
+	tests.TestValidationErrors(t, app.Validate(clash), []string{"softDelete"})
+	if userField.System || userField.Hidden {
+		t.Fatal("Expected the user defined deleted field to remain unchanged")
+	}
+}
+
+func TestSoftDeleteCollectionUniqueIndexes(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("sd_unique")
+	collection.Fields.Add(&core.TextField{Name: "title"})
+	collection.Fields.Add(&core.TextField{Name: "slug"})
+	collection.AddIndex("idx_sd_unique_title", true, "title", "")
+	collection.AddIndex("idx_sd_unique_slug", true, "slug", "slug != ''")
+	collection.AddIndex("idx_sd_unique_partial", true, "title, slug", "`deleted` != ''")
+	collection.AddIndex("idx_sd_nonunique", false, "slug", "")
+	collection.SoftDelete.Enabled = true
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedWheres := map[string]string{
+		"idx_sd_unique_title":   "`deleted` = ''",
+		"idx_sd_unique_slug":    "(slug != '') AND `deleted` = ''",
+		"idx_sd_unique_partial": "`deleted` != ''",
+		"idx_sd_nonunique":      "",
+	}
+	for name, where := range expectedWheres {
+		idx := dbutils.ParseIndex(collection.GetIndex(name))
+		if idx.Where != where {
+			t.Fatalf("[%s] Expected where %q, got %q", name, where, idx.Where)
+		}
+	}
+
+	// trashed records shouldn't occupy the unique values
+	first := core.NewRecord(collection)
+	first.Set("title", "test")
+	if err := app.Save(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Delete(first); err != nil {
+		t.Fatal(err)
+	}
+
+	second := core.NewRecord(collection)
+	second.Set("title", "test")
+	if err := app.Save(second); err != nil {
+		t.Fatalf("Expected the active record to be saved, got %v", err)
+	}
+
+	// restoring a conflicting record should fail
+	if err := app.RestoreRecord(first); err == nil {
+		t.Fatal("Expected the restore to fail because of the unique constraint")
+	}
+
+	// disabling the soft delete with conflicting trashed records should fail
+	collection.SoftDelete.Enabled = false
+	tests.TestValidationErrors(t, app.Save(collection), []string{"softDelete"})
+
+	// permanently delete the conflicting trashed record and try again
+	if err := app.Delete(first); err != nil {
+		t.Fatal(err)
+	}
+
+	collection, err := app.FindCollectionByNameOrId("sd_unique")
+	if err != nil {
+		t.Fatal(err)
+	}
+	collection.SoftDelete.Enabled = false
+	if err := app.Save(collection); err != nil {
+		t.Fatalf("Expected the soft delete to be disabled, got %v", err)
+	}
+
+	restoredWheres := map[string]string{
+		"idx_sd_unique_title":   "",
+		"idx_sd_unique_slug":    "slug != ''",
+		"idx_sd_unique_partial": "`deleted` != ''",
+		"idx_sd_nonunique":      "",
+	}
+	for name, where := range restoredWheres {
+		idx := dbutils.ParseIndex(collection.GetIndex(name))
+		if idx.Where != where {
+			t.Fatalf("[%s] Expected restored where %q, got %q", name, where, idx.Where)
+		}
+	}
+
+	// the restored index should apply again to all records
+	third := core.NewRecord(collection)
+	third.Set("title", "test")
+	if err := app.Save(third); err == nil {
+		t.Fatal("Expected the duplicated title to fail")
+	}
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	posts, comments := createSoftDeleteCollections(t, app)
+
+	post := core.NewRecord(posts)
+	post.Set("title", "test")
+	if err := app.Save(post); err != nil {
+		t.Fatal(err)
+	}
+
+	comment := core.NewRecord(comments)
+	comment.Set("post", post.Id)
+	if err := app.Save(comment); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(post); err != nil {
+		t.Fatal(err)
+	}
+
+	if !post.IsTrashed() {
+		t.Fatal("Expected the post to be marked as trashed")
+	}
+
+	for _, c := range []*core.Collection{posts, comments} {
+		if total := countSoftDeleteRecords(t, app, c, false); total != 0 {
+			t.Fatalf("[%s] Expected 0 visible records, got %d", c.Name, total)
+		}
+		if total := countSoftDeleteRecords(t, app, c, true); total != 1 {
+			t.Fatalf("[%s] Expected 1 trashed record, got %d", c.Name, total)
+		}
+	}
+
+	if _, err := app.FindRecordById(posts, post.Id); err == nil {
+		t.Fatal("Expected the trashed post to not be found")
+	}
+
+	// restore
+	trashed := &core.Record{}
+	if err := app.RecordQueryWithTrashed(posts).AndWhere(dbx.HashExp{"id": post.Id}).One(trashed); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.RestoreRecord(trashed); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []*core.Collection{posts, comments} {
+		if total := countSoftDeleteRecords(t, app, c, false); total != 1 {
+			t.Fatalf("[%s] Expected 1 restored record, got %d", c.Name, total)
+		}
+	}
+
+	// restoring a non-trashed record should fail
+	if err := app.RestoreRecord(trashed); err == nil {
+		t.Fatal("Expected non-trashed record restore error")
+	}
+}
+
+func TestSoftDeletePermanentDelete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	posts, comments := createSoftDeleteCollections(t, app)
+
+	post := core.NewRecord(posts)
+	if err := app.Save(post); err != nil {
+		t.Fatal(err)
+	}
+
+	comment := core.NewRecord(comments)
+	comment.Set("post", post.Id)
+	if err := app.Save(comment); err != nil {
+		t.Fatal(err)
+	}
+
+	// delete a trashed record
+	if err := app.Delete(post); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Delete(post); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []*core.Collection{posts, comments} {
+		if total := countSoftDeleteRecords(t, app, c, true); total != 0 {
+			t.Fatalf("[%s] Expected all records to be permanently deleted, got %d", c.Name, total)
+		}
+	}
+
+	// explicit permanent delete
+	post2 := core.NewRecord(posts)
+	if err := app.Save(post2); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Delete(post2.IgnoreSoftDelete(true)); err != nil {
+		t.Fatal(err)
+	}
+	if total := countSoftDeleteRecords(t, app, posts, true); total != 0 {
+		t.Fatalf("Expected the record to be permanently deleted, got %d", total)
+	}
+}
+
+func TestSoftDeleteFiles(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	posts, _ := createSoftDeleteCollections(t, app)
+	posts.Fields.Add(&core.FileField{Name: "file", MaxSelect: 1})
+	if err := app.Save(posts); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := filesystem.NewFileFromBytes([]byte("test"), "test.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := core.NewRecord(posts)
+	post.Set("file", f)
+	if err := app.Save(post); err != nil {
+		t.Fatal(err)
+	}
+
+	fileKey := post.BaseFilesPath() + "/" + post.GetString("file")
+
+	fileExists := func() bool {
+		fsys, err := app.NewFilesystem()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fsys.Close()
+
+		exists, err := fsys.Exists(fileKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return exists
+	}
+
+	// wait for the background files delete (if any)
+	waitForFileDelete := func() bool {
+		for i := 0; i < 50; i++ {
+			if !fileExists() {
+				return true
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		return false
+	}
+
+	// soft delete
+	if err := app.Delete(post); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if !fileExists() {
+		t.Fatal("Expected the trashed record file to be kept")
+	}
+
+	// empty the trash
+	trashed := &core.Record{}
+	if err := app.RecordQueryWithTrashed(posts).AndWhere(dbx.HashExp{"id": post.Id}).One(trashed); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Delete(trashed); err != nil {
+		t.Fatal(err)
+	}
+	if !waitForFileDelete() {
+		t.Fatal("Expected the permanently deleted trashed record file to be removed")
+	}
+}
+
+func TestDeleteExpiredTrashedRecords(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	posts, _ := createSoftDeleteCollections(t, app)
+	posts.SoftDelete.MaxDays = 2
+	if err := app.Save(posts); err != nil {
+		t.Fatal(err)
+	}
+
+	old := core.NewRecord(posts)
+	recent := core.NewRecord(posts)
+	for _, r := range []*core.Record{old, recent} {
+		if err := app.Save(r); err != nil {
+			t.Fatal(err)
+		}
+		if err := app.Delete(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldDate, _ := types.ParseDateTime(time.Now().Add(-3 * 24 * time.Hour))
+	_, err := app.NonconcurrentDB().Update(posts.Name, dbx.Params{core.FieldNameDeleted: oldDate.String()}, dbx.HashExp{"id": old.Id}).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.DeleteExpiredTrashedRecords(); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []string{}
+	if err := app.RecordQueryWithTrashed(posts).Select("id").Column(&ids); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ids) != 1 || ids[0] != recent.Id {
+		t.Fatalf("Expected only the recent trashed record to remain, got %v", ids)
+	}
+}
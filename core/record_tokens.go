@@ -15,6 +15,8 @@ const (
 	TokenTypeVerification  = "verification"
 	TokenTypePasswordReset = "passwordReset"
 	TokenTypeEmailChange   = "emailChange"
+	TokenTypeOAuth2Access  = "oauth2Access"
+	TokenTypeOAuth2Refresh = "oauth2Refresh"
 )
 
 // List with commonly used record token claims
@@ -25,12 +27,18 @@ const (
 	TokenClaimEmail        = "email"
 	TokenClaimNewEmail     = "newEmail"
 	TokenClaimRefreshable  = "refreshable"
+	TokenClaimSessionId    = "sessionId"
+	TokenClaimClientId     = "clientId"
+	TokenClaimScope        = "scope"
+	TokenClaimKeyHash      = "keyHash"
+	TokenClaimTokenId      = "jti"
 )
 
 // Common token related errors
 var (
 	ErrNotAuthRecord     = errors.New("not an auth collection record")
 	ErrMissingSigningKey = errors.New("missing or invalid signing key")
+	ErrRevokedSession    = errors.New("the auth session was revoked")
 )
 
 // NewStaticAuthToken generates and returns a new static record authentication token.
@@ -48,7 +56,52 @@ func (m *Record) NewAuthToken() (string, error) {
 	return m.newAuthToken(0, true)
 }
 
-func (m *Record) newAuthToken(duration time.Duration, refreshable bool) (string, error) {
+// NewSessionAuthToken generates and returns a new record authentication token
+// bound to the specified auth session (aka. [AuthOrigin] model id).
+//
+// Session auth tokens are rejected by [App.FindAuthRecordByToken]
+// once the related auth session is deleted.
+func (m *Record) NewSessionAuthToken(sessionId string) (string, error) {
+	if sessionId == "" {
+		return "", errors.New("missing session id")
+	}
+
+	return m.newAuthToken(0, true, sessionId)
+}
+
+// NewSignedAuthToken generates and returns a new record authentication token
+// signed with the provided asymmetric app signing key (see [SigningKeysConfig]).
+//
+// Unlike the HMAC signed auth tokens, the signed ones could be verified by other
+// services using only the app public keys (see the "/.well-known/jwks.json" endpoint).
+// They are still invalidated on record tokenKey or collection auth token secret change
+// because of the embedded "keyHash" claim.
+//
+// The optSessionId argument is optional and when set binds the token
+// to the related auth session (see [Record.NewSessionAuthToken]).
+//
+// Zero or negative duration will fallback to the duration from the auth collection settings.
+func (m *Record) NewSignedAuthToken(key SigningKeyConfig, duration time.Duration, refreshable bool, optSessionId ...string) (string, error) {
+	if !m.Collection().IsAuth() {
+		return "", ErrNotAuthRecord
+	}
+
+	signer, err := key.Signer()
+	if err != nil {
+		return "", errors.Join(ErrMissingSigningKey, err)
+	}
+
+	claims := m.authTokenClaims(refreshable, optSessionId...)
+	claims[TokenClaimKeyHash] = m.authTokenKeyHash()
+
+	if duration <= 0 {
+		duration = m.Collection().AuthToken.DurationTime()
+	}
+
+	return security.NewSignedJWT(claims, key.Algorithm, key.Id, signer, duration)
+}
+
+func (m *Record) newAuthToken(duration time.Duration, refreshable bool, optSessionId ...string) (string, error) {
 	if !m.Collection().IsAuth() {
 		return "", ErrNotAuthRecord
 	}
@@ -58,6 +111,14 @@ func (m *Record) newAuthToken(duration time.Duration, refreshable bool) (string,
 		return "", ErrMissingSigningKey
 	}
 
+	if duration <= 0 {
+		duration = m.Collection().AuthToken.DurationTime()
+	}
+
+	return security.NewJWT(m.authTokenClaims(refreshable, optSessionId...), key, duration)
+}
+
+func (m *Record) authTokenClaims(refreshable bool, optSessionId ...string) jwt.MapClaims {
 	claims := jwt.MapClaims{
 		TokenClaimType:         TokenTypeAuth,
 		TokenClaimId:           m.Id,
@@ -65,11 +126,61 @@ func (m *Record) newAuthToken(duration time.Duration, refreshable bool) (string,
 		TokenClaimRefreshable:  refreshable,
 	}
 
-	if duration <= 0 {
-		duration = m.Collection().AuthToken.DurationTime()
+	if len(optSessionId) > 0 && optSessionId[0] != "" {
+		claims[TokenClaimSessionId] = optSessionId[0]
+	}
+
+	return claims
+}
+
+// authTokenKeyHash returns a hash of the record auth token secrets
+// that is embedded in the asymmetric signed auth tokens.
+func (m *Record) authTokenKeyHash() string {
+	return security.SHA256(m.TokenKey() + m.Collection().AuthToken.Secret)
+}
+
+// NewOAuth2AccessToken generates and returns a new OAuth2 server access token
+// issued to the specified OAuth2 client with the specified space separated scope.
+//
+// Note that the OAuth2 access tokens are accepted only by the OAuth2 server
+// endpoints (e.g. userinfo) and cannot be used as regular auth tokens.
+func (m *Record) NewOAuth2AccessToken(clientId string, scope string) (string, error) {
+	return m.newOAuth2Token(TokenTypeOAuth2Access, clientId, scope, m.Collection().AuthToken.DurationTime())
+}
+
+// NewOAuth2RefreshToken generates and returns a new OAuth2 server refresh token
+// issued to the specified OAuth2 client with the specified space separated scope.
+func (m *Record) NewOAuth2RefreshToken(clientId string, scope string, duration time.Duration) (string, error) {
+	return m.newOAuth2Token(TokenTypeOAuth2Refresh, clientId, scope, duration)
+}
+
+func (m *Record) newOAuth2Token(tokenType string, clientId string, scope string, duration time.Duration) (string, error) {
+	if !m.Collection().IsAuth() {
+		return "", ErrNotAuthRecord
 	}
 
-	return security.NewJWT(claims, key, duration)
+	if clientId == "" {
+		return "", errors.New("missing OAuth2 client id")
+	}
+
+	key := (m.TokenKey() + m.Collection().AuthToken.Secret)
+	if key == "" {
+		return "", ErrMissingSigningKey
+	}
+
+	return security.NewJWT(
+		jwt.MapClaims{
+			TokenClaimType:         tokenType,
+			TokenClaimId:           m.Id,
+			TokenClaimCollectionId: m.Collection().Id,
+			TokenClaimClientId:     clientId,
+			TokenClaimScope:        scope,
+			// make each token unique so that it could be tracked individually
+			TokenClaimTokenId: security.RandomString(20),
+		},
+		key,
+		duration,
+	)
 }
 
 // NewVerificationToken generates and returns a new record verification token.
@@ -1,6 +1,7 @@
 package core_test
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -83,6 +84,228 @@ func TestNewAuthToken(t *testing.T) {
 	})
 }
 
+func TestNewSessionAuthToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	superuser, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("non-auth record", func(t *testing.T) {
+		_, err := demo1.NewSessionAuthToken("5798yh833k6w6w0")
+		if err == nil {
+			t.Fatal("Expected error for non-auth records")
+		}
+	})
+
+	t.Run("empty session id", func(t *testing.T) {
+		_, err := superuser.NewSessionAuthToken("")
+		if err == nil {
+			t.Fatal("Expected error for empty session id")
+		}
+	})
+
+	t.Run("auth record", func(t *testing.T) {
+		token, err := superuser.NewSessionAuthToken("5798yh833k6w6w0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tokenRecord, _ := app.FindAuthRecordByToken(token, core.TokenTypeAuth)
+		if tokenRecord == nil || tokenRecord.Id != superuser.Id {
+			t.Fatalf("Expected auth record\n%v\ngot\n%v", superuser, tokenRecord)
+		}
+
+		claims, _ := security.ParseUnverifiedJWT(token)
+		expectedClaims := map[string]any{
+			core.TokenClaimRefreshable: true,
+			core.TokenClaimSessionId:   "5798yh833k6w6w0",
+		}
+		for k, v := range expectedClaims {
+			if claims[k] != v {
+				t.Errorf("Expected claim %q with value %#v, got %#v", k, v, claims[k])
+			}
+		}
+	})
+}
+
+func TestNewSignedAuthToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := app.ActiveSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("non-auth record", func(t *testing.T) {
+		_, err := demo1.NewSignedAuthToken(key, 0, true)
+		if err == nil {
+			t.Fatal("Expected error for non-auth records")
+		}
+	})
+
+	t.Run("invalid signing key", func(t *testing.T) {
+		_, err := user.NewSignedAuthToken(core.SigningKeyConfig{Id: "test", Algorithm: "RS256"}, 0, true)
+		if !errors.Is(err, core.ErrMissingSigningKey) {
+			t.Fatalf("Expected ErrMissingSigningKey, got %v", err)
+		}
+	})
+
+	scenarios := []struct {
+		name           string
+		duration       time.Duration
+		refreshable    bool
+		sessionId      string
+		expectedClaims map[string]any
+	}{
+		{
+			"refreshable",
+			0,
+			true,
+			"",
+			map[string]any{core.TokenClaimRefreshable: true},
+		},
+		{
+			"static",
+			100 * time.Second,
+			false,
+			"",
+			map[string]any{core.TokenClaimRefreshable: false},
+		},
+		{
+			"with session",
+			0,
+			true,
+			"test_session",
+			map[string]any{core.TokenClaimRefreshable: true, core.TokenClaimSessionId: "test_session"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			now := time.Now()
+
+			token, err := user.NewSignedAuthToken(key, s.duration, s.refreshable, s.sessionId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			header, err := security.ParseUnverifiedJWTHeader(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if header["alg"] != key.Algorithm || header["kid"] != key.Id {
+				t.Fatalf("Expected alg %q and kid %q headers, got %v", key.Algorithm, key.Id, header)
+			}
+
+			signer, err := key.Signer()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims, err := security.ParseSignedJWT(token, key.Algorithm, signer.Public())
+			if err != nil {
+				t.Fatalf("Expected the token to be verifiable with the public key, got %v", err)
+			}
+
+			expectedClaims := map[string]any{
+				core.TokenClaimType:         core.TokenTypeAuth,
+				core.TokenClaimId:           user.Id,
+				core.TokenClaimCollectionId: user.Collection().Id,
+			}
+			for k, v := range s.expectedClaims {
+				expectedClaims[k] = v
+			}
+			for k, v := range expectedClaims {
+				if claims[k] != v {
+					t.Errorf("Expected claim %q with value %#v, got %#v", k, v, claims[k])
+				}
+			}
+
+			if _, ok := claims[core.TokenClaimSessionId]; ok && s.sessionId == "" {
+				t.Errorf("Expected no %q claim", core.TokenClaimSessionId)
+			}
+
+			if v, _ := claims[core.TokenClaimKeyHash].(string); v == "" {
+				t.Errorf("Expected non-empty %q claim", core.TokenClaimKeyHash)
+			}
+
+			expectedDuration := s.duration
+			if expectedDuration <= 0 {
+				expectedDuration = user.Collection().AuthToken.DurationTime()
+			}
+			exp := cast.ToInt64(claims["exp"])
+			if expected := now.Add(expectedDuration).Unix(); exp < expected-1 || exp > expected+1 {
+				t.Errorf("Expected exp ~%d, got %d", expected, exp)
+			}
+		})
+	}
+}
+
+func TestNewOAuth2AccessToken(t *testing.T) {
+	t.Parallel()
+
+	testRecordToken(t, core.TokenTypeOAuth2Access, func(record *core.Record) (string, error) {
+		return record.NewOAuth2AccessToken("test_client", "openid email")
+	}, map[string]any{
+		core.TokenClaimClientId: "test_client",
+		core.TokenClaimScope:    "openid email",
+	})
+}
+
+func TestNewOAuth2RefreshToken(t *testing.T) {
+	t.Parallel()
+
+	testRecordToken(t, core.TokenTypeOAuth2Refresh, func(record *core.Record) (string, error) {
+		return record.NewOAuth2RefreshToken("test_client", "openid", time.Hour)
+	}, map[string]any{
+		core.TokenClaimClientId: "test_client",
+		core.TokenClaimScope:    "openid",
+	})
+}
+
+func TestNewOAuth2TokenWithoutClientId(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := user.NewOAuth2AccessToken("", "openid"); err == nil {
+		t.Fatal("Expected access token error")
+	}
+
+	if _, err := user.NewOAuth2RefreshToken("", "openid", time.Hour); err == nil {
+		t.Fatal("Expected refresh token error")
+	}
+}
+
 func TestNewVerificationToken(t *testing.T) {
 	t.Parallel()
 
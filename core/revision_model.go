@@ -0,0 +1,272 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var (
+	_ Model = (*Revision)(nil)
+)
+
+const RevisionsTableName = "_revisions"
+
+const (
+	RevisionActionCreate = "create"
+	RevisionActionUpdate = "update"
+	RevisionActionDelete = "delete"
+)
+
+// Revision defines a single record change entry of a collection
+// with enabled History option.
+type Revision struct {
+	BaseModel
+
+	CollectionRef     string `db:"collectionRef" json:"collectionRef"`
+	RecordRef         string `db:"recordRef" json:"recordRef"`
+	Action            string `db:"action" json:"action"`
+	AuthCollectionRef string `db:"authCollectionRef" json:"authCollectionRef"`
+	AuthRef           string `db:"authRef" json:"authRef"`
+
+	// Changes holds the changed record fields in the format:
+	//
+	//	{"fieldName": {"old": oldValue, "new": newValue}}
+	Changes types.JSONMap[any] `db:"changes" json:"changes"`
+
+	Created types.DateTime `db:"created" json:"created"`
+}
+
+func (m *Revision) TableName() string {
+	return RevisionsTableName
+}
+
+// OldValue returns the old value of the specified changed field
+// and whether the field is part of the revision changes.
+func (m *Revision) OldValue(fieldName string) (any, bool) {
+	change, ok := m.Changes[fieldName].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	return change["old"], true
+}
+
+// -------------------------------------------------------------------
+
+type authContextKey struct{}
+
+// ContextWithAuth returns a copy of ctx associated with the provided auth record.
+//
+// It is used for example by the records revisions history to identify
+// the acting auth record of a [App.SaveWithContext] or [App.DeleteWithContext] call.
+func ContextWithAuth(ctx context.Context, auth *Record) context.Context {
+	return context.WithValue(ctx, authContextKey{}, auth)
+}
+
+// AuthFromContext returns the auth record associated with ctx
+// via [ContextWithAuth] (if any).
+func AuthFromContext(ctx context.Context) *Record {
+	if ctx == nil {
+		return nil
+	}
+
+	auth, _ := ctx.Value(authContextKey{}).(*Record)
+
+	return auth
+}
+
+// -------------------------------------------------------------------
+
+func (m *Collection) isHistoryEnabled() bool {
+	return m != nil && m.IsBase() && m.History.Enabled
+}
+
+func (app *BaseApp) registerRevisionHooks() {
+	// the revision is written as part of the record db write so that
+	// a failed revision save rolls back the record operation as well
+	handler := func(action string) func(e *RecordEvent) error {
+		return func(e *RecordEvent) error {
+			if !e.Record.Collection().isHistoryEnabled() {
+				return e.Next()
+			}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp App) error {
+				e.App = txApp
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				if err := saveRecordRevision(e, action); err != nil {
+					return fmt.Errorf("failed to save record revision: %w", err)
+				}
+
+				return nil
+			})
+			e.App = originalApp
+
+			return txErr
+		}
+	}
+
+	app.OnRecordCreateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Id:   "__pbRevisionOnCreate__",
+		Func: handler(RevisionActionCreate),
+	})
+
+	app.OnRecordUpdateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Id:   "__pbRevisionOnUpdate__",
+		Func: handler(RevisionActionUpdate),
+	})
+
+	app.OnRecordDeleteExecute().Bind(&hook.Handler[*RecordEvent]{
+		Id:   "__pbRevisionOnDelete__",
+		Func: handler(RevisionActionDelete),
+	})
+
+	// delete the collection revisions
+	app.OnCollectionAfterDeleteSuccess().Bind(&hook.Handler[*CollectionEvent]{
+		Id: "__pbRevisionOnCollectionDelete__",
+		Func: func(e *CollectionEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			if err := e.App.DeleteAllRevisionsByCollection(e.Collection); err != nil {
+				e.App.Logger().Warn(
+					"Failed to delete collection revisions",
+					"collectionName", e.Collection.Name,
+					"error", err,
+				)
+			}
+
+			return nil
+		},
+	})
+}
+
+func saveRecordRevision(e *RecordEvent, action string) error {
+	original := e.Record.Original()
+	isPermanentDelete := action == RevisionActionDelete
+
+	// soft delete
+	if isPermanentDelete && e.Record.IsTrashed() {
+		var exists int
+		_ = e.App.RecordQueryWithTrashed(e.Record.Collection()).
+			Select("(1)").
+			AndWhere(dbx.HashExp{FieldNameId: e.Record.Id}).
+			Limit(1).
+			Row(&exists)
+
+		if exists > 0 {
+			isPermanentDelete = false
+
+			// the original state is already updated with the soft delete timestamp
+			original = e.Record.Clone()
+			original.Set(FieldNameDeleted, "")
+		}
+	}
+
+	changes, err := recordChanges(e.Record, original, isPermanentDelete)
+	if err != nil {
+		return err
+	}
+
+	// nothing to store
+	if len(changes) == 0 {
+		return nil
+	}
+
+	revision := &Revision{
+		CollectionRef: e.Record.Collection().Id,
+		RecordRef:     e.Record.Id,
+		Action:        action,
+		Changes:       changes,
+		Created:       types.NowDateTime(),
+	}
+	revision.Id = GenerateDefaultRandomId()
+
+	if auth := AuthFromContext(e.Context); auth != nil {
+		revision.AuthCollectionRef = auth.Collection().Id
+		revision.AuthRef = auth.Id
+	}
+
+	if err := e.App.AuxSave(revision); err != nil {
+		return err
+	}
+
+	// the revisions are stored in the auxiliary db and are not part of
+	// the main db transaction so remove the revision on rollback
+	if txInfo := e.App.TxInfo(); txInfo != nil {
+		app := e.App
+		txInfo.OnComplete(func(txErr error) error {
+			if txErr == nil {
+				return nil
+			}
+
+			if parent := txInfo.parent; parent != nil {
+				app = parent
+			}
+
+			// delete directly by id because the revision model "new" state
+			// is reset by the model after create error hooks
+			_, err := app.AuxNonconcurrentDB().Delete(
+				RevisionsTableName,
+				dbx.HashExp{"id": revision.Id},
+			).Execute()
+
+			return err
+		})
+	}
+
+	return nil
+}
+
+// recordChanges returns the changed record field values compared to the original record state.
+//
+// If allOld is set, all tracked field values are returned as old values (e.g. on permanent delete).
+func recordChanges(record *Record, original *Record, allOld bool) (map[string]any, error) {
+	changes := map[string]any{}
+
+	for _, field := range record.Collection().Fields {
+		switch field.(type) {
+		case *PasswordField, *AutodateField, *SearchField:
+			continue // not tracked
+		}
+
+		name := field.GetName()
+		if name == FieldNameId {
+			continue
+		}
+
+		if allOld {
+			changes[name] = map[string]any{"old": record.Get(name), "new": nil}
+			continue
+		}
+
+		oldValue := original.Get(name)
+		newValue := record.Get(name)
+
+		oldRaw, err := json.Marshal(oldValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize %q old value: %w", name, err)
+		}
+
+		newRaw, err := json.Marshal(newValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize %q new value: %w", name, err)
+		}
+
+		if string(oldRaw) != string(newRaw) {
+			changes[name] = map[string]any{"old": oldValue, "new": newValue}
+		}
+	}
+
+	return changes, nil
+}
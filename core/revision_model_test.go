@@ -0,0 +1,275 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func createHistoryCollection(t *testing.T, app core.App) *core.Collection {
+	collection := core.NewBaseCollection("history_test")
+	collection.History.Enabled = true
+	collection.SoftDelete.Enabled = true
+	collection.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.NumberField{Name: "total"},
+		&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	return collection
+}
+
+func TestRevisionTableName(t *testing.T) {
+	t.Parallel()
+
+	m := &core.Revision{}
+	if m.TableName() != core.RevisionsTableName {
+		t.Fatalf("Expected %q, got %q", core.RevisionsTableName, m.TableName())
+	}
+}
+
+func TestRevisionOldValue(t *testing.T) {
+	t.Parallel()
+
+	m := &core.Revision{Changes: map[string]any{
+		"a": map[string]any{"old": "test", "new": "test2"},
+		"b": "invalid",
+	}}
+
+	scenarios := []struct {
+		field      string
+		expected   any
+		expectedOk bool
+	}{
+		{"missing", nil, false},
+		{"b", nil, false},
+		{"a", "test", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.field, func(t *testing.T) {
+			v, ok := m.OldValue(s.field)
+			if ok != s.expectedOk || v != s.expected {
+				t.Fatalf("Expected (%v, %v), got (%v, %v)", s.expected, s.expectedOk, v, ok)
+			}
+		})
+	}
+}
+
+func TestContextWithAuth(t *testing.T) {
+	t.Parallel()
+
+	if auth := core.AuthFromContext(context.Background()); auth != nil {
+		t.Fatalf("Expected nil auth, got %v", auth)
+	}
+
+	auth := &core.Record{}
+	ctx := core.ContextWithAuth(context.Background(), auth)
+	if v := core.AuthFromContext(ctx); v != auth {
+		t.Fatalf("Expected %v auth, got %v", auth, v)
+	}
+}
+
+func TestRecordRevisionsHistory(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createHistoryCollection(t, app)
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := core.ContextWithAuth(context.Background(), user)
+
+	record := core.NewRecord(collection)
+	record.Set("title", "a")
+	if err := app.SaveWithContext(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+
+	// refetch to refresh the original record state
+	record, err = app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no changes
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	record.Set("title", "b")
+	record.Set("total", 2)
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err = app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// soft delete
+	if err := app.Delete(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// permanent delete
+	if err := app.Delete(record); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := app.FindAllRevisionsByRecord(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		action  string
+		authRef string
+		changes string
+	}{
+		{core.RevisionActionCreate, user.Id, `{"title":{"new":"a","old":""}}`},
+		{core.RevisionActionUpdate, "", `{"title":{"new":"b","old":"a"},"total":{"new":2,"old":0}}`},
+		{core.RevisionActionDelete, "", `{"deleted":{"new":"` + record.GetString("deleted") + `","old":""}}`},
+		{core.RevisionActionDelete, "", `{"deleted":{"new":null,"old":"` + record.GetString("deleted") + `"},"title":{"new":null,"old":"b"},"total":{"new":null,"old":2}}`},
+	}
+
+	if len(revisions) != len(expected) {
+		t.Fatalf("Expected %d revisions, got %d", len(expected), len(revisions))
+	}
+
+	for i, e := range expected {
+		r := revisions[i]
+
+		if r.Action != e.action {
+			t.Errorf("[%d] Expected action %q, got %q", i, e.action, r.Action)
+		}
+
+		if r.AuthRef != e.authRef {
+			t.Errorf("[%d] Expected authRef %q, got %q", i, e.authRef, r.AuthRef)
+		}
+
+		if r.AuthRef != "" && r.AuthCollectionRef != user.Collection().Id {
+			t.Errorf("[%d] Expected authCollectionRef %q, got %q", i, user.Collection().Id, r.AuthCollectionRef)
+		}
+
+		if changes := r.Changes.String(); changes != e.changes {
+			t.Errorf("[%d] Expected changes\n%s\ngot\n%s", i, e.changes, changes)
+		}
+	}
+
+	// collection delete
+	if err := app.Delete(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err = app.FindAllRevisionsByRecord(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 0 {
+		t.Fatalf("Expected the collection revisions to be deleted, got %d", len(revisions))
+	}
+}
+
+func TestRecordRevisionsHistoryDisabled(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.FindRecordById("demo2", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record.Set("title", "new")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := app.FindAllRevisionsByRecord(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 0 {
+		t.Fatalf("Expected no revisions, got %d", len(revisions))
+	}
+}
+
+func TestRecordRevisionsHistorySaveFailure(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createHistoryCollection(t, app)
+
+	app.OnModelCreate(core.RevisionsTableName).BindFunc(func(e *core.ModelEvent) error {
+		return errors.New("test revision error")
+	})
+
+	record := core.NewRecord(collection)
+	record.Set("title", "a")
+	if err := app.Save(record); err == nil {
+		t.Fatal("Expected the record save to fail")
+	}
+
+	total, err := app.CountRecords(collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Fatalf("Expected the record create to be rolled back, got %d records", total)
+	}
+}
+
+func TestRecordRevisionsHistoryTransactionRollback(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createHistoryCollection(t, app)
+
+	record := core.NewRecord(collection)
+	record.Set("title", "a")
+
+	txErr := app.RunInTransaction(func(txApp core.App) error {
+		if err := txApp.Save(record); err != nil {
+			return err
+		}
+
+		revisions, err := txApp.FindAllRevisionsByRecord(record)
+		if err != nil {
+			return err
+		}
+		if len(revisions) != 1 {
+			t.Fatalf("Expected 1 revision within the transaction, got %d", len(revisions))
+		}
+
+		return errors.New("test rollback")
+	})
+	if txErr == nil {
+		t.Fatal("Expected the transaction to fail")
+
+	}
+
+	revisions, err := app.FindAllRevisionsByRecord(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 0 {
+		t.Fatalf("Expected the revision to be removed on rollback, got %d", len(revisions))
+	}
+}
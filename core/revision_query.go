@@ -0,0 +1,111 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pocketbase/dbx"
+)
+
+// RevisionQuery returns a new Revision select query.
+func (app *BaseApp) RevisionQuery() *dbx.SelectQuery {
+	return app.AuxModelQuery(&Revision{})
+}
+
+// FindRevisionById finds a single Revision entry by its id.
+func (app *BaseApp) FindRevisionById(id string) (*Revision, error) {
+	model := &Revision{}
+
+	err := app.RevisionQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// FindAllRevisionsByRecord returns all revisions of the provided record
+// ordered by their creation (oldest first).
+func (app *BaseApp) FindAllRevisionsByRecord(record *Record) ([]*Revision, error) {
+	result := []*Revision{}
+
+	err := app.RevisionQuery().
+		AndWhere(dbx.HashExp{
+			"collectionRef": record.Collection().Id,
+			"recordRef":     record.Id,
+		}).
+		OrderBy("created ASC", "rowid ASC").
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteAllRevisionsByCollection deletes all revisions associated with the provided collection.
+//
+// For better performance the revisions delete is executed as plain SQL statement,
+// aka. no delete model hook events will be fired.
+func (app *BaseApp) DeleteAllRevisionsByCollection(collection *Collection) error {
+	_, err := app.auxNonconcurrentDB.Delete(
+		RevisionsTableName,
+		dbx.HashExp{"collectionRef": collection.Id},
+	).Execute()
+
+	return err
+}
+
+// RevertRecord reverts the record fields to their state right after
+// the specified revision, aka. it undoes all newer revisions changes,
+// and saves the record.
+//
+// File and password fields are not reverted.
+func (app *BaseApp) RevertRecord(record *Record, revision *Revision) error {
+	return app.RevertRecordWithContext(context.Background(), record, revision)
+}
+
+// RevertRecordWithContext is the same as [App.RevertRecord] but allows
+// specifying a context to limit the db execution.
+func (app *BaseApp) RevertRecordWithContext(ctx context.Context, record *Record, revision *Revision) error {
+	if revision.CollectionRef != record.Collection().Id || revision.RecordRef != record.Id {
+		return errors.New("the revision doesn't belong to the record")
+	}
+
+	revisions, err := app.FindAllRevisionsByRecord(record)
+	if err != nil {
+		return err
+	}
+
+	index := -1
+	for i, r := range revisions {
+		if r.Id == revision.Id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return errors.New("missing record revision")
+	}
+
+	// undo the newer changes starting from the latest one
+	for i := len(revisions) - 1; i > index; i-- {
+		for _, field := range record.Collection().Fields {
+			switch field.(type) {
+			case *FileField, *PasswordField:
+				continue
+			}
+
+			if v, ok := revisions[i].OldValue(field.GetName()); ok {
+				record.Set(field.GetName(), v)
+			}
+		}
+	}
+
+	return app.SaveWithContext(ctx, record)
+}
@@ -0,0 +1,139 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestFindRevisionById(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createHistoryCollection(t, app)
+
+	record := core.NewRecord(collection)
+	record.Set("title", "a")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := app.FindAllRevisionsByRecord(record)
+	if err != nil || len(revisions) != 1 {
+		t.Fatalf("Expected 1 revision, got %d (%v)", len(revisions), err)
+	}
+
+	scenarios := []struct {
+		id          string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{revisions[0].Id, false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.id), func(t *testing.T) {
+			revision, err := app.FindRevisionById(s.id)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr to be %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if revision != nil && revision.Id != s.id {
+				t.Fatalf("Expected revision with id %q, got %q", s.id, revision.Id)
+			}
+		})
+	}
+}
+
+func TestRevertRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createHistoryCollection(t, app)
+
+	record := core.NewRecord(collection)
+	record.Set("title", "a")
+	record.Set("total", 1)
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.Set("title", "b")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err = app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.Set("title", "c")
+	record.Set("total", 3)
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := app.FindAllRevisionsByRecord(record)
+	if err != nil || len(revisions) != 3 {
+		t.Fatalf("Expected 3 revisions, got %d (%v)", len(revisions), err)
+	}
+
+	// revision of another record
+	other := core.NewRecord(collection)
+	if err := app.Save(other); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.RevertRecord(other, revisions[0]); err == nil {
+		t.Fatal("Expected error for revision of another record")
+	}
+
+	// revert to the create state
+	if err := app.RevertRecord(record, revisions[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh, err := app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := fresh.GetString("title"); v != "a" {
+		t.Fatalf("Expected title %q, got %q", "a", v)
+	}
+
+	if v := fresh.GetInt("total"); v != 1 {
+		t.Fatalf("Expected total %d, got %d", 1, v)
+	}
+
+	// the revert itself should be stored as a new revision
+	revisions, err = app.FindAllRevisionsByRecord(record)
+	if err != nil || len(revisions) != 4 {
+		t.Fatalf("Expected 4 revisions, got %d (%v)", len(revisions), err)
+	}
+
+	// revert to the "b" state
+	if err := app.RevertRecord(fresh, revisions[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := fresh.GetString("title"); v != "b" {
+		t.Fatalf("Expected title %q, got %q", "b", v)
+	}
+
+	if v := fresh.GetInt("total"); v != 1 {
+		t.Fatalf("Expected total %d, got %d", 1, v)
+	}
+}
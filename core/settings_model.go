@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"crypto"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -132,6 +133,10 @@ type settings struct {
 	TrustedProxy TrustedProxyConfig `form:"trustedProxy" json:"trustedProxy"`
 	Batch        BatchConfig        `form:"batch" json:"batch"`
 	Logs         LogsConfig         `form:"logs" json:"logs"`
+	Webhooks     WebhooksConfig     `form:"webhooks" json:"webhooks"`
+	SigningKeys  SigningKeysConfig  `form:"signingKeys" json:"signingKeys"`
+	OAuth2Server OAuth2ServerConfig `form:"oauth2Server" json:"oauth2Server"`
+	FileScan     FileScanConfig     `form:"fileScan" json:"fileScan"`
 }
 
 // Settings defines the PocketBase app settings.
@@ -169,6 +174,18 @@ func newDefaultSettings() *Settings {
 			Backups: BackupsConfig{
 				CronMaxKeep: 3,
 			},
+			Webhooks: WebhooksConfig{
+				MaxRetries: 5,
+				MaxDays:    7,
+			},
+			OAuth2Server: OAuth2ServerConfig{
+				RefreshTokenDuration: 2592000, // 30 days
+			},
+			FileScan: FileScanConfig{
+				Network: "tcp",
+				Address: "127.0.0.1:3310",
+				Timeout: 60,
+			},
 			Batch: BatchConfig{
 				Enabled:     false,
 				MaxRequests: 50,
@@ -300,6 +317,10 @@ func (s *Settings) PostValidate(ctx context.Context, app App) error {
 		validation.Field(&s.Batch),
 		validation.Field(&s.RateLimits),
 		validation.Field(&s.TrustedProxy),
+		validation.Field(&s.Webhooks),
+		validation.Field(&s.SigningKeys),
+		validation.Field(&s.OAuth2Server),
+		validation.Field(&s.FileScan),
 	)
 }
 
@@ -355,6 +376,20 @@ func (s *Settings) MarshalJSON() ([]byte, error) {
 		}
 	}
 
+	// mask the webhooks secrets
+	// (shallow copy to avoid modifying the original settings)
+	copy.Webhooks.Hooks = slices.Clone(copy.Webhooks.Hooks)
+	for i := range copy.Webhooks.Hooks {
+		copy.Webhooks.Hooks[i].Secret = ""
+	}
+
+	// mask the signing private keys
+	// (shallow copy to avoid modifying the original settings)
+	copy.SigningKeys.Keys = slices.Clone(copy.SigningKeys.Keys)
+	for i := range copy.SigningKeys.Keys {
+		copy.SigningKeys.Keys[i].PrivateKey = ""
+	}
+
 	// @todo remove with encoding/json/2
 	// serialize as empty array
 	if copy.SuperuserIPs == nil {
@@ -445,6 +480,29 @@ type S3Config struct {
 	AccessKey      string `form:"accessKey" json:"accessKey"`
 	Secret         string `form:"secret" json:"secret,omitempty"`
 	ForcePathStyle bool   `form:"forcePathStyle" json:"forcePathStyle"`
+
+	// PresignedUploads enables the direct to S3 record file uploads
+	// via presigned PUT URLs (applicable only for the app storage).
+	PresignedUploads bool `form:"presignedUploads" json:"presignedUploads"`
+
+	// PresignedDownloads enables redirecting the protected record file
+	// downloads to presigned GET URLs (applicable only for the app storage).
+	PresignedDownloads bool `form:"presignedDownloads" json:"presignedDownloads"`
+
+	// PresignExpiry specifies the validity duration in seconds of the generated presigned URLs.
+	//
+	// If not set, fallbacks to 300 (5 minutes).
+	PresignExpiry int64 `form:"presignExpiry" json:"presignExpiry"`
+}
+
+// PresignDuration returns the presigned URLs validity duration
+// (with fallback to 5 minutes if not set).
+func (c S3Config) PresignDuration() time.Duration {
+	if c.PresignExpiry <= 0 {
+		return 5 * time.Minute
+	}
+
+	return time.Duration(c.PresignExpiry) * time.Second
 }
 
 // Validate makes S3Config validatable by implementing [validation.Validatable] interface.
@@ -455,6 +513,7 @@ func (c S3Config) Validate() error {
 		validation.Field(&c.Region, validation.When(c.Enabled, validation.Required)),
 		validation.Field(&c.AccessKey, validation.When(c.Enabled, validation.Required)),
 		validation.Field(&c.Secret, validation.When(c.Enabled, validation.Required)),
+		validation.Field(&c.PresignExpiry, validation.Min(0), validation.Max(604800)),
 	)
 }
 
@@ -772,3 +831,562 @@ func (c RateLimitRule) String() string {
 
 	return string(raw)
 }
+
+// -------------------------------------------------------------------
+
+// The allowed WebhookConfig.Actions values.
+const (
+	WebhookActionCreate = "create"
+	WebhookActionUpdate = "update"
+	WebhookActionDelete = "delete"
+)
+
+type WebhooksConfig struct {
+	Hooks []WebhookConfig `form:"hooks" json:"hooks"`
+
+	// MaxRetries is the max number of automatic redelivery attempts
+	// of a failed webhook delivery (with exponential backoff).
+	MaxRetries int `form:"maxRetries" json:"maxRetries"`
+
+	// MaxDays is the number of days to keep the webhook deliveries log.
+	//
+	// Set it to 0 to keep the log forever.
+	MaxDays int `form:"maxDays" json:"maxDays"`
+}
+
+// FindHook returns the webhook configuration with the specified id (if exists).
+func (c *WebhooksConfig) FindHook(id string) (WebhookConfig, bool) {
+	for _, h := range c.Hooks {
+		if h.Id == id {
+			return h, true
+		}
+	}
+
+	return WebhookConfig{}, false
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (c WebhooksConfig) MarshalJSON() ([]byte, error) {
+	type alias WebhooksConfig
+
+	// serialize as empty array
+	if c.Hooks == nil {
+		c.Hooks = []WebhookConfig{}
+	}
+
+	return json.Marshal(alias(c))
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface.
+//
+// Because the webhook secrets are masked in the settings JSON serialization,
+// an empty secret of an already existing webhook (matched by its id)
+// is replaced with the existing one.
+//
+// Webhooks without id are assigned a new random one.
+func (c *WebhooksConfig) UnmarshalJSON(data []byte) error {
+	type alias WebhooksConfig
+
+	existingSecrets := make(map[string]string, len(c.Hooks))
+	for _, h := range c.Hooks {
+		existingSecrets[h.Id] = h.Secret
+	}
+
+	decoded := alias(*c)
+	decoded.Hooks = nil
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	for i, h := range decoded.Hooks {
+		if h.Id == "" {
+			decoded.Hooks[i].Id = GenerateDefaultRandomId()
+		} else if h.Secret == "" {
+			decoded.Hooks[i].Secret = existingSecrets[h.Id]
+		}
+	}
+
+	*c = WebhooksConfig(decoded)
+
+	return nil
+}
+
+// Validate makes WebhooksConfig validatable by implementing [validation.Validatable] interface.
+func (c WebhooksConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Hooks, validation.By(checkUniqueWebhookId)),
+		validation.Field(&c.MaxRetries, validation.Min(0), validation.Max(20)),
+		validation.Field(&c.MaxDays, validation.Min(0)),
+	)
+}
+
+func checkUniqueWebhookId(value any) error {
+	hooks, ok := value.([]WebhookConfig)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	existing := make(map[string]struct{}, len(hooks))
+
+	for i, h := range hooks {
+		if _, ok := existing[h.Id]; ok {
+			return validation.Errors{
+				strconv.Itoa(i): validation.Errors{
+					"id": validation.NewError("validation_duplicated_webhook_id", "Webhook with id {{.id}} already exists.").
+						SetParams(map[string]any{"id": h.Id}),
+				},
+			}
+		}
+		existing[h.Id] = struct{}{}
+	}
+
+	return nil
+}
+
+var webhookIdRegex = regexp.MustCompile(`^\w+$`)
+
+type WebhookConfig struct {
+	// Id is the unique identifier of the webhook (auto generated if not set).
+	Id string `form:"id" json:"id"`
+
+	// Name is an optional descriptive name of the webhook.
+	Name string `form:"name" json:"name"`
+
+	// URL is the endpoint where the webhook events will be POST-ed.
+	URL string `form:"url" json:"url"`
+
+	// Collections is a list of collection names or ids whose records
+	// changes trigger the webhook ("*" matches all non-system collections).
+	Collections []string `form:"collections" json:"collections"`
+
+	// Actions is a list of the record actions ("create", "update", "delete")
+	// that trigger the webhook (empty means all actions).
+	Actions []string `form:"actions" json:"actions"`
+
+	// Filter is an optional record filter expression (in the same format as the
+	// collection API rules but without the @request.* context) that the
+	// changed record must satisfy in order to trigger the webhook,
+	// e.g. "status = 'published' && total > 10".
+	Filter string `form:"filter" json:"filter"`
+
+	// Secret is an optional key used to sign the webhook requests
+	// with HMAC-SHA256 (see the X-PB-Signature request header).
+	Secret string `form:"secret" json:"secret,omitempty"`
+
+	Enabled bool `form:"enabled" json:"enabled"`
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (c WebhookConfig) MarshalJSON() ([]byte, error) {
+	type alias WebhookConfig
+
+	// serialize as empty array
+	if c.Collections == nil {
+		c.Collections = []string{}
+	}
+	if c.Actions == nil {
+		c.Actions = []string{}
+	}
+
+	return json.Marshal(alias(c))
+}
+
+// Validate makes WebhookConfig validatable by implementing [validation.Validatable] interface.
+func (c WebhookConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Id, validation.Required, validation.Length(1, 100), validation.Match(webhookIdRegex)),
+		validation.Field(&c.Name, validation.Length(0, 255)),
+		validation.Field(&c.URL, validation.Required, is.URL),
+		validation.Field(&c.Collections, validation.Required, validation.Each(validation.Required)),
+		validation.Field(&c.Actions, validation.Each(validation.In(
+			WebhookActionCreate,
+			WebhookActionUpdate,
+			WebhookActionDelete,
+		))),
+		validation.Field(&c.Filter, validation.Length(0, 2000)),
+		validation.Field(&c.Secret, validation.Length(0, 255)),
+	)
+}
+
+// matches reports whether the webhook should be triggered for the specified collection action.
+//
+// Note that the Filter is not checked here.
+func (c WebhookConfig) matches(collection *Collection, action string) bool {
+	if !c.Enabled {
+		return false
+	}
+
+	if len(c.Actions) > 0 && !slices.Contains(c.Actions, action) {
+		return false
+	}
+
+	if slices.Contains(c.Collections, collection.Name) || slices.Contains(c.Collections, collection.Id) {
+		return true
+	}
+
+	// the system collections (e.g. _superusers) must be listed explicitly
+	return !collection.System && slices.Contains(c.Collections, "*")
+}
+
+// -------------------------------------------------------------------
+
+var signingKeyIdRegex = regexp.MustCompile(`^[\w\-]+$`)
+
+type SigningKeysConfig struct {
+	// Keys is a list with the app asymmetric JWT signing keys
+	// (e.g. used for signing the OAuth2 server ID tokens).
+	//
+	// The first key is the active one and is used for signing new tokens,
+	// while the rest are only published (see the "/.well-known/jwks.json" endpoint)
+	// so that the already issued tokens could be still verified.
+	Keys []SigningKeyConfig `form:"keys" json:"keys"`
+
+	// AuthTokens specifies whether to sign the new record auth tokens with
+	// the active signing key instead of the per-record HMAC secret, allowing
+	// other services to verify them with the "/.well-known/jwks.json" public keys.
+	//
+	// The already issued tokens remain valid in both modes until their expiration.
+	AuthTokens bool `form:"authTokens" json:"authTokens"`
+
+	// RotationPeriod specifies how often (in seconds) a new active
+	// signing key should be generated automatically.
+	//
+	// Leave it zero to disable the automatic rotation.
+	RotationPeriod int64 `form:"rotationPeriod" json:"rotationPeriod"`
+
+	// RotationOverlap specifies how long (in seconds) the previous signing keys
+	// remain valid and published after a rotation.
+	//
+	// The app rotations always extend it to the longest auth collection
+	// token duration so that the already issued tokens remain verifiable
+	// until their expiration (see [App.RotateSigningKeys]).
+	//
+	// If zero, fallback to 7 days.
+	RotationOverlap int64 `form:"rotationOverlap" json:"rotationOverlap"`
+}
+
+// RotationPeriodTime returns the current rotation period in [time.Duration] format.
+func (c SigningKeysConfig) RotationPeriodTime() time.Duration {
+	return time.Duration(c.RotationPeriod) * time.Second
+}
+
+// RotationOverlapTime returns the current rotation overlap in [time.Duration] format.
+func (c SigningKeysConfig) RotationOverlapTime() time.Duration {
+	if c.RotationOverlap <= 0 {
+		return 7 * 24 * time.Hour
+	}
+
+	return time.Duration(c.RotationOverlap) * time.Second
+}
+
+// NewSigningKeyConfig generates a new signing key configuration
+// with random id for the specified algorithm.
+func NewSigningKeyConfig(algorithm string) (SigningKeyConfig, error) {
+	privateKey, err := security.GenerateSigningKey(algorithm)
+	if err != nil {
+		return SigningKeyConfig{}, err
+	}
+
+	return SigningKeyConfig{
+		Id:         GenerateDefaultRandomId(),
+		Algorithm:  algorithm,
+		PrivateKey: privateKey,
+		Created:    types.NowDateTime(),
+	}, nil
+}
+
+// ActiveKey returns the signing key that should be used for signing new tokens.
+func (c *SigningKeysConfig) ActiveKey() (SigningKeyConfig, bool) {
+	if len(c.Keys) == 0 {
+		return SigningKeyConfig{}, false
+	}
+
+	return c.Keys[0], true
+}
+
+// FindKey returns the non-expired signing key with the specified id (if exists).
+func (c *SigningKeysConfig) FindKey(id string) (SigningKeyConfig, bool) {
+	for _, k := range c.Keys {
+		if k.Id == id && !k.IsExpired() {
+			return k, true
+		}
+	}
+
+	return SigningKeyConfig{}, false
+}
+
+// NeedsRotation reports whether the active signing key is older than the RotationPeriod.
+//
+// Active keys without creation date are always considered due for rotation
+// (if the automatic rotation is enabled).
+func (c *SigningKeysConfig) NeedsRotation() bool {
+	if c.RotationPeriod <= 0 {
+		return false
+	}
+
+	active, ok := c.ActiveKey()
+	if !ok {
+		return false // nothing to rotate
+	}
+
+	if active.Created.IsZero() {
+		return true
+	}
+
+	return time.Since(active.Created.Time()) >= c.RotationPeriodTime()
+}
+
+// Rotate generates and prepends a new active signing key for the specified algorithm
+// (fallbacks to the current active key algorithm or RS256 if empty).
+//
+// The previous keys remain valid for RotationOverlap seconds (or minOverlap
+// if longer, e.g. the max issued token duration) and the already expired ones are removed.
+func (c *SigningKeysConfig) Rotate(algorithm string, minOverlap time.Duration) (SigningKeyConfig, error) {
+	if algorithm == "" {
+		algorithm = security.SigningAlgRS256
+		if active, ok := c.ActiveKey(); ok && active.Algorithm != "" {
+			algorithm = active.Algorithm
+		}
+	}
+
+	newKey, err := NewSigningKeyConfig(algorithm)
+	if err != nil {
+		return SigningKeyConfig{}, err
+	}
+
+	expires := types.NowDateTime().Add(max(c.RotationOverlapTime(), minOverlap))
+
+	keys := make([]SigningKeyConfig, 0, len(c.Keys)+1)
+	keys = append(keys, newKey)
+	for _, k := range c.Keys {
+		if k.IsExpired() {
+			continue
+		}
+
+		if k.Expires.IsZero() || k.Expires.After(expires) {
+			k.Expires = expires
+		}
+
+		keys = append(keys, k)
+	}
+
+	c.Keys = keys
+
+	return newKey, nil
+}
+
+// PublicJWKs returns the public JWK representation of all non-expired signing keys.
+func (c *SigningKeysConfig) PublicJWKs() ([]*security.JWK, error) {
+	result := make([]*security.JWK, 0, len(c.Keys))
+
+	for _, k := range c.Keys {
+		if k.IsExpired() {
+			continue
+		}
+
+		signer, err := k.Signer()
+		if err != nil {
+			return nil, fmt.Errorf("signing key %q: %w", k.Id, err)
+		}
+
+		jwk, err := security.NewPublicJWK(k.Id, k.Algorithm, signer.Public())
+		if err != nil {
+			return nil, fmt.Errorf("signing key %q: %w", k.Id, err)
+		}
+
+		result = append(result, jwk)
+	}
+
+	return result, nil
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (c SigningKeysConfig) MarshalJSON() ([]byte, error) {
+	type alias SigningKeysConfig
+
+	// serialize as empty array
+	if c.Keys == nil {
+		c.Keys = []SigningKeyConfig{}
+	}
+
+	return json.Marshal(alias(c))
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface.
+//
+// Because the private keys are masked in the settings JSON serialization,
+// an empty private key of an already existing signing key (matched by its id)
+// is replaced with the existing one.
+func (c *SigningKeysConfig) UnmarshalJSON(data []byte) error {
+	type alias SigningKeysConfig
+
+	existingKeys := make(map[string]string, len(c.Keys))
+	for _, k := range c.Keys {
+		existingKeys[k.Id] = k.PrivateKey
+	}
+
+	decoded := alias(*c)
+	decoded.Keys = nil
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	for i, k := range decoded.Keys {
+		if k.PrivateKey == "" {
+			decoded.Keys[i].PrivateKey = existingKeys[k.Id]
+		}
+	}
+
+	*c = SigningKeysConfig(decoded)
+
+	return nil
+}
+
+// Validate makes SigningKeysConfig validatable by implementing [validation.Validatable] interface.
+func (c SigningKeysConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Keys, validation.By(checkUniqueSigningKeyId)),
+		validation.Field(&c.RotationPeriod, validation.When(c.RotationPeriod != 0, validation.Min(int64(3600)))),
+		validation.Field(&c.RotationOverlap, validation.Min(int64(0))),
+	)
+}
+
+func checkUniqueSigningKeyId(value any) error {
+	keys, ok := value.([]SigningKeyConfig)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	existing := make(map[string]struct{}, len(keys))
+
+	for i, k := range keys {
+		if _, ok := existing[k.Id]; ok {
+			return validation.Errors{
+				strconv.Itoa(i): validation.Errors{
+					"id": validation.NewError("validation_duplicated_signing_key_id", "Signing key with id {{.id}} already exists.").
+						SetParams(map[string]any{"id": k.Id}),
+				},
+			}
+		}
+		existing[k.Id] = struct{}{}
+	}
+
+	return nil
+}
+
+type SigningKeyConfig struct {
+	// Id is the unique identifier of the key (aka. the JWT "kid" header).
+	Id string `form:"id" json:"id"`
+
+	// Algorithm is the JWT signing algorithm of the key ("RS256" or "EdDSA").
+	Algorithm string `form:"algorithm" json:"algorithm"`
+
+	// PrivateKey is the PEM encoded (PKCS #8) private key.
+	PrivateKey string `form:"privateKey" json:"privateKey,omitempty"`
+
+	// Created is the key creation date (used for the automatic rotation).
+	Created types.DateTime `form:"created" json:"created"`
+
+	// Expires is the date after which a rotated key is no longer
+	// accepted for verification nor published.
+	//
+	// Zero value means no expiration.
+	Expires types.DateTime `form:"expires" json:"expires"`
+}
+
+// IsExpired reports whether the key has an expiration date in the past.
+func (c SigningKeyConfig) IsExpired() bool {
+	return !c.Expires.IsZero() && c.Expires.Before(types.NowDateTime())
+}
+
+// Signer parses and returns the configured private key.
+func (c SigningKeyConfig) Signer() (crypto.Signer, error) {
+	return security.ParseSigningKey(c.Algorithm, c.PrivateKey)
+}
+
+// Validate makes SigningKeyConfig validatable by implementing [validation.Validatable] interface.
+func (c SigningKeyConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Id, validation.Required, validation.Length(1, 100), validation.Match(signingKeyIdRegex)),
+		validation.Field(&c.Algorithm, validation.Required, validation.In(security.SigningAlgRS256, security.SigningAlgEdDSA)),
+		validation.Field(&c.PrivateKey, validation.Required, validation.By(func(value any) error {
+			if c.Algorithm == "" {
+				return nil // already checked by the algorithm validator
+			}
+
+			if _, err := c.Signer(); err != nil {
+				return validation.NewError("validation_invalid_private_key", "Invalid or incompatible PEM encoded private key.")
+			}
+
+			return nil
+		})),
+	)
+}
+
+// -------------------------------------------------------------------
+
+type OAuth2ServerConfig struct {
+	// ConsentURL is the address of the app frontend page where the users
+	// are redirected to sign in and approve (or deny) the OAuth2 client
+	// authorization request.
+	//
+	// The pending authorization request id is appended to the url
+	// as "request" query parameter.
+	ConsentURL string `form:"consentURL" json:"consentURL"`
+
+	// RefreshTokenDuration specifies how long (in seconds)
+	// the issued OAuth2 refresh tokens are valid.
+	RefreshTokenDuration int64 `form:"refreshTokenDuration" json:"refreshTokenDuration"`
+
+	// Enabled specifies whether the app could act as OAuth2 and
+	// OpenID Connect provider for the registered OAuth2 clients.
+	Enabled bool `form:"enabled" json:"enabled"`
+}
+
+// RefreshTokenDurationTime returns the current refresh token duration in [time.Duration] format.
+func (c OAuth2ServerConfig) RefreshTokenDurationTime() time.Duration {
+	return time.Duration(c.RefreshTokenDuration) * time.Second
+}
+
+// Validate makes OAuth2ServerConfig validatable by implementing [validation.Validatable] interface.
+func (c OAuth2ServerConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.ConsentURL, validation.When(c.Enabled, validation.Required), is.URL),
+		validation.Field(&c.RefreshTokenDuration, validation.When(c.Enabled, validation.Required), validation.Min(60)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+type FileScanConfig struct {
+	// Network is the clamd socket network type - "tcp" or "unix".
+	Network string `form:"network" json:"network"`
+
+	// Address is the clamd socket address
+	// (e.g. "127.0.0.1:3310" or "/var/run/clamav/clamd.ctl").
+	Address string `form:"address" json:"address"`
+
+	// Timeout is the max duration in seconds of a single file scan.
+	Timeout int64 `form:"timeout" json:"timeout"`
+
+	// FailOpen specifies whether to accept the uploaded files when
+	// the scan couldn't be completed (e.g. clamd is unreachable).
+	//
+	// By default the uploads are rejected (aka. fail closed).
+	FailOpen bool `form:"failOpen" json:"failOpen"`
+
+	// Enabled specifies whether to scan the newly uploaded
+	// record files with the built-in clamd client.
+	Enabled bool `form:"enabled" json:"enabled"`
+}
+
+// Validate makes FileScanConfig validatable by implementing [validation.Validatable] interface.
+func (c FileScanConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Network, validation.When(c.Enabled, validation.Required), validation.In("tcp", "unix")),
+		validation.Field(&c.Address, validation.When(c.Enabled, validation.Required), validation.Length(0, 255)),
+		validation.Field(&c.Timeout, validation.When(c.Enabled, validation.Required), validation.Min(0)),
+	)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tools/mailer"
 	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 func TestSettingsDelete(t *testing.T) {
@@ -61,6 +63,7 @@ func TestSettings_DBExport(t *testing.T) {
 			settings.Batch.Timeout = 15
 			settings.RateLimits.Enabled = true
 			settings.TrustedProxy.UseLeftmostIP = true
+			settings.SigningKeys.Keys = []core.SigningKeyConfig{{Id: "abc", Algorithm: "RS256", PrivateKey: "test_private_key"}}
 
 			if s.encryption {
 				os.Setenv(app.EncryptionEnv(), encryptionKey)
@@ -84,7 +87,7 @@ func TestSettings_DBExport(t *testing.T) {
 				valueStr = string(export["value"].([]byte))
 			}
 
-			expected := `{"superuserIPs":[],"smtp":{"enabled":false,"port":0,"host":"smtp_host","username":"smtp_username","password":"","authMethod":"","tls":false,"localName":""},"backups":{"cron":"* * * * *","cronMaxKeep":0,"s3":{"enabled":true,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false}},"s3":{"enabled":false,"bucket":"","region":"","endpoint":"s3_endpoint","accessKey":"","secret":"s3_secret","forcePathStyle":false},"meta":{"accentColor":"","appName":"test_app_name","appURL":"","senderName":"","senderAddress":"","hideControls":false},"rateLimits":{"rules":[],"excludedIPs":[],"enabled":true},"trustedProxy":{"headers":[],"useLeftmostIP":true},"batch":{"enabled":false,"maxRequests":0,"timeout":15,"maxBodySize":0},"logs":{"maxDays":123,"minLevel":0,"logIP":false,"logAuthId":false}}`
+			expected := `{"superuserIPs":[],"smtp":{"enabled":false,"port":0,"host":"smtp_host","username":"smtp_username","password":"","authMethod":"","tls":false,"localName":""},"backups":{"cron":"* * * * *","cronMaxKeep":0,"s3":{"enabled":true,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false,"presignedUploads":false,"presignedDownloads":false,"presignExpiry":0}},"s3":{"enabled":false,"bucket":"","region":"","endpoint":"s3_endpoint","accessKey":"","secret":"s3_secret","forcePathStyle":false,"presignedUploads":false,"presignedDownloads":false,"presignExpiry":0},"meta":{"accentColor":"","appName":"test_app_name","appURL":"","senderName":"","senderAddress":"","hideControls":false},"rateLimits":{"rules":[],"excludedIPs":[],"enabled":true},"trustedProxy":{"headers":[],"useLeftmostIP":true},"batch":{"enabled":false,"maxRequests":0,"timeout":15,"maxBodySize":0},"logs":{"maxDays":123,"minLevel":0,"logIP":false,"logAuthId":false},"webhooks":{"hooks":[],"maxRetries":0,"maxDays":0},"signingKeys":{"keys":[{"id":"abc","algorithm":"RS256","privateKey":"test_private_key","created":"","expires":""}],"authTokens":false,"rotationPeriod":0,"rotationOverlap":0},"oauth2Server":{"consentURL":"","refreshTokenDuration":0,"enabled":false},"fileScan":{"network":"","address":"","timeout":0,"failOpen":false,"enabled":false}}`
 			if valueStr != expected {
 				t.Fatalf("Expected exported settings\n%s\ngot\n%s", expected, valueStr)
 			}
@@ -173,6 +176,8 @@ func TestSettingsMarshalJSON(t *testing.T) {
 	settings.SMTP.Password = testSecret
 	settings.S3.Secret = testSecret
 	settings.Backups.S3.Secret = testSecret
+	settings.Webhooks.Hooks = []core.WebhookConfig{{Id: "abc", URL: "https://example.com", Secret: testSecret}}
+	settings.SigningKeys.Keys = []core.SigningKeyConfig{{Id: "abc", Algorithm: "RS256", PrivateKey: testSecret}}
 
 	raw, err := json.Marshal(settings)
 	if err != nil {
@@ -180,7 +185,7 @@ func TestSettingsMarshalJSON(t *testing.T) {
 	}
 	rawStr := string(raw)
 
-	expected := `{"superuserIPs":[],"smtp":{"enabled":false,"port":0,"host":"","username":"abc","authMethod":"","tls":false,"localName":""},"backups":{"cron":"","cronMaxKeep":0,"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false}},"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false},"meta":{"accentColor":"","appName":"test123","appURL":"","senderName":"","senderAddress":"","hideControls":false},"rateLimits":{"rules":[],"excludedIPs":[],"enabled":false},"trustedProxy":{"headers":[],"useLeftmostIP":false},"batch":{"enabled":false,"maxRequests":0,"timeout":0,"maxBodySize":0},"logs":{"maxDays":0,"minLevel":0,"logIP":false,"logAuthId":false}}`
+	expected := `{"superuserIPs":[],"smtp":{"enabled":false,"port":0,"host":"","username":"abc","authMethod":"","tls":false,"localName":""},"backups":{"cron":"","cronMaxKeep":0,"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false,"presignedUploads":false,"presignedDownloads":false,"presignExpiry":0}},"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false,"presignedUploads":false,"presignedDownloads":false,"presignExpiry":0},"meta":{"accentColor":"","appName":"test123","appURL":"","senderName":"","senderAddress":"","hideControls":false},"rateLimits":{"rules":[],"excludedIPs":[],"enabled":false},"trustedProxy":{"headers":[],"useLeftmostIP":false},"batch":{"enabled":false,"maxRequests":0,"timeout":0,"maxBodySize":0},"logs":{"maxDays":0,"minLevel":0,"logIP":false,"logAuthId":false},"webhooks":{"hooks":[{"id":"abc","name":"","url":"https://example.com","collections":[],"actions":[],"filter":"","enabled":false}],"maxRetries":0,"maxDays":0},"signingKeys":{"keys":[{"id":"abc","algorithm":"RS256","created":"","expires":""}],"authTokens":false,"rotationPeriod":0,"rotationOverlap":0},"oauth2Server":{"consentURL":"","refreshTokenDuration":0,"enabled":false},"fileScan":{"network":"","address":"","timeout":0,"failOpen":false,"enabled":false}}`
 
 	if rawStr != expected {
 		t.Fatalf("Expected\n%v\ngot\n%v", expected, rawStr)
@@ -210,6 +215,12 @@ func TestSettingsValidate(t *testing.T) {
 	s.Batch.Timeout = -1
 	s.RateLimits.Enabled = true
 	s.RateLimits.Rules = nil
+	s.Webhooks.MaxRetries = -1
+	s.SigningKeys.Keys = []core.SigningKeyConfig{{}}
+	s.OAuth2Server.Enabled = true
+	s.OAuth2Server.ConsentURL = ""
+	s.FileScan.Enabled = true
+	s.FileScan.Address = ""
 
 	// check if Validate() is triggering the members validate methods.
 	err := app.Validate(s)
@@ -226,6 +237,10 @@ func TestSettingsValidate(t *testing.T) {
 		`"backups":{`,
 		`"batch":{`,
 		`"rateLimits":{`,
+		`"webhooks":{`,
+		`"signingKeys":{`,
+		`"oauth2Server":{`,
+		`"fileScan":{`,
 	}
 
 	errBytes, _ := json.Marshal(err)
@@ -328,6 +343,657 @@ func TestLogsConfigValidate(t *testing.T) {
 	}
 }
 
+func TestWebhooksConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	validHook := core.WebhookConfig{
+		Id:          "test",
+		URL:         "https://example.com",
+		Collections: []string{"demo1"},
+	}
+
+	scenarios := []struct {
+		name           string
+		config         core.WebhooksConfig
+		expectedErrors []string
+	}{
+		{
+			"zero values",
+			core.WebhooksConfig{},
+			[]string{},
+		},
+		{
+			"invalid data",
+			core.WebhooksConfig{
+				MaxRetries: -1,
+				MaxDays:    -1,
+				Hooks:      []core.WebhookConfig{{}},
+			},
+			[]string{"maxRetries", "maxDays", "hooks"},
+		},
+		{
+			"duplicated hook ids",
+			core.WebhooksConfig{
+				Hooks: []core.WebhookConfig{validHook, validHook},
+			},
+			[]string{"hooks"},
+		},
+		{
+			"valid data",
+			core.WebhooksConfig{
+				MaxRetries: 5,
+				MaxDays:    2,
+				Hooks:      []core.WebhookConfig{validHook},
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestWebhookConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name           string
+		config         core.WebhookConfig
+		expectedErrors []string
+	}{
+		{
+			"zero values",
+			core.WebhookConfig{},
+			[]string{"id", "url", "collections"},
+		},
+		{
+			"invalid data",
+			core.WebhookConfig{
+				Id:          "a b",
+				Name:        strings.Repeat("a", 256),
+				URL:         "invalid",
+				Collections: []string{""},
+				Actions:     []string{"create", "view"},
+				Secret:      strings.Repeat("a", 256),
+			},
+			[]string{"id", "name", "url", "collections", "actions", "secret"},
+		},
+		{
+			"valid data",
+			core.WebhookConfig{
+				Id:          "test",
+				URL:         "https://example.com",
+				Collections: []string{"*"},
+				Actions:     []string{"create", "update", "delete"},
+				Filter:      "title != ''",
+				Secret:      "test",
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestWebhooksConfigUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	config := core.WebhooksConfig{
+		Hooks: []core.WebhookConfig{
+			{Id: "a", Secret: "secret_a"},
+			{Id: "b", Secret: "secret_b"},
+		},
+	}
+
+	raw := `{"hooks":[{"id":"a"},{"id":"b","secret":"new_b"},{"id":"c"},{"url":"https://example.com"}]}`
+
+	if err := json.Unmarshal([]byte(raw), &config); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.Hooks) != 4 {
+		t.Fatalf("Expected 4 hooks, got %d", len(config.Hooks))
+	}
+
+	expectedSecrets := []string{"secret_a", "new_b", "", ""}
+	for i, secret := range expectedSecrets {
+		if config.Hooks[i].Secret != secret {
+			t.Errorf("[%d] Expected secret %q, got %q", i, secret, config.Hooks[i].Secret)
+		}
+	}
+
+	if config.Hooks[3].Id == "" {
+		t.Fatal("Expected the missing hook id to be generated")
+	}
+}
+
+func TestSigningKeysConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	validKey, err := core.NewSigningKeyConfig(security.SigningAlgRS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name           string
+		config         core.SigningKeysConfig
+		expectedErrors []string
+	}{
+		{
+			"zero values",
+			core.SigningKeysConfig{},
+			[]string{},
+		},
+		{
+			"invalid key",
+			core.SigningKeysConfig{
+				Keys: []core.SigningKeyConfig{{}},
+			},
+			[]string{"keys"},
+		},
+		{
+			"duplicated key ids",
+			core.SigningKeysConfig{
+				Keys: []core.SigningKeyConfig{validKey, validKey},
+			},
+			[]string{"keys"},
+		},
+		{
+			"invalid rotation settings",
+			core.SigningKeysConfig{
+				RotationPeriod:  3599,
+				RotationOverlap: -1,
+			},
+			[]string{"rotationPeriod", "rotationOverlap"},
+		},
+		{
+			"valid data",
+			core.SigningKeysConfig{
+				Keys:            []core.SigningKeyConfig{validKey},
+				AuthTokens:      true,
+				RotationPeriod:  3600,
+				RotationOverlap: 86400,
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestSigningKeyConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	validKey, err := core.NewSigningKeyConfig(security.SigningAlgRS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validEdKey, err := core.NewSigningKeyConfig(security.SigningAlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name           string
+		config         core.SigningKeyConfig
+		expectedErrors []string
+	}{
+		{
+			"zero values",
+			core.SigningKeyConfig{},
+			[]string{"id", "algorithm", "privateKey"},
+		},
+		{
+			"invalid data",
+			core.SigningKeyConfig{
+				Id:         "a b",
+				Algorithm:  "HS256",
+				PrivateKey: "invalid",
+			},
+			[]string{"id", "algorithm", "privateKey"},
+		},
+		{
+			"invalid private key",
+			core.SigningKeyConfig{
+				Id:         "test",
+				Algorithm:  security.SigningAlgRS256,
+				PrivateKey: "invalid",
+			},
+			[]string{"privateKey"},
+		},
+		{
+			"incompatible private key",
+			core.SigningKeyConfig{
+				Id:         "test",
+				Algorithm:  security.SigningAlgEdDSA,
+				PrivateKey: validKey.PrivateKey,
+			},
+			[]string{"privateKey"},
+		},
+		{
+			"valid data",
+			validKey,
+			[]string{},
+		},
+		{
+			"valid data (EdDSA)",
+			validEdKey,
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestSigningKeysConfigUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	config := core.SigningKeysConfig{
+		Keys: []core.SigningKeyConfig{
+			{Id: "a", PrivateKey: "key_a"},
+			{Id: "b", PrivateKey: "key_b"},
+		},
+	}
+
+	raw := `{"keys":[{"id":"b"},{"id":"a","privateKey":"new_a"},{"id":"c"}]}`
+
+	if err := json.Unmarshal([]byte(raw), &config); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.Keys) != 3 {
+		t.Fatalf("Expected 3 keys, got %d", len(config.Keys))
+	}
+
+	expectedKeys := []string{"key_b", "new_a", ""}
+	for i, key := range expectedKeys {
+		if config.Keys[i].PrivateKey != key {
+			t.Errorf("[%d] Expected private key %q, got %q", i, key, config.Keys[i].PrivateKey)
+		}
+	}
+}
+
+func TestSigningKeysConfigKeys(t *testing.T) {
+	t.Parallel()
+
+	config := core.SigningKeysConfig{}
+
+	if _, ok := config.ActiveKey(); ok {
+		t.Fatal("Expected no active key")
+	}
+
+	key1, err := core.NewSigningKeyConfig(security.SigningAlgRS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key2, err := core.NewSigningKeyConfig(security.SigningAlgRS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key1.Id == key2.Id {
+		t.Fatal("Expected the generated keys to have different ids")
+	}
+
+	config.Keys = []core.SigningKeyConfig{key1, key2}
+
+	active, ok := config.ActiveKey()
+	if !ok || active.Id != key1.Id {
+		t.Fatalf("Expected active key %q, got %q", key1.Id, active.Id)
+	}
+
+	if found, ok := config.FindKey(key2.Id); !ok || found.Id != key2.Id {
+		t.Fatalf("Expected to find key %q", key2.Id)
+	}
+
+	if _, ok := config.FindKey("missing"); ok {
+		t.Fatal("Expected missing key")
+	}
+
+	jwks, err := config.PublicJWKs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(jwks) != 2 || jwks[0].Kid != key1.Id || jwks[1].Kid != key2.Id {
+		t.Fatalf("Unexpected JWKs %v", jwks)
+	}
+
+	// expired keys should be ignored
+	config.Keys[1].Expires = types.NowDateTime().Add(-1 * time.Second)
+
+	if _, ok := config.FindKey(key2.Id); ok {
+		t.Fatalf("Expected expired key %q to be ignored", key2.Id)
+	}
+
+	jwks, err = config.PublicJWKs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(jwks) != 1 || jwks[0].Kid != key1.Id {
+		t.Fatalf("Expected only the non-expired JWK, got %v", jwks)
+	}
+
+	config.Keys = append(config.Keys, core.SigningKeyConfig{Id: "invalid", Algorithm: security.SigningAlgRS256})
+	if _, err := config.PublicJWKs(); err == nil {
+		t.Fatal("Expected invalid key error")
+	}
+}
+
+func TestSigningKeyConfigIsExpired(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name     string
+		expires  types.DateTime
+		expected bool
+	}{
+		{"zero expires", types.DateTime{}, false},
+		{"future expires", types.NowDateTime().Add(1 * time.Minute), false},
+		{"past expires", types.NowDateTime().Add(-1 * time.Minute), true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			key := core.SigningKeyConfig{Expires: s.expires}
+
+			if v := key.IsExpired(); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestSigningKeysConfigRotationOverlapTime(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		overlap  int64
+		expected time.Duration
+	}{
+		{-1, 7 * 24 * time.Hour},
+		{0, 7 * 24 * time.Hour},
+		{3600, 1 * time.Hour},
+	}
+
+	for _, s := range scenarios {
+		t.Run(fmt.Sprintf("%d", s.overlap), func(t *testing.T) {
+			config := core.SigningKeysConfig{RotationOverlap: s.overlap}
+
+			if v := config.RotationOverlapTime(); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestSigningKeysConfigNeedsRotation(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name     string
+		config   core.SigningKeysConfig
+		expected bool
+	}{
+		{
+			"disabled rotation",
+			core.SigningKeysConfig{
+				Keys: []core.SigningKeyConfig{{Id: "a", Created: types.NowDateTime().Add(-48 * time.Hour)}},
+			},
+			false,
+		},
+		{
+			"no keys",
+			core.SigningKeysConfig{RotationPeriod: 3600},
+			false,
+		},
+		{
+			"active key without created date",
+			core.SigningKeysConfig{
+				RotationPeriod: 3600,
+				Keys:           []core.SigningKeyConfig{{Id: "a"}},
+			},
+			true,
+		},
+		{
+			"recent active key",
+			core.SigningKeysConfig{
+				RotationPeriod: 3600,
+				Keys: []core.SigningKeyConfig{
+					{Id: "a", Created: types.NowDateTime().Add(-30 * time.Minute)},
+					{Id: "b", Created: types.NowDateTime().Add(-48 * time.Hour)},
+				},
+			},
+			false,
+		},
+		{
+			"old active key",
+			core.SigningKeysConfig{
+				RotationPeriod: 3600,
+				Keys:           []core.SigningKeyConfig{{Id: "a", Created: types.NowDateTime().Add(-2 * time.Hour)}},
+			},
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if v := s.config.NeedsRotation(); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestSigningKeysConfigRotate(t *testing.T) {
+	t.Parallel()
+
+	edKey, err := core.NewSigningKeyConfig(security.SigningAlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	farExpires := types.NowDateTime().Add(30 * 24 * time.Hour)
+	nearExpires := types.NowDateTime().Add(1 * time.Minute)
+
+	config := core.SigningKeysConfig{
+		RotationOverlap: 3600,
+		Keys: []core.SigningKeyConfig{
+			edKey,
+			{Id: "far", Algorithm: security.SigningAlgRS256, Expires: farExpires},
+			{Id: "near", Algorithm: security.SigningAlgRS256, Expires: nearExpires},
+			{Id: "expired", Algorithm: security.SigningAlgRS256, Expires: types.NowDateTime().Add(-1 * time.Minute)},
+		},
+	}
+
+	if _, err := config.Rotate("HS256", 0); err == nil {
+		t.Fatal("Expected unsupported algorithm error")
+	}
+
+	// fallback to the active key algorithm
+	newKey, err := config.Rotate("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if newKey.Algorithm != security.SigningAlgEdDSA {
+		t.Fatalf("Expected the new key algorithm %q, got %q", security.SigningAlgEdDSA, newKey.Algorithm)
+	}
+
+	if newKey.Created.IsZero() || !newKey.Expires.IsZero() {
+		t.Fatalf("Expected the new key to have created and no expires date, got %v", newKey)
+	}
+
+	ids := make([]string, len(config.Keys))
+	for i, k := range config.Keys {
+		ids[i] = k.Id
+	}
+
+	expectedIds := []string{newKey.Id, edKey.Id, "far", "near"}
+	if !slices.Equal(ids, expectedIds) {
+		t.Fatalf("Expected keys\n%v\ngot\n%v", expectedIds, ids)
+	}
+
+	if active, _ := config.ActiveKey(); active.Id != newKey.Id {
+		t.Fatalf("Expected the new key to be active, got %q", active.Id)
+	}
+
+	maxExpires := types.NowDateTime().Add(config.RotationOverlapTime())
+
+	// the previous keys expiration should be capped to the rotation overlap
+	for _, k := range config.Keys[1:3] {
+		if k.Expires.IsZero() || k.Expires.After(maxExpires) || k.Expires.Before(nearExpires) {
+			t.Errorf("Expected key %q to expire within the rotation overlap, got %v", k.Id, k.Expires)
+		}
+	}
+
+	// the sooner expiration should be preserved
+	if config.Keys[3].Expires.String() != nearExpires.String() {
+		t.Errorf("Expected key %q expires %v, got %v", config.Keys[3].Id, nearExpires, config.Keys[3].Expires)
+	}
+
+	// explicit algorithm
+	rsaKey, err := config.Rotate(security.SigningAlgRS256, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rsaKey.Algorithm != security.SigningAlgRS256 {
+		t.Fatalf("Expected the new key algorithm %q, got %q", security.SigningAlgRS256, rsaKey.Algorithm)
+	}
+}
+
+func TestOAuth2ServerConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name           string
+		config         core.OAuth2ServerConfig
+		expectedErrors []string
+	}{
+		{
+			"zero values (disabled)",
+			core.OAuth2ServerConfig{},
+			[]string{},
+		},
+		{
+			"zero values (enabled)",
+			core.OAuth2ServerConfig{Enabled: true},
+			[]string{"consentURL", "refreshTokenDuration"},
+		},
+		{
+			"invalid data",
+			core.OAuth2ServerConfig{
+				ConsentURL:           "invalid",
+				RefreshTokenDuration: 59,
+			},
+			[]string{"consentURL", "refreshTokenDuration"},
+		},
+		{
+			"valid data",
+			core.OAuth2ServerConfig{
+				Enabled:              true,
+				ConsentURL:           "https://example.com/consent",
+				RefreshTokenDuration: 60,
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestFileScanConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name           string
+		config         core.FileScanConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.FileScanConfig{},
+			[]string{},
+		},
+		{
+			"zero value (enabled)",
+			core.FileScanConfig{Enabled: true},
+			[]string{"network", "address", "timeout"},
+		},
+		{
+			"invalid data",
+			core.FileScanConfig{
+				Network: "udp",
+				Address: strings.Repeat("a", 256),
+				Timeout: -1,
+			},
+			[]string{"network", "address", "timeout"},
+		},
+		{
+			"valid data",
+			core.FileScanConfig{
+				Enabled: true,
+				Network: "unix",
+				Address: "/var/run/clamav/clamd.ctl",
+				Timeout: 30,
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestOAuth2ServerConfigRefreshTokenDurationTime(t *testing.T) {
+	t.Parallel()
+
+	config := core.OAuth2ServerConfig{RefreshTokenDuration: 90}
+
+	if v := config.RefreshTokenDurationTime(); v != 90*time.Second {
+		t.Fatalf("Expected %v, got %v", 90*time.Second, v)
+	}
+}
+
 func TestSMTPConfigValidate(t *testing.T) {
 	t.Parallel()
 
@@ -451,6 +1117,21 @@ func TestS3ConfigValidate(t *testing.T) {
 			},
 			[]string{},
 		},
+		{
+			"negative presignExpiry",
+			core.S3Config{PresignExpiry: -1},
+			[]string{"presignExpiry"},
+		},
+		{
+			"presignExpiry > 7 days",
+			core.S3Config{PresignExpiry: 604801},
+			[]string{"presignExpiry"},
+		},
+		{
+			"valid presignExpiry",
+			core.S3Config{PresignExpiry: 604800},
+			[]string{},
+		},
 	}
 
 	for _, s := range scenarios {
@@ -462,6 +1143,30 @@ func TestS3ConfigValidate(t *testing.T) {
 	}
 }
 
+func TestS3ConfigPresignDuration(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		expiry   int64
+		expected time.Duration
+	}{
+		{-1, 5 * time.Minute},
+		{0, 5 * time.Minute},
+		{1, time.Second},
+		{3600, time.Hour},
+	}
+
+	for _, s := range scenarios {
+		t.Run(fmt.Sprintf("%d", s.expiry), func(t *testing.T) {
+			result := core.S3Config{PresignExpiry: s.expiry}.PresignDuration()
+
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
 func TestBackupsConfigValidate(t *testing.T) {
 	t.Parallel()
 
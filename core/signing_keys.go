@@ -0,0 +1,148 @@
+package core
+
+import (
+	"crypto"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/store"
+)
+
+// signingKeysMu serializes the app signing keys generation
+// to prevent concurrent settings overwrites.
+var signingKeysMu sync.Mutex
+
+// ActiveSigningKey returns the app signing key used for signing
+// new asymmetric tokens (e.g. the OAuth2 server ID tokens).
+//
+// If the app doesn't have any signing key, a new RS256 one is generated and persisted.
+func (app *BaseApp) ActiveSigningKey() (SigningKeyConfig, error) {
+	if key, ok := app.Settings().SigningKeys.ActiveKey(); ok {
+		return key, nil
+	}
+
+	signingKeysMu.Lock()
+	defer signingKeysMu.Unlock()
+
+	settings, err := app.Settings().Clone()
+	if err != nil {
+		return SigningKeyConfig{}, err
+	}
+
+	// check again in case it was created while waiting for the lock
+	if key, ok := settings.SigningKeys.ActiveKey(); ok {
+		return key, nil
+	}
+
+	key, err := NewSigningKeyConfig(security.SigningAlgRS256)
+	if err != nil {
+		return SigningKeyConfig{}, err
+	}
+
+	settings.SigningKeys.Keys = append([]SigningKeyConfig{key}, settings.SigningKeys.Keys...)
+
+	if err := app.Save(settings); err != nil {
+		return SigningKeyConfig{}, err
+	}
+
+	return key, nil
+}
+
+// RotateSigningKeys generates and persists a new active app signing key
+// for the specified algorithm (see [SigningKeysConfig.Rotate]).
+//
+// The previous keys remain valid at least for the longest auth collection
+// token duration, even if the configured rotation overlap is shorter.
+func (app *BaseApp) RotateSigningKeys(algorithm string) (SigningKeyConfig, error) {
+	signingKeysMu.Lock()
+	defer signingKeysMu.Unlock()
+
+	minOverlap, err := app.maxAuthTokenDuration()
+	if err != nil {
+		return SigningKeyConfig{}, err
+	}
+
+	settings, err := app.Settings().Clone()
+	if err != nil {
+		return SigningKeyConfig{}, err
+	}
+
+	key, err := settings.SigningKeys.Rotate(algorithm, minOverlap)
+	if err != nil {
+		return SigningKeyConfig{}, err
+	}
+
+	if err := app.Save(settings); err != nil {
+		return SigningKeyConfig{}, err
+	}
+
+	return key, nil
+}
+
+// maxAuthTokenDuration returns the longest auth token duration of all auth collections.
+func (app *BaseApp) maxAuthTokenDuration() (time.Duration, error) {
+	collections, err := app.FindAllCollections(CollectionTypeAuth)
+	if err != nil {
+		return 0, err
+	}
+
+	var result time.Duration
+	for _, c := range collections {
+		result = max(result, c.AuthToken.DurationTime())
+	}
+
+	return result, nil
+}
+
+const signingPublicKeysStoreKey = "@pbSigningPublicKeys"
+
+// maxCachedSigningPublicKeys limits the number of the cached parsed public keys.
+const maxCachedSigningPublicKeys = 100
+
+type cachedSigningPublicKey struct {
+	privateKey string
+	publicKey  crypto.PublicKey
+}
+
+// signingPublicKey returns the public key of the provided signing key.
+//
+// The parsed public keys are cached by their key id to avoid parsing
+// the PEM encoded private key on every token verification.
+func (app *BaseApp) signingPublicKey(key SigningKeyConfig) (crypto.PublicKey, error) {
+	cache := app.Store().GetOrSet(signingPublicKeysStoreKey, func() any {
+		return store.New[string, *cachedSigningPublicKey](nil)
+	}).(*store.Store[string, *cachedSigningPublicKey])
+
+	// reparse if the key was changed
+	if cached, ok := cache.GetOk(key.Id); ok && cached.privateKey == key.PrivateKey {
+		return cached.publicKey, nil
+	}
+
+	signer, err := key.Signer()
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := signer.Public()
+
+	cache.SetIfLessThanLimit(key.Id, &cachedSigningPublicKey{
+		privateKey: key.PrivateKey,
+		publicKey:  publicKey,
+	}, maxCachedSigningPublicKeys)
+
+	return publicKey, nil
+}
+
+func (app *BaseApp) registerSigningKeysHooks() {
+	// check every hour whether the active signing key is due for rotation
+	app.Cron().Add("__pbSigningKeysRotation__", "15 * * * *", func() {
+		if !app.Settings().SigningKeys.NeedsRotation() {
+			return
+		}
+
+		if _, err := app.RotateSigningKeys(""); err != nil {
+			app.Logger().Warn("Failed to rotate the app signing keys", "error", err)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestActiveSigningKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if len(app.Settings().SigningKeys.Keys) != 0 {
+		t.Fatalf("Expected no signing keys, got %d", len(app.Settings().SigningKeys.Keys))
+	}
+
+	key1, err := app.ActiveSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key1.Algorithm != security.SigningAlgRS256 {
+		t.Fatalf("Expected the generated key algorithm to be %q, got %q", security.SigningAlgRS256, key1.Algorithm)
+	}
+
+	// should return the already persisted key
+	key2, err := app.ActiveSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key1.Id != key2.Id {
+		t.Fatalf("Expected the same active key %q, got %q", key1.Id, key2.Id)
+	}
+
+	// reload the settings from the db
+	if err := app.ReloadSettings(); err != nil {
+		t.Fatal(err)
+	}
+
+	if active, ok := app.Settings().SigningKeys.ActiveKey(); !ok || active.Id != key1.Id {
+		t.Fatalf("Expected persisted active key %q, got %q", key1.Id, active.Id)
+	}
+}
+
+func TestRotateSigningKeys(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	oldKey, err := app.ActiveSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// shorter than the auth collections token duration
+	app.Settings().SigningKeys.RotationOverlap = 60
+
+	superusers, err := app.FindCollectionByNameOrId(core.CollectionNameSuperusers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	superusers.AuthToken.Duration = 30 * 86400
+	if err := app.Save(superusers); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.RotateSigningKeys("HS256"); err == nil {
+		t.Fatal("Expected unsupported algorithm error")
+	}
+
+	newKey, err := app.RotateSigningKeys(security.SigningAlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.ReloadSettings(); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := app.Settings().SigningKeys.Keys
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 signing keys, got %d", len(keys))
+	}
+
+	if keys[0].Id != newKey.Id || keys[0].Algorithm != security.SigningAlgEdDSA || keys[0].PrivateKey == "" {
+		t.Fatalf("Expected the new EdDSA key to be active, got %v", keys[0])
+	}
+
+	if keys[1].Id != oldKey.Id || keys[1].Expires.IsZero() {
+		t.Fatalf("Expected the old key to remain with expiration date, got %v", keys[1])
+	}
+
+	if _, ok := app.Settings().SigningKeys.FindKey(oldKey.Id); !ok {
+		t.Fatal("Expected the old key to be still valid during the rotation overlap")
+	}
+
+	// the overlap should be extended to the longest auth token duration
+	minExpires := types.NowDateTime().Add(superusers.AuthToken.DurationTime() - time.Minute)
+	if keys[1].Expires.Before(minExpires) {
+		t.Fatalf("Expected the old key to expire after %v, got %v", minExpires, keys[1].Expires)
+	}
+}
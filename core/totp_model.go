@@ -0,0 +1,254 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameTOTPs = "_totps"
+
+// TOTPSkew is the number of time steps before and after the
+// current one that are accepted to compensate for clock drifts.
+const TOTPSkew = 1
+
+// totpEncryptedSecretPrefix is the prefix of the encrypted "secret" db values
+// (the base32 alphabet doesn't contain ":" so it can't clash with a plain secret).
+const totpEncryptedSecretPrefix = "enc:"
+
+var (
+	_ Model        = (*TOTP)(nil)
+	_ PreValidator = (*TOTP)(nil)
+	_ RecordProxy  = (*TOTP)(nil)
+)
+
+// TOTP defines a Record proxy for working with the totps collection
+// (aka. the enrolled authenticator apps).
+type TOTP struct {
+	*Record
+}
+
+// NewTOTP instantiates and returns a new blank *TOTP model.
+//
+// Example usage:
+//
+//	totp := core.NewTOTP(app)
+//	totp.SetRecordRef(user.Id)
+//	totp.SetCollectionRef(user.Collection().Id)
+//	totp.SetSecret(security.RandomTOTPSecret())
+//	app.Save(totp)
+func NewTOTP(app App) *TOTP {
+	m := &TOTP{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameTOTPs)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on TOTP.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *TOTP) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameTOTPs {
+		return errors.New("missing or invalid TOTP ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *TOTP) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *TOTP) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *TOTP) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *TOTP) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *TOTP) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *TOTP) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Secret returns the "secret" record field value
+// (the base32 encoded shared TOTP secret).
+//
+// If the app encryption key is set, the secret is stored encrypted in the db
+// and it is decrypted when the model is loaded with [App.FindTOTPByRecord] or [App.FindTOTPById].
+func (m *TOTP) Secret() string {
+	return m.GetString("secret")
+}
+
+// SetSecret updates the "secret" record field value.
+func (m *TOTP) SetSecret(secret string) {
+	m.Set("secret", secret)
+}
+
+// Confirmed returns the "confirmed" record field value, aka. whether
+// the enrollment was completed with a valid authenticator app code.
+func (m *TOTP) Confirmed() bool {
+	return m.GetBool("confirmed")
+}
+
+// SetConfirmed updates the "confirmed" record field value.
+func (m *TOTP) SetConfirmed(confirmed bool) {
+	m.Set("confirmed", confirmed)
+}
+
+// LastStep returns the "lastStep" record field value
+// (the time step of the last successfully used code).
+func (m *TOTP) LastStep() int64 {
+	return int64(m.GetFloat("lastStep"))
+}
+
+// SetLastStep updates the "lastStep" record field value.
+func (m *TOTP) SetLastStep(step int64) {
+	m.Set("lastStep", step)
+}
+
+// RecoveryCodesCount returns the number of the remaining unused recovery codes.
+func (m *TOTP) RecoveryCodesCount() int {
+	return len(m.GetStringSlice("recoveryCodes"))
+}
+
+// SetRecoveryCodes replaces the "recoveryCodes" record field value
+// with the SHA256 hashes of the provided plain recovery codes.
+func (m *TOTP) SetRecoveryCodes(codes []string) {
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = security.SHA256(code)
+	}
+
+	m.Set("recoveryCodes", hashes)
+}
+
+// Created returns the "created" record field value.
+func (m *TOTP) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *TOTP) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+// ValidateCode checks whether code is a valid authenticator app code
+// for the current time that wasn't used before.
+//
+// On success the "lastStep" record field is updated and
+// the model must be persisted to prevent reusing the same code
+// (see also [App.UpdateTOTPLastStep]).
+func (m *TOTP) ValidateCode(code string) bool {
+	step, ok := security.ValidateTOTP(m.Secret(), code, time.Now(), TOTPSkew)
+	if !ok || step <= m.LastStep() {
+		return false
+	}
+
+	m.SetLastStep(step)
+
+	return true
+}
+
+// UseRecoveryCode checks whether code is one of the unused recovery codes.
+//
+// On success the matched code is removed from the "recoveryCodes" record field
+// and the model must be persisted to prevent reusing the same code.
+func (m *TOTP) UseRecoveryCode(code string) bool {
+	if code == "" {
+		return false
+	}
+
+	hash := security.SHA256(code)
+
+	hashes := m.GetStringSlice("recoveryCodes")
+	for i, h := range hashes {
+		if security.Equal(h, hash) {
+			m.Set("recoveryCodes", slices.Delete(hashes, i, i+1))
+			return true
+		}
+	}
+
+	return false
+}
+
+// decryptSecret decrypts the loaded "secret" record field value (if encrypted).
+func (m *TOTP) decryptSecret(app App) error {
+	secret := m.GetString("secret")
+	if !strings.HasPrefix(secret, totpEncryptedSecretPrefix) {
+		return nil // plain secret (e.g. stored before setting the encryption key)
+	}
+
+	encryptionKey := os.Getenv(app.EncryptionEnv())
+	if encryptionKey == "" {
+		return fmt.Errorf("missing encryption key %q to decrypt the TOTP secret", app.EncryptionEnv())
+	}
+
+	decrypted, err := security.Decrypt(strings.TrimPrefix(secret, totpEncryptedSecretPrefix), encryptionKey)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt the TOTP secret: %w", err)
+	}
+
+	m.SetSecret(string(decrypted))
+
+	return nil
+}
+
+func (app *BaseApp) registerTOTPHooks() {
+	recordRefHooks[*TOTP](app, CollectionNameTOTPs, CollectionTypeAuth)
+
+	// encrypt the secret with the app encryption key (if set) right before the db write
+	encryptSecret := &hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			encryptionKey := os.Getenv(e.App.EncryptionEnv())
+			secret := e.Record.GetString("secret")
+			if encryptionKey == "" || secret == "" || strings.HasPrefix(secret, totpEncryptedSecretPrefix) {
+				return e.Next()
+			}
+
+			encrypted, err := security.Encrypt([]byte(secret), encryptionKey)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt the TOTP secret: %w", err)
+			}
+
+			e.Record.Set("secret", totpEncryptedSecretPrefix+encrypted)
+
+			// restore the plain secret so that the model could be still used after save
+			defer e.Record.Set("secret", secret)
+
+			return e.Next()
+		},
+		Priority: 99, // execute as latest as possible, aka. closer to the db action
+	}
+	app.OnRecordCreateExecute(CollectionNameTOTPs).Bind(encryptSecret)
+	app.OnRecordUpdateExecute(CollectionNameTOTPs).Bind(encryptSecret)
+}
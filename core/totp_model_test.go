@@ -0,0 +1,442 @@
+package core_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestNewTOTP(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	totp := core.NewTOTP(app)
+
+	if totp.Collection().Name != core.CollectionNameTOTPs {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameTOTPs, totp.Collection().Name)
+	}
+}
+
+func TestTOTPProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	totp := core.TOTP{}
+	totp.SetProxyRecord(record)
+
+	if totp.ProxyRecord() == nil || totp.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, totp.ProxyRecord())
+	}
+}
+
+func TestTOTPStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	totp := core.NewTOTP(app)
+
+	fields := []struct {
+		name   string
+		getter func() string
+		setter func(string)
+	}{
+		{"collectionRef", totp.CollectionRef, totp.SetCollectionRef},
+		{"recordRef", totp.RecordRef, totp.SetRecordRef},
+		{"secret", totp.Secret, totp.SetSecret},
+	}
+
+	testValues := []string{"test_1", "test2", ""}
+
+	for _, f := range fields {
+		for i, testValue := range testValues {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := totp.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestTOTPConfirmed(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	totp := core.NewTOTP(app)
+
+	testValues := []bool{true, false}
+	for i, testValue := range testValues {
+		t.Run(fmt.Sprintf("%d_%v", i, testValue), func(t *testing.T) {
+			totp.SetConfirmed(testValue)
+
+			if v := totp.Confirmed(); v != testValue {
+				t.Fatalf("Expected getter %v, got %v", testValue, v)
+			}
+
+			if v := totp.GetBool("confirmed"); v != testValue {
+				t.Fatalf("Expected field value %v, got %v", testValue, v)
+			}
+		})
+	}
+}
+
+func TestTOTPLastStep(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	totp := core.NewTOTP(app)
+
+	testValues := []int64{0, 59366670, 123}
+	for i, testValue := range testValues {
+		t.Run(fmt.Sprintf("%d_%d", i, testValue), func(t *testing.T) {
+			totp.SetLastStep(testValue)
+
+			if v := totp.LastStep(); v != testValue {
+				t.Fatalf("Expected getter %d, got %d", testValue, v)
+			}
+		})
+	}
+}
+
+func TestTOTPRecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	totp := core.NewTOTP(app)
+	totp.SetRecoveryCodes([]string{"aaaaa-00000", "bbbbb-11111", "ccccc-22222"})
+
+	if v := totp.RecoveryCodesCount(); v != 3 {
+		t.Fatalf("Expected 3 recovery codes, got %d", v)
+	}
+
+	// ensure that only the hashes are stored
+	stored := totp.GetStringSlice("recoveryCodes")
+	if len(stored) != 3 || stored[0] != security.SHA256("aaaaa-00000") {
+		t.Fatalf("Expected hashed recovery codes, got %v", stored)
+	}
+
+	scenarios := []struct {
+		code          string
+		expected      bool
+		expectedCount int
+	}{
+		{"", false, 3},
+		{"missing", false, 3},
+		{stored[1], false, 3}, // the hash itself
+		{"bbbbb-11111", true, 2},
+		{"bbbbb-11111", false, 2}, // already used
+		{"aaaaa-00000", true, 1},
+		{"ccccc-22222", true, 0},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.code), func(t *testing.T) {
+			result := totp.UseRecoveryCode(s.code)
+
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+
+			if v := totp.RecoveryCodesCount(); v != s.expectedCount {
+				t.Fatalf("Expected %d remaining recovery codes, got %d", s.expectedCount, v)
+			}
+		})
+	}
+}
+
+func TestTOTPValidateCode(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	totp := core.NewTOTP(app)
+	totp.SetSecret(testTOTPSecret)
+
+	now := time.Now()
+	step := security.TOTPStep(now)
+
+	current, err := security.TOTP(testTOTPSecret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous, err := security.TOTP(testTOTPSecret, now.Add(-security.TOTPPeriod*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old, err := security.TOTP(testTOTPSecret, now.Add(-5*security.TOTPPeriod*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if totp.ValidateCode("") {
+		t.Fatal("Expected empty code to fail")
+	}
+
+	if old != current && old != previous && totp.ValidateCode(old) {
+		t.Fatal("Expected outdated code to fail")
+	}
+
+	if !totp.ValidateCode(previous) {
+		t.Fatal("Expected previous step code to succeed")
+	}
+
+	if totp.LastStep() != step-1 {
+		t.Fatalf("Expected lastStep %d, got %d", step-1, totp.LastStep())
+	}
+
+	if totp.ValidateCode(previous) {
+		t.Fatal("Expected reused previous step code to fail")
+	}
+
+	if !totp.ValidateCode(current) {
+		t.Fatal("Expected current step code to succeed")
+	}
+
+	if totp.LastStep() != step {
+		t.Fatalf("Expected lastStep %d, got %d", step, totp.LastStep())
+	}
+
+	if totp.ValidateCode(current) {
+		t.Fatal("Expected reused current step code to fail")
+	}
+}
+
+func TestTOTPSecretEncryption(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	rawSecret := func(id string) string {
+		var secret string
+		err := app.DB().Select("secret").From(core.CollectionNameTOTPs).Where(dbx.HashExp{"id": id}).Row(&secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return secret
+	}
+
+	// stored before setting the encryption key
+	t.Setenv(app.EncryptionEnv(), "")
+	if err := tests.StubTOTPRecords(app); err != nil {
+		t.Fatal(err)
+	}
+	if secret := rawSecret("user1_totp"); secret != testTOTPSecret {
+		t.Fatalf("Expected plain db secret, got %q", secret)
+	}
+
+	t.Setenv(app.EncryptionEnv(), "abcdefghijklmnopqrstuvwxyz123456")
+
+	totp, err := app.FindTOTPById("user1_totp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totp.Secret() != testTOTPSecret {
+		t.Fatalf("Expected the plain secret to be loaded, got %q", totp.Secret())
+	}
+
+	// resave with the encryption key
+	if err := app.Save(totp); err != nil {
+		t.Fatal(err)
+	}
+	if totp.Secret() != testTOTPSecret {
+		t.Fatalf("Expected the plain secret to be restored after save, got %q", totp.Secret())
+	}
+	if secret := rawSecret("user1_totp"); !strings.HasPrefix(secret, "enc:") || strings.Contains(secret, testTOTPSecret) {
+		t.Fatalf("Expected encrypted db secret, got %q", secret)
+	}
+
+	totp, err = app.FindTOTPById("user1_totp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totp.Secret() != testTOTPSecret {
+		t.Fatalf("Expected the decrypted secret, got %q", totp.Secret())
+	}
+
+	// missing encryption key
+	t.Setenv(app.EncryptionEnv(), "")
+	if _, err := app.FindTOTPById("user1_totp"); err == nil {
+		t.Fatal("Expected error when loading an encrypted secret without the encryption key")
+	}
+}
+
+func TestTOTPPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	totpsCol, err := app.FindCollectionByNameOrId(core.CollectionNameTOTPs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fill := func(totp *core.TOTP) {
+		totp.SetRecordRef(user.Id)
+		totp.SetCollectionRef(user.Collection().Id)
+		totp.SetSecret(testTOTPSecret)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		totp := &core.TOTP{}
+
+		if err := app.Validate(totp); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-TOTP collection", func(t *testing.T) {
+		totp := &core.TOTP{}
+		totp.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		fill(totp)
+
+		if err := app.Validate(totp); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("TOTP collection", func(t *testing.T) {
+		totp := &core.TOTP{}
+		totp.SetProxyRecord(core.NewRecord(totpsCol))
+		fill(totp)
+
+		if err := app.Validate(totp); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestTOTPValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name           string
+		totp           func() *core.TOTP
+		expectedErrors []string
+	}{
+		{
+			"empty",
+			func() *core.TOTP {
+				return core.NewTOTP(app)
+			},
+			[]string{"collectionRef", "recordRef", "secret"},
+		},
+		{
+			"non-auth collection",
+			func() *core.TOTP {
+				totp := core.NewTOTP(app)
+				totp.SetCollectionRef(demo1.Collection().Id)
+				totp.SetRecordRef(demo1.Id)
+				totp.SetSecret(testTOTPSecret)
+				return totp
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing record id",
+			func() *core.TOTP {
+				totp := core.NewTOTP(app)
+				totp.SetCollectionRef(user.Collection().Id)
+				totp.SetRecordRef("missing")
+				totp.SetSecret(testTOTPSecret)
+				return totp
+			},
+			[]string{"recordRef"},
+		},
+		{
+			"valid ref",
+			func() *core.TOTP {
+				totp := core.NewTOTP(app)
+				totp.SetCollectionRef(user.Collection().Id)
+				totp.SetRecordRef(user.Id)
+				totp.SetSecret(testTOTPSecret)
+				return totp
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.totp())
+			tests.TestValidationErrors(t, errs, s.expectedErrors)
+		})
+	}
+}
+
+func TestTOTPDeleteOnRecordDelete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := tests.StubTOTPRecords(app); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.FindTOTPByRecord(user); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Expected the user TOTP to be deleted, got %v", err)
+	}
+
+	// other records TOTPs should remain
+	if _, err := app.FindTOTPById("superuser2_totp"); err != nil {
+		t.Fatalf("Expected superuser2_totp to remain, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// ErrTOTPStepUsed is returned by [App.UpdateTOTPLastStep] when
+// the same or a later TOTP time step was already used.
+var ErrTOTPStepUsed = errors.New("the TOTP code was already used")
+
+// FindTOTPByRecord returns the TOTP model linked to the provided auth record.
+func (app *BaseApp) FindTOTPByRecord(authRecord *Record) (*TOTP, error) {
+	result := &TOTP{}
+
+	err := app.RecordQuery(CollectionNameTOTPs).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+		}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := result.decryptSecret(app); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindTOTPById returns a single TOTP model by its id.
+func (app *BaseApp) FindTOTPById(id string) (*TOTP, error) {
+	result := &TOTP{}
+
+	err := app.RecordQuery(CollectionNameTOTPs).
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := result.decryptSecret(app); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateTOTPLastStep persists the "lastStep" field of the provided TOTP model
+// with a conditional update that succeeds only if the stored step is older.
+//
+// Returns [ErrTOTPStepUsed] if no row was updated, aka. the same code
+// was already used (e.g. by a concurrent request).
+//
+// Note that the update is executed directly against the db and doesn't trigger the record hooks.
+func (app *BaseApp) UpdateTOTPLastStep(totp *TOTP) error {
+	now := types.NowDateTime()
+
+	result, err := app.NonconcurrentDB().Update(
+		CollectionNameTOTPs,
+		dbx.Params{
+			"lastStep": totp.LastStep(),
+			"updated":  now.String(),
+		},
+		dbx.And(
+			dbx.HashExp{"id": totp.Id},
+			dbx.NewExp("[[lastStep]] < {:step}", dbx.Params{"step": totp.LastStep()}),
+		),
+	).Execute()
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrTOTPStepUsed
+	}
+
+	totp.Set("updated", now)
+
+	return nil
+}
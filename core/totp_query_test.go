@@ -0,0 +1,159 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestFindTOTPByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := tests.StubTOTPRecords(app); err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	superuser2, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	superuser4, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test4@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		record   *core.Record
+		expected string
+	}{
+		{demo1, ""},
+		{superuser2, "superuser2_totp"},
+		{superuser4, ""},
+		{user1, "user1_totp"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.record.Collection().Name+"_"+s.record.Id, func(t *testing.T) {
+			result, err := app.FindTOTPByRecord(s.record)
+
+			hasErr := err != nil
+			expectErr := s.expected == ""
+			if hasErr != expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", expectErr, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.Id != s.expected {
+				t.Fatalf("Expected TOTP %q, got %q", s.expected, result.Id)
+			}
+		})
+	}
+}
+
+func TestFindTOTPById(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := tests.StubTOTPRecords(app); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		id          string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{"user1_totp", false},
+		{"superuser2_totp", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.id, func(t *testing.T) {
+			result, err := app.FindTOTPById(s.id)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.Id != s.id {
+				t.Fatalf("Expected TOTP %q, got %q", s.id, result.Id)
+			}
+		})
+	}
+}
+
+func TestUpdateTOTPLastStep(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := tests.StubTOTPRecords(app); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate 2 concurrent requests loading the same TOTP
+	totpA, err := app.FindTOTPById("user1_totp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	totpB, err := app.FindTOTPById("user1_totp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	totpA.SetLastStep(100)
+	if err := app.UpdateTOTPLastStep(totpA); err != nil {
+		t.Fatalf("Expected the first update to succeed, got %v", err)
+	}
+
+	totpB.SetLastStep(100)
+	if err := app.UpdateTOTPLastStep(totpB); !errors.Is(err, core.ErrTOTPStepUsed) {
+		t.Fatalf("Expected ErrTOTPStepUsed for the same step, got %v", err)
+	}
+
+	totpB.SetLastStep(99)
+	if err := app.UpdateTOTPLastStep(totpB); !errors.Is(err, core.ErrTOTPStepUsed) {
+		t.Fatalf("Expected ErrTOTPStepUsed for an older step, got %v", err)
+	}
+
+	totpB.SetLastStep(101)
+	if err := app.UpdateTOTPLastStep(totpB); err != nil {
+		t.Fatalf("Expected the newer step update to succeed, got %v", err)
+	}
+
+	totp, err := app.FindTOTPById("user1_totp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totp.LastStep() != 101 {
+		t.Fatalf("Expected lastStep 101, got %d", totp.LastStep())
+	}
+}
@@ -0,0 +1,356 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	validation "github.com/pocketbase/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/filesystem/blob"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameUploads = "_uploads"
+
+// UploadsStoragePath is the app storage directory where the
+// resumable uploads chunks are staged.
+const UploadsStoragePath = "_pb_uploads_"
+
+// UploadMaxAge is the max allowed duration since the last received
+// chunk after which an unattached resumable upload is deleted.
+const UploadMaxAge = 24 * time.Hour
+
+var (
+	_ Model        = (*Upload)(nil)
+	_ PreValidator = (*Upload)(nil)
+	_ RecordProxy  = (*Upload)(nil)
+)
+
+// Upload defines a Record proxy for working with the uploads collection
+// (aka. the staged resumable uploads for a record file field).
+//
+// The upload content is stored as separate chunk files in the app storage
+// under [Upload.ChunksPath] and it is deleted together with the model.
+type Upload struct {
+	*Record
+}
+
+// NewUpload instantiates and returns a new blank *Upload model.
+//
+// Example usage:
+//
+//	upload := core.NewUpload(app)
+//	upload.SetCollectionRef(user.Collection().Id)
+//	upload.SetRecordRef(user.Id)
+//	upload.SetTargetCollectionRef(posts.Id)
+//	upload.SetTargetField("video")
+//	upload.SetFilename("video.mp4")
+//	upload.SetSize(1 << 30)
+//	app.Save(upload)
+func NewUpload(app App) *Upload {
+	m := &Upload{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameUploads)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on Upload.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *Upload) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameUploads {
+		return errors.New("missing or invalid Upload ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *Upload) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *Upload) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value
+// (aka. the collection of the upload owner).
+func (m *Upload) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *Upload) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value
+// (aka. the id of the upload owner).
+func (m *Upload) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *Upload) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// TargetCollectionRef returns the "targetCollectionRef" record field value
+// (aka. the collection of the file field the upload is intended for).
+func (m *Upload) TargetCollectionRef() string {
+	return m.GetString("targetCollectionRef")
+}
+
+// SetTargetCollectionRef updates the "targetCollectionRef" record field value.
+func (m *Upload) SetTargetCollectionRef(collectionId string) {
+	m.Set("targetCollectionRef", collectionId)
+}
+
+// TargetField returns the "targetField" record field value
+// (aka. the name of the file field the upload is intended for).
+func (m *Upload) TargetField() string {
+	return m.GetString("targetField")
+}
+
+// SetTargetField updates the "targetField" record field value.
+func (m *Upload) SetTargetField(name string) {
+	m.Set("targetField", name)
+}
+
+// Filename returns the "filename" record field value
+// (aka. the original name of the uploaded file).
+func (m *Upload) Filename() string {
+	return m.GetString("filename")
+}
+
+// SetFilename updates the "filename" record field value.
+func (m *Upload) SetFilename(filename string) {
+	m.Set("filename", filename)
+}
+
+// Size returns the "size" record field value
+// (aka. the total declared upload size in bytes).
+func (m *Upload) Size() int64 {
+	return int64(m.GetInt("size"))
+}
+
+// SetSize updates the "size" record field value.
+func (m *Upload) SetSize(size int64) {
+	m.Set("size", size)
+}
+
+// Offset returns the "offset" record field value
+// (aka. the number of the already received bytes).
+func (m *Upload) Offset() int64 {
+	return int64(m.GetInt("offset"))
+}
+
+// SetOffset updates the "offset" record field value.
+func (m *Upload) SetOffset(offset int64) {
+	m.Set("offset", offset)
+}
+
+// Created returns the "created" record field value.
+func (m *Upload) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *Upload) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+// IsComplete reports whether all of the declared upload bytes were received.
+func (m *Upload) IsComplete() bool {
+	return m.Size() > 0 && m.Offset() >= m.Size()
+}
+
+// IsOwner reports whether the provided auth record is the upload owner.
+func (m *Upload) IsOwner(authRecord *Record) bool {
+	return authRecord != nil &&
+		m.CollectionRef() == authRecord.Collection().Id &&
+		m.RecordRef() == authRecord.Id
+}
+
+// ChunksPath returns the storage directory of the upload chunk files.
+func (m *Upload) ChunksPath() string {
+	return UploadsStoragePath + "/" + m.Id
+}
+
+// ChunkKey returns the storage key of the upload chunk starting at the specified offset.
+//
+// The offset is zero padded so that the chunk keys could be sorted lexicographically.
+func (m *Upload) ChunkKey(offset int64) string {
+	return fmt.Sprintf("%s/%020d", m.ChunksPath(), offset)
+}
+
+// NewFile returns a new reuploadable [filesystem.File] from the upload chunks
+// that could be assigned to a record file field value.
+//
+// The upload is deleted automatically after the successful save of the
+// record the file is assigned to.
+//
+// Returns an error if the upload is not complete or some of its chunks are missing.
+func (m *Upload) NewFile(app App) (*filesystem.File, error) {
+	if !m.IsComplete() {
+		return nil, errors.New("the upload is not complete")
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return nil, err
+	}
+	defer fsys.Close()
+
+	objects, err := fsys.List(m.ChunksPath() + "/")
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(objects, func(a, b *blob.ListObject) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	keys := make([]string, 0, len(objects))
+
+	var total int64
+	for _, obj := range objects {
+		offset, err := strconv.ParseInt(strings.TrimPrefix(obj.Key, m.ChunksPath()+"/"), 10, 64)
+		if err != nil || offset != total {
+			return nil, fmt.Errorf("invalid or missing upload chunk at offset %d", total)
+		}
+
+		keys = append(keys, obj.Key)
+		total += obj.Size
+	}
+
+	if total != m.Size() {
+		return nil, fmt.Errorf("expected %d upload bytes, got %d", m.Size(), total)
+	}
+
+	return filesystem.NewFileFromReader(&uploadFileReader{app: app, uploadId: m.Id, keys: keys}, total, m.Filename())
+}
+
+// -------------------------------------------------------------------
+
+var _ filesystem.StoredFileReader = (*uploadFileReader)(nil)
+
+// uploadFileReader defines a [filesystem.FileReader] for the staged upload chunks.
+type uploadFileReader struct {
+	app      App
+	uploadId string
+	keys     []string
+}
+
+// Open implements the [filesystem.FileReader] interface.
+func (r *uploadFileReader) Open() (io.ReadSeekCloser, error) {
+	fsys, err := r.app.NewFilesystem()
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := fsys.GetMultiReader(r.keys...)
+	if err != nil {
+		return nil, errors.Join(err, fsys.Close())
+	}
+
+	return &uploadReadSeekCloser{ReadSeekCloser: reader, fsys: fsys}, nil
+}
+
+// StoredKey implements the [filesystem.StoredFileReader] interface.
+//
+// It returns the single chunk key of the upload (e.g. from a presigned upload)
+// or empty string if the upload content is stored in multiple chunks.
+func (r *uploadFileReader) StoredKey() string {
+	if len(r.keys) != 1 {
+		return ""
+	}
+
+	return r.keys[0]
+}
+
+type uploadReadSeekCloser struct {
+	io.ReadSeekCloser
+	fsys *filesystem.System
+}
+
+// Close implements the [io.Closer] interface and releases both
+// the chunks reader and its filesystem.
+func (r *uploadReadSeekCloser) Close() error {
+	return errors.Join(r.ReadSeekCloser.Close(), r.fsys.Close())
+}
+
+// -------------------------------------------------------------------
+
+func (app *BaseApp) registerUploadHooks() {
+	recordRefHooks[*Upload](app, CollectionNameUploads, CollectionTypeAuth)
+
+	app.OnRecordValidate(CollectionNameUploads).Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			targetCollection, err := e.App.FindCachedCollectionByNameOrId(e.Record.GetString("targetCollectionRef"))
+			if err != nil {
+				return validation.Errors{"targetCollectionRef": validation.NewError("validation_missing_collection", "Missing or invalid collection.")}
+			}
+
+			field, _ := targetCollection.Fields.GetByName(e.Record.GetString("targetField")).(*FileField)
+			if field == nil {
+				return validation.Errors{"targetField": validation.NewError("validation_missing_file_field", "Missing or invalid file field.")}
+			}
+
+			size := int64(e.Record.GetInt("size"))
+			if size > field.maxSize() {
+				return validation.Errors{"size": validation.NewError("validation_file_size_limit", "Maximum allowed file size is {{.maxSize}} bytes.").
+					SetParams(map[string]any{"maxSize": field.maxSize()})}
+			}
+
+			if offset := int64(e.Record.GetInt("offset")); offset > size {
+				return validation.Errors{"offset": validation.NewError("validation_offset_exceeds_size", "The upload offset cannot exceed its size.")}
+			}
+
+			return e.Next()
+		},
+		Priority: 99,
+	})
+
+	// delete the staged chunks together with the upload model
+	app.OnRecordAfterDeleteSuccess(CollectionNameUploads).Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			fsys, err := e.App.NewFilesystem()
+			if err != nil {
+				e.App.Logger().Warn("Failed to initialize the upload chunks filesystem", "error", err, "uploadId", e.Record.Id)
+				return e.Next()
+			}
+			defer fsys.Close()
+
+			if errs := fsys.DeletePrefix(UploadsStoragePath + "/" + e.Record.Id + "/"); len(errs) > 0 {
+				e.App.Logger().Warn("Failed to delete the upload chunks", "error", errors.Join(errs...), "uploadId", e.Record.Id)
+			}
+
+			return e.Next()
+		},
+		Priority: 99,
+	})
+
+	// run on every hour to cleanup the stale uploads
+	app.Cron().Add("__pbUploadsCleanup__", "0 * * * *", func() {
+		if err := app.DeleteExpiredUploads(); err != nil {
+			app.Logger().Warn("Failed to delete expired uploads", "error", err)
+		}
+	})
+}
@@ -0,0 +1,636 @@
+package core_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestNewUpload(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	upload := core.NewUpload(app)
+
+	if upload.Collection().Name != core.CollectionNameUploads {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameUploads, upload.Collection().Name)
+	}
+}
+
+func TestUploadProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	upload := core.Upload{}
+	upload.SetProxyRecord(record)
+
+	if upload.ProxyRecord() == nil || upload.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, upload.ProxyRecord())
+	}
+}
+
+func TestUploadStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	upload := core.NewUpload(app)
+
+	fields := []struct {
+		name   string
+		getter func() string
+		setter func(string)
+	}{
+		{"collectionRef", upload.CollectionRef, upload.SetCollectionRef},
+		{"recordRef", upload.RecordRef, upload.SetRecordRef},
+		{"targetCollectionRef", upload.TargetCollectionRef, upload.SetTargetCollectionRef},
+		{"targetField", upload.TargetField, upload.SetTargetField},
+		{"filename", upload.Filename, upload.SetFilename},
+	}
+
+	testValues := []string{"test_1", "test2", ""}
+
+	for _, f := range fields {
+		for i, testValue := range testValues {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := upload.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestUploadIntFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	upload := core.NewUpload(app)
+
+	fields := []struct {
+		name   string
+		getter func() int64
+		setter func(int64)
+	}{
+		{"size", upload.Size, upload.SetSize},
+		{"offset", upload.Offset, upload.SetOffset},
+	}
+
+	testValues := []int64{0, 10, 5 << 30}
+
+	for _, f := range fields {
+		for i, testValue := range testValues {
+			t.Run(fmt.Sprintf("%s_%d_%d", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %d, got %d", testValue, v)
+				}
+
+				if v := int64(upload.GetInt(f.name)); v != testValue {
+					t.Fatalf("Expected field value %d, got %d", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestUploadCreated(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	upload := core.NewUpload(app)
+
+	if v := upload.Created().String(); v != "" {
+		t.Fatalf("Expected empty created, got %q", v)
+	}
+
+	now := types.NowDateTime()
+	upload.SetRaw("created", now)
+
+	if v := upload.Created().String(); v != now.String() {
+		t.Fatalf("Expected %q created, got %q", now.String(), v)
+	}
+}
+
+func TestUploadUpdated(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	upload := core.NewUpload(app)
+
+	if v := upload.Updated().String(); v != "" {
+		t.Fatalf("Expected empty updated, got %q", v)
+	}
+
+	now := types.NowDateTime()
+	upload.SetRaw("updated", now)
+
+	if v := upload.Updated().String(); v != now.String() {
+		t.Fatalf("Expected %q updated, got %q", now.String(), v)
+	}
+}
+
+func TestUploadIsComplete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		size     int64
+		offset   int64
+		expected bool
+	}{
+		{0, 0, false},
+		{10, 0, false},
+		{10, 9, false},
+		{10, 10, true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%d", s.size, s.offset), func(t *testing.T) {
+			upload := core.NewUpload(app)
+			upload.SetSize(s.size)
+			upload.SetOffset(s.offset)
+
+			if v := upload.IsComplete(); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestUploadIsOwner(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	superuser, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upload := core.NewUpload(app)
+	upload.SetCollectionRef(user1.Collection().Id)
+	upload.SetRecordRef(user1.Id)
+
+	scenarios := []struct {
+		name     string
+		auth     *core.Record
+		expected bool
+	}{
+		{"nil auth", nil, false},
+		{"different record", user2, false},
+		{"different collection", superuser, false},
+		{"owner", user1, true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if v := upload.IsOwner(s.auth); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestUploadChunkKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	upload := core.NewUpload(app)
+	upload.Id = "test"
+
+	if v := upload.ChunksPath(); v != core.UploadsStoragePath+"/test" {
+		t.Fatalf("Expected chunks path %q, got %q", core.UploadsStoragePath+"/test", v)
+	}
+
+	if v := upload.ChunkKey(123); v != core.UploadsStoragePath+"/test/00000000000000000123" {
+		t.Fatalf("Expected chunk key %q, got %q", core.UploadsStoragePath+"/test/00000000000000000123", v)
+	}
+}
+
+func TestUploadPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	uploadsCollection, err := app.FindCollectionByNameOrId(core.CollectionNameUploads)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		upload := &core.Upload{}
+
+		if err := app.Validate(upload); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-Upload collection", func(t *testing.T) {
+		upload := &core.Upload{}
+		upload.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		upload.SetCollectionRef(user.Collection().Id)
+		upload.SetRecordRef(user.Id)
+		upload.SetTargetCollectionRef(user.Collection().Id)
+		upload.SetTargetField("file")
+		upload.SetFilename("test.txt")
+		upload.SetSize(10)
+
+		if err := app.Validate(upload); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("Upload collection", func(t *testing.T) {
+		upload := &core.Upload{}
+		upload.SetProxyRecord(core.NewRecord(uploadsCollection))
+		upload.SetCollectionRef(user.Collection().Id)
+		upload.SetRecordRef(user.Id)
+		upload.SetTargetCollectionRef(user.Collection().Id)
+		upload.SetTargetField("file")
+		upload.SetFilename("test.txt")
+		upload.SetSize(10)
+
+		if err := app.Validate(upload); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestUploadValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name           string
+		upload         func() *core.Upload
+		expectedErrors []string
+	}{
+		{
+			"empty upload",
+			func() *core.Upload {
+				return core.NewUpload(app)
+			},
+			[]string{"collectionRef", "recordRef", "targetCollectionRef", "targetField", "filename", "size"},
+		},
+		{
+			"non-auth owner collection",
+			func() *core.Upload {
+				upload := core.NewUpload(app)
+				upload.SetCollectionRef(demo1.Id)
+				upload.SetRecordRef("84nmscqy84lsi1t")
+				upload.SetTargetCollectionRef(demo1.Id)
+				upload.SetTargetField("file_one")
+				upload.SetFilename("test.txt")
+				upload.SetSize(10)
+				return upload
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing target collection",
+			func() *core.Upload {
+				upload := core.NewUpload(app)
+				upload.SetCollectionRef(user.Collection().Id)
+				upload.SetRecordRef(user.Id)
+				upload.SetTargetCollectionRef("missing")
+				upload.SetTargetField("file_one")
+				upload.SetFilename("test.txt")
+				upload.SetSize(10)
+				return upload
+			},
+			[]string{"targetCollectionRef"},
+		},
+		{
+			"non-file target field",
+			func() *core.Upload {
+				upload := core.NewUpload(app)
+				upload.SetCollectionRef(user.Collection().Id)
+				upload.SetRecordRef(user.Id)
+				upload.SetTargetCollectionRef(demo1.Id)
+				upload.SetTargetField("text")
+				upload.SetFilename("test.txt")
+				upload.SetSize(10)
+				return upload
+			},
+			[]string{"targetField"},
+		},
+		{
+			"size exceeding the field max size",
+			func() *core.Upload {
+				upload := core.NewUpload(app)
+				upload.SetCollectionRef(user.Collection().Id)
+				upload.SetRecordRef(user.Id)
+				upload.SetTargetCollectionRef(demo1.Id)
+				upload.SetTargetField("file_one")
+				upload.SetFilename("test.txt")
+				upload.SetSize(5242881)
+				return upload
+			},
+			[]string{"size"},
+		},
+		{
+			"offset exceeding the size",
+			func() *core.Upload {
+				upload := core.NewUpload(app)
+				upload.SetCollectionRef(user.Collection().Id)
+				upload.SetRecordRef(user.Id)
+				upload.SetTargetCollectionRef(demo1.Id)
+				upload.SetTargetField("file_one")
+				upload.SetFilename("test.txt")
+				upload.SetSize(10)
+				upload.SetOffset(11)
+				return upload
+			},
+			[]string{"offset"},
+		},
+		{
+			"valid upload",
+			func() *core.Upload {
+				upload := core.NewUpload(app)
+				upload.SetCollectionRef(user.Collection().Id)
+				upload.SetRecordRef(user.Id)
+				upload.SetTargetCollectionRef(demo1.Id)
+				upload.SetTargetField("file_one")
+				upload.SetFilename("test.txt")
+				upload.SetSize(5242880)
+				upload.SetOffset(10)
+				return upload
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.upload())
+			tests.TestValidationErrors(t, errs, s.expectedErrors)
+		})
+	}
+}
+
+func TestUploadNewFile(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	upload := stubUpload(t, app, "users", "file", "test.txt", "hello world")
+
+	t.Run("incomplete upload", func(t *testing.T) {
+		incomplete := core.NewUpload(app)
+		incomplete.SetSize(10)
+		incomplete.SetOffset(5)
+
+		if _, err := incomplete.NewFile(app); err == nil {
+			t.Fatal("Expected incomplete upload error")
+		}
+	})
+
+	t.Run("complete upload", func(t *testing.T) {
+		file, err := upload.NewFile(app)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if file.OriginalName != "test.txt" {
+			t.Fatalf("Expected original name %q, got %q", "test.txt", file.OriginalName)
+		}
+
+		if !strings.HasPrefix(file.Name, "test_") || !strings.HasSuffix(file.Name, ".txt") {
+			t.Fatalf("Expected normalized test_*.txt name, got %q", file.Name)
+		}
+
+		if file.Size != 11 {
+			t.Fatalf("Expected size %d, got %d", 11, file.Size)
+		}
+
+		r, err := file.Reader.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+
+		content, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if str := string(content); str != "hello world" {
+			t.Fatalf("Expected content %q, got %q", "hello world", str)
+		}
+	})
+
+	t.Run("missing chunk", func(t *testing.T) {
+		fsys, err := app.NewFilesystem()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fsys.Close()
+
+		if err := fsys.Delete(upload.ChunkKey(0)); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := upload.NewFile(app); err == nil {
+			t.Fatal("Expected missing chunk error")
+		}
+	})
+}
+
+func TestUploadDeleteChunksOnDelete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	upload := stubUpload(t, app, "users", "file", "test.txt", "hello world")
+
+	if err := app.Delete(upload); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	files, err := fsys.List(upload.ChunksPath() + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 0 {
+		t.Fatalf("Expected the upload chunks to be deleted, got %d files", len(files))
+	}
+}
+
+func TestUploadDeleteOnRecordDelete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	upload := stubUpload(t, app, "users", "file", "test.txt", "hello world")
+
+	user, err := app.FindRecordById(upload.CollectionRef(), upload.RecordRef())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.FindUploadById(upload.Id); err == nil {
+		t.Fatal("Expected the user upload to be deleted")
+	}
+}
+
+func TestUploadAttachToRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	upload := stubUpload(t, app, "users", "file", "test.txt", "hello world")
+
+	user, err := app.FindRecordById(upload.CollectionRef(), upload.RecordRef())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := upload.NewFile(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user.Set("file+", file)
+	if err := app.Save(user); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	r, err := fsys.GetReader(user.BaseFilesPath() + "/" + file.Name)
+	if err != nil {
+		t.Fatalf("Expected the upload file to be stored as record file: %v", err)
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if str := string(content); str != "hello world" {
+		t.Fatalf("Expected content %q, got %q", "hello world", str)
+	}
+
+	if _, err := app.FindUploadById(upload.Id); err == nil {
+		t.Fatal("Expected the attached upload to be deleted")
+	}
+}
+
+// stubUpload creates a new complete upload owned by test@example.com
+// with the content stored in 2 chunks.
+func stubUpload(t testing.TB, app core.App, collection string, field string, filename string, content string) *core.Upload {
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	targetCollection, err := app.FindCollectionByNameOrId(collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upload := core.NewUpload(app)
+	upload.SetCollectionRef(user.Collection().Id)
+	upload.SetRecordRef(user.Id)
+	upload.SetTargetCollectionRef(targetCollection.Id)
+	upload.SetTargetField(field)
+	upload.SetFilename(filename)
+	upload.SetSize(int64(len(content)))
+	upload.SetOffset(int64(len(content)))
+	if err := app.Save(upload); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	half := len(content) / 2
+	chunks := map[int64]string{0: content[:half], int64(half): content[half:]}
+	for offset, chunk := range chunks {
+		if err := fsys.Upload([]byte(chunk), upload.ChunkKey(offset)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return upload
+}
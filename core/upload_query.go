@@ -0,0 +1,51 @@
+package core
+
+import (
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// FindUploadById returns a single Upload model by its id.
+func (app *BaseApp) FindUploadById(id string) (*Upload, error) {
+	result := &Upload{}
+
+	err := app.RecordQuery(CollectionNameUploads).
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteExpiredUploads deletes the stale Upload models (and their staged chunks),
+// aka. the ones without any received chunk within the last [UploadMaxAge].
+func (app *BaseApp) DeleteExpiredUploads() error {
+	minValidDate, err := types.ParseDateTime(time.Now().Add(-1 * UploadMaxAge))
+	if err != nil {
+		return err
+	}
+
+	items := []*Record{}
+
+	err = app.RecordQuery(CollectionNameUploads).
+		AndWhere(dbx.NewExp("[[updated]] < {:date}", dbx.Params{"date": minValidDate})).
+		All(&items)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		err = app.Delete(item)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
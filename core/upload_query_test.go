@@ -0,0 +1,91 @@
+package core_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestFindUploadById(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	upload := stubUpload(t, app, "users", "file", "test.txt", "hello world")
+
+	scenarios := []struct {
+		id          string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{upload.Id, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.id, func(t *testing.T) {
+			result, err := app.FindUploadById(s.id)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.Id != s.id {
+				t.Fatalf("Expected upload with id %q, got %q", s.id, result.Id)
+			}
+		})
+	}
+}
+
+func TestDeleteExpiredUploads(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	upload1 := stubUpload(t, app, "users", "file", "test1.txt", "test1")
+	upload2 := stubUpload(t, app, "users", "file", "test2.txt", "test2")
+	upload3 := stubUpload(t, app, "users", "file", "test3.txt", "test3")
+
+	stubs := []struct {
+		upload  *core.Upload
+		updated types.DateTime
+	}{
+		{upload1, types.NowDateTime()},
+		{upload2, types.NowDateTime().Add(-1*core.UploadMaxAge + time.Hour)},
+		{upload3, types.NowDateTime().Add(-1*core.UploadMaxAge - time.Hour)},
+	}
+
+	for _, s := range stubs {
+		// manually update the autodate field
+		_, err := app.DB().Update(core.CollectionNameUploads, dbx.Params{"updated": s.updated}, dbx.HashExp{"id": s.upload.Id}).Execute()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deletedIds := []string{}
+	app.OnRecordAfterDeleteSuccess(core.CollectionNameUploads).BindFunc(func(e *core.RecordEvent) error {
+		deletedIds = append(deletedIds, e.Record.Id)
+		return e.Next()
+	})
+
+	if err := app.DeleteExpiredUploads(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deletedIds) != 1 || !slices.Contains(deletedIds, upload3.Id) {
+		t.Fatalf("Expected only %q to be deleted, got %v", upload3.Id, deletedIds)
+	}
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameWebAuthnCredentials = "_webauthnCredentials"
+
+var (
+	_ Model        = (*WebAuthnCredential)(nil)
+	_ PreValidator = (*WebAuthnCredential)(nil)
+	_ RecordProxy  = (*WebAuthnCredential)(nil)
+)
+
+// WebAuthnCredential defines a Record proxy for working with the
+// webauthnCredentials collection (aka. the registered passkeys).
+type WebAuthnCredential struct {
+	*Record
+}
+
+// NewWebAuthnCredential instantiates and returns a new blank *WebAuthnCredential model.
+//
+// Example usage:
+//
+//	credential := core.NewWebAuthnCredential(app)
+//	credential.SetRecordRef(user.Id)
+//	credential.SetCollectionRef(user.Collection().Id)
+//	credential.SetCredentialId("...")
+//	credential.SetPublicKey("...")
+//	credential.SetAlgorithm(-7)
+//	app.Save(credential)
+func NewWebAuthnCredential(app App) *WebAuthnCredential {
+	m := &WebAuthnCredential{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameWebAuthnCredentials)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on WebAuthnCredential.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *WebAuthnCredential) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameWebAuthnCredentials {
+		return errors.New("missing or invalid WebAuthnCredential ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *WebAuthnCredential) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *WebAuthnCredential) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *WebAuthnCredential) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *WebAuthnCredential) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *WebAuthnCredential) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *WebAuthnCredential) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Name returns the "name" record field value.
+func (m *WebAuthnCredential) Name() string {
+	return m.GetString("name")
+}
+
+// SetName updates the "name" record field value.
+func (m *WebAuthnCredential) SetName(name string) {
+	m.Set("name", name)
+}
+
+// CredentialId returns the "credentialId" record field value
+// (the base64url encoded authenticator credential id).
+func (m *WebAuthnCredential) CredentialId() string {
+	return m.GetString("credentialId")
+}
+
+// SetCredentialId updates the "credentialId" record field value.
+func (m *WebAuthnCredential) SetCredentialId(credentialId string) {
+	m.Set("credentialId", credentialId)
+}
+
+// PublicKey returns the "publicKey" record field value
+// (the base64url encoded COSE public key).
+func (m *WebAuthnCredential) PublicKey() string {
+	return m.GetString("publicKey")
+}
+
+// SetPublicKey updates the "publicKey" record field value.
+func (m *WebAuthnCredential) SetPublicKey(publicKey string) {
+	m.Set("publicKey", publicKey)
+}
+
+// Algorithm returns the "algorithm" record field value
+// (the COSE algorithm identifier of the public key).
+func (m *WebAuthnCredential) Algorithm() int {
+	return m.GetInt("algorithm")
+}
+
+// SetAlgorithm updates the "algorithm" record field value.
+func (m *WebAuthnCredential) SetAlgorithm(algorithm int) {
+	m.Set("algorithm", algorithm)
+}
+
+// SignCount returns the "signCount" record field value
+// (the last known authenticator signature counter).
+func (m *WebAuthnCredential) SignCount() int {
+	return m.GetInt("signCount")
+}
+
+// SetSignCount updates the "signCount" record field value.
+func (m *WebAuthnCredential) SetSignCount(signCount int) {
+	m.Set("signCount", signCount)
+}
+
+// Transports returns the "transports" record field value
+// (the authenticator transport hints, e.g. "internal", "hybrid", "usb").
+func (m *WebAuthnCredential) Transports() []string {
+	return m.GetStringSlice("transports")
+}
+
+// SetTransports updates the "transports" record field value.
+func (m *WebAuthnCredential) SetTransports(transports []string) {
+	m.Set("transports", transports)
+}
+
+// AAGUID returns the "aaguid" record field value
+// (the hex encoded authenticator model identifier).
+func (m *WebAuthnCredential) AAGUID() string {
+	return m.GetString("aaguid")
+}
+
+// SetAAGUID updates the "aaguid" record field value.
+func (m *WebAuthnCredential) SetAAGUID(aaguid string) {
+	m.Set("aaguid", aaguid)
+}
+
+// BackupEligible returns the "backupEligible" record field value,
+// aka. whether the credential could be synced between devices.
+func (m *WebAuthnCredential) BackupEligible() bool {
+	return m.GetBool("backupEligible")
+}
+
+// SetBackupEligible updates the "backupEligible" record field value.
+func (m *WebAuthnCredential) SetBackupEligible(backupEligible bool) {
+	m.Set("backupEligible", backupEligible)
+}
+
+// Created returns the "created" record field value.
+func (m *WebAuthnCredential) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *WebAuthnCredential) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+func (app *BaseApp) registerWebAuthnCredentialHooks() {
+	recordRefHooks[*WebAuthnCredential](app, CollectionNameWebAuthnCredentials, CollectionTypeAuth)
+}
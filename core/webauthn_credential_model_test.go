@@ -0,0 +1,321 @@
+package core_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewWebAuthnCredential(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	credential := core.NewWebAuthnCredential(app)
+
+	if credential.Collection().Name != core.CollectionNameWebAuthnCredentials {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameWebAuthnCredentials, credential.Collection().Name)
+	}
+}
+
+func TestWebAuthnCredentialProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	credential := core.WebAuthnCredential{}
+	credential.SetProxyRecord(record)
+
+	if credential.ProxyRecord() == nil || credential.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, credential.ProxyRecord())
+	}
+}
+
+func TestWebAuthnCredentialStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	credential := core.NewWebAuthnCredential(app)
+
+	fields := []struct {
+		name   string
+		getter func() string
+		setter func(string)
+	}{
+		{"collectionRef", credential.CollectionRef, credential.SetCollectionRef},
+		{"recordRef", credential.RecordRef, credential.SetRecordRef},
+		{"name", credential.Name, credential.SetName},
+		{"credentialId", credential.CredentialId, credential.SetCredentialId},
+		{"publicKey", credential.PublicKey, credential.SetPublicKey},
+		{"aaguid", credential.AAGUID, credential.SetAAGUID},
+	}
+
+	testValues := []string{"test_1", "test2", ""}
+
+	for _, f := range fields {
+		for i, testValue := range testValues {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := credential.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestWebAuthnCredentialNumberFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	credential := core.NewWebAuthnCredential(app)
+
+	fields := []struct {
+		name   string
+		getter func() int
+		setter func(int)
+	}{
+		{"algorithm", credential.Algorithm, credential.SetAlgorithm},
+		{"signCount", credential.SignCount, credential.SetSignCount},
+	}
+
+	testValues := []int{-7, 0, 123}
+
+	for _, f := range fields {
+		for i, testValue := range testValues {
+			t.Run(fmt.Sprintf("%s_%d_%d", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %d, got %d", testValue, v)
+				}
+
+				if v := credential.GetInt(f.name); v != testValue {
+					t.Fatalf("Expected field value %d, got %d", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestWebAuthnCredentialTransports(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	credential := core.NewWebAuthnCredential(app)
+
+	testValues := [][]string{{"internal", "hybrid"}, {"usb"}, {}}
+	for i, testValue := range testValues {
+		t.Run(fmt.Sprintf("%d_%v", i, testValue), func(t *testing.T) {
+			credential.SetTransports(testValue)
+
+			if v := credential.Transports(); !slices.Equal(v, testValue) {
+				t.Fatalf("Expected getter %v, got %v", testValue, v)
+			}
+		})
+	}
+}
+
+func TestWebAuthnCredentialBackupEligible(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	credential := core.NewWebAuthnCredential(app)
+
+	testValues := []bool{true, false}
+	for i, testValue := range testValues {
+		t.Run(fmt.Sprintf("%d_%v", i, testValue), func(t *testing.T) {
+			credential.SetBackupEligible(testValue)
+
+			if v := credential.BackupEligible(); v != testValue {
+				t.Fatalf("Expected getter %v, got %v", testValue, v)
+			}
+
+			if v := credential.GetBool("backupEligible"); v != testValue {
+				t.Fatalf("Expected field value %v, got %v", testValue, v)
+			}
+		})
+	}
+}
+
+func TestWebAuthnCredentialPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	credentialsCol, err := app.FindCollectionByNameOrId(core.CollectionNameWebAuthnCredentials)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fill := func(credential *core.WebAuthnCredential) {
+		credential.SetRecordRef(user.Id)
+		credential.SetCollectionRef(user.Collection().Id)
+		credential.SetCredentialId("test")
+		credential.SetPublicKey("test")
+		credential.SetAlgorithm(-7)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		credential := &core.WebAuthnCredential{}
+
+		if err := app.Validate(credential); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-WebAuthnCredential collection", func(t *testing.T) {
+		credential := &core.WebAuthnCredential{}
+		credential.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		fill(credential)
+
+		if err := app.Validate(credential); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("WebAuthnCredential collection", func(t *testing.T) {
+		credential := &core.WebAuthnCredential{}
+		credential.SetProxyRecord(core.NewRecord(credentialsCol))
+		fill(credential)
+
+		if err := app.Validate(credential); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestWebAuthnCredentialValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name           string
+		credential     func() *core.WebAuthnCredential
+		expectedErrors []string
+	}{
+		{
+			"empty",
+			func() *core.WebAuthnCredential {
+				return core.NewWebAuthnCredential(app)
+			},
+			[]string{"collectionRef", "recordRef", "credentialId", "publicKey", "algorithm"},
+		},
+		{
+			"non-auth collection",
+			func() *core.WebAuthnCredential {
+				credential := core.NewWebAuthnCredential(app)
+				credential.SetCollectionRef(demo1.Collection().Id)
+				credential.SetRecordRef(demo1.Id)
+				credential.SetCredentialId("test")
+				credential.SetPublicKey("test")
+				credential.SetAlgorithm(-7)
+				return credential
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing record id",
+			func() *core.WebAuthnCredential {
+				credential := core.NewWebAuthnCredential(app)
+				credential.SetCollectionRef(user.Collection().Id)
+				credential.SetRecordRef("missing")
+				credential.SetCredentialId("test")
+				credential.SetPublicKey("test")
+				credential.SetAlgorithm(-7)
+				return credential
+			},
+			[]string{"recordRef"},
+		},
+		{
+			"valid ref",
+			func() *core.WebAuthnCredential {
+				credential := core.NewWebAuthnCredential(app)
+				credential.SetCollectionRef(user.Collection().Id)
+				credential.SetRecordRef(user.Id)
+				credential.SetCredentialId("test")
+				credential.SetPublicKey("test")
+				credential.SetAlgorithm(-7)
+				return credential
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.credential())
+			tests.TestValidationErrors(t, errs, s.expectedErrors)
+		})
+	}
+}
+
+func TestWebAuthnCredentialDeleteOnRecordDelete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := tests.StubWebAuthnCredentialRecords(app); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(user); err != nil {
+		t.Fatal(err)
+	}
+
+	credentials, err := app.FindAllWebAuthnCredentialsByRecord(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(credentials) != 0 {
+		t.Fatalf("Expected the user credentials to be deleted, found %d", len(credentials))
+	}
+
+	// other records credentials should remain
+	if _, err := app.FindWebAuthnCredentialById("superuser2_0"); err != nil {
+		t.Fatalf("Expected superuser2_0 credential to remain, got %v", err)
+	}
+}
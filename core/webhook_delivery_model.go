@@ -0,0 +1,390 @@
+package core
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var (
+	_ Model = (*WebhookDelivery)(nil)
+)
+
+const WebhookDeliveriesTableName = "_webhookDeliveries"
+
+const (
+	WebhookDeliveryStatusPending = "pending"
+	WebhookDeliveryStatusSuccess = "success"
+	WebhookDeliveryStatusFailed  = "failed"
+)
+
+// The webhook request headers.
+const (
+	WebhookHeaderId         = "X-PB-Webhook-Id"
+	WebhookHeaderDeliveryId = "X-PB-Delivery-Id"
+
+	// WebhookHeaderSignature holds the webhook request signature in the format:
+	//
+	//	t=<unix timestamp>,v1=<hex encoded HMAC-SHA256(secret, timestamp + "." + body)>
+	//
+	// The header is sent only if the webhook has a secret.
+	WebhookHeaderSignature = "X-PB-Signature"
+)
+
+// webhookHTTPClient is the http client used for sending the webhook requests.
+var webhookHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// WebhookDelivery defines a single webhook request entry
+// together with its delivery state.
+type WebhookDelivery struct {
+	BaseModel
+
+	WebhookRef     string         `db:"webhookRef" json:"webhookRef"`
+	URL            string         `db:"url" json:"url"`
+	CollectionRef  string         `db:"collectionRef" json:"collectionRef"`
+	RecordRef      string         `db:"recordRef" json:"recordRef"`
+	Action         string         `db:"action" json:"action"`
+	Payload        types.JSONRaw  `db:"payload" json:"payload"`
+	Status         string         `db:"status" json:"status"`
+	Error          string         `db:"error" json:"error"`
+	Attempts       int            `db:"attempts" json:"attempts"`
+	ResponseStatus int            `db:"responseStatus" json:"responseStatus"`
+	NextAttempt    types.DateTime `db:"nextAttempt" json:"nextAttempt"`
+	Created        types.DateTime `db:"created" json:"created"`
+	Updated        types.DateTime `db:"updated" json:"updated"`
+}
+
+func (m *WebhookDelivery) TableName() string {
+	return WebhookDeliveriesTableName
+}
+
+// SignWebhookPayload returns the [WebhookHeaderSignature] value
+// of the provided payload for the specified unix timestamp.
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	t := strconv.FormatInt(timestamp, 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// -------------------------------------------------------------------
+
+func (app *BaseApp) registerWebhookHooks() {
+	app.OnRecordAfterCreateSuccess().Bind(&hook.Handler[*RecordEvent]{
+		Id: "__pbWebhooksOnCreate__",
+		Func: func(e *RecordEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			queueRecordWebhooks(e.App, matchingRecordWebhooks(e.App, e.Record, WebhookActionCreate), WebhookActionCreate, e.Record)
+
+			return nil
+		},
+	})
+
+	app.OnRecordAfterUpdateSuccess().Bind(&hook.Handler[*RecordEvent]{
+		Id: "__pbWebhooksOnUpdate__",
+		Func: func(e *RecordEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			queueRecordWebhooks(e.App, matchingRecordWebhooks(e.App, e.Record, WebhookActionUpdate), WebhookActionUpdate, e.Record)
+
+			return nil
+		},
+	})
+
+	// the deleted record can no longer be checked against the webhooks filter
+	// after the delete so we resolve the matching webhooks in advance
+	app.OnRecordDelete().Bind(&hook.Handler[*RecordEvent]{
+		Id: "__pbWebhooksOnDelete__",
+		Func: func(e *RecordEvent) error {
+			hooks := matchingRecordWebhooks(e.App, e.Record, WebhookActionDelete)
+			if len(hooks) == 0 {
+				return e.Next()
+			}
+
+			key := webhooksDeleteStoreKey(e.Record)
+
+			e.App.Store().Set(key, hooks)
+
+			if err := e.Next(); err != nil {
+				e.App.Store().Remove(key)
+				return err
+			}
+
+			return nil
+		},
+		Priority: -99,
+	})
+
+	app.OnRecordAfterDeleteSuccess().Bind(&hook.Handler[*RecordEvent]{
+		Id: "__pbWebhooksOnAfterDelete__",
+		Func: func(e *RecordEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			key := webhooksDeleteStoreKey(e.Record)
+
+			hooks, _ := e.App.Store().Get(key).([]WebhookConfig)
+			if len(hooks) > 0 {
+				e.App.Store().Remove(key)
+				queueRecordWebhooks(e.App, hooks, WebhookActionDelete, e.Record)
+			}
+
+			return nil
+		},
+	})
+
+	app.OnRecordAfterDeleteError().Bind(&hook.Handler[*RecordErrorEvent]{
+		Id: "__pbWebhooksOnAfterDeleteError__",
+		Func: func(e *RecordErrorEvent) error {
+			e.App.Store().Remove(webhooksDeleteStoreKey(e.Record))
+			return e.Next()
+		},
+	})
+
+	// run every minute to resend the failed deliveries
+	// (skipping the run if the previous one is still in progress)
+	var retrying atomic.Bool
+	app.Cron().Add("__pbWebhooksRetry__", "* * * * *", func() {
+		if !retrying.CompareAndSwap(false, true) {
+			return
+		}
+		defer retrying.Store(false)
+
+		if err := app.RetryWebhookDeliveries(); err != nil {
+			app.Logger().Warn("Failed to retry webhook deliveries", "error", err)
+		}
+	})
+
+	// run every 6 hours to cleanup the old deliveries
+	app.Cron().Add("__pbWebhooksCleanup__", "0 */6 * * *", func() {
+		maxDays := app.Settings().Webhooks.MaxDays
+		if maxDays <= 0 {
+			return
+		}
+
+		if err := app.DeleteOldWebhookDeliveries(time.Now().AddDate(0, 0, -1*maxDays)); err != nil {
+			app.Logger().Warn("Failed to delete old webhook deliveries", "error", err)
+		}
+	})
+}
+
+func webhooksDeleteStoreKey(record *Record) string {
+	return "@pbWebhooksDelete/" + record.Collection().Id + "/" + record.Id
+}
+
+// matchingRecordWebhooks returns the enabled webhooks configured
+// for the record collection action and whose filter (if any) is satisfied.
+func matchingRecordWebhooks(app App, record *Record, action string) []WebhookConfig {
+	var result []WebhookConfig
+
+	for _, h := range app.Settings().Webhooks.Hooks {
+		if !h.matches(record.Collection(), action) {
+			continue
+		}
+
+		if h.Filter != "" {
+			ok, err := app.CanAccessRecord(record, &RequestInfo{Context: RequestInfoContextDefault}, &h.Filter)
+			if err != nil {
+				app.Logger().Warn(
+					"Failed to check webhook filter",
+					"webhookId", h.Id,
+					"collectionName", record.Collection().Name,
+					"recordId", record.Id,
+					"error", err,
+				)
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		result = append(result, h)
+	}
+
+	return result
+}
+
+// queueRecordWebhooks creates a pending delivery for each of the provided
+// webhooks and tries to send them in the background.
+func queueRecordWebhooks(app App, hooks []WebhookConfig, action string, record *Record) {
+	if len(hooks) == 0 {
+		return
+	}
+
+	exportRecord := record.Fresh()
+	exportRecord.IgnoreEmailVisibility(true)
+
+	deliveries := make([]*WebhookDelivery, 0, len(hooks))
+
+	for _, h := range hooks {
+		delivery, err := newRecordWebhookDelivery(h, action, exportRecord)
+		if err == nil {
+			err = app.AuxSave(delivery)
+		}
+		if err != nil {
+			app.Logger().Warn(
+				"Failed to create webhook delivery",
+				"webhookId", h.Id,
+				"collectionName", record.Collection().Name,
+				"recordId", record.Id,
+				"error", err,
+			)
+			continue
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	routine.FireAndForget(func() {
+		for _, delivery := range deliveries {
+			// the request error is already stored in the delivery entry
+			if _, saveErr := sendWebhookDelivery(app, delivery); saveErr != nil {
+				app.Logger().Warn("Failed to send webhook delivery", "deliveryId", delivery.Id, "error", saveErr)
+			}
+		}
+	})
+}
+
+func newRecordWebhookDelivery(h WebhookConfig, action string, record *Record) (*WebhookDelivery, error) {
+	now := types.NowDateTime()
+
+	delivery := &WebhookDelivery{
+		WebhookRef:    h.Id,
+		URL:           h.URL,
+		CollectionRef: record.Collection().Id,
+		RecordRef:     record.Id,
+		Action:        action,
+		Status:        WebhookDeliveryStatusPending,
+		Created:       now,
+		Updated:       now,
+		// give enough time for the initial attempt to complete
+		// before considering it for a retry
+		NextAttempt: now.Add(time.Minute),
+	}
+	delivery.Id = GenerateDefaultRandomId()
+
+	payload, err := json.Marshal(map[string]any{
+		"id":             delivery.Id,
+		"webhookId":      h.Id,
+		"action":         action,
+		"collectionId":   record.Collection().Id,
+		"collectionName": record.Collection().Name,
+		"record":         record,
+		"created":        now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = payload
+
+	return delivery, nil
+}
+
+// SendWebhookDelivery makes a single attempt to send the webhook delivery
+// request and persists its updated state.
+//
+// A failed delivery is scheduled for a retry with exponential backoff
+// until the Webhooks.MaxRetries setting is reached.
+func (app *BaseApp) SendWebhookDelivery(delivery *WebhookDelivery) error {
+	sendErr, saveErr := sendWebhookDelivery(app, delivery)
+	if saveErr != nil {
+		return errors.Join(sendErr, saveErr)
+	}
+
+	return sendErr
+}
+
+// sendWebhookDelivery sends the delivery request and persists the
+// delivery state, returning separately the request and save errors.
+func sendWebhookDelivery(app App, delivery *WebhookDelivery) (sendErr error, saveErr error) {
+	sendErr = sendWebhookRequest(app, delivery)
+
+	delivery.Attempts++
+	delivery.Updated = types.NowDateTime()
+
+	if sendErr == nil {
+		delivery.Status = WebhookDeliveryStatusSuccess
+		delivery.Error = ""
+		delivery.NextAttempt = types.DateTime{}
+	} else {
+		delivery.Error = sendErr.Error()
+
+		if delivery.Attempts > app.Settings().Webhooks.MaxRetries {
+			delivery.Status = WebhookDeliveryStatusFailed
+			delivery.NextAttempt = types.DateTime{}
+		} else {
+			delivery.Status = WebhookDeliveryStatusPending
+			backoff := time.Duration(1<<min(delivery.Attempts-1, 10)) * time.Minute
+			delivery.NextAttempt = delivery.Updated.Add(backoff)
+		}
+	}
+
+	if err := app.AuxSave(delivery); err != nil {
+		saveErr = fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+
+	return sendErr, saveErr
+}
+
+func sendWebhookRequest(app App, delivery *WebhookDelivery) error {
+	delivery.ResponseStatus = 0
+
+	h, ok := app.Settings().Webhooks.FindHook(delivery.WebhookRef)
+	if !ok {
+		return fmt.Errorf("missing webhook %q", delivery.WebhookRef)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PocketBase-Webhooks")
+	req.Header.Set(WebhookHeaderId, h.Id)
+	req.Header.Set(WebhookHeaderDeliveryId, delivery.Id)
+	if h.Secret != "" {
+		req.Header.Set(WebhookHeaderSignature, SignWebhookPayload(h.Secret, time.Now().Unix(), delivery.Payload))
+	}
+
+	res, err := webhookHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// drain a limited part of the body to allow reusing the connection
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	delivery.ResponseStatus = res.StatusCode
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status code %d", res.StatusCode)
+	}
+
+	return nil
+}
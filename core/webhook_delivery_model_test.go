@@ -0,0 +1,423 @@
+package core_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+type webhookTestServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	status   int
+}
+
+func newWebhookTestServer(status int) *webhookTestServer {
+	s := &webhookTestServer{status: status}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, string(body))
+		status := s.status
+		s.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+
+	return s
+}
+
+func (s *webhookTestServer) setStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = status
+}
+
+func waitWebhookDeliveries(t *testing.T, app core.App, expectedStatus string, expectedTotal int) []*core.WebhookDelivery {
+	t.Helper()
+
+	var deliveries []*core.WebhookDelivery
+
+	for i := 0; i < 100; i++ {
+		deliveries = []*core.WebhookDelivery{}
+
+		err := app.WebhookDeliveryQuery().
+			AndWhere(dbx.HashExp{"status": expectedStatus}).
+			OrderBy("created ASC", "rowid ASC").
+			All(&deliveries)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(deliveries) == expectedTotal {
+			return deliveries
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Fatalf("Expected %d %q deliveries, got %d", expectedTotal, expectedStatus, len(deliveries))
+
+	return nil
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	t.Parallel()
+
+	result := core.SignWebhookPayload("test", 123, []byte(`{"a":1}`))
+
+	mac := hmac.New(sha256.New, []byte("test"))
+	mac.Write([]byte(`123.{"a":1}`))
+	expected := "t=123,v1=" + hex.EncodeToString(mac.Sum(nil))
+
+	if result != expected {
+		t.Fatalf("Expected signature %q, got %q", expected, result)
+	}
+
+	if result == core.SignWebhookPayload("test2", 123, []byte(`{"a":1}`)) {
+		t.Fatal("Expected different signature for different secret")
+	}
+
+	if result == core.SignWebhookPayload("test", 124, []byte(`{"a":1}`)) {
+		t.Fatal("Expected different signature for different timestamp")
+	}
+}
+
+func TestWebhookRecordHooks(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	server := newWebhookTestServer(200)
+	defer server.Close()
+
+	app.Settings().Webhooks.Hooks = []core.WebhookConfig{
+		{
+			Id:          "all",
+			URL:         server.URL,
+			Collections: []string{"demo2"},
+			Secret:      "test_secret",
+			Enabled:     true,
+		},
+		{
+			Id:          "filtered",
+			URL:         server.URL,
+			Collections: []string{"*"},
+			Actions:     []string{core.WebhookActionUpdate, core.WebhookActionDelete},
+			Filter:      "title = 'webhook'",
+			Enabled:     true,
+		},
+		{
+			Id:          "disabled",
+			URL:         server.URL,
+			Collections: []string{"*"},
+		},
+	}
+
+	collection, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("title", "webhook")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	waitWebhookDeliveries(t, app, core.WebhookDeliveryStatusSuccess, 1)
+
+	record.Set("title", "other")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	waitWebhookDeliveries(t, app, core.WebhookDeliveryStatusSuccess, 2)
+
+	record.Set("title", "webhook")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	waitWebhookDeliveries(t, app, core.WebhookDeliveryStatusSuccess, 4)
+
+	if err := app.Delete(record); err != nil {
+		t.Fatal(err)
+	}
+	deliveries := waitWebhookDeliveries(t, app, core.WebhookDeliveryStatusSuccess, 6)
+
+	expected := []string{
+		"all_create",
+		"all_update",
+		"all_update",
+		"filtered_update",
+		"all_delete",
+		"filtered_delete",
+	}
+
+	actual := make([]string, len(deliveries))
+	for i, d := range deliveries {
+		actual[i] = d.WebhookRef + "_" + d.Action
+
+		if d.RecordRef != record.Id || d.CollectionRef != collection.Id || d.Attempts != 1 || d.ResponseStatus != 200 {
+			t.Fatalf("[%d] Unexpected delivery state %+v", i, d)
+		}
+
+		payload := map[string]any{}
+		if err := json.Unmarshal(d.Payload, &payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload["id"] != d.Id || payload["action"] != d.Action || payload["collectionName"] != "demo2" {
+			t.Fatalf("[%d] Unexpected delivery payload %s", i, d.Payload)
+		}
+	}
+
+	// the deliveries of the same event are created in the hooks order
+	// but could complete in any order
+	if strings.Join(actual[:3], ",") != strings.Join(expected[:3], ",") {
+		t.Fatalf("Expected deliveries\n%v\ngot\n%v", expected, actual)
+	}
+	for _, v := range expected[3:] {
+		found := false
+		for _, a := range actual[3:] {
+			if a == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("Missing delivery %q in %v", v, actual)
+		}
+	}
+
+	// check the request headers
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if len(server.requests) != 6 {
+		t.Fatalf("Expected 6 webhook requests, got %d", len(server.requests))
+	}
+
+	for i, r := range server.requests {
+		hookId := r.Header.Get(core.WebhookHeaderId)
+		signature := r.Header.Get(core.WebhookHeaderSignature)
+
+		if r.Header.Get(core.WebhookHeaderDeliveryId) == "" {
+			t.Fatalf("[%d] Missing delivery id header", i)
+		}
+
+		if hookId == "filtered" {
+			if signature != "" {
+				t.Fatalf("[%d] Expected no signature for webhook without secret, got %q", i, signature)
+			}
+			continue
+		}
+
+		var timestamp int64
+		if _, err := fmt.Sscanf(signature, "t=%d,", &timestamp); err != nil {
+			t.Fatalf("[%d] Invalid signature header %q", i, signature)
+		}
+
+		if expected := core.SignWebhookPayload("test_secret", timestamp, []byte(server.bodies[i])); signature != expected {
+			t.Fatalf("[%d] Expected signature %q, got %q", i, expected, signature)
+		}
+	}
+}
+
+func TestSendWebhookDeliveryRetries(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	server := newWebhookTestServer(500)
+	defer server.Close()
+
+	app.Settings().Webhooks.MaxRetries = 1
+	app.Settings().Webhooks.Hooks = []core.WebhookConfig{
+		{
+			Id:          "test",
+			URL:         server.URL,
+			Collections: []string{"demo2"},
+			Actions:     []string{core.WebhookActionCreate},
+			Enabled:     true,
+		},
+	}
+
+	collection, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("title", "webhook")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	var delivery *core.WebhookDelivery
+	for i := 0; i < 100; i++ {
+		deliveries := waitWebhookDeliveries(t, app, core.WebhookDeliveryStatusPending, 1)
+		if deliveries[0].Attempts == 1 {
+			delivery = deliveries[0]
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if delivery == nil {
+		t.Fatal("Expected the initial delivery attempt to complete")
+	}
+
+	if delivery.ResponseStatus != 500 || delivery.Error == "" {
+		t.Fatalf("Expected failed attempt with status 500 and error, got %d (%q)", delivery.ResponseStatus, delivery.Error)
+	}
+
+	if delivery.NextAttempt.Time().Sub(delivery.Updated.Time()) != time.Minute {
+		t.Fatalf("Expected the next attempt to be after 1 minute, got %v", delivery.NextAttempt)
+	}
+
+	// not ready for retry yet
+	if err := app.RetryWebhookDeliveries(); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := app.FindWebhookDeliveryById(delivery.Id); d.Attempts != 1 {
+		t.Fatalf("Expected the delivery to not be retried yet, got %d attempts", d.Attempts)
+	}
+
+	// reached max retries
+	_, err = app.AuxNonconcurrentDB().Update(
+		core.WebhookDeliveriesTableName,
+		dbx.Params{"nextAttempt": ""},
+		dbx.HashExp{"id": delivery.Id},
+	).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// concurrent runs shouldn't resend the same delivery
+	server.mu.Lock()
+	totalRequests := len(server.requests)
+	server.mu.Unlock()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := app.RetryWebhookDeliveries(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	server.mu.Lock()
+	totalRequests = len(server.requests) - totalRequests
+	server.mu.Unlock()
+	if totalRequests != 1 {
+		t.Fatalf("Expected the delivery to be resent only once, got %d requests", totalRequests)
+	}
+	delivery, err = app.FindWebhookDeliveryById(delivery.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != core.WebhookDeliveryStatusFailed || delivery.Attempts != 2 || !delivery.NextAttempt.IsZero() {
+		t.Fatalf("Expected failed delivery with 2 attempts, got %q with %d attempts", delivery.Status, delivery.Attempts)
+	}
+
+	// manual resend
+	server.setStatus(204)
+	if err := app.SendWebhookDelivery(delivery); err != nil {
+		t.Fatal(err)
+	}
+	delivery, err = app.FindWebhookDeliveryById(delivery.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != core.WebhookDeliveryStatusSuccess || delivery.Attempts != 3 || delivery.Error != "" || delivery.ResponseStatus != 204 {
+		t.Fatalf("Expected successful delivery, got %+v", delivery)
+	}
+
+	// missing webhook config
+	app.Settings().Webhooks.Hooks = nil
+	if err := app.SendWebhookDelivery(delivery); err == nil {
+		t.Fatal("Expected missing webhook error")
+	}
+}
+
+func TestWebhookWildcardSystemCollections(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	server := newWebhookTestServer(200)
+	defer server.Close()
+
+	app.Settings().Webhooks.Hooks = []core.WebhookConfig{
+		{
+			Id:          "wildcard",
+			URL:         server.URL,
+			Collections: []string{"*"},
+			Actions:     []string{core.WebhookActionCreate},
+			Enabled:     true,
+		},
+	}
+
+	superusers, err := app.FindCollectionByNameOrId(core.CollectionNameSuperusers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createSuperuser := func(email string) {
+		superuser := core.NewRecord(superusers)
+		superuser.SetEmail(email)
+		superuser.SetPassword("1234567890")
+		if err := app.Save(superuser); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	demo2, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createSuperuser("webhook1@example.com")
+
+	record := core.NewRecord(demo2)
+	record.Set("title", "webhook")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries := waitWebhookDeliveries(t, app, core.WebhookDeliveryStatusSuccess, 1)
+	if deliveries[0].CollectionRef != demo2.Id {
+		t.Fatalf("Expected only the %q delivery, got %q", demo2.Name, deliveries[0].CollectionRef)
+	}
+
+	// explicitly listed system collection
+	app.Settings().Webhooks.Hooks[0].Collections = []string{"*", core.CollectionNameSuperusers}
+
+	createSuperuser("webhook2@example.com")
+
+	deliveries = waitWebhookDeliveries(t, app, core.WebhookDeliveryStatusSuccess, 2)
+	if deliveries[1].CollectionRef != superusers.Id {
+		t.Fatalf("Expected %q delivery, got %q", superusers.Name, deliveries[1].CollectionRef)
+	}
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// WebhookDeliveryQuery returns a new WebhookDelivery select query.
+func (app *BaseApp) WebhookDeliveryQuery() *dbx.SelectQuery {
+	return app.AuxModelQuery(&WebhookDelivery{})
+}
+
+// FindWebhookDeliveryById finds a single WebhookDelivery entry by its id.
+func (app *BaseApp) FindWebhookDeliveryById(id string) (*WebhookDelivery, error) {
+	model := &WebhookDelivery{}
+
+	err := app.WebhookDeliveryQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// RetryWebhookDeliveries resends all pending webhook deliveries
+// whose next attempt time has been reached.
+func (app *BaseApp) RetryWebhookDeliveries() error {
+	deliveries := []*WebhookDelivery{}
+
+	err := app.WebhookDeliveryQuery().
+		AndWhere(dbx.HashExp{"status": WebhookDeliveryStatusPending}).
+		AndWhere(dbx.NewExp("[[nextAttempt]] <= {:now}", dbx.Params{"now": types.NowDateTime().String()})).
+		OrderBy("nextAttempt ASC").
+		All(&deliveries)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	// the request errors are already stored in the delivery entries
+	// so here we are interested only in the unsaved state changes
+	for _, delivery := range deliveries {
+		claimed, err := claimWebhookDelivery(app, delivery)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if !claimed {
+			continue // already picked by another run
+		}
+
+		if _, saveErr := sendWebhookDelivery(app, delivery); saveErr != nil {
+			errs = append(errs, saveErr)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// claimWebhookDelivery atomically postpones the next attempt of the
+// loaded pending delivery so that it couldn't be resent concurrently.
+//
+// It returns false if the delivery was already claimed or modified
+// since it was loaded.
+func claimWebhookDelivery(app App, delivery *WebhookDelivery) (bool, error) {
+	// give enough time for the attempt to complete before considering it for another retry
+	lease := types.NowDateTime().Add(webhookHTTPClient.Timeout + time.Minute)
+
+	result, err := app.AuxNonconcurrentDB().Update(
+		WebhookDeliveriesTableName,
+		dbx.Params{"nextAttempt": lease.String()},
+		dbx.HashExp{
+			"id":          delivery.Id,
+			"status":      WebhookDeliveryStatusPending,
+			"nextAttempt": delivery.NextAttempt.String(),
+		},
+	).Execute()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if affected == 0 {
+		return false, nil
+	}
+
+	delivery.NextAttempt = lease
+
+	return true, nil
+}
+
+// DeleteOldWebhookDeliveries delete all webhook deliveries that are created before createdBefore.
+//
+// For better performance the deliveries delete is executed as plain SQL statement,
+// aka. no delete model hook events will be fired.
+func (app *BaseApp) DeleteOldWebhookDeliveries(createdBefore time.Time) error {
+	formattedDate := createdBefore.UTC().Format(types.DefaultDateLayout)
+	expr := dbx.NewExp("[[created]] <= {:date}", dbx.Params{"date": formattedDate})
+
+	_, err := app.auxNonconcurrentDB.Delete(WebhookDeliveriesTableName, expr).Execute()
+
+	return err
+}
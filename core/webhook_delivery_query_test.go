@@ -0,0 +1,88 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestFindWebhookDeliveryById(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := tests.StubWebhookDeliveriesData(app); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		id          string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{"wd1", false},
+		{"wd2", false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.id), func(t *testing.T) {
+			delivery, err := app.FindWebhookDeliveryById(s.id)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr to be %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if delivery != nil && delivery.Id != s.id {
+				t.Fatalf("Expected delivery with id %q, got %q", s.id, delivery.Id)
+			}
+		})
+	}
+}
+
+func TestDeleteOldWebhookDeliveries(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := tests.StubWebhookDeliveriesData(app); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		date          string
+		expectedTotal int
+	}{
+		{"2022-01-01 10:00:00.000Z", 2},
+		{"2022-05-01 11:00:00.000Z", 1},
+		{"2022-05-03 11:00:00.000Z", 0},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.date, func(t *testing.T) {
+			date, err := time.Parse(types.DefaultDateLayout, s.date)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := app.DeleteOldWebhookDeliveries(date); err != nil {
+				t.Fatal(err)
+			}
+
+			var total int
+			if err := app.WebhookDeliveryQuery().Select("count(*)").Row(&total); err != nil {
+				t.Fatal(err)
+			}
+
+			if total != s.expectedTotal {
+				t.Fatalf("Expected %d remaining deliveries, got %d", s.expectedTotal, total)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	core.SystemMigrations.Add(&core.Migration{
+		Up: func(txApp core.App) error {
+			_, execErr := txApp.AuxDB().NewQuery(`
+				CREATE TABLE IF NOT EXISTS {{_revisions}} (
+					[[id]]                TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL,
+					[[collectionRef]]     TEXT DEFAULT "" NOT NULL,
+					[[recordRef]]         TEXT DEFAULT "" NOT NULL,
+					[[action]]            TEXT DEFAULT "" NOT NULL,
+					[[authCollectionRef]] TEXT DEFAULT "" NOT NULL,
+					[[authRef]]           TEXT DEFAULT "" NOT NULL,
+					[[changes]]           JSON DEFAULT "{}" NOT NULL,
+					[[created]]           TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_revisions_record on {{_revisions}} ([[collectionRef]], [[recordRef]]);
+				CREATE INDEX IF NOT EXISTS idx_revisions_auth on {{_revisions}} ([[authCollectionRef]], [[authRef]]);
+				CREATE INDEX IF NOT EXISTS idx_revisions_created on {{_revisions}} ([[created]]);
+			`).Execute()
+
+			return execErr
+		},
+		Down: func(txApp core.App) error {
+			_, err := txApp.AuxDB().DropTable("_revisions").Execute()
+			return err
+		},
+		ReapplyCondition: func(txApp core.App, runner *core.MigrationsRunner, fileName string) (bool, error) {
+			// reapply only if the _revisions table doesn't exist
+			exists := txApp.AuxHasTable("_revisions")
+			return !exists, nil
+		},
+	})
+}
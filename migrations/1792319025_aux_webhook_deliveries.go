@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	core.SystemMigrations.Add(&core.Migration{
+		Up: func(txApp core.App) error {
+			_, execErr := txApp.AuxDB().NewQuery(`
+				CREATE TABLE IF NOT EXISTS {{_webhookDeliveries}} (
+					[[id]]             TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL,
+					[[webhookRef]]     TEXT DEFAULT "" NOT NULL,
+					[[url]]            TEXT DEFAULT "" NOT NULL,
+					[[collectionRef]]  TEXT DEFAULT "" NOT NULL,
+					[[recordRef]]      TEXT DEFAULT "" NOT NULL,
+					[[action]]         TEXT DEFAULT "" NOT NULL,
+					[[payload]]        JSON DEFAULT "{}" NOT NULL,
+					[[status]]         TEXT DEFAULT "" NOT NULL,
+					[[error]]          TEXT DEFAULT "" NOT NULL,
+					[[attempts]]       INTEGER DEFAULT 0 NOT NULL,
+					[[responseStatus]] INTEGER DEFAULT 0 NOT NULL,
+					[[nextAttempt]]    TEXT DEFAULT "" NOT NULL,
+					[[created]]        TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+					[[updated]]        TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_webhookDeliveries_webhook on {{_webhookDeliveries}} ([[webhookRef]]);
+				CREATE INDEX IF NOT EXISTS idx_webhookDeliveries_status on {{_webhookDeliveries}} ([[status]], [[nextAttempt]]);
+				CREATE INDEX IF NOT EXISTS idx_webhookDeliveries_created on {{_webhookDeliveries}} ([[created]]);
+			`).Execute()
+
+			return execErr
+		},
+		Down: func(txApp core.App) error {
+			_, err := txApp.AuxDB().DropTable("_webhookDeliveries").Execute()
+			return err
+		},
+		ReapplyCondition: func(txApp core.App, runner *core.MigrationsRunner, fileName string) (bool, error) {
+			// reapply only if the _webhookDeliveries table doesn't exist
+			exists := txApp.AuxHasTable("_webhookDeliveries")
+			return !exists, nil
+		},
+	})
+}
@@ -0,0 +1,124 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		col := core.NewBaseCollection(core.CollectionNameWebAuthnCredentials)
+		col.System = true
+
+		ownerRule := "@request.auth.id != '' && recordRef = @request.auth.id && collectionRef = @request.auth.collectionId"
+		col.ListRule = types.Pointer(ownerRule)
+		col.ViewRule = types.Pointer(ownerRule)
+		col.DeleteRule = types.Pointer(ownerRule)
+
+		col.Fields.Add(&core.TextField{
+			Name:     "collectionRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "recordRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:   "name",
+			System: true,
+			Max:    255,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "credentialId",
+			System:   true,
+			Required: true,
+			Max:      2000,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "publicKey",
+			System:   true,
+			Hidden:   true,
+			Required: true,
+			Max:      5000,
+		})
+		col.Fields.Add(&core.NumberField{
+			Name:     "algorithm",
+			System:   true,
+			Required: true,
+			OnlyInt:  true,
+		})
+		col.Fields.Add(&core.NumberField{
+			Name:    "signCount",
+			System:  true,
+			OnlyInt: true,
+		})
+		col.Fields.Add(&core.JSONField{
+			Name:    "transports",
+			System:  true,
+			MaxSize: 2000,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:   "aaguid",
+			System: true,
+		})
+		col.Fields.Add(&core.BoolField{
+			Name:   "backupEligible",
+			System: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		col.AddIndex("idx_webauthnCredentials_collectionRef_recordRef", false, "collectionRef, recordRef", "")
+		col.AddIndex("idx_webauthnCredentials_collection_credentialId", true, "collectionRef, credentialId", "")
+
+		if err := txApp.Save(col); err != nil {
+			return err
+		}
+
+		// populate the default WebAuthn options of the existing auth collections
+		authCollections, err := txApp.FindAllCollections(core.CollectionTypeAuth)
+		if err != nil {
+			return err
+		}
+		for _, c := range authCollections {
+			if c.WebAuthn.Duration > 0 {
+				continue
+			}
+
+			c.WebAuthn.Duration = 300
+			c.WebAuthn.UserVerification = core.WebAuthnUserVerificationPreferred
+
+			if err := txApp.Save(c); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameWebAuthnCredentials)
+		if err != nil {
+			return nil // already deleted
+		}
+
+		// system collections can't be deleted through the regular Delete
+		_, err = txApp.DB().DropTable(col.Name).Execute()
+		if err != nil {
+			return err
+		}
+
+		_, err = txApp.DB().Delete("_collections", dbx.HashExp{"id": col.Id}).Execute()
+
+		return err
+	})
+}
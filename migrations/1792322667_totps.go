@@ -0,0 +1,79 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		col := core.NewBaseCollection(core.CollectionNameTOTPs)
+		col.System = true
+
+		// note: no API rules (aka. superusers only) since the TOTP
+		// enrollment is managed through the dedicated auth endpoints
+
+		col.Fields.Add(&core.TextField{
+			Name:     "collectionRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "recordRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "secret",
+			System:   true,
+			Hidden:   true,
+			Required: true,
+			Max:      255,
+		})
+		col.Fields.Add(&core.BoolField{
+			Name:   "confirmed",
+			System: true,
+		})
+		col.Fields.Add(&core.JSONField{
+			Name:    "recoveryCodes",
+			System:  true,
+			Hidden:  true,
+			MaxSize: 5000,
+		})
+		col.Fields.Add(&core.NumberField{
+			Name:    "lastStep",
+			System:  true,
+			Hidden:  true,
+			OnlyInt: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		col.AddIndex("idx_totps_collectionRef_recordRef", true, "collectionRef, recordRef", "")
+
+		return txApp.Save(col)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameTOTPs)
+		if err != nil {
+			return nil // already deleted
+		}
+
+		// system collections can't be deleted through the regular Delete
+		_, err = txApp.DB().DropTable(col.Name).Execute()
+		if err != nil {
+			return err
+		}
+
+		_, err = txApp.DB().Delete("_collections", dbx.HashExp{"id": col.Id}).Execute()
+
+		return err
+	})
+}
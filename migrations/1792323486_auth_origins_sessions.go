@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// adds the auth session details to the authOrigins collection
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameAuthOrigins)
+		if err != nil {
+			return err
+		}
+
+		pos := len(col.Fields)
+		for i, f := range col.Fields {
+			if f.GetName() == "created" {
+				pos = i
+				break
+			}
+		}
+
+		col.Fields.AddAt(
+			pos,
+			&core.TextField{
+				Name:   "ip",
+				System: true,
+				Max:    255,
+			},
+			&core.TextField{
+				Name:   "userAgent",
+				System: true,
+				Max:    255,
+			},
+			&core.DateField{
+				Name:   "lastSeen",
+				System: true,
+			},
+		)
+
+		return txApp.Save(col)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameAuthOrigins)
+		if err != nil {
+			return err
+		}
+
+		col.Fields.RemoveByName("ip")
+		col.Fields.RemoveByName("userAgent")
+		col.Fields.RemoveByName("lastSeen")
+
+		// system fields can't be removed with the regular validated save
+		return txApp.SaveNoValidate(col)
+	})
+}
@@ -0,0 +1,83 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		col := core.NewBaseCollection(core.CollectionNameAPIKeys)
+		col.System = true
+
+		// note: no API rules (aka. superusers only) since the keys
+		// are managed through the dedicated auth record endpoints
+
+		col.Fields.Add(&core.TextField{
+			Name:     "collectionRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "recordRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "name",
+			System:   true,
+			Required: true,
+			Max:      100,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "keyHash",
+			System:   true,
+			Hidden:   true,
+			Required: true,
+			Max:      255,
+		})
+		col.Fields.Add(&core.DateField{
+			Name:   "expires",
+			System: true,
+		})
+		col.Fields.Add(&core.JSONField{
+			Name:    "allowedIPs",
+			System:  true,
+			MaxSize: 5000,
+		})
+		col.Fields.Add(&core.JSONField{
+			Name:    "scopes",
+			System:  true,
+			MaxSize: 5000,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		col.AddIndex("idx_apiKeys_collectionRef_recordRef", false, "collectionRef, recordRef", "")
+
+		return txApp.Save(col)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameAPIKeys)
+		if err != nil {
+			return nil // already deleted
+		}
+
+		// system collections can't be deleted through the regular Delete
+		_, err = txApp.DB().DropTable(col.Name).Execute()
+		if err != nil {
+			return err
+		}
+
+		_, err = txApp.DB().Delete("_collections", dbx.HashExp{"id": col.Id}).Execute()
+
+		return err
+	})
+}
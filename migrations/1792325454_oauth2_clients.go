@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		col := core.NewBaseCollection(core.CollectionNameOAuth2Clients)
+		col.System = true
+
+		// note: no API rules (aka. superusers only)
+
+		col.Fields.Add(&core.TextField{
+			Name:     "collectionRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "name",
+			System:   true,
+			Required: true,
+			Max:      100,
+		})
+		col.Fields.Add(&core.PasswordField{
+			Name:   "secret",
+			System: true,
+			Hidden: true,
+		})
+		col.Fields.Add(&core.JSONField{
+			Name:     "redirectURIs",
+			System:   true,
+			Required: true,
+			MaxSize:  20000,
+		})
+		col.Fields.Add(&core.BoolField{
+			Name:   "skipConsent",
+			System: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		col.AddIndex("idx_oauth2Clients_collectionRef", false, "collectionRef", "")
+
+		return txApp.Save(col)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameOAuth2Clients)
+		if err != nil {
+			return nil // already deleted
+		}
+
+		// system collections can't be deleted through the regular Delete
+		_, err = txApp.DB().DropTable(col.Name).Execute()
+		if err != nil {
+			return err
+		}
+
+		_, err = txApp.DB().Delete("_collections", dbx.HashExp{"id": col.Id}).Execute()
+
+		return err
+	})
+}
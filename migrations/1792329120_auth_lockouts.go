@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		col := core.NewBaseCollection(core.CollectionNameAuthLockouts)
+		col.System = true
+
+		// note: no API rules (aka. superusers only) since the lockouts
+		// are managed through the password auth and unlock endpoints
+
+		col.Fields.Add(&core.TextField{
+			Name:     "collectionRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:   "recordRef",
+			System: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:   "identity",
+			System: true,
+			Max:    255,
+		})
+		col.Fields.Add(&core.NumberField{
+			Name:    "failures",
+			System:  true,
+			OnlyInt: true,
+		})
+		col.Fields.Add(&core.DateField{
+			Name:   "lastFailure",
+			System: true,
+		})
+		col.Fields.Add(&core.DateField{
+			Name:   "lockedUntil",
+			System: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		col.AddIndex("idx_authLockouts_collectionRef_recordRef_identity", true, "collectionRef, recordRef, identity", "")
+
+		return txApp.Save(col)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameAuthLockouts)
+		if err != nil {
+			return nil // already deleted
+		}
+
+		// system collections can't be deleted through the regular Delete
+		_, err = txApp.DB().DropTable(col.Name).Execute()
+		if err != nil {
+			return err
+		}
+
+		_, err = txApp.DB().Delete("_collections", dbx.HashExp{"id": col.Id}).Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,87 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		col := core.NewBaseCollection(core.CollectionNameUploads)
+		col.System = true
+
+		// note: no API rules (aka. superusers only) since the uploads
+		// are managed through the dedicated resumable upload endpoints
+
+		col.Fields.Add(&core.TextField{
+			Name:     "collectionRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "recordRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "targetCollectionRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "targetField",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "filename",
+			System:   true,
+			Required: true,
+			Max:      255,
+		})
+		col.Fields.Add(&core.NumberField{
+			Name:     "size",
+			System:   true,
+			Required: true,
+			OnlyInt:  true,
+			Min:      types.Pointer(1.0),
+		})
+		col.Fields.Add(&core.NumberField{
+			Name:    "offset",
+			System:  true,
+			OnlyInt: true,
+			Min:     types.Pointer(0.0),
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			System:   true,
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		col.AddIndex("idx_uploads_collectionRef_recordRef", false, "collectionRef, recordRef", "")
+		col.AddIndex("idx_uploads_updated", false, "updated", "")
+
+		return txApp.Save(col)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameUploads)
+		if err != nil {
+			return nil // already deleted
+		}
+
+		// system collections can't be deleted through the regular Delete
+		_, err = txApp.DB().DropTable(col.Name).Execute()
+		if err != nil {
+			return err
+		}
+
+		_, err = txApp.DB().Delete("_collections", dbx.HashExp{"id": col.Id}).Execute()
+
+		return err
+	})
+}
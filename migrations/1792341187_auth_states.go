@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		col := core.NewBaseCollection(core.CollectionNameAuthStates)
+		col.System = true
+
+		// note: no API rules (aka. superusers only) since the auth states
+		// are managed internally by the multi-step auth flows
+
+		col.Fields.Add(&core.TextField{
+			Name:     "collectionRef",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:   "recordRef",
+			System: true,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "kind",
+			System:   true,
+			Required: true,
+			Max:      100,
+		})
+		col.Fields.Add(&core.TextField{
+			Name:     "key",
+			System:   true,
+			Required: true,
+			Hidden:   true,
+		})
+		col.Fields.Add(&core.JSONField{
+			Name:   "data",
+			System: true,
+			Hidden: true,
+		})
+		col.Fields.Add(&core.DateField{
+			Name:     "expires",
+			System:   true,
+			Required: true,
+		})
+		col.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		col.AddIndex("idx_authStates_kind_key", true, "kind, key", "")
+		col.AddIndex("idx_authStates_collectionRef_recordRef_kind", false, "collectionRef, recordRef, kind", "")
+		col.AddIndex("idx_authStates_expires", false, "expires", "")
+
+		return txApp.Save(col)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameAuthStates)
+		if err != nil {
+			return nil // already deleted
+		}
+
+		// system collections can't be deleted through the regular Delete
+		_, err = txApp.DB().DropTable(col.Name).Execute()
+		if err != nil {
+			return err
+		}
+
+		_, err = txApp.DB().Delete("_collections", dbx.HashExp{"id": col.Id}).Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// replaces the unique authOrigins fingerprint index with a regular one
+// since each login creates its own auth session (aka. authOrigins record)
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameAuthOrigins)
+		if err != nil {
+			return err
+		}
+
+		col.RemoveIndex("idx_authOrigins_unique_pairs")
+		col.AddIndex("idx_authOrigins_fingerprint", false, "collectionRef, recordRef, fingerprint", "")
+
+		// system unique indexes can't be removed with the regular validated save
+		return txApp.SaveNoValidate(col)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameAuthOrigins)
+		if err != nil {
+			return err
+		}
+
+		// keep only the latest session of each fingerprint
+		_, err = txApp.DB().NewQuery(
+			"DELETE FROM {{" + core.CollectionNameAuthOrigins + "}} WHERE [[id]] NOT IN (" +
+				"SELECT [[id]] FROM (SELECT [[id]], ROW_NUMBER() OVER (" +
+				"PARTITION BY [[collectionRef]], [[recordRef]], [[fingerprint]] ORDER BY [[created]] DESC" +
+				") AS [[rn]] FROM {{" + core.CollectionNameAuthOrigins + "}}) WHERE [[rn]] = 1)",
+		).Execute()
+		if err != nil {
+			return err
+		}
+
+		col.RemoveIndex("idx_authOrigins_fingerprint")
+		col.AddIndex("idx_authOrigins_unique_pairs", true, "collectionRef, recordRef, fingerprint", "")
+
+		return txApp.Save(col)
+	})
+}
@@ -65,10 +65,14 @@ migrate((app) => {
       },
       {
         "cost": 0,
+        "forbidCommon": false,
+        "forbiddenFields": null,
         "help": "",
         "hidden": true,
+        "historyDepth": 0,
         "id": "password@TEST_RANDOM",
         "max": 0,
+        "maxAge": 0,
         "min": 8,
         "name": "password",
         "pattern": "",
@@ -134,6 +138,18 @@ migrate((app) => {
       "CREATE UNIQUE INDEX ` + "`" + `idx_tokenKey_@TEST_RANDOM` + "`" + ` ON ` + "`" + `new_name` + "`" + ` (` + "`" + `tokenKey` + "`" + `)",
       "CREATE UNIQUE INDEX ` + "`" + `idx_email_@TEST_RANDOM` + "`" + ` ON ` + "`" + `new_name` + "`" + ` (` + "`" + `email` + "`" + `) WHERE ` + "`" + `email` + "`" + ` != ''"
     ],
+    "ldap": {
+      "baseDN": "",
+      "bindDN": "",
+      "enabled": false,
+      "groupMappings": [],
+      "groupsAttribute": "memberOf",
+      "mappedFields": {},
+      "startTLS": false,
+      "tlsSkipVerify": false,
+      "url": "",
+      "userFilter": "(uid={username})"
+    },
     "listRule": "@request.auth.id != '' && 1 > 0 || 'backtick` + "`" + `test' = 0",
     "manageRule": "1 != 2",
     "mfa": {
@@ -164,7 +180,14 @@ migrate((app) => {
       "enabled": true,
       "identityFields": [
         "email"
-      ]
+      ],
+      "lockout": {
+        "alert": false,
+        "delay": 1,
+        "duration": 900,
+        "enabled": false,
+        "maxFailures": 5
+      }
     },
     "passwordResetToken": {
       "duration": 1800
@@ -173,7 +196,22 @@ migrate((app) => {
       "body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, please ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
       "subject": "Reset your {APP_NAME} password"
     },
+    "saml": {
+      "allowIdPInitiated": false,
+      "enabled": false,
+      "idpCertificate": "",
+      "idpEntityId": "",
+      "idpSSOURL": "",
+      "mappedFields": {},
+      "nameIdFormat": "",
+      "redirectURLs": [],
+      "spEntityId": ""
+    },
     "system": true,
+    "totp": {
+      "enabled": false,
+      "issuer": ""
+    },
     "type": "auth",
     "updateRule": null,
     "verificationTemplate": {
@@ -183,7 +221,15 @@ migrate((app) => {
     "verificationToken": {
       "duration": 86400
     },
-    "viewRule": "id = \"1\""
+    "viewRule": "id = \"1\"",
+    "webauthn": {
+      "duration": 300,
+      "enabled": false,
+      "origins": [],
+      "rpId": "",
+      "rpName": "",
+      "userVerification": "preferred"
+    }
   });
 
   return app.save(collection);
@@ -247,10 +293,14 @@ func init() {
 				},
 				{
 					"cost": 0,
+					"forbidCommon": false,
+					"forbiddenFields": null,
 					"help": "",
 					"hidden": true,
+					"historyDepth": 0,
 					"id": "password@TEST_RANDOM",
 					"max": 0,
+					"maxAge": 0,
 					"min": 8,
 					"name": "password",
 					"pattern": "",
@@ -316,6 +366,18 @@ func init() {
 				"CREATE UNIQUE INDEX ` + "` + \"`\" + `" + `idx_tokenKey_@TEST_RANDOM` + "` + \"`\" + `" + ` ON ` + "` + \"`\" + `" + `new_name` + "` + \"`\" + `" + ` (` + "` + \"`\" + `" + `tokenKey` + "` + \"`\" + `" + `)",
 				"CREATE UNIQUE INDEX ` + "` + \"`\" + `" + `idx_email_@TEST_RANDOM` + "` + \"`\" + `" + ` ON ` + "` + \"`\" + `" + `new_name` + "` + \"`\" + `" + ` (` + "` + \"`\" + `" + `email` + "` + \"`\" + `" + `) WHERE ` + "` + \"`\" + `" + `email` + "` + \"`\" + `" + ` != ''"
 			],
+			"ldap": {
+				"baseDN": "",
+				"bindDN": "",
+				"enabled": false,
+				"groupMappings": [],
+				"groupsAttribute": "memberOf",
+				"mappedFields": {},
+				"startTLS": false,
+				"tlsSkipVerify": false,
+				"url": "",
+				"userFilter": "(uid={username})"
+			},
 			"listRule": "@request.auth.id != '' && 1 > 0 || 'backtick` + "` + \"`\" + `" + `test' = 0",
 			"manageRule": "1 != 2",
 			"mfa": {
@@ -346,7 +408,14 @@ func init() {
 				"enabled": true,
 				"identityFields": [
 					"email"
-				]
+				],
+				"lockout": {
+					"alert": false,
+					"delay": 1,
+					"duration": 900,
+					"enabled": false,
+					"maxFailures": 5
+				}
 			},
 			"passwordResetToken": {
 				"duration": 1800
@@ -355,7 +424,22 @@ func init() {
 				"body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, please ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
 				"subject": "Reset your {APP_NAME} password"
 			},
+			"saml": {
+				"allowIdPInitiated": false,
+				"enabled": false,
+				"idpCertificate": "",
+				"idpEntityId": "",
+				"idpSSOURL": "",
+				"mappedFields": {},
+				"nameIdFormat": "",
+				"redirectURLs": [],
+				"spEntityId": ""
+			},
 			"system": true,
+			"totp": {
+				"enabled": false,
+				"issuer": ""
+			},
 			"type": "auth",
 			"updateRule": null,
 			"verificationTemplate": {
@@ -365,7 +449,15 @@ func init() {
 			"verificationToken": {
 				"duration": 86400
 			},
-			"viewRule": "id = \"1\""
+			"viewRule": "id = \"1\"",
+			"webauthn": {
+				"duration": 300,
+				"enabled": false,
+				"origins": [],
+				"rpId": "",
+				"rpName": "",
+				"userVerification": "preferred"
+			}
 		}` + "`" + `
 
 		collection := &core.Collection{}
@@ -518,10 +610,14 @@ migrate((app) => {
       },
       {
         "cost": 0,
+        "forbidCommon": false,
+        "forbiddenFields": null,
         "help": "",
         "hidden": true,
+        "historyDepth": 0,
         "id": "password@TEST_RANDOM",
         "max": 0,
+        "maxAge": 0,
         "min": 8,
         "name": "password",
         "pattern": "",
@@ -587,6 +683,18 @@ migrate((app) => {
       "CREATE UNIQUE INDEX ` + "`" + `idx_tokenKey_@TEST_RANDOM` + "`" + ` ON ` + "`" + `test123` + "`" + ` (` + "`" + `tokenKey` + "`" + `)",
       "CREATE UNIQUE INDEX ` + "`" + `idx_email_@TEST_RANDOM` + "`" + ` ON ` + "`" + `test123` + "`" + ` (` + "`" + `email` + "`" + `) WHERE ` + "`" + `email` + "`" + ` != ''"
     ],
+    "ldap": {
+      "baseDN": "",
+      "bindDN": "",
+      "enabled": false,
+      "groupMappings": [],
+      "groupsAttribute": "memberOf",
+      "mappedFields": {},
+      "startTLS": false,
+      "tlsSkipVerify": false,
+      "url": "",
+      "userFilter": "(uid={username})"
+    },
     "listRule": "@request.auth.id != '' && 1 > 0 || 'backtick` + "`" + `test' = 0",
     "manageRule": "1 != 2",
     "mfa": {
@@ -617,7 +725,14 @@ migrate((app) => {
       "enabled": true,
       "identityFields": [
         "email"
-      ]
+      ],
+      "lockout": {
+        "alert": false,
+        "delay": 1,
+        "duration": 900,
+        "enabled": false,
+        "maxFailures": 5
+      }
     },
     "passwordResetToken": {
       "duration": 1800
@@ -626,7 +741,22 @@ migrate((app) => {
       "body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, please ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
       "subject": "Reset your {APP_NAME} password"
     },
+    "saml": {
+      "allowIdPInitiated": false,
+      "enabled": false,
+      "idpCertificate": "",
+      "idpEntityId": "",
+      "idpSSOURL": "",
+      "mappedFields": {},
+      "nameIdFormat": "",
+      "redirectURLs": [],
+      "spEntityId": ""
+    },
     "system": false,
+    "totp": {
+      "enabled": false,
+      "issuer": ""
+    },
     "type": "auth",
     "updateRule": null,
     "verificationTemplate": {
@@ -636,7 +766,15 @@ migrate((app) => {
     "verificationToken": {
       "duration": 86400
     },
-    "viewRule": "id = \"1\""
+    "viewRule": "id = \"1\"",
+    "webauthn": {
+      "duration": 300,
+      "enabled": false,
+      "origins": [],
+      "rpId": "",
+      "rpName": "",
+      "userVerification": "preferred"
+    }
   });
 
   return app.save(collection);
@@ -703,10 +841,14 @@ func init() {
 				},
 				{
 					"cost": 0,
+					"forbidCommon": false,
+					"forbiddenFields": null,
 					"help": "",
 					"hidden": true,
+					"historyDepth": 0,
 					"id": "password@TEST_RANDOM",
 					"max": 0,
+					"maxAge": 0,
 					"min": 8,
 					"name": "password",
 					"pattern": "",
@@ -772,6 +914,18 @@ func init() {
 				"CREATE UNIQUE INDEX ` + "` + \"`\" + `" + `idx_tokenKey_@TEST_RANDOM` + "` + \"`\" + `" + ` ON ` + "` + \"`\" + `" + `test123` + "` + \"`\" + `" + ` (` + "` + \"`\" + `" + `tokenKey` + "` + \"`\" + `" + `)",
 				"CREATE UNIQUE INDEX ` + "` + \"`\" + `" + `idx_email_@TEST_RANDOM` + "` + \"`\" + `" + ` ON ` + "` + \"`\" + `" + `test123` + "` + \"`\" + `" + ` (` + "` + \"`\" + `" + `email` + "` + \"`\" + `" + `) WHERE ` + "` + \"`\" + `" + `email` + "` + \"`\" + `" + ` != ''"
 			],
+			"ldap": {
+				"baseDN": "",
+				"bindDN": "",
+				"enabled": false,
+				"groupMappings": [],
+				"groupsAttribute": "memberOf",
+				"mappedFields": {},
+				"startTLS": false,
+				"tlsSkipVerify": false,
+				"url": "",
+				"userFilter": "(uid={username})"
+			},
 			"listRule": "@request.auth.id != '' && 1 > 0 || 'backtick` + "` + \"`\" + `" + `test' = 0",
 			"manageRule": "1 != 2",
 			"mfa": {
@@ -802,7 +956,14 @@ func init() {
 				"enabled": true,
 				"identityFields": [
 					"email"
-				]
+				],
+				"lockout": {
+					"alert": false,
+					"delay": 1,
+					"duration": 900,
+					"enabled": false,
+					"maxFailures": 5
+				}
 			},
 			"passwordResetToken": {
 				"duration": 1800
@@ -811,7 +972,22 @@ func init() {
 				"body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, please ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
 				"subject": "Reset your {APP_NAME} password"
 			},
+			"saml": {
+				"allowIdPInitiated": false,
+				"enabled": false,
+				"idpCertificate": "",
+				"idpEntityId": "",
+				"idpSSOURL": "",
+				"mappedFields": {},
+				"nameIdFormat": "",
+				"redirectURLs": [],
+				"spEntityId": ""
+			},
 			"system": false,
+			"totp": {
+				"enabled": false,
+				"issuer": ""
+			},
 			"type": "auth",
 			"updateRule": null,
 			"verificationTemplate": {
@@ -821,7 +997,15 @@ func init() {
 			"verificationToken": {
 				"duration": 86400
 			},
-			"viewRule": "id = \"1\""
+			"viewRule": "id = \"1\"",
+			"webauthn": {
+				"duration": 300,
+				"enabled": false,
+				"origins": [],
+				"rpId": "",
+				"rpName": "",
+				"userVerification": "preferred"
+			}
 		}` + "`" + `
 
 		collection := &core.Collection{}
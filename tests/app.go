@@ -613,6 +613,14 @@ func NewTestAppWithConfig(config core.BaseAppConfig) (*TestApp, error) {
 		Priority: -99999,
 	})
 
+	t.OnFileScan().Bind(&hook.Handler[*core.FileScanEvent]{
+		Func: func(e *core.FileScanEvent) error {
+			t.registerEventCall("OnFileScan")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
 	t.OnRecordAuthRequest().Bind(&hook.Handler[*core.RecordAuthRequestEvent]{
 		Func: func(e *core.RecordAuthRequestEvent) error {
 			t.registerEventCall("OnRecordAuthRequest")
@@ -629,6 +637,14 @@ func NewTestAppWithConfig(config core.BaseAppConfig) (*TestApp, error) {
 		Priority: -99999,
 	})
 
+	t.OnRecordAuthLockoutRequest().Bind(&hook.Handler[*core.RecordAuthLockoutRequestEvent]{
+		Func: func(e *core.RecordAuthLockoutRequestEvent) error {
+			t.registerEventCall("OnRecordAuthLockoutRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
 	t.OnRecordAuthWithOAuth2Request().Bind(&hook.Handler[*core.RecordAuthWithOAuth2RequestEvent]{
 		Func: func(e *core.RecordAuthWithOAuth2RequestEvent) error {
 			t.registerEventCall("OnRecordAuthWithOAuth2Request")
@@ -709,6 +725,54 @@ func NewTestAppWithConfig(config core.BaseAppConfig) (*TestApp, error) {
 		Priority: -99999,
 	})
 
+	t.OnRecordRegisterWebAuthnRequest().Bind(&hook.Handler[*core.RecordRegisterWebAuthnRequestEvent]{
+		Func: func(e *core.RecordRegisterWebAuthnRequestEvent) error {
+			t.registerEventCall("OnRecordRegisterWebAuthnRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordAuthWithWebAuthnRequest().Bind(&hook.Handler[*core.RecordAuthWithWebAuthnRequestEvent]{
+		Func: func(e *core.RecordAuthWithWebAuthnRequestEvent) error {
+			t.registerEventCall("OnRecordAuthWithWebAuthnRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordConfirmTOTPSetupRequest().Bind(&hook.Handler[*core.RecordConfirmTOTPSetupRequestEvent]{
+		Func: func(e *core.RecordConfirmTOTPSetupRequestEvent) error {
+			t.registerEventCall("OnRecordConfirmTOTPSetupRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordAuthWithTOTPRequest().Bind(&hook.Handler[*core.RecordAuthWithTOTPRequestEvent]{
+		Func: func(e *core.RecordAuthWithTOTPRequestEvent) error {
+			t.registerEventCall("OnRecordAuthWithTOTPRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordAuthWithLDAPRequest().Bind(&hook.Handler[*core.RecordAuthWithLDAPRequestEvent]{
+		Func: func(e *core.RecordAuthWithLDAPRequestEvent) error {
+			t.registerEventCall("OnRecordAuthWithLDAPRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordAuthWithSAMLRequest().Bind(&hook.Handler[*core.RecordAuthWithSAMLRequestEvent]{
+		Func: func(e *core.RecordAuthWithSAMLRequestEvent) error {
+			t.registerEventCall("OnRecordAuthWithSAMLRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
 	t.OnRecordsListRequest().Bind(&hook.Handler[*core.RecordsListRequestEvent]{
 		Func: func(e *core.RecordsListRequestEvent) error {
 			t.registerEventCall("OnRecordsListRequest")
@@ -717,6 +781,38 @@ func NewTestAppWithConfig(config core.BaseAppConfig) (*TestApp, error) {
 		Priority: -99999,
 	})
 
+	t.OnRecordsAggregateRequest().Bind(&hook.Handler[*core.RecordsAggregateRequestEvent]{
+		Func: func(e *core.RecordsAggregateRequestEvent) error {
+			t.registerEventCall("OnRecordsAggregateRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordsTrashListRequest().Bind(&hook.Handler[*core.RecordsListRequestEvent]{
+		Func: func(e *core.RecordsListRequestEvent) error {
+			t.registerEventCall("OnRecordsTrashListRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordRestoreRequest().Bind(&hook.Handler[*core.RecordRequestEvent]{
+		Func: func(e *core.RecordRequestEvent) error {
+			t.registerEventCall("OnRecordRestoreRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordTrashDeleteRequest().Bind(&hook.Handler[*core.RecordRequestEvent]{
+		Func: func(e *core.RecordRequestEvent) error {
+			t.registerEventCall("OnRecordTrashDeleteRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
 	t.OnRecordViewRequest().Bind(&hook.Handler[*core.RecordRequestEvent]{
 		Func: func(e *core.RecordRequestEvent) error {
 			t.registerEventCall("OnRecordViewRequest")
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/pocketbase/pocketbase/tools/types"
 )
 
@@ -112,6 +113,195 @@ func StubMFARecords(app core.App) error {
 	return nil
 }
 
+func StubWebAuthnCredentialRecords(app core.App) error {
+	superuser2, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test2@example.com")
+	if err != nil {
+		return err
+	}
+	superuser2.SetRaw("stubId", "superuser2")
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		return err
+	}
+	user1.SetRaw("stubId", "user1")
+
+	now := types.NowDateTime()
+
+	stubs := map[*core.Record][]types.DateTime{
+		superuser2: {now, now.Add(-1 * time.Hour)},
+		user1:      {now.Add(-2 * time.Hour)},
+	}
+	for record, idDates := range stubs {
+		for i, date := range idDates {
+			id := record.GetString("stubId") + "_" + strconv.Itoa(i)
+
+			credential := core.NewWebAuthnCredential(app)
+			credential.Id = id
+			credential.SetRecordRef(record.Id)
+			credential.SetCollectionRef(record.Collection().Id)
+			credential.SetCredentialId(id + "_credential")
+			credential.SetPublicKey("test")
+			credential.SetAlgorithm(-7)
+			credential.SetRaw("created", date)
+			if err := app.SaveNoValidate(credential); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// StubTOTPRecords creates confirmed TOTP records for the test2@example.com
+// superuser and the test@example.com user with secret
+// "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" and recovery codes
+// "aaaaa-00000" and "bbbbb-11111".
+func StubTOTPRecords(app core.App) error {
+	superuser2, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test2@example.com")
+	if err != nil {
+		return err
+	}
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		return err
+	}
+
+	stubs := map[string]*core.Record{
+		"superuser2_totp": superuser2,
+		"user1_totp":      user1,
+	}
+	for id, record := range stubs {
+		totp := core.NewTOTP(app)
+		totp.Id = id
+		totp.SetRecordRef(record.Id)
+		totp.SetCollectionRef(record.Collection().Id)
+		totp.SetSecret("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+		totp.SetConfirmed(true)
+		totp.SetRecoveryCodes([]string{"aaaaa-00000", "bbbbb-11111"})
+		if err := app.SaveNoValidate(totp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StubAPIKeyRecords creates API key stubs for the test auth records.
+//
+// The plain API keys of the stubs are in the format "pbk_{id}_secret" and are:
+//   - pbk_superuser2key_secret (superusers, test2@example.com, unrestricted)
+//   - pbk_superuser2scoped_secret (superusers, test2@example.com, scopes: demo1:list, *:view)
+//   - pbk_superuser2expired_secret (superusers, test2@example.com, expired)
+//   - pbk_superuser2ip_secret (superusers, test2@example.com, allowedIPs: 10.0.0.1)
+//   - pbk_user1key_secret (users, test@example.com, unrestricted)
+func StubAPIKeyRecords(app core.App) error {
+	superuser2, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test2@example.com")
+	if err != nil {
+		return err
+	}
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		return err
+	}
+
+	expired, err := types.ParseDateTime("2020-01-01 00:00:00.000Z")
+	if err != nil {
+		return err
+	}
+
+	stubs := []struct {
+		id     string
+		record *core.Record
+		init   func(apiKey *core.APIKey)
+	}{
+		{"superuser2key", superuser2, nil},
+		{"superuser2scoped", superuser2, func(apiKey *core.APIKey) {
+			apiKey.SetScopes([]string{"demo1:list", "*:view"})
+		}},
+		{"superuser2expired", superuser2, func(apiKey *core.APIKey) {
+			apiKey.SetExpires(expired)
+		}},
+		{"superuser2ip", superuser2, func(apiKey *core.APIKey) {
+			apiKey.SetAllowedIPs([]string{"10.0.0.1"})
+		}},
+		{"user1key", user1, nil},
+	}
+	for _, stub := range stubs {
+		apiKey := core.NewAPIKey(app)
+		apiKey.Id = stub.id
+		apiKey.SetRecordRef(stub.record.Id)
+		apiKey.SetCollectionRef(stub.record.Collection().Id)
+		apiKey.SetName(stub.id)
+		apiKey.Set("keyHash", security.SHA256("secret"))
+		if stub.init != nil {
+			stub.init(apiKey)
+		}
+		if err := app.SaveNoValidate(apiKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StubOAuth2ClientRecords creates OAuth2 client stubs.
+//
+// All clients have "https://example.com/callback" redirect URI and
+// the confidential ones have "secret" as client secret:
+//   - usersconfidential (users, confidential)
+//   - userspublic (users, public, with additional "myapp://callback" redirect URI)
+//   - userstrusted (users, confidential, skipConsent)
+//   - superusersclient (superusers, confidential)
+func StubOAuth2ClientRecords(app core.App) error {
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		return err
+	}
+
+	superusers, err := app.FindCollectionByNameOrId(core.CollectionNameSuperusers)
+	if err != nil {
+		return err
+	}
+
+	stubs := []struct {
+		id         string
+		collection *core.Collection
+		init       func(client *core.OAuth2Client)
+	}{
+		{"usersconfidential", users, func(client *core.OAuth2Client) {
+			client.SetSecret("secret")
+		}},
+		{"userspublic", users, func(client *core.OAuth2Client) {
+			client.SetRedirectURIs([]string{"https://example.com/callback", "myapp://callback"})
+		}},
+		{"userstrusted", users, func(client *core.OAuth2Client) {
+			client.SetSecret("secret")
+			client.SetSkipConsent(true)
+		}},
+		{"superusersclient", superusers, func(client *core.OAuth2Client) {
+			client.SetSecret("secret")
+		}},
+	}
+	for _, stub := range stubs {
+		client := core.NewOAuth2Client(app)
+		client.Id = stub.id
+		client.SetCollectionRef(stub.collection.Id)
+		client.SetName(stub.id)
+		client.SetRedirectURIs([]string{"https://example.com/callback"})
+		if stub.init != nil {
+			stub.init(client)
+		}
+		if err := app.SaveNoValidate(client); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func StubLogsData(app *TestApp) error {
 	_, err := app.AuxDB().NewQuery(`
 		delete from {{_logs}};
@@ -145,3 +335,58 @@ func StubLogsData(app *TestApp) error {
 
 	return err
 }
+
+func StubWebhookDeliveriesData(app *TestApp) error {
+	_, err := app.AuxDB().NewQuery(`
+		delete from {{_webhookDeliveries}};
+
+		insert into {{_webhookDeliveries}} (
+			[[id]],
+			[[webhookRef]],
+			[[url]],
+			[[collectionRef]],
+			[[recordRef]],
+			[[action]],
+			[[payload]],
+			[[status]],
+			[[error]],
+			[[attempts]],
+			[[responseStatus]],
+			[[created]],
+			[[updated]]
+		)
+		values
+		(
+			"wd1",
+			"test",
+			"http://127.0.0.1:0/missing",
+			"sz5l5z67tg7gku0",
+			"llvuca81nly1qls",
+			"create",
+			'{"id":"wd1"}',
+			"success",
+			"",
+			1,
+			200,
+			"2022-05-01 10:00:00.123Z",
+			"2022-05-01 10:00:00.123Z"
+		),
+		(
+			"wd2",
+			"test",
+			"http://127.0.0.1:0/missing",
+			"sz5l5z67tg7gku0",
+			"llvuca81nly1qls",
+			"update",
+			'{"id":"wd2"}',
+			"failed",
+			"unexpected response status code 500",
+			6,
+			500,
+			"2022-05-02 10:00:00.123Z",
+			"2022-05-02 10:00:00.123Z"
+		);
+	`).Execute()
+
+	return err
+}
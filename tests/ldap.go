@@ -0,0 +1,389 @@
+package tests
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/tools/ldap"
+)
+
+// TestLDAPEntry defines a single [TestLDAPServer] directory entry.
+type TestLDAPEntry struct {
+	DN string
+
+	// Password is the entry bind password
+	// (leave it empty to disallow binding as the entry).
+	Password string
+
+	Attributes map[string][]string
+}
+
+// TestLDAPServer is a minimal in-process LDAP server stand-in
+// that could be used for testing the LDAP integrations.
+//
+// It supports only anonymous and simple binds and search
+// operations (extensible match filters are not supported).
+type TestLDAPServer struct {
+	// URL is the "ldap://" url of the running server.
+	URL string
+
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	entries []*TestLDAPEntry
+	binds   []string
+	conns   map[net.Conn]struct{}
+}
+
+// NewTestLDAPServer starts a new [TestLDAPServer] with the provided
+// directory entries listening on a random local port.
+//
+// Call [TestLDAPServer.Close] to stop the server.
+func NewTestLDAPServer(entries ...*TestLDAPEntry) (*TestLDAPServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &TestLDAPServer{
+		URL:      "ldap://" + listener.Addr().String(),
+		listener: listener,
+		entries:  entries,
+		conns:    map[net.Conn]struct{}{},
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			s.mu.Lock()
+			s.conns[conn] = struct{}{}
+			s.mu.Unlock()
+
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(conn)
+			}()
+		}
+	}()
+
+	return s, nil
+}
+
+// Close stops the server and closes all of its active connections.
+func (s *TestLDAPServer) Close() error {
+	err := s.listener.Close()
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	return err
+}
+
+// Binds returns the DNs of all bind requests received so far (including the failed ones).
+func (s *TestLDAPServer) Binds() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.binds)
+}
+
+func (s *TestLDAPServer) serve(conn net.Conn) {
+	defer func() {
+		conn.Close()
+
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	reader := bufio.NewReader(conn)
+
+	for {
+		message, err := ldap.ReadPacket(reader)
+		if err != nil {
+			return
+		}
+
+		if len(message.Children) < 2 {
+			return
+		}
+
+		id, err := message.Children[0].Int()
+		if err != nil {
+			return
+		}
+
+		op := message.Children[1]
+
+		var responses []*ldap.Packet
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			responses = append(responses, s.bind(op))
+		case ldap.ApplicationSearchRequest:
+			responses = s.search(op)
+		case ldap.ApplicationExtendedRequest:
+			responses = append(responses, testLDAPResult(ldap.ApplicationExtendedResponse, ldap.ResultProtocolError, "unsupported extended operation"))
+		case ldap.ApplicationUnbindRequest:
+			return
+		default:
+			return
+		}
+
+		for _, response := range responses {
+			msg := ldap.NewSequence(ldap.ClassUniversal, ldap.TagSequence,
+				ldap.NewInteger(ldap.ClassUniversal, ldap.TagInteger, id),
+				response,
+			)
+
+			if _, err := conn.Write(msg.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *TestLDAPServer) bind(op *ldap.Packet) *ldap.Packet {
+	if len(op.Children) < 3 {
+		return testLDAPResult(ldap.ApplicationBindResponse, ldap.ResultProtocolError, "invalid bind request")
+	}
+
+	dn := op.Children[1].String()
+	password := op.Children[2].String()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.binds = append(s.binds, dn)
+
+	// anonymous bind
+	if dn == "" && password == "" {
+		return testLDAPResult(ldap.ApplicationBindResponse, ldap.ResultSuccess, "")
+	}
+
+	for _, entry := range s.entries {
+		if strings.EqualFold(entry.DN, dn) && entry.Password != "" && entry.Password == password {
+			return testLDAPResult(ldap.ApplicationBindResponse, ldap.ResultSuccess, "")
+		}
+	}
+
+	return testLDAPResult(ldap.ApplicationBindResponse, ldap.ResultInvalidCredentials, "invalid credentials")
+}
+
+func (s *TestLDAPServer) search(op *ldap.Packet) []*ldap.Packet {
+	if len(op.Children) < 8 {
+		return []*ldap.Packet{
+			testLDAPResult(ldap.ApplicationSearchResultDone, ldap.ResultProtocolError, "invalid search request"),
+		}
+	}
+
+	baseDN := strings.ToLower(op.Children[0].String())
+	scope, _ := op.Children[1].Int()
+	sizeLimit, _ := op.Children[3].Int()
+	filter := op.Children[6]
+
+	var attributes []string
+	for _, attr := range op.Children[7].Children {
+		attributes = append(attributes, attr.String())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []*ldap.Packet
+
+	for _, entry := range s.entries {
+		dn := strings.ToLower(entry.DN)
+
+		switch scope {
+		case ldap.ScopeBaseObject:
+			if dn != baseDN {
+				continue
+			}
+		case ldap.ScopeSingleLevel:
+			parent := ""
+			if i := strings.IndexByte(dn, ','); i >= 0 {
+				parent = dn[i+1:]
+			}
+			if parent != baseDN {
+				continue
+			}
+		default:
+			if baseDN != "" && dn != baseDN && !strings.HasSuffix(dn, ","+baseDN) {
+				continue
+			}
+		}
+
+		ok, err := testLDAPMatch(entry, filter)
+		if err != nil {
+			return []*ldap.Packet{
+				testLDAPResult(ldap.ApplicationSearchResultDone, ldap.ResultProtocolError, err.Error()),
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		if sizeLimit > 0 && len(results) >= int(sizeLimit) {
+			return append(results, testLDAPResult(ldap.ApplicationSearchResultDone, ldap.ResultSizeLimitExceeded, ""))
+		}
+
+		results = append(results, testLDAPEntryPacket(entry, attributes))
+	}
+
+	return append(results, testLDAPResult(ldap.ApplicationSearchResultDone, ldap.ResultSuccess, ""))
+}
+
+func testLDAPResult(tag int, code int, message string) *ldap.Packet {
+	return ldap.NewSequence(ldap.ClassApplication, tag,
+		ldap.NewInteger(ldap.ClassUniversal, ldap.TagEnumerated, int64(code)),
+		ldap.NewString(ldap.ClassUniversal, ldap.TagOctetString, ""),
+		ldap.NewString(ldap.ClassUniversal, ldap.TagOctetString, message),
+	)
+}
+
+func testLDAPEntryPacket(entry *TestLDAPEntry, attributes []string) *ldap.Packet {
+	rawAttrs := ldap.NewSequence(ldap.ClassUniversal, ldap.TagSequence)
+
+	for name, values := range entry.Attributes {
+		if len(attributes) > 0 && !slices.ContainsFunc(attributes, func(a string) bool {
+			return strings.EqualFold(a, name)
+		}) {
+			continue
+		}
+
+		rawValues := ldap.NewSequence(ldap.ClassUniversal, ldap.TagSet)
+		for _, v := range values {
+			rawValues.Children = append(rawValues.Children, ldap.NewString(ldap.ClassUniversal, ldap.TagOctetString, v))
+		}
+
+		rawAttrs.Children = append(rawAttrs.Children, ldap.NewSequence(ldap.ClassUniversal, ldap.TagSequence,
+			ldap.NewString(ldap.ClassUniversal, ldap.TagOctetString, name),
+			rawValues,
+		))
+	}
+
+	return ldap.NewSequence(ldap.ClassApplication, ldap.ApplicationSearchResultEntry,
+		ldap.NewString(ldap.ClassUniversal, ldap.TagOctetString, entry.DN),
+		rawAttrs,
+	)
+}
+
+// testLDAPMatch reports whether the entry matches the BER encoded search filter.
+//
+// Note that all values are compared case-insensitively.
+func testLDAPMatch(entry *TestLDAPEntry, filter *ldap.Packet) (bool, error) {
+	values := func(name string) []string {
+		for k, v := range entry.Attributes {
+			if strings.EqualFold(k, name) {
+				return v
+			}
+		}
+		return nil
+	}
+
+	switch filter.Tag {
+	case ldap.FilterAnd, ldap.FilterOr:
+		for _, child := range filter.Children {
+			ok, err := testLDAPMatch(entry, child)
+			if err != nil {
+				return false, err
+			}
+			if filter.Tag == ldap.FilterOr && ok {
+				return true, nil
+			}
+			if filter.Tag == ldap.FilterAnd && !ok {
+				return false, nil
+			}
+		}
+		return filter.Tag == ldap.FilterAnd, nil
+	case ldap.FilterNot:
+		if len(filter.Children) != 1 {
+			return false, errors.New("invalid not filter")
+		}
+		ok, err := testLDAPMatch(entry, filter.Children[0])
+		return !ok, err
+	case ldap.FilterPresent:
+		return len(values(filter.String())) > 0, nil
+	case ldap.FilterEqualityMatch, ldap.FilterApproxMatch, ldap.FilterGreaterOrEqual, ldap.FilterLessOrEqual:
+		if len(filter.Children) != 2 {
+			return false, errors.New("invalid attribute value assertion")
+		}
+		expected := strings.ToLower(filter.Children[1].String())
+		for _, v := range values(filter.Children[0].String()) {
+			v = strings.ToLower(v)
+			switch filter.Tag {
+			case ldap.FilterGreaterOrEqual:
+				if v >= expected {
+					return true, nil
+				}
+			case ldap.FilterLessOrEqual:
+				if v <= expected {
+					return true, nil
+				}
+			default:
+				if v == expected {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	case ldap.FilterSubstrings:
+		if len(filter.Children) != 2 {
+			return false, errors.New("invalid substrings filter")
+		}
+		for _, v := range values(filter.Children[0].String()) {
+			if testLDAPMatchSubstrings(strings.ToLower(v), filter.Children[1].Children) {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		return false, errors.New("unsupported filter")
+	}
+}
+
+func testLDAPMatchSubstrings(value string, parts []*ldap.Packet) bool {
+	for _, part := range parts {
+		s := strings.ToLower(part.String())
+
+		switch part.Tag {
+		case ldap.FilterSubstringsInitial:
+			if !strings.HasPrefix(value, s) {
+				return false
+			}
+			value = value[len(s):]
+		case ldap.FilterSubstringsFinal:
+			if !strings.HasSuffix(value, s) {
+				return false
+			}
+			value = value[:len(value)-len(s)]
+		default:
+			i := strings.Index(value, s)
+			if i < 0 {
+				return false
+			}
+			value = value[i+len(s):]
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/saml"
+)
+
+// TestSAMLIdP is a minimal SAML 2.0 identity provider stand-in
+// that could be used for generating signed SAML responses in tests.
+type TestSAMLIdP struct {
+	// EntityId is the IdP entity id (the responses and assertions issuer).
+	EntityId string
+
+	// SSOURL is the IdP single sign-on url.
+	SSOURL string
+
+	// CertificatePEM is the PEM encoded IdP signing certificate.
+	CertificatePEM string
+
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+// TestSAMLResponse defines the [TestSAMLIdP.NewResponse] options.
+type TestSAMLResponse struct {
+	// Destination is the SP ACS url (used also as subject confirmation recipient).
+	Destination string
+
+	// Audience is the SP entity id.
+	Audience string
+
+	// InResponseTo is the related authentication request id
+	// (leave it empty for IdP-initiated responses).
+	InResponseTo string
+
+	NameId       string
+	NameIdFormat string
+
+	// AssertionId is the optional assertion ID (auto generated if empty).
+	AssertionId string
+
+	// Status is the optional response status code (default to success).
+	Status string
+
+	// Attributes are the assertion attributes in the format name:values.
+	Attributes map[string][]string
+
+	// IssueInstant is the optional assertion issue time (default to now).
+	IssueInstant time.Time
+
+	SignResponse  bool
+	SignAssertion bool
+}
+
+// NewTestSAMLIdP creates a new [TestSAMLIdP] with a random RSA signing key
+// and a self-signed certificate.
+func NewTestSAMLIdP(entityId string, ssoURL string) (*TestSAMLIdP, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test-idp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TestSAMLIdP{
+		EntityId:       entityId,
+		SSOURL:         ssoURL,
+		CertificatePEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		key:            key,
+		cert:           cert,
+	}, nil
+}
+
+// NewResponse generates a new base64 encoded SAML response
+// (as it would be posted by the IdP to the SP ACS url).
+func (idp *TestSAMLIdP) NewResponse(options TestSAMLResponse) (string, error) {
+	issueInstant := options.IssueInstant
+	if issueInstant.IsZero() {
+		issueInstant = time.Now()
+	}
+	issueInstant = issueInstant.UTC()
+
+	status := options.Status
+	if status == "" {
+		status = saml.StatusSuccess
+	}
+
+	assertionId := options.AssertionId
+	if assertionId == "" {
+		assertionId = saml.NewRequestId()
+	}
+
+	response := saml.NewElement("samlp", saml.NamespaceProtocol, "Response")
+	response.Namespaces["saml"] = saml.NamespaceAssertion
+	response.SetAttr("ID", saml.NewRequestId())
+	response.SetAttr("Version", "2.0")
+	response.SetAttr("IssueInstant", issueInstant.Format(time.RFC3339))
+	response.SetAttr("Destination", options.Destination)
+	if options.InResponseTo != "" {
+		response.SetAttr("InResponseTo", options.InResponseTo)
+	}
+
+	addAssertionElement(response, "Issuer").AddChild(saml.CharData(idp.EntityId))
+
+	statusEl := &saml.Element{Prefix: "samlp", Space: saml.NamespaceProtocol, Local: "Status"}
+	response.AddChild(statusEl)
+	statusCode := &saml.Element{Prefix: "samlp", Space: saml.NamespaceProtocol, Local: "StatusCode"}
+	statusCode.SetAttr("Value", status)
+	statusEl.AddChild(statusCode)
+
+	if status == saml.StatusSuccess {
+		assertion := addAssertionElement(response, "Assertion")
+		assertion.SetAttr("ID", assertionId)
+		assertion.SetAttr("Version", "2.0")
+		assertion.SetAttr("IssueInstant", issueInstant.Format(time.RFC3339))
+
+		addAssertionElement(assertion, "Issuer").AddChild(saml.CharData(idp.EntityId))
+
+		subject := addAssertionElement(assertion, "Subject")
+		nameId := addAssertionElement(subject, "NameID")
+		if options.NameIdFormat != "" {
+			nameId.SetAttr("Format", options.NameIdFormat)
+		}
+		nameId.AddChild(saml.CharData(options.NameId))
+
+		confirmation := addAssertionElement(subject, "SubjectConfirmation")
+		confirmation.SetAttr("Method", "urn:oasis:names:tc:SAML:2.0:cm:bearer")
+		confirmationData := addAssertionElement(confirmation, "SubjectConfirmationData")
+		if options.InResponseTo != "" {
+			confirmationData.SetAttr("InResponseTo", options.InResponseTo)
+		}
+		confirmationData.SetAttr("NotOnOrAfter", issueInstant.Add(5*time.Minute).Format(time.RFC3339))
+		confirmationData.SetAttr("Recipient", options.Destination)
+
+		conditions := addAssertionElement(assertion, "Conditions")
+		conditions.SetAttr("NotBefore", issueInstant.Add(-time.Minute).Format(time.RFC3339))
+		conditions.SetAttr("NotOnOrAfter", issueInstant.Add(5*time.Minute).Format(time.RFC3339))
+		audienceRestriction := addAssertionElement(conditions, "AudienceRestriction")
+		addAssertionElement(audienceRestriction, "Audience").AddChild(saml.CharData(options.Audience))
+
+		authn := addAssertionElement(assertion, "AuthnStatement")
+		authn.SetAttr("AuthnInstant", issueInstant.Format(time.RFC3339))
+		authn.SetAttr("SessionIndex", "_session_"+assertionId)
+
+		if len(options.Attributes) > 0 {
+			statement := addAssertionElement(assertion, "AttributeStatement")
+			for name, values := range options.Attributes {
+				attr := addAssertionElement(statement, "Attribute")
+				attr.SetAttr("Name", name)
+				for _, v := range values {
+					addAssertionElement(attr, "AttributeValue").AddChild(saml.CharData(v))
+				}
+			}
+		}
+
+		if options.SignAssertion {
+			if err := saml.Sign(assertion, idp.key, idp.cert); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if options.SignResponse {
+		if err := saml.Sign(response, idp.key, idp.cert); err != nil {
+			return "", err
+		}
+	}
+
+	return base64.StdEncoding.EncodeToString(response.Bytes()), nil
+}
+
+func addAssertionElement(parent *saml.Element, local string) *saml.Element {
+	el := &saml.Element{Prefix: "saml", Space: saml.NamespaceAssertion, Local: local}
+	parent.AddChild(el)
+	return el
+}
@@ -67,6 +67,20 @@ func TestParseIndex(t *testing.T) {
 				Where: "test = 1",
 			},
 		},
+		// parenthesized columns and where expressions
+		{
+			`CREATE UNIQUE INDEX idx ON tablename (lower(col1), col2) WHERE (col1 != '' OR col2 != '') AND deleted = '';`,
+			dbutils.Index{
+				Unique:    true,
+				IndexName: "idx",
+				TableName: "tablename",
+				Columns: []dbutils.IndexColumn{
+					{Name: "lower(col1)"},
+					{Name: "col2"},
+				},
+				Where: "(col1 != '' OR col2 != '') AND deleted = ''",
+			},
+		},
 	}
 
 	for i, s := range scenarios {
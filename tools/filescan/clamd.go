@@ -0,0 +1,213 @@
+package filescan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+var _ Scanner = (*Clamd)(nil)
+
+// DefaultClamdChunkSize is the default size of the INSTREAM data chunks.
+const DefaultClamdChunkSize = 64 << 10
+
+// DefaultClamdTimeout is the default clamd scan timeout.
+const DefaultClamdTimeout = 60 * time.Second
+
+// ErrSizeLimitExceeded is returned when the scanned content exceeds
+// the clamd StreamMaxLength limit.
+var ErrSizeLimitExceeded = errors.New("clamd INSTREAM size limit exceeded")
+
+// Clamd defines a [Scanner] client for the ClamAV daemon (or any other
+// clamd compatible server) using the INSTREAM protocol command.
+type Clamd struct {
+	// Network is the clamd socket network type - "tcp" or "unix".
+	//
+	// If empty, fallbacks to "tcp".
+	Network string
+
+	// Address is the clamd socket address
+	// (e.g. "127.0.0.1:3310" or "/var/run/clamav/clamd.ctl").
+	Address string
+
+	// Timeout is the max duration of a single scan (including the dial).
+	//
+	// If zero, fallbacks to [DefaultClamdTimeout].
+	Timeout time.Duration
+
+	// ChunkSize is the max size of a single INSTREAM data chunk.
+	//
+	// If zero, fallbacks to [DefaultClamdChunkSize].
+	ChunkSize int
+}
+
+// Scan implements [Scanner.Scan] by streaming the content of r to clamd
+// with the INSTREAM command.
+func (c *Clamd) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("failed to send the INSTREAM command: %w", err)
+	}
+
+	chunkSize := c.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultClamdChunkSize
+	}
+
+	buf := make([]byte, 4+chunkSize)
+
+	for {
+		n, readErr := io.ReadFull(r, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[0:4], uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				// clamd could close the connection earlier
+				// (e.g. on exceeded stream size limit)
+				resp, respErr := readClamdResponse(conn)
+				if respErr == nil {
+					return parseClamdResponse(resp)
+				}
+
+				return nil, fmt.Errorf("failed to send data chunk: %w", err)
+			}
+		}
+
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+
+	// zero length chunk to mark the end of the stream
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, fmt.Errorf("failed to send the stream terminator: %w", err)
+	}
+
+	resp, err := readClamdResponse(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseClamdResponse(resp)
+}
+
+// Ping checks whether the clamd server is reachable and responsive.
+func (c *Clamd) Ping(ctx context.Context) error {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("zPING\x00")); err != nil {
+		return err
+	}
+
+	resp, err := readClamdResponse(conn)
+	if err != nil {
+		return err
+	}
+
+	if resp != "PONG" {
+		return fmt.Errorf("unexpected clamd PING response %q", resp)
+	}
+
+	return nil
+}
+
+func (c *Clamd) dial(ctx context.Context) (net.Conn, error) {
+	network := c.Network
+	if network == "" {
+		network = "tcp"
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultClamdTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, network, c.Address)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	// abort any pending read/write on context cancellation
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+
+	return &clamdConn{Conn: conn, cancel: func() {
+		stop()
+		cancel()
+	}}, nil
+}
+
+// -------------------------------------------------------------------
+
+type clamdConn struct {
+	net.Conn
+	cancel func()
+}
+
+func (c *clamdConn) Close() error {
+	c.cancel()
+	return c.Conn.Close()
+}
+
+// readClamdResponse reads a single null (or new line) terminated clamd response.
+func readClamdResponse(conn net.Conn) (string, error) {
+	resp, err := bufio.NewReader(io.LimitReader(conn, 4096)).ReadBytes(0)
+	if err != nil && (err != io.EOF || len(resp) == 0) {
+		return "", fmt.Errorf("failed to read clamd response: %w", err)
+	}
+
+	return string(bytes.TrimSpace(bytes.TrimRight(resp, "\x00"))), nil
+}
+
+// parseClamdResponse parses a clamd INSTREAM response, e.g.:
+//
+//	stream: OK
+//	stream: Eicar-Test-Signature FOUND
+//	INSTREAM size limit exceeded. ERROR
+func parseClamdResponse(resp string) (*Result, error) {
+	status := strings.TrimSpace(strings.TrimPrefix(resp, "stream:"))
+
+	switch {
+	case status == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(status, " FOUND"):
+		return &Result{
+			Infected:  true,
+			Signature: strings.TrimSpace(strings.TrimSuffix(status, " FOUND")),
+		}, nil
+	case strings.HasPrefix(status, "INSTREAM size limit exceeded"):
+		return nil, ErrSizeLimitExceeded
+	case strings.HasSuffix(status, " ERROR"):
+		return nil, fmt.Errorf("clamd error: %s", strings.TrimSpace(strings.TrimSuffix(status, " ERROR")))
+	}
+
+	return nil, errors.New("unexpected clamd response: " + resp)
+}
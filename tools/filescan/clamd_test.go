@@ -0,0 +1,236 @@
+package filescan_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/filescan"
+)
+
+func TestClamdScan(t *testing.T) {
+	t.Parallel()
+
+	tcpAddr := startFakeClamd(t, "tcp", "127.0.0.1:0", 100)
+	unixAddr := startFakeClamd(t, "unix", filepath.Join(t.TempDir(), "clamd.sock"), 100)
+
+	scenarios := []struct {
+		name        string
+		client      *filescan.Clamd
+		content     string
+		expectError bool
+		expected    filescan.Result
+	}{
+		{
+			"unreachable server",
+			&filescan.Clamd{Address: "127.0.0.1:1", Timeout: time.Second},
+			"test",
+			true,
+			filescan.Result{},
+		},
+		{
+			"tcp clean",
+			&filescan.Clamd{Network: "tcp", Address: tcpAddr, ChunkSize: 3},
+			"clean content",
+			false,
+			filescan.Result{},
+		},
+		{
+			"tcp empty",
+			&filescan.Clamd{Network: "tcp", Address: tcpAddr},
+			"",
+			false,
+			filescan.Result{},
+		},
+		{
+			"tcp infected (split across chunks)",
+			&filescan.Clamd{Address: tcpAddr, ChunkSize: 2},
+			"abc EICAR def",
+			false,
+			filescan.Result{Infected: true, Signature: "Eicar-Test-Signature"},
+		},
+		{
+			"tcp size limit",
+			&filescan.Clamd{Address: tcpAddr, ChunkSize: 10},
+			strings.Repeat("a", 200),
+			true,
+			filescan.Result{},
+		},
+		{
+			"unix clean",
+			&filescan.Clamd{Network: "unix", Address: unixAddr},
+			"clean content",
+			false,
+			filescan.Result{},
+		},
+		{
+			"unix infected",
+			&filescan.Clamd{Network: "unix", Address: unixAddr},
+			"EICAR",
+			false,
+			filescan.Result{Infected: true, Signature: "Eicar-Test-Signature"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := s.client.Scan(context.Background(), strings.NewReader(s.content))
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if *result != s.expected {
+				t.Fatalf("Expected result %#v, got %#v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestClamdScanSizeLimit(t *testing.T) {
+	t.Parallel()
+
+	addr := startFakeClamd(t, "tcp", "127.0.0.1:0", 100)
+
+	client := &filescan.Clamd{Address: addr, ChunkSize: 10}
+
+	_, err := client.Scan(context.Background(), strings.NewReader(strings.Repeat("a", 200)))
+	if !errors.Is(err, filescan.ErrSizeLimitExceeded) {
+		t.Fatalf("Expected ErrSizeLimitExceeded, got %v", err)
+	}
+}
+
+func TestClamdScanContextCancel(t *testing.T) {
+	t.Parallel()
+
+	// server that never responds
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(io.Discard, conn)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	client := &filescan.Clamd{Address: ln.Addr().String()}
+
+	start := time.Now()
+
+	_, err = client.Scan(ctx, strings.NewReader("test"))
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Expected the scan to be aborted on context cancel, took %v", elapsed)
+	}
+}
+
+func TestClamdPing(t *testing.T) {
+	t.Parallel()
+
+	addr := startFakeClamd(t, "tcp", "127.0.0.1:0", 100)
+
+	if err := (&filescan.Clamd{Address: addr}).Ping(context.Background()); err != nil {
+		t.Fatalf("Expected ping to succeed, got %v", err)
+	}
+
+	if err := (&filescan.Clamd{Address: "127.0.0.1:1", Timeout: time.Second}).Ping(context.Background()); err == nil {
+		t.Fatal("Expected ping to fail")
+	}
+}
+
+// -------------------------------------------------------------------
+
+// startFakeClamd starts a minimal clamd compatible server that reports
+// as infected every stream containing "EICAR".
+//
+// Returns the server listener address.
+func startFakeClamd(t *testing.T, network, address string, maxStreamSize int) string {
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				r := bufio.NewReader(conn)
+
+				cmd, err := r.ReadString(0)
+				if err != nil {
+					return
+				}
+
+				switch cmd {
+				case "zPING\x00":
+					conn.Write([]byte("PONG\x00"))
+				case "zINSTREAM\x00":
+					var data bytes.Buffer
+					size := make([]byte, 4)
+					for {
+						if _, err := io.ReadFull(r, size); err != nil {
+							return
+						}
+
+						n := binary.BigEndian.Uint32(size)
+						if n == 0 {
+							break
+						}
+
+						if _, err := io.CopyN(&data, r, int64(n)); err != nil {
+							return
+						}
+
+						if data.Len() > maxStreamSize {
+							conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+							return
+						}
+					}
+
+					if bytes.Contains(data.Bytes(), []byte("EICAR")) {
+						conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+					} else {
+						conn.Write([]byte("stream: OK\x00"))
+					}
+				default:
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+				}
+			}()
+		}
+	}()
+
+	return ln.Addr().String()
+}
@@ -0,0 +1,27 @@
+// Package filescan defines a common interface for scanning file
+// contents (e.g. antivirus) and a built-in clamd client implementation.
+package filescan
+
+import (
+	"context"
+	"io"
+)
+
+// Scanner defines a base file content scanner interface.
+type Scanner interface {
+	// Scan scans the content of the provided reader.
+	//
+	// A non-nil error is returned only if the scan couldn't be completed
+	// (e.g. connection failure). Detected threats are reported
+	// with the [Result.Infected] flag.
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+// Result defines a single file scan result.
+type Result struct {
+	// Signature is the name of the detected threat (if any).
+	Signature string `json:"signature"`
+
+	// Infected indicates whether a threat was detected.
+	Infected bool `json:"infected"`
+}
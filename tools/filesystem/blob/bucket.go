@@ -22,6 +22,7 @@ import (
 	"io"
 	"log"
 	"mime"
+	"net/http"
 	"runtime"
 	"strings"
 	"sync"
@@ -693,6 +694,83 @@ func (b *Bucket) Delete(ctx context.Context, key string) (err error) {
 	return wrapError(b.drv, b.drv.Delete(ctx, key), key)
 }
 
+// SignedURLOptions sets options for SignedURL.
+type SignedURLOptions struct {
+	// Expiry sets how long the returned URL is valid for.
+	//
+	// Defaults to DefaultSignedURLExpiry.
+	Expiry time.Duration
+
+	// Method is the HTTP method that can be used on the URL; one of "GET" or "PUT".
+	//
+	// Defaults to "GET".
+	Method string
+
+	// ContentType specifies the Content-Type HTTP header the user agent
+	// is permitted to use in the PUT request.
+	//
+	// For GET requests it specifies the Content-Type response header override.
+	ContentType string
+
+	// ContentDisposition specifies the Content-Disposition response header
+	// override of the GET request (ignored for PUT).
+	ContentDisposition string
+
+	// ContentLength specifies the exact Content-Length HTTP header
+	// (aka. body size) the user agent must use in the PUT request.
+	//
+	// It is ignored for GET requests and zero means no restriction.
+	ContentLength int64
+}
+
+// DefaultSignedURLExpiry is the default duration for SignedURLOptions.Expiry.
+const DefaultSignedURLExpiry = 1 * time.Hour
+
+// SignedURL returns a URL that can be used to GET (default) or PUT the blob
+// for the duration specified in opts.Expiry.
+//
+// A nil SignedURLOptions is treated the same as the zero value.
+//
+// If the driver doesn't support this functionality, SignedURL
+// will return an error wrapping [errors.ErrUnsupported].
+func (b *Bucket) SignedURL(ctx context.Context, key string, opts *SignedURLOptions) (string, error) {
+	if !utf8.ValidString(key) {
+		return "", fmt.Errorf("SignedURL key must be a valid UTF-8 string: %q", key)
+	}
+
+	dopts := new(SignedURLOptions)
+	if opts != nil {
+		*dopts = *opts
+	}
+
+	if dopts.Expiry < 0 {
+		return "", fmt.Errorf("SignedURLOptions.Expiry must be >= 0 (%v)", dopts.Expiry)
+	}
+	if dopts.Expiry == 0 {
+		dopts.Expiry = DefaultSignedURLExpiry
+	}
+
+	switch dopts.Method {
+	case "":
+		dopts.Method = http.MethodGet
+	case http.MethodGet, http.MethodPut:
+		// valid
+	default:
+		return "", fmt.Errorf("unsupported SignedURLOptions.Method %q", dopts.Method)
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return "", ErrClosed
+	}
+
+	url, err := b.drv.SignedURL(ctx, key, dopts)
+
+	return url, wrapError(b.drv, err, key)
+}
+
 // Close releases any resources used for the bucket.
 //
 // @todo Consider removing it.
@@ -89,6 +89,14 @@ type Driver interface {
 	// not exist, Delete must return an ErrNotFound.
 	Delete(ctx context.Context, key string) error
 
+	// SignedURL returns a URL that can be used to GET (default) or PUT
+	// the blob for the duration specified in opts.Expiry.
+	//
+	// opts is guaranteed to be non-nil.
+	//
+	// If not supported, SignedURL must return an error wrapping [errors.ErrUnsupported].
+	SignedURL(ctx context.Context, key string, opts *SignedURLOptions) (string, error)
+
 	// Close cleans up any resources used by the Bucket. Once Close is called,
 	// there will be no method calls to the Bucket other than As, ErrorAs, and
 	// ErrorCode. There may be open readers or writers that will receive calls.
@@ -23,6 +23,18 @@ type FileReader interface {
 	Open() (io.ReadSeekCloser, error)
 }
 
+// StoredFileReader defines a [FileReader] for a file that is already
+// stored in the same filesystem under a single key.
+//
+// It allows [System.UploadFile] to copy the file within the storage
+// (e.g. with S3 server-side copy) instead of streaming its content through the app.
+type StoredFileReader interface {
+	FileReader
+
+	// StoredKey returns the storage key of the file (or empty string if not applicable).
+	StoredKey() string
+}
+
 // File defines a single file [io.ReadSeekCloser] resource.
 //
 // The file could be from a local path, multipart/form-data header, etc.
@@ -77,6 +89,24 @@ func NewFileFromBytes(b []byte, name string) (*File, error) {
 	return f, nil
 }
 
+// NewFileFromReader creates a new File instance from the provided custom FileReader.
+//
+// size is the total size of the reader content and name is the original file name.
+func NewFileFromReader(reader FileReader, size int64, name string) (*File, error) {
+	if size <= 0 {
+		return nil, errors.New("cannot create an empty file")
+	}
+
+	f := &File{}
+
+	f.Reader = reader
+	f.Size = size
+	f.OriginalName = name
+	f.Name = normalizeName(f.Reader, f.OriginalName)
+
+	return f, nil
+}
+
 // NewFileFromMultipart creates a new File from the provided multipart header.
 func NewFileFromMultipart(mh *multipart.FileHeader) (*File, error) {
 	f := &File{}
@@ -14,6 +14,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/disintegration/imaging"
 	"github.com/fatih/color"
@@ -121,6 +122,28 @@ func (s *System) GetReader(fileKey string) (*blob.Reader, error) {
 	return s.bucket.NewReader(s.ctx, fileKey)
 }
 
+// GetMultiReader returns a single seekable reader that concatenates
+// the content of the specified fileKeys in the provided order
+// (e.g. to read the staged chunks of a resumable upload as one file).
+//
+// NB! Make sure to call Close() on the reader after you are done working with it.
+//
+// If any of the files doesn't exist returns ErrNotFound.
+func (s *System) GetMultiReader(fileKeys ...string) (io.ReadSeekCloser, error) {
+	r := &multiReader{fsys: s, keys: fileKeys, sizes: make([]int64, len(fileKeys))}
+
+	for i, key := range fileKeys {
+		attrs, err := s.Attributes(key)
+		if err != nil {
+			return nil, err
+		}
+		r.sizes[i] = attrs.Size
+		r.total += attrs.Size
+	}
+
+	return r, nil
+}
+
 // Deprecated: Please use GetReader(fileKey) instead.
 func (s *System) GetFile(fileKey string) (*blob.Reader, error) {
 	color.Yellow("Deprecated: Please replace GetFile with GetReader.")
@@ -228,6 +251,16 @@ func (s *System) UploadFile(file *File, fileKey string) error {
 		return err
 	}
 
+	// copy directly the already stored file if its detected content type matches
+	// (the content type of the stored files could be client provided, e.g. from presigned uploads)
+	if sr, ok := file.Reader.(StoredFileReader); ok {
+		if key := sr.StoredKey(); key != "" {
+			if attrs, err := s.Attributes(key); err == nil && attrs.ContentType == mt.String() {
+				return s.Copy(key, fileKey)
+			}
+		}
+	}
+
 	// rewind
 	f.Seek(0, io.SeekStart)
 
@@ -446,13 +479,65 @@ func (s *System) Serve(res http.ResponseWriter, req *http.Request, fileKey strin
 	}
 	defer br.Close()
 
+	disposition, extContentType := serveDispositionAndContentType(req, fileKey, br.ContentType())
+
+	setHeaderIfMissing(res, "Content-Disposition", disposition+"; filename="+name)
+	setHeaderIfMissing(res, "Content-Type", extContentType)
+	setHeaderIfMissing(res, "Content-Security-Policy", "default-src 'none'; media-src 'self'; style-src 'unsafe-inline'; sandbox")
+
+	// set a default cache-control header
+	// (valid for 30 days but the cache is allowed to reuse the file for any requests
+	// that are made in the last day while revalidating the res in the background)
+	setHeaderIfMissing(res, "Cache-Control", "max-age=2592000, stale-while-revalidate=86400")
+
+	http.ServeContent(res, req, name, br.ModTime(), br)
+
+	return nil
+}
+
+// SignedURL returns a new presigned URL that allows direct GET (default)
+// or PUT access to the file at fileKey location without streaming
+// its content through the app.
+//
+// Returns an error wrapping [errors.ErrUnsupported] if the storage
+// doesn't support presigned URLs (e.g. the local filesystem).
+func (s *System) SignedURL(fileKey string, opts *blob.SignedURLOptions) (string, error) {
+	return s.bucket.SignedURL(s.ctx, fileKey, opts)
+}
+
+// SignedServeURL returns a presigned GET URL for the file at fileKey location
+// that could be used as a redirect alternative to [System.Serve].
+//
+// The response Content-Disposition and Content-Type headers of the
+// presigned URL are resolved the same way as in [System.Serve].
+//
+// Returns an error wrapping [errors.ErrUnsupported] if the storage
+// doesn't support presigned URLs (e.g. the local filesystem).
+func (s *System) SignedServeURL(req *http.Request, fileKey string, name string, expiry time.Duration) (string, error) {
+	attrs, err := s.Attributes(fileKey)
+	if err != nil {
+		return "", err
+	}
+
+	disposition, contentType := serveDispositionAndContentType(req, fileKey, attrs.ContentType)
+
+	return s.SignedURL(fileKey, &blob.SignedURLOptions{
+		Method:             http.MethodGet,
+		Expiry:             expiry,
+		ContentType:        contentType,
+		ContentDisposition: disposition + "; filename=" + name,
+	})
+}
+
+// serveDispositionAndContentType returns the Content-Disposition type
+// and the Content-Type header value to serve the fileKey file with.
+func serveDispositionAndContentType(req *http.Request, fileKey string, realContentType string) (string, string) {
 	var forceAttachment bool
 	if raw := req.URL.Query().Get(forceAttachmentParam); raw != "" {
 		forceAttachment, _ = strconv.ParseBool(raw)
 	}
 
 	disposition := "attachment"
-	realContentType := br.ContentType()
 	if !forceAttachment && list.ExistInSlice(realContentType, inlineServeContentTypes) {
 		disposition = "inline"
 	}
@@ -464,18 +549,7 @@ func (s *System) Serve(res http.ResponseWriter, req *http.Request, fileKey strin
 		extContentType = ct
 	}
 
-	setHeaderIfMissing(res, "Content-Disposition", disposition+"; filename="+name)
-	setHeaderIfMissing(res, "Content-Type", extContentType)
-	setHeaderIfMissing(res, "Content-Security-Policy", "default-src 'none'; media-src 'self'; style-src 'unsafe-inline'; sandbox")
-
-	// set a default cache-control header
-	// (valid for 30 days but the cache is allowed to reuse the file for any requests
-	// that are made in the last day while revalidating the res in the background)
-	setHeaderIfMissing(res, "Cache-Control", "max-age=2592000, stale-while-revalidate=86400")
-
-	http.ServeContent(res, req, name, br.ModTime(), br)
-
-	return nil
+	return disposition, extContentType
 }
 
 // note: expects key to be in a canonical form (eg. "accept-encoding" should be "Accept-Encoding").
@@ -506,17 +580,9 @@ func (s *System) CreateThumb(originalKey, thumbKey, thumbSize string) error {
 }
 
 func (s *System) createThumb(originalKey, thumbKey, thumbSize string) error {
-	sizeParts := ThumbSizeRegex.FindStringSubmatch(thumbSize)
-	if len(sizeParts) != 4 {
-		return errors.New("thumb size must be in WxH, WxHt, WxHb or WxHf format")
-	}
-
-	width, _ := strconv.Atoi(sizeParts[1])
-	height, _ := strconv.Atoi(sizeParts[2])
-	resizeType := sizeParts[3]
-
-	if width == 0 && height == 0 {
-		return errors.New("thumb width and height cannot be zero at the same time")
+	width, height, resizeType, err := parseThumbSize(thumbSize)
+	if err != nil {
+		return err
 	}
 
 	// fetch the original
@@ -533,27 +599,7 @@ func (s *System) createThumb(originalKey, thumbKey, thumbSize string) error {
 		return decodeErr
 	}
 
-	var thumbImg *image.NRGBA
-
-	if width == 0 || height == 0 {
-		// force resize preserving aspect ratio
-		thumbImg = imaging.Resize(img, width, height, imaging.Linear)
-	} else {
-		switch resizeType {
-		case "f":
-			// fit
-			thumbImg = imaging.Fit(img, width, height, imaging.Linear)
-		case "t":
-			// fill and crop from top
-			thumbImg = imaging.Fill(img, width, height, imaging.Top, imaging.Linear)
-		case "b":
-			// fill and crop from bottom
-			thumbImg = imaging.Fill(img, width, height, imaging.Bottom, imaging.Linear)
-		default:
-			// fill and crop from center
-			thumbImg = imaging.Fill(img, width, height, imaging.Center, imaging.Linear)
-		}
-	}
+	thumbImg := resizeImage(img, width, height, resizeType)
 
 	originalContentType := r.ContentType()
 
@@ -594,3 +640,156 @@ func (s *System) createThumb(originalKey, thumbKey, thumbSize string) error {
 	// check for close errors to ensure that the thumb was really saved
 	return w.Close()
 }
+
+// parseThumbSize extracts the width, height and resize type from
+// a thumb size string in one of the [ThumbSizeRegex] formats.
+func parseThumbSize(thumbSize string) (int, int, string, error) {
+	sizeParts := ThumbSizeRegex.FindStringSubmatch(thumbSize)
+	if len(sizeParts) != 4 {
+		return 0, 0, "", errors.New("thumb size must be in WxH, WxHt, WxHb or WxHf format")
+	}
+
+	width, _ := strconv.Atoi(sizeParts[1])
+	height, _ := strconv.Atoi(sizeParts[2])
+
+	if width == 0 && height == 0 {
+		return 0, 0, "", errors.New("thumb width and height cannot be zero at the same time")
+	}
+
+	return width, height, sizeParts[3], nil
+}
+
+// resizeImage resizes img according to the parsed thumb size parts.
+func resizeImage(img image.Image, width, height int, resizeType string) *image.NRGBA {
+	if width == 0 || height == 0 {
+		// force resize preserving aspect ratio
+		return imaging.Resize(img, width, height, imaging.Linear)
+	}
+
+	switch resizeType {
+	case "f":
+		// fit
+		return imaging.Fit(img, width, height, imaging.Linear)
+	case "t":
+		// fill and crop from top
+		return imaging.Fill(img, width, height, imaging.Top, imaging.Linear)
+	case "b":
+		// fill and crop from bottom
+		return imaging.Fill(img, width, height, imaging.Bottom, imaging.Linear)
+	default:
+		// fill and crop from center
+		return imaging.Fill(img, width, height, imaging.Center, imaging.Linear)
+	}
+}
+
+// -------------------------------------------------------------------
+
+var _ io.ReadSeekCloser = (*multiReader)(nil)
+
+// multiReader concatenates multiple blobs into a single io.ReadSeekCloser.
+//
+// The underlying blob readers are opened lazily one at a time.
+type multiReader struct {
+	fsys   *System
+	cur    io.ReadCloser
+	keys   []string
+	sizes  []int64
+	total  int64
+	pos    int64
+	curEnd int64
+}
+
+// Read implements the [io.Reader] interface.
+func (r *multiReader) Read(p []byte) (int, error) {
+	if r.pos >= r.total {
+		return 0, io.EOF
+	}
+
+	if r.cur == nil {
+		var start int64
+		for i, size := range r.sizes {
+			if r.pos < start+size {
+				reader, err := r.fsys.GetReader(r.keys[i])
+				if err != nil {
+					return 0, err
+				}
+
+				if offset := r.pos - start; offset > 0 {
+					if _, err := reader.Seek(offset, io.SeekStart); err != nil {
+						reader.Close()
+						return 0, err
+					}
+				}
+
+				r.cur = reader
+				r.curEnd = start + size
+				break
+			}
+			start += size
+		}
+	}
+
+	n, err := r.cur.Read(p)
+	r.pos += int64(n)
+
+	if errors.Is(err, io.EOF) {
+		closeErr := r.cur.Close()
+		r.cur = nil
+
+		if closeErr != nil {
+			return n, closeErr
+		}
+
+		// the blob was modified after the reader initialization
+		if r.pos != r.curEnd {
+			return n, io.ErrUnexpectedEOF
+		}
+
+		err = nil
+	}
+
+	return n, err
+}
+
+// Seek implements the [io.Seeker] interface.
+func (r *multiReader) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = r.pos + offset
+	case io.SeekEnd:
+		pos = r.total + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if pos < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if pos != r.pos && r.cur != nil {
+		if err := r.cur.Close(); err != nil {
+			return 0, err
+		}
+		r.cur = nil
+	}
+
+	r.pos = pos
+
+	return pos, nil
+}
+
+// Close implements the [io.Closer] interface.
+func (r *multiReader) Close() error {
+	if r.cur == nil {
+		return nil
+	}
+
+	err := r.cur.Close()
+	r.cur = nil
+
+	return err
+}
@@ -3,6 +3,7 @@ package filesystem_test
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"image"
 	"image/jpeg"
 	"image/png"
@@ -14,9 +15,11 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/filesystem/blob"
 )
 
 func TestFileSystemExists(t *testing.T) {
@@ -305,6 +308,109 @@ func TestFileSystemUploadFile(t *testing.T) {
 	}
 }
 
+func TestFileSystemUploadFileStoredReader(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fsys, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	if err := fsys.Upload([]byte("<html></html>"), "stored_html"); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		storedKey   string
+		content     string
+		expectCopy  bool
+		expectError bool
+	}{
+		{"empty stored key", "", "demo", false, false},
+		{"missing stored key", "missing", "demo", false, false},
+		{"stored key with different content type", "test/sub1.txt", "<html></html>", false, false},
+		{"stored key with the same content type", "stored_html", "<html></html>", true, false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			file, err := filesystem.NewFileFromReader(
+				&storedFileReader{BytesReader: filesystem.BytesReader{Bytes: []byte(s.content)}, key: s.storedKey},
+				int64(len(s.content)),
+				"test.txt",
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fileKey := fmt.Sprintf("stored_upload_%d", i)
+
+			err = fsys.UploadFile(file, fileKey)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			attrs, err := fsys.Attributes(fileKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the copied files have the attributes of the stored file
+			_, hasOriginalName := attrs.Metadata["original-filename"]
+			if hasOriginalName == s.expectCopy {
+				t.Fatalf("Expected copy %v, got metadata %v", s.expectCopy, attrs.Metadata)
+			}
+		})
+	}
+}
+
+type storedFileReader struct {
+	filesystem.BytesReader
+	key string
+}
+
+func (r *storedFileReader) StoredKey() string {
+	return r.key
+}
+
+func TestFileSystemSignedURL(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fsys, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	_, err = fsys.SignedURL("test/sub1.txt", nil)
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported, got %v", err)
+	}
+
+	_, err = fsys.SignedURL("test/sub1.txt", &blob.SignedURLOptions{Method: http.MethodDelete})
+	if err == nil || errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected invalid method error, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	_, err = fsys.SignedServeURL(req, "missing.txt", "missing.txt", time.Minute)
+	if !errors.Is(err, filesystem.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+
+	_, err = fsys.SignedServeURL(req, "test/sub1.txt", "sub1.txt", time.Minute)
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
 func TestFileSystemUpload(t *testing.T) {
 	dir := createTestDir(t)
 	defer os.RemoveAll(dir)
@@ -623,6 +729,86 @@ func TestFileSystemGetReader(t *testing.T) {
 	}
 }
 
+func TestFileSystemGetMultiReader(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fsys, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := fsys.GetMultiReader("test/sub1.txt", "test/missing.txt")
+		if !errors.Is(err, filesystem.ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound error, got %v", err)
+		}
+	})
+
+	t.Run("no files", func(t *testing.T) {
+		r, err := fsys.GetMultiReader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+
+		raw, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(raw) != 0 {
+			t.Fatalf("Expected empty content, got %q", raw)
+		}
+	})
+
+	t.Run("read and seek", func(t *testing.T) {
+		r, err := fsys.GetMultiReader("test/sub1.txt", "test/sub2.txt", "test/sub1.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+
+		raw, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if str := string(raw); str != "sub1sub2sub1" {
+			t.Fatalf("Expected content %q, got %q", "sub1sub2sub1", str)
+		}
+
+		seekScenarios := []struct {
+			offset   int64
+			whence   int
+			expected string
+		}{
+			{0, io.SeekStart, "sub1sub2sub1"},
+			{6, io.SeekStart, "b2sub1"},
+			{-3, io.SeekEnd, "ub1"},
+			{100, io.SeekStart, ""},
+		}
+
+		for _, s := range seekScenarios {
+			if _, err := r.Seek(s.offset, s.whence); err != nil {
+				t.Fatal(err)
+			}
+
+			raw, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if str := string(raw); str != s.expected {
+				t.Fatalf("[%d, %d] Expected content %q, got %q", s.offset, s.whence, s.expected, str)
+			}
+		}
+
+		if _, err := r.Seek(-1, io.SeekStart); err == nil {
+			t.Fatal("Expected negative position error")
+		}
+	})
+}
+
 func TestFileSystemGetReuploadableFile(t *testing.T) {
 	dir := createTestDir(t)
 	defer os.RemoveAll(dir)
@@ -0,0 +1,372 @@
+package filesystem
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+	"math"
+
+	"github.com/disintegration/imaging"
+	"github.com/pocketbase/pocketbase/tools/filesystem/internal/webp"
+)
+
+// ErrUnsupportedImage is returned by [SanitizeImage] for non JPEG, PNG or WebP content.
+var ErrUnsupportedImage = errors.New("unsupported image format")
+
+// ErrImageTooLarge is returned by [SanitizeImage] for images
+// whose dimensions exceed [MaxSanitizeImagePixels].
+var ErrImageTooLarge = errors.New("image dimensions exceed the max allowed pixels")
+
+// MaxSanitizeImagePixels is the max number of pixels (width * height)
+// of an image that [SanitizeImage] accepts.
+//
+// The limit is checked against the image header dimensions before
+// decoding to prevent allocating huge buffers for small "pixel bomb" files.
+const MaxSanitizeImagePixels = 50_000_000
+
+var (
+	jpegSignature = []byte{0xff, 0xd8, 0xff}
+	pngSignature  = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+)
+
+// SanitizeImage strips the metadata (EXIF, XMP, IPTC, comments, etc.) of a
+// JPEG, PNG or WebP image, bakes in its EXIF orientation and optionally
+// downscales it (preserving the aspect ratio) to fit inside maxWidth x maxHeight
+// (zero means no limit).
+//
+// The image is re-encoded only if it needs to be rotated or downscaled
+// (the WebP images are re-encoded lossless). Otherwise only the metadata
+// segments/chunks are removed and the image data is kept as it is.
+//
+// Returns [ErrUnsupportedImage] if r is not a JPEG, PNG or WebP image
+// (in this case only the header of r is consumed) and [ErrImageTooLarge]
+// if the image has more than [MaxSanitizeImagePixels] pixels.
+func SanitizeImage(r io.Reader, maxWidth, maxHeight int) ([]byte, error) {
+	br := bufio.NewReader(r)
+
+	header, _ := br.Peek(12)
+
+	var strip func([]byte) ([]byte, []byte, bool, error)
+
+	switch {
+	case bytes.HasPrefix(header, jpegSignature):
+		strip = stripJPEGMetadata
+	case bytes.HasPrefix(header, pngSignature):
+		strip = stripPNGMetadata
+	case len(header) == 12 && string(header[0:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		strip = stripWebPMetadata
+	default:
+		return nil, ErrUnsupportedImage
+	}
+
+	data, err := io.ReadAll(br)
+	if err != nil {
+		return nil, err
+	}
+
+	stripped, exif, animated, err := strip(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if animated {
+		return stripped, nil // re-encoding is not supported
+	}
+
+	orientation := exifOrientation(exif)
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(stripped))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(config.Width)*int64(config.Height) > MaxSanitizeImagePixels {
+		return nil, ErrImageTooLarge
+	}
+
+	width, height := config.Width, config.Height
+	if orientation >= 5 {
+		width, height = height, width // rotated by 90 or 270 degrees
+	}
+
+	newWidth, newHeight := fitSize(width, height, maxWidth, maxHeight)
+
+	if orientation <= 1 && newWidth == width && newHeight == height {
+		return stripped, nil
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		return nil, err
+	}
+
+	img = applyOrientation(img, orientation)
+
+	if newWidth != width || newHeight != height {
+		img = imaging.Resize(img, newWidth, newHeight, imaging.Lanczos)
+	}
+
+	var buf bytes.Buffer
+
+	switch format {
+	case "jpeg":
+		err = imaging.Encode(&buf, img, imaging.JPEG)
+	case "png":
+		err = imaging.Encode(&buf, img, imaging.PNG)
+	default:
+		err = webp.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// fitSize returns the downscaled width and height to fit inside maxWidth x maxHeight.
+func fitSize(width, height, maxWidth, maxHeight int) (int, int) {
+	scale := 1.0
+
+	if maxWidth > 0 && width > maxWidth {
+		scale = math.Min(scale, float64(maxWidth)/float64(width))
+	}
+
+	if maxHeight > 0 && height > maxHeight {
+		scale = math.Min(scale, float64(maxHeight)/float64(height))
+	}
+
+	if scale >= 1 {
+		return width, height
+	}
+
+	return max(1, int(math.Round(float64(width)*scale))), max(1, int(math.Round(float64(height)*scale)))
+}
+
+// applyOrientation transforms img according to the EXIF orientation tag value.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(img)
+	case 3:
+		return imaging.Rotate180(img)
+	case 4:
+		return imaging.FlipV(img)
+	case 5:
+		return imaging.Transpose(img)
+	case 6:
+		return imaging.Rotate270(img)
+	case 7:
+		return imaging.Transverse(img)
+	case 8:
+		return imaging.Rotate90(img)
+	}
+
+	return img
+}
+
+// exifOrientation returns the orientation tag value from the raw EXIF
+// (TIFF) data or 1 if the tag is missing or invalid.
+func exifOrientation(exif []byte) int {
+	exif = bytes.TrimPrefix(exif, []byte("Exif\x00\x00"))
+
+	if len(exif) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(exif[0:4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(exif[4:8]))
+	if offset < 8 || offset+2 > len(exif) {
+		return 1
+	}
+
+	entries := int(order.Uint16(exif[offset:]))
+	for i := 0; i < entries; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(exif) {
+			break
+		}
+
+		tag := order.Uint16(exif[entry:])
+		valueType := order.Uint16(exif[entry+2:])
+		if tag != 0x0112 || valueType != 3 { // orientation SHORT
+			continue
+		}
+
+		orientation := int(order.Uint16(exif[entry+8:]))
+		if orientation < 1 || orientation > 8 {
+			return 1
+		}
+
+		return orientation
+	}
+
+	return 1
+}
+
+// -------------------------------------------------------------------
+
+// stripJPEGMetadata removes the APP1 (EXIF, XMP), APP3-APP13, APP15 and COM segments.
+//
+// The APP0 (JFIF), APP2 (ICC profile) and APP14 (Adobe color transform)
+// segments are kept because they could affect the image rendering.
+func stripJPEGMetadata(data []byte) ([]byte, []byte, bool, error) {
+	errInvalid := errors.New("invalid jpeg data")
+
+	result := make([]byte, 0, len(data))
+	result = append(result, data[0:2]...) // SOI
+
+	var exif []byte
+
+	for i := 2; ; {
+		// skip fill bytes
+		for i < len(data) && data[i] == 0xff && i+1 < len(data) && data[i+1] == 0xff {
+			i++
+		}
+
+		if i+4 > len(data) || data[i] != 0xff {
+			return nil, nil, false, errInvalid
+		}
+
+		marker := data[i+1]
+
+		// the start of scan is followed by the entropy coded data
+		// (the rest of the file is kept as it is)
+		if marker == 0xda || marker == 0xd9 {
+			return append(result, data[i:]...), exif, false, nil
+		}
+
+		// standalone markers
+		if marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7) {
+			result = append(result, data[i:i+2]...)
+			i += 2
+			continue
+		}
+
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) || end < i+4 {
+			return nil, nil, false, errInvalid
+		}
+
+		segment := data[i:end]
+		i = end
+
+		switch {
+		case marker == 0xe1:
+			if bytes.HasPrefix(segment[4:], []byte("Exif\x00\x00")) && exif == nil {
+				exif = segment[4:]
+			}
+		case (marker >= 0xe3 && marker <= 0xed) || marker == 0xef || marker == 0xfe:
+			// strip
+		default:
+			result = append(result, segment...)
+		}
+	}
+}
+
+// stripPNGMetadata removes the textual (tEXt, zTXt, iTXt), eXIf and tIME chunks.
+func stripPNGMetadata(data []byte) ([]byte, []byte, bool, error) {
+	errInvalid := errors.New("invalid png data")
+
+	result := make([]byte, 0, len(data))
+	result = append(result, pngSignature...)
+
+	var exif []byte
+	var animated bool
+
+	for i := len(pngSignature); i < len(data); {
+		if i+12 > len(data) {
+			return nil, nil, false, errInvalid
+		}
+
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) || end < i {
+			return nil, nil, false, errInvalid
+		}
+
+		chunkType := string(data[i+4 : i+8])
+		chunk := data[i:end]
+		i = end
+
+		switch chunkType {
+		case "eXIf":
+			exif = chunk[8 : 8+length]
+		case "tEXt", "zTXt", "iTXt", "tIME":
+			// strip
+		case "acTL":
+			animated = true
+			result = append(result, chunk...)
+		default:
+			result = append(result, chunk...)
+		}
+
+		if chunkType == "IEND" {
+			break
+		}
+	}
+
+	return result, exif, animated, nil
+}
+
+// stripWebPMetadata removes the EXIF and XMP chunks.
+func stripWebPMetadata(data []byte) ([]byte, []byte, bool, error) {
+	errInvalid := errors.New("invalid webp data")
+
+	if len(data) < 12 {
+		return nil, nil, false, errInvalid
+	}
+
+	result := make([]byte, 12, len(data))
+	copy(result, data[0:12])
+
+	var exif []byte
+	var animated bool
+	vp8xOffset := -1
+
+	for i := 12; i+8 <= len(data); {
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size&1
+		if size < 0 || end > len(data)+size&1 || end < i {
+			return nil, nil, false, errInvalid
+		}
+		end = min(end, len(data))
+
+		chunkType := string(data[i : i+4])
+		chunk := data[i:end]
+		i = end
+
+		switch chunkType {
+		case "EXIF":
+			exif = chunk[8 : 8+size]
+		case "XMP ":
+			// strip
+		default:
+			if chunkType == "VP8X" && size >= 10 {
+				vp8xOffset = len(result)
+				animated = chunk[8]&0x02 != 0
+			}
+			result = append(result, chunk...)
+		}
+	}
+
+	// clear the EXIF and XMP VP8X flags
+	if vp8xOffset >= 0 {
+		result[vp8xOffset+8] &^= 0x08 | 0x04
+	}
+
+	binary.LittleEndian.PutUint32(result[4:8], uint32(len(result)-8))
+
+	return result, exif, animated, nil
+}
@@ -0,0 +1,315 @@
+package filesystem_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/filesystem/internal/webp"
+)
+
+func TestSanitizeImage(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name           string
+		data           []byte
+		maxWidth       int
+		maxHeight      int
+		expectError    bool
+		expectedErr    error
+		expectedFormat string
+		expectedSize   image.Point
+		expectedTopRow color.Color // expected top left pixel color (nil to skip)
+	}{
+		{
+			name:        "unsupported",
+			data:        []byte("test"),
+			expectError: true,
+			expectedErr: filesystem.ErrUnsupportedImage,
+		},
+		{
+			name:        "image with too many pixels",
+			data:        testPNGWithSize(t, 10000, 10000),
+			expectError: true,
+			expectedErr: filesystem.ErrImageTooLarge,
+		},
+		{
+			name:        "invalid jpeg",
+			data:        []byte("\xff\xd8\xff\xe1\x00\xff"),
+			expectError: true,
+		},
+		{
+			name:           "jpeg without orientation",
+			data:           testJPEG(t, 4, 2, 1),
+			expectedFormat: "jpeg",
+			expectedSize:   image.Point{4, 2},
+		},
+		{
+			name:           "jpeg with orientation",
+			data:           testJPEG(t, 4, 2, 6),
+			expectedFormat: "jpeg",
+			expectedSize:   image.Point{2, 4},
+		},
+		{
+			name:           "jpeg with orientation and max width",
+			data:           testJPEG(t, 40, 20, 8),
+			maxWidth:       10,
+			expectedFormat: "jpeg",
+			expectedSize:   image.Point{10, 20},
+		},
+		{
+			name:           "png without orientation",
+			data:           testPNG(t, 4, 2, 1),
+			expectedFormat: "png",
+			expectedSize:   image.Point{4, 2},
+			expectedTopRow: color.NRGBA{255, 0, 0, 255},
+		},
+		{
+			name:           "png with orientation",
+			data:           testPNG(t, 4, 2, 3),
+			expectedFormat: "png",
+			expectedSize:   image.Point{4, 2},
+			expectedTopRow: color.NRGBA{0, 0, 255, 255},
+		},
+		{
+			name:           "png with max size",
+			data:           testPNG(t, 40, 20, 1),
+			maxWidth:       30,
+			maxHeight:      5,
+			expectedFormat: "png",
+			expectedSize:   image.Point{10, 5},
+		},
+		{
+			name:           "png smaller than the max size",
+			data:           testPNG(t, 40, 20, 1),
+			maxWidth:       100,
+			maxHeight:      100,
+			expectedFormat: "png",
+			expectedSize:   image.Point{40, 20},
+		},
+		{
+			name:           "webp without orientation",
+			data:           testWebP(t, 4, 2, 1),
+			expectedFormat: "webp",
+			expectedSize:   image.Point{4, 2},
+		},
+		{
+			name:           "webp with orientation",
+			data:           testWebP(t, 4, 2, 6),
+			expectedFormat: "webp",
+			expectedSize:   image.Point{2, 4},
+		},
+		{
+			name:           "webp with max height",
+			data:           testWebP(t, 40, 20, 1),
+			maxHeight:      10,
+			expectedFormat: "webp",
+			expectedSize:   image.Point{20, 10},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := filesystem.SanitizeImage(bytes.NewReader(s.data), s.maxWidth, s.maxHeight)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				if s.expectedErr != nil && !errors.Is(err, s.expectedErr) {
+					t.Fatalf("Expected error %v, got %v", s.expectedErr, err)
+				}
+				return
+			}
+
+			for _, secret := range []string{"Exif", "GPS", "XMP", "secret"} {
+				if bytes.Contains(result, []byte(secret)) {
+					t.Fatalf("Expected %q to be stripped", secret)
+				}
+			}
+
+			img, format, err := image.Decode(bytes.NewReader(result))
+			if err != nil {
+				t.Fatalf("Failed to decode the sanitized image: %v", err)
+			}
+
+			if format != s.expectedFormat {
+				t.Fatalf("Expected format %q, got %q", s.expectedFormat, format)
+			}
+
+			if size := img.Bounds().Size(); size != s.expectedSize {
+				t.Fatalf("Expected size %v, got %v", s.expectedSize, size)
+			}
+
+			if s.expectedTopRow != nil {
+				if c := color.NRGBAModel.Convert(img.At(0, 0)); c != s.expectedTopRow {
+					t.Fatalf("Expected top left pixel %v, got %v", s.expectedTopRow, c)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitizeImageWebPFlags(t *testing.T) {
+	t.Parallel()
+
+	result, err := filesystem.SanitizeImage(bytes.NewReader(testWebP(t, 4, 2, 1)), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size := int(binary.LittleEndian.Uint32(result[4:8])); size != len(result)-8 {
+		t.Fatalf("Expected RIFF size %d, got %d", len(result)-8, size)
+	}
+
+	if string(result[12:16]) != "VP8X" {
+		t.Fatalf("Expected VP8X chunk, got %q", result[12:16])
+	}
+
+	if flags := result[20]; flags&(0x08|0x04) != 0 {
+		t.Fatalf("Expected the EXIF and XMP flags to be cleared, got %08b", flags)
+	}
+}
+
+// -------------------------------------------------------------------
+
+// testImage creates a new w x h image with red top half and blue bottom half.
+func testImage(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if y < h/2 {
+				img.Set(x, y, color.NRGBA{255, 0, 0, 255})
+			} else {
+				img.Set(x, y, color.NRGBA{0, 0, 255, 255})
+			}
+		}
+	}
+	return img
+}
+
+// testExif creates a minimal little-endian TIFF EXIF data with
+// the specified orientation tag and some dummy GPS text.
+func testExif(orientation int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("II*\x00")
+	binary.Write(&buf, binary.LittleEndian, uint32(8))      // IFD0 offset
+	binary.Write(&buf, binary.LittleEndian, uint16(1))      // entries count
+	binary.Write(&buf, binary.LittleEndian, uint16(0x0112)) // orientation tag
+	binary.Write(&buf, binary.LittleEndian, uint16(3))      // SHORT
+	binary.Write(&buf, binary.LittleEndian, uint32(1))      // count
+	binary.Write(&buf, binary.LittleEndian, uint16(orientation))
+	binary.Write(&buf, binary.LittleEndian, uint16(0))
+	binary.Write(&buf, binary.LittleEndian, uint32(0)) // next IFD
+	buf.WriteString("GPS secret location")
+	return buf.Bytes()
+}
+
+func testJPEG(t *testing.T, w, h, orientation int) []byte {
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, testImage(w, h), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	segment := func(marker byte, payload []byte) []byte {
+		s := []byte{0xff, marker, 0, 0}
+		binary.BigEndian.PutUint16(s[2:], uint16(len(payload)+2))
+		return append(s, payload...)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(encoded.Bytes()[:2]) // SOI
+	buf.Write(segment(0xe1, append([]byte("Exif\x00\x00"), testExif(orientation)...)))
+	buf.Write(segment(0xe1, []byte("http://ns.adobe.com/xap/1.0/\x00<XMP secret>")))
+	buf.Write(segment(0xfe, []byte("secret comment")))
+	buf.Write(encoded.Bytes()[2:])
+	return buf.Bytes()
+}
+
+func testPNG(t *testing.T, w, h, orientation int) []byte {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, testImage(w, h)); err != nil {
+		t.Fatal(err)
+	}
+
+	chunk := func(typ string, data []byte) []byte {
+		c := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+		c = append(c, typ...)
+		c = append(c, data...)
+		return append(c, 0, 0, 0, 0) // dummy crc (the chunk is stripped anyway)
+	}
+
+	data := encoded.Bytes()
+	ihdrEnd := 8 + 12 + 13 // signature + IHDR chunk
+
+	var buf bytes.Buffer
+	buf.Write(data[:ihdrEnd])
+	buf.Write(chunk("eXIf", testExif(orientation)))
+	buf.Write(chunk("tEXt", []byte("Comment\x00secret")))
+	buf.Write(data[ihdrEnd:])
+	return buf.Bytes()
+}
+
+// testPNGWithSize returns a small 1x1 PNG image whose header
+// declares the provided dimensions.
+func testPNGWithSize(t *testing.T, w, h int) []byte {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, testImage(1, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	data := encoded.Bytes()
+
+	// IHDR data starts after the signature, the chunk length and type
+	ihdr := data[8+8 : 8+8+13]
+	binary.BigEndian.PutUint32(ihdr[0:4], uint32(w))
+	binary.BigEndian.PutUint32(ihdr[4:8], uint32(h))
+	binary.BigEndian.PutUint32(data[8+8+13:], crc32.ChecksumIEEE(data[8+4:8+8+13]))
+
+	return data
+}
+
+func testWebP(t *testing.T, w, h, orientation int) []byte {
+	var encoded bytes.Buffer
+	if err := webp.Encode(&encoded, testImage(w, h)); err != nil {
+		t.Fatal(err)
+	}
+
+	chunk := func(typ string, data []byte) []byte {
+		c := append([]byte(typ), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+		c = append(c, data...)
+		if len(data)%2 == 1 {
+			c = append(c, 0)
+		}
+		return c
+	}
+
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x08 | 0x04 // EXIF and XMP flags
+	vp8x[4], vp8x[5], vp8x[6] = byte(w-1), byte((w-1)>>8), byte((w-1)>>16)
+	vp8x[7], vp8x[8], vp8x[9] = byte(h-1), byte((h-1)>>8), byte((h-1)>>16)
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	body.Write(chunk("VP8X", vp8x))
+	body.Write(encoded.Bytes()[12:]) // the VP8L chunk
+	body.Write(chunk("EXIF", testExif(orientation)))
+	body.Write(chunk("XMP ", []byte("<x:xmpmeta>secret</x:xmpmeta>")))
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(body.Len()))
+	buf.Write(body.Bytes())
+
+	return buf.Bytes()
+}
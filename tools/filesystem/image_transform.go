@@ -0,0 +1,295 @@
+package filesystem
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/pocketbase/pocketbase/tools/filesystem/blob"
+	"github.com/pocketbase/pocketbase/tools/filesystem/internal/webp"
+	"github.com/pocketbase/pocketbase/tools/routine"
+)
+
+// List with the supported image transform output formats.
+const (
+	ImageFormatWebp = "webp"
+	ImageFormatPng  = "png"
+	ImageFormatJpeg = "jpeg"
+)
+
+// MaxImageTransformBlur is the max allowed image transform blur sigma.
+const MaxImageTransformBlur = 50
+
+// ImageTransform defines a set of image transformation options.
+//
+// The transformations are applied in the following order:
+// auto orientation, resize, grayscale, blur and format conversion.
+type ImageTransform struct {
+	// Size is an optional resize size in one of the [ThumbSizeRegex] formats.
+	Size string
+
+	// Format is an optional output format (webp, png or jpeg).
+	//
+	// If empty, JPEG, GIF and WebP images keep their original format
+	// and all other fallback to PNG.
+	Format string
+
+	// Quality is an optional JPEG encoding quality in the range 1-100.
+	Quality int
+
+	// Blur is an optional gaussian blur sigma in the range (0, [MaxImageTransformBlur]].
+	Blur float64
+
+	// Grayscale indicates whether to convert the image to grayscale.
+	Grayscale bool
+
+	// AutoRotate indicates whether to rotate/flip the image
+	// according to its EXIF orientation tag.
+	AutoRotate bool
+}
+
+// ParseImageTransform parses and validates a comma separated list of
+// image transformation options.
+//
+// The supported options are:
+//
+//   - WxH, WxHt, WxHb, WxHf, 0xH or Wx0 - resize in the same format as the thumbs
+//   - format:webp, format:png or format:jpeg - convert to the specified format
+//   - quality:N - JPEG encoding quality in the range 1-100
+//   - blur:N - gaussian blur sigma in the range (0, 50]
+//   - grayscale - convert to grayscale
+//   - autorotate - rotate/flip the image according to its EXIF orientation
+//
+// For example: "100x100f,format:webp,grayscale".
+func ParseImageTransform(str string) (*ImageTransform, error) {
+	if str == "" {
+		return nil, errors.New("empty image transform")
+	}
+
+	t := &ImageTransform{}
+
+	seen := map[string]bool{}
+
+	for _, option := range strings.Split(str, ",") {
+		name, value, hasValue := strings.Cut(strings.TrimSpace(option), ":")
+
+		if ThumbSizeRegex.MatchString(name) && !hasValue {
+			value = name
+			name = "size"
+		}
+
+		if seen[name] {
+			return nil, fmt.Errorf("duplicated image transform option %q", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "size":
+			if _, _, _, err := parseThumbSize(value); err != nil {
+				return nil, err
+			}
+			t.Size = value
+		case "format":
+			switch value {
+			case ImageFormatWebp, ImageFormatPng, ImageFormatJpeg:
+				t.Format = value
+			case "jpg":
+				t.Format = ImageFormatJpeg
+			default:
+				return nil, fmt.Errorf("unsupported image transform format %q", value)
+			}
+		case "quality":
+			quality, err := strconv.Atoi(value)
+			if err != nil || quality < 1 || quality > 100 {
+				return nil, errors.New("image transform quality must be an integer in the range 1-100")
+			}
+			t.Quality = quality
+		case "blur":
+			blur, err := strconv.ParseFloat(value, 64)
+			if err != nil || !(blur > 0 && blur <= MaxImageTransformBlur) {
+				return nil, fmt.Errorf("image transform blur must be a number in the range (0, %d]", MaxImageTransformBlur)
+			}
+			t.Blur = blur
+		case "grayscale", "autorotate":
+			if hasValue {
+				return nil, fmt.Errorf("image transform option %q doesn't accept a value", name)
+			}
+			if name == "grayscale" {
+				t.Grayscale = true
+			} else {
+				t.AutoRotate = true
+			}
+		default:
+			return nil, fmt.Errorf("unknown image transform option %q", name)
+		}
+	}
+
+	if t.Quality > 0 && t.Format != "" && t.Format != ImageFormatJpeg {
+		return nil, errors.New("image transform quality is supported only for the jpeg format")
+	}
+
+	return t, nil
+}
+
+// String returns the normalized comma separated string representation
+// of the image transform (the options are always in the same order).
+func (t *ImageTransform) String() string {
+	options := make([]string, 0, 6)
+
+	if t.Size != "" {
+		options = append(options, t.Size)
+	}
+	if t.Format != "" {
+		options = append(options, "format:"+t.Format)
+	}
+	if t.Quality > 0 {
+		options = append(options, "quality:"+strconv.Itoa(t.Quality))
+	}
+	if t.Blur > 0 {
+		options = append(options, "blur:"+strconv.FormatFloat(t.Blur, 'f', -1, 64))
+	}
+	if t.Grayscale {
+		options = append(options, "grayscale")
+	}
+	if t.AutoRotate {
+		options = append(options, "autorotate")
+	}
+
+	return strings.Join(options, ",")
+}
+
+// Filename returns the storage safe filename of the transformed original file
+// (the transform options are prepended and the extension is adjusted to the output format).
+//
+// For example: "100x100_format-webp_grayscale_test.webp".
+func (t *ImageTransform) Filename(original string) string {
+	prefix := strings.NewReplacer(",", "_", ":", "-").Replace(t.String())
+
+	if t.Format == "" {
+		return prefix + "_" + original
+	}
+
+	ext := "." + t.Format
+	if t.Format == ImageFormatJpeg {
+		ext = ".jpg"
+	}
+
+	return prefix + "_" + strings.TrimSuffix(original, filepath.Ext(original)) + ext
+}
+
+// CreateImageTransform creates a new transformed image from the originalKey image
+// and stores it under the transformedKey.
+func (s *System) CreateImageTransform(originalKey, transformedKey string, transform *ImageTransform) error {
+	// note: the wrapping is an extra precaution since there were several
+	// golang.org/x/image panic related issues over the years
+	return routine.SafeWrap(func() error {
+		return s.createImageTransform(originalKey, transformedKey, transform)
+	})()
+}
+
+func (s *System) createImageTransform(originalKey, transformedKey string, transform *ImageTransform) error {
+	var width, height int
+	var resizeType string
+	if transform.Size != "" {
+		var err error
+		width, height, resizeType, err = parseThumbSize(transform.Size)
+		if err != nil {
+			return err
+		}
+	}
+
+	// fetch the original
+	r, err := s.GetReader(originalKey)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	// detect the original format from the content itself since the
+	// stored content type may be missing or inaccurate
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(512)
+	originalContentType := mimetype.Detect(header).String()
+
+	// (note: only the first frame for animated image formats)
+	var img image.Image
+	img, err = imaging.Decode(br, imaging.AutoOrientation(transform.AutoRotate))
+	if err != nil {
+		return err
+	}
+
+	if transform.Size != "" {
+		img = resizeImage(img, width, height, resizeType)
+	}
+
+	if transform.Grayscale {
+		img = imaging.Grayscale(img)
+	}
+
+	if transform.Blur > 0 {
+		img = imaging.Blur(img, transform.Blur)
+	}
+
+	format := transform.Format
+	if format == "" {
+		switch originalContentType {
+		case "image/jpeg":
+			format = ImageFormatJpeg
+		case "image/gif":
+			format = "gif"
+		case "image/webp":
+			format = ImageFormatWebp
+		default:
+			format = ImageFormatPng
+		}
+	}
+
+	var encode func(w io.Writer) error
+
+	switch format {
+	case ImageFormatWebp:
+		encode = func(w io.Writer) error {
+			return webp.Encode(w, img)
+		}
+	case ImageFormatJpeg:
+		encode = func(w io.Writer) error {
+			var opts []imaging.EncodeOption
+			if transform.Quality > 0 {
+				opts = append(opts, imaging.JPEGQuality(transform.Quality))
+			}
+			return imaging.Encode(w, img, imaging.JPEG, opts...)
+		}
+	case "gif":
+		encode = func(w io.Writer) error {
+			return imaging.Encode(w, img, imaging.GIF)
+		}
+	default:
+		encode = func(w io.Writer) error {
+			return imaging.Encode(w, img, imaging.PNG)
+		}
+	}
+
+	// open a storage writer (aka. prepare for upload)
+	w, err := s.bucket.NewWriter(s.ctx, transformedKey, &blob.WriterOptions{
+		ContentType: "image/" + format,
+	})
+	if err != nil {
+		return err
+	}
+
+	// encode (aka. upload)
+	if err := encode(w); err != nil {
+		w.Close()
+		return err
+	}
+
+	// check for close errors to ensure that the image was really saved
+	return w.Close()
+}
@@ -0,0 +1,186 @@
+package filesystem_test
+
+import (
+	"image"
+	"os"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+func TestParseImageTransform(t *testing.T) {
+	scenarios := []struct {
+		str         string
+		expectError bool
+		expected    string
+	}{
+		{"", true, ""},
+		{"unknown", true, ""},
+		{"100x", true, ""},
+		{"0x0", true, ""},
+		{"size:100x100", false, "100x100"},
+		{"100x100,200x200", true, ""},
+		{"format:gif", true, ""},
+		{"format:webp,format:png", true, ""},
+		{"quality:0", true, ""},
+		{"quality:101", true, ""},
+		{"quality:abc", true, ""},
+		{"format:png,quality:80", true, ""},
+		{"blur:0", true, ""},
+		{"blur:-1", true, ""},
+		{"blur:51", true, ""},
+		{"blur:abc", true, ""},
+		{"grayscale:1", true, ""},
+		{"autorotate:1", true, ""},
+		{"100x100", false, "100x100"},
+		{"format:jpg", false, "format:jpeg"},
+		{"quality:80", false, "quality:80"},
+		{"blur:1.50", false, "blur:1.5"},
+		{
+			"autorotate, grayscale,blur:2,quality:90,format:jpeg,100x50f",
+			false,
+			"100x50f,format:jpeg,quality:90,blur:2,grayscale,autorotate",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.str, func(t *testing.T) {
+			transform, err := filesystem.ParseImageTransform(s.str)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if str := transform.String(); str != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, str)
+			}
+		})
+	}
+}
+
+func TestImageTransformFilename(t *testing.T) {
+	scenarios := []struct {
+		transform string
+		original  string
+		expected  string
+	}{
+		{"100x100", "test.png", "100x100_test.png"},
+		{"blur:1.5,grayscale", "test.png", "blur-1.5_grayscale_test.png"},
+		{"format:webp,100x0", "test.png", "100x0_format-webp_test.webp"},
+		{"format:jpeg,quality:80", "test.png", "format-jpeg_quality-80_test.jpg"},
+		{"format:png", "test", "format-png_test.png"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.transform, func(t *testing.T) {
+			transform, err := filesystem.ParseImageTransform(s.transform)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if name := transform.Filename(s.original); name != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, name)
+			}
+		})
+	}
+}
+
+func TestFileSystemCreateImageTransform(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fsys, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	scenarios := []struct {
+		file             string
+		key              string
+		transform        string
+		expectedMimeType string
+		expectedSize     image.Point
+	}{
+		// missing
+		{"missing.txt", "transform_missing", "100x100", "", image.Point{}},
+		// non-image existing file
+		{"test/sub1.txt", "transform_sub1", "100x100", "", image.Point{}},
+		// existing image file with existing transform path = should fail
+		{"image.png", "test", "100x100", "", image.Point{}},
+		// png original format
+		{"image.png", "transform_png", "100x50,grayscale,blur:1", "image/png", image.Point{100, 50}},
+		// png to webp
+		{"image.png", "transform_webp", "20x10,format:webp", "image/webp", image.Point{20, 10}},
+		// png to jpeg
+		{"image.png", "transform_jpeg", "format:jpeg,quality:50", "image/jpeg", image.Point{1, 1}},
+		// jpg original format with quality
+		{"image.jpg", "transform.jpg", "quality:50,autorotate", "image/jpeg", image.Point{1, 1}},
+		// jpg to png
+		{"image.jpg", "transform_jpg.png", "format:png", "image/png", image.Point{1, 1}},
+		// webp original format (should keep webp)
+		{"image.webp", "transform.webp", "10x10", "image/webp", image.Point{10, 10}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.file+"_"+s.key+"_"+s.transform, func(t *testing.T) {
+			transform, err := filesystem.ParseImageTransform(s.transform)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = fsys.CreateImageTransform(s.file, s.key, transform)
+
+			expectErr := s.expectedMimeType == ""
+
+			hasErr := err != nil
+			if hasErr != expectErr {
+				t.Fatalf("Expected hasErr to be %v, got %v (%v)", expectErr, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			f, err := fsys.GetReader(s.key)
+			if err != nil {
+				t.Fatalf("Missing expected transformed file %s (%v)", s.key, err)
+			}
+			defer f.Close()
+
+			if attrsMimeType := f.ContentType(); attrsMimeType != s.expectedMimeType {
+				t.Fatalf("Expected attrs MimeType %q, got %q", s.expectedMimeType, attrsMimeType)
+			}
+
+			mt, err := mimetype.DetectReader(f)
+			if err != nil {
+				t.Fatalf("Failed to detect %s mimetype (%v)", s.key, err)
+			}
+
+			if fileMimeType := mt.String(); fileMimeType != s.expectedMimeType {
+				t.Fatalf("Expected file MimeType %q, got %q", s.expectedMimeType, fileMimeType)
+			}
+
+			r, err := fsys.GetReader(s.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			cfg, _, err := image.DecodeConfig(r)
+			if err != nil {
+				t.Fatalf("Failed to decode %s (%v)", s.key, err)
+			}
+
+			if cfg.Width != s.expectedSize.X || cfg.Height != s.expectedSize.Y {
+				t.Fatalf("Expected %v size, got %dx%d", s.expectedSize, cfg.Width, cfg.Height)
+			}
+		})
+	}
+}
@@ -549,6 +549,14 @@ func (b *driver) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// SignedURL implements [blob/Driver.SignedURL].
+//
+// The local filesystem doesn't support signed URLs
+// and it always returns an error wrapping [errors.ErrUnsupported].
+func (b *driver) SignedURL(ctx context.Context, key string, opts *blob.SignedURLOptions) (string, error) {
+	return "", fmt.Errorf("fileblob: signed URLs are not supported: %w", errors.ErrUnsupported)
+}
+
 // -------------------------------------------------------------------
 
 type reader struct {
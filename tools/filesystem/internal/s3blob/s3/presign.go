@@ -0,0 +1,93 @@
+package s3
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxPresignExpiry is the max allowed validity duration of a presigned URL.
+const MaxPresignExpiry = 7 * 24 * time.Hour
+
+// PresignURL returns a new query string authenticated (aka. presigned)
+// URL for the specified method and object key that is valid for the
+// specified expires duration (must be between 1s and [MaxPresignExpiry]).
+//
+// The optional request funcs could be used to specify additional query
+// parameters (e.g. "response-content-disposition") or headers that
+// must be sent with the presigned request (e.g. "Content-Type" or "Content-Length").
+//
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-query-string-auth.html
+func (s3 *S3) PresignURL(method string, key string, expires time.Duration, optFuncs ...func(*http.Request)) (string, error) {
+	if expires < time.Second || expires > MaxPresignExpiry {
+		return "", errors.New("the presigned URL expires duration must be between 1s and 7 days")
+	}
+
+	req, err := http.NewRequest(method, s3.URL(key), nil)
+	if err != nil {
+		return "", err
+	}
+
+	// apply optional request funcs
+	for _, fn := range optFuncs {
+		if fn != nil {
+			fn(req)
+		}
+	}
+
+	reqDateTime, _ := time.Parse(dateTimeFormat, req.Header.Get("x-amz-date"))
+	if reqDateTime.IsZero() {
+		reqDateTime = time.Now().UTC()
+	}
+
+	// the date and the payload hash are part of the query string and the canonical request
+	req.Header.Del("x-amz-date")
+	req.Header.Del("x-amz-content-sha256")
+
+	req.Header.Set("host", req.URL.Host)
+
+	date := reqDateTime.Format("20060102")
+
+	dateTime := reqDateTime.Format(dateTimeFormat)
+
+	scope := strings.Join([]string{
+		date,
+		s3.Region,
+		awsS3ServiceCode,
+		awsTerminationString,
+	}, "/")
+
+	// sign also the Content-Length (if set) to restrict the size of the presigned PUT body
+	canonicalHeaders, signedHeaders := canonicalAndSignedHeaders(req, "content-length")
+
+	query := req.URL.Query()
+	query.Set("X-Amz-Algorithm", awsSignAlgorithm)
+	query.Set("X-Amz-Credential", s3.AccessKey+"/"+scope)
+	query.Set("X-Amz-Date", dateTime)
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+
+	canonicalQuery := escapeQuery(query)
+
+	canonicalParts := []string{
+		req.Method,
+		escapePath(req.URL.Path),
+		canonicalQuery,
+		canonicalHeaders,
+		signedHeaders,
+		"UNSIGNED-PAYLOAD",
+	}
+
+	stringToSign := strings.Join([]string{
+		awsSignAlgorithm,
+		dateTime,
+		scope,
+		sha256Hex([]byte(strings.Join(canonicalParts, "\n"))),
+	}, "\n")
+
+	req.URL.RawQuery = canonicalQuery + "&X-Amz-Signature=" + s3.signature(date, stringToSign)
+
+	return req.URL.String(), nil
+}
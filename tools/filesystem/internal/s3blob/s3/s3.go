@@ -2,7 +2,7 @@
 // REST APIs of any S3 compatible service.
 //
 // It implements only the minimal functionality required by PocketBase
-// such as objects list, get, copy, delete and upload.
+// such as objects list, get, copy, delete, upload and presigned URLs.
 //
 // For more details why we don't use the official aws-sdk-go-v2, you could check
 // https://github.com/pocketbase/pocketbase/discussions/6562.
@@ -213,11 +213,7 @@ func (s3 *S3) sign(req *http.Request) {
 	// 4. Derive a signing key for SigV4
 	// https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv-create-signed-request.html#derive-signing-key
 	// ---------------------------------------------------------------
-	dateKey := hmacSHA256([]byte("AWS4"+s3.SecretKey), date)
-	dateRegionKey := hmacSHA256(dateKey, s3.Region)
-	dateRegionServiceKey := hmacSHA256(dateRegionKey, awsS3ServiceCode)
-	signingKey := hmacSHA256(dateRegionServiceKey, awsTerminationString)
-	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+	signature := s3.signature(date, stringToSign)
 
 	// 5. Add the signature to the request
 	// https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv-create-signed-request.html#add-signature-to-request
@@ -233,6 +229,19 @@ func (s3 *S3) sign(req *http.Request) {
 	req.Header.Set("authorization", authorization)
 }
 
+// signature derives the SigV4 signing key for the specified date
+// and returns the hex encoded signature of stringToSign.
+//
+// https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv-create-signed-request.html#derive-signing-key
+func (s3 *S3) signature(date string, stringToSign string) string {
+	dateKey := hmacSHA256([]byte("AWS4"+s3.SecretKey), date)
+	dateRegionKey := hmacSHA256(dateKey, s3.Region)
+	dateRegionServiceKey := hmacSHA256(dateRegionKey, awsS3ServiceCode)
+	signingKey := hmacSHA256(dateRegionServiceKey, awsTerminationString)
+
+	return hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+}
+
 func sha256Hex(content []byte) string {
 	h := sha256.New()
 	h.Write(content)
@@ -245,7 +254,9 @@ func hmacSHA256(key []byte, content string) []byte {
 	return mac.Sum(nil)
 }
 
-func canonicalAndSignedHeaders(req *http.Request) (string, string) {
+// canonicalAndSignedHeaders returns the canonical and signed headers
+// of the request (host, content-type, x-amz-* and the optional extra lowercased header names).
+func canonicalAndSignedHeaders(req *http.Request, extraHeaders ...string) (string, string) {
 	signed := []string{}
 	canonical := map[string]string{}
 
@@ -254,7 +265,8 @@ func canonicalAndSignedHeaders(req *http.Request) (string, string) {
 
 		if normalizedKey != "host" &&
 			normalizedKey != "content-type" &&
-			!strings.HasPrefix(normalizedKey, "x-amz-") {
+			!strings.HasPrefix(normalizedKey, "x-amz-") &&
+			!slices.Contains(extraHeaders, normalizedKey) {
 			continue
 		}
 
@@ -297,6 +297,32 @@ func (drv *driver) Delete(ctx context.Context, key string) error {
 	return drv.s3.DeleteObject(ctx, key)
 }
 
+// SignedURL implements [blob/Driver.SignedURL].
+func (drv *driver) SignedURL(ctx context.Context, key string, opts *blob.SignedURLOptions) (string, error) {
+	key = escapeKey(key)
+
+	return drv.s3.PresignURL(opts.Method, key, opts.Expiry, func(r *http.Request) {
+		if opts.Method == http.MethodPut {
+			if opts.ContentType != "" {
+				r.Header.Set("Content-Type", opts.ContentType)
+			}
+			if opts.ContentLength > 0 {
+				r.Header.Set("Content-Length", strconv.FormatInt(opts.ContentLength, 10))
+			}
+			return
+		}
+
+		query := r.URL.Query()
+		if opts.ContentType != "" {
+			query.Set("response-content-type", opts.ContentType)
+		}
+		if opts.ContentDisposition != "" {
+			query.Set("response-content-disposition", opts.ContentDisposition)
+		}
+		r.URL.RawQuery = query.Encode()
+	})
+}
+
 // -------------------------------------------------------------------
 
 // reader reads an S3 object. It implements io.ReadCloser.
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pocketbase/pocketbase/tools/filesystem/blob"
 	"github.com/pocketbase/pocketbase/tools/filesystem/internal/s3blob"
@@ -603,3 +604,92 @@ func TestDriverNewTypedWriter(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestDriverSignedURL(t *testing.T) {
+	t.Parallel()
+
+	drv, err := s3blob.New(&s3.S3{
+		Bucket:    "test_bucket",
+		Region:    "test_region",
+		Endpoint:  "https://example.com",
+		AccessKey: "123",
+		SecretKey: "abc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		key         string
+		opts        *blob.SignedURLOptions
+		expected    []string
+		notExpected []string
+	}{
+		{
+			"GET",
+			"a/b c",
+			&blob.SignedURLOptions{Method: http.MethodGet, Expiry: time.Minute},
+			[]string{
+				"https://test_bucket.example.com/a/b%20c?",
+				"X-Amz-Expires=60",
+				"X-Amz-SignedHeaders=host&",
+				"X-Amz-Signature=",
+			},
+			[]string{"response-content"},
+		},
+		{
+			"GET with response overrides",
+			"test\x01/../a",
+			&blob.SignedURLOptions{
+				Method:             http.MethodGet,
+				Expiry:             time.Minute,
+				ContentType:        "text/plain",
+				ContentDisposition: "attachment; filename=a",
+			},
+			[]string{
+				"https://test_bucket.example.com/test__0x1__/..__0x2f__a?",
+				"response-content-disposition=attachment%3B%20filename%3Da",
+				"response-content-type=text%2Fplain",
+			},
+			nil,
+		},
+		{
+			"PUT with content type",
+			"a",
+			&blob.SignedURLOptions{
+				Method:             http.MethodPut,
+				Expiry:             time.Hour,
+				ContentType:        "text/plain",
+				ContentDisposition: "attachment",
+			},
+			[]string{
+				"https://test_bucket.example.com/a?",
+				"X-Amz-Expires=3600",
+				"X-Amz-SignedHeaders=content-type%3Bhost&",
+			},
+			[]string{"response-content"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := drv.SignedURL(context.Background(), s.key, s.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, str := range s.expected {
+				if !strings.Contains(result, str) {
+					t.Fatalf("Expected %q in\n%s", str, result)
+				}
+			}
+
+			for _, str := range s.notExpected {
+				if strings.Contains(result, str) {
+					t.Fatalf("Didn't expect %q in\n%s", str, result)
+				}
+			}
+		})
+	}
+}
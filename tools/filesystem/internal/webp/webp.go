@@ -0,0 +1,651 @@
+// Package webp implements a minimal pure Go lossless WebP (VP8L) encoder.
+//
+// The encoder applies only the subtract green and predictor transforms
+// followed by a single set of prefix (Huffman) codes with simple run-length
+// backward references and without color cache, which is good enough for
+// thumbs and other small images without relying on cgo or external tools.
+//
+// See https://developers.google.com/speed/webp/docs/webp_lossless_bitstream_specification.
+package webp
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+	"math/bits"
+	"slices"
+	"sort"
+)
+
+const (
+	maxDimension = 1 << 14
+
+	predictorBits = 4
+
+	nLiteralCodes  = 256
+	nLengthCodes   = 24
+	nDistanceCodes = 40
+
+	maxCodeLength           = 15
+	maxCodeLengthCodeLength = 7
+
+	minBackwardLength = 3
+	maxBackwardLength = 4096
+
+	// the distance codes of the left and top neighbour pixels
+	planeCodeTop  = 1
+	planeCodeLeft = 2
+)
+
+const (
+	transformPredictor     = 0
+	transformSubtractGreen = 2
+)
+
+// the order in which the code length code lengths are stored
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// the predictor modes that are tried for each tile
+var predictorModes = []uint8{1, 2, 11, 12, 13}
+
+// Encode writes the image m to w in lossless WebP format.
+func Encode(w io.Writer, m image.Image) error {
+	b := m.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width <= 0 || height <= 0 {
+		return errors.New("webp: empty image")
+	}
+	if width > maxDimension || height > maxDimension {
+		return errors.New("webp: image is too large")
+	}
+
+	img, ok := m.(*image.NRGBA)
+	if !ok || img.Stride != 4*width || b.Min != (image.Point{}) {
+		img = image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(img, img.Bounds(), m, b.Min, draw.Src)
+	}
+
+	hasAlpha := false
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0xff {
+			hasAlpha = true
+			break
+		}
+	}
+
+	pix := make([]byte, len(img.Pix))
+	copy(pix, img.Pix)
+
+	subtractGreen(pix)
+
+	tiles, residuals := predict(pix, width, height)
+
+	bw := &bitWriter{}
+
+	// header
+	bw.write(0x2f, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if hasAlpha {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3) // version
+
+	// transforms
+	// (the decoder inverts them in reverse order)
+	bw.write(1, 1)
+	bw.write(transformSubtractGreen, 2)
+	bw.write(1, 1)
+	bw.write(transformPredictor, 2)
+	bw.write(predictorBits-2, 3)
+	writeImage(bw, tiles, (width+1<<predictorBits-1)>>predictorBits, false)
+	bw.write(0, 1)
+
+	// main image
+	writeImage(bw, residuals, width, true)
+
+	data := bw.flush()
+
+	return writeRIFF(w, data)
+}
+
+func writeRIFF(w io.Writer, data []byte) error {
+	chunkSize := len(data)
+	padding := chunkSize & 1
+
+	header := make([]byte, 20)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(4+8+chunkSize+padding))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8L")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(chunkSize))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	if padding > 0 {
+		if _, err := w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+// Transforms
+// -------------------------------------------------------------------
+
+func subtractGreen(pix []byte) {
+	for p := 0; p < len(pix); p += 4 {
+		pix[p+0] -= pix[p+1]
+		pix[p+2] -= pix[p+1]
+	}
+}
+
+// predict chooses a predictor mode for each tile and returns
+// the encoded tiles image together with the prediction residuals.
+func predict(pix []byte, width, height int) ([]byte, []byte) {
+	tileSize := 1 << predictorBits
+	tilesW := (width + tileSize - 1) >> predictorBits
+	tilesH := (height + tileSize - 1) >> predictorBits
+
+	modes := make([]uint8, tilesW*tilesH)
+	for ty := 0; ty < tilesH; ty++ {
+		for tx := 0; tx < tilesW; tx++ {
+			bestMode, bestCost := predictorModes[0], -1
+			for _, mode := range predictorModes {
+				cost := 0
+				for y := ty * tileSize; y < min((ty+1)*tileSize, height); y++ {
+					for x := tx * tileSize; x < min((tx+1)*tileSize, width); x++ {
+						p := 4 * (y*width + x)
+						pred := predictPixel(pix, p, width, x, y, mode)
+						for c := 0; c < 4; c++ {
+							cost += absInt(int(int8(pix[p+c] - pred[c])))
+						}
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					bestMode, bestCost = mode, cost
+				}
+			}
+			modes[ty*tilesW+tx] = bestMode
+		}
+	}
+
+	residuals := make([]byte, len(pix))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := 4 * (y*width + x)
+			mode := modes[(y>>predictorBits)*tilesW+(x>>predictorBits)]
+			pred := predictPixel(pix, p, width, x, y, mode)
+			for c := 0; c < 4; c++ {
+				residuals[p+c] = pix[p+c] - pred[c]
+			}
+		}
+	}
+
+	// the tile mode is stored in the green channel
+	tiles := make([]byte, 4*len(modes))
+	for i, mode := range modes {
+		tiles[4*i+1] = mode
+		tiles[4*i+3] = 0xff
+	}
+
+	return tiles, residuals
+}
+
+// predictPixel returns the predicted value for the pixel at offset p
+// following the edge cases rules of the VP8L predictor transform.
+func predictPixel(pix []byte, p int, width int, x int, y int, mode uint8) [4]byte {
+	switch {
+	case x == 0 && y == 0:
+		return [4]byte{0, 0, 0, 0xff}
+	case y == 0:
+		mode = 1
+	case x == 0:
+		mode = 2
+	}
+
+	top := p - 4*width
+
+	var result [4]byte
+
+	for c := 0; c < 4; c++ {
+		switch mode {
+		case 1: // L
+			result[c] = pix[p-4+c]
+		case 2: // T
+			result[c] = pix[top+c]
+		case 12: // ClampAddSubtractFull(L, T, TL)
+			result[c] = clamp(int(pix[p-4+c]) + int(pix[top+c]) - int(pix[top-4+c]))
+		case 13: // ClampAddSubtractHalf(Average2(L, T), TL)
+			a := int(avg2(pix[p-4+c], pix[top+c]))
+			result[c] = clamp(a + (a-int(pix[top-4+c]))/2)
+		}
+	}
+
+	if mode == 11 { // Select(L, T, TL)
+		var l, t int
+		for c := 0; c < 4; c++ {
+			l += absInt(int(pix[top-4+c]) - int(pix[top+c]))
+			t += absInt(int(pix[top-4+c]) - int(pix[p-4+c]))
+		}
+		if l < t {
+			copy(result[:], pix[p-4:p])
+		} else {
+			copy(result[:], pix[top:top+4])
+		}
+	}
+
+	return result
+}
+
+func avg2(a, b byte) byte {
+	return byte((int(a) + int(b)) / 2)
+}
+
+func clamp(v int) byte {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return byte(v)
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// -------------------------------------------------------------------
+// Entropy coding
+// -------------------------------------------------------------------
+
+// writeImage writes the entropy coded pixels (in RGBA byte order)
+// using a single prefix codes group.
+//
+// Repeating pixels are encoded as backward references
+// to the left or the top pixel.
+func writeImage(bw *bitWriter, pix []byte, width int, topLevel bool) {
+	bw.write(0, 1) // no color cache
+	if topLevel {
+		bw.write(0, 1) // no meta prefix codes
+	}
+
+	type token struct {
+		pixel  int // pixel index for literals
+		length int // non-zero for backward references
+		dist   int // distance plane code
+	}
+
+	n := len(pix) / 4
+
+	same := func(a, b int) bool {
+		return pix[4*a] == pix[4*b] && pix[4*a+1] == pix[4*b+1] && pix[4*a+2] == pix[4*b+2] && pix[4*a+3] == pix[4*b+3]
+	}
+
+	var tokens []token
+	for i := 0; i < n; {
+		var leftRun, topRun int
+		if i > 0 {
+			for i+leftRun < n && leftRun < maxBackwardLength && same(i+leftRun, i+leftRun-1) {
+				leftRun++
+			}
+		}
+		if i >= width {
+			for i+topRun < n && topRun < maxBackwardLength && same(i+topRun, i+topRun-width) {
+				topRun++
+			}
+		}
+
+		switch {
+		case leftRun >= minBackwardLength && leftRun >= topRun:
+			tokens = append(tokens, token{length: leftRun, dist: planeCodeLeft})
+			i += leftRun
+		case topRun >= minBackwardLength:
+			tokens = append(tokens, token{length: topRun, dist: planeCodeTop})
+			i += topRun
+		default:
+			tokens = append(tokens, token{pixel: i})
+			i++
+		}
+	}
+
+	// green, red, blue, alpha and distance histograms
+	histograms := [5][]int{
+		make([]int, nLiteralCodes+nLengthCodes),
+		make([]int, nLiteralCodes),
+		make([]int, nLiteralCodes),
+		make([]int, nLiteralCodes),
+		make([]int, nDistanceCodes),
+	}
+	for _, t := range tokens {
+		if t.length > 0 {
+			lengthPrefix, _, _ := prefixEncode(t.length)
+			distPrefix, _, _ := prefixEncode(t.dist)
+			histograms[0][nLiteralCodes+lengthPrefix]++
+			histograms[4][distPrefix]++
+			continue
+		}
+
+		p := 4 * t.pixel
+		histograms[0][pix[p+1]]++
+		histograms[1][pix[p+0]]++
+		histograms[2][pix[p+2]]++
+		histograms[3][pix[p+3]]++
+	}
+
+	// the unused codes must still be valid
+	for _, h := range histograms {
+		if !slices.ContainsFunc(h, func(c int) bool { return c > 0 }) {
+			h[0] = 1
+		}
+	}
+
+	var codes [5]*prefixCode
+	for i, h := range histograms {
+		codes[i] = writePrefixCode(bw, h)
+	}
+
+	for _, t := range tokens {
+		if t.length > 0 {
+			lengthPrefix, lengthExtraBits, lengthExtra := prefixEncode(t.length)
+			codes[0].write(bw, nLiteralCodes+lengthPrefix)
+			bw.write(lengthExtra, lengthExtraBits)
+
+			distPrefix, distExtraBits, distExtra := prefixEncode(t.dist)
+			codes[4].write(bw, distPrefix)
+			bw.write(distExtra, distExtraBits)
+			continue
+		}
+
+		p := 4 * t.pixel
+		codes[0].write(bw, int(pix[p+1]))
+		codes[1].write(bw, int(pix[p+0]))
+		codes[2].write(bw, int(pix[p+2]))
+		codes[3].write(bw, int(pix[p+3]))
+	}
+}
+
+// prefixEncode returns the LZ77 prefix symbol and its extra bits for v >= 1.
+func prefixEncode(v int) (symbol int, extraBits uint, extra uint32) {
+	d := v - 1
+	if d < 4 {
+		return d, 0, 0
+	}
+
+	highest := bits.Len(uint(d)) - 1
+	second := (d >> (highest - 1)) & 1
+	extraBits = uint(highest - 1)
+
+	return 2*highest + second, extraBits, uint32(d) & (1<<extraBits - 1)
+}
+
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32
+
+	// single indicates that the code has only one used symbol
+	// and therefore no bits are written for it
+	single bool
+}
+
+func (c *prefixCode) write(bw *bitWriter, symbol int) {
+	if !c.single {
+		bw.write(c.codes[symbol], uint(c.lengths[symbol]))
+	}
+}
+
+// writePrefixCode writes the prefix code for the histogram
+// and returns the code that should be used to write its symbols.
+func writePrefixCode(bw *bitWriter, histogram []int) *prefixCode {
+	code := newPrefixCode(histogram, maxCodeLength)
+
+	// single symbol - use the shorter "simple" code
+	if code.single {
+		var symbol int
+		for i, l := range code.lengths {
+			if l > 0 {
+				symbol = i
+				break
+			}
+		}
+
+		bw.write(1, 1) // simple code
+		bw.write(0, 1) // 1 symbol
+		if symbol < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(symbol), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(symbol), 8)
+		}
+
+		return code
+	}
+
+	// run-length encode the code lengths using the code length alphabet
+	// (only the zero repeats are used for simplicity)
+	type token struct {
+		symbol    int
+		extraBits uint
+		extra     uint32
+	}
+	var tokens []token
+	for i := 0; i < len(code.lengths); {
+		if code.lengths[i] != 0 {
+			tokens = append(tokens, token{symbol: int(code.lengths[i])})
+			i++
+			continue
+		}
+
+		run := 1
+		for i+run < len(code.lengths) && code.lengths[i+run] == 0 && run < 138 {
+			run++
+		}
+
+		switch {
+		case run < 3:
+			for j := 0; j < run; j++ {
+				tokens = append(tokens, token{symbol: 0})
+			}
+		case run <= 10:
+			tokens = append(tokens, token{symbol: 17, extraBits: 3, extra: uint32(run - 3)})
+		default:
+			tokens = append(tokens, token{symbol: 18, extraBits: 7, extra: uint32(run - 11)})
+		}
+		i += run
+	}
+
+	clHistogram := make([]int, len(codeLengthCodeOrder))
+	for _, t := range tokens {
+		clHistogram[t.symbol]++
+	}
+	clCode := newPrefixCode(clHistogram, maxCodeLengthCodeLength)
+
+	numCodes := len(codeLengthCodeOrder)
+	for numCodes > 4 && clCode.lengths[codeLengthCodeOrder[numCodes-1]] == 0 {
+		numCodes--
+	}
+
+	bw.write(0, 1) // normal code
+	bw.write(uint32(numCodes-4), 4)
+	for i := 0; i < numCodes; i++ {
+		bw.write(uint32(clCode.lengths[codeLengthCodeOrder[i]]), 3)
+	}
+	bw.write(0, 1) // max_symbol = alphabet size
+
+	for _, t := range tokens {
+		clCode.write(bw, t.symbol)
+		if t.extraBits > 0 {
+			bw.write(t.extra, t.extraBits)
+		}
+	}
+
+	return code
+}
+
+// newPrefixCode builds a canonical prefix code for the histogram.
+//
+// Note that the decoders treat a code with a single used symbol
+// as zero bits code (its length is still stored as non-zero).
+func newPrefixCode(histogram []int, maxLength int) *prefixCode {
+	code := &prefixCode{
+		lengths: buildCodeLengths(histogram, maxLength),
+		codes:   make([]uint32, len(histogram)),
+	}
+
+	var blCount [maxCodeLength + 1]uint32
+	var used int
+	for _, l := range code.lengths {
+		if l > 0 {
+			blCount[l]++
+			used++
+		}
+	}
+
+	if used <= 1 {
+		code.single = true
+		return code
+	}
+
+	var nextCode [maxCodeLength + 1]uint32
+	var c uint32
+	for bits := 1; bits <= maxCodeLength; bits++ {
+		c = (c + blCount[bits-1]) << 1
+		nextCode[bits] = c
+	}
+
+	for symbol, l := range code.lengths {
+		if l == 0 {
+			continue
+		}
+		// the codes are read MSB first from the LSB first bit stream
+		code.codes[symbol] = reverse(nextCode[l], uint(l))
+		nextCode[l]++
+	}
+
+	return code
+}
+
+func reverse(code uint32, n uint) uint32 {
+	var r uint32
+	for i := uint(0); i < n; i++ {
+		r = r<<1 | (code>>i)&1
+	}
+	return r
+}
+
+// buildCodeLengths returns the Huffman code lengths for the histogram
+// limited to maxLength bits.
+func buildCodeLengths(histogram []int, maxLength int) []uint8 {
+	lengths := make([]uint8, len(histogram))
+
+	var used []int
+	for symbol, count := range histogram {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	switch len(used) {
+	case 0:
+		return lengths
+	case 1:
+		lengths[used[0]] = 1
+		return lengths
+	}
+
+	// flatten the smallest counts until the tree fits the length limit
+	for minCount := 1; ; minCount *= 2 {
+		type node struct {
+			count  int
+			parent int
+		}
+
+		nodes := make([]node, 0, 2*len(used))
+		for _, symbol := range used {
+			nodes = append(nodes, node{count: max(histogram[symbol], minCount), parent: -1})
+		}
+
+		active := make([]int, len(used))
+		for i := range active {
+			active[i] = i
+		}
+
+		for len(active) > 1 {
+			sort.SliceStable(active, func(i, j int) bool {
+				return nodes[active[i]].count < nodes[active[j]].count
+			})
+
+			a, b := active[0], active[1]
+			nodes = append(nodes, node{count: nodes[a].count + nodes[b].count, parent: -1})
+			parent := len(nodes) - 1
+			nodes[a].parent = parent
+			nodes[b].parent = parent
+
+			active = append(active[2:], parent)
+		}
+
+		tooLong := false
+		for i, symbol := range used {
+			depth := 0
+			for n := i; nodes[n].parent >= 0; n = nodes[n].parent {
+				depth++
+			}
+			if depth > maxLength {
+				tooLong = true
+				break
+			}
+			lengths[symbol] = uint8(depth)
+		}
+
+		if !tooLong {
+			return lengths
+		}
+	}
+}
+
+// -------------------------------------------------------------------
+// Bit writer
+// -------------------------------------------------------------------
+
+// bitWriter writes the bits in LSB first order.
+type bitWriter struct {
+	buf   []byte
+	bits  uint64
+	nBits uint
+}
+
+func (w *bitWriter) write(v uint32, n uint) {
+	w.bits |= uint64(v&(1<<n-1)) << w.nBits
+	w.nBits += n
+	for w.nBits >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.nBits -= 8
+	}
+}
+
+func (w *bitWriter) flush() []byte {
+	if w.nBits > 0 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits = 0
+		w.nBits = 0
+	}
+	return w.buf
+}
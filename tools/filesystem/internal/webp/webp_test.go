@@ -0,0 +1,158 @@
+package webp_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/filesystem/internal/webp"
+	xwebp "golang.org/x/image/webp"
+)
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+
+	scenarios := []struct {
+		name string
+		img  func() image.Image
+	}{
+		{
+			"1x1",
+			func() image.Image {
+				img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+				img.Set(0, 0, color.NRGBA{10, 20, 30, 255})
+				return img
+			},
+		},
+		{
+			"solid color",
+			func() image.Image {
+				img := image.NewNRGBA(image.Rect(0, 0, 33, 17))
+				for y := 0; y < 17; y++ {
+					for x := 0; x < 33; x++ {
+						img.Set(x, y, color.NRGBA{200, 100, 50, 255})
+					}
+				}
+				return img
+			},
+		},
+		{
+			"gradient with transparency",
+			func() image.Image {
+				img := image.NewNRGBA(image.Rect(0, 0, 70, 45))
+				for y := 0; y < 45; y++ {
+					for x := 0; x < 70; x++ {
+						img.Set(x, y, color.NRGBA{uint8(x * 3), uint8(y * 5), uint8(x + y), uint8(255 - y)})
+					}
+				}
+				return img
+			},
+		},
+		{
+			"large solid color (multiple max length backward references)",
+			func() image.Image {
+				img := image.NewNRGBA(image.Rect(0, 0, 100, 100))
+				for i := 0; i < len(img.Pix); i += 4 {
+					img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 1, 2, 3, 4
+				}
+				return img
+			},
+		},
+		{
+			"vertical stripes",
+			func() image.Image {
+				img := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+				for y := 0; y < 30; y++ {
+					for x := 0; x < 40; x++ {
+						img.Set(x, y, color.NRGBA{uint8(x * x), uint8(x * 7), uint8(x), 255})
+					}
+				}
+				return img
+			},
+		},
+		{
+			"random noise",
+			func() image.Image {
+				img := image.NewNRGBA(image.Rect(0, 0, 50, 40))
+				rnd.Read(img.Pix)
+				return img
+			},
+		},
+		{
+			"non-zero bounds and non-NRGBA image",
+			func() image.Image {
+				img := image.NewRGBA(image.Rect(5, 5, 40, 30))
+				for y := 5; y < 30; y++ {
+					for x := 5; x < 40; x++ {
+						img.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+					}
+				}
+				return img
+			},
+		},
+		{
+			"gray image",
+			func() image.Image {
+				img := image.NewGray(image.Rect(0, 0, 20, 20))
+				rnd.Read(img.Pix)
+				return img
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			original := s.img()
+
+			var buf bytes.Buffer
+			if err := webp.Encode(&buf, original); err != nil {
+				t.Fatal(err)
+			}
+
+			decoded, err := xwebp.Decode(&buf)
+			if err != nil {
+				t.Fatalf("Failed to decode the encoded image: %v", err)
+			}
+
+			ob := original.Bounds()
+			db := decoded.Bounds()
+			if db.Dx() != ob.Dx() || db.Dy() != ob.Dy() {
+				t.Fatalf("Expected %dx%d image, got %dx%d", ob.Dx(), ob.Dy(), db.Dx(), db.Dy())
+			}
+
+			for y := 0; y < ob.Dy(); y++ {
+				for x := 0; x < ob.Dx(); x++ {
+					expected := color.NRGBAModel.Convert(original.At(ob.Min.X+x, ob.Min.Y+y))
+					actual := color.NRGBAModel.Convert(decoded.At(db.Min.X+x, db.Min.Y+y))
+					if expected != actual {
+						t.Fatalf("Pixel (%d,%d) mismatch: expected %v, got %v", x, y, expected, actual)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeInvalidSize(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name string
+		img  image.Image
+	}{
+		{"empty", image.NewNRGBA(image.Rect(0, 0, 0, 0))},
+		{"too large", image.NewGray(image.Rect(0, 0, 1<<14+1, 1))},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if err := webp.Encode(&bytes.Buffer{}, s.img); err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+		})
+	}
+}
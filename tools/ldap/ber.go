@@ -0,0 +1,301 @@
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BER element classes.
+const (
+	ClassUniversal   byte = 0x00
+	ClassApplication byte = 0x40
+	ClassContext     byte = 0x80
+)
+
+// Universal BER tags used by the LDAP protocol.
+const (
+	TagBoolean     = 0x01
+	TagInteger     = 0x02
+	TagOctetString = 0x04
+	TagNull        = 0x05
+	TagEnumerated  = 0x0a
+	TagSequence    = 0x10
+	TagSet         = 0x11
+)
+
+// MaxPacketSize is the max allowed size of a single decoded BER element (10MB).
+const MaxPacketSize = 10 << 20
+
+// Packet defines a single BER encoded element (see ITU-T X.690).
+//
+// Only the subset of the rules that is required by the LDAP protocol
+// is supported (definite lengths and tag numbers lower than 31).
+type Packet struct {
+	Class       byte
+	Constructed bool
+	Tag         int
+
+	// Value is the raw content of a primitive element.
+	Value []byte
+
+	// Children are the nested elements of a constructed element.
+	Children []*Packet
+}
+
+// NewSequence creates a new constructed packet with the provided children.
+func NewSequence(class byte, tag int, children ...*Packet) *Packet {
+	return &Packet{Class: class, Constructed: true, Tag: tag, Children: children}
+}
+
+// NewString creates a new primitive packet with the provided string content.
+func NewString(class byte, tag int, value string) *Packet {
+	return &Packet{Class: class, Tag: tag, Value: []byte(value)}
+}
+
+// NewInteger creates a new primitive packet with the two's complement
+// encoded integer content (used also for the enumerated values).
+func NewInteger(class byte, tag int, value int64) *Packet {
+	// find the minimal number of bytes
+	size := 1
+	for v := value; v > 127 || v < -128; v >>= 8 {
+		size++
+	}
+
+	content := make([]byte, size)
+	for i := size - 1; i >= 0; i-- {
+		content[i] = byte(value)
+		value >>= 8
+	}
+
+	return &Packet{Class: class, Tag: tag, Value: content}
+}
+
+// NewBool creates a new primitive packet with the provided boolean content.
+func NewBool(class byte, tag int, value bool) *Packet {
+	content := []byte{0x00}
+	if value {
+		content[0] = 0xff
+	}
+
+	return &Packet{Class: class, Tag: tag, Value: content}
+}
+
+// Is reports whether the packet has the specified class and tag.
+func (p *Packet) Is(class byte, tag int) bool {
+	return p != nil && p.Class == class && p.Tag == tag
+}
+
+// Int decodes the packet content as two's complement integer.
+func (p *Packet) Int() (int64, error) {
+	if len(p.Value) == 0 || len(p.Value) > 8 {
+		return 0, errors.New("invalid integer length")
+	}
+
+	// sign extend
+	var result int64
+	if p.Value[0]&0x80 != 0 {
+		result = -1
+	}
+
+	for _, b := range p.Value {
+		result = result<<8 | int64(b)
+	}
+
+	return result, nil
+}
+
+// Bool decodes the packet content as boolean.
+func (p *Packet) Bool() (bool, error) {
+	if len(p.Value) != 1 {
+		return false, errors.New("invalid boolean length")
+	}
+
+	return p.Value[0] != 0x00, nil
+}
+
+// String returns the packet content as string.
+func (p *Packet) String() string {
+	return string(p.Value)
+}
+
+// Bytes returns the BER encoded packet.
+func (p *Packet) Bytes() []byte {
+	content := p.Value
+	if p.Constructed {
+		content = nil
+		for _, child := range p.Children {
+			content = append(content, child.Bytes()...)
+		}
+	}
+
+	identifier := p.Class | byte(p.Tag&0x1f)
+	if p.Constructed {
+		identifier |= 0x20
+	}
+
+	result := make([]byte, 0, len(content)+6)
+	result = append(result, identifier)
+	result = append(result, encodeLength(len(content))...)
+	result = append(result, content...)
+
+	return result
+}
+
+func encodeLength(length int) []byte {
+	if length < 0x80 {
+		return []byte{byte(length)}
+	}
+
+	var result []byte
+	for l := length; l > 0; l >>= 8 {
+		result = append([]byte{byte(l)}, result...)
+	}
+
+	return append([]byte{0x80 | byte(len(result))}, result...)
+}
+
+// ReadPacket reads and decodes a single BER element from r.
+//
+// Note that r is read byte by byte so consider wrapping it
+// with [bufio.Reader] when reading from a network connection.
+func ReadPacket(r io.Reader) (*Packet, error) {
+	identifier, err := readByte(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if identifier&0x1f == 0x1f {
+		return nil, errors.New("high tag numbers are not supported")
+	}
+
+	lengthByte, err := readByte(r)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	length := int(lengthByte)
+	if lengthByte&0x80 != 0 {
+		n := int(lengthByte & 0x7f)
+		if n == 0 {
+			return nil, errors.New("indefinite lengths are not supported")
+		}
+		if n > 4 {
+			return nil, fmt.Errorf("too large length size %d", n)
+		}
+
+		length = 0
+		for range n {
+			b, err := readByte(r)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			length = length<<8 | int(b)
+		}
+	}
+
+	if length > MaxPacketSize {
+		return nil, fmt.Errorf("packet length %d exceeds the max allowed size", length)
+	}
+
+	// fail early for nested elements exceeding their parent content
+	if br, ok := r.(*byteReader); ok && length > len(br.data)-br.pos {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	return decodePacket(identifier, content)
+}
+
+// DecodePacket decodes a single BER element from data.
+func DecodePacket(data []byte) (*Packet, error) {
+	r := &byteReader{data: data}
+
+	p, err := ReadPacket(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.pos != len(data) {
+		return nil, errors.New("unexpected trailing data")
+	}
+
+	return p, nil
+}
+
+func decodePacket(identifier byte, content []byte) (*Packet, error) {
+	p := &Packet{
+		Class:       identifier & 0xc0,
+		Constructed: identifier&0x20 != 0,
+		Tag:         int(identifier & 0x1f),
+	}
+
+	if !p.Constructed {
+		p.Value = content
+		return p, nil
+	}
+
+	r := &byteReader{data: content}
+	for r.pos < len(content) {
+		child, err := ReadPacket(r)
+		if err != nil {
+			return nil, err
+		}
+		p.Children = append(p.Children, child)
+	}
+
+	return p, nil
+}
+
+func readByte(r io.Reader) (byte, error) {
+	if br, ok := r.(io.ByteReader); ok {
+		return br.ReadByte()
+	}
+
+	var b [1]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+
+	return b[0], nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// byteReader is a minimal [io.Reader] and [io.ByteReader] over a byte slice
+// (similar to [bytes.Reader] but with accessible read position).
+type byteReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *byteReader) Read(b []byte) (int, error) {
+	if r.pos >= len(r.data) {
+		return 0, io.EOF
+	}
+
+	n := copy(b, r.data[r.pos:])
+	r.pos += n
+
+	return n, nil
+}
+
+func (r *byteReader) ReadByte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, io.EOF
+	}
+
+	b := r.data[r.pos]
+	r.pos++
+
+	return b, nil
+}
@@ -0,0 +1,127 @@
+package ldap_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/ldap"
+)
+
+func TestPacketBytes(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		packet   *ldap.Packet
+		expected string
+	}{
+		{"integer 0", ldap.NewInteger(ldap.ClassUniversal, ldap.TagInteger, 0), "020100"},
+		{"integer 127", ldap.NewInteger(ldap.ClassUniversal, ldap.TagInteger, 127), "02017f"},
+		{"integer 128", ldap.NewInteger(ldap.ClassUniversal, ldap.TagInteger, 128), "02020080"},
+		{"integer 256", ldap.NewInteger(ldap.ClassUniversal, ldap.TagInteger, 256), "02020100"},
+		{"integer -1", ldap.NewInteger(ldap.ClassUniversal, ldap.TagInteger, -1), "0201ff"},
+		{"integer -129", ldap.NewInteger(ldap.ClassUniversal, ldap.TagInteger, -129), "0202ff7f"},
+		{"enumerated", ldap.NewInteger(ldap.ClassUniversal, ldap.TagEnumerated, 2), "0a0102"},
+		{"bool true", ldap.NewBool(ldap.ClassUniversal, ldap.TagBoolean, true), "0101ff"},
+		{"bool false", ldap.NewBool(ldap.ClassUniversal, ldap.TagBoolean, false), "010100"},
+		{"string", ldap.NewString(ldap.ClassUniversal, ldap.TagOctetString, "abc"), "0403616263"},
+		{"context string", ldap.NewString(ldap.ClassContext, 0, "abc"), "8003616263"},
+		{
+			"sequence",
+			ldap.NewSequence(ldap.ClassUniversal, ldap.TagSequence,
+				ldap.NewInteger(ldap.ClassUniversal, ldap.TagInteger, 1),
+				ldap.NewSequence(ldap.ClassApplication, ldap.ApplicationBindRequest),
+			),
+			"30050201016000",
+		},
+		{
+			"long form length",
+			ldap.NewString(ldap.ClassUniversal, ldap.TagOctetString, strings.Repeat("a", 200)),
+			"0481c8" + strings.Repeat("61", 200),
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := hex.EncodeToString(s.packet.Bytes())
+			if result != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, result)
+			}
+
+			// decode back
+			decoded, err := ldap.DecodePacket(s.packet.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(decoded.Bytes(), s.packet.Bytes()) {
+				t.Fatalf("Expected the decoded packet to be encoded as\n%x\ngot\n%x", s.packet.Bytes(), decoded.Bytes())
+			}
+		})
+	}
+}
+
+func TestPacketValues(t *testing.T) {
+	for _, v := range []int64{0, 1, -1, 127, 128, -128, -129, 65535, 1 << 40, -(1 << 40)} {
+		p, err := ldap.DecodePacket(ldap.NewInteger(ldap.ClassUniversal, ldap.TagInteger, v).Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := p.Int()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != v {
+			t.Fatalf("Expected integer %d, got %d", v, result)
+		}
+	}
+
+	for _, v := range []bool{true, false} {
+		result, err := ldap.NewBool(ldap.ClassUniversal, ldap.TagBoolean, v).Bool()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != v {
+			t.Fatalf("Expected bool %v, got %v", v, result)
+		}
+	}
+
+	if _, err := (&ldap.Packet{}).Int(); err == nil {
+		t.Fatal("Expected empty integer error")
+	}
+
+	if _, err := (&ldap.Packet{Value: []byte{1, 2}}).Bool(); err == nil {
+		t.Fatal("Expected invalid bool error")
+	}
+}
+
+func TestDecodePacketErrors(t *testing.T) {
+	scenarios := []string{
+		"",
+		"04",         // missing length
+		"0405616263", // missing content
+		"0480",       // indefinite length
+		"1f0100",     // high tag number
+		"0485ffffffffff",
+		"048400ffffff", // exceeding max size
+		"3003040561",   // child exceeding the parent content
+		"040161ff",     // trailing data
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s), func(t *testing.T) {
+			data, err := hex.DecodeString(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := ldap.DecodePacket(data); err == nil {
+				t.Fatal("Expected decode error")
+			}
+		})
+	}
+}
@@ -0,0 +1,471 @@
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LDAP protocol operation tags (RFC 4511 section 4.2).
+const (
+	ApplicationBindRequest           = 0
+	ApplicationBindResponse          = 1
+	ApplicationUnbindRequest         = 2
+	ApplicationSearchRequest         = 3
+	ApplicationSearchResultEntry     = 4
+	ApplicationSearchResultDone      = 5
+	ApplicationSearchResultReference = 19
+	ApplicationExtendedRequest       = 23
+	ApplicationExtendedResponse      = 24
+)
+
+// Common LDAP result codes (RFC 4511 appendix A).
+const (
+	ResultSuccess            = 0
+	ResultOperationsError    = 1
+	ResultProtocolError      = 2
+	ResultSizeLimitExceeded  = 4
+	ResultNoSuchObject       = 32
+	ResultInvalidCredentials = 49
+	ResultInsufficientAccess = 50
+	ResultUnavailable        = 52
+	ResultUnwillingToPerform = 53
+	ResultOther              = 80
+)
+
+// Search scopes.
+const (
+	ScopeBaseObject   = 0
+	ScopeSingleLevel  = 1
+	ScopeWholeSubtree = 2
+)
+
+// StartTLSOID is the StartTLS extended operation name.
+const StartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// DefaultTimeout is the default timeout of a single LDAP operation.
+const DefaultTimeout = 10 * time.Second
+
+// ErrEmptyPassword is returned when trying to bind with an empty password.
+//
+// Most LDAP servers treat such binds as successful unauthenticated
+// (aka. anonymous) binds so they are not allowed by the client.
+var ErrEmptyPassword = errors.New("the bind password must not be empty")
+
+// Error defines a non-successful LDAP operation result.
+type Error struct {
+	ResultCode int
+	Message    string
+}
+
+// Error implements the [error] interface.
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("ldap: result code %d", e.ResultCode)
+	}
+	return fmt.Sprintf("ldap: result code %d: %s", e.ResultCode, e.Message)
+}
+
+// IsErrorWithCode reports whether err is an LDAP [Error] with the specified result code.
+func IsErrorWithCode(err error, code int) bool {
+	var ldapErr *Error
+	return errors.As(err, &ldapErr) && ldapErr.ResultCode == code
+}
+
+// Entry defines a single search result entry.
+type Entry struct {
+	DN         string
+	Attributes []*EntryAttribute
+}
+
+// EntryAttribute defines a single search result entry attribute.
+type EntryAttribute struct {
+	Name   string
+	Values []string
+}
+
+// Values returns the values of the specified attribute
+// (the attribute name is case-insensitive).
+func (e *Entry) Values(name string) []string {
+	for _, attr := range e.Attributes {
+		if strings.EqualFold(attr.Name, name) {
+			return attr.Values
+		}
+	}
+	return nil
+}
+
+// Value returns the first value of the specified attribute
+// (the attribute name is case-insensitive).
+func (e *Entry) Value(name string) string {
+	values := e.Values(name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// SearchRequest defines the parameters of a search operation.
+type SearchRequest struct {
+	// BaseDN is the DN of the entry relative to which the search is performed.
+	BaseDN string
+
+	// Scope is the search scope (default to ScopeBaseObject).
+	Scope int
+
+	// Filter is the string representation of the search filter (e.g. "(uid=john)").
+	Filter string
+
+	// Attributes is an optional list with the attributes to return.
+	//
+	// Leave it empty to return all user attributes.
+	Attributes []string
+
+	// SizeLimit is the max number of entries to return (0 means no client limit).
+	SizeLimit int
+}
+
+// Client is a minimal synchronous LDAPv3 client
+// that supports only simple binds and search operations.
+type Client struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	host    string
+	timeout time.Duration
+
+	mu        sync.Mutex
+	messageId int64
+}
+
+// Dial connects to the LDAP server with the specified url.
+//
+// The url scheme must be "ldap" (default port 389) or "ldaps" (default port 636).
+// The optional tlsConfig is used for the "ldaps" connections and for [Client.StartTLS].
+func Dial(ctx context.Context, rawURL string, tlsConfig *tls.Config) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return nil, errors.New("missing LDAP server host")
+	}
+
+	port := u.Port()
+
+	var conn net.Conn
+	switch u.Scheme {
+	case "ldap":
+		if port == "" {
+			port = "389"
+		}
+
+		dialer := &net.Dialer{}
+		conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	case "ldaps":
+		if port == "" {
+			port = "636"
+		}
+
+		dialer := &tls.Dialer{Config: normalizeTLSConfig(tlsConfig, host)}
+		conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	default:
+		return nil, fmt.Errorf("unsupported LDAP url scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	client := NewClient(conn)
+	client.host = host
+
+	return client, nil
+}
+
+// NewClient creates a new LDAP client from an already established connection.
+func NewClient(conn net.Conn) *Client {
+	return &Client{
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		timeout: DefaultTimeout,
+	}
+}
+
+// SetTimeout changes the timeout of the client operations.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.timeout = timeout
+}
+
+// Close sends an unbind request and closes the underlying connection.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the unbind request doesn't have a response so just ignore the error
+	_ = c.write(&Packet{Class: ClassApplication, Tag: ApplicationUnbindRequest})
+
+	return c.conn.Close()
+}
+
+// StartTLS upgrades the current plain connection to TLS.
+func (c *Client) StartTLS(tlsConfig *tls.Config) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.conn.(*tls.Conn); ok {
+		return errors.New("the connection is already using TLS")
+	}
+
+	err := c.request(
+		NewSequence(ClassApplication, ApplicationExtendedRequest,
+			NewString(ClassContext, 0, StartTLSOID),
+		),
+		ApplicationExtendedResponse,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	tlsConn := tls.Client(c.conn, normalizeTLSConfig(tlsConfig, c.host))
+	tlsConn.SetDeadline(time.Now().Add(c.timeout))
+	if err := tlsConn.Handshake(); err != nil {
+		return err
+	}
+
+	c.conn = tlsConn
+	c.reader = bufio.NewReader(tlsConn)
+
+	return nil
+}
+
+// Bind authenticates the connection with the specified DN and password (simple bind).
+//
+// Returns [ErrEmptyPassword] if password is empty.
+func (c *Client) Bind(dn string, password string) error {
+	if password == "" {
+		return ErrEmptyPassword
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.request(
+		NewSequence(ClassApplication, ApplicationBindRequest,
+			NewInteger(ClassUniversal, TagInteger, 3),
+			NewString(ClassUniversal, TagOctetString, dn),
+			NewString(ClassContext, 0, password),
+		),
+		ApplicationBindResponse,
+		nil,
+	)
+}
+
+// Search performs a search operation and returns the found entries.
+//
+// Search result references are ignored.
+func (c *Client) Search(req SearchRequest) ([]*Entry, error) {
+	filter, err := CompileFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := NewSequence(ClassUniversal, TagSequence)
+	for _, attr := range req.Attributes {
+		attributes.Children = append(attributes.Children, NewString(ClassUniversal, TagOctetString, attr))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var entries []*Entry
+
+	err = c.request(
+		NewSequence(ClassApplication, ApplicationSearchRequest,
+			NewString(ClassUniversal, TagOctetString, req.BaseDN),
+			NewInteger(ClassUniversal, TagEnumerated, int64(req.Scope)),
+			NewInteger(ClassUniversal, TagEnumerated, 0), // neverDerefAliases
+			NewInteger(ClassUniversal, TagInteger, int64(req.SizeLimit)),
+			NewInteger(ClassUniversal, TagInteger, int64(c.timeout/time.Second)),
+			NewBool(ClassUniversal, TagBoolean, false),
+			filter,
+			attributes,
+		),
+		ApplicationSearchResultDone,
+		func(op *Packet) error {
+			switch op.Tag {
+			case ApplicationSearchResultEntry:
+				entry, err := decodeEntry(op)
+				if err != nil {
+					return err
+				}
+				entries = append(entries, entry)
+			case ApplicationSearchResultReference:
+				// not supported
+			default:
+				return fmt.Errorf("unexpected search response operation %d", op.Tag)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// request sends the provided operation and waits for its response.
+//
+// Any intermediate response operations (e.g. search entries) are passed to onIntermediate.
+func (c *Client) request(op *Packet, responseTag int, onIntermediate func(op *Packet) error) error {
+	c.messageId++
+	id := c.messageId
+
+	err := c.write(NewSequence(ClassUniversal, TagSequence,
+		NewInteger(ClassUniversal, TagInteger, id),
+		op,
+	))
+	if err != nil {
+		return err
+	}
+
+	for {
+		msgId, responseOp, err := c.read()
+		if err != nil {
+			return err
+		}
+
+		if msgId != id {
+			// unsolicited notification (e.g. notice of disconnection)
+			if msgId == 0 {
+				if err := decodeResult(responseOp); err != nil {
+					return err
+				}
+				return errors.New("unexpected unsolicited LDAP notification")
+			}
+			return fmt.Errorf("unexpected LDAP response message id %d", msgId)
+		}
+
+		if responseOp.Tag == responseTag {
+			return decodeResult(responseOp)
+		}
+
+		if onIntermediate == nil {
+			return fmt.Errorf("unexpected LDAP response operation %d", responseOp.Tag)
+		}
+
+		if err := onIntermediate(responseOp); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) write(message *Packet) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return err
+	}
+
+	_, err := c.conn.Write(message.Bytes())
+
+	return err
+}
+
+func (c *Client) read() (int64, *Packet, error) {
+	if err := c.conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, nil, err
+	}
+
+	message, err := ReadPacket(c.reader)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !message.Is(ClassUniversal, TagSequence) || len(message.Children) < 2 {
+		return 0, nil, errors.New("invalid LDAP message")
+	}
+
+	id, err := message.Children[0].Int()
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid LDAP message id: %w", err)
+	}
+
+	op := message.Children[1]
+	if op.Class != ClassApplication {
+		return 0, nil, errors.New("invalid LDAP message operation")
+	}
+
+	return id, op, nil
+}
+
+// decodeResult decodes the LDAPResult components of a response operation.
+//
+// Returns an [*Error] if the result code is not [ResultSuccess].
+func decodeResult(op *Packet) error {
+	if len(op.Children) < 3 {
+		return errors.New("invalid LDAP result")
+	}
+
+	code, err := op.Children[0].Int()
+	if err != nil {
+		return fmt.Errorf("invalid LDAP result code: %w", err)
+	}
+
+	if code != ResultSuccess {
+		return &Error{ResultCode: int(code), Message: op.Children[2].String()}
+	}
+
+	return nil
+}
+
+func decodeEntry(op *Packet) (*Entry, error) {
+	if len(op.Children) < 2 {
+		return nil, errors.New("invalid LDAP search entry")
+	}
+
+	entry := &Entry{DN: op.Children[0].String()}
+
+	for _, rawAttr := range op.Children[1].Children {
+		if len(rawAttr.Children) < 2 {
+			return nil, errors.New("invalid LDAP search entry attribute")
+		}
+
+		attr := &EntryAttribute{Name: rawAttr.Children[0].String()}
+		for _, v := range rawAttr.Children[1].Children {
+			attr.Values = append(attr.Values, v.String())
+		}
+
+		entry.Attributes = append(entry.Attributes, attr)
+	}
+
+	return entry, nil
+}
+
+func normalizeTLSConfig(config *tls.Config, host string) *tls.Config {
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+
+	if config.ServerName == "" {
+		config.ServerName = host
+	}
+
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	return config
+}
@@ -0,0 +1,237 @@
+package ldap_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/ldap"
+)
+
+func newTestClient(t *testing.T) (*ldap.Client, *tests.TestLDAPServer) {
+	server, err := tests.NewTestLDAPServer(
+		&tests.TestLDAPEntry{
+			DN:       "cn=admin,dc=example,dc=com",
+			Password: "admin_pass",
+		},
+		&tests.TestLDAPEntry{
+			DN:       "uid=john,ou=people,dc=example,dc=com",
+			Password: "john_pass",
+			Attributes: map[string][]string{
+				"uid":      {"john"},
+				"cn":       {"John Doe"},
+				"mail":     {"john@example.com"},
+				"memberOf": {"cn=admins,ou=groups,dc=example,dc=com", "cn=staff,ou=groups,dc=example,dc=com"},
+			},
+		},
+		&tests.TestLDAPEntry{
+			DN: "uid=jane,ou=people,dc=example,dc=com",
+			Attributes: map[string][]string{
+				"uid":  {"jane"},
+				"cn":   {"Jane Doe"},
+				"mail": {"jane@example.com"},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := ldap.Dial(ctx, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client, server
+}
+
+func TestDialInvalidURL(t *testing.T) {
+	for _, url := range []string{"", "http://example.com", "ldap://", "ldap://%zz"} {
+		if _, err := ldap.Dial(context.Background(), url, nil); err == nil {
+			t.Fatalf("Expected error for url %q", url)
+		}
+	}
+}
+
+func TestClientBind(t *testing.T) {
+	client, server := newTestClient(t)
+
+	scenarios := []struct {
+		name         string
+		dn           string
+		password     string
+		expectedCode int // -1 for non-LDAP error, 0 for success
+	}{
+		{"empty password", "cn=admin,dc=example,dc=com", "", -1},
+		{"missing entry", "cn=missing,dc=example,dc=com", "admin_pass", ldap.ResultInvalidCredentials},
+		{"invalid password", "cn=admin,dc=example,dc=com", "invalid", ldap.ResultInvalidCredentials},
+		{"entry without password", "uid=jane,ou=people,dc=example,dc=com", "jane_pass", ldap.ResultInvalidCredentials},
+		{"valid credentials", "cn=admin,dc=example,dc=com", "admin_pass", 0},
+		{"valid credentials (case-insensitive dn)", "UID=John,ou=people,dc=example,dc=com", "john_pass", 0},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := client.Bind(s.dn, s.password)
+
+			switch s.expectedCode {
+			case 0:
+				if err != nil {
+					t.Fatalf("Expected successful bind, got %v", err)
+				}
+			case -1:
+				if !errors.Is(err, ldap.ErrEmptyPassword) {
+					t.Fatalf("Expected ErrEmptyPassword, got %v", err)
+				}
+			default:
+				if !ldap.IsErrorWithCode(err, s.expectedCode) {
+					t.Fatalf("Expected LDAP error with code %d, got %v", s.expectedCode, err)
+				}
+			}
+		})
+	}
+
+	// the empty password bind must not be sent to the server
+	if binds := server.Binds(); len(binds) != 5 {
+		t.Fatalf("Expected 5 bind requests, got %v", binds)
+	}
+}
+
+func TestClientSearch(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	if err := client.Bind("cn=admin,dc=example,dc=com", "admin_pass"); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		req         ldap.SearchRequest
+		expectError bool
+		expectedDNs []string
+	}{
+		{
+			"invalid filter",
+			ldap.SearchRequest{BaseDN: "dc=example,dc=com", Scope: ldap.ScopeWholeSubtree, Filter: "(uid=john"},
+			true,
+			nil,
+		},
+		{
+			"no matches",
+			ldap.SearchRequest{BaseDN: "dc=example,dc=com", Scope: ldap.ScopeWholeSubtree, Filter: "(uid=missing)"},
+			false,
+			nil,
+		},
+		{
+			"single match",
+			ldap.SearchRequest{BaseDN: "dc=example,dc=com", Scope: ldap.ScopeWholeSubtree, Filter: "(uid=" + ldap.EscapeFilter("john") + ")"},
+			false,
+			[]string{"uid=john,ou=people,dc=example,dc=com"},
+		},
+		{
+			"escaped wildcard injection",
+			ldap.SearchRequest{BaseDN: "dc=example,dc=com", Scope: ldap.ScopeWholeSubtree, Filter: "(uid=" + ldap.EscapeFilter("*") + ")"},
+			false,
+			nil,
+		},
+		{
+			"multiple matches",
+			ldap.SearchRequest{BaseDN: "ou=people,dc=example,dc=com", Scope: ldap.ScopeSingleLevel, Filter: "(&(uid=j*)(mail=*@example.com))"},
+			false,
+			[]string{"uid=john,ou=people,dc=example,dc=com", "uid=jane,ou=people,dc=example,dc=com"},
+		},
+		{
+			"size limit exceeded",
+			ldap.SearchRequest{BaseDN: "dc=example,dc=com", Scope: ldap.ScopeWholeSubtree, Filter: "(uid=*)", SizeLimit: 1},
+			true,
+			nil,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			entries, err := client.Search(s.req)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			dns := make([]string, 0, len(entries))
+			for _, e := range entries {
+				dns = append(dns, e.DN)
+			}
+
+			if len(dns) != len(s.expectedDNs) {
+				t.Fatalf("Expected DNs %v, got %v", s.expectedDNs, dns)
+			}
+
+			for _, dn := range s.expectedDNs {
+				if !slices.Contains(dns, dn) {
+					t.Fatalf("Missing expected DN %q in %v", dn, dns)
+				}
+			}
+		})
+	}
+}
+
+func TestClientSearchAttributes(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	entries, err := client.Search(ldap.SearchRequest{
+		BaseDN:     "dc=example,dc=com",
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     "(uid=john)",
+		Attributes: []string{"CN", "memberOf"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+
+	entry := entries[0]
+
+	if v := entry.Value("cn"); v != "John Doe" {
+		t.Fatalf("Expected cn %q, got %q", "John Doe", v)
+	}
+
+	if v := entry.Value("mail"); v != "" {
+		t.Fatalf("Expected the mail attribute to not be returned, got %q", v)
+	}
+
+	groups := strings.Join(entry.Values("MEMBEROF"), ";")
+	if groups != "cn=admins,ou=groups,dc=example,dc=com;cn=staff,ou=groups,dc=example,dc=com" {
+		t.Fatalf("Unexpected memberOf values %q", groups)
+	}
+}
+
+func TestClientStartTLSUnsupported(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	err := client.StartTLS(nil)
+	if !ldap.IsErrorWithCode(err, ldap.ResultProtocolError) {
+		t.Fatalf("Expected protocol error, got %v", err)
+	}
+}
+
+func TestClientClosedServer(t *testing.T) {
+	client, server := newTestClient(t)
+
+	server.Close()
+
+	if err := client.Bind("cn=admin,dc=example,dc=com", "admin_pass"); err == nil {
+		t.Fatal("Expected bind error")
+	}
+}
@@ -0,0 +1,264 @@
+package ldap
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Search filter choice tags (RFC 4511 section 4.5.1).
+const (
+	FilterAnd            = 0
+	FilterOr             = 1
+	FilterNot            = 2
+	FilterEqualityMatch  = 3
+	FilterSubstrings     = 4
+	FilterGreaterOrEqual = 5
+	FilterLessOrEqual    = 6
+	FilterPresent        = 7
+	FilterApproxMatch    = 8
+)
+
+// Substrings filter choice tags.
+const (
+	FilterSubstringsInitial = 0
+	FilterSubstringsAny     = 1
+	FilterSubstringsFinal   = 2
+)
+
+// maxFilterDepth limits the nesting of the and/or/not filters.
+const maxFilterDepth = 20
+
+// EscapeFilter escapes the special characters of the provided
+// value so that it could be safely used as part of a search filter
+// (RFC 4515 section 3).
+func EscapeFilter(value string) string {
+	var sb strings.Builder
+	sb.Grow(len(value))
+
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '*', '(', ')', '\\', 0:
+			sb.WriteString(fmt.Sprintf("\\%02x", c))
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
+
+// CompileFilter parses the string representation of a search filter
+// (e.g. "(&(objectClass=person)(uid=john))") and returns its BER encoded packet.
+//
+// Extensible match filters are not supported.
+func CompileFilter(filter string) (*Packet, error) {
+	filter = strings.TrimSpace(filter)
+
+	// allow the outer parenthesis to be omitted (e.g. "uid=john")
+	if !strings.HasPrefix(filter, "(") {
+		filter = "(" + filter + ")"
+	}
+
+	p := &filterParser{input: filter}
+
+	packet, err := p.parseFilter(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos != len(p.input) {
+		return nil, fmt.Errorf("unexpected filter character at position %d", p.pos)
+	}
+
+	return packet, nil
+}
+
+type filterParser struct {
+	input string
+	pos   int
+}
+
+func (p *filterParser) parseFilter(depth int) (*Packet, error) {
+	if depth > maxFilterDepth {
+		return nil, errors.New("too deeply nested filter")
+	}
+
+	if p.pos >= len(p.input) || p.input[p.pos] != '(' {
+		return nil, fmt.Errorf("expected ( at position %d", p.pos)
+	}
+	p.pos++
+
+	if p.pos >= len(p.input) {
+		return nil, errors.New("unexpected end of filter")
+	}
+
+	var packet *Packet
+	var err error
+
+	switch p.input[p.pos] {
+	case '&':
+		p.pos++
+		packet, err = p.parseSet(FilterAnd, depth)
+	case '|':
+		p.pos++
+		packet, err = p.parseSet(FilterOr, depth)
+	case '!':
+		p.pos++
+		var child *Packet
+		child, err = p.parseFilter(depth + 1)
+		packet = NewSequence(ClassContext, FilterNot, child)
+	default:
+		packet, err = p.parseItem()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+		return nil, fmt.Errorf("expected ) at position %d", p.pos)
+	}
+	p.pos++
+
+	return packet, nil
+}
+
+func (p *filterParser) parseSet(tag int, depth int) (*Packet, error) {
+	packet := NewSequence(ClassContext, tag)
+
+	for p.pos < len(p.input) && p.input[p.pos] == '(' {
+		child, err := p.parseFilter(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		packet.Children = append(packet.Children, child)
+	}
+
+	if len(packet.Children) == 0 {
+		return nil, fmt.Errorf("empty filter set at position %d", p.pos)
+	}
+
+	return packet, nil
+}
+
+func (p *filterParser) parseItem() (*Packet, error) {
+	end := strings.IndexByte(p.input[p.pos:], ')')
+	if end < 0 {
+		return nil, errors.New("unterminated filter item")
+	}
+
+	item := p.input[p.pos : p.pos+end]
+	p.pos += end
+
+	eq := strings.IndexByte(item, '=')
+	if eq <= 0 {
+		return nil, fmt.Errorf("invalid filter item %q", item)
+	}
+
+	attr := item[:eq]
+	rawValue := item[eq+1:]
+
+	tag := FilterEqualityMatch
+	switch attr[len(attr)-1] {
+	case '>':
+		tag = FilterGreaterOrEqual
+		attr = attr[:len(attr)-1]
+	case '<':
+		tag = FilterLessOrEqual
+		attr = attr[:len(attr)-1]
+	case '~':
+		tag = FilterApproxMatch
+		attr = attr[:len(attr)-1]
+	case ':':
+		return nil, errors.New("extensible match filters are not supported")
+	}
+
+	if attr == "" || strings.ContainsAny(attr, "*()\\ ") {
+		return nil, fmt.Errorf("invalid filter attribute %q", attr)
+	}
+
+	if tag == FilterEqualityMatch && strings.Contains(rawValue, "*") {
+		if rawValue == "*" {
+			return NewString(ClassContext, FilterPresent, attr), nil
+		}
+
+		return compileSubstrings(attr, rawValue)
+	}
+
+	value, err := unescapeFilterValue(rawValue)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSequence(
+		ClassContext,
+		tag,
+		NewString(ClassUniversal, TagOctetString, attr),
+		NewString(ClassUniversal, TagOctetString, value),
+	), nil
+}
+
+func compileSubstrings(attr string, rawValue string) (*Packet, error) {
+	parts := strings.Split(rawValue, "*")
+
+	substrings := NewSequence(ClassUniversal, TagSequence)
+
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+
+		value, err := unescapeFilterValue(part)
+		if err != nil {
+			return nil, err
+		}
+
+		tag := FilterSubstringsAny
+		switch i {
+		case 0:
+			tag = FilterSubstringsInitial
+		case len(parts) - 1:
+			tag = FilterSubstringsFinal
+		}
+
+		substrings.Children = append(substrings.Children, NewString(ClassContext, tag, value))
+	}
+
+	return NewSequence(
+		ClassContext,
+		FilterSubstrings,
+		NewString(ClassUniversal, TagOctetString, attr),
+		substrings,
+	), nil
+}
+
+func unescapeFilterValue(value string) (string, error) {
+	if !strings.Contains(value, "\\") {
+		return value, nil
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(value))
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			sb.WriteByte(value[i])
+			continue
+		}
+
+		if i+2 >= len(value) {
+			return "", fmt.Errorf("invalid filter value escape sequence in %q", value)
+		}
+
+		b, err := hex.DecodeString(value[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("invalid filter value escape sequence in %q", value)
+		}
+
+		sb.Write(b)
+		i += 2
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,92 @@
+package ldap_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/ldap"
+)
+
+func TestEscapeFilter(t *testing.T) {
+	scenarios := []struct {
+		value    string
+		expected string
+	}{
+		{"", ""},
+		{"john", "john"},
+		{"*", `\2a`},
+		{"a(b)c", `a\28b\29c`},
+		{`a\b`, `a\5cb`},
+		{"a\x00b", `a\00b`},
+		{"*)(uid=*", `\2a\29\28uid=\2a`},
+		{"žluť", "žluť"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.value, func(t *testing.T) {
+			result := ldap.EscapeFilter(s.value)
+			if result != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestCompileFilter(t *testing.T) {
+	scenarios := []struct {
+		filter      string
+		expectError bool
+		expected    string
+	}{
+		// invalid
+		{"", true, ""},
+		{"()", true, ""},
+		{"(uid=a", true, ""},
+		{"(uid=a))", true, ""},
+		{"(=a)", true, ""},
+		{"(uid)", true, ""},
+		{"(&)", true, ""},
+		{"(|(uid=a)", true, ""},
+		{"(u id=a)", true, ""},
+		{"(uid:dn:=a)", true, ""},
+		{`(uid=a\2)`, true, ""},
+		{`(uid=a\zz)`, true, ""},
+		{strings.Repeat("(!", 30) + "(uid=a)" + strings.Repeat(")", 30), true, ""},
+
+		// valid
+		{"uid=a", false, "a3080403756964040161"},
+		{"(uid=a)", false, "a3080403756964040161"},
+		{"(uid=*)", false, "8703756964"},
+		{"(uid>=a)", false, "a5080403756964040161"},
+		{"(uid<=a)", false, "a6080403756964040161"},
+		{"(uid~=a)", false, "a8080403756964040161"},
+		{`(uid=a\2a)`, false, "a30904037569640402612a"},
+		{"(uid=a*b*c)", false, "a4100403756964" + "3009800161810162820163"},
+		{"(uid=*b*)", false, "a40a0403756964" + "3003810162"},
+		{"(!(uid=a))", false, "a20a" + "a3080403756964040161"},
+		{"(&(uid=a)(uid=*))", false, "a00f" + "a3080403756964040161" + "8703756964"},
+		{"(|(uid=a)(uid=*))", false, "a10f" + "a3080403756964040161" + "8703756964"},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.filter), func(t *testing.T) {
+			p, err := ldap.CompileFilter(s.filter)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			result := hex.EncodeToString(p.Bytes())
+			if result != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, result)
+			}
+		})
+	}
+}
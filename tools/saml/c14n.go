@@ -0,0 +1,170 @@
+package saml
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Canonicalize returns the Exclusive XML Canonicalization
+// (https://www.w3.org/TR/xml-exc-c14n/, without comments) of the element.
+//
+// inclusivePrefixes is the optional InclusiveNamespaces PrefixList
+// (use "#default" for the default namespace).
+//
+// The optional exclude element (and its descendants) is omitted from
+// the output (used for the enveloped signature transform).
+func Canonicalize(el *Element, inclusivePrefixes []string, exclude *Element) []byte {
+	var buf bytes.Buffer
+
+	c := &canonicalizer{
+		buf:       &buf,
+		exclude:   exclude,
+		inclusive: inclusivePrefixes,
+	}
+	c.element(el, map[string]string{})
+
+	return buf.Bytes()
+}
+
+type canonicalizer struct {
+	buf       *bytes.Buffer
+	exclude   *Element
+	inclusive []string
+}
+
+func (c *canonicalizer) element(el *Element, rendered map[string]string) {
+	if el == c.exclude {
+		return
+	}
+
+	// collect the visibly utilized namespace prefixes
+	prefixes := []string{el.Prefix}
+	for _, attr := range el.Attrs {
+		if attr.Prefix != "" && attr.Prefix != "xml" && !slices.Contains(prefixes, attr.Prefix) {
+			prefixes = append(prefixes, attr.Prefix)
+		}
+	}
+	for _, p := range c.inclusive {
+		if p == "#default" {
+			p = ""
+		}
+		if _, ok := el.LookupNamespace(p); ok && !slices.Contains(prefixes, p) {
+			prefixes = append(prefixes, p)
+		}
+	}
+	slices.Sort(prefixes)
+
+	current := rendered
+	var copied bool
+
+	c.buf.WriteByte('<')
+	c.buf.WriteString(qualifiedName(el.Prefix, el.Local))
+
+	for _, p := range prefixes {
+		if p == "xml" {
+			continue
+		}
+
+		uri, _ := el.LookupNamespace(p)
+
+		renderedURI, isRendered := current[p]
+		if renderedURI == uri && (isRendered || (p == "" && uri == "")) {
+			continue // already in the output scope
+		}
+
+		// copy on write to avoid modifying the parent scope
+		if !copied {
+			current = maps.Clone(rendered)
+			copied = true
+		}
+		current[p] = uri
+
+		c.buf.WriteByte(' ')
+		if p == "" {
+			c.buf.WriteString("xmlns")
+		} else {
+			c.buf.WriteString("xmlns:")
+			c.buf.WriteString(p)
+		}
+		c.buf.WriteString(`="`)
+		escapeAttr(c.buf, uri)
+		c.buf.WriteByte('"')
+	}
+
+	attrs := slices.Clone(el.Attrs)
+	slices.SortFunc(attrs, func(a, b Attr) int {
+		if a.Space != b.Space {
+			return strings.Compare(a.Space, b.Space)
+		}
+		return strings.Compare(a.Local, b.Local)
+	})
+	for _, attr := range attrs {
+		c.buf.WriteByte(' ')
+		c.buf.WriteString(qualifiedName(attr.Prefix, attr.Local))
+		c.buf.WriteString(`="`)
+		escapeAttr(c.buf, attr.Value)
+		c.buf.WriteByte('"')
+	}
+
+	c.buf.WriteByte('>')
+
+	for _, child := range el.Children {
+		switch v := child.(type) {
+		case *Element:
+			c.element(v, current)
+		case CharData:
+			escapeText(c.buf, string(v))
+		}
+	}
+
+	c.buf.WriteString("</")
+	c.buf.WriteString(qualifiedName(el.Prefix, el.Local))
+	c.buf.WriteByte('>')
+}
+
+func qualifiedName(prefix string, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+func escapeText(buf *bytes.Buffer, s string) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '>':
+			buf.WriteString("&gt;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteByte(s[i])
+		}
+	}
+}
+
+func escapeAttr(buf *bytes.Buffer, s string) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '"':
+			buf.WriteString("&quot;")
+		case '\t':
+			buf.WriteString("&#x9;")
+		case '\n':
+			buf.WriteString("&#xA;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteByte(s[i])
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package saml_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/saml"
+)
+
+func TestCanonicalize(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name      string
+		data      string
+		inclusive []string
+		expected  string
+	}{
+		{
+			"attributes and namespaces ordering",
+			`<a xmlns:z="urn:z" xmlns:b="urn:b" z:y="1" c="2" b:x="3" a="4"/>`,
+			nil,
+			`<a xmlns:b="urn:b" xmlns:z="urn:z" a="4" c="2" b:x="3" z:y="1"></a>`,
+		},
+		{
+			"unused namespaces are omitted",
+			`<x:a xmlns:x="urn:x" xmlns:y="urn:y"><x:b>test</x:b></x:a>`,
+			nil,
+			`<x:a xmlns:x="urn:x"><x:b>test</x:b></x:a>`,
+		},
+		{
+			"inclusive namespaces",
+			`<x:a xmlns:x="urn:x" xmlns:y="urn:y"><x:b>test</x:b></x:a>`,
+			[]string{"y"},
+			`<x:a xmlns:x="urn:x" xmlns:y="urn:y"><x:b>test</x:b></x:a>`,
+		},
+		{
+			"redeclared namespaces",
+			`<x:a xmlns:x="urn:x"><x:b xmlns:x="urn:x2"><x:c/></x:b><x:d/></x:a>`,
+			nil,
+			`<x:a xmlns:x="urn:x"><x:b xmlns:x="urn:x2"><x:c></x:c></x:b><x:d></x:d></x:a>`,
+		},
+		{
+			"default namespace",
+			`<a xmlns="urn:a"><b xmlns=""><c xmlns="urn:a"/></b></a>`,
+			nil,
+			`<a xmlns="urn:a"><b xmlns=""><c xmlns="urn:a"></c></b></a>`,
+		},
+		{
+			"escaping",
+			"<a b=\"&quot;&lt;&gt;&#9;&#10;\">&lt;&gt;&amp;\"'&#13;</a>",
+			nil,
+			"<a b=\"&quot;&lt;>&#x9;&#xA;\">&lt;&gt;&amp;\"'&#xD;</a>",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			el, err := saml.ParseElement([]byte(s.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := string(saml.Canonicalize(el, s.inclusive, nil))
+			if result != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeSubtree(t *testing.T) {
+	t.Parallel()
+
+	el, err := saml.ParseElement([]byte(`<x:a xmlns:x="urn:x" xmlns:y="urn:y"><x:b id="1"><y:c/><x:d/></x:b></x:a>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := el.Element("urn:x", "b")
+	d := b.Element("urn:x", "d")
+
+	result := string(saml.Canonicalize(b, nil, d))
+
+	expected := `<x:b xmlns:x="urn:x" id="1"><y:c xmlns:y="urn:y"></y:c></x:b>`
+	if result != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, result)
+	}
+}
@@ -0,0 +1,284 @@
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxDocumentSize is the max allowed size of a single parsed XML document (1MB).
+const MaxDocumentSize = 1 << 20
+
+// maxDocumentDepth limits the nesting of the parsed XML elements.
+const maxDocumentDepth = 50
+
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// Attr defines a single XML element attribute.
+type Attr struct {
+	// Prefix is the raw attribute namespace prefix (if any).
+	Prefix string
+
+	// Space is the resolved attribute namespace URI
+	// (empty for the unprefixed attributes).
+	Space string
+
+	Local string
+	Value string
+}
+
+// Element defines a minimal namespace aware XML element node
+// that preserves the information required for the XML canonicalization
+// (the raw prefixes and the namespace declarations).
+//
+// Comments, processing instructions and directives are not preserved.
+type Element struct {
+	// Prefix is the raw element namespace prefix (if any).
+	Prefix string
+
+	// Space is the resolved element namespace URI.
+	Space string
+
+	Local string
+
+	// Attrs are the element attributes (excluding the namespace declarations).
+	Attrs []Attr
+
+	// Namespaces are the namespace declarations of the element
+	// in the format prefix:uri (the default namespace has empty prefix).
+	Namespaces map[string]string
+
+	// Children are the element child nodes (either *Element or CharData).
+	Children []any
+
+	Parent *Element
+}
+
+// CharData defines an XML text node.
+type CharData string
+
+// NewElement creates a new element with the specified namespace
+// and declares the namespace on the element (if not empty).
+func NewElement(prefix string, space string, local string) *Element {
+	el := &Element{Prefix: prefix, Space: space, Local: local}
+
+	if space != "" {
+		el.Namespaces = map[string]string{prefix: space}
+	}
+
+	return el
+}
+
+// ParseElement parses the provided XML document and returns its root element.
+//
+// Documents with DTD declarations are rejected.
+func ParseElement(data []byte) (*Element, error) {
+	if len(data) > MaxDocumentSize {
+		return nil, errors.New("the XML document exceeds the max allowed size")
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	var root *Element
+	var current *Element
+	depth := 0
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if current == nil && root != nil {
+				return nil, errors.New("multiple XML root elements")
+			}
+
+			depth++
+			if depth > maxDocumentDepth {
+				return nil, errors.New("too deeply nested XML document")
+			}
+
+			el := &Element{
+				Prefix: t.Name.Space,
+				Local:  t.Name.Local,
+				Parent: current,
+			}
+
+			for _, attr := range t.Attr {
+				switch {
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+					el.setNamespace("", attr.Value)
+				case attr.Name.Space == "xmlns":
+					el.setNamespace(attr.Name.Local, attr.Value)
+				default:
+					el.Attrs = append(el.Attrs, Attr{Prefix: attr.Name.Space, Local: attr.Name.Local, Value: attr.Value})
+				}
+			}
+
+			// resolve the element and attributes namespaces
+			var ok bool
+			el.Space, ok = el.LookupNamespace(el.Prefix)
+			if !ok && el.Prefix != "" {
+				return nil, fmt.Errorf("undeclared namespace prefix %q", el.Prefix)
+			}
+			for i, attr := range el.Attrs {
+				if attr.Prefix == "" {
+					continue
+				}
+				el.Attrs[i].Space, ok = el.LookupNamespace(attr.Prefix)
+				if !ok {
+					return nil, fmt.Errorf("undeclared namespace prefix %q", attr.Prefix)
+				}
+			}
+
+			if current == nil {
+				root = el
+			} else {
+				current.Children = append(current.Children, el)
+			}
+			current = el
+		case xml.EndElement:
+			if current == nil || current.Prefix != t.Name.Space || current.Local != t.Name.Local {
+				return nil, fmt.Errorf("unexpected XML end element %q", t.Name.Local)
+			}
+			depth--
+			current = current.Parent
+		case xml.CharData:
+			if current == nil {
+				if len(bytes.TrimSpace(t)) > 0 {
+					return nil, errors.New("unexpected XML character data outside of the root element")
+				}
+				continue
+			}
+			current.Children = append(current.Children, CharData(t))
+		case xml.Directive:
+			return nil, errors.New("XML directives (DTD) are not allowed")
+		}
+	}
+
+	if root == nil || current != nil {
+		return nil, errors.New("missing or incomplete XML root element")
+	}
+
+	return root, nil
+}
+
+func (el *Element) setNamespace(prefix string, uri string) {
+	if el.Namespaces == nil {
+		el.Namespaces = map[string]string{}
+	}
+	el.Namespaces[prefix] = uri
+}
+
+// LookupNamespace returns the in-scope namespace URI of the specified prefix.
+func (el *Element) LookupNamespace(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return xmlNamespace, true
+	}
+
+	for e := el; e != nil; e = e.Parent {
+		if uri, ok := e.Namespaces[prefix]; ok {
+			return uri, true
+		}
+	}
+
+	// the default namespace is empty if not declared
+	return "", prefix == ""
+}
+
+// Is reports whether the element has the specified namespace and local name.
+func (el *Element) Is(space string, local string) bool {
+	return el != nil && el.Space == space && el.Local == local
+}
+
+// Attr returns the value of the unprefixed attribute with the specified name.
+func (el *Element) Attr(name string) string {
+	for _, attr := range el.Attrs {
+		if attr.Prefix == "" && attr.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// SetAttr adds or updates the unprefixed attribute with the specified name.
+func (el *Element) SetAttr(name string, value string) {
+	for i, attr := range el.Attrs {
+		if attr.Prefix == "" && attr.Local == name {
+			el.Attrs[i].Value = value
+			return
+		}
+	}
+	el.Attrs = append(el.Attrs, Attr{Local: name, Value: value})
+}
+
+// Text returns the concatenated text content of the element direct text nodes.
+func (el *Element) Text() string {
+	var sb strings.Builder
+	for _, child := range el.Children {
+		if text, ok := child.(CharData); ok {
+			sb.WriteString(string(text))
+		}
+	}
+	return sb.String()
+}
+
+// Elements returns all direct child elements with the specified namespace and local name.
+func (el *Element) Elements(space string, local string) []*Element {
+	var result []*Element
+	for _, child := range el.Children {
+		if c, ok := child.(*Element); ok && c.Is(space, local) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// Element returns the first direct child element with the specified namespace and local name.
+func (el *Element) Element(space string, local string) *Element {
+	for _, child := range el.Children {
+		if c, ok := child.(*Element); ok && c.Is(space, local) {
+			return c
+		}
+	}
+	return nil
+}
+
+// AddChild appends the provided child element or CharData.
+func (el *Element) AddChild(child any) {
+	el.InsertChild(len(el.Children), child)
+}
+
+// InsertChild inserts the provided child element or CharData at the specified position.
+func (el *Element) InsertChild(index int, child any) {
+	if c, ok := child.(*Element); ok {
+		c.Parent = el
+	}
+	el.Children = append(el.Children[:index], append([]any{child}, el.Children[index:]...)...)
+}
+
+// RemoveChild removes the provided child element.
+func (el *Element) RemoveChild(child *Element) {
+	for i, c := range el.Children {
+		if c == child {
+			el.Children = append(el.Children[:i], el.Children[i+1:]...)
+			child.Parent = nil
+			return
+		}
+	}
+}
+
+// Bytes serializes the element as standalone XML document
+// (it is the same as its exclusive canonical form).
+func (el *Element) Bytes() []byte {
+	return Canonicalize(el, nil, nil)
+}
@@ -0,0 +1,81 @@
+package saml_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/saml"
+)
+
+func TestParseElement(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name        string
+		data        string
+		expectError bool
+	}{
+		{"empty", ``, true},
+		{"invalid xml", `<a><b></a>`, true},
+		{"multiple roots", `<a></a><b></b>`, true},
+		{"text outside of root", `<a></a>test`, true},
+		{"doctype", `<!DOCTYPE a [<!ENTITY x "y">]><a>&x;</a>`, true},
+		{"undeclared element prefix", `<x:a></x:a>`, true},
+		{"undeclared attribute prefix", `<a x:b="1"></a>`, true},
+		{"too deep", strings.Repeat("<a>", 51) + strings.Repeat("</a>", 51), true},
+		{"valid", `<?xml version="1.0"?><x:a xmlns:x="urn:x"><b c="1">test</b></x:a>`, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			_, err := saml.ParseElement([]byte(s.data))
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestElementNavigation(t *testing.T) {
+	t.Parallel()
+
+	el, err := saml.ParseElement([]byte(`<x:a xmlns:x="urn:x" xmlns="urn:default" id="1"><x:b>b1</x:b><c>c1</c><x:b>b2</x:b><x:c/></x:a>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !el.Is("urn:x", "a") {
+		t.Fatalf("Expected the root element to be {urn:x}a, got {%s}%s", el.Space, el.Local)
+	}
+
+	if v := el.Attr("id"); v != "1" {
+		t.Fatalf("Expected id attribute 1, got %q", v)
+	}
+
+	if v := el.Attr("missing"); v != "" {
+		t.Fatalf("Expected empty missing attribute, got %q", v)
+	}
+
+	bElems := el.Elements("urn:x", "b")
+	if len(bElems) != 2 || bElems[0].Text() != "b1" || bElems[1].Text() != "b2" {
+		t.Fatalf("Expected 2 b elements, got %v", bElems)
+	}
+
+	if c := el.Element("urn:default", "c"); c == nil || c.Text() != "c1" {
+		t.Fatalf("Expected {urn:default}c element with text c1, got %v", c)
+	}
+
+	if c := el.Element("urn:x", "c"); c == nil || c.Text() != "" {
+		t.Fatalf("Expected empty {urn:x}c element, got %v", c)
+	}
+
+	if missing := el.Element("urn:x", "missing"); missing != nil {
+		t.Fatalf("Expected nil element, got %v", missing)
+	}
+
+	if uri, ok := bElems[0].LookupNamespace("x"); !ok || uri != "urn:x" {
+		t.Fatalf("Expected inherited x namespace, got %q (%v)", uri, ok)
+	}
+}
@@ -0,0 +1,332 @@
+package saml
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	_ "crypto/sha512" // register the SHA-512 hash implementation
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// XML Signature namespaces and algorithms.
+const (
+	NamespaceDSig = "http://www.w3.org/2000/09/xmldsig#"
+
+	AlgorithmExcC14N            = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	AlgorithmEnvelopedSignature = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	AlgorithmSHA256             = "http://www.w3.org/2001/04/xmlenc#sha256"
+	AlgorithmSHA512             = "http://www.w3.org/2001/04/xmlenc#sha512"
+	AlgorithmRSASHA256          = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	AlgorithmRSASHA512          = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	AlgorithmECDSASHA256        = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"
+	AlgorithmECDSASHA512        = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha512"
+)
+
+// ErrMissingSignature is returned when the verified element doesn't have a signature.
+var ErrMissingSignature = errors.New("missing XML signature")
+
+var digestAlgorithms = map[string]crypto.Hash{
+	AlgorithmSHA256: crypto.SHA256,
+	AlgorithmSHA512: crypto.SHA512,
+}
+
+var signatureAlgorithms = map[string]crypto.Hash{
+	AlgorithmRSASHA256:   crypto.SHA256,
+	AlgorithmRSASHA512:   crypto.SHA512,
+	AlgorithmECDSASHA256: crypto.SHA256,
+	AlgorithmECDSASHA512: crypto.SHA512,
+}
+
+// ParseCertificates parses one or more PEM encoded X.509 certificates.
+//
+// For convenience a single base64 encoded DER certificate
+// without the PEM armor is also accepted (e.g. copied from IdP metadata).
+func ParseCertificates(data string) ([]*x509.Certificate, error) {
+	data = strings.TrimSpace(data)
+
+	if !strings.HasPrefix(data, "-----") {
+		der, err := base64.StdEncoding.DecodeString(stripSpaces(data))
+		if err != nil {
+			return nil, errors.New("invalid certificate encoding")
+		}
+
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+
+		return []*x509.Certificate{cert}, nil
+	}
+
+	var certs []*x509.Certificate
+
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+
+	return certs, nil
+}
+
+// VerifySignature verifies the enveloped XML signature of the element
+// against the public keys of the provided trusted certificates.
+//
+// Only signatures that are direct children of the element and have a single
+// reference to the element ID, exclusive canonicalization and SHA-2 digests are accepted.
+// The certificates embedded in the signature KeyInfo are ignored.
+//
+// Returns [ErrMissingSignature] if the element is not signed.
+func VerifySignature(el *Element, certs []*x509.Certificate) error {
+	signatures := el.Elements(NamespaceDSig, "Signature")
+	if len(signatures) == 0 {
+		return ErrMissingSignature
+	}
+	if len(signatures) > 1 {
+		return errors.New("multiple XML signatures")
+	}
+	signature := signatures[0]
+
+	signedInfo := signature.Element(NamespaceDSig, "SignedInfo")
+	if signedInfo == nil {
+		return errors.New("missing SignedInfo")
+	}
+
+	c14nMethod := signedInfo.Element(NamespaceDSig, "CanonicalizationMethod")
+	if c14nMethod == nil || c14nMethod.Attr("Algorithm") != AlgorithmExcC14N {
+		return errors.New("unsupported SignedInfo canonicalization method")
+	}
+
+	signatureMethod := signedInfo.Element(NamespaceDSig, "SignatureMethod")
+	if signatureMethod == nil {
+		return errors.New("missing SignatureMethod")
+	}
+	signatureHash, ok := signatureAlgorithms[signatureMethod.Attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported signature method %q", signatureMethod.Attr("Algorithm"))
+	}
+
+	// reference checks
+	// ---
+	references := signedInfo.Elements(NamespaceDSig, "Reference")
+	if len(references) != 1 {
+		return errors.New("the signature must have exactly one reference")
+	}
+	reference := references[0]
+
+	id := el.Attr("ID")
+	if id == "" || reference.Attr("URI") != "#"+id {
+		return errors.New("the signature reference doesn't match with the signed element ID")
+	}
+
+	var inclusivePrefixes []string
+	var hasC14NTransform, hasEnvelopedTransform bool
+	if transforms := reference.Element(NamespaceDSig, "Transforms"); transforms != nil {
+		for _, transform := range transforms.Elements(NamespaceDSig, "Transform") {
+			switch transform.Attr("Algorithm") {
+			case AlgorithmEnvelopedSignature:
+				hasEnvelopedTransform = true
+			case AlgorithmExcC14N:
+				hasC14NTransform = true
+				inclusivePrefixes = inclusiveNamespaces(transform)
+			default:
+				return fmt.Errorf("unsupported reference transform %q", transform.Attr("Algorithm"))
+			}
+		}
+	}
+	if !hasEnvelopedTransform || !hasC14NTransform {
+		return errors.New("missing enveloped signature or exclusive canonicalization reference transform")
+	}
+
+	digestMethod := reference.Element(NamespaceDSig, "DigestMethod")
+	if digestMethod == nil {
+		return errors.New("missing DigestMethod")
+	}
+	digestHash, ok := digestAlgorithms[digestMethod.Attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported digest method %q", digestMethod.Attr("Algorithm"))
+	}
+
+	digestValue := reference.Element(NamespaceDSig, "DigestValue")
+	if digestValue == nil {
+		return errors.New("missing DigestValue")
+	}
+	expectedDigest, err := base64.StdEncoding.DecodeString(stripSpaces(digestValue.Text()))
+	if err != nil {
+		return errors.New("invalid DigestValue encoding")
+	}
+
+	h := digestHash.New()
+	h.Write(Canonicalize(el, inclusivePrefixes, signature))
+	if subtle.ConstantTimeCompare(h.Sum(nil), expectedDigest) != 1 {
+		return errors.New("the signed element digest doesn't match")
+	}
+
+	// signature value checks
+	// ---
+	signatureValue := signature.Element(NamespaceDSig, "SignatureValue")
+	if signatureValue == nil {
+		return errors.New("missing SignatureValue")
+	}
+	rawSignature, err := base64.StdEncoding.DecodeString(stripSpaces(signatureValue.Text()))
+	if err != nil {
+		return errors.New("invalid SignatureValue encoding")
+	}
+
+	h = signatureHash.New()
+	h.Write(Canonicalize(signedInfo, inclusiveNamespaces(c14nMethod), nil))
+	hashed := h.Sum(nil)
+
+	for _, cert := range certs {
+		if verifyHashSignature(cert.PublicKey, signatureHash, hashed, rawSignature) {
+			return nil
+		}
+	}
+
+	return errors.New("invalid XML signature")
+}
+
+func verifyHashSignature(publicKey any, hash crypto.Hash, hashed []byte, signature []byte) bool {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, hash, hashed, signature) == nil
+	case *ecdsa.PublicKey:
+		// XML-DSig ECDSA signatures are the raw r||s concatenation (RFC 4050)
+		if len(signature) == 0 || len(signature)%2 != 0 {
+			return false
+		}
+		half := len(signature) / 2
+		r := new(big.Int).SetBytes(signature[:half])
+		s := new(big.Int).SetBytes(signature[half:])
+		return ecdsa.Verify(key, hashed, r, s)
+	default:
+		return false
+	}
+}
+
+// Sign adds an enveloped XML signature (with exclusive canonicalization
+// and SHA-256 digest) to the element using the provided RSA or ECDSA key.
+//
+// The element must have an ID attribute. The signature is inserted
+// right after the element Issuer child (if any) as required by the SAML schema.
+func Sign(el *Element, key crypto.Signer, cert *x509.Certificate) error {
+	id := el.Attr("ID")
+	if id == "" {
+		return errors.New("the element to sign must have an ID attribute")
+	}
+
+	var signatureAlgorithm string
+	switch key.Public().(type) {
+	case *rsa.PublicKey:
+		signatureAlgorithm = AlgorithmRSASHA256
+	case *ecdsa.PublicKey:
+		signatureAlgorithm = AlgorithmECDSASHA256
+	default:
+		return errors.New("unsupported signing key type")
+	}
+
+	// remove existing signatures (if any)
+	for _, old := range el.Elements(NamespaceDSig, "Signature") {
+		el.RemoveChild(old)
+	}
+
+	digest := sha256.Sum256(Canonicalize(el, nil, nil))
+
+	signature := NewElement("ds", NamespaceDSig, "Signature")
+	signedInfo := newDSigElement(signature, "SignedInfo")
+	newDSigElement(signedInfo, "CanonicalizationMethod").SetAttr("Algorithm", AlgorithmExcC14N)
+	newDSigElement(signedInfo, "SignatureMethod").SetAttr("Algorithm", signatureAlgorithm)
+	reference := newDSigElement(signedInfo, "Reference")
+	reference.SetAttr("URI", "#"+id)
+	transforms := newDSigElement(reference, "Transforms")
+	newDSigElement(transforms, "Transform").SetAttr("Algorithm", AlgorithmEnvelopedSignature)
+	newDSigElement(transforms, "Transform").SetAttr("Algorithm", AlgorithmExcC14N)
+	newDSigElement(reference, "DigestMethod").SetAttr("Algorithm", AlgorithmSHA256)
+	newDSigElement(reference, "DigestValue").AddChild(CharData(base64.StdEncoding.EncodeToString(digest[:])))
+
+	// insert after the Issuer (if any)
+	index := 0
+	for i, child := range el.Children {
+		if c, ok := child.(*Element); ok && c.Local == "Issuer" {
+			index = i + 1
+			break
+		}
+	}
+	el.InsertChild(index, signature)
+
+	hashed := sha256.Sum256(Canonicalize(signedInfo, nil, nil))
+
+	rawSignature, err := key.Sign(rand.Reader, hashed[:], crypto.SHA256)
+	if err != nil {
+		el.RemoveChild(signature)
+		return err
+	}
+
+	// convert the ASN.1 ECDSA signature to r||s
+	if publicKey, ok := key.Public().(*ecdsa.PublicKey); ok {
+		var sig struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(rawSignature, &sig); err != nil {
+			el.RemoveChild(signature)
+			return err
+		}
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		rawSignature = make([]byte, 2*size)
+		sig.R.FillBytes(rawSignature[:size])
+		sig.S.FillBytes(rawSignature[size:])
+	}
+
+	newDSigElement(signature, "SignatureValue").AddChild(CharData(base64.StdEncoding.EncodeToString(rawSignature)))
+
+	if cert != nil {
+		keyInfo := newDSigElement(signature, "KeyInfo")
+		x509Data := newDSigElement(keyInfo, "X509Data")
+		newDSigElement(x509Data, "X509Certificate").AddChild(CharData(base64.StdEncoding.EncodeToString(cert.Raw)))
+	}
+
+	return nil
+}
+
+func newDSigElement(parent *Element, local string) *Element {
+	el := &Element{Prefix: "ds", Space: NamespaceDSig, Local: local}
+	parent.AddChild(el)
+	return el
+}
+
+// inclusiveNamespaces returns the InclusiveNamespaces PrefixList of the transform element (if any).
+func inclusiveNamespaces(transform *Element) []string {
+	inclusive := transform.Element(AlgorithmExcC14N, "InclusiveNamespaces")
+	if inclusive == nil {
+		return nil
+	}
+	return strings.Fields(inclusive.Attr("PrefixList"))
+}
+
+func stripSpaces(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
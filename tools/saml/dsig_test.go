@@ -0,0 +1,234 @@
+package saml_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/saml"
+)
+
+func newTestCertificate(t *testing.T, key crypto.Signer) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert
+}
+
+const testSignDocument = `<p:Root xmlns:p="urn:p" xmlns:unused="urn:unused" ID="_abc"><p:Issuer>test</p:Issuer><p:Data attr="1">hello</p:Data></p:Root>`
+
+func TestParseCertificates(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := newTestCertificate(t, key)
+
+	pemCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	b64Cert := base64.StdEncoding.EncodeToString(cert.Raw)
+
+	scenarios := []struct {
+		name          string
+		data          string
+		expectedCount int
+		expectError   bool
+	}{
+		{"empty", "", 0, true},
+		{"invalid base64", "invalid!", 0, true},
+		{"invalid der", base64.StdEncoding.EncodeToString([]byte("test")), 0, true},
+		{"non-certificate PEM", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("test")})), 0, true},
+		{"bare base64 DER", b64Cert[:20] + "\n " + b64Cert[20:], 1, false},
+		{"single PEM", pemCert, 1, false},
+		{"multiple PEM", pemCert + "\n" + pemCert, 2, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			certs, err := saml.ParseCertificates(s.data)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if len(certs) != s.expectedCount {
+				t.Fatalf("Expected %d certificates, got %d", s.expectedCount, len(certs))
+			}
+		})
+	}
+}
+
+func TestSignAndVerifySignature(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := map[string]crypto.Signer{
+		"rsa":   rsaKey,
+		"ecdsa": ecdsaKey,
+	}
+
+	for name, key := range keys {
+		t.Run(name, func(t *testing.T) {
+			cert := newTestCertificate(t, key)
+
+			el, err := saml.ParseElement([]byte(testSignDocument))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := saml.Sign(el, key, cert); err != nil {
+				t.Fatal(err)
+			}
+
+			// the signature must be inserted after the issuer
+			if signature, ok := el.Children[1].(*saml.Element); !ok || !signature.Is(saml.NamespaceDSig, "Signature") {
+				t.Fatalf("Expected the signature to be the second child, got %v", el.Children[1])
+			}
+
+			// reparse to ensure that the serialization is verifiable
+			signed, err := saml.ParseElement(el.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := saml.VerifySignature(signed, []*x509.Certificate{cert}); err != nil {
+				t.Fatalf("Expected valid signature, got %v", err)
+			}
+
+			// untrusted certificate
+			otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			otherCert := newTestCertificate(t, otherKey)
+			if err := saml.VerifySignature(signed, []*x509.Certificate{otherCert}); err == nil {
+				t.Fatal("Expected error for untrusted certificate")
+			}
+
+			// multiple trusted certificates (e.g. during rotation)
+			if err := saml.VerifySignature(signed, []*x509.Certificate{otherCert, cert}); err != nil {
+				t.Fatalf("Expected valid signature with multiple certificates, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifySignatureTampering(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := newTestCertificate(t, key)
+
+	el, err := saml.ParseElement([]byte(testSignDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := saml.Sign(el, key, cert); err != nil {
+		t.Fatal(err)
+	}
+	signed := string(el.Bytes())
+
+	scenarios := []struct {
+		name     string
+		document string
+		expected error
+	}{
+		{
+			"missing signature",
+			testSignDocument,
+			saml.ErrMissingSignature,
+		},
+		{
+			"modified text",
+			strings.Replace(signed, ">hello<", ">hell0<", 1),
+			nil,
+		},
+		{
+			"modified attribute",
+			strings.Replace(signed, `attr="1"`, `attr="2"`, 1),
+			nil,
+		},
+		{
+			"added element",
+			strings.Replace(signed, `</p:Root>`, `<p:Extra></p:Extra></p:Root>`, 1),
+			nil,
+		},
+		{
+			"modified reference id",
+			strings.Replace(signed, `ID="_abc"`, `ID="_xyz"`, 1),
+			nil,
+		},
+		{
+			"modified signature method",
+			strings.Replace(signed, saml.AlgorithmRSASHA256, "http://www.w3.org/2000/09/xmldsig#rsa-sha1", 1),
+			nil,
+		},
+		{
+			"missing enveloped transform",
+			strings.Replace(signed, `<ds:Transform Algorithm="`+saml.AlgorithmEnvelopedSignature+`"></ds:Transform>`, "", 1),
+			nil,
+		},
+		{
+			"duplicated signature",
+			strings.Replace(signed, `<p:Data`, signed[strings.Index(signed, "<ds:Signature"):strings.Index(signed, "</ds:Signature>")+len("</ds:Signature>")]+`<p:Data`, 1),
+			nil,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			doc, err := saml.ParseElement([]byte(s.document))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = saml.VerifySignature(doc, []*x509.Certificate{cert})
+			if err == nil {
+				t.Fatal("Expected verification error, got nil")
+			}
+
+			if s.expected != nil && !errors.Is(err, s.expected) {
+				t.Fatalf("Expected error %v, got %v", s.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,452 @@
+// Package saml implements a minimal SAML 2.0 Web Browser SSO
+// service provider (HTTP-Redirect binding for the authentication
+// requests and HTTP-POST binding for the responses).
+//
+// Encrypted assertions and signed authentication requests are not supported.
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SAML 2.0 namespaces, bindings and other identifiers.
+const (
+	NamespaceAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	NamespaceProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	NamespaceMetadata  = "urn:oasis:names:tc:SAML:2.0:metadata"
+
+	BindingHTTPPost     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	BindingHTTPRedirect = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+
+	NameIdFormatUnspecified  = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	NameIdFormatEmailAddress = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+	NameIdFormatPersistent   = "urn:oasis:names:tc:SAML:2.0:nameid-format:persistent"
+	NameIdFormatTransient    = "urn:oasis:names:tc:SAML:2.0:nameid-format:transient"
+
+	StatusSuccess = "urn:oasis:names:tc:SAML:2.0:status:Success"
+
+	subjectConfirmationBearer = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+)
+
+// DefaultClockSkew is the default allowed clock difference with the IdP.
+const DefaultClockSkew = 3 * time.Minute
+
+// ServiceProvider defines the SAML service provider (SP) and its trusted identity provider (IdP).
+type ServiceProvider struct {
+	// EntityId is the SP entity id (usually the SP metadata url).
+	EntityId string
+
+	// ACSURL is the SP Assertion Consumer Service url
+	// where the IdP posts the SAML responses.
+	ACSURL string
+
+	// NameIdFormat is the optional requested NameID format.
+	NameIdFormat string
+
+	// IdPEntityId is the trusted IdP entity id (the assertions issuer).
+	IdPEntityId string
+
+	// IdPSSOURL is the IdP single sign-on service url (HTTP-Redirect binding).
+	IdPSSOURL string
+
+	// IdPCertificates are the trusted IdP signing certificates
+	// (multiple certificates could be specified for keys rotation).
+	IdPCertificates []*x509.Certificate
+
+	// ClockSkew is the allowed clock difference with the IdP
+	// (default to [DefaultClockSkew]).
+	ClockSkew time.Duration
+
+	// Now is an optional function that returns the current time (used mainly for testing).
+	Now func() time.Time
+}
+
+// Assertion defines the validated SAML assertion data.
+type Assertion struct {
+	Id           string
+	Issuer       string
+	NameId       string
+	NameIdFormat string
+	SessionIndex string
+
+	// InResponseTo is the id of the authentication request
+	// (empty for the IdP-initiated responses).
+	InResponseTo string
+
+	// ExpiresAt is the time after which the assertion must not be accepted
+	// (could be used to limit the replay protection cache).
+	ExpiresAt time.Time
+
+	Attributes []*AssertionAttribute
+}
+
+// AssertionAttribute defines a single SAML assertion attribute.
+type AssertionAttribute struct {
+	Name         string
+	FriendlyName string
+	Values       []string
+}
+
+// Values returns the values of the attribute with the specified Name or FriendlyName.
+func (a *Assertion) Values(name string) []string {
+	for _, attr := range a.Attributes {
+		if attr.Name == name {
+			return attr.Values
+		}
+	}
+
+	for _, attr := range a.Attributes {
+		if attr.FriendlyName != "" && attr.FriendlyName == name {
+			return attr.Values
+		}
+	}
+
+	return nil
+}
+
+// Value returns the first value of the attribute with the specified Name or FriendlyName.
+func (a *Assertion) Value(name string) string {
+	values := a.Values(name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// NewRequestId generates a new random SAML request/assertion id.
+//
+// The id is prefixed with "_" because it must be a valid xsd:ID (NCName).
+func NewRequestId() string {
+	b := make([]byte, 20)
+	_, _ = rand.Read(b)
+	return "_" + hex.EncodeToString(b)
+}
+
+// AuthnRequestURL returns the IdP url to redirect the user to
+// for SP-initiated login (HTTP-Redirect binding).
+//
+// The requestId must be stored by the caller and later
+// used to validate the InResponseTo of the SAML response.
+func (sp *ServiceProvider) AuthnRequestURL(requestId string, relayState string) (string, error) {
+	u, err := url.Parse(sp.IdPSSOURL)
+	if err != nil {
+		return "", err
+	}
+
+	request := NewElement("samlp", NamespaceProtocol, "AuthnRequest")
+	request.setNamespace("saml", NamespaceAssertion)
+	request.SetAttr("ID", requestId)
+	request.SetAttr("Version", "2.0")
+	request.SetAttr("IssueInstant", sp.now().UTC().Format(time.RFC3339))
+	request.SetAttr("Destination", sp.IdPSSOURL)
+	request.SetAttr("AssertionConsumerServiceURL", sp.ACSURL)
+	request.SetAttr("ProtocolBinding", BindingHTTPPost)
+
+	issuer := &Element{Prefix: "saml", Space: NamespaceAssertion, Local: "Issuer"}
+	issuer.AddChild(CharData(sp.EntityId))
+	request.AddChild(issuer)
+
+	policy := &Element{Prefix: "samlp", Space: NamespaceProtocol, Local: "NameIDPolicy"}
+	policy.SetAttr("AllowCreate", "true")
+	if sp.NameIdFormat != "" {
+		policy.SetAttr("Format", sp.NameIdFormat)
+	}
+	request.AddChild(policy)
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(request.Bytes()); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// Metadata returns the SP metadata XML document.
+func (sp *ServiceProvider) Metadata() []byte {
+	entity := NewElement("md", NamespaceMetadata, "EntityDescriptor")
+	entity.SetAttr("entityID", sp.EntityId)
+
+	descriptor := &Element{Prefix: "md", Space: NamespaceMetadata, Local: "SPSSODescriptor"}
+	descriptor.SetAttr("AuthnRequestsSigned", "false")
+	descriptor.SetAttr("WantAssertionsSigned", "true")
+	descriptor.SetAttr("protocolSupportEnumeration", NamespaceProtocol)
+	entity.AddChild(descriptor)
+
+	if sp.NameIdFormat != "" {
+		format := &Element{Prefix: "md", Space: NamespaceMetadata, Local: "NameIDFormat"}
+		format.AddChild(CharData(sp.NameIdFormat))
+		descriptor.AddChild(format)
+	}
+
+	acs := &Element{Prefix: "md", Space: NamespaceMetadata, Local: "AssertionConsumerService"}
+	acs.SetAttr("Binding", BindingHTTPPost)
+	acs.SetAttr("Location", sp.ACSURL)
+	acs.SetAttr("index", "0")
+	acs.SetAttr("isDefault", "true")
+	descriptor.AddChild(acs)
+
+	return append([]byte(`<?xml version="1.0" encoding="UTF-8"?>`+"\n"), entity.Bytes()...)
+}
+
+// ParseResponse decodes and validates the base64 encoded SAML response
+// (HTTP-POST binding) and returns its single assertion.
+//
+// inResponseTo is the id of the related authentication request
+// or empty string to accept only IdP-initiated (unsolicited) responses.
+//
+// Either the response or the assertion must be signed by one of the trusted
+// IdP certificates. Note that the caller is responsible for the assertion
+// replay protection (e.g. by caching the assertion ids until [Assertion.ExpiresAt]).
+func (sp *ServiceProvider) ParseResponse(encodedResponse string, inResponseTo string) (*Assertion, error) {
+	raw, err := base64.StdEncoding.DecodeString(stripSpaces(encodedResponse))
+	if err != nil {
+		return nil, errors.New("invalid SAML response encoding")
+	}
+
+	response, err := ParseElement(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAML response XML: %w", err)
+	}
+
+	if !response.Is(NamespaceProtocol, "Response") {
+		return nil, errors.New("the document is not a SAML response")
+	}
+
+	if response.Attr("Version") != "2.0" {
+		return nil, errors.New("unsupported SAML response version")
+	}
+
+	if destination := response.Attr("Destination"); destination != "" && destination != sp.ACSURL {
+		return nil, fmt.Errorf("the SAML response destination %q doesn't match with the ACS url", destination)
+	}
+
+	if response.Attr("InResponseTo") != inResponseTo {
+		return nil, errors.New("the SAML response InResponseTo doesn't match with the authentication request")
+	}
+
+	if issuer := response.Element(NamespaceAssertion, "Issuer"); issuer != nil && strings.TrimSpace(issuer.Text()) != sp.IdPEntityId {
+		return nil, errors.New("the SAML response issuer doesn't match with the IdP entity id")
+	}
+
+	var statusCode *Element
+	if status := response.Element(NamespaceProtocol, "Status"); status != nil {
+		statusCode = status.Element(NamespaceProtocol, "StatusCode")
+	}
+	if statusCode == nil {
+		return nil, errors.New("missing SAML response status")
+	}
+	if code := statusCode.Attr("Value"); code != StatusSuccess {
+		if subCode := statusCode.Element(NamespaceProtocol, "StatusCode"); subCode != nil {
+			code += " (" + subCode.Attr("Value") + ")"
+		}
+		return nil, fmt.Errorf("unsuccessful SAML response status %s", code)
+	}
+
+	if len(response.Elements(NamespaceAssertion, "EncryptedAssertion")) > 0 {
+		return nil, errors.New("encrypted SAML assertions are not supported")
+	}
+
+	assertions := response.Elements(NamespaceAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, errors.New("the SAML response must contain exactly one assertion")
+	}
+	assertion := assertions[0]
+
+	// verify the response and/or the assertion signatures
+	// ---
+	responseSignatureErr := VerifySignature(response, sp.IdPCertificates)
+	if responseSignatureErr != nil && !errors.Is(responseSignatureErr, ErrMissingSignature) {
+		return nil, fmt.Errorf("invalid SAML response signature: %w", responseSignatureErr)
+	}
+
+	assertionSignatureErr := VerifySignature(assertion, sp.IdPCertificates)
+	if assertionSignatureErr != nil && !errors.Is(assertionSignatureErr, ErrMissingSignature) {
+		return nil, fmt.Errorf("invalid SAML assertion signature: %w", assertionSignatureErr)
+	}
+
+	if responseSignatureErr != nil && assertionSignatureErr != nil {
+		return nil, errors.New("either the SAML response or the assertion must be signed")
+	}
+
+	return sp.validateAssertion(assertion, inResponseTo)
+}
+
+func (sp *ServiceProvider) validateAssertion(el *Element, inResponseTo string) (*Assertion, error) {
+	now := sp.now()
+
+	skew := sp.ClockSkew
+	if skew <= 0 {
+		skew = DefaultClockSkew
+	}
+
+	result := &Assertion{
+		Id:           el.Attr("ID"),
+		InResponseTo: inResponseTo,
+	}
+
+	if result.Id == "" {
+		return nil, errors.New("missing SAML assertion ID")
+	}
+
+	if el.Attr("Version") != "2.0" {
+		return nil, errors.New("unsupported SAML assertion version")
+	}
+
+	// issuer
+	// ---
+	issuer := el.Element(NamespaceAssertion, "Issuer")
+	if issuer == nil || strings.TrimSpace(issuer.Text()) != sp.IdPEntityId {
+		return nil, errors.New("the SAML assertion issuer doesn't match with the IdP entity id")
+	}
+	result.Issuer = sp.IdPEntityId
+
+	// subject
+	// ---
+	subject := el.Element(NamespaceAssertion, "Subject")
+	if subject == nil {
+		return nil, errors.New("missing SAML assertion subject")
+	}
+	nameId := subject.Element(NamespaceAssertion, "NameID")
+	if nameId == nil || strings.TrimSpace(nameId.Text()) == "" {
+		return nil, errors.New("missing SAML assertion subject NameID")
+	}
+	result.NameId = strings.TrimSpace(nameId.Text())
+	result.NameIdFormat = nameId.Attr("Format")
+
+	var hasValidConfirmation bool
+	for _, confirmation := range subject.Elements(NamespaceAssertion, "SubjectConfirmation") {
+		if confirmation.Attr("Method") != subjectConfirmationBearer {
+			continue
+		}
+
+		data := confirmation.Element(NamespaceAssertion, "SubjectConfirmationData")
+		if data == nil ||
+			data.Attr("Recipient") != sp.ACSURL ||
+			data.Attr("InResponseTo") != inResponseTo {
+			continue
+		}
+
+		notOnOrAfter, err := parseTime(data.Attr("NotOnOrAfter"))
+		if err != nil || notOnOrAfter.IsZero() || !now.Before(notOnOrAfter.Add(skew)) {
+			continue
+		}
+
+		hasValidConfirmation = true
+		result.ExpiresAt = notOnOrAfter
+		break
+	}
+	if !hasValidConfirmation {
+		return nil, errors.New("missing or invalid SAML assertion bearer subject confirmation")
+	}
+
+	// conditions
+	// ---
+	conditions := el.Element(NamespaceAssertion, "Conditions")
+	if conditions == nil {
+		return nil, errors.New("missing SAML assertion conditions")
+	}
+
+	notBefore, err := parseTime(conditions.Attr("NotBefore"))
+	if err != nil {
+		return nil, err
+	}
+	if !notBefore.IsZero() && now.Add(skew).Before(notBefore) {
+		return nil, errors.New("the SAML assertion is not valid yet")
+	}
+
+	notOnOrAfter, err := parseTime(conditions.Attr("NotOnOrAfter"))
+	if err != nil {
+		return nil, err
+	}
+	if !notOnOrAfter.IsZero() {
+		if !now.Before(notOnOrAfter.Add(skew)) {
+			return nil, errors.New("the SAML assertion has expired")
+		}
+		if notOnOrAfter.Before(result.ExpiresAt) {
+			result.ExpiresAt = notOnOrAfter
+		}
+	}
+
+	restrictions := conditions.Elements(NamespaceAssertion, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return nil, errors.New("missing SAML assertion audience restriction")
+	}
+	for _, restriction := range restrictions {
+		var audiences []string
+		for _, audience := range restriction.Elements(NamespaceAssertion, "Audience") {
+			audiences = append(audiences, strings.TrimSpace(audience.Text()))
+		}
+		if !slices.Contains(audiences, sp.EntityId) {
+			return nil, errors.New("the SAML assertion audience doesn't match with the SP entity id")
+		}
+	}
+
+	// authn statement
+	// ---
+	if authn := el.Element(NamespaceAssertion, "AuthnStatement"); authn != nil {
+		result.SessionIndex = authn.Attr("SessionIndex")
+	}
+
+	// attributes
+	// ---
+	for _, statement := range el.Elements(NamespaceAssertion, "AttributeStatement") {
+		for _, attr := range statement.Elements(NamespaceAssertion, "Attribute") {
+			item := &AssertionAttribute{
+				Name:         attr.Attr("Name"),
+				FriendlyName: attr.Attr("FriendlyName"),
+			}
+			for _, value := range attr.Elements(NamespaceAssertion, "AttributeValue") {
+				item.Values = append(item.Values, strings.TrimSpace(value.Text()))
+			}
+			result.Attributes = append(result.Attributes, item)
+		}
+	}
+
+	return result, nil
+}
+
+func (sp *ServiceProvider) now() time.Time {
+	if sp.Now != nil {
+		return sp.Now()
+	}
+	return time.Now()
+}
+
+// parseTime parses an xs:dateTime value (returns zero time for empty value).
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SAML date time %q", value)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,360 @@
+package saml_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/saml"
+)
+
+const (
+	testSPEntityId = "https://sp.example.com/metadata"
+	testSPACSURL   = "https://sp.example.com/acs"
+)
+
+func newTestServiceProvider(t *testing.T) (*saml.ServiceProvider, *tests.TestSAMLIdP) {
+	idp, err := tests.NewTestSAMLIdP("https://idp.example.com", "https://idp.example.com/sso?tenant=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certs, err := saml.ParseCertificates(idp.CertificatePEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sp := &saml.ServiceProvider{
+		EntityId:        testSPEntityId,
+		ACSURL:          testSPACSURL,
+		NameIdFormat:    saml.NameIdFormatEmailAddress,
+		IdPEntityId:     idp.EntityId,
+		IdPSSOURL:       idp.SSOURL,
+		IdPCertificates: certs,
+	}
+
+	return sp, idp
+}
+
+func TestAuthnRequestURL(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := newTestServiceProvider(t)
+
+	rawURL, err := sp.AuthnRequestURL("_test_id", "test_state")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Host != "idp.example.com" || u.Path != "/sso" {
+		t.Fatalf("Expected the IdP SSO url, got %q", rawURL)
+	}
+
+	query := u.Query()
+
+	if v := query.Get("tenant"); v != "1" {
+		t.Fatalf("Expected the original IdP url query params to be preserved, got %q", v)
+	}
+
+	if v := query.Get("RelayState"); v != "test_state" {
+		t.Fatalf("Expected RelayState test_state, got %q", v)
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(query.Get("SAMLRequest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, err := saml.ParseElement(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !request.Is(saml.NamespaceProtocol, "AuthnRequest") {
+		t.Fatalf("Expected AuthnRequest, got %s", raw)
+	}
+
+	expectedAttrs := map[string]string{
+		"ID":                          "_test_id",
+		"Version":                     "2.0",
+		"Destination":                 sp.IdPSSOURL,
+		"AssertionConsumerServiceURL": testSPACSURL,
+		"ProtocolBinding":             saml.BindingHTTPPost,
+	}
+	for name, expected := range expectedAttrs {
+		if v := request.Attr(name); v != expected {
+			t.Fatalf("Expected %s attribute %q, got %q", name, expected, v)
+		}
+	}
+
+	if issuer := request.Element(saml.NamespaceAssertion, "Issuer"); issuer == nil || issuer.Text() != testSPEntityId {
+		t.Fatalf("Expected issuer %q, got %s", testSPEntityId, raw)
+	}
+
+	policy := request.Element(saml.NamespaceProtocol, "NameIDPolicy")
+	if policy == nil || policy.Attr("Format") != saml.NameIdFormatEmailAddress {
+		t.Fatalf("Expected NameIDPolicy with email format, got %s", raw)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := newTestServiceProvider(t)
+
+	metadata := sp.Metadata()
+
+	el, err := saml.ParseElement(metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !el.Is(saml.NamespaceMetadata, "EntityDescriptor") || el.Attr("entityID") != testSPEntityId {
+		t.Fatalf("Invalid EntityDescriptor:\n%s", metadata)
+	}
+
+	descriptor := el.Element(saml.NamespaceMetadata, "SPSSODescriptor")
+	if descriptor == nil || descriptor.Attr("WantAssertionsSigned") != "true" {
+		t.Fatalf("Invalid SPSSODescriptor:\n%s", metadata)
+	}
+
+	acs := descriptor.Element(saml.NamespaceMetadata, "AssertionConsumerService")
+	if acs == nil || acs.Attr("Location") != testSPACSURL || acs.Attr("Binding") != saml.BindingHTTPPost {
+		t.Fatalf("Invalid AssertionConsumerService:\n%s", metadata)
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	t.Parallel()
+
+	sp, idp := newTestServiceProvider(t)
+
+	_, otherIdP := newTestServiceProvider(t)
+
+	validOptions := func() tests.TestSAMLResponse {
+		return tests.TestSAMLResponse{
+			Destination:   testSPACSURL,
+			Audience:      testSPEntityId,
+			InResponseTo:  "_request",
+			NameId:        "test@example.com",
+			NameIdFormat:  saml.NameIdFormatEmailAddress,
+			AssertionId:   "_assertion",
+			SignAssertion: true,
+			Attributes: map[string][]string{
+				"name":   {"John Doe"},
+				"groups": {"admins", "staff"},
+			},
+		}
+	}
+
+	scenarios := []struct {
+		name          string
+		idp           *tests.TestSAMLIdP
+		options       func(o *tests.TestSAMLResponse)
+		tamper        func(doc string) string
+		inResponseTo  string
+		expectedError string
+	}{
+		{
+			name:         "signed assertion",
+			inResponseTo: "_request",
+		},
+		{
+			name: "signed response",
+			options: func(o *tests.TestSAMLResponse) {
+				o.SignAssertion = false
+				o.SignResponse = true
+			},
+			inResponseTo: "_request",
+		},
+		{
+			name: "signed response and assertion",
+			options: func(o *tests.TestSAMLResponse) {
+				o.SignResponse = true
+			},
+			inResponseTo: "_request",
+		},
+		{
+			name: "IdP-initiated",
+			options: func(o *tests.TestSAMLResponse) {
+				o.InResponseTo = ""
+			},
+		},
+		{
+			name: "unsigned",
+			options: func(o *tests.TestSAMLResponse) {
+				o.SignAssertion = false
+			},
+			inResponseTo:  "_request",
+			expectedError: "must be signed",
+		},
+		{
+			name:          "untrusted IdP key",
+			idp:           otherIdP,
+			inResponseTo:  "_request",
+			expectedError: "invalid SAML assertion signature",
+		},
+		{
+			name:          "tampered assertion",
+			tamper:        func(doc string) string { return strings.Replace(doc, "John Doe", "Jane Doe", 1) },
+			inResponseTo:  "_request",
+			expectedError: "invalid SAML assertion signature",
+		},
+		{
+			name: "tampered unsigned assertion of signed response",
+			options: func(o *tests.TestSAMLResponse) {
+				o.SignAssertion = false
+				o.SignResponse = true
+			},
+			tamper:        func(doc string) string { return strings.Replace(doc, "test@example.com", "admin@example.com", 1) },
+			inResponseTo:  "_request",
+			expectedError: "invalid SAML response signature",
+		},
+		{
+			name:          "unsolicited response when a request is expected",
+			options:       func(o *tests.TestSAMLResponse) { o.InResponseTo = "" },
+			inResponseTo:  "_request",
+			expectedError: "InResponseTo",
+		},
+		{
+			name:          "solicited response for unknown request",
+			inResponseTo:  "_other",
+			expectedError: "InResponseTo",
+		},
+		{
+			name:          "different destination",
+			options:       func(o *tests.TestSAMLResponse) { o.Destination = "https://other.example.com/acs" },
+			inResponseTo:  "_request",
+			expectedError: "destination",
+		},
+		{
+			name:          "different audience",
+			options:       func(o *tests.TestSAMLResponse) { o.Audience = "https://other.example.com" },
+			inResponseTo:  "_request",
+			expectedError: "audience",
+		},
+		{
+			name:          "expired",
+			options:       func(o *tests.TestSAMLResponse) { o.IssueInstant = time.Now().Add(-time.Hour) },
+			inResponseTo:  "_request",
+			expectedError: "subject confirmation",
+		},
+		{
+			name:          "not valid yet",
+			options:       func(o *tests.TestSAMLResponse) { o.IssueInstant = time.Now().Add(time.Hour) },
+			inResponseTo:  "_request",
+			expectedError: "not valid yet",
+		},
+		{
+			name:          "missing NameID",
+			options:       func(o *tests.TestSAMLResponse) { o.NameId = "" },
+			inResponseTo:  "_request",
+			expectedError: "NameID",
+		},
+		{
+			name:          "unsuccessful status",
+			options:       func(o *tests.TestSAMLResponse) { o.Status = "urn:oasis:names:tc:SAML:2.0:status:Requester" },
+			inResponseTo:  "_request",
+			expectedError: "unsuccessful SAML response status",
+		},
+		{
+			name:          "invalid encoding",
+			tamper:        func(doc string) string { return "!" + doc },
+			inResponseTo:  "_request",
+			expectedError: "encoding",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			options := validOptions()
+			if s.options != nil {
+				s.options(&options)
+			}
+
+			testIdP := idp
+			if s.idp != nil {
+				testIdP = s.idp
+			}
+
+			encoded, err := testIdP.NewResponse(options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if s.tamper != nil {
+				raw, err := base64.StdEncoding.DecodeString(encoded)
+				if err != nil {
+					t.Fatal(err)
+				}
+				tampered := s.tamper(string(raw))
+				if strings.HasPrefix(tampered, "!") {
+					encoded = tampered
+				} else {
+					encoded = base64.StdEncoding.EncodeToString([]byte(tampered))
+				}
+			}
+
+			assertion, err := sp.ParseResponse(encoded, s.inResponseTo)
+
+			if s.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), s.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", s.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected nil error, got %v", err)
+			}
+
+			if assertion.Id != "_assertion" {
+				t.Fatalf("Expected assertion id _assertion, got %q", assertion.Id)
+			}
+
+			if assertion.NameId != options.NameId || assertion.NameIdFormat != options.NameIdFormat {
+				t.Fatalf("Expected NameID %q (%s), got %q (%s)", options.NameId, options.NameIdFormat, assertion.NameId, assertion.NameIdFormat)
+			}
+
+			if assertion.Issuer != idp.EntityId {
+				t.Fatalf("Expected issuer %q, got %q", idp.EntityId, assertion.Issuer)
+			}
+
+			if assertion.SessionIndex == "" {
+				t.Fatal("Expected non-empty session index")
+			}
+
+			if assertion.ExpiresAt.Before(time.Now()) {
+				t.Fatalf("Expected future ExpiresAt, got %v", assertion.ExpiresAt)
+			}
+
+			if v := assertion.Value("name"); v != "John Doe" {
+				t.Fatalf("Expected name attribute John Doe, got %q", v)
+			}
+
+			if v := assertion.Values("groups"); len(v) != 2 || v[0] != "admins" || v[1] != "staff" {
+				t.Fatalf("Expected groups attribute [admins staff], got %v", v)
+			}
+
+			if v := assertion.Values("missing"); v != nil {
+				t.Fatalf("Expected nil missing attribute, got %v", v)
+			}
+		})
+	}
+}
@@ -0,0 +1,510 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"golang.org/x/sync/errgroup"
+)
+
+// MaxAggregateExprLimit specifies the max allowed total number of
+// group by and aggregate expressions in a single aggregate query.
+const MaxAggregateExprLimit int = 10
+
+// Common aggregate errors.
+var (
+	ErrEmptyAggregate     = errors.New("at least one group by or aggregate expression is required")
+	ErrAggregateExprLimit = errors.New("max group by and aggregate expressions limit reached")
+)
+
+// Supported aggregate functions.
+const (
+	AggregateCount string = "count"
+	AggregateSum   string = "sum"
+	AggregateAvg   string = "avg"
+	AggregateMin   string = "min"
+	AggregateMax   string = "max"
+)
+
+var aggregateFunctions = map[string]string{
+	AggregateCount: "COUNT",
+	AggregateSum:   "SUM",
+	AggregateAvg:   "AVG",
+	AggregateMin:   "MIN",
+	AggregateMax:   "MAX",
+}
+
+// GroupBy sets the group by expressions used by `ExecAggregate()`.
+//
+// Each expression must be either a single identifier (e.g. "status", "author.org.name")
+// or a registered token function (e.g. "strftime('%Y-%m-%d', created)").
+func (s *Provider) GroupBy(exprs ...string) *Provider {
+	s.groupBy = exprs
+	return s
+}
+
+// Aggregate sets the aggregate expressions used by `ExecAggregate()`.
+//
+// Each expression must be one of the supported aggregate functions, for example:
+// "count()", "count(field)", "sum(field)", "avg(field)", "min(field)", "max(field)".
+func (s *Provider) Aggregate(exprs ...string) *Provider {
+	s.aggregate = exprs
+	return s
+}
+
+// ExecAggregate executes the search provider as an aggregate query
+// grouped by the provider's `groupBy` expressions and returns
+// a paginated result with []map[string]any items.
+//
+// Each item contains the group by and aggregate expressions values
+// keyed by their trimmed expressions text, for example:
+//
+//	{"status": "active", "count()": 10, "sum(total)": 123.5}
+//
+// The provider filters are applied to the base query rows before the aggregation
+// and the rows are deduplicated by the `CountCol` so that joins that are
+// part only of the filter don't affect the aggregated values.
+// The multi-match aggregate arguments (e.g. "sum(rel.total)" of a multiple relation)
+// are first aggregated per row with a correlated subquery so that the equal values
+// of the different joined rows are not collapsed by the deduplication.
+//
+// The provider sort fields (if any) must match exactly one of the group by
+// or aggregate expressions (e.g. "-count()"). If not set, the result is sorted
+// by the group by expressions.
+//
+// Note that the base query pre and post fragments are overwritten.
+func (s *Provider) ExecAggregate() (*Result, error) {
+	if s.query == nil {
+		return nil, ErrEmptyQuery
+	}
+
+	if len(s.groupBy) == 0 && len(s.aggregate) == 0 {
+		return nil, ErrEmptyAggregate
+	}
+
+	if len(s.groupBy)+len(s.aggregate) > MaxAggregateExprLimit {
+		return nil, ErrAggregateExprLimit
+	}
+
+	// shallow clone the provider's query
+	innerQuery := *s.query
+
+	if err := s.applyFilters(&innerQuery); err != nil {
+		return nil, err
+	}
+
+	// rows deduplication column
+	idCol := s.countCol
+	queryInfo := innerQuery.Info()
+	if len(queryInfo.From) > 0 {
+		idCol = dbutils.AliasOrIdentifier(queryInfo.From[0]) + "." + idCol
+	}
+
+	// copy the base query params to avoid modifying the shared map
+	innerParams := make(dbx.Params, len(queryInfo.Params))
+	for k, v := range queryInfo.Params {
+		innerParams[k] = v
+	}
+
+	innerSelects := []string{"[[" + idCol + "]] AS [[agg_id]]"}
+	outerSelects := make([]string, 0, len(s.groupBy)+len(s.aggregate))
+	groupCols := make([]string, 0, len(s.groupBy))
+	keys := make([]string, 0, len(s.groupBy)+len(s.aggregate))
+
+	for i, expr := range s.groupBy {
+		expr = strings.TrimSpace(expr)
+
+		resolved, err := s.resolveAggregateArg(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid group by expression %q: %w", expr, err)
+		}
+		for k, v := range resolved.Params {
+			innerParams[k] = v
+		}
+
+		identifier, err := guardedIdentifier(resolved, queryInfo.Builder, innerParams)
+		if err != nil {
+			return nil, fmt.Errorf("invalid group by expression %q: %w", expr, err)
+		}
+
+		alias := "agg_g" + strconv.Itoa(i)
+		innerSelects = append(innerSelects, identifier+" AS [["+alias+"]]")
+		groupCols = append(groupCols, "[["+alias+"]]")
+		outerSelects = append(outerSelects, "[["+alias+"]]")
+		keys = append(keys, expr)
+	}
+
+	for i, expr := range s.aggregate {
+		expr = strings.TrimSpace(expr)
+
+		fn, arg, err := parseAggregateExpr(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid aggregate expression %q: %w", expr, err)
+		}
+
+		if arg == nil {
+			// count all unique rows
+			outerSelects = append(outerSelects, "COUNT(DISTINCT [[agg_id]])")
+		} else {
+			resolved, err := resolveToken(*arg, s.fieldResolver)
+			if err != nil || resolved.Identifier == "" {
+				return nil, fmt.Errorf("invalid aggregate expression %q: failed to resolve argument", expr)
+			}
+			for k, v := range resolved.Params {
+				innerParams[k] = v
+			}
+
+			alias := "agg_a" + strconv.Itoa(i)
+
+			if resolved.MultiMatchSubQuery == nil || queryInfo.Builder == nil {
+				identifier, err := guardedIdentifier(resolved, queryInfo.Builder, innerParams)
+				if err != nil {
+					return nil, fmt.Errorf("invalid aggregate expression %q: %w", expr, err)
+				}
+
+				innerSelects = append(innerSelects, identifier+" AS [["+alias+"]]")
+				outerSelects = append(outerSelects, fn+"([["+alias+"]])")
+			} else {
+				// the guard condition references the main query joins
+				// and can't be applied to the per row subquery values
+				if resolved.AfterBuild != nil {
+					return nil, fmt.Errorf("invalid aggregate expression %q: the argument is not allowed", expr)
+				}
+
+				// the deduplicated inner rows would collapse the equal values
+				// of the different joined rows so instead the multi-match values
+				// are aggregated per row with a correlated subquery
+				mmSelects, mmOuterSelect := multiMatchAggregateSelects(
+					fn,
+					alias,
+					queryInfo.Builder.QueryBuilder(),
+					resolved.MultiMatchSubQuery,
+					innerParams,
+				)
+				innerSelects = append(innerSelects, mmSelects...)
+				outerSelects = append(outerSelects, mmOuterSelect)
+			}
+		}
+
+		keys = append(keys, expr)
+	}
+
+	// resolve the result order
+	orderBy := make([]string, 0, len(groupCols))
+	if len(s.sort) > s.maxSortExprLimit {
+		return nil, ErrSortExprLimit
+	}
+	for _, sortField := range s.sort {
+		idx := -1
+		for i, key := range keys {
+			if key == strings.TrimSpace(sortField.Name) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid sort field %q", sortField.Name)
+		}
+		direction := SortAsc
+		if strings.EqualFold(sortField.Direction, SortDesc) {
+			direction = SortDesc
+		}
+		orderBy = append(orderBy, outerSelects[idx]+" "+direction)
+	}
+	if len(orderBy) == 0 {
+		for _, col := range groupCols {
+			orderBy = append(orderBy, col+" "+SortAsc)
+		}
+	}
+
+	innerQuery.Bind(innerParams)
+
+	// apply field resolver query modifications (if any)
+	if err := s.fieldResolver.UpdateQuery(&innerQuery); err != nil {
+		return nil, err
+	}
+
+	// note: innerQuery is shallow cloned and slice/map in-place modifications should be avoided
+	innerQuery.Select(innerSelects...).
+		Distinct(true).
+		GroupBy( /* reset */ ).
+		OrderBy( /* reset */ ).
+		Limit(-1).
+		Offset(0)
+
+	s.normalizePagination()
+
+	var groupClause string
+	if len(groupCols) > 0 {
+		groupClause = " GROUP BY " + strings.Join(groupCols, ", ")
+	}
+
+	// negative value to differentiate from the zero default
+	totalCount := -1
+	totalPages := -1
+
+	countQuery := innerQuery // shallow clone
+	countExec := func() error {
+		if len(groupCols) == 0 {
+			totalCount = 1 // there is always a single aggregated row
+		} else {
+			err := countQuery.
+				PreFragment("SELECT COUNT(*) FROM (SELECT 1 FROM (").
+				PostFragment(") [[agg]]" + groupClause + ")").
+				Row(&totalCount)
+			if err != nil {
+				return err
+			}
+		}
+
+		totalPages = int(math.Ceil(float64(totalCount) / float64(s.perPage)))
+
+		return nil
+	}
+
+	items := []map[string]any{}
+	itemsQuery := innerQuery // shallow clone
+	itemsExec := func() error {
+		postFragment := ") [[agg]]" + groupClause
+		if len(orderBy) > 0 {
+			postFragment += " ORDER BY " + strings.Join(orderBy, ", ")
+		}
+		postFragment += fmt.Sprintf(" LIMIT %d OFFSET %d", s.perPage, s.perPage*(s.page-1))
+
+		rows, err := itemsQuery.
+			PreFragment("SELECT " + strings.Join(outerSelects, ", ") + " FROM (").
+			PostFragment(postFragment).
+			Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			values := make([]any, len(keys))
+			pointers := make([]any, len(keys))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+
+			if err := rows.Scan(pointers...); err != nil {
+				return err
+			}
+
+			item := make(map[string]any, len(keys))
+			for i, key := range keys {
+				if b, ok := values[i].([]byte); ok {
+					item[key] = string(b)
+				} else {
+					item[key] = values[i]
+				}
+			}
+			items = append(items, item)
+		}
+
+		return rows.Err()
+	}
+
+	if !s.skipTotal {
+		// execute the 2 queries concurrently
+		errg := new(errgroup.Group)
+		errg.Go(routine.SafeWrap(countExec))
+		errg.Go(routine.SafeWrap(itemsExec))
+		if err := errg.Wait(); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := itemsExec(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Result{
+		Page:       s.page,
+		PerPage:    s.perPage,
+		TotalItems: totalCount,
+		TotalPages: totalPages,
+		Items:      items,
+	}, nil
+}
+
+// ParseAndExecAggregate is a short convenient method to trigger both
+// `Parse()` and `ExecAggregate()` in a single call.
+func (s *Provider) ParseAndExecAggregate(urlQuery string) (*Result, error) {
+	if err := s.Parse(urlQuery); err != nil {
+		return nil, err
+	}
+
+	return s.ExecAggregate()
+}
+
+// resolveAggregateArg resolves a single identifier or token function expression.
+func (s *Provider) resolveAggregateArg(expr string) (*ResolverResult, error) {
+	if len(expr) > MaxSortFieldLength {
+		return nil, ErrSortFieldLengthLimit
+	}
+
+	token, err := scanSingleToken(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Type != fexpr.TokenIdentifier && token.Type != fexpr.TokenFunction {
+		return nil, errors.New("expected identifier or function")
+	}
+
+	resolved, err := resolveToken(token, s.fieldResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolved.Identifier == "" || strings.EqualFold(resolved.Identifier, "null") {
+		return nil, errors.New("failed to resolve expression")
+	}
+
+	return resolved, nil
+}
+
+// multiMatchAggregateSelects returns the inner per row select expressions
+// and the outer select expression for the aggregate function fn
+// of the provided multi-match argument subquery.
+func multiMatchAggregateSelects(
+	fn string,
+	alias string,
+	builder dbx.QueryBuilder,
+	mm *MultiMatchSubquery,
+	params dbx.Params,
+) ([]string, string) {
+	// build the subquery with the current query builder
+	// (the joins expressions could require db specific quoting)
+	subquery := strings.TrimPrefix(builder.BuildWhere(mm, params), "WHERE ")
+
+	perRow := func(rowFn string) string {
+		return "(SELECT " + rowFn + "([[multiMatchValue]]) FROM (" + subquery + "))"
+	}
+
+	switch fn {
+	case aggregateFunctions[AggregateCount]:
+		return []string{perRow("COUNT") + " AS [[" + alias + "]]"}, "COALESCE(SUM([[" + alias + "]]), 0)"
+	case aggregateFunctions[AggregateAvg]:
+		sumAlias := alias + "_sum"
+		countAlias := alias + "_count"
+		return []string{
+			perRow("SUM") + " AS [[" + sumAlias + "]]",
+			perRow("COUNT") + " AS [[" + countAlias + "]]",
+		}, "(SUM([[" + sumAlias + "]]) * 1.0 / SUM([[" + countAlias + "]]))"
+	default: // SUM, MIN, MAX
+		return []string{perRow(fn) + " AS [[" + alias + "]]"}, fn + "([[" + alias + "]])"
+	}
+}
+
+// parseAggregateExpr parses a single aggregate expression (e.g. "sum(total)")
+// and returns its SQL function name and its optional argument token.
+func parseAggregateExpr(expr string) (string, *fexpr.Token, error) {
+	if len(expr) > MaxSortFieldLength {
+		return "", nil, ErrSortFieldLengthLimit
+	}
+
+	token, err := scanSingleToken(expr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	fn, ok := aggregateFunctions[strings.ToLower(token.Literal)]
+	if token.Type != fexpr.TokenFunction || !ok {
+		return "", nil, errors.New("unsupported aggregate function")
+	}
+
+	args, _ := token.Meta.([]fexpr.Token)
+
+	switch {
+	case len(args) == 0 && fn == aggregateFunctions[AggregateCount]:
+		return fn, nil, nil
+	case len(args) != 1:
+		return "", nil, errors.New("expected exactly 1 argument")
+	case args[0].Type != fexpr.TokenIdentifier && args[0].Type != fexpr.TokenFunction:
+		return "", nil, errors.New("the argument must be an identifier or function")
+	}
+
+	return fn, &args[0], nil
+}
+
+// scanSingleToken scans the provided expression and
+// returns its first and only non-whitespace token.
+func scanSingleToken(expr string) (fexpr.Token, error) {
+	scanner := fexpr.NewScanner([]byte(expr))
+
+	var result *fexpr.Token
+	for {
+		t, err := scanner.Scan()
+		if err != nil {
+			return fexpr.Token{}, err
+		}
+
+		if t.Type == fexpr.TokenEOF {
+			break
+		}
+
+		if t.Type == fexpr.TokenWS {
+			continue
+		}
+
+		if result != nil {
+			return fexpr.Token{}, errors.New("expected a single expression")
+		}
+
+		result = &t
+	}
+
+	if result == nil {
+		return fexpr.Token{}, errors.New("empty expression")
+	}
+
+	return *result, nil
+}
+
+// splitExprList splits the provided comma separated expressions list
+// ignoring the commas inside function parenthesis and quoted texts.
+//
+// Example:
+//
+//	splitExprList("a, strftime('%Y,%m', b)") // ["a", "strftime('%Y,%m', b)"]
+func splitExprList(str string) []string {
+	result := []string{}
+
+	var depth int
+	var quote rune
+	var start int
+	for i, ch := range str {
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == ',' && depth == 0:
+			if part := strings.TrimSpace(str[start:i]); part != "" {
+				result = append(result, part)
+			}
+			start = i + 1
+		}
+	}
+
+	if part := strings.TrimSpace(str[start:]); part != "" {
+		result = append(result, part)
+	}
+
+	return result
+}
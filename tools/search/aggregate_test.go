@@ -0,0 +1,202 @@
+package search
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+)
+
+func TestProviderGroupByAndAggregate(t *testing.T) {
+	p := NewProvider(&testFieldResolver{})
+
+	if err := p.Parse("groupBy=a,strftime('%25Y,%25m',b)&aggregate=count(),sum(c)"); err != nil {
+		t.Fatal(err)
+	}
+
+	encodedGroupBy, _ := json.Marshal(p.groupBy)
+	if v := string(encodedGroupBy); v != `["a","strftime('%Y,%m',b)"]` {
+		t.Fatalf("Unexpected groupBy %s", v)
+	}
+
+	encodedAggregate, _ := json.Marshal(p.aggregate)
+	if v := string(encodedAggregate); v != `["count()","sum(c)"]` {
+		t.Fatalf("Unexpected aggregate %s", v)
+	}
+
+	p.GroupBy("x").Aggregate("max(y)")
+
+	encodedGroupBy, _ = json.Marshal(p.groupBy)
+	if v := string(encodedGroupBy); v != `["x"]` {
+		t.Fatalf("Unexpected groupBy %s", v)
+	}
+
+	encodedAggregate, _ = json.Marshal(p.aggregate)
+	if v := string(encodedAggregate); v != `["max(y)"]` {
+		t.Fatalf("Unexpected aggregate %s", v)
+	}
+}
+
+func TestProviderExecAggregate(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	testDB.Insert("test", dbx.Params{"id": 3, "test1": 3, "test2": "test2.1"}).Execute()
+
+	query := testDB.Select("*").From("test").OrderBy("test1 ASC")
+
+	scenarios := []struct {
+		name         string
+		groupBy      []string
+		aggregate    []string
+		filter       []FilterData
+		sort         []SortField
+		perPage      int
+		skipTotal    bool
+		expectError  bool
+		expectResult string
+	}{
+		{
+			"missing group by and aggregate expressions",
+			nil,
+			nil,
+			nil,
+			nil,
+			10,
+			false,
+			true,
+			"",
+		},
+		{
+			"unknown aggregate function",
+			nil,
+			[]string{"test(test1)"},
+			nil,
+			nil,
+			10,
+			false,
+			true,
+			"",
+		},
+		{
+			"aggregate with multiple arguments",
+			nil,
+			[]string{"sum(test1, test2)"},
+			nil,
+			nil,
+			10,
+			false,
+			true,
+			"",
+		},
+		{
+			"invalid group by expression",
+			[]string{"test1 test2"},
+			nil,
+			nil,
+			nil,
+			10,
+			false,
+			true,
+			"",
+		},
+		{
+			"invalid sort field",
+			[]string{"test2"},
+			[]string{"count()"},
+			nil,
+			[]SortField{{"test1", SortAsc}},
+			10,
+			false,
+			true,
+			"",
+		},
+		{
+			"only aggregate expressions",
+			nil,
+			[]string{"count()", "sum(test1)", "max(test1)"},
+			[]FilterData{"test1 > 1"},
+			nil,
+			10,
+			false,
+			false,
+			`{"items":[{"count()":2,"max(test1)":3,"sum(test1)":5}],"page":1,"perPage":10,"totalItems":1,"totalPages":1}`,
+		},
+		{
+			"group by with default sort",
+			[]string{"test2"},
+			[]string{"count()", "sum(test1)"},
+			nil,
+			nil,
+			10,
+			false,
+			false,
+			`{"items":[{"count()":2,"sum(test1)":4,"test2":"test2.1"},{"count()":1,"sum(test1)":2,"test2":"test2.2"}],"page":1,"perPage":10,"totalItems":2,"totalPages":1}`,
+		},
+		{
+			"group by with custom sort and pagination",
+			[]string{"test2"},
+			[]string{"count()"},
+			nil,
+			[]SortField{{"count()", SortAsc}},
+			1,
+			true,
+			false,
+			`{"items":[{"count()":1,"test2":"test2.2"}],"page":1,"perPage":1,"totalItems":-1,"totalPages":-1}`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := NewProvider(&testFieldResolver{}).
+				Query(query).
+				GroupBy(s.groupBy...).
+				Aggregate(s.aggregate...).
+				Filter(s.filter).
+				Sort(s.sort).
+				PerPage(s.perPage).
+				SkipTotal(s.skipTotal).
+				ExecAggregate()
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			encoded, _ := json.Marshal(result)
+			if string(encoded) != s.expectResult {
+				t.Fatalf("Expected result %v, got \n%v", s.expectResult, string(encoded))
+			}
+		})
+	}
+}
+
+func TestSplitExprList(t *testing.T) {
+	scenarios := []struct {
+		str      string
+		expected string
+	}{
+		{"", `[]`},
+		{" , ", `[]`},
+		{"a", `["a"]`},
+		{"a, b ,c", `["a","b","c"]`},
+		{"count(), sum(a)", `["count()","sum(a)"]`},
+		{`strftime('%Y,%m', a), strftime("%d,)", b)`, `["strftime('%Y,%m', a)","strftime(\"%d,)\", b)"]`},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.str, func(t *testing.T) {
+			encoded, _ := json.Marshal(splitExprList(s.str))
+			if string(encoded) != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, encoded)
+			}
+		})
+	}
+}
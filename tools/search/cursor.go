@@ -0,0 +1,135 @@
+package search
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+)
+
+// cursorIdKey is the sort key that is always appended as
+// tie-breaker to the cursor pagination sort fields.
+const cursorIdKey string = "id"
+
+// ErrInvalidCursor is returned when the provided pagination cursor
+// is malformed or doesn't match the current sort fields.
+var ErrInvalidCursor = errors.New("invalid or malformed cursor")
+
+// ErrCursorRandomSort is returned when cursor pagination is used in combination with random sort.
+var ErrCursorRandomSort = errors.New("cursor pagination cannot be used with random sort")
+
+// ErrCursorMultiMatchSort is returned when cursor pagination is used in combination
+// with a sort field that could have multiple values per row (e.g. back-relation fields).
+var ErrCursorMultiMatchSort = errors.New("cursor pagination cannot be used with multi-match sort fields")
+
+// cursorKey holds a single resolved cursor sort key.
+type cursorKey struct {
+	identifier string
+	direction  string
+}
+
+// encodeCursor encodes the provided sort key values into an opaque cursor string.
+func encodeCursor(values []any) (string, error) {
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor decodes the provided opaque cursor string into
+// its sort key values and verifies that they are exactly totalKeys.
+func decodeCursor(cursor string, totalKeys int) ([]any, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	values := []any{}
+	if err := decoder.Decode(&values); err != nil || len(values) != totalKeys {
+		return nil, ErrInvalidCursor
+	}
+
+	for i, v := range values {
+		switch val := v.(type) {
+		case nil, string, bool:
+			// keep as it is
+		case json.Number:
+			if n, err := val.Int64(); err == nil {
+				values[i] = n
+			} else if f, err := val.Float64(); err == nil {
+				values[i] = f
+			} else {
+				return nil, ErrInvalidCursor
+			}
+		default:
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return values, nil
+}
+
+// normalizeCursorValue converts the scanned db value into a JSON serializable one.
+func normalizeCursorValue(v any) any {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+
+	return v
+}
+
+// buildCursorExpr builds a keyset pagination expression that matches
+// all rows positioned after the ones identified by the provided values.
+//
+// The generated expression follows the SQLite default NULL ordering
+// (NULLs are considered smaller than any other value) and has the form:
+//
+//	(k0 > v0) OR (k0 IS v0 AND k1 > v1) OR (k0 IS v0 AND k1 IS v1 AND k2 > v2)...
+func buildCursorExpr(keys []cursorKey, values []any) dbx.Expression {
+	params := dbx.Params{}
+	orParts := make([]string, 0, len(keys))
+	eqParts := make([]string, 0, len(keys))
+
+	for i, key := range keys {
+		placeholder := fmt.Sprintf("cursor%d", i)
+		params[placeholder] = values[i]
+
+		var strict string
+		isDesc := strings.EqualFold(key.direction, SortDesc)
+		switch {
+		case values[i] == nil && isDesc:
+			// nothing can be positioned after NULL in descending order
+		case values[i] == nil:
+			strict = key.identifier + " IS NOT NULL"
+		case isDesc:
+			strict = fmt.Sprintf("(%s < {:%s} OR %s IS NULL)", key.identifier, placeholder, key.identifier)
+		default:
+			strict = fmt.Sprintf("%s > {:%s}", key.identifier, placeholder)
+		}
+
+		if strict != "" {
+			if len(eqParts) == 0 {
+				orParts = append(orParts, strict)
+			} else {
+				orParts = append(orParts, "("+strings.Join(eqParts, " AND ")+" AND "+strict+")")
+			}
+		}
+
+		eqParts = append(eqParts, fmt.Sprintf("%s IS {:%s}", key.identifier, placeholder))
+	}
+
+	if len(orParts) == 0 {
+		return dbx.NewExp("0=1")
+	}
+
+	return dbx.NewExp("("+strings.Join(orParts, " OR ")+")", params)
+}
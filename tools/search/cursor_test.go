@@ -0,0 +1,102 @@
+package search
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+)
+
+func TestEncodeDecodeCursor(t *testing.T) {
+	cursor, err := encodeCursor([]any{nil, "a", 123, 1.5, true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := decodeCursor(cursor, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []any{nil, "a", int64(123), 1.5, true}
+	for i, v := range expected {
+		if values[i] != v {
+			t.Fatalf("Expected value %d to be %#v, got %#v", i, v, values[i])
+		}
+	}
+}
+
+func TestDecodeCursorErrors(t *testing.T) {
+	validCursor, _ := encodeCursor([]any{"a", "b"})
+	objCursor, _ := encodeCursor([]any{"a", map[string]any{"b": 1}})
+
+	scenarios := []struct {
+		name      string
+		cursor    string
+		totalKeys int
+	}{
+		{"invalid base64", "!@#", 1},
+		{"invalid json", "YWJj", 1},
+		{"keys mismatch", validCursor, 3},
+		{"unsupported value type", objCursor, 2},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			_, err := decodeCursor(s.cursor, s.totalKeys)
+			if err != ErrInvalidCursor {
+				t.Fatalf("Expected ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBuildCursorExpr(t *testing.T) {
+	db := dbx.NewFromDB(nil, "")
+
+	scenarios := []struct {
+		name         string
+		keys         []cursorKey
+		values       []any
+		expectExpr   string
+		expectParams string
+	}{
+		{
+			"asc and desc keys",
+			[]cursorKey{{"a", SortAsc}, {"b", SortDesc}, {"id", SortAsc}},
+			[]any{1, "x", "y"},
+			"(a > {:cursor0} OR (a IS {:cursor0} AND (b < {:cursor1} OR b IS NULL)) OR (a IS {:cursor0} AND b IS {:cursor1} AND id > {:cursor2}))",
+			`{"cursor0":1,"cursor1":"x","cursor2":"y"}`,
+		},
+		{
+			"null values",
+			[]cursorKey{{"a", SortAsc}, {"b", SortDesc}, {"id", SortAsc}},
+			[]any{nil, nil, "y"},
+			"(a IS NOT NULL OR (a IS {:cursor0} AND b IS {:cursor1} AND id > {:cursor2}))",
+			`{"cursor0":null,"cursor1":null,"cursor2":"y"}`,
+		},
+		{
+			"no matching parts",
+			[]cursorKey{{"a", SortDesc}},
+			[]any{nil},
+			"0=1",
+			`{}`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			params := dbx.Params{}
+
+			expr := buildCursorExpr(s.keys, s.values).Build(db, params)
+			if expr != s.expectExpr {
+				t.Fatalf("Expected expression\n%s\ngot\n%s", s.expectExpr, expr)
+			}
+
+			encodedParams, _ := json.Marshal(params)
+			if string(encodedParams) != s.expectParams {
+				t.Fatalf("Expected params\n%s\ngot\n%s", s.expectParams, encodedParams)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"math"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -50,6 +51,9 @@ const (
 	SortQueryParam      string = "sort"
 	FilterQueryParam    string = "filter"
 	SkipTotalQueryParam string = "skipTotal"
+	CursorQueryParam    string = "cursor"
+	GroupByQueryParam   string = "groupBy"
+	AggregateQueryParam string = "aggregate"
 )
 
 // Result defines the returned search result structure.
@@ -59,6 +63,12 @@ type Result struct {
 	PerPage    int `json:"perPage"`
 	TotalItems int `json:"totalItems"`
 	TotalPages int `json:"totalPages"`
+
+	// NextCursor is the opaque cursor that could be used to fetch the next
+	// page items when the cursor pagination mode is enabled.
+	//
+	// It is empty if there are no more items or when the cursor pagination mode is not used.
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 // Provider represents a single configured search provider instance.
@@ -68,8 +78,11 @@ type Provider struct {
 	countCol           string
 	sort               []SortField
 	filter             []FilterData
+	groupBy            []string
+	aggregate          []string
 	page               int
 	perPage            int
+	cursor             *string
 	skipTotal          bool
 	maxFilterExprLimit int
 	maxSortExprLimit   int
@@ -150,6 +163,20 @@ func (s *Provider) PerPage(perPage int) *Provider {
 	return s
 }
 
+// Cursor enables the cursor (aka. keyset) pagination mode and sets
+// the opaque cursor from which the next items will be fetched.
+//
+// An empty cursor string fetches the first page items.
+//
+// In cursor mode the `page` field is ignored, the base query order is reset
+// and the "id" field is appended as tie-breaker to the sort fields
+// (if not already specified) to guarantee a deterministic order.
+// The cursor of the following page is returned as [Result.NextCursor].
+func (s *Provider) Cursor(cursor string) *Provider {
+	s.cursor = &cursor
+	return s
+}
+
 // Sort sets the `sort` field of the current search provider.
 func (s *Provider) Sort(sort []SortField) *Provider {
 	s.sort = sort
@@ -182,6 +209,8 @@ func (s *Provider) AddFilter(filter FilterData) *Provider {
 // The data from the "sort" and "filter" query parameters are appended
 // to the existing provider's `sort` and `filter` fields
 // (aka. using `AddSort` and `AddFilter`).
+//
+// The "groupBy" and "aggregate" query parameters are used only by `ExecAggregate()`.
 func (s *Provider) Parse(urlQuery string) error {
 	params, err := url.ParseQuery(urlQuery)
 	if err != nil {
@@ -212,6 +241,10 @@ func (s *Provider) Parse(urlQuery string) error {
 		s.PerPage(v)
 	}
 
+	if params.Has(CursorQueryParam) {
+		s.Cursor(params.Get(CursorQueryParam))
+	}
+
 	if raw := params.Get(SortQueryParam); raw != "" {
 		for _, sortField := range ParseSortFromString(raw) {
 			s.AddSort(sortField)
@@ -222,6 +255,14 @@ func (s *Provider) Parse(urlQuery string) error {
 		s.AddFilter(FilterData(raw))
 	}
 
+	if raw := params.Get(GroupByQueryParam); raw != "" {
+		s.GroupBy(splitExprList(raw)...)
+	}
+
+	if raw := params.Get(AggregateQueryParam); raw != "" {
+		s.Aggregate(splitExprList(raw)...)
+	}
+
 	return nil
 }
 
@@ -236,41 +277,88 @@ func (s *Provider) Exec(items any) (*Result, error) {
 	modelsQuery := *s.query
 
 	// build filters
-	for _, f := range s.filter {
-		if len(f) > MaxFilterLength {
-			return nil, ErrFilterLengthLimit
-		}
-		expr, err := f.BuildExprWithLimit(s.fieldResolver, s.maxFilterExprLimit)
-		if err != nil {
-			return nil, err
-		}
-		if expr != nil {
-			modelsQuery.AndWhere(expr)
-		}
+	if err := s.applyFilters(&modelsQuery); err != nil {
+		return nil, err
 	}
 
 	// apply sorting
 	if len(s.sort) > s.maxSortExprLimit {
 		return nil, ErrSortExprLimit
 	}
-	for _, sortField := range s.sort {
+	sortFields := s.sort
+	if s.cursor != nil {
+		// the cursor mode requires a unique and deterministic order
+		modelsQuery.OrderBy( /* reset */ )
+
+		hasIdSort := slices.ContainsFunc(sortFields, func(f SortField) bool {
+			return f.Name == cursorIdKey
+		})
+		if !hasIdSort {
+			sortFields = append(slices.Clone(sortFields), SortField{cursorIdKey, SortAsc})
+		}
+	}
+	cursorKeys := make([]cursorKey, 0, len(sortFields))
+	cursorIdIndex := -1
+	for _, sortField := range sortFields {
 		if len(sortField.Name) > MaxSortFieldLength {
 			return nil, ErrSortFieldLengthLimit
 		}
-		expr, err := sortField.BuildExpr(s.fieldResolver)
+
+		if sortField.Name == randomSortKey {
+			if s.cursor != nil {
+				return nil, ErrCursorRandomSort
+			}
+			modelsQuery.AndOrderBy("RANDOM()")
+			continue
+		}
+
+		resolved, err := sortField.resolve(s.fieldResolver)
 		if err != nil {
 			return nil, err
 		}
-		if expr != "" {
-			// ensure that _rowid_ expressions are always prefixed with the first FROM table
-			if sortField.Name == rowidSortKey && !strings.Contains(expr, ".") {
-				queryInfo := modelsQuery.Info()
-				if len(queryInfo.From) > 0 {
-					expr = "[[" + inflector.Columnify(queryInfo.From[0]) + "]]." + expr
-				}
+
+		// multi-match fields could have more than one value per row
+		// which makes the cursor position ambiguous
+		if s.cursor != nil && resolved.MultiMatchSubQuery != nil {
+			return nil, ErrCursorMultiMatchSort
+		}
+
+		// note: the guard params are merged with the resolved ones below
+		if resolved.Params == nil {
+			resolved.Params = dbx.Params{}
+		}
+		identifier, err := guardedIdentifier(resolved, modelsQuery.Info().Builder, resolved.Params)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(resolved.Params) > 0 {
+			// copy to avoid modifying the shared base query params
+			modelsQuery.Bind(mergeParams(modelsQuery.Info().Params, resolved.Params))
+		}
+
+		// ensure that _rowid_ expressions are always prefixed with the first FROM table
+		if sortField.Name == rowidSortKey && !strings.Contains(identifier, ".") {
+			queryInfo := modelsQuery.Info()
+			if len(queryInfo.From) > 0 {
+				identifier = "[[" + inflector.Columnify(queryInfo.From[0]) + "]]." + identifier
 			}
+		}
 
-			modelsQuery.AndOrderBy(expr)
+		if sortField.Name == cursorIdKey && cursorIdIndex < 0 {
+			cursorIdIndex = len(cursorKeys)
+		}
+		cursorKeys = append(cursorKeys, cursorKey{identifier: identifier, direction: sortField.Direction})
+
+		modelsQuery.AndOrderBy(identifier + " " + sortField.Direction)
+	}
+
+	var cursorValues []any
+	if s.cursor != nil && *s.cursor != "" {
+		var err error
+		cursorValues, err = decodeCursor(*s.cursor, len(cursorKeys))
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -279,17 +367,8 @@ func (s *Provider) Exec(items any) (*Result, error) {
 		return nil, err
 	}
 
-	// normalize page
-	if s.page <= 0 {
-		s.page = 1
-	}
-
-	// normalize perPage
-	if s.perPage <= 0 {
-		s.perPage = DefaultPerPage
-	} else if s.perPage > MaxPerPage {
-		s.perPage = MaxPerPage
-	}
+	// normalize page and perPage
+	s.normalizePagination()
 
 	// negative value to differentiate from the zero default
 	totalCount := -1
@@ -327,7 +406,14 @@ func (s *Provider) Exec(items any) (*Result, error) {
 	}
 
 	// apply pagination to the original query and fetch the models
+	var nextCursor string
 	modelsExec := func() error {
+		if s.cursor != nil {
+			var err error
+			nextCursor, err = s.cursorExec(modelsQuery, cursorKeys, cursorIdIndex, cursorValues, items)
+			return err
+		}
+
 		modelsQuery.Limit(int64(s.perPage))
 		modelsQuery.Offset(int64(s.perPage * (s.page - 1)))
 
@@ -353,12 +439,131 @@ func (s *Provider) Exec(items any) (*Result, error) {
 		PerPage:    s.perPage,
 		TotalItems: totalCount,
 		TotalPages: totalPages,
+		NextCursor: nextCursor,
 		Items:      items,
 	}
 
 	return result, nil
 }
 
+// applyFilters builds and applies the provider filters to the specified query.
+func (s *Provider) applyFilters(query *dbx.SelectQuery) error {
+	for _, f := range s.filter {
+		if len(f) > MaxFilterLength {
+			return ErrFilterLengthLimit
+		}
+		expr, err := f.BuildExprWithLimit(s.fieldResolver, s.maxFilterExprLimit)
+		if err != nil {
+			return err
+		}
+		if expr != nil {
+			query.AndWhere(expr)
+		}
+	}
+
+	return nil
+}
+
+// normalizePagination normalizes the provider `page` and `perPage` fields.
+func (s *Provider) normalizePagination() {
+	if s.page <= 0 || s.cursor != nil {
+		s.page = 1
+	}
+
+	if s.perPage <= 0 {
+		s.perPage = DefaultPerPage
+	} else if s.perPage > MaxPerPage {
+		s.perPage = MaxPerPage
+	}
+}
+
+// cursorExec fetches the models positioned after the provided cursor values
+// and returns the encoded cursor of the next page (if there are more items).
+//
+// The page rows keys are loaded first with a separate lightweight query so that
+// the returned cursor is guaranteed to match the last fetched model.
+func (s *Provider) cursorExec(modelsQuery dbx.SelectQuery, keys []cursorKey, idIndex int, values []any, items any) (string, error) {
+	if values != nil {
+		modelsQuery.AndWhere(buildCursorExpr(keys, values))
+	}
+
+	cols := make([]string, len(keys))
+	for i, key := range keys {
+		cols[i] = key.identifier
+	}
+
+	// note: modelsQuery is shallow cloned and slice/map in-place modifications should be avoided
+	keysQuery := modelsQuery
+	rows, err := keysQuery.
+		Select(cols...).
+		// deduplicate the rows from the joins (the sort keys are single value per id)
+		GroupBy(keys[idIndex].identifier).
+		Limit(int64(s.perPage + 1)).
+		Rows()
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var hasMore bool
+	var lastValues []any
+	ids := make([]any, 0, s.perPage)
+	for rows.Next() {
+		if len(ids) == s.perPage {
+			hasMore = true
+			break
+		}
+
+		rowValues := make([]any, len(keys))
+		rowPointers := make([]any, len(keys))
+		for i := range rowValues {
+			rowPointers[i] = &rowValues[i]
+		}
+
+		if err := rows.Scan(rowPointers...); err != nil {
+			return "", err
+		}
+
+		for i, v := range rowValues {
+			rowValues[i] = normalizeCursorValue(v)
+		}
+
+		ids = append(ids, rowValues[idIndex])
+		lastValues = rowValues
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	rows.Close()
+
+	// fetch the models of the found keys (preserving the sort order)
+	if len(ids) == 0 {
+		modelsQuery.AndWhere(dbx.NewExp("0=1"))
+	} else {
+		params := make(dbx.Params, len(ids))
+		placeholders := make([]string, len(ids))
+		for i, id := range ids {
+			name := "cursorId" + strconv.Itoa(i)
+			params[name] = id
+			placeholders[i] = "{:" + name + "}"
+		}
+		modelsQuery.AndWhere(dbx.NewExp(
+			keys[idIndex].identifier+" IN ("+strings.Join(placeholders, ",")+")",
+			params,
+		))
+	}
+
+	if err := modelsQuery.All(items); err != nil {
+		return "", err
+	}
+
+	if !hasMore {
+		return "", nil
+	}
+
+	return encodeCursor(lastValues)
+}
+
 // ParseAndExec is a short convenient method to trigger both
 // `Parse()` and `Exec()` in a single call.
 func (s *Provider) ParseAndExec(urlQuery string, modelsSlice any) (*Result, error) {
@@ -368,3 +573,26 @@ func (s *Provider) ParseAndExec(urlQuery string, modelsSlice any) (*Result, erro
 
 	return s.Exec(modelsSlice)
 }
+
+// guardedIdentifier returns the resolved identifier wrapped with
+// the resolver AfterBuild condition (if any, e.g. the emails visibility check)
+// so that the values of the rows that don't satisfy it are treated as NULL
+// (used for the sort and aggregate expressions that are not filter conditions).
+func guardedIdentifier(resolved *ResolverResult, builder dbx.Builder, params dbx.Params) (string, error) {
+	if resolved.AfterBuild == nil {
+		return resolved.Identifier, nil
+	}
+
+	if builder == nil {
+		return "", errors.New("the expression is not allowed")
+	}
+
+	cond := resolved.AfterBuild(dbx.NewExp("1=1"))
+	if cond == nil {
+		return "", errors.New("the expression is not allowed")
+	}
+
+	where := strings.TrimPrefix(builder.QueryBuilder().BuildWhere(cond, params), "WHERE ")
+
+	return "(CASE WHEN " + where + " THEN " + resolved.Identifier + " END)", nil
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -471,6 +472,180 @@ func TestProviderExecNonEmptyQuery(t *testing.T) {
 	}
 }
 
+func TestProviderCursor(t *testing.T) {
+	p := NewProvider(&testFieldResolver{})
+
+	if p.cursor != nil {
+		t.Fatalf("Expected nil cursor, got %q", *p.cursor)
+	}
+
+	if err := p.Parse("page=2"); err != nil {
+		t.Fatal(err)
+	}
+	if p.cursor != nil {
+		t.Fatalf("Expected the cursor mode to remain disabled, got %q", *p.cursor)
+	}
+
+	if err := p.Parse("cursor="); err != nil {
+		t.Fatal(err)
+	}
+	if p.cursor == nil || *p.cursor != "" {
+		t.Fatalf("Expected empty cursor, got %v", p.cursor)
+	}
+
+	p.Cursor("test")
+	if p.cursor == nil || *p.cursor != "test" {
+		t.Fatalf("Expected cursor %q, got %v", "test", p.cursor)
+	}
+}
+
+func TestProviderExecCursor(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	query := testDB.Select("*").
+		From("test").
+		Where(dbx.Not(dbx.HashExp{"test1": nil})).
+		OrderBy("test1 ASC")
+
+	secondPageCursor, _ := encodeCursor([]any{"test2.2", 2})
+
+	scenarios := []struct {
+		name          string
+		cursor        string
+		perPage       int
+		sort          []SortField
+		skipTotal     bool
+		expectError   bool
+		expectResult  string
+		expectQueries []string
+	}{
+		{
+			"random sort",
+			"",
+			1,
+			[]SortField{{"@random", SortAsc}},
+			true,
+			true,
+			"",
+			nil,
+		},
+		{
+			"multi-match sort",
+			"",
+			1,
+			[]SortField{{"multiMatch", SortAsc}},
+			true,
+			true,
+			"",
+			nil,
+		},
+		{
+			"malformed cursor",
+			"invalid",
+			1,
+			[]SortField{},
+			true,
+			true,
+			"",
+			nil,
+		},
+		{
+			"cursor with different number of sort keys",
+			secondPageCursor,
+			1,
+			[]SortField{},
+			true,
+			true,
+			"",
+			nil,
+		},
+		{
+			"first page",
+			"",
+			1,
+			[]SortField{{"test2", SortDesc}},
+			false,
+			false,
+			`{"items":[{"test1":2,"test2":"test2.2","test3":""}],"page":1,"perPage":1,"totalItems":2,"totalPages":2,"nextCursor":"` + secondPageCursor + `"}`,
+			[]string{
+				"SELECT COUNT(DISTINCT [[test.id]]) FROM `test` WHERE NOT (`test1` IS NULL)",
+				"SELECT `test2`, `id` FROM `test` WHERE NOT (`test1` IS NULL) GROUP BY `id` ORDER BY `test2` DESC, `id` ASC LIMIT 2",
+				"SELECT * FROM `test` WHERE (NOT (`test1` IS NULL)) AND (id IN (2)) ORDER BY `test2` DESC, `id` ASC",
+			},
+		},
+		{
+			"last page",
+			secondPageCursor,
+			1,
+			[]SortField{{"test2", SortDesc}},
+			true,
+			false,
+			`{"items":[{"test1":1,"test2":"test2.1","test3":""}],"page":1,"perPage":1,"totalItems":-1,"totalPages":-1}`,
+			[]string{
+				"SELECT `test2`, `id` FROM `test` WHERE (NOT (`test1` IS NULL)) AND (((test2 < 'test2.2' OR test2 IS NULL) OR (test2 IS 'test2.2' AND id > 2))) GROUP BY `id` ORDER BY `test2` DESC, `id` ASC LIMIT 2",
+				"SELECT * FROM `test` WHERE ((NOT (`test1` IS NULL)) AND (((test2 < 'test2.2' OR test2 IS NULL) OR (test2 IS 'test2.2' AND id > 2)))) AND (id IN (1)) ORDER BY `test2` DESC, `id` ASC",
+			},
+		},
+		{
+			"explicit id sort and no matching items",
+			`WzBd`, // [0]
+			10,
+			[]SortField{{"id", SortDesc}},
+			true,
+			false,
+			`{"items":[],"page":1,"perPage":10,"totalItems":-1,"totalPages":-1}`,
+			[]string{
+				"SELECT `id` FROM `test` WHERE (NOT (`test1` IS NULL)) AND (((id < 0 OR id IS NULL))) GROUP BY `id` ORDER BY `id` DESC LIMIT 11",
+				"SELECT * FROM `test` WHERE ((NOT (`test1` IS NULL)) AND (((id < 0 OR id IS NULL)))) AND (0=1) ORDER BY `id` DESC",
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			testDB.CalledQueries = []string{} // reset
+
+			p := NewProvider(&testFieldResolver{}).
+				Query(query).
+				Page(5). // should be ignored
+				PerPage(s.perPage).
+				Sort(s.sort).
+				SkipTotal(s.skipTotal).
+				Cursor(s.cursor)
+
+			result, err := p.Exec(&[]testTableStruct{})
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			encoded, _ := json.Marshal(result)
+			if string(encoded) != s.expectResult {
+				t.Fatalf("Expected result %v, got \n%v", s.expectResult, string(encoded))
+			}
+
+			if len(s.expectQueries) != len(testDB.CalledQueries) {
+				t.Fatalf("Expected %d queries, got %d: \n%v", len(s.expectQueries), len(testDB.CalledQueries), testDB.CalledQueries)
+			}
+
+			for _, q := range testDB.CalledQueries {
+				if !slices.Contains(s.expectQueries, q) {
+					t.Fatalf("Didn't expect query \n%v \nin \n%v", q, s.expectQueries)
+				}
+			}
+		})
+	}
+}
+
 func TestProviderFilterAndSortLimits(t *testing.T) {
 	testDB, err := createTestDB()
 	if err != nil {
@@ -794,5 +969,9 @@ func (t *testFieldResolver) Resolve(field string) (*ResolverResult, error) {
 		return nil, errors.New("test error")
 	}
 
+	if field == "multiMatch" {
+		return &ResolverResult{Identifier: field, MultiMatchSubQuery: &MultiMatchSubquery{}}, nil
+	}
+
 	return &ResolverResult{Identifier: field}, nil
 }
@@ -42,6 +42,25 @@ type ResolverResult struct {
 	// AfterBuild is an optional function that will be called after building
 	// and combining the result of both resolved operands/sides in a single expression.
 	AfterBuild func(expr dbx.Expression) dbx.Expression
+
+	// FullTextIndex is an optional SQLite FTS5 virtual table associated
+	// with the resolved identifier (used by the full-text search token functions).
+	FullTextIndex *FullTextIndex
+}
+
+// FullTextIndex defines a SQLite FTS5 virtual table which key column
+// matches with the key of the resolved identifier table.
+type FullTextIndex struct {
+	// TableName is the name of the FTS5 virtual table.
+	TableName string
+
+	// KeyColumn is the name of the FTS5 virtual table column
+	// that stores the source table row key (e.g. "_recordId").
+	KeyColumn string
+
+	// KeyIdentifier is the SQL identifier of the source table row key
+	// (e.g. "[[demo.id]]").
+	KeyIdentifier string
 }
 
 // FieldResolver defines an interface for managing search fields.
@@ -3,6 +3,8 @@ package search
 import (
 	"fmt"
 	"strings"
+
+	"github.com/ganigeorgiev/fexpr"
 )
 
 const (
@@ -29,19 +31,61 @@ func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
 		return "RANDOM()", nil
 	}
 
+	resolved, err := s.resolve(fieldResolver)
+	if err != nil {
+		return "", err
+	}
+
+	// the plain sort expression doesn't support bind params
+	if len(resolved.Params) > 0 {
+		return "", fmt.Errorf("invalid sort field %q", s.Name)
+	}
+
+	return fmt.Sprintf("%s %s", resolved.Identifier, s.Direction), nil
+}
+
+// resolve resolves the sort field name (without the direction)
+// into a valid db column identifier or function expression (e.g. "rank(content, 'test')")
+// together with its bind params (if any).
+//
+// The returned MultiMatchSubQuery is non-nil if the sort field
+// could match more than one value per row (e.g. a back-relation field).
+//
+// The returned AfterBuild (if any) is the resolver guard condition
+// that should be applied to the sort identifier (see [guardedIdentifier]).
+func (s *SortField) resolve(fieldResolver FieldResolver) (*ResolverResult, error) {
 	// special case for the builtin SQLite rowid column
 	if s.Name == rowidSortKey {
-		return fmt.Sprintf("[[_rowid_]] %s", s.Direction), nil
+		return &ResolverResult{Identifier: "[[_rowid_]]"}, nil
+	}
+
+	// function expression
+	if strings.Contains(s.Name, "(") {
+		token, err := scanSingleToken(s.Name)
+		if err != nil || token.Type != fexpr.TokenFunction {
+			return nil, fmt.Errorf("invalid sort field %q", s.Name)
+		}
+
+		result, err := resolveToken(token, fieldResolver)
+		if err != nil || result.Identifier == "" || result.MultiMatchSubQuery != nil {
+			return nil, fmt.Errorf("invalid sort field %q", s.Name)
+		}
+
+		return &ResolverResult{Identifier: result.Identifier, Params: result.Params, AfterBuild: result.AfterBuild}, nil
 	}
 
 	result, err := fieldResolver.Resolve(s.Name)
 
 	// invalidate empty fields and non-column identifiers
 	if err != nil || len(result.Params) > 0 || result.Identifier == "" || strings.ToLower(result.Identifier) == "null" {
-		return "", fmt.Errorf("invalid sort field %q", s.Name)
+		return nil, fmt.Errorf("invalid sort field %q", s.Name)
 	}
 
-	return fmt.Sprintf("%s %s", result.Identifier, s.Direction), nil
+	return &ResolverResult{
+		Identifier:         result.Identifier,
+		MultiMatchSubQuery: result.MultiMatchSubQuery,
+		AfterBuild:         result.AfterBuild,
+	}, nil
 }
 
 // ParseSortFromString parses the provided string expression
@@ -52,6 +96,10 @@ func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
 //	fields := search.ParseSortFromString("-name,+created")
 func ParseSortFromString(str string) (fields []SortField) {
 	data := strings.Split(str, ",")
+	if strings.Contains(str, "(") {
+		// function expressions could contain commas in their arguments
+		data = splitExprList(str)
+	}
 
 	for _, field := range data {
 		// trim whitespaces
@@ -62,6 +62,7 @@ func TestParseSortFromString(t *testing.T) {
 		{"test1,-test2,+test3", `[{"name":"test1","direction":"ASC"},{"name":"test2","direction":"DESC"},{"name":"test3","direction":"ASC"}]`},
 		{"@random,-test", `[{"name":"@random","direction":"ASC"},{"name":"test","direction":"DESC"}]`},
 		{"-@rowid,-test", `[{"name":"@rowid","direction":"DESC"},{"name":"test","direction":"DESC"}]`},
+		{"rank(a, 'b,c'),-test", `[{"name":"rank(a, 'b,c')","direction":"ASC"},{"name":"test","direction":"DESC"}]`},
 	}
 
 	for _, s := range scenarios {
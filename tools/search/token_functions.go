@@ -179,6 +179,86 @@ var TokenFunctions = map[string]func(
 
 		return result, nil
 	},
+
+	// match(field, query) checks whether the specified full-text search
+	// field matches the provided SQLite FTS5 query (https://sqlite.org/fts5.html#full_text_query_syntax).
+	//
+	// The first argument must be an identifier resolving to a field with
+	// an associated full-text index (e.g. "search" field) and the second argument
+	// must be either a string literal or an identifier (e.g. "@request.query.q").
+	//
+	// The function returns a boolean value and it is usually used as:
+	// `match(content, 'hello world') = true`.
+	"match": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		index, query, err := resolveFullTextArgs("match", argTokenResolverFunc, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ResolverResult{
+			NullFallback: NullFallbackDisabled,
+			Identifier: "(" + index.KeyIdentifier + " IN (" +
+				"SELECT [[" + index.KeyColumn + "]] FROM {{" + index.TableName + "}} WHERE {{" + index.TableName + "}} MATCH " + query.Identifier +
+				"))",
+			Params: query.Params,
+		}, nil
+	},
+
+	// rank(field, query) returns the SQLite FTS5 rank (aka. bm25 score)
+	// of the specified full-text search field for the provided query.
+	//
+	// Similar to the FTS5 "rank" column, the better matches have smaller (more negative) values,
+	// so sorting by `rank(content, 'hello')` in ascending order returns the best matches first.
+	//
+	// Records that don't match the query resolve to NULL.
+	"rank": func(argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+		index, query, err := resolveFullTextArgs("rank", argTokenResolverFunc, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ResolverResult{
+			NullFallback: NullFallbackDisabled,
+			Identifier: "(SELECT [[rank]] FROM {{" + index.TableName + "}} WHERE {{" + index.TableName + "}} MATCH " + query.Identifier +
+				" AND [[" + index.KeyColumn + "]] = " + index.KeyIdentifier + ")",
+			Params: query.Params,
+		}, nil
+	},
+}
+
+// resolveFullTextArgs resolves the common (field, query) full-text search token function arguments.
+func resolveFullTextArgs(
+	funcName string,
+	argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error),
+	args ...fexpr.Token,
+) (*FullTextIndex, *ResolverResult, error) {
+	if len(args) != 2 {
+		return nil, nil, fmt.Errorf("[%s] expected 2 arguments, got %d", funcName, len(args))
+	}
+
+	if args[0].Type != fexpr.TokenIdentifier {
+		return nil, nil, fmt.Errorf("[%s] expects the first argument to be a field identifier", funcName)
+	}
+
+	fieldResult, err := argTokenResolverFunc(args[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("[%s] failed to resolve field argument: %w", funcName, err)
+	}
+
+	if fieldResult.FullTextIndex == nil || fieldResult.FullTextIndex.TableName == "" || fieldResult.FullTextIndex.KeyColumn == "" {
+		return nil, nil, fmt.Errorf("[%s] %q is not a full-text search field", funcName, args[0].Literal)
+	}
+
+	if args[1].Type != fexpr.TokenText && args[1].Type != fexpr.TokenIdentifier {
+		return nil, nil, fmt.Errorf("[%s] expects the second argument to be a string or identifier", funcName)
+	}
+
+	queryResult, err := argTokenResolverFunc(args[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("[%s] failed to resolve query argument: %w", funcName, err)
+	}
+
+	return fieldResult.FullTextIndex, queryResult, nil
 }
 
 func concatUniqueParams(destParams, newParams dbx.Params) error {
@@ -624,3 +624,83 @@ func testCompareResults(t *testing.T, a, b *ResolverResult) {
 		t.Fatalf("Expected resolved identifiers to match, got\n%s\nvs\n%s", aResolved, bResolved)
 	}
 }
+
+func TestTokenFunctionsFullText(t *testing.T) {
+	t.Parallel()
+
+	resolver := func(t fexpr.Token) (*ResolverResult, error) {
+		switch t.Type {
+		case fexpr.TokenText:
+			return &ResolverResult{Identifier: "{:q}", Params: dbx.Params{"q": t.Literal}}, nil
+		case fexpr.TokenIdentifier:
+			if t.Literal == "content" {
+				return &ResolverResult{
+					Identifier:    "[[demo.content]]",
+					FullTextIndex: &FullTextIndex{TableName: "fts", KeyColumn: "_key", KeyIdentifier: "[[demo.id]]"},
+				}, nil
+			}
+			return &ResolverResult{Identifier: "[[demo." + t.Literal + "]]"}, nil
+		}
+		return nil, errors.New("unexpected token")
+	}
+
+	scenarios := []struct {
+		fn         string
+		args       []fexpr.Token
+		expectErr  bool
+		identifier string
+	}{
+		{"match", nil, true, ""},
+		{
+			"match",
+			[]fexpr.Token{{Literal: "title", Type: fexpr.TokenIdentifier}, {Literal: "a", Type: fexpr.TokenText}},
+			true,
+			"",
+		},
+		{
+			"match",
+			[]fexpr.Token{{Literal: "content", Type: fexpr.TokenIdentifier}, {Literal: "1", Type: fexpr.TokenNumber}},
+			true,
+			"",
+		},
+		{
+			"match",
+			[]fexpr.Token{{Literal: "content", Type: fexpr.TokenIdentifier}, {Literal: "a", Type: fexpr.TokenText}},
+			false,
+			"([[demo.id]] IN (SELECT [[_key]] FROM {{fts}} WHERE {{fts}} MATCH {:q}))",
+		},
+		{
+			"rank",
+			[]fexpr.Token{{Literal: "content", Type: fexpr.TokenIdentifier}, {Literal: "a", Type: fexpr.TokenText}},
+			false,
+			"(SELECT [[rank]] FROM {{fts}} WHERE {{fts}} MATCH {:q} AND [[_key]] = [[demo.id]])",
+		},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.fn), func(t *testing.T) {
+			result, err := TokenFunctions[s.fn](resolver, s.args...)
+
+			hasErr := err != nil
+			if hasErr != s.expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectErr, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.Identifier != s.identifier {
+				t.Fatalf("Expected identifier\n%s\ngot\n%s", s.identifier, result.Identifier)
+			}
+
+			if result.NullFallback != NullFallbackDisabled {
+				t.Fatalf("Expected NullFallbackDisabled, got %v", result.NullFallback)
+			}
+
+			if result.Params["q"] != "a" {
+				t.Fatalf("Expected the query param to be forwarded, got %v", result.Params)
+			}
+		})
+	}
+}
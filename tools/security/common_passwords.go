@@ -0,0 +1,35 @@
+package security
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+//go:embed common_passwords.txt
+var rawCommonPasswords string
+
+var commonPasswords = sync.OnceValue(func() map[string]struct{} {
+	lines := strings.Split(rawCommonPasswords, "\n")
+
+	result := make(map[string]struct{}, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		result[line] = struct{}{}
+	}
+
+	return result
+})
+
+// IsCommonPassword reports whether the provided password is part of the
+// bundled list with the most common and frequently breached passwords.
+//
+// The check is case-insensitive.
+func IsCommonPassword(password string) bool {
+	_, ok := commonPasswords()[strings.ToLower(password)]
+
+	return ok
+}
@@ -0,0 +1,37 @@
+package security_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestIsCommonPassword(t *testing.T) {
+	scenarios := []struct {
+		password string
+		expected bool
+	}{
+		{"", false},
+		{" ", false},
+		{"123456", true},
+		{"password", true},
+		{"Password", true},
+		{"QWERTY123", true},
+		{"p@ssw0rd", true},
+		{" password", false},
+		{"1234567890", true},
+		{"correct horse battery staple", false},
+		{"5Fh#kQ92!mZx", false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.password), func(t *testing.T) {
+			result := security.IsCommonPassword(s.password)
+
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported asymmetric JWT signing algorithms.
+const (
+	SigningAlgRS256 = "RS256"
+	SigningAlgEdDSA = "EdDSA"
+)
+
+const rsaSigningKeyBits = 2048
+
+// GenerateSigningKey generates a new private key for the specified
+// asymmetric JWT signing algorithm and returns it PEM encoded (PKCS #8).
+func GenerateSigningKey(alg string) (string, error) {
+	var key crypto.Signer
+	var err error
+
+	switch alg {
+	case SigningAlgRS256:
+		key, err = rsa.GenerateKey(rand.Reader, rsaSigningKeyBits)
+	case SigningAlgEdDSA:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return "", fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// ParseSigningKey parses the PEM encoded (PKCS #8) private key and
+// checks whether it is compatible with the specified signing algorithm.
+func ParseSigningKey(alg string, privateKeyPEM string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return nil, errors.New("invalid PEM encoded private key")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch alg {
+	case SigningAlgRS256:
+		if k, ok := key.(*rsa.PrivateKey); ok {
+			return k, nil
+		}
+	case SigningAlgEdDSA:
+		if k, ok := key.(ed25519.PrivateKey); ok {
+			return k, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+
+	return nil, fmt.Errorf("the private key is not compatible with the %q signing algorithm", alg)
+}
+
+// JWK defines a single public JSON Web Key as described in [RFC 7517].
+//
+// [RFC 7517]: https://datatracker.ietf.org/doc/html/rfc7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+
+	// RSA specific members
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// OKP (Ed25519) specific members
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// NewPublicJWK creates a new signature verification JWK from the provided public key.
+func NewPublicJWK(kid string, alg string, key crypto.PublicKey) (*JWK, error) {
+	jwk := &JWK{
+		Use: "sig",
+		Alg: alg,
+		Kid: kid,
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(k)
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+
+	return jwk, nil
+}
+
+// NewSignedJWT generates and returns new JWT signed with the provided
+// asymmetric private key.
+//
+// The kid argument is optional and when set it is added as "kid" header.
+func NewSignedJWT(payload jwt.MapClaims, alg string, kid string, key crypto.Signer, duration time.Duration) (string, error) {
+	method, err := signingMethod(alg)
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"exp": time.Now().Add(duration).Unix(),
+	}
+
+	for k, v := range payload {
+		claims[k] = v
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	return token.SignedString(key)
+}
+
+// ParseSignedJWT verifies the JWT signature with the provided asymmetric
+// public key and returns its claims.
+func ParseSignedJWT(token string, alg string, key crypto.PublicKey) (jwt.MapClaims, error) {
+	if _, err := signingMethod(alg); err != nil {
+		return nil, err
+	}
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{alg}))
+
+	parsedToken, err := parser.Parse(token, func(t *jwt.Token) (any, error) {
+		return key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := parsedToken.Claims.(jwt.MapClaims); ok && parsedToken.Valid {
+		return claims, nil
+	}
+
+	return nil, errors.New("unable to parse token")
+}
+
+func signingMethod(alg string) (jwt.SigningMethod, error) {
+	switch alg {
+	case SigningAlgRS256:
+		return jwt.SigningMethodRS256, nil
+	case SigningAlgEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+}
@@ -0,0 +1,213 @@
+package security_test
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestGenerateAndParseSigningKey(t *testing.T) {
+	if _, err := security.GenerateSigningKey("HS256"); err == nil {
+		t.Fatal("Expected unsupported algorithm error")
+	}
+
+	pemKey, err := security.GenerateSigningKey(security.SigningAlgRS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edPemKey, err := security.GenerateSigningKey(security.SigningAlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		alg         string
+		key         string
+		expectError bool
+	}{
+		{"empty key", security.SigningAlgRS256, "", true},
+		{"invalid PEM", security.SigningAlgRS256, "invalid", true},
+		{"unsupported algorithm", "HS256", pemKey, true},
+		{"RSA key with EdDSA algorithm", security.SigningAlgEdDSA, pemKey, true},
+		{"Ed25519 key with RS256 algorithm", security.SigningAlgRS256, edPemKey, true},
+		{"valid RSA key", security.SigningAlgRS256, pemKey, false},
+		{"valid Ed25519 key", security.SigningAlgEdDSA, edPemKey, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			key, err := security.ParseSigningKey(s.alg, s.key)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			switch s.alg {
+			case security.SigningAlgRS256:
+				if _, ok := key.(*rsa.PrivateKey); !ok {
+					t.Fatalf("Expected *rsa.PrivateKey, got %T", key)
+				}
+			case security.SigningAlgEdDSA:
+				if _, ok := key.(ed25519.PrivateKey); !ok {
+					t.Fatalf("Expected ed25519.PrivateKey, got %T", key)
+				}
+			}
+		})
+	}
+}
+
+func TestNewPublicJWK(t *testing.T) {
+	pemKey, err := security.GenerateSigningKey(security.SigningAlgRS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := security.ParseSigningKey(security.SigningAlgRS256, pemKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := security.NewPublicJWK("test", security.SigningAlgRS256, "invalid"); err == nil {
+		t.Fatal("Expected unsupported public key error")
+	}
+
+	jwk, err := security.NewPublicJWK("test", security.SigningAlgRS256, key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if jwk.Kty != "RSA" || jwk.Use != "sig" || jwk.Alg != security.SigningAlgRS256 || jwk.Kid != "test" {
+		t.Fatalf("Unexpected JWK %#v", jwk)
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub := key.Public().(*rsa.PublicKey)
+	if new(big.Int).SetBytes(n).Cmp(pub.N) != 0 {
+		t.Fatal("The JWK modulus doesn't match with the public key one")
+	}
+
+	if jwk.E != "AQAB" {
+		t.Fatalf("Expected exponent AQAB, got %q", jwk.E)
+	}
+}
+
+func TestNewPublicJWKEd25519(t *testing.T) {
+	pemKey, err := security.GenerateSigningKey(security.SigningAlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := security.ParseSigningKey(security.SigningAlgEdDSA, pemKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwk, err := security.NewPublicJWK("test", security.SigningAlgEdDSA, key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.Use != "sig" || jwk.Alg != security.SigningAlgEdDSA || jwk.Kid != "test" {
+		t.Fatalf("Unexpected JWK %#v", jwk)
+	}
+
+	if jwk.N != "" || jwk.E != "" {
+		t.Fatalf("Expected empty RSA members, got %#v", jwk)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !key.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(x)) {
+		t.Fatal("The JWK x member doesn't match with the public key")
+	}
+}
+
+func TestNewAndParseSignedJWT(t *testing.T) {
+	pemKey1, _ := security.GenerateSigningKey(security.SigningAlgRS256)
+	key1, _ := security.ParseSigningKey(security.SigningAlgRS256, pemKey1)
+
+	pemKey2, _ := security.GenerateSigningKey(security.SigningAlgRS256)
+	key2, _ := security.ParseSigningKey(security.SigningAlgRS256, pemKey2)
+
+	edPemKey, _ := security.GenerateSigningKey(security.SigningAlgEdDSA)
+	edKey, _ := security.ParseSigningKey(security.SigningAlgEdDSA, edPemKey)
+
+	if _, err := security.NewSignedJWT(jwt.MapClaims{}, "HS256", "", key1, time.Minute); err == nil {
+		t.Fatal("Expected unsupported algorithm error")
+	}
+
+	token, err := security.NewSignedJWT(jwt.MapClaims{"name": "test"}, security.SigningAlgRS256, "kid1", key1, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["kid"] != "kid1" {
+		t.Fatalf("Expected kid header %q, got %v", "kid1", parsed.Header["kid"])
+	}
+
+	expiredToken, err := security.NewSignedJWT(jwt.MapClaims{"name": "test"}, security.SigningAlgRS256, "", key1, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edToken, err := security.NewSignedJWT(jwt.MapClaims{"name": "test"}, security.SigningAlgEdDSA, "kid2", edKey, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		token       string
+		alg         string
+		key         crypto.PublicKey
+		expectError bool
+	}{
+		{"unsupported algorithm", token, "HS256", key1.Public(), true},
+		{"different key", token, security.SigningAlgRS256, key2.Public(), true},
+		{"expired token", expiredToken, security.SigningAlgRS256, key1.Public(), true},
+		{"valid token", token, security.SigningAlgRS256, key1.Public(), false},
+		{"EdDSA token with RS256 algorithm", edToken, security.SigningAlgRS256, edKey.Public(), true},
+		{"EdDSA token with different key", edToken, security.SigningAlgEdDSA, key1.Public(), true},
+		{"valid EdDSA token", edToken, security.SigningAlgEdDSA, edKey.Public(), false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			claims, err := security.ParseSignedJWT(s.token, s.alg, s.key)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && claims["name"] != "test" {
+				t.Fatalf("Expected name claim %q, got %v", "test", claims["name"])
+			}
+		})
+	}
+}
@@ -24,6 +24,19 @@ func ParseUnverifiedJWT(token string) (jwt.MapClaims, error) {
 	return claims, err
 }
 
+// ParseUnverifiedJWTHeader parses JWT and returns its header
+// but DOES NOT verify the signature or any of the claims.
+func ParseUnverifiedJWTHeader(token string) (map[string]any, error) {
+	parser := &jwt.Parser{}
+
+	parsedToken, _, err := parser.ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+
+	return parsedToken.Header, nil
+}
+
 // ParseJWT verifies and parses JWT and returns its claims.
 func ParseJWT(token string, verificationKey string) (jwt.MapClaims, error) {
 	parser := jwt.NewParser(jwt.WithValidMethods([]string{"HS256"}))
@@ -0,0 +1,128 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The TOTP parameters used by [TOTP] and [ValidateTOTP].
+//
+// They are the defaults of the most common authenticator apps
+// and are not configurable on purpose.
+const (
+	TOTPDigits = 6
+	TOTPPeriod = 30 // in seconds
+)
+
+const totpSecretAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+
+// RandomTOTPSecret generates a new cryptographically random
+// base32 encoded (without padding) 160-bit TOTP secret.
+func RandomTOTPSecret() string {
+	return RandomStringWithAlphabet(32, totpSecretAlphabet)
+}
+
+// TOTPStep returns the [RFC 6238] time step counter for the specified time.
+//
+// [RFC 6238]: https://datatracker.ietf.org/doc/html/rfc6238
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / TOTPPeriod
+}
+
+// TOTP generates the [RFC 6238] time-based one-time password
+// (HMAC-SHA1, 6 digits, 30s period) for the base32 encoded secret and time.
+//
+// [RFC 6238]: https://datatracker.ietf.org/doc/html/rfc6238
+func TOTP(secret string, t time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return hotp(key, TOTPStep(t)), nil
+}
+
+// ValidateTOTP checks whether code is a valid TOTP for the secret at
+// the specified time, allowing skew number of time steps before and after
+// to compensate for clock drifts.
+//
+// On success it returns the matched time step that could be used to
+// prevent replaying the same code.
+func ValidateTOTP(secret string, code string, t time.Time, skew int) (int64, bool) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil || len(code) != TOTPDigits {
+		return 0, false
+	}
+
+	current := TOTPStep(t)
+
+	for i := -int64(skew); i <= int64(skew); i++ {
+		if Equal(hotp(key, current+i), code) {
+			return current + i, true
+		}
+	}
+
+	return 0, false
+}
+
+// TOTPProvisioningURI returns the "otpauth://" key URI that is usually
+// encoded as QR code in order to be scanned by the authenticator apps.
+//
+// See https://github.com/google/google-authenticator/wiki/Key-Uri-Format.
+func TOTPProvisioningURI(secret string, issuer string, accountName string) string {
+	label := url.PathEscape(accountName)
+	if issuer != "" {
+		label = url.PathEscape(issuer) + ":" + label
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TOTPDigits))
+	query.Set("period", fmt.Sprint(TOTPPeriod))
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) == 0 {
+		return nil, errors.New("empty TOTP secret")
+	}
+
+	return key, nil
+}
+
+// hotp implements the RFC 4226 HOTP algorithm.
+func hotp(key []byte, counter int64) string {
+	h := hmac.New(sha1.New, key)
+	_ = binary.Write(h, binary.BigEndian, counter)
+	sum := h.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range TOTPDigits {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", TOTPDigits, value%mod)
+}
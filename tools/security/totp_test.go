@@ -0,0 +1,145 @@
+package security_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// base32 encoded RFC 6238 SHA1 test secret "12345678901234567890"
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestRandomTOTPSecret(t *testing.T) {
+	generated := make(map[string]struct{}, 100)
+
+	for i := 0; i < 100; i++ {
+		secret := security.RandomTOTPSecret()
+
+		if len(secret) != 32 {
+			t.Fatalf("Expected secret with length 32, got %d (%q)", len(secret), secret)
+		}
+
+		if _, err := security.TOTP(secret, time.Now()); err != nil {
+			t.Fatalf("Expected valid base32 secret %q, got %v", secret, err)
+		}
+
+		if _, ok := generated[secret]; ok {
+			t.Fatalf("Repeating secret %q", secret)
+		}
+		generated[secret] = struct{}{}
+	}
+}
+
+func TestTOTP(t *testing.T) {
+	// RFC 6238 Appendix B (truncated to 6 digits)
+	scenarios := []struct {
+		secret      string
+		unix        int64
+		expected    string
+		expectError bool
+	}{
+		{"", 59, "", true},
+		{"invalid!", 59, "", true},
+		{testTOTPSecret, 59, "287082", false},
+		{testTOTPSecret, 1111111109, "081804", false},
+		{testTOTPSecret, 1111111111, "050471", false},
+		{testTOTPSecret, 1234567890, "005924", false},
+		{testTOTPSecret, 2000000000, "279037", false},
+		{testTOTPSecret, 20000000000, "353130", false},
+		// lowercase, spaces and padding
+		{strings.ToLower("GEZD GNBV GY3T QOJQ GEZD GNBV GY3T QOJQ"), 59, "287082", false},
+		{"GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ====", 59, "287082", false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%d", i, s.unix), func(t *testing.T) {
+			result, err := security.TOTP(s.secret, time.Unix(s.unix, 0))
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if result != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0) // step 37037037
+
+	scenarios := []struct {
+		code         string
+		skew         int
+		expectedStep int64
+		expectedOk   bool
+	}{
+		{"", 1, 0, false},
+		{"05047", 1, 0, false},
+		{"123456", 1, 0, false},
+		{"050471", 0, 37037037, true},
+		{"050471", 1, 37037037, true},
+		{"081804", 0, 0, false},       // previous step
+		{"081804", 1, 37037036, true}, // previous step with skew
+		{"0504710", 1, 0, false},      // extra digit
+		{"050471\x00", 1, 0, false},   // extra null byte
+		{"050 471", 1, 0, false},      // invalid format
+		{"٠٥٠٤٧١", 1, 0, false},       // non-ASCII digits
+		{"050471 ", 1, 0, false},      // trailing space
+		{" 050471", 1, 0, false},      // leading space
+		{"050471", -1, 0, false},      // negative skew
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s_%d", i, s.code, s.skew), func(t *testing.T) {
+			step, ok := security.ValidateTOTP(testTOTPSecret, s.code, now, s.skew)
+
+			if ok != s.expectedOk {
+				t.Fatalf("Expected ok %v, got %v", s.expectedOk, ok)
+			}
+
+			if step != s.expectedStep {
+				t.Fatalf("Expected step %d, got %d", s.expectedStep, step)
+			}
+		})
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	scenarios := []struct {
+		issuer   string
+		account  string
+		expected string
+	}{
+		{
+			"",
+			"test@example.com",
+			"otpauth://totp/test@example.com?algorithm=SHA1&digits=6&period=30&secret=ABC",
+		},
+		{
+			"Acme Inc",
+			"test@example.com",
+			"otpauth://totp/Acme%20Inc:test@example.com?algorithm=SHA1&digits=6&issuer=Acme+Inc&period=30&secret=ABC",
+		},
+		{
+			"a:b",
+			"c/d",
+			"otpauth://totp/a:b:c%2Fd?algorithm=SHA1&digits=6&issuer=a%3Ab&period=30&secret=ABC",
+		},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.issuer), func(t *testing.T) {
+			result := security.TOTPProvisioningURI("ABC", s.issuer, s.account)
+
+			if result != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, result)
+			}
+		})
+	}
+}
@@ -2,20 +2,33 @@ package subscriptions
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/store"
 )
 
+// Default [Broker] event log bounds.
+const (
+	DefaultEventLogMaxSize = 100
+	DefaultEventLogMaxAge  = 5 * time.Minute
+)
+
 // Broker defines a struct for managing subscriptions clients.
 type Broker struct {
-	store *store.Store[string, Client]
+	store       *store.Store[string, Client]
+	transport   Transport
+	peerHandler func(payload []byte)
+	eventLog    *EventLog
+	mu          sync.RWMutex
 }
 
 // NewBroker initializes and returns a new Broker instance.
 func NewBroker() *Broker {
 	return &Broker{
-		store: store.New[string, Client](nil),
+		store:    store.New[string, Client](nil),
+		eventLog: NewEventLog(DefaultEventLogMaxSize, DefaultEventLogMaxAge),
 	}
 }
 
@@ -63,3 +76,77 @@ func (b *Broker) Unregister(clientId string) {
 	client.Discard()
 	b.store.Remove(clientId)
 }
+
+// SetTransport replaces the broker peers fan-out transport
+// (pass nil to remove the current one).
+//
+// The transport is used by [Broker.Publish] to propagate messages to
+// the other broker instances (aka. peers) and the messages received from
+// them are forwarded to the handler registered with [Broker.SetPeerHandler].
+//
+// Note that the previous transport (if any) is not closed automatically.
+func (b *Broker) SetTransport(transport Transport) {
+	b.mu.Lock()
+	b.transport = transport
+	b.mu.Unlock()
+
+	if transport != nil {
+		transport.Subscribe(b.handlePeerPayload)
+	}
+}
+
+// Transport returns the current broker peers transport (if any).
+func (b *Broker) Transport() Transport {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.transport
+}
+
+// SetPeerHandler registers the handler that will be called
+// for every payload received from the broker peers.
+func (b *Broker) SetPeerHandler(handler func(payload []byte)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.peerHandler = handler
+}
+
+// Publish sends the payload to the broker peers using the current transport.
+//
+// If no transport is set, this method does nothing.
+func (b *Broker) Publish(payload []byte) error {
+	transport := b.Transport()
+	if transport == nil {
+		return nil
+	}
+
+	return transport.Publish(payload)
+}
+
+func (b *Broker) handlePeerPayload(payload []byte) {
+	b.mu.RLock()
+	handler := b.peerHandler
+	b.mu.RUnlock()
+
+	if handler != nil {
+		handler(payload)
+	}
+}
+
+// EventLog returns the broker event log used for replaying the missed
+// events to reconnecting clients (could be nil if disabled).
+func (b *Broker) EventLog() *EventLog {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.eventLog
+}
+
+// SetEventLog replaces the broker event log (pass nil to disable it).
+func (b *Broker) SetEventLog(eventLog *EventLog) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.eventLog = eventLog
+}
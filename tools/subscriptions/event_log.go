@@ -0,0 +1,226 @@
+package subscriptions
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// EventLogEntry defines a single [EventLog] entry.
+type EventLogEntry struct {
+	Time  time.Time
+	Data  any
+	Id    string
+	Topic string
+	seq   uint64
+}
+
+type eventLogMarker struct {
+	time time.Time
+	seq  uint64
+}
+
+// EventLog is a bounded in-memory log of the broadcasted events that
+// allows reconnecting clients to replay the ones they have missed.
+//
+// The entries are grouped by topic and each topic keeps at most
+// maxSize of the latest entries that are not older than maxAge.
+type EventLog struct {
+	topics  map[string][]*EventLogEntry
+	markers map[string]eventLogMarker
+
+	// dropped holds the seq of the latest removed entry of each topic
+	// (used to detect whether a client has missed no longer available entries)
+	dropped map[string]uint64
+
+	// epoch is a random log instance identifier used as entry ids prefix
+	// so that the ids from another process or log instance are not mistaken
+	// for the ids of the current one (e.g. after app restart)
+	epoch string
+
+	seq     uint64
+	maxSize int
+	maxAge  time.Duration
+	mu      sync.RWMutex
+}
+
+// NewEventLog creates a new [EventLog] keeping at most maxSize entries
+// per topic that are not older than maxAge.
+//
+// Zero or negative maxSize or maxAge means no limit for the related bound.
+func NewEventLog(maxSize int, maxAge time.Duration) *EventLog {
+	return &EventLog{
+		topics:  map[string][]*EventLogEntry{},
+		markers: map[string]eventLogMarker{},
+		dropped: map[string]uint64{},
+		epoch:   security.PseudorandomString(8),
+		maxSize: maxSize,
+		maxAge:  maxAge,
+	}
+}
+
+// Add appends a new event to the topic log and returns the created entry.
+//
+// The entry Id is unique for the EventLog instance (in the format "epoch-seq")
+// and could be used as SSE event id for the related messages.
+func (l *EventLog) Add(topic string, data any) *EventLogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.seq++
+
+	entry := &EventLogEntry{
+		Id:    l.epoch + "-" + strconv.FormatUint(l.seq, 10),
+		Topic: topic,
+		Data:  data,
+		Time:  time.Now(),
+		seq:   l.seq,
+	}
+
+	entries := append(l.topics[topic], entry)
+	if l.maxSize > 0 && len(entries) > l.maxSize {
+		total := len(entries) - l.maxSize
+		l.dropped[topic] = entries[total-1].seq
+		entries = slices.Delete(entries, 0, total)
+	}
+	l.topics[topic] = entries
+
+	l.prune(entry.Time)
+
+	return entry
+}
+
+// Mark stores the current log position under the specified key
+// (e.g. a client id) so that it could be later used as [EventLog.Since] argument.
+//
+// The markers are subject to the same maxAge bound as the log entries
+// and this method does nothing if the log doesn't have maxAge limit.
+func (l *EventLog) Mark(key string) {
+	if l.maxAge <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	l.markers[key] = eventLogMarker{seq: l.seq, time: now}
+
+	l.prune(now)
+}
+
+// Since returns the topics entries (or all entries if no topics are specified)
+// that were added after the lastEventId in their insertion order.
+//
+// lastEventId could be either an entry Id or a [EventLog.Mark] key.
+//
+// Returns false if the lastEventId is unknown (e.g. because it
+// is from another EventLog instance or its marker has expired) or
+// if some of the entries after it are no longer available
+// (e.g. because they were evicted due to the log bounds).
+func (l *EventLog) Since(lastEventId string, topics ...string) ([]*EventLogEntry, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var lastSeq uint64
+
+	if marker, ok := l.markers[lastEventId]; ok {
+		lastSeq = marker.seq
+	} else {
+		epoch, rawSeq, ok := strings.Cut(lastEventId, "-")
+		if !ok || epoch != l.epoch {
+			return nil, false
+		}
+
+		seq, err := strconv.ParseUint(rawSeq, 10, 64)
+		if err != nil || seq > l.seq {
+			return nil, false
+		}
+		lastSeq = seq
+	}
+
+	minTime := time.Time{}
+	if l.maxAge > 0 {
+		minTime = time.Now().Add(-l.maxAge)
+	}
+
+	// the removed entries of a topic are always the oldest ones
+	// so it is enough to check only the latest removed seq
+	for topic, droppedSeq := range l.dropped {
+		if droppedSeq > lastSeq && (len(topics) == 0 || slices.Contains(topics, topic)) {
+			return nil, false
+		}
+	}
+
+	result := []*EventLogEntry{}
+
+	for topic, entries := range l.topics {
+		if len(topics) > 0 && !slices.Contains(topics, topic) {
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry.seq <= lastSeq {
+				continue
+			}
+
+			// expired but not pruned yet
+			if !entry.Time.After(minTime) {
+				return nil, false
+			}
+
+			result = append(result, entry)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b *EventLogEntry) int {
+		if a.seq < b.seq {
+			return -1
+		}
+		if a.seq > b.seq {
+			return 1
+		}
+		return 0
+	})
+
+	return result, true
+}
+
+// prune removes the expired entries and markers.
+//
+// Note: must be called with an acquired write lock.
+func (l *EventLog) prune(now time.Time) {
+	if l.maxAge <= 0 {
+		return
+	}
+
+	minTime := now.Add(-l.maxAge)
+
+	for topic, entries := range l.topics {
+		idx := slices.IndexFunc(entries, func(entry *EventLogEntry) bool {
+			return entry.Time.After(minTime)
+		})
+
+		switch idx {
+		case -1:
+			l.dropped[topic] = entries[len(entries)-1].seq
+			delete(l.topics, topic)
+		case 0:
+			// nothing to prune
+		default:
+			l.dropped[topic] = entries[idx-1].seq
+			l.topics[topic] = slices.Delete(entries, 0, idx)
+		}
+	}
+
+	for key, marker := range l.markers {
+		if !marker.time.After(minTime) {
+			delete(l.markers, key)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package subscriptions_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+)
+
+func eventLogEntriesData(entries []*subscriptions.EventLogEntry) string {
+	parts := make([]string, len(entries))
+	for i, entry := range entries {
+		parts[i] = entry.Topic + ":" + entry.Data.(string)
+	}
+	return strings.Join(parts, ",")
+}
+
+// eventLogZeroId returns the id before the first entry of the entry log.
+func eventLogZeroId(entry *subscriptions.EventLogEntry) string {
+	epoch, _, _ := strings.Cut(entry.Id, "-")
+	return epoch + "-0"
+}
+
+func TestEventLogSince(t *testing.T) {
+	l := subscriptions.NewEventLog(0, 0)
+
+	e1 := l.Add("a", "1")
+	l.Add("b", "2")
+	e3 := l.Add("a", "3")
+	e4 := l.Add("c", "4")
+
+	if e1.Id == e3.Id {
+		t.Fatalf("Expected unique entry ids, got %q", e1.Id)
+	}
+
+	epoch, _, ok := strings.Cut(e1.Id, "-")
+	if !ok || epoch == "" {
+		t.Fatalf("Expected the entry id to be prefixed with the log epoch, got %q", e1.Id)
+	}
+
+	other := subscriptions.NewEventLog(0, 0)
+	otherEntry := other.Add("a", "1")
+	if otherEntry.Id == e1.Id {
+		t.Fatalf("Expected the entry ids of different logs to be different, got %q", e1.Id)
+	}
+
+	scenarios := []struct {
+		name        string
+		lastEventId string
+		topics      []string
+		expectOk    bool
+		expected    string
+	}{
+		{"empty id", "", nil, false, ""},
+		{"unknown id", "missing", nil, false, ""},
+		{"id without epoch", "1", nil, false, ""},
+		{"id from another log", otherEntry.Id, nil, false, ""},
+		{"future id", epoch + "-100", nil, false, ""},
+		{"zero id", epoch + "-0", nil, true, "a:1,b:2,a:3,c:4"},
+		{"first entry id", e1.Id, nil, true, "b:2,a:3,c:4"},
+		{"with topics", e1.Id, []string{"a", "c"}, true, "a:3,c:4"},
+		{"last entry id", e4.Id, nil, true, ""},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			entries, ok := l.Since(s.lastEventId, s.topics...)
+
+			if ok != s.expectOk {
+				t.Fatalf("Expected ok %v, got %v", s.expectOk, ok)
+			}
+
+			if v := eventLogEntriesData(entries); v != s.expected {
+				t.Fatalf("Expected entries %q, got %q", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestEventLogMaxSize(t *testing.T) {
+	l := subscriptions.NewEventLog(2, 0)
+
+	e1 := l.Add("a", "1")
+	l.Add("b", "2")
+	l.Add("a", "3")
+	l.Add("a", "4")
+
+	// the max size is per topic
+	entries, ok := l.Since(e1.Id)
+	if !ok {
+		t.Fatal("Expected ok after the last evicted entry")
+	}
+	expected := "b:2,a:3,a:4"
+	if v := eventLogEntriesData(entries); v != expected {
+		t.Fatalf("Expected entries %q, got %q", expected, v)
+	}
+
+	// the evicted "a:1" entry is not missed by the other topic clients
+	entries, ok = l.Since(l.Add("b", "5").Id, "b")
+	if !ok || len(entries) != 0 {
+		t.Fatalf("Expected ok and no entries, got %v (%q)", ok, eventLogEntriesData(entries))
+	}
+
+	// missed evicted entry
+	if entries, ok := l.Since(eventLogZeroId(e1)); ok {
+		t.Fatalf("Expected not ok because of the missed evicted entry, got %q", eventLogEntriesData(entries))
+	}
+}
+
+func TestEventLogMaxAge(t *testing.T) {
+	l := subscriptions.NewEventLog(0, 50*time.Millisecond)
+
+	e1 := l.Add("a", "1")
+	l.Mark("test")
+
+	time.Sleep(60 * time.Millisecond)
+
+	l.Add("a", "2")
+
+	entries, ok := l.Since(e1.Id)
+	if !ok {
+		t.Fatal("Expected ok after the last expired entry")
+	}
+	if v := eventLogEntriesData(entries); v != "a:2" {
+		t.Fatalf("Expected only the non-expired entries, got %q", v)
+	}
+
+	if _, ok := l.Since(eventLogZeroId(e1)); ok {
+		t.Fatal("Expected not ok because of the missed expired entry")
+	}
+
+	if _, ok := l.Since("test"); ok {
+		t.Fatal("Expected the marker to be expired")
+	}
+}
+
+func TestEventLogMark(t *testing.T) {
+	t.Run("without max age", func(t *testing.T) {
+		l := subscriptions.NewEventLog(0, 0)
+
+		l.Mark("test")
+
+		if _, ok := l.Since("test"); ok {
+			t.Fatal("Expected the marker to be ignored")
+		}
+	})
+
+	t.Run("with max age", func(t *testing.T) {
+		l := subscriptions.NewEventLog(0, time.Minute)
+
+		l.Add("a", "1")
+		l.Mark("test")
+		l.Add("a", "2")
+
+		entries, ok := l.Since("test")
+		if !ok {
+			t.Fatal("Expected the marker to be found")
+		}
+
+		if v := eventLogEntriesData(entries); v != "a:2" {
+			t.Fatalf("Expected only the entries after the marker, got %q", v)
+		}
+
+		// remark
+		l.Mark("test")
+		entries, _ = l.Since("test")
+		if len(entries) != 0 {
+			t.Fatalf("Expected no entries after the new marker, got %d", len(entries))
+		}
+	})
+}
+
+func TestBrokerEventLog(t *testing.T) {
+	b := subscriptions.NewBroker()
+
+	if b.EventLog() == nil {
+		t.Fatal("Expected the default event log to be initialized")
+	}
+
+	b.SetEventLog(nil)
+
+	if b.EventLog() != nil {
+		t.Fatal("Expected the event log to be disabled")
+	}
+}
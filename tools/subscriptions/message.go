@@ -6,6 +6,8 @@ import (
 
 // Message defines a client's channel data.
 type Message struct {
+	// Id is an optional message event id (e.g. an [EventLogEntry] id).
+	Id   string `json:"id,omitempty"`
 	Name string `json:"name"`
 	Data []byte `json:"data"`
 }
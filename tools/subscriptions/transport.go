@@ -0,0 +1,137 @@
+package subscriptions
+
+import (
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/tools/routine"
+)
+
+// ErrTransportClosed is returned when trying to publish through an already closed transport.
+var ErrTransportClosed = errors.New("the transport is closed")
+
+// Transport defines a pluggable fan-out transport used to propagate
+// messages between multiple broker instances (aka. peers), for example
+// when running several app instances behind a load balancer.
+//
+// Implementations must not deliver the published payloads back to the publisher.
+type Transport interface {
+	// Publish sends the payload to all peers.
+	Publish(payload []byte) error
+
+	// Subscribe registers the handler that will be called for every
+	// payload received from a peer (replacing the previous one).
+	Subscribe(handler func(payload []byte))
+
+	// Close stops the transport and releases its resources.
+	Close() error
+}
+
+// -------------------------------------------------------------------
+
+// LocalHub is an in-memory [Transport] factory that connects multiple
+// broker instances running within the same process (e.g. in tests).
+type LocalHub struct {
+	transports []*localTransport
+	mu         sync.RWMutex
+}
+
+// NewLocalHub initializes and returns a new LocalHub instance.
+func NewLocalHub() *LocalHub {
+	return &LocalHub{}
+}
+
+// NewTransport creates a new [Transport] attached to the hub.
+//
+// Payloads published through the returned transport are delivered
+// asynchronously to all other transports attached to the same hub.
+func (h *LocalHub) NewTransport() Transport {
+	t := &localTransport{hub: h}
+
+	h.mu.Lock()
+	h.transports = append(h.transports, t)
+	h.mu.Unlock()
+
+	return t
+}
+
+func (h *LocalHub) detach(t *localTransport) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.transports = slices.DeleteFunc(h.transports, func(item *localTransport) bool {
+		return item == t
+	})
+}
+
+func (h *LocalHub) broadcast(from *localTransport, payload []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, t := range h.transports {
+		if t == from {
+			continue
+		}
+
+		handler := t.getHandler()
+		if handler == nil {
+			continue
+		}
+
+		// each peer receives its own copy
+		data := slices.Clone(payload)
+
+		routine.FireAndForget(func() {
+			handler(data)
+		})
+	}
+}
+
+var _ Transport = (*localTransport)(nil)
+
+type localTransport struct {
+	hub     *LocalHub
+	handler func(payload []byte)
+	closed  bool
+	mu      sync.RWMutex
+}
+
+func (t *localTransport) Publish(payload []byte) error {
+	t.mu.RLock()
+	closed := t.closed
+	t.mu.RUnlock()
+
+	if closed {
+		return ErrTransportClosed
+	}
+
+	t.hub.broadcast(t, payload)
+
+	return nil
+}
+
+func (t *localTransport) Subscribe(handler func(payload []byte)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.handler = handler
+}
+
+func (t *localTransport) Close() error {
+	t.mu.Lock()
+	t.closed = true
+	t.handler = nil
+	t.mu.Unlock()
+
+	t.hub.detach(t)
+
+	return nil
+}
+
+func (t *localTransport) getHandler() func(payload []byte) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.handler
+}
@@ -0,0 +1,472 @@
+package subscriptions
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MaxNetTransportPayloadSize is the max allowed size (in bytes)
+// of a single [NetTransport] payload.
+const MaxNetTransportPayloadSize = 16 << 20 // 16MB
+
+// MinNetTransportSecretLength is the min required length (in bytes)
+// of the [NetTransport] shared secret.
+const MinNetTransportSecretLength = 32
+
+// DefaultNetTransportQueueSize is the default max number
+// of pending frames per [NetTransport] peer.
+const DefaultNetTransportQueueSize = 1000
+
+// netTransportHeaderSize is the size of the frame header
+// (4 bytes payload size + 8 bytes timestamp + 8 bytes sender id +
+// 8 bytes sequence number + 32 bytes HMAC-SHA256 signature).
+const netTransportHeaderSize = 4 + 8 + 8 + 8 + sha256.Size
+
+// netTransportSignedOffset and netTransportSignatureOffset are the
+// offsets of the signed header part (timestamp, sender id and sequence) and the signature.
+const (
+	netTransportSignedOffset    = 4
+	netTransportSignatureOffset = 4 + 8 + 8 + 8
+)
+
+// ErrTransportQueueFull is returned when the frame couldn't be queued
+// because there are already too many pending frames for the peer.
+var ErrTransportQueueFull = errors.New("the peer publish queue is full")
+
+var _ Transport = (*NetTransport)(nil)
+
+// NetTransport is a peer-to-peer [Transport] implementation that
+// exchanges length-prefixed payloads with a static list of peers
+// over TCP or Unix domain sockets.
+//
+// Each instance listens on its own address and the published frames
+// are delivered asynchronously through a bounded queue per peer
+// (the peers are lazily dialed and broken connections are redialed on the next frame).
+//
+// Every frame is signed with HMAC-SHA256 using the shared secret of the peers
+// and contains the random id of the sender instance and a monotonic sequence number.
+// The frames with invalid signature are rejected (the connection is closed), while
+// the frames older than MaxFrameAge or with already received sequence number
+// (aka. replayed frames) are skipped. Note that the payloads are not encrypted so
+// the peers are still expected to communicate over a private network.
+type NetTransport struct {
+	listener      net.Listener
+	handler       func(payload []byte)
+	conns         map[string]net.Conn
+	queues        map[string]chan []byte
+	inbound       map[net.Conn]struct{}
+	senders       map[uint64]netTransportSender
+	sendersPruned time.Time
+	network       string
+	secret        []byte
+	peers         []string
+	senderId      uint64
+	sequence      uint64
+	closed        bool
+	mu            sync.RWMutex
+	sendersMu     sync.Mutex
+	wg            sync.WaitGroup
+	DialTimeout   time.Duration
+	WriteTimeout  time.Duration
+
+	// MaxFrameAge is the max allowed difference between the frame
+	// timestamp and the receiver local time (in both directions).
+	//
+	// The queued frames that become older than MaxFrameAge are not sent.
+	MaxFrameAge time.Duration
+
+	// QueueSize is the max number of pending frames per peer
+	// (the frames published to a peer with full queue are dropped).
+	//
+	// Changing it affects only the queues of the peers that weren't published yet.
+	QueueSize int
+
+	// OnSendError is an optional callback that is invoked
+	// when a queued frame fails to be delivered to a peer.
+	OnSendError func(peer string, err error)
+}
+
+// netTransportSender holds the last received sequence number of a single sender.
+type netTransportSender struct {
+	lastSeen time.Time
+	sequence uint64
+}
+
+// NewNetTransport creates a new [NetTransport] listening on listenAddr
+// and publishing to the specified peers addresses.
+//
+// network could be any stream oriented network supported by [net.Listen]
+// (e.g. "tcp", "tcp4", "unix").
+//
+// secret is the shared key used to sign and verify the exchanged frames.
+// It must be the same for all peers and at least [MinNetTransportSecretLength] bytes long.
+func NewNetTransport(network string, listenAddr string, secret []byte, peers []string) (*NetTransport, error) {
+	if len(secret) < MinNetTransportSecretLength {
+		return nil, fmt.Errorf("the transport secret must be at least %d bytes long", MinNetTransportSecretLength)
+	}
+
+	senderId := make([]byte, 8)
+	if _, err := rand.Read(senderId); err != nil {
+		return nil, fmt.Errorf("failed to generate the transport sender id: %w", err)
+	}
+
+	listener, err := net.Listen(network, listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the transport listener: %w", err)
+	}
+
+	t := &NetTransport{
+		listener:     listener,
+		network:      network,
+		secret:       slices.Clone(secret),
+		peers:        peers,
+		senderId:     binary.BigEndian.Uint64(senderId),
+		conns:        map[string]net.Conn{},
+		queues:       map[string]chan []byte{},
+		inbound:      map[net.Conn]struct{}{},
+		senders:      map[uint64]netTransportSender{},
+		DialTimeout:  5 * time.Second,
+		WriteTimeout: 5 * time.Second,
+		MaxFrameAge:  1 * time.Minute,
+		QueueSize:    DefaultNetTransportQueueSize,
+	}
+
+	t.wg.Add(1)
+	go t.acceptLoop()
+
+	return t, nil
+}
+
+// Addr returns the transport listener address.
+func (t *NetTransport) Addr() net.Addr {
+	return t.listener.Addr()
+}
+
+// SetPeers replaces the transport peers addresses.
+//
+// The pending frames of the removed peers are discarded.
+func (t *NetTransport) SetPeers(peers []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.peers = peers
+
+	// stop the queues and close the connections of the removed peers
+	for addr, queue := range t.queues {
+		if !slices.Contains(peers, addr) {
+			close(queue)
+			delete(t.queues, addr)
+		}
+	}
+	for addr, conn := range t.conns {
+		if !slices.Contains(peers, addr) {
+			conn.Close()
+			delete(t.conns, addr)
+		}
+	}
+}
+
+// Subscribe implements [Transport.Subscribe].
+func (t *NetTransport) Subscribe(handler func(payload []byte)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.handler = handler
+}
+
+// Publish implements [Transport.Publish].
+//
+// The payload is queued for all peers and sent in the background, aka.
+// the method doesn't wait for the peers delivery (see [NetTransport.OnSendError]).
+//
+// The returned error (if any) is a join of the individual peers queue errors
+// (e.g. [ErrTransportQueueFull] when the frame was dropped).
+func (t *NetTransport) Publish(payload []byte) error {
+	if len(payload) > MaxNetTransportPayloadSize {
+		return fmt.Errorf("the payload size exceeds the max allowed %d bytes", MaxNetTransportPayloadSize)
+	}
+
+	// note: the frames are queued under the same lock as the sequence
+	// increment so that each peer receives them in sequence order
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ErrTransportClosed
+	}
+
+	t.sequence++
+
+	frame := make([]byte, netTransportHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	binary.BigEndian.PutUint64(frame[4:], uint64(time.Now().UnixNano()))
+	binary.BigEndian.PutUint64(frame[12:], t.senderId)
+	binary.BigEndian.PutUint64(frame[20:], t.sequence)
+	copy(frame[netTransportHeaderSize:], payload)
+	copy(frame[netTransportSignatureOffset:netTransportHeaderSize], t.sign(frame[netTransportSignedOffset:netTransportSignatureOffset], payload))
+
+	var errs []error
+	for _, addr := range t.peers {
+		queue, ok := t.queues[addr]
+		if !ok {
+			queue = make(chan []byte, max(1, t.QueueSize))
+			t.queues[addr] = queue
+
+			t.wg.Add(1)
+			go t.sendLoop(addr, queue)
+		}
+
+		select {
+		case queue <- frame:
+		default:
+			errs = append(errs, fmt.Errorf("peer %s: %w", addr, ErrTransportQueueFull))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close implements [Transport.Close].
+//
+// The pending frames are discarded.
+func (t *NetTransport) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	for addr, queue := range t.queues {
+		close(queue)
+		delete(t.queues, addr)
+	}
+	for addr, conn := range t.conns {
+		conn.Close()
+		delete(t.conns, addr)
+	}
+	for conn := range t.inbound {
+		conn.Close()
+	}
+	t.mu.Unlock()
+
+	err := t.listener.Close()
+
+	t.wg.Wait()
+
+	return err
+}
+
+// sendLoop delivers the queued frames of a single peer until the queue is closed.
+func (t *NetTransport) sendLoop(addr string, queue chan []byte) {
+	defer t.wg.Done()
+
+	for frame := range queue {
+		// skip the frames that will be rejected by the peer anyway
+		// (e.g. accumulated while the peer was unreachable)
+		timestamp := time.Unix(0, int64(binary.BigEndian.Uint64(frame[4:12])))
+		if time.Since(timestamp) > t.MaxFrameAge {
+			continue
+		}
+
+		if err := t.send(addr, frame); err != nil && t.OnSendError != nil {
+			t.OnSendError(addr, err)
+		}
+	}
+}
+
+func (t *NetTransport) send(addr string, frame []byte) error {
+	// retry once with a fresh connection in case the existing one was broken
+	var err error
+	for i := 0; i < 2; i++ {
+		var conn net.Conn
+		conn, err = t.conn(addr)
+		if err != nil {
+			return err
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(t.WriteTimeout))
+
+		if _, err = conn.Write(frame); err == nil {
+			return nil
+		}
+
+		t.dropConn(addr, conn)
+	}
+
+	return err
+}
+
+// conn returns the existing peer connection or dials a new one.
+//
+// Note that it is expected to be called only by the peer sendLoop.
+func (t *NetTransport) conn(addr string) (net.Conn, error) {
+	t.mu.RLock()
+	conn, ok := t.conns[addr]
+	t.mu.RUnlock()
+	if ok {
+		return conn, nil
+	}
+
+	// dial without holding the lock to not block the other publishers
+	conn, err := net.DialTimeout(t.network, addr, t.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		conn.Close()
+		return nil, ErrTransportClosed
+	}
+
+	if !slices.Contains(t.peers, addr) {
+		conn.Close()
+		return nil, errors.New("the peer was removed")
+	}
+
+	t.conns[addr] = conn
+
+	return conn, nil
+}
+
+func (t *NetTransport) dropConn(addr string, conn net.Conn) {
+	conn.Close()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conns[addr] == conn {
+		delete(t.conns, addr)
+	}
+}
+
+func (t *NetTransport) acceptLoop() {
+	defer t.wg.Done()
+
+	for {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		t.mu.Lock()
+		if t.closed {
+			t.mu.Unlock()
+			conn.Close()
+			return
+		}
+		t.inbound[conn] = struct{}{}
+		t.mu.Unlock()
+
+		t.wg.Add(1)
+		go t.readLoop(conn)
+	}
+}
+
+func (t *NetTransport) readLoop(conn net.Conn) {
+	defer func() {
+		conn.Close()
+
+		t.mu.Lock()
+		delete(t.inbound, conn)
+		t.mu.Unlock()
+
+		t.wg.Done()
+	}()
+
+	reader := bufio.NewReader(conn)
+	header := make([]byte, netTransportHeaderSize)
+
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return
+		}
+
+		size := binary.BigEndian.Uint32(header)
+		if size > MaxNetTransportPayloadSize {
+			return // malformed or malicious frame
+		}
+
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return
+		}
+
+		signed := header[netTransportSignedOffset:netTransportSignatureOffset]
+		if !hmac.Equal(header[netTransportSignatureOffset:], t.sign(signed, payload)) {
+			return // unauthenticated peer
+		}
+
+		timestamp := time.Unix(0, int64(binary.BigEndian.Uint64(header[4:12])))
+		if age := time.Since(timestamp); age > t.MaxFrameAge || age < -t.MaxFrameAge {
+			continue // expired frame
+		}
+
+		senderId := binary.BigEndian.Uint64(header[12:20])
+		sequence := binary.BigEndian.Uint64(header[20:28])
+		if !t.acceptSequence(senderId, sequence) {
+			continue // replayed frame
+		}
+
+		t.mu.RLock()
+		handler := t.handler
+		t.mu.RUnlock()
+
+		if handler != nil {
+			handler(payload)
+		}
+	}
+}
+
+// acceptSequence reports whether the frame sequence number is newer than
+// the last received one from the same sender and stores it if it is.
+func (t *NetTransport) acceptSequence(senderId uint64, sequence uint64) bool {
+	t.sendersMu.Lock()
+	defer t.sendersMu.Unlock()
+
+	now := time.Now()
+
+	// forget the inactive senders since their already received frames
+	// are older than MaxFrameAge and are rejected by the timestamp check
+	// (doubled to account for the peers clock differences)
+	if now.Sub(t.sendersPruned) > t.MaxFrameAge {
+		for id, sender := range t.senders {
+			if now.Sub(sender.lastSeen) > 2*t.MaxFrameAge {
+				delete(t.senders, id)
+			}
+		}
+		t.sendersPruned = now
+	}
+
+	if sender, ok := t.senders[senderId]; ok && sequence <= sender.sequence {
+		return false
+	}
+
+	t.senders[senderId] = netTransportSender{sequence: sequence, lastSeen: now}
+
+	return true
+}
+
+// sign returns the HMAC-SHA256 signature of the signed frame header part
+// (timestamp, sender id and sequence) and the payload.
+func (t *NetTransport) sign(signedHeader []byte, payload []byte) []byte {
+	h := hmac.New(sha256.New, t.secret)
+	h.Write(signedHeader)
+	h.Write(payload)
+	return h.Sum(nil)
+}
@@ -0,0 +1,346 @@
+package subscriptions_test
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+)
+
+func waitPayload(t *testing.T, ch chan []byte, expected string) {
+	t.Helper()
+
+	select {
+	case payload := <-ch:
+		if string(payload) != expected {
+			t.Fatalf("Expected payload %q, got %q", expected, payload)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("Timeout waiting for payload %q", expected)
+	}
+}
+
+func expectNoPayload(t *testing.T, ch chan []byte) {
+	t.Helper()
+
+	select {
+	case payload := <-ch:
+		t.Fatalf("Expected no payload, got %q", payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBrokerPublishWithoutTransport(t *testing.T) {
+	b := subscriptions.NewBroker()
+
+	if b.Transport() != nil {
+		t.Fatal("Expected nil transport")
+	}
+
+	if err := b.Publish([]byte("test")); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+}
+
+func TestLocalHub(t *testing.T) {
+	hub := subscriptions.NewLocalHub()
+
+	b1 := subscriptions.NewBroker()
+	b2 := subscriptions.NewBroker()
+	b3 := subscriptions.NewBroker()
+
+	b1.SetTransport(hub.NewTransport())
+	b2.SetTransport(hub.NewTransport())
+	b3.SetTransport(hub.NewTransport())
+
+	ch1 := make(chan []byte, 10)
+	ch2 := make(chan []byte, 10)
+	ch3 := make(chan []byte, 10)
+
+	b1.SetPeerHandler(func(payload []byte) { ch1 <- payload })
+	b2.SetPeerHandler(func(payload []byte) { ch2 <- payload })
+	b3.SetPeerHandler(func(payload []byte) { ch3 <- payload })
+
+	if err := b1.Publish([]byte("test1")); err != nil {
+		t.Fatal(err)
+	}
+
+	waitPayload(t, ch2, "test1")
+	waitPayload(t, ch3, "test1")
+	expectNoPayload(t, ch1) // no echo
+
+	// close b3 transport
+	if err := b3.Transport().Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b3.Publish([]byte("test2")); !errors.Is(err, subscriptions.ErrTransportClosed) {
+		t.Fatalf("Expected ErrTransportClosed, got %v", err)
+	}
+
+	if err := b2.Publish([]byte("test3")); err != nil {
+		t.Fatal(err)
+	}
+
+	waitPayload(t, ch1, "test3")
+	expectNoPayload(t, ch3)
+}
+
+var testTransportSecret = []byte("01234567890123456789012345678901")
+
+func TestNetTransport(t *testing.T) {
+	scenarios := []struct {
+		network string
+		addr    func(t *testing.T, name string) string
+	}{
+		{
+			"tcp",
+			func(t *testing.T, name string) string { return "127.0.0.1:0" },
+		},
+		{
+			"unix",
+			func(t *testing.T, name string) string { return t.TempDir() + "/" + name + ".sock" },
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.network, func(t *testing.T) {
+			t1, err := subscriptions.NewNetTransport(s.network, s.addr(t, "t1"), testTransportSecret, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer t1.Close()
+
+			t2, err := subscriptions.NewNetTransport(s.network, s.addr(t, "t2"), testTransportSecret, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer t2.Close()
+
+			t1.SetPeers([]string{t2.Addr().String()})
+			t2.SetPeers([]string{t1.Addr().String()})
+
+			b1 := subscriptions.NewBroker()
+			b1.SetTransport(t1)
+			ch1 := make(chan []byte, 10)
+			b1.SetPeerHandler(func(payload []byte) { ch1 <- payload })
+
+			b2 := subscriptions.NewBroker()
+			b2.SetTransport(t2)
+			ch2 := make(chan []byte, 10)
+			b2.SetPeerHandler(func(payload []byte) { ch2 <- payload })
+
+			if err := b1.Publish([]byte("test1")); err != nil {
+				t.Fatal(err)
+			}
+			if err := b1.Publish([]byte("")); err != nil {
+				t.Fatal(err)
+			}
+			if err := b2.Publish([]byte("test2")); err != nil {
+				t.Fatal(err)
+			}
+
+			waitPayload(t, ch2, "test1")
+			waitPayload(t, ch2, "")
+			waitPayload(t, ch1, "test2")
+			expectNoPayload(t, ch1)
+			expectNoPayload(t, ch2)
+		})
+	}
+}
+
+func TestNetTransportUnreachablePeer(t *testing.T) {
+	tr, err := subscriptions.NewNetTransport("unix", t.TempDir()+"/t.sock", testTransportSecret, []string{t.TempDir() + "/missing.sock"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sendErrs := make(chan error, 10)
+	tr.OnSendError = func(peer string, err error) { sendErrs <- err }
+
+	// the publish shouldn't wait for the peer delivery
+	if err := tr.Publish([]byte("test")); err != nil {
+		t.Fatalf("Expected nil publish error, got %v", err)
+	}
+
+	select {
+	case err := <-sendErrs:
+		if err == nil {
+			t.Fatal("Expected non-nil send error")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timeout waiting for the send error")
+	}
+
+	if err := tr.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tr.Publish([]byte("test")); !errors.Is(err, subscriptions.ErrTransportClosed) {
+		t.Fatalf("Expected ErrTransportClosed, got %v", err)
+	}
+}
+
+func TestNetTransportShortSecret(t *testing.T) {
+	_, err := subscriptions.NewNetTransport("unix", t.TempDir()+"/t.sock", []byte("short"), nil)
+	if err == nil {
+		t.Fatal("Expected short secret error")
+	}
+}
+
+func TestNetTransportInvalidSignature(t *testing.T) {
+	t1, err := subscriptions.NewNetTransport("unix", t.TempDir()+"/t1.sock", testTransportSecret, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer t1.Close()
+
+	t2, err := subscriptions.NewNetTransport("unix", t.TempDir()+"/t2.sock", []byte("abcdefghijabcdefghijabcdefghijab"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer t2.Close()
+
+	t1.SetPeers([]string{t2.Addr().String()})
+
+	ch2 := make(chan []byte, 10)
+	t2.Subscribe(func(payload []byte) { ch2 <- payload })
+
+	if err := t1.Publish([]byte("test")); err != nil {
+		t.Fatal(err)
+	}
+
+	expectNoPayload(t, ch2)
+}
+
+func TestNetTransportExpiredFrame(t *testing.T) {
+	t1, err := subscriptions.NewNetTransport("unix", t.TempDir()+"/t1.sock", testTransportSecret, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer t1.Close()
+
+	t2, err := subscriptions.NewNetTransport("unix", t.TempDir()+"/t2.sock", testTransportSecret, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer t2.Close()
+	t2.MaxFrameAge = -1 // all frames are expired
+
+	t1.SetPeers([]string{t2.Addr().String()})
+
+	ch2 := make(chan []byte, 10)
+	t2.Subscribe(func(payload []byte) { ch2 <- payload })
+
+	if err := t1.Publish([]byte("test")); err != nil {
+		t.Fatal(err)
+	}
+
+	expectNoPayload(t, ch2)
+}
+
+func TestNetTransportQueueFull(t *testing.T) {
+	// a peer that accepts the connection but never reads from it
+	peer, err := net.Listen("unix", t.TempDir()+"/peer.sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer.Close()
+
+	tr, err := subscriptions.NewNetTransport("unix", t.TempDir()+"/t.sock", testTransportSecret, []string{peer.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+	tr.QueueSize = 1
+	tr.WriteTimeout = 100 * time.Millisecond
+
+	payload := make([]byte, subscriptions.MaxNetTransportPayloadSize)
+
+	var queueFull bool
+	for i := 0; i < 100; i++ {
+		if err := tr.Publish(payload); err != nil {
+			if !errors.Is(err, subscriptions.ErrTransportQueueFull) {
+				t.Fatalf("Expected ErrTransportQueueFull, got %v", err)
+			}
+			queueFull = true
+			break
+		}
+	}
+
+	if !queueFull {
+		t.Fatal("Expected the peer queue to be full")
+	}
+}
+
+func TestNetTransportReplayedFrame(t *testing.T) {
+	// capture the raw frames sent by t1
+	capture, err := net.Listen("unix", t.TempDir()+"/capture.sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer capture.Close()
+
+	t1, err := subscriptions.NewNetTransport("unix", t.TempDir()+"/t1.sock", testTransportSecret, []string{capture.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer t1.Close()
+
+	t2, err := subscriptions.NewNetTransport("unix", t.TempDir()+"/t2.sock", testTransportSecret, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer t2.Close()
+
+	ch2 := make(chan []byte, 10)
+	t2.Subscribe(func(payload []byte) { ch2 <- payload })
+
+	if err := t1.Publish([]byte("test1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := t1.Publish([]byte("test2")); err != nil {
+		t.Fatal(err)
+	}
+
+	captured, err := capture.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer captured.Close()
+
+	// read the two captured frames (both have the same size)
+	var frames []byte
+	buf := make([]byte, 1024)
+	captured.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	for {
+		n, err := captured.Read(buf)
+		frames = append(frames, buf[:n]...)
+		if err != nil {
+			break
+		}
+	}
+	if len(frames) == 0 || len(frames)%2 != 0 {
+		t.Fatalf("Expected 2 captured frames, got %d bytes", len(frames))
+	}
+	frame1 := frames[:len(frames)/2]
+	frame2 := frames[len(frames)/2:]
+
+	conn, err := net.Dial("unix", t2.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for _, frame := range [][]byte{frame1, frame2, frame1, frame2} {
+		if _, err := conn.Write(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	waitPayload(t, ch2, "test1")
+	waitPayload(t, ch2, "test2")
+	expectNoPayload(t, ch2)
+}
@@ -0,0 +1,325 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// maxCBORDepth limits the nesting level of the decoded CBOR items.
+const maxCBORDepth = 16
+
+var errCBORUnexpectedEnd = errors.New("cbor: unexpected end of data")
+
+// DecodeCBOR decodes the first CBOR data item from data and returns
+// the decoded value together with the remaining unread bytes.
+//
+// Only the subset of CBOR used by WebAuthn is supported, aka. definite
+// length items. The values are decoded as:
+//   - unsigned and negative integers -> int64
+//   - byte strings -> []byte
+//   - text strings -> string
+//   - arrays -> []any
+//   - maps -> map[any]any
+//   - false, true -> bool
+//   - null, undefined -> nil
+//   - floats -> float64
+//
+// Tags are ignored and only their content is returned.
+func DecodeCBOR(data []byte) (any, []byte, error) {
+	d := &cborDecoder{data: data}
+
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return v, d.data[d.pos:], nil
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errCBORUnexpectedEnd
+	}
+
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+
+	return b, nil
+}
+
+// head reads the item initial byte and its argument.
+func (d *cborDecoder) head() (major byte, info byte, arg uint64, err error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	major = b[0] >> 5
+	info = b[0] & 0x1f
+
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info == 24:
+		b, err = d.read(1)
+		if err == nil {
+			arg = uint64(b[0])
+		}
+	case info == 25:
+		b, err = d.read(2)
+		if err == nil {
+			arg = uint64(binary.BigEndian.Uint16(b))
+		}
+	case info == 26:
+		b, err = d.read(4)
+		if err == nil {
+			arg = uint64(binary.BigEndian.Uint32(b))
+		}
+	case info == 27:
+		b, err = d.read(8)
+		if err == nil {
+			arg = binary.BigEndian.Uint64(b)
+		}
+	default:
+		err = fmt.Errorf("cbor: unsupported additional info %d", info)
+	}
+
+	return major, info, arg, err
+}
+
+func (d *cborDecoder) decode(depth int) (any, error) {
+	if depth > maxCBORDepth {
+		return nil, errors.New("cbor: max nesting depth reached")
+	}
+
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0: // unsigned int
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflow")
+		}
+		return int64(arg), nil
+	case 1: // negative int
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflow")
+		}
+		return -1 - int64(arg), nil
+	case 2: // byte string
+		b, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+		return slices.Clone(b), nil
+	case 3: // text string
+		b, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case 4: // array
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, errCBORUnexpectedEnd
+		}
+		result := make([]any, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, v)
+		}
+		return result, nil
+	case 5: // map
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, errCBORUnexpectedEnd
+		}
+		result := make(map[any]any, arg)
+		for i := uint64(0); i < arg; i++ {
+			k, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, fmt.Errorf("cbor: unsupported map key type %T", k)
+			}
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = v
+		}
+		return result, nil
+	case 6: // tag
+		return d.decode(depth + 1)
+	default: // simple values and floats
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		case 25:
+			return float64(float16ToFloat32(uint16(arg))), nil
+		case 26:
+			return float64(math.Float32frombits(uint32(arg))), nil
+		case 27:
+			return math.Float64frombits(arg), nil
+		}
+		return nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+	}
+}
+
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+
+	switch exp {
+	case 0:
+		// subnormal
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | frac<<13)
+	}
+
+	return math.Float32frombits(sign | (exp+112)<<23 | frac<<13)
+}
+
+// -------------------------------------------------------------------
+
+// EncodeCBOR encodes v as CBOR data item.
+//
+// It is the reverse of [DecodeCBOR] and supports the same subset of value types
+// (in addition all Go integer types are accepted). The map keys are
+// sorted by their encoded form as required by the CTAP2 canonical encoding.
+//
+// It is mainly intended to be used in tests for creating authenticator responses.
+func EncodeCBOR(v any) ([]byte, error) {
+	return appendCBOR(nil, v)
+}
+
+func appendCBORHead(dst []byte, major byte, arg uint64) []byte {
+	major <<= 5
+
+	switch {
+	case arg < 24:
+		return append(dst, major|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(dst, major|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, major|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, major|26), uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, major|27), arg)
+	}
+}
+
+func appendCBORInt(dst []byte, v int64) []byte {
+	if v < 0 {
+		return appendCBORHead(dst, 1, uint64(-1-v))
+	}
+	return appendCBORHead(dst, 0, uint64(v))
+}
+
+func appendCBOR(dst []byte, v any) ([]byte, error) {
+	switch val := v.(type) {
+	case nil:
+		return append(dst, 0xf6), nil
+	case bool:
+		if val {
+			return append(dst, 0xf5), nil
+		}
+		return append(dst, 0xf4), nil
+	case int:
+		return appendCBORInt(dst, int64(val)), nil
+	case int8:
+		return appendCBORInt(dst, int64(val)), nil
+	case int16:
+		return appendCBORInt(dst, int64(val)), nil
+	case int32:
+		return appendCBORInt(dst, int64(val)), nil
+	case int64:
+		return appendCBORInt(dst, val), nil
+	case uint:
+		return appendCBORHead(dst, 0, uint64(val)), nil
+	case uint8:
+		return appendCBORHead(dst, 0, uint64(val)), nil
+	case uint16:
+		return appendCBORHead(dst, 0, uint64(val)), nil
+	case uint32:
+		return appendCBORHead(dst, 0, uint64(val)), nil
+	case uint64:
+		return appendCBORHead(dst, 0, val), nil
+	case float64:
+		return binary.BigEndian.AppendUint64(append(dst, 0xfb), math.Float64bits(val)), nil
+	case []byte:
+		return append(appendCBORHead(dst, 2, uint64(len(val))), val...), nil
+	case string:
+		return append(appendCBORHead(dst, 3, uint64(len(val))), val...), nil
+	case []any:
+		dst = appendCBORHead(dst, 4, uint64(len(val)))
+		for _, item := range val {
+			var err error
+			dst, err = appendCBOR(dst, item)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case map[any]any:
+		type entry struct {
+			key   []byte
+			value any
+		}
+
+		entries := make([]entry, 0, len(val))
+		for k, item := range val {
+			key, err := appendCBOR(nil, k)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry{key, item})
+		}
+
+		// canonical order - shorter keys first, then lexical
+		slices.SortFunc(entries, func(a, b entry) int {
+			if len(a.key) != len(b.key) {
+				return len(a.key) - len(b.key)
+			}
+			return slices.Compare(a.key, b.key)
+		})
+
+		dst = appendCBORHead(dst, 5, uint64(len(entries)))
+		for _, e := range entries {
+			var err error
+			dst = append(dst, e.key...)
+			dst, err = appendCBOR(dst, e.value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	default:
+		return nil, fmt.Errorf("cbor: unsupported value type %T", v)
+	}
+}
@@ -0,0 +1,134 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// The supported COSE algorithm identifiers.
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// SupportedAlgorithms lists the supported COSE algorithms
+// in the order of preference.
+var SupportedAlgorithms = []int{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE key parameters.
+const (
+	coseKeyKty = 1
+	coseKeyAlg = 3
+	coseKeyCrv = -1 // EC2 and OKP
+	coseKeyX   = -2 // EC2 and OKP
+	coseKeyY   = -3 // EC2
+	coseKeyN   = -1 // RSA
+	coseKeyE   = -2 // RSA
+
+	coseKtyOKP = 1
+	coseKtyEC2 = 2
+	coseKtyRSA = 3
+
+	coseCrvP256    = 1
+	coseCrvEd25519 = 6
+)
+
+// PublicKey represents a parsed COSE credential public key.
+type PublicKey struct {
+	key       crypto.PublicKey
+	Algorithm int
+}
+
+// ParsePublicKey parses the provided CBOR encoded COSE_Key.
+func ParsePublicKey(raw []byte) (*PublicKey, error) {
+	v, _, err := DecodeCBOR(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := v.(map[any]any)
+	if !ok {
+		return nil, errors.New("cose: the public key is not a map")
+	}
+
+	kty, _ := m[int64(coseKeyKty)].(int64)
+	alg, _ := m[int64(coseKeyAlg)].(int64)
+
+	result := &PublicKey{Algorithm: int(alg)}
+
+	switch {
+	case kty == coseKtyEC2 && alg == AlgES256:
+		crv, _ := m[int64(coseKeyCrv)].(int64)
+		x, _ := m[int64(coseKeyX)].([]byte)
+		y, _ := m[int64(coseKeyY)].([]byte)
+		if crv != coseCrvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("cose: invalid EC2 P-256 key")
+		}
+
+		pub := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("cose: the EC2 point is not on the curve")
+		}
+		result.key = pub
+	case kty == coseKtyOKP && alg == AlgEdDSA:
+		crv, _ := m[int64(coseKeyCrv)].(int64)
+		x, _ := m[int64(coseKeyX)].([]byte)
+		if crv != coseCrvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("cose: invalid OKP Ed25519 key")
+		}
+		result.key = ed25519.PublicKey(x)
+	case kty == coseKtyRSA && alg == AlgRS256:
+		n, _ := m[int64(coseKeyN)].([]byte)
+		e, _ := m[int64(coseKeyE)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("cose: invalid RSA key")
+		}
+
+		exp := 0
+		for _, b := range e {
+			exp = exp<<8 | int(b)
+		}
+
+		result.key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exp}
+	default:
+		return nil, fmt.Errorf("cose: unsupported key type %d with algorithm %d", kty, alg)
+	}
+
+	return result, nil
+}
+
+// Verify checks the signature of the provided data.
+func (k *PublicKey) Verify(data []byte, sig []byte) error {
+	switch pub := k.key.(type) {
+	case *ecdsa.PublicKey:
+		hash := sha256.Sum256(data)
+		if !ecdsa.VerifyASN1(pub, hash[:], sig) {
+			return errors.New("invalid ES256 signature")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, data, sig) {
+			return errors.New("invalid EdDSA signature")
+		}
+	case *rsa.PublicKey:
+		hash := sha256.Sum256(data)
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], sig); err != nil {
+			return fmt.Errorf("invalid RS256 signature: %w", err)
+		}
+	default:
+		return errors.New("unsupported public key")
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package webauthn
+
+// RelyingParty defines the PublicKeyCredentialRpEntity options.
+type RelyingParty struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// User defines the PublicKeyCredentialUserEntity options.
+type User struct {
+	Id          Base64URL `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"displayName"`
+}
+
+// CredentialParameter defines a single PublicKeyCredentialParameters option.
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// CredentialDescriptor defines the PublicKeyCredentialDescriptor options.
+type CredentialDescriptor struct {
+	Type       string    `json:"type"`
+	Id         Base64URL `json:"id"`
+	Transports []string  `json:"transports,omitempty"`
+}
+
+// AuthenticatorSelection defines the AuthenticatorSelectionCriteria options.
+type AuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// CreationOptions defines the JSON serialized PublicKeyCredentialCreationOptions
+// that could be passed to the browser PublicKeyCredential.parseCreationOptionsFromJSON().
+type CreationOptions struct {
+	RP                     RelyingParty           `json:"rp"`
+	User                   User                   `json:"user"`
+	Challenge              string                 `json:"challenge"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions defines the JSON serialized PublicKeyCredentialRequestOptions
+// that could be passed to the browser PublicKeyCredential.parseRequestOptionsFromJSON().
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPId             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// PublicKeyCredentialParameters returns the default credential
+// parameters for the [SupportedAlgorithms].
+func PublicKeyCredentialParameters() []CredentialParameter {
+	result := make([]CredentialParameter, len(SupportedAlgorithms))
+
+	for i, alg := range SupportedAlgorithms {
+		result[i] = CredentialParameter{Type: "public-key", Alg: alg}
+	}
+
+	return result
+}
+
+// -------------------------------------------------------------------
+
+// RegistrationResponse defines the JSON serialized PublicKeyCredential
+// returned from the browser navigator.credentials.create() call.
+type RegistrationResponse struct {
+	Id       string    `json:"id"`
+	RawId    Base64URL `json:"rawId"`
+	Type     string    `json:"type"`
+	Response struct {
+		ClientDataJSON    Base64URL `json:"clientDataJSON"`
+		AttestationObject Base64URL `json:"attestationObject"`
+		Transports        []string  `json:"transports"`
+	} `json:"response"`
+}
+
+// AssertionResponse defines the JSON serialized PublicKeyCredential
+// returned from the browser navigator.credentials.get() call.
+type AssertionResponse struct {
+	Id       string    `json:"id"`
+	RawId    Base64URL `json:"rawId"`
+	Type     string    `json:"type"`
+	Response struct {
+		ClientDataJSON    Base64URL `json:"clientDataJSON"`
+		AuthenticatorData Base64URL `json:"authenticatorData"`
+		Signature         Base64URL `json:"signature"`
+		UserHandle        Base64URL `json:"userHandle"`
+	} `json:"response"`
+}
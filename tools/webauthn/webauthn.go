@@ -0,0 +1,325 @@
+// Package webauthn implements the server side verification of the
+// WebAuthn (passkeys) registration and authentication ceremonies.
+//
+// Note that the authenticator attestation statements are not verified
+// since the ceremonies are expected to request "none" attestation
+// conveyance, aka. the authenticator model is not trusted in any way.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// The authenticator data flags.
+const (
+	FlagUserPresent            byte = 0x01
+	FlagUserVerified           byte = 0x04
+	FlagBackupEligible         byte = 0x08
+	FlagBackupState            byte = 0x10
+	FlagAttestedCredentialData byte = 0x40
+	FlagExtensionData          byte = 0x80
+)
+
+// The client data types.
+const (
+	ClientDataTypeCreate = "webauthn.create"
+	ClientDataTypeGet    = "webauthn.get"
+)
+
+// The user verification requirements.
+const (
+	UserVerificationRequired    = "required"
+	UserVerificationPreferred   = "preferred"
+	UserVerificationDiscouraged = "discouraged"
+)
+
+// Base64URL is a byte slice that is JSON serialized as base64url string.
+//
+// For compatibility with the different clients the decoding accepts
+// both padded and unpadded standard and url base64 encodings.
+type Base64URL []byte
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (b Base64URL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface.
+func (b *Base64URL) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	decoded, err := DecodeBase64URL(str)
+	if err != nil {
+		return err
+	}
+
+	*b = decoded
+
+	return nil
+}
+
+// String returns the unpadded base64url encoded bytes.
+func (b Base64URL) String() string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeBase64URL decodes the provided base64url or standard base64
+// string (the padding is optional).
+func DecodeBase64URL(str string) ([]byte, error) {
+	str = strings.TrimRight(str, "=")
+	str = strings.NewReplacer("+", "-", "/", "_").Replace(str)
+
+	return base64.RawURLEncoding.DecodeString(str)
+}
+
+// NewChallenge generates a new random base64url encoded ceremony challenge.
+func NewChallenge() string {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// -------------------------------------------------------------------
+
+// ClientData defines the WebAuthn CollectedClientData fields.
+type ClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// AuthenticatorData defines the parsed WebAuthn authenticator data.
+type AuthenticatorData struct {
+	RPIdHash  []byte
+	Flags     byte
+	SignCount uint32
+
+	// the below fields are available only with FlagAttestedCredentialData
+	AAGUID              []byte
+	CredentialId        []byte
+	CredentialPublicKey []byte
+}
+
+// HasFlag checks whether the specified authenticator data flag is set.
+func (d *AuthenticatorData) HasFlag(flag byte) bool {
+	return d.Flags&flag == flag
+}
+
+// ParseAuthenticatorData parses the raw WebAuthn authenticator data.
+func ParseAuthenticatorData(raw []byte) (*AuthenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, errors.New("authenticator data is too short")
+	}
+
+	result := &AuthenticatorData{
+		RPIdHash:  slices.Clone(raw[:32]),
+		Flags:     raw[32],
+		SignCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+
+	if !result.HasFlag(FlagAttestedCredentialData) {
+		return result, nil
+	}
+
+	rest := raw[37:]
+	if len(rest) < 18 {
+		return nil, errors.New("attested credential data is too short")
+	}
+
+	result.AAGUID = slices.Clone(rest[:16])
+
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if idLength == 0 || idLength > 1023 || len(rest) < idLength {
+		return nil, errors.New("invalid credential id length")
+	}
+	result.CredentialId = slices.Clone(rest[:idLength])
+	rest = rest[idLength:]
+
+	// the public key is followed by the optional extensions
+	_, remaining, err := DecodeCBOR(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential public key: %w", err)
+	}
+	result.CredentialPublicKey = slices.Clone(rest[:len(rest)-len(remaining)])
+
+	return result, nil
+}
+
+// -------------------------------------------------------------------
+
+// CeremonyOptions defines the common expected values of a ceremony response.
+type CeremonyOptions struct {
+	// Challenge is the base64url encoded ceremony challenge.
+	Challenge string
+
+	// RPId is the relying party id (usually the app domain).
+	RPId string
+
+	// Origins is the list of the allowed client origins (e.g. "https://example.com").
+	Origins []string
+
+	// RequireUserVerification requires the user verified flag to be set.
+	RequireUserVerification bool
+}
+
+func (o CeremonyOptions) verifyClientData(raw []byte, expectedType string) error {
+	clientData := ClientData{}
+	if err := json.Unmarshal(raw, &clientData); err != nil {
+		return fmt.Errorf("invalid client data: %w", err)
+	}
+
+	if clientData.Type != expectedType {
+		return fmt.Errorf("invalid client data type %q", clientData.Type)
+	}
+
+	if o.Challenge == "" || subtle.ConstantTimeCompare([]byte(clientData.Challenge), []byte(o.Challenge)) != 1 {
+		return errors.New("client data challenge mismatch")
+	}
+
+	if !slices.Contains(o.Origins, clientData.Origin) {
+		return fmt.Errorf("client data origin %q is not allowed", clientData.Origin)
+	}
+
+	return nil
+}
+
+func (o CeremonyOptions) verifyAuthData(authData *AuthenticatorData) error {
+	rpIdHash := sha256.Sum256([]byte(o.RPId))
+	if !bytes.Equal(authData.RPIdHash, rpIdHash[:]) {
+		return errors.New("relying party id hash mismatch")
+	}
+
+	if !authData.HasFlag(FlagUserPresent) {
+		return errors.New("missing user presence")
+	}
+
+	if o.RequireUserVerification && !authData.HasFlag(FlagUserVerified) {
+		return errors.New("missing user verification")
+	}
+
+	return nil
+}
+
+// Credential defines a newly registered WebAuthn credential.
+type Credential struct {
+	Id             []byte
+	PublicKey      []byte // CBOR encoded COSE_Key
+	AAGUID         []byte
+	Algorithm      int
+	SignCount      uint32
+	BackupEligible bool
+}
+
+// VerifyRegistration verifies the registration ceremony response
+// and returns the new credential data.
+func VerifyRegistration(options CeremonyOptions, clientDataJSON []byte, attestationObject []byte) (*Credential, error) {
+	if err := options.verifyClientData(clientDataJSON, ClientDataTypeCreate); err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := DecodeCBOR(attestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation object: %w", err)
+	}
+
+	attestation, ok := decoded.(map[any]any)
+	if !ok {
+		return nil, errors.New("invalid attestation object")
+	}
+
+	rawAuthData, _ := attestation["authData"].([]byte)
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := options.verifyAuthData(authData); err != nil {
+		return nil, err
+	}
+
+	if !authData.HasFlag(FlagAttestedCredentialData) {
+		return nil, errors.New("missing attested credential data")
+	}
+
+	publicKey, err := ParsePublicKey(authData.CredentialPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		Id:             authData.CredentialId,
+		PublicKey:      authData.CredentialPublicKey,
+		AAGUID:         authData.AAGUID,
+		Algorithm:      publicKey.Algorithm,
+		SignCount:      authData.SignCount,
+		BackupEligible: authData.HasFlag(FlagBackupEligible),
+	}, nil
+}
+
+// VerifyAssertion verifies the authentication ceremony response
+// against the stored credential public key and signature counter.
+//
+// On success it returns the new authenticator signature counter.
+func VerifyAssertion(
+	options CeremonyOptions,
+	publicKey []byte,
+	storedSignCount uint32,
+	clientDataJSON []byte,
+	rawAuthData []byte,
+	signature []byte,
+) (uint32, error) {
+	if err := options.verifyClientData(clientDataJSON, ClientDataTypeGet); err != nil {
+		return 0, err
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := options.verifyAuthData(authData); err != nil {
+		return 0, err
+	}
+
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+
+	signed := make([]byte, 0, len(rawAuthData)+len(clientDataHash))
+	signed = append(signed, rawAuthData...)
+	signed = append(signed, clientDataHash[:]...)
+
+	if err := key.Verify(signed, signature); err != nil {
+		return 0, err
+	}
+
+	// authenticators that don't support signature counters always return 0
+	if (authData.SignCount != 0 || storedSignCount != 0) && authData.SignCount <= storedSignCount {
+		return 0, errors.New("the signature counter has not increased (possibly cloned authenticator)")
+	}
+
+	return authData.SignCount, nil
+}
@@ -0,0 +1,442 @@
+package webauthn_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/webauthn"
+)
+
+const (
+	testRPId   = "example.com"
+	testOrigin = "https://example.com"
+)
+
+type testAuthenticator struct {
+	key       *ecdsa.PrivateKey
+	id        []byte
+	signCount uint32
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testAuthenticator{key: key, id: []byte("test_credential_id")}
+}
+
+func (a *testAuthenticator) coseKey(t *testing.T) []byte {
+	raw, err := webauthn.EncodeCBOR(map[any]any{
+		1:  2,
+		3:  webauthn.AlgES256,
+		-1: 1,
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return raw
+}
+
+func (a *testAuthenticator) authData(t *testing.T, rpId string, flags byte, attested bool) []byte {
+	rpIdHash := sha256.Sum256([]byte(rpId))
+
+	data := append([]byte{}, rpIdHash[:]...)
+	if attested {
+		flags |= webauthn.FlagAttestedCredentialData
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+
+	if attested {
+		data = append(data, make([]byte, 16)...) // aaguid
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.id)))
+		data = append(data, a.id...)
+		data = append(data, a.coseKey(t)...)
+	}
+
+	return data
+}
+
+func clientDataJSON(t *testing.T, typ string, challenge string, origin string) []byte {
+	raw, err := json.Marshal(webauthn.ClientData{Type: typ, Challenge: challenge, Origin: origin})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return raw
+}
+
+func (a *testAuthenticator) attestationObject(t *testing.T, rpId string, flags byte) []byte {
+	raw, err := webauthn.EncodeCBOR(map[any]any{
+		"fmt":      "none",
+		"attStmt":  map[any]any{},
+		"authData": a.authData(t, rpId, flags, true),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return raw
+}
+
+func (a *testAuthenticator) sign(t *testing.T, authData []byte, clientData []byte) []byte {
+	hash := sha256.Sum256(clientData)
+	signed := sha256.Sum256(append(append([]byte{}, authData...), hash[:]...))
+
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, signed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sig
+}
+
+func TestCBOR(t *testing.T) {
+	t.Parallel()
+
+	value := map[any]any{
+		1:      -7,
+		"a":    []byte{1, 2, 3},
+		"b":    []any{"c", true, false, nil, int64(1000000)},
+		-1:     map[any]any{"nested": 1.5},
+		"long": strings.Repeat("x", 300),
+	}
+
+	raw, err := webauthn.EncodeCBOR(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, rest, err := webauthn.DecodeCBOR(append(raw, 0xff))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rest) != 1 || rest[0] != 0xff {
+		t.Fatalf("Expected the trailing byte to be returned, got %v", rest)
+	}
+
+	m, ok := decoded.(map[any]any)
+	if !ok {
+		t.Fatalf("Expected map, got %T", decoded)
+	}
+
+	if v := m[int64(1)]; v != int64(-7) {
+		t.Fatalf("Expected -7, got %v", v)
+	}
+
+	if v, _ := m["a"].([]byte); string(v) != string([]byte{1, 2, 3}) {
+		t.Fatalf("Expected bytes, got %v", m["a"])
+	}
+
+	if v, _ := m["b"].([]any); len(v) != 5 || v[0] != "c" || v[1] != true || v[2] != false || v[3] != nil || v[4] != int64(1000000) {
+		t.Fatalf("Unexpected array %v", m["b"])
+	}
+
+	if v, _ := m[int64(-1)].(map[any]any); v["nested"] != 1.5 {
+		t.Fatalf("Unexpected nested map %v", m[int64(-1)])
+	}
+
+	if v, _ := m["long"].(string); len(v) != 300 {
+		t.Fatalf("Unexpected long string length %d", len(v))
+	}
+
+	// truncated
+	if _, _, err := webauthn.DecodeCBOR(raw[:len(raw)-1]); err == nil {
+		t.Fatal("Expected error for truncated data")
+	}
+
+	// indefinite length
+	if _, _, err := webauthn.DecodeCBOR([]byte{0x9f, 0xff}); err == nil {
+		t.Fatal("Expected error for indefinite length array")
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAuthenticator(t)
+
+	key, err := webauthn.ParsePublicKey(a.coseKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Algorithm != webauthn.AlgES256 {
+		t.Fatalf("Expected ES256 algorithm, got %d", key.Algorithm)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawEd, _ := webauthn.EncodeCBOR(map[any]any{1: 1, 3: webauthn.AlgEdDSA, -1: 6, -2: []byte(pub)})
+	edKey, err := webauthn.ParsePublicKey(rawEd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := edKey.Verify([]byte("test"), ed25519.Sign(priv, []byte("test"))); err != nil {
+		t.Fatalf("Expected valid EdDSA signature, got %v", err)
+	}
+	if err := edKey.Verify([]byte("test2"), ed25519.Sign(priv, []byte("test"))); err == nil {
+		t.Fatal("Expected invalid EdDSA signature")
+	}
+
+	invalid := []map[any]any{
+		{1: 2, 3: webauthn.AlgES256, -1: 1, -2: make([]byte, 32), -3: make([]byte, 32)}, // not on curve
+		{1: 2, 3: webauthn.AlgES256, -1: 2, -2: make([]byte, 32), -3: make([]byte, 32)}, // unsupported curve
+		{1: 3, 3: webauthn.AlgRS256, -1: make([]byte, 10), -2: []byte{1, 0, 1}},         // short modulus
+		{1: 2, 3: -35},
+	}
+	for i, v := range invalid {
+		raw, _ := webauthn.EncodeCBOR(v)
+		if _, err := webauthn.ParsePublicKey(raw); err == nil {
+			t.Fatalf("[%d] Expected error", i)
+		}
+	}
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAuthenticator(t)
+
+	challenge := webauthn.NewChallenge()
+
+	options := webauthn.CeremonyOptions{
+		Challenge: challenge,
+		RPId:      testRPId,
+		Origins:   []string{testOrigin},
+	}
+
+	scenarios := []struct {
+		name        string
+		options     webauthn.CeremonyOptions
+		clientData  []byte
+		attestation []byte
+		expectError bool
+	}{
+		{
+			"invalid client data type",
+			options,
+			clientDataJSON(t, webauthn.ClientDataTypeGet, challenge, testOrigin),
+			a.attestationObject(t, testRPId, webauthn.FlagUserPresent),
+			true,
+		},
+		{
+			"challenge mismatch",
+			options,
+			clientDataJSON(t, webauthn.ClientDataTypeCreate, "other", testOrigin),
+			a.attestationObject(t, testRPId, webauthn.FlagUserPresent),
+			true,
+		},
+		{
+			"origin mismatch",
+			options,
+			clientDataJSON(t, webauthn.ClientDataTypeCreate, challenge, "https://other.com"),
+			a.attestationObject(t, testRPId, webauthn.FlagUserPresent),
+			true,
+		},
+		{
+			"rp id mismatch",
+			options,
+			clientDataJSON(t, webauthn.ClientDataTypeCreate, challenge, testOrigin),
+			a.attestationObject(t, "other.com", webauthn.FlagUserPresent),
+			true,
+		},
+		{
+			"missing user presence",
+			options,
+			clientDataJSON(t, webauthn.ClientDataTypeCreate, challenge, testOrigin),
+			a.attestationObject(t, testRPId, 0),
+			true,
+		},
+		{
+			"missing required user verification",
+			webauthn.CeremonyOptions{Challenge: challenge, RPId: testRPId, Origins: []string{testOrigin}, RequireUserVerification: true},
+			clientDataJSON(t, webauthn.ClientDataTypeCreate, challenge, testOrigin),
+			a.attestationObject(t, testRPId, webauthn.FlagUserPresent),
+			true,
+		},
+		{
+			"invalid attestation object",
+			options,
+			clientDataJSON(t, webauthn.ClientDataTypeCreate, challenge, testOrigin),
+			[]byte("invalid"),
+			true,
+		},
+		{
+			"valid",
+			options,
+			clientDataJSON(t, webauthn.ClientDataTypeCreate, challenge, testOrigin),
+			a.attestationObject(t, testRPId, webauthn.FlagUserPresent|webauthn.FlagUserVerified|webauthn.FlagBackupEligible),
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			credential, err := webauthn.VerifyRegistration(s.options, s.clientData, s.attestation)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if string(credential.Id) != string(a.id) {
+				t.Fatalf("Expected credential id %q, got %q", a.id, credential.Id)
+			}
+
+			if string(credential.PublicKey) != string(a.coseKey(t)) {
+				t.Fatal("Expected the credential public key to match the authenticator one")
+			}
+
+			if credential.Algorithm != webauthn.AlgES256 || !credential.BackupEligible {
+				t.Fatalf("Unexpected credential %+v", credential)
+			}
+		})
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAuthenticator(t)
+
+	challenge := webauthn.NewChallenge()
+
+	options := webauthn.CeremonyOptions{
+		Challenge: challenge,
+		RPId:      testRPId,
+		Origins:   []string{testOrigin},
+	}
+
+	validClientData := clientDataJSON(t, webauthn.ClientDataTypeGet, challenge, testOrigin)
+
+	a.signCount = 5
+	validAuthData := a.authData(t, testRPId, webauthn.FlagUserPresent, false)
+	validSignature := a.sign(t, validAuthData, validClientData)
+
+	a.signCount = 0
+	zeroCountAuthData := a.authData(t, testRPId, webauthn.FlagUserPresent, false)
+	zeroCountSignature := a.sign(t, zeroCountAuthData, validClientData)
+
+	otherRPAuthData := a.authData(t, "other.com", webauthn.FlagUserPresent, false)
+
+	scenarios := []struct {
+		name              string
+		storedCount       uint32
+		clientData        []byte
+		authData          []byte
+		signature         []byte
+		expectError       bool
+		expectedSignCount uint32
+	}{
+		{
+			"invalid client data type",
+			0,
+			clientDataJSON(t, webauthn.ClientDataTypeCreate, challenge, testOrigin),
+			validAuthData,
+			validSignature,
+			true,
+			0,
+		},
+		{
+			"rp id mismatch",
+			0,
+			validClientData,
+			otherRPAuthData,
+			a.sign(t, otherRPAuthData, validClientData),
+			true,
+			0,
+		},
+		{
+			"invalid signature",
+			0,
+			validClientData,
+			validAuthData,
+			zeroCountSignature,
+			true,
+			0,
+		},
+		{
+			"not increased sign count",
+			5,
+			validClientData,
+			validAuthData,
+			validSignature,
+			true,
+			0,
+		},
+		{
+			"increased sign count",
+			4,
+			validClientData,
+			validAuthData,
+			validSignature,
+			false,
+			5,
+		},
+		{
+			"zero sign counts",
+			0,
+			validClientData,
+			zeroCountAuthData,
+			zeroCountSignature,
+			false,
+			0,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			signCount, err := webauthn.VerifyAssertion(options, a.coseKey(t), s.storedCount, s.clientData, s.authData, s.signature)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if signCount != s.expectedSignCount {
+				t.Fatalf("Expected sign count %d, got %d", s.expectedSignCount, signCount)
+			}
+		})
+	}
+}
+
+func TestBase64URL(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []string{`"AQID_w"`, `"AQID/w=="`, `"AQID_w=="`}
+
+	for _, s := range scenarios {
+		var b webauthn.Base64URL
+		if err := json.Unmarshal([]byte(s), &b); err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+
+		if string(b) != string([]byte{1, 2, 3, 255}) {
+			t.Fatalf("%s: unexpected decoded bytes %v", s, []byte(b))
+		}
+
+		raw, _ := json.Marshal(b)
+		if string(raw) != `"AQID_w"` {
+			t.Fatalf("%s: expected to be encoded as unpadded base64url, got %s", s, raw)
+		}
+	}
+}